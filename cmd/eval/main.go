@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+
+	multiagent "antifraud/internal/modules/multi_agent/core"
+	"antifraud/internal/modules/multi_agent/domain/scoring"
+	"antifraud/internal/modules/multi_agent/evaluation"
+	appcfg "antifraud/internal/platform/config"
+	"antifraud/internal/platform/llm"
+)
+
+func main() {
+	datasetPath := flag.String("dataset", evaluation.DefaultDatasetPath, "labeled dataset path")
+	mode := flag.String("llm", "stub", "prediction source: stub | live | recorded")
+	jsonPath := flag.String("json", "", "write JSON report to this path")
+	markdownPath := flag.String("markdown", "", "write Markdown report to this path (default: stdout)")
+	bins := flag.Int("bins", 10, "score calibration bins")
+	rulesPath := flag.String("rules", "", "risk scoring rules file to evaluate (default: built-in rules)")
+	flag.Parse()
+
+	if err := scoring.SetRuleFile(*rulesPath); err != nil {
+		fmt.Fprintf(os.Stderr, "load risk rules failed: %v\n", err)
+		os.Exit(2)
+	}
+
+	dataset, err := evaluation.LoadDataset(*datasetPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "load dataset failed: %v\n", err)
+		os.Exit(1)
+	}
+
+	predictor, err := buildPredictor(strings.TrimSpace(*mode), dataset)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "build predictor failed: %v\n", err)
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	report, err := evaluation.Run(ctx, dataset, predictor, evaluation.RunOptions{CalibrationBins: *bins})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "run evaluation failed: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *jsonPath != "" {
+		if err := writeReportFile(*jsonPath, func(file *os.File) error { return evaluation.WriteJSON(file, report) }); err != nil {
+			fmt.Fprintf(os.Stderr, "write json report failed: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if *markdownPath != "" {
+		if err := writeReportFile(*markdownPath, func(file *os.File) error { return evaluation.WriteMarkdown(file, report) }); err != nil {
+			fmt.Fprintf(os.Stderr, "write markdown report failed: %v\n", err)
+			os.Exit(1)
+		}
+	} else if err := evaluation.WriteMarkdown(os.Stdout, report); err != nil {
+		fmt.Fprintf(os.Stderr, "write markdown report failed: %v\n", err)
+		os.Exit(1)
+	}
+
+	if report.FailedCases > 0 {
+		os.Exit(3)
+	}
+}
+
+// buildPredictor 按 -llm 参数选择预测来源：
+// stub 使用进程内桩模型驱动真实主流程，live 使用 config.json 中的主智能体模型，recorded 复用标注集中的历史输出。
+// live 模式遵循 llm_cassette 配置，设置 LLM_CASSETTE_MODE=replay 即可在离线环境回放录制好的模型交互。
+func buildPredictor(mode string, dataset evaluation.Dataset) (evaluation.Predictor, error) {
+	switch mode {
+	case "recorded":
+		return evaluation.RecordedPredictor{}, nil
+	case "live":
+		cfg, err := appcfg.LoadConfig("internal/platform/config/config.json")
+		if err != nil {
+			return nil, err
+		}
+		llm.SetDefaultCassette(llm.CassetteOptions{Mode: cfg.LLMCassette.Mode, Dir: cfg.LLMCassette.Dir})
+		return evaluation.MainAgentPredictor{
+			Label: "live",
+			Agent: multiagent.NewMainAgent(cfg.Agents.Main, cfg.Retry, cfg.Prompts.Main),
+		}, nil
+	case "stub", "":
+		// 桩模型不依赖模型密钥；配置可读时沿用主智能体提示词与重试参数，保持请求形态与线上一致。
+		modelCfg := appcfg.ModelConfig{Model: "eval-stub"}
+		retryCfg := appcfg.RetryConfig{MaxRetries: 1}
+		systemPrompt := ""
+		if cfg, err := appcfg.LoadConfig("internal/platform/config/config.json"); err == nil {
+			modelCfg = cfg.Agents.Main
+			retryCfg = cfg.Retry
+			systemPrompt = cfg.Prompts.Main
+		} else {
+			fmt.Fprintf(os.Stderr, "config unavailable, stub uses empty main prompt: %v\n", err)
+		}
+		stub := evaluation.NewStubLLM(dataset.Cases)
+		return evaluation.MainAgentPredictor{
+			Label:            "stub",
+			Agent:            multiagent.NewMainAgentWithClient(modelCfg, retryCfg, systemPrompt, stub.NewClient()),
+			RecordedScamType: true,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported -llm mode %q", mode)
+	}
+}
+
+func writeReportFile(path string, write func(file *os.File) error) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(file); err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -36,7 +36,7 @@ func main() {
 		fmt.Printf("BASE64_PREFIX=%s\n", encoded)
 	}
 
-	normalizedPayload, err := application.NormalizeTaskPayload(state.TaskPayload{
+	normalizedPayload, err := application.NormalizeTaskPayload(context.Background(), state.TaskPayload{
 		Videos: []string{encoded},
 	})
 	if err != nil {
@@ -15,13 +15,25 @@ import (
 	"antifraud/internal/modules/login/adapters/outbound/session"
 	"antifraud/internal/modules/login/adapters/outbound/smscode"
 	multihttp "antifraud/internal/modules/multi_agent/adapters/inbound/http"
+	"antifraud/internal/modules/multi_agent/adapters/outbound/blobstore"
 	"antifraud/internal/modules/multi_agent/adapters/outbound/case_library"
+	"antifraud/internal/modules/multi_agent/adapters/outbound/mediastore"
 	"antifraud/internal/modules/multi_agent/adapters/outbound/state"
+	"antifraud/internal/modules/multi_agent/adapters/outbound/user_history_index"
+	"antifraud/internal/modules/multi_agent/application/casecluster"
+	"antifraud/internal/modules/multi_agent/application/casecollection"
+	"antifraud/internal/modules/multi_agent/application/casetransfer"
+	"antifraud/internal/modules/multi_agent/application/migration"
+	"antifraud/internal/modules/multi_agent/application/queue"
+	"antifraud/internal/modules/multi_agent/application/retention"
+	"antifraud/internal/modules/multi_agent/domain/explanation"
+	"antifraud/internal/modules/multi_agent/domain/scoring"
 	region_system "antifraud/internal/modules/region"
 	"antifraud/internal/modules/scam_simulation"
 	user_profile_system "antifraud/internal/modules/user_profile"
 	appcfg "antifraud/internal/platform/config"
 	"antifraud/internal/platform/database"
+	"antifraud/internal/platform/llm"
 
 	"github.com/gin-gonic/gin"
 )
@@ -30,15 +42,51 @@ const defaultConfigPath = "internal/platform/internal/platform/config/config.jso
 
 // BuildRouter 创建并装配 HTTP 服务。
 func BuildRouter() (*gin.Engine, error) {
-	if _, err := appcfg.LoadConfig(defaultConfigPath); err != nil {
+	cfg, err := appcfg.LoadConfig(defaultConfigPath)
+	if err != nil {
 		return nil, err
 	}
+	// 录制回放需在任何智能体创建模型客户端之前生效。
+	llm.SetDefaultCassette(llm.CassetteOptions{Mode: cfg.LLMCassette.Mode, Dir: cfg.LLMCassette.Dir})
+	if cfg.LLMCassette.Mode != appcfg.LLMCassetteModeOff {
+		log.Printf("[llm] cassette enabled: mode=%s dir=%s", cfg.LLMCassette.Mode, cfg.LLMCassette.Dir)
+	}
+	if err := scoring.SetRuleFile(cfg.RiskRules.Path); err != nil {
+		log.Printf("[scoring] use built-in risk rules, external file unavailable: %v", err)
+	}
+	log.Printf("[scoring] risk rules version=%s", scoring.CurrentVersion())
 	if err := database.InitPersistence(); err != nil {
 		return nil, err
 	}
 	if err := case_library.WarmupHistoricalCaseVectorCache(); err != nil {
 		log.Printf("warmup historical case vector cache failed: %v", err)
 	}
+	// 固定当前向量库使用的 embedding 模型，修改配置后需通过迁移任务切换；随后恢复被中断的迁移。
+	embeddingMigration := migration.DefaultService()
+	if activeModel, err := embeddingMigration.InitializeActiveModel(); err != nil {
+		log.Printf("[embedding] pin active embedding model failed: %v", err)
+	} else {
+		log.Printf("[embedding] active embedding model=%s", activeModel)
+	}
+	embeddingMigration.ResumeInterruptedJobs()
+	casetransfer.DefaultService().ResumeInterruptedJobs()
+	casecluster.DefaultService().ResumeInterruptedJobs()
+	caseCollection := casecollection.DefaultService()
+	caseCollection.ResumeInterruptedJobs()
+	// 中断任务收尾后再启动定时采集调度，避免把遗留的 running 任务误判为计划重叠。
+	caseCollection.StartCampaignScheduler(context.Background())
+	mediastore.DefaultStore().StartJanitor(context.Background())
+	// 历史版本把媒体以 base64 内联在任务与历史表中，后台逐行迁移为 blob 引用，再启动孤儿 blob 清理。
+	go func() {
+		migrated, err := state.MigrateInlinePayloadMedia()
+		if err != nil {
+			log.Printf("[state] migrate inline payload media failed: %v", err)
+		} else if migrated > 0 {
+			log.Printf("[state] migrated inline payload media rows=%d", migrated)
+		}
+		blobstore.DefaultStore().StartJanitor(context.Background())
+	}()
+	retention.DefaultService().Start(context.Background())
 
 	authUserReader := middleware.NewGormAuthUserReader(database.DB)
 	activeTokenManager := session.NewDefaultRedisActiveTokenManager()
@@ -61,6 +109,7 @@ func BuildRouter() (*gin.Engine, error) {
 		if err != nil {
 			return
 		}
+		riskExplanation := explanation.BuildRiskExplanation(record)
 		_ = familyService.HandleRiskEvent(context.Background(), family_system.RiskEvent{
 			TargetUserID: uint(userID),
 			RecordID:     record.RecordID,
@@ -68,10 +117,26 @@ func BuildRouter() (*gin.Engine, error) {
 			CaseSummary:  record.CaseSummary,
 			ScamType:     record.ScamType,
 			RiskLevel:    record.RiskLevel,
+			RiskHeadline: riskExplanation.Headline,
+			RiskReasons:  riskExplanation.NotificationReasons(),
 			CreatedAt:    record.CreatedAt,
 		})
 	})
 
+	// 历史记录被删除（用户删除、保留策略到期或用户数据清除）后同步清理相似检索向量并刷新统计缓存。
+	state.RegisterHistoryRemovedObserver(func(userID string, recordID string) {
+		multihttp.TouchGeoCaseMapCacheVersion()
+		region_system.TouchRegionCaseStatsCacheVersion()
+		if err := user_history_index.DefaultService().DeleteHistoryVector(recordID, userID); err != nil {
+			log.Printf("[user_history_index] delete history vector failed: user=%s record=%s err=%v", userID, recordID, err)
+		}
+	})
+
+	// 观察者注册完成后再启动工作池，保证重启回收的任务归档时同样触发告警与缓存失效。
+	if err := queue.StartMultimodalTaskWorkers(context.Background(), cfg.TaskQueue); err != nil {
+		return nil, err
+	}
+
 	r := gin.Default()
 	if err := r.SetTrustedProxies([]string{"127.0.0.1", "::1"}); err != nil {
 		return nil, err
@@ -150,11 +215,22 @@ func registerProtectedRoutes(
 	family_system.RegisterRoutes(api, familyService)
 	api.POST("/scam/image/quick-analyze", multihttp.AnalyzeImageQuickHandle)
 	api.POST("/scam/multimodal/analyze", multihttp.AnalyzeMultimodalScamHandle)
+	api.POST("/scam/multimodal/uploads", multihttp.CreateMediaUploadHandle)
+	api.GET("/scam/multimodal/uploads/:uploadId", multihttp.GetMediaUploadHandle)
+	api.PUT("/scam/multimodal/uploads/:uploadId/chunks/:index", multihttp.PutMediaUploadChunkHandle)
+	api.POST("/scam/multimodal/uploads/:uploadId/complete", multihttp.CompleteMediaUploadHandle)
+	api.DELETE("/scam/multimodal/uploads/:uploadId", multihttp.AbortMediaUploadHandle)
 	api.GET("/scam/multimodal/tasks", multihttp.GetMultimodalTaskStateHandle)
 	api.GET("/scam/multimodal/history", multihttp.GetMultimodalHistoryHandle)
 	api.GET("/scam/multimodal/history/overview", multihttp.GetMultimodalRiskOverviewHandle)
 	api.DELETE("/scam/multimodal/history/:recordId", multihttp.DeleteMultimodalHistoryHandle)
+	api.GET("/scam/multimodal/history/:recordId/explanation", multihttp.GetMultimodalRiskExplanationHandle)
 	api.GET("/scam/multimodal/tasks/:taskId", multihttp.GetMultimodalTaskDetailHandle)
+	api.POST("/scam/multimodal/tasks/:taskId/cancel", multihttp.CancelMultimodalTaskHandle)
+	api.GET("/scam/multimodal/tasks/:taskId/events", multihttp.StreamMultimodalTaskProgressHandle)
+	api.GET("/scam/multimodal/tasks/:taskId/media/:blobKey", multihttp.GetMultimodalTaskMediaHandle)
+	api.GET("/scam/multimodal/data/export", multihttp.ExportUserDataHandle)
+	api.POST("/scam/multimodal/data/purge", multihttp.PurgeUserDataHandle)
 
 	adminCaseLibrary := api.Group("/scam/case-library")
 	adminCaseLibrary.Use(middleware.AdminMiddleware(authUserReader))
@@ -168,17 +244,53 @@ func registerProtectedRoutes(
 	adminCaseLibrary.GET("/maps/geojson", multihttp.GetGeoBoundaryGeoJSONHandle)
 	adminCaseLibrary.GET("/options/scam-types", multihttp.GetHistoricalCaseScamTypeOptionsHandle)
 	adminCaseLibrary.GET("/options/target-groups", multihttp.GetHistoricalCaseTargetGroupOptionsHandle)
+	adminCaseLibrary.GET("/cases/export", multihttp.ExportHistoricalCasesHandle)
+	adminCaseLibrary.GET("/citations", multihttp.GetCasesByCitationHandle)
 	adminCaseLibrary.GET("/cases/:caseId", multihttp.GetHistoricalCaseDetailHandle)
+	adminCaseLibrary.PUT("/cases/:caseId", multihttp.ReplaceHistoricalCaseHandle)
+	adminCaseLibrary.PATCH("/cases/:caseId", multihttp.PatchHistoricalCaseHandle)
 	adminCaseLibrary.DELETE("/cases/:caseId", multihttp.DeleteHistoricalCaseHandle)
+	adminCaseLibrary.GET("/cases/:caseId/revisions", multihttp.GetHistoricalCaseRevisionsHandle)
+	adminCaseLibrary.GET("/cases/:caseId/revisions/:revision/diff", multihttp.GetHistoricalCaseRevisionDiffHandle)
+	adminCaseLibrary.POST("/cases/:caseId/revisions/:revision/rollback", multihttp.RollbackHistoricalCaseHandle)
+	adminCaseLibrary.POST("/cases/:caseId/merge", multihttp.MergeHistoricalCasesHandle)
+	adminCaseLibrary.POST("/clusters", multihttp.StartCaseClusterJobHandle)
+	adminCaseLibrary.GET("/clusters", multihttp.ListCaseClusterJobsHandle)
+	adminCaseLibrary.GET("/clusters/:jobId", multihttp.GetCaseClusterJobHandle)
+	adminCaseLibrary.POST("/embedding-migrations", multihttp.StartEmbeddingMigrationHandle)
+	adminCaseLibrary.GET("/embedding-migrations", multihttp.ListEmbeddingMigrationsHandle)
+	adminCaseLibrary.GET("/embedding-migrations/:jobId", multihttp.GetEmbeddingMigrationHandle)
+	adminCaseLibrary.POST("/embedding-migrations/:jobId/cancel", multihttp.CancelEmbeddingMigrationHandle)
+	adminCaseLibrary.POST("/imports", multihttp.StartHistoricalCaseImportHandle)
+	adminCaseLibrary.GET("/imports", multihttp.ListHistoricalCaseImportsHandle)
+	adminCaseLibrary.GET("/imports/:jobId", multihttp.GetHistoricalCaseImportHandle)
+	adminCaseLibrary.GET("/imports/:jobId/errors", multihttp.GetHistoricalCaseImportErrorsHandle)
 
 	adminReview := api.Group("/scam/review")
 	adminReview.Use(middleware.AdminMiddleware(authUserReader))
 	adminReview.GET("/cases", multihttp.GetPendingReviewCasesHandle)
 	adminReview.GET("/cases/:recordId", multihttp.GetPendingReviewCaseDetailHandle)
+	adminReview.PATCH("/cases/:recordId", multihttp.PatchPendingReviewCaseHandle)
+	adminReview.POST("/cases/:recordId/claim", multihttp.ClaimPendingReviewCaseHandle)
+	adminReview.POST("/cases/:recordId/release", multihttp.ReleasePendingReviewCaseHandle)
+	adminReview.POST("/cases/:recordId/comments", multihttp.AddPendingReviewCommentHandle)
+	adminReview.GET("/cases/:recordId/audit", multihttp.GetPendingReviewCaseAuditHandle)
 	adminReview.POST("/cases/:recordId/approve", multihttp.ApprovePendingReviewCaseHandle)
 	adminReview.POST("/cases/:recordId/reject", multihttp.RejectPendingReviewCaseHandle)
+	adminReview.GET("/audit", multihttp.GetPendingReviewAuditLogsHandle)
 
 	adminCaseCollection := api.Group("/scam/case-collection")
 	adminCaseCollection.Use(middleware.AdminMiddleware(authUserReader))
 	adminCaseCollection.POST("/search", multihttp.CollectCaseCollectionHandle)
+	adminCaseCollection.GET("/jobs", multihttp.ListCaseCollectionJobsHandle)
+	adminCaseCollection.GET("/jobs/:jobId", multihttp.GetCaseCollectionJobHandle)
+	adminCaseCollection.POST("/jobs/:jobId/cancel", multihttp.CancelCaseCollectionJobHandle)
+	adminCaseCollection.POST("/jobs/:jobId/retry", multihttp.RetryCaseCollectionJobHandle)
+	adminCaseCollection.GET("/campaigns", multihttp.ListCaseCollectionCampaignsHandle)
+	adminCaseCollection.POST("/campaigns", multihttp.CreateCaseCollectionCampaignHandle)
+	adminCaseCollection.GET("/campaigns/:campaignId", multihttp.GetCaseCollectionCampaignHandle)
+	adminCaseCollection.PATCH("/campaigns/:campaignId", multihttp.PatchCaseCollectionCampaignHandle)
+	adminCaseCollection.DELETE("/campaigns/:campaignId", multihttp.DeleteCaseCollectionCampaignHandle)
+	adminCaseCollection.POST("/campaigns/:campaignId/run", multihttp.RunCaseCollectionCampaignHandle)
+	adminCaseCollection.GET("/campaigns/:campaignId/runs", multihttp.ListCaseCollectionCampaignRunsHandle)
 }
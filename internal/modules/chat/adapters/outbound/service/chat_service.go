@@ -12,6 +12,7 @@ import (
 	chattool "antifraud/internal/modules/chat/adapters/outbound/tool"
 	"antifraud/internal/platform/cache"
 	appcfg "antifraud/internal/platform/config"
+	"antifraud/internal/platform/redaction"
 
 	openai "antifraud/internal/platform/llm"
 )
@@ -111,6 +112,9 @@ func BuildMessagesForUserWithPrefix(conversationKeyPrefix string, systemPrompt s
 		if err := cache.SetJSON(key, history, conversationTTL); err != nil {
 			log.Printf("[chat] refresh conversation ttl failed: user=%s err=%v", trimmedUserID, err)
 		}
+		if err := refreshConversationRedactionTTL(conversationKeyPrefix, trimmedUserID); err != nil {
+			log.Printf("[chat] refresh conversation redaction ttl failed: user=%s err=%v", trimmedUserID, err)
+		}
 	}
 
 	for _, item := range history {
@@ -132,6 +136,8 @@ func BuildMessagesForUserWithPrefix(conversationKeyPrefix string, systemPrompt s
 
 // StreamReply 使用全流式回合处理：首轮即 stream=true，边接收边向前端推送内容；
 // 若出现 tool_calls，则在参数拼接完成后执行工具并继续下一轮流式请求。
+// ctx 绑定了会话 Redactor 时，userInput 与 messages 应已脱敏：工具结果回传模型前同样脱敏，
+// 推送给前端的内容按映射还原，返回的回复与会话消息保留占位符。
 func (s *ChatService) StreamReply(ctx context.Context, userID string, userInput string, userImageURLs []string, messages []openai.ChatCompletionMessage, emit func(event map[string]interface{}) error) (string, []ConversationMessage, error) {
 	resolved := append([]openai.ChatCompletionMessage{}, messages...)
 	recorded := make([]ConversationMessage, 0)
@@ -189,15 +195,17 @@ func (s *ChatService) StreamReply(ctx context.Context, userID string, userInput
 			}
 
 			payloadBytes, _ := json.Marshal(toolPayload)
+			// 工具结果可能带回用户画像、历史案件等原文，回传模型前按会话映射脱敏；占位符不含引号与反斜杠，不会破坏 JSON。
+			toolContent := redaction.FromContext(ctx).Redact(string(payloadBytes))
 			resolved = append(resolved, openai.ChatCompletionMessage{
 				Role:       openai.ChatMessageRoleTool,
 				ToolCallID: call.ID,
-				Content:    string(payloadBytes),
+				Content:    toolContent,
 			})
 			recorded = append(recorded, ConversationMessage{
 				Role:       openai.ChatMessageRoleTool,
 				ToolCallID: call.ID,
-				Content:    string(payloadBytes),
+				Content:    toolContent,
 			})
 		}
 	}
@@ -223,6 +231,19 @@ func (s *ChatService) streamAssistantRound(ctx context.Context, messages []opena
 
 	var contentBuilder strings.Builder
 	toolCallCollector := newStreamToolCallCollector()
+	var restorer *placeholderStreamRestorer
+	if redactor := redaction.FromContext(ctx); redactor != nil {
+		restorer = &placeholderStreamRestorer{redactor: redactor}
+	}
+	emitContent := func(content string) error {
+		if emit == nil || content == "" {
+			return nil
+		}
+		return emit(map[string]interface{}{
+			"type":    "content",
+			"content": content,
+		})
+	}
 
 	for {
 		resp, recvErr := stream.Recv()
@@ -240,13 +261,12 @@ func (s *ChatService) streamAssistantRound(ctx context.Context, messages []opena
 		delta := resp.Choices[0].Delta
 		if delta.Content != "" {
 			contentBuilder.WriteString(delta.Content)
-			if emit != nil {
-				if err := emit(map[string]interface{}{
-					"type":    "content",
-					"content": delta.Content,
-				}); err != nil {
-					return openai.ChatCompletionMessage{}, err
-				}
+			content := delta.Content
+			if restorer != nil {
+				content = restorer.Push(content)
+			}
+			if err := emitContent(content); err != nil {
+				return openai.ChatCompletionMessage{}, err
 			}
 		}
 		if len(delta.ToolCalls) > 0 {
@@ -254,6 +274,12 @@ func (s *ChatService) streamAssistantRound(ctx context.Context, messages []opena
 		}
 	}
 
+	if restorer != nil {
+		if err := emitContent(restorer.Flush()); err != nil {
+			return openai.ChatCompletionMessage{}, err
+		}
+	}
+
 	return openai.ChatCompletionMessage{
 		Role:      openai.ChatMessageRoleAssistant,
 		Content:   contentBuilder.String(),
@@ -473,6 +499,9 @@ func ClearConversationWithPrefix(conversationKeyPrefix string, userID string) er
 	if err := cache.Delete(key); err != nil {
 		return fmt.Errorf("clear conversation from redis failed: %w", err)
 	}
+	if err := cache.Delete(conversationRedactionKey(conversationKeyPrefix, trimmedUserID)); err != nil {
+		return fmt.Errorf("clear conversation redaction mapping failed: %w", err)
+	}
 
 	return nil
 }
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"antifraud/internal/platform/cache"
+	"antifraud/internal/platform/redaction"
+)
+
+// maxPendingPlaceholderBytes 是流式还原时为等待占位符闭合而暂存的最大字节数，超过后视为普通文本直接输出。
+const maxPendingPlaceholderBytes = 64
+
+// LoadConversationRedactionMapping 读取会话的脱敏映射（占位符 -> 原文），会话不存在或已过期时返回空映射。
+func LoadConversationRedactionMapping(conversationKeyPrefix string, userID string) (map[string]string, error) {
+	mapping := map[string]string{}
+	if _, err := cache.GetJSON(conversationRedactionKey(conversationKeyPrefix, userID), &mapping); err != nil {
+		return nil, fmt.Errorf("load conversation redaction mapping failed: %w", err)
+	}
+	return mapping, nil
+}
+
+// SaveConversationRedactionMapping 合并保存会话的脱敏映射并与会话上下文使用相同 TTL，已保存的占位符保持原值不被覆盖。
+func SaveConversationRedactionMapping(conversationKeyPrefix string, userID string, mapping map[string]string) error {
+	if len(mapping) == 0 {
+		return nil
+	}
+	merged, err := LoadConversationRedactionMapping(conversationKeyPrefix, userID)
+	if err != nil {
+		return err
+	}
+	for placeholder, original := range mapping {
+		if _, exists := merged[placeholder]; !exists {
+			merged[placeholder] = original
+		}
+	}
+	if err := cache.SetJSON(conversationRedactionKey(conversationKeyPrefix, userID), merged, conversationTTL); err != nil {
+		return fmt.Errorf("save conversation redaction mapping failed: %w", err)
+	}
+	return nil
+}
+
+// conversationRedactionKey 生成会话脱敏映射在 Redis 中的 key，与会话上下文同前缀、同生命周期。
+func conversationRedactionKey(prefix string, userID string) string {
+	trimmedUserID := strings.TrimSpace(userID)
+	if trimmedUserID == "" {
+		trimmedUserID = "demo-user"
+	}
+	return conversationKey(prefix, trimmedUserID) + ":redaction"
+}
+
+// refreshConversationRedactionTTL 随会话上下文一起续期映射，避免映射先于上下文过期后占位符编号被重新分配。
+func refreshConversationRedactionTTL(conversationKeyPrefix string, userID string) error {
+	mapping, err := LoadConversationRedactionMapping(conversationKeyPrefix, userID)
+	if err != nil || len(mapping) == 0 {
+		return err
+	}
+	return cache.SetJSON(conversationRedactionKey(conversationKeyPrefix, userID), mapping, conversationTTL)
+}
+
+// placeholderStreamRestorer 把流式增量中的占位符还原为原文后再推送给用户。
+// 占位符可能被拆在两个增量之间，末尾未闭合的 "[..." 片段暂存到下一个增量再处理。
+type placeholderStreamRestorer struct {
+	redactor *redaction.Redactor
+	pending  string
+}
+
+// Push 返回可以立即推送的已还原文本。
+func (r *placeholderStreamRestorer) Push(delta string) string {
+	text := r.pending + delta
+	r.pending = ""
+	if start := strings.LastIndex(text, "["); start >= 0 && !strings.Contains(text[start:], "]") && len(text)-start <= maxPendingPlaceholderBytes {
+		r.pending = text[start:]
+		text = text[:start]
+	}
+	return r.redactor.Restore(text)
+}
+
+// Flush 返回暂存的剩余文本。
+func (r *placeholderStreamRestorer) Flush() string {
+	text := r.pending
+	r.pending = ""
+	return r.redactor.Restore(text)
+}
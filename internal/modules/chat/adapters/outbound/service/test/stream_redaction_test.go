@@ -0,0 +1,92 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	chatservice "antifraud/internal/modules/chat/adapters/outbound/service"
+	appcfg "antifraud/internal/platform/config"
+	openai "antifraud/internal/platform/llm"
+	"antifraud/internal/platform/redaction"
+)
+
+func TestStreamReply_RestoresPlaceholdersSplitAcrossDeltas(t *testing.T) {
+	const phone = "13812345678"
+	redactor := redaction.NewRedactor(nil)
+	userInput := redactor.Redact("我的电话是" + phone)
+	placeholder := strings.TrimPrefix(userInput, "我的电话是")
+	if placeholder == "" || strings.Contains(userInput, phone) {
+		t.Fatalf("expected phone to be redacted, got %q", userInput)
+	}
+	// 在字符边界处把占位符拆到两个增量里，模拟模型逐 token 输出。
+	splitAt := len(string([]rune(placeholder)[:3]))
+
+	var requestBody string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		raw, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Fatalf("read request body failed: %v", err)
+		}
+		requestBody = string(raw)
+		writeSSEResponse(t, w,
+			streamContentChunk(t, "已记录"+placeholder[:splitAt]),
+			streamContentChunk(t, placeholder[splitAt:]+"，请注意"),
+		)
+	}))
+	defer server.Close()
+
+	svc := chatservice.NewChatService(&appcfg.ChatConfig{BaseURL: server.URL, Model: "test-model"})
+	ctx := redaction.WithRedactor(context.Background(), redactor)
+
+	emitted := make([]string, 0, 2)
+	reply, turnMessages, err := svc.StreamReply(ctx, "u1", userInput, nil, []openai.ChatCompletionMessage{
+		{Role: openai.ChatMessageRoleSystem, Content: "sys"},
+		{Role: openai.ChatMessageRoleUser, Content: userInput},
+	}, func(event map[string]interface{}) error {
+		if event["type"] == "content" {
+			emitted = append(emitted, event["content"].(string))
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("StreamReply failed: %v", err)
+	}
+
+	if strings.Contains(requestBody, phone) {
+		t.Fatalf("model request should only contain placeholders, got %s", requestBody)
+	}
+	for _, chunk := range emitted {
+		if strings.Contains(chunk, "[") {
+			t.Fatalf("emitted chunk should not leak a placeholder fragment: %q", chunk)
+		}
+	}
+	if got := strings.Join(emitted, ""); got != "已记录"+phone+"，请注意" {
+		t.Fatalf("unexpected emitted content: %q", got)
+	}
+	if reply != "已记录"+placeholder+"，请注意" {
+		t.Fatalf("reply should keep placeholders for persistence, got %q", reply)
+	}
+	for _, message := range turnMessages {
+		if strings.Contains(message.Content, phone) {
+			t.Fatalf("turn messages should keep placeholders, got %+v", message)
+		}
+	}
+}
+
+func streamContentChunk(t *testing.T, content string) string {
+	t.Helper()
+	raw, err := json.Marshal(map[string]interface{}{
+		"choices": []map[string]interface{}{
+			{"index": 0, "delta": map[string]interface{}{"content": content}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("encode stream chunk failed: %v", err)
+	}
+	return string(raw)
+}
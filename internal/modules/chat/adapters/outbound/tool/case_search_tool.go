@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log"
 	"strings"
 
 	"antifraud/internal/modules/multi_agent/adapters/outbound/case_library"
@@ -24,7 +25,7 @@ var ChatSearchSimilarCasesTool = openai.Tool{
 	Type: openai.ToolTypeFunction,
 	Function: &openai.FunctionDefinition{
 		Name:        ChatSearchSimilarCasesToolName,
-		Description: "根据查询语句检索历史案件库中的相似案件，返回按相似度排序的结果。",
+		Description: "根据查询语句检索历史案件库中的相似案件。检索同时使用语义向量与关键词（话术原文、账号、App 名称等）两路召回并融合排序，返回按融合得分排序的结果。",
 		Parameters: map[string]interface{}{
 			"type": "object",
 			"properties": map[string]interface{}{
@@ -36,8 +37,8 @@ var ChatSearchSimilarCasesTool = openai.Tool{
 					"type":        "integer",
 					"description": "返回结果数量，默认 5，最大 20。",
 				},
-				"target_group": buildChatTargetGroupSchema("可选，按目标人群精确过滤后再做向量召回。必须来自 config/target_groups.json 配置。"),
-				"scam_type":    buildChatScamTypeSchema("可选，按诈骗类型精确过滤后再做向量召回。必须来自 config/scam_types.json 配置。"),
+				"target_group": buildChatTargetGroupSchema("可选，按目标人群精确过滤后再做召回。必须来自 config/target_groups.json 配置。"),
+				"scam_type":    buildChatScamTypeSchema("可选，按诈骗类型精确过滤后再做召回。必须来自 config/scam_types.json 配置。"),
 			},
 			"required": []string{"query"},
 		},
@@ -141,17 +142,24 @@ func SearchSimilarCasesForChatWithFilters(ctx context.Context, query string, top
 		return nil, 0, fmt.Errorf("query is empty")
 	}
 
-	queryVector, _, err := embedding.GenerateVector(ctx, trimmedQuery)
+	// 向量化失败时不中断检索，退化为仅关键词通道召回。
+	queryVector, queryModel, err := embedding.GenerateVector(ctx, trimmedQuery)
 	if err != nil {
-		return nil, 0, err
-	}
-
-	results, appliedTopK, err := case_library.SearchTopKSimilarCasesByVectorWithConditions(
-		queryVector,
-		topK,
-		strings.TrimSpace(targetGroup),
-		strings.TrimSpace(scamType),
-	)
+		log.Printf("[chat] embed case search query failed, fallback to lexical recall only: %v", err)
+		queryVector = nil
+		queryModel = ""
+	}
+
+	results, appliedTopK, err := case_library.SearchHybrid(case_library.HybridSearchQuery{
+		Text:   trimmedQuery,
+		Vector: queryVector,
+		TopK:   topK,
+		Filter: case_library.SimilarCaseRecallFilter{
+			TargetGroup:    strings.TrimSpace(targetGroup),
+			ScamType:       strings.TrimSpace(scamType),
+			EmbeddingModel: queryModel,
+		},
+	})
 	if err != nil {
 		return nil, appliedTopK, err
 	}
@@ -169,10 +177,12 @@ func SearchSimilarCasesForChatWithFilters(ctx context.Context, query string, top
 		description := noneFallback(item.CaseDescription)
 
 		cases = append(cases, fmt.Sprintf(
-			"TOP%d | case_id:%s | score:%.4f | title:%s | target_group:%s | risk:%s | scam_type:%s | keywords:%s | description:%s | violated_law:%s",
+			"TOP%d | case_id:%s | score:%.4f | similarity:%.4f | matched_by:%s | title:%s | target_group:%s | risk:%s | scam_type:%s | keywords:%s | description:%s | violated_law:%s",
 			index+1,
 			item.CaseID,
+			item.FusionScore,
 			item.Similarity,
+			describeHybridMatch(item),
 			item.Title,
 			item.TargetGroup,
 			item.RiskLevel,
@@ -192,3 +202,26 @@ func noneFallback(text string) string {
 	}
 	return trimmed
 }
+
+// describeHybridMatch 描述案件由哪些召回通道命中，例如 "vector#1+keyword#3(转账,安全账户)"。
+func describeHybridMatch(item case_library.HybridCaseResult) string {
+	parts := make([]string, 0, 2)
+	if item.VectorRank > 0 {
+		parts = append(parts, fmt.Sprintf("vector#%d", item.VectorRank))
+	}
+	if item.LexicalRank > 0 {
+		keyword := fmt.Sprintf("keyword#%d", item.LexicalRank)
+		if len(item.MatchedTerms) > 0 {
+			terms := item.MatchedTerms
+			if len(terms) > 5 {
+				terms = terms[:5]
+			}
+			keyword += "(" + strings.Join(terms, ",") + ")"
+		}
+		parts = append(parts, keyword)
+	}
+	if len(parts) == 0 {
+		return "none"
+	}
+	return strings.Join(parts, "+")
+}
@@ -7,6 +7,7 @@ import (
 
 	appcfg "antifraud/internal/platform/config"
 	openai "antifraud/internal/platform/llm"
+	"antifraud/internal/platform/redaction"
 	websearch_system "antifraud/internal/platform/websearch"
 )
 
@@ -27,7 +28,7 @@ var ChatWebSearchTool = openai.Tool{
 	Type: openai.ToolTypeFunction,
 	Function: &openai.FunctionDefinition{
 		Name:        ChatWebSearchToolName,
-		Description: "使用 Tavily 执行联网搜索，返回摘要答案和相关网页结果。",
+		Description: "执行联网搜索，返回摘要答案和相关网页结果。",
 		Parameters: map[string]interface{}{
 			"type": "object",
 			"properties": map[string]interface{}{
@@ -46,8 +47,10 @@ var ChatWebSearchTool = openai.Tool{
 }
 
 var loadChatWebSearchConfig = appcfg.LoadConfig
-var newChatWebSearcher = func(cfg appcfg.TavilyConfig) websearch_system.Searcher {
-	return websearch_system.NewTavilyClient(cfg)
+
+// newChatWebSearcher 按 web_search.provider 创建搜索器。
+var newChatWebSearcher = func(cfg appcfg.Config) (websearch_system.Searcher, error) {
+	return websearch_system.NewSearcher(cfg)
 }
 
 func ParseChatWebSearchInput(arguments string) (ChatWebSearchInput, error) {
@@ -59,7 +62,8 @@ func ExecuteChatWebSearch(ctx context.Context, searcher websearch_system.Searche
 		return nil, fmt.Errorf("web searcher is nil")
 	}
 
-	trimmedQuery := strings.TrimSpace(input.Query)
+	// 搜索关键词发往第三方搜索服务，先不可逆地移除其中的个人信息。
+	trimmedQuery := strings.TrimSpace(redaction.Strip(input.Query))
 	if trimmedQuery == "" {
 		return nil, fmt.Errorf("query is empty")
 	}
@@ -77,13 +81,17 @@ func ExecuteChatWebSearch(ctx context.Context, searcher websearch_system.Searche
 		return nil, err
 	}
 
-	return map[string]interface{}{
+	payload := map[string]interface{}{
 		"query":       result.Query,
 		"answer":      result.Answer,
 		"max_results": resolvedMaxResults,
 		"results":     result.Results,
 		"status":      "success",
-	}, nil
+	}
+	if result.Provider != "" {
+		payload["provider"] = result.Provider
+	}
+	return payload, nil
 }
 
 type ChatWebSearchHandler struct{}
@@ -108,7 +116,16 @@ func (h *ChatWebSearchHandler) Handle(ctx context.Context, userID string, args s
 		}}, nil
 	}
 
-	payload, err := ExecuteChatWebSearch(ctx, newChatWebSearcher(cfg.Tavily), input)
+	searcher, err := newChatWebSearcher(*cfg)
+	if err != nil {
+		return ChatToolResponse{Payload: map[string]interface{}{
+			"query":  strings.TrimSpace(input.Query),
+			"status": "failed",
+			"error":  err.Error(),
+		}}, nil
+	}
+
+	payload, err := ExecuteChatWebSearch(ctx, searcher, input)
 	if err != nil {
 		return ChatToolResponse{Payload: map[string]interface{}{
 			"query":  strings.TrimSpace(input.Query),
@@ -7,6 +7,7 @@ import (
 	chatservice "antifraud/internal/modules/chat/adapters/outbound/service"
 	appcfg "antifraud/internal/platform/config"
 	openai "antifraud/internal/platform/llm"
+	"antifraud/internal/platform/redaction"
 )
 
 const defaultConfigPath = "internal/platform/config/config.json"
@@ -36,6 +37,8 @@ type ConversationStore interface {
 	Persist(userID string, newMessages []chatservice.ConversationMessage) error
 	Clear(userID string) error
 	GetContext(userID string) ([]chatservice.ConversationMessage, int64, bool, error)
+	LoadRedactionMapping(userID string) (map[string]string, error)
+	SaveRedactionMapping(userID string, mapping map[string]string) error
 }
 
 // UseCase 负责编排聊天请求。
@@ -83,6 +86,7 @@ func NewAdminUseCase(configPath string) *UseCase {
 }
 
 // HandleChat 执行一轮聊天请求。
+// 用户消息在组装上下文前按会话映射脱敏，模型与工具只看到占位符；返回与推送给用户的回复会还原原文。
 func (u *UseCase) HandleChat(ctx context.Context, userID string, message string, images []string, emit func(event map[string]interface{}) error) (string, error) {
 	if u == nil {
 		return "", fmt.Errorf("chat use case is unavailable")
@@ -96,6 +100,14 @@ func (u *UseCase) HandleChat(ctx context.Context, userID string, message string,
 		return "", err
 	}
 
+	mapping, err := u.conversationStore.LoadRedactionMapping(userID)
+	if err != nil {
+		return "", err
+	}
+	redactor := redaction.NewRedactor(mapping)
+	ctx = redaction.WithRedactor(ctx, redactor)
+	message = redactor.Redact(message)
+
 	messages, err := u.messageBuilder.Build(cfg.Prompt, userID, message, images)
 	if err != nil {
 		return "", err
@@ -110,10 +122,14 @@ func (u *UseCase) HandleChat(ctx context.Context, userID string, message string,
 	if err != nil {
 		return "", err
 	}
+	// 先保存映射再持久化会话，保证已落盘的占位符都能被还原。
+	if err := u.conversationStore.SaveRedactionMapping(userID, redactor.Mapping()); err != nil {
+		return "", err
+	}
 	if err := u.conversationStore.Persist(userID, turnMessages); err != nil {
 		return "", err
 	}
-	return reply, nil
+	return redactor.Restore(reply), nil
 }
 
 // RefreshConversation 清空指定用户对话上下文。
@@ -124,12 +140,26 @@ func (u *UseCase) RefreshConversation(userID string) error {
 	return u.conversationStore.Clear(userID)
 }
 
-// GetConversationContext 查询指定用户对话上下文。
+// GetConversationContext 查询指定用户对话上下文，消息内容中的占位符按会话映射还原后返回。
 func (u *UseCase) GetConversationContext(userID string) ([]chatservice.ConversationMessage, int64, bool, error) {
 	if u == nil || u.conversationStore == nil {
 		return nil, 0, false, fmt.Errorf("chat use case is unavailable")
 	}
-	return u.conversationStore.GetContext(userID)
+	history, ttlSeconds, found, err := u.conversationStore.GetContext(userID)
+	if err != nil || !found {
+		return history, ttlSeconds, found, err
+	}
+	mapping, err := u.conversationStore.LoadRedactionMapping(userID)
+	if err != nil {
+		return nil, 0, false, err
+	}
+	redactor := redaction.NewRedactor(mapping)
+	restored := make([]chatservice.ConversationMessage, len(history))
+	for i, item := range history {
+		item.Content = redactor.Restore(item.Content)
+		restored[i] = item
+	}
+	return restored, ttlSeconds, found, nil
 }
 
 type fileConfigProvider struct {
@@ -171,6 +201,14 @@ func (defaultConversationStore) GetContext(userID string) ([]chatservice.Convers
 	return chatservice.GetConversationContext(userID)
 }
 
+func (defaultConversationStore) LoadRedactionMapping(userID string) (map[string]string, error) {
+	return chatservice.LoadConversationRedactionMapping(chatservice.DefaultConversationKeyPrefix, userID)
+}
+
+func (defaultConversationStore) SaveRedactionMapping(userID string, mapping map[string]string) error {
+	return chatservice.SaveConversationRedactionMapping(chatservice.DefaultConversationKeyPrefix, userID, mapping)
+}
+
 type adminConfigProvider struct {
 	path string
 }
@@ -209,3 +247,11 @@ func (adminConversationStore) Clear(userID string) error {
 func (adminConversationStore) GetContext(userID string) ([]chatservice.ConversationMessage, int64, bool, error) {
 	return chatservice.GetConversationContextWithPrefix(chatservice.AdminConversationKeyPrefix, userID)
 }
+
+func (adminConversationStore) LoadRedactionMapping(userID string) (map[string]string, error) {
+	return chatservice.LoadConversationRedactionMapping(chatservice.AdminConversationKeyPrefix, userID)
+}
+
+func (adminConversationStore) SaveRedactionMapping(userID string, mapping map[string]string) error {
+	return chatservice.SaveConversationRedactionMapping(chatservice.AdminConversationKeyPrefix, userID, mapping)
+}
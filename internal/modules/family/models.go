@@ -95,6 +95,8 @@ type FamilyNotificationEntity struct {
 	ScamType       string     `gorm:"size:64;index"`
 	RiskLevel      string     `gorm:"size:32;index"`
 	Summary        string     `gorm:"type:text;not null"`
+	RiskHeadline   string     `gorm:"type:text"`
+	RiskReasons    string     `gorm:"type:text"`
 	EventAt        time.Time  `gorm:"index;not null"`
 	ReadAt         *time.Time `gorm:"index"`
 }
@@ -211,20 +213,22 @@ type FamilyGuardianLinkView struct {
 
 // FamilyNotificationView 是家庭通知返回结构。
 type FamilyNotificationView struct {
-	ID             uint   `json:"id"`
-	FamilyID       uint   `json:"family_id"`
-	TargetUserID   uint   `json:"target_user_id"`
-	TargetName     string `json:"target_name"`
-	ReceiverUserID uint   `json:"receiver_user_id"`
-	EventType      string `json:"event_type"`
-	RecordID       string `json:"record_id"`
-	Title          string `json:"title"`
-	CaseSummary    string `json:"case_summary"`
-	ScamType       string `json:"scam_type,omitempty"`
-	RiskLevel      string `json:"risk_level,omitempty"`
-	Summary        string `json:"summary"`
-	EventAt        string `json:"event_at"`
-	ReadAt         string `json:"read_at,omitempty"`
+	ID             uint     `json:"id"`
+	FamilyID       uint     `json:"family_id"`
+	TargetUserID   uint     `json:"target_user_id"`
+	TargetName     string   `json:"target_name"`
+	ReceiverUserID uint     `json:"receiver_user_id"`
+	EventType      string   `json:"event_type"`
+	RecordID       string   `json:"record_id"`
+	Title          string   `json:"title"`
+	CaseSummary    string   `json:"case_summary"`
+	ScamType       string   `json:"scam_type,omitempty"`
+	RiskLevel      string   `json:"risk_level,omitempty"`
+	Summary        string   `json:"summary"`
+	RiskHeadline   string   `json:"risk_headline,omitempty"`
+	RiskReasons    []string `json:"risk_reasons,omitempty"`
+	EventAt        string   `json:"event_at"`
+	ReadAt         string   `json:"read_at,omitempty"`
 }
 
 // FamilyOverviewResponse 是家庭中心总览返回结构。
@@ -245,5 +249,8 @@ type RiskEvent struct {
 	CaseSummary  string
 	ScamType     string
 	RiskLevel    string
+	// RiskHeadline/RiskReasons 为风险等级解释摘要，由调用方生成后随通知下发给守护人。
+	RiskHeadline string
+	RiskReasons  []string
 	CreatedAt    time.Time
 }
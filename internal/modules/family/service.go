@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"strings"
@@ -598,6 +599,7 @@ func (s *Service) HandleRiskEvent(ctx context.Context, event RiskEvent) error {
 	}
 
 	summary := fmt.Sprintf("家庭成员 %s 触发高风险案件，请及时核查。", strings.TrimSpace(targetUser.Username))
+	riskReasons := encodeRiskReasons(event.RiskReasons)
 	for _, link := range links {
 		entity := FamilyNotificationEntity{
 			FamilyID:       targetMember.FamilyID,
@@ -610,6 +612,8 @@ func (s *Service) HandleRiskEvent(ctx context.Context, event RiskEvent) error {
 			ScamType:       strings.TrimSpace(event.ScamType),
 			RiskLevel:      "高",
 			Summary:        summary,
+			RiskHeadline:   strings.TrimSpace(event.RiskHeadline),
+			RiskReasons:    riskReasons,
 			EventAt:        event.CreatedAt,
 		}
 		if err := s.db.WithContext(ctx).Where(
@@ -625,6 +629,36 @@ func (s *Service) HandleRiskEvent(ctx context.Context, event RiskEvent) error {
 	return nil
 }
 
+// encodeRiskReasons 将风险解释理由编码为 JSON 数组字符串，空列表存空串。
+func encodeRiskReasons(reasons []string) string {
+	cleaned := make([]string, 0, len(reasons))
+	for _, reason := range reasons {
+		if trimmed := strings.TrimSpace(reason); trimmed != "" {
+			cleaned = append(cleaned, trimmed)
+		}
+	}
+	if len(cleaned) == 0 {
+		return ""
+	}
+	encoded, err := json.Marshal(cleaned)
+	if err != nil {
+		return ""
+	}
+	return string(encoded)
+}
+
+func decodeRiskReasons(value string) []string {
+	trimmed := strings.TrimSpace(value)
+	if trimmed == "" {
+		return nil
+	}
+	reasons := make([]string, 0)
+	if err := json.Unmarshal([]byte(trimmed), &reasons); err != nil {
+		return nil
+	}
+	return reasons
+}
+
 func (s *Service) ensureReady() error {
 	if s == nil || s.db == nil {
 		return fmt.Errorf("family system service is unavailable")
@@ -824,6 +858,8 @@ func (s *Service) buildNotificationViews(ctx context.Context, rows []FamilyNotif
 			ScamType:       strings.TrimSpace(row.ScamType),
 			RiskLevel:      strings.TrimSpace(row.RiskLevel),
 			Summary:        strings.TrimSpace(row.Summary),
+			RiskHeadline:   strings.TrimSpace(row.RiskHeadline),
+			RiskReasons:    decodeRiskReasons(row.RiskReasons),
 			EventAt:        row.EventAt.Format(time.RFC3339),
 		}
 		if row.ReadAt != nil {
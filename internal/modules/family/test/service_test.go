@@ -416,6 +416,8 @@ func TestHighRiskEventCreatesNotification(t *testing.T) {
 		CaseSummary:  "存在高风险诈骗迹象",
 		ScamType:     "冒充客服类",
 		RiskLevel:    "高",
+		RiskHeadline: "判定为高风险：调整后得分 84，高出动态阈值 39 分。",
+		RiskReasons:  []string{"同时出现「转账+私人账户收款」，触发组合保底规则。", " ", "命中风险因子「要求转账/充值」（索要动作 +20）。"},
 		CreatedAt:    time.Now(),
 	})
 	if err != nil {
@@ -432,6 +434,9 @@ func TestHighRiskEventCreatesNotification(t *testing.T) {
 	if notifications[0].TargetUserID != member.ID {
 		t.Fatalf("unexpected notification target: %+v", notifications[0])
 	}
+	if notifications[0].RiskHeadline == "" || len(notifications[0].RiskReasons) != 2 {
+		t.Fatalf("expected risk explanation on notification, got: %+v", notifications[0])
+	}
 }
 
 func TestRemoveMemberClearsRelatedNotifications(t *testing.T) {
@@ -0,0 +1,47 @@
+package httpapi
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	apimodel "antifraud/internal/modules/multi_agent/adapters/inbound/http/models"
+	"antifraud/internal/modules/multi_agent/adapters/outbound/case_library"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetCasesByCitationHandle 按来源链接或域名查找引用了该来源的历史案件与待审核案件，用于核实来源或处理下架请求。
+func GetCasesByCitationHandle(c *gin.Context) {
+	source := strings.TrimSpace(c.Query("source"))
+	if source == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "source 不能为空"})
+		return
+	}
+
+	matches, err := defaultCaseLibraryService.FindCasesByCitation(source)
+	if err != nil {
+		if case_library.IsValidationError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "source 需为 http/https 链接或域名"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "来源引用查询失败: " + err.Error()})
+		return
+	}
+
+	items := make([]apimodel.CitationMatchItem, 0, len(matches))
+	for _, match := range matches {
+		items = append(items, apimodel.CitationMatchItem{
+			Kind:      match.Kind,
+			ID:        match.ID,
+			Title:     match.Title,
+			CreatedAt: match.CreatedAt.Format(time.RFC3339),
+			Citations: toCaseCitationItems(match.Citations),
+		})
+	}
+	c.JSON(http.StatusOK, apimodel.CitationMatchResponse{
+		Source: source,
+		Total:  len(items),
+		Cases:  items,
+	})
+}
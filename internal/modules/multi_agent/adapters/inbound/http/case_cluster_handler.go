@@ -0,0 +1,173 @@
+package httpapi
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	apimodel "antifraud/internal/modules/multi_agent/adapters/inbound/http/models"
+	"antifraud/internal/modules/multi_agent/adapters/outbound/case_library"
+	"antifraud/internal/modules/multi_agent/application/casecluster"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StartCaseClusterJobHandle 启动近似案件聚类任务（管理员）。
+func StartCaseClusterJobHandle(c *gin.Context) {
+	var payload apimodel.StartCaseClusterJobRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误: " + err.Error()})
+		return
+	}
+
+	job, err := casecluster.DefaultService().Start(getCurrentUserID(c), casecluster.Options{
+		Threshold:     payload.Threshold,
+		KeywordWeight: payload.KeywordWeight,
+		ScamType:      payload.ScamType,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, casecluster.ErrJobRunning):
+			c.JSON(http.StatusConflict, gin.H{"error": "已有进行中的近似案件聚类任务"})
+		case errors.Is(err, casecluster.ErrInvalidOptions):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "聚类参数无效: " + err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "启动近似案件聚类失败: " + err.Error()})
+		}
+		return
+	}
+	c.JSON(http.StatusAccepted, apimodel.CaseClusterJobResponse{Job: toCaseClusterJobItem(job)})
+}
+
+// ListCaseClusterJobsHandle 返回最近的近似案件聚类任务，不含聚类明细（管理员）。
+func ListCaseClusterJobsHandle(c *gin.Context) {
+	jobs, err := casecluster.DefaultService().List(20)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询近似案件聚类任务失败: " + err.Error()})
+		return
+	}
+	items := make([]apimodel.CaseClusterJobItem, 0, len(jobs))
+	for _, job := range jobs {
+		items = append(items, toCaseClusterJobItem(job))
+	}
+	c.JSON(http.StatusOK, apimodel.CaseClusterJobListResponse{Jobs: items})
+}
+
+// GetCaseClusterJobHandle 返回指定聚类任务及其聚类结果（管理员）。
+func GetCaseClusterJobHandle(c *gin.Context) {
+	jobID := strings.TrimSpace(c.Param("jobId"))
+	if jobID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "jobId 不能为空"})
+		return
+	}
+	job, err := casecluster.DefaultService().Get(jobID)
+	if err != nil {
+		if errors.Is(err, casecluster.ErrJobNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "近似案件聚类任务不存在"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询近似案件聚类任务失败: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, apimodel.CaseClusterJobResponse{Job: toCaseClusterJobItem(job)})
+}
+
+// MergeHistoricalCasesHandle 把近似案件合并进路径中的保留案件：话术、关键词与来源引用取并集，
+// 被合并案件删除并跳转到保留案件（管理员）。
+func MergeHistoricalCasesHandle(c *gin.Context) {
+	caseID := strings.TrimSpace(c.Param("caseId"))
+	if caseID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "caseId 不能为空"})
+		return
+	}
+	var payload apimodel.MergeHistoricalCasesRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误: " + err.Error()})
+		return
+	}
+
+	record, exists, err := defaultCaseLibraryService.MergeHistoricalCases(c.Request.Context(), getCurrentUserID(c), caseID, payload.Revision, payload.MergedCaseIDs)
+	if err != nil {
+		switch {
+		case errors.Is(err, case_library.ErrHistoricalCaseRevisionConflict):
+			c.JSON(http.StatusConflict, gin.H{"error": "历史案件已被他人修改，请刷新后重试"})
+		case case_library.IsValidationError(err):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			writeHistoricalCaseWriteError(c, err, "历史案件合并失败: ")
+		}
+		return
+	}
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "历史案件不存在"})
+		return
+	}
+	c.JSON(http.StatusOK, buildHistoricalCaseDetailResponse(record, ""))
+}
+
+// buildHistoricalCaseDetailResponse 组装案件详情响应，并附带已并入该案件的旧案件列表。
+func buildHistoricalCaseDetailResponse(record case_library.HistoricalCaseRecord, requestedCaseID string) apimodel.HistoricalCaseDetailResponse {
+	response := apimodel.HistoricalCaseDetailResponse{Case: toHistoricalCaseDetailItem(record)}
+	if requestedCaseID != "" && requestedCaseID != record.CaseID {
+		response.RedirectedFrom = requestedCaseID
+	}
+	redirects, err := defaultCaseLibraryService.ListMergedHistoricalCases(record.CaseID)
+	if err != nil {
+		log.Printf("[case_library] list merged historical cases failed: case_id=%s err=%v", record.CaseID, err)
+		return response
+	}
+	for _, redirect := range redirects {
+		response.MergedFrom = append(response.MergedFrom, apimodel.MergedHistoricalCaseItem{
+			CaseID:   redirect.FromCaseID,
+			Title:    redirect.FromTitle,
+			MergedBy: redirect.MergedBy,
+			MergedAt: redirect.CreatedAt.Format(time.RFC3339),
+		})
+	}
+	return response
+}
+
+func toCaseClusterJobItem(job casecluster.Job) apimodel.CaseClusterJobItem {
+	item := apimodel.CaseClusterJobItem{
+		JobID:          job.JobID,
+		Status:         job.Status,
+		Threshold:      job.Options.Threshold,
+		KeywordWeight:  job.Options.KeywordWeight,
+		ScamType:       job.Options.ScamType,
+		EmbeddingModel: job.EmbeddingModel,
+		ScannedCases:   job.ScannedCases,
+		SkippedCases:   job.SkippedCases,
+		ComparedPairs:  job.ComparedPairs,
+		ClusterCount:   job.ClusterCount,
+		LastError:      job.LastError,
+		CreatedBy:      job.CreatedBy,
+		StartedAt:      job.StartedAt.Format(time.RFC3339),
+		UpdatedAt:      job.UpdatedAt.Format(time.RFC3339),
+	}
+	if job.FinishedAt != nil {
+		item.FinishedAt = job.FinishedAt.Format(time.RFC3339)
+	}
+	for _, cluster := range job.Clusters {
+		members := make([]apimodel.CaseClusterMemberItem, 0, len(cluster.Members))
+		for _, member := range cluster.Members {
+			members = append(members, apimodel.CaseClusterMemberItem{
+				CaseID:            member.CaseID,
+				Title:             member.Title,
+				ScamType:          member.ScamType,
+				CreatedAt:         member.CreatedAt.Format(time.RFC3339),
+				AverageSimilarity: member.AverageSimilarity,
+				MergedInto:        member.MergedInto,
+			})
+		}
+		item.Clusters = append(item.Clusters, apimodel.CaseClusterItem{
+			ClusterID:            cluster.ClusterID,
+			SuggestedCanonicalID: cluster.SuggestedCanonicalID,
+			MinSimilarity:        cluster.MinSimilarity,
+			MaxSimilarity:        cluster.MaxSimilarity,
+			Members:              members,
+		})
+	}
+	return item
+}
@@ -0,0 +1,219 @@
+package httpapi
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	apimodel "antifraud/internal/modules/multi_agent/adapters/inbound/http/models"
+	"antifraud/internal/modules/multi_agent/application/casecollection"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListCaseCollectionCampaignsHandle 返回全部定时采集计划（管理员）。
+func ListCaseCollectionCampaignsHandle(c *gin.Context) {
+	campaigns, err := casecollection.DefaultService().ListCampaigns()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询定时采集计划失败: " + err.Error()})
+		return
+	}
+	items := make([]apimodel.CaseCollectionCampaignItem, 0, len(campaigns))
+	for _, campaign := range campaigns {
+		items = append(items, toCaseCollectionCampaignItem(campaign))
+	}
+	c.JSON(http.StatusOK, apimodel.CaseCollectionCampaignListResponse{Campaigns: items})
+}
+
+// CreateCaseCollectionCampaignHandle 创建定时采集计划，enabled 缺省为 true（管理员）。
+func CreateCaseCollectionCampaignHandle(c *gin.Context) {
+	var payload apimodel.CaseCollectionCampaignRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误: " + err.Error()})
+		return
+	}
+	enabled := true
+	if payload.Enabled != nil {
+		enabled = *payload.Enabled
+	}
+
+	campaign, err := casecollection.DefaultService().CreateCampaign(getCurrentUserID(c), casecollection.CampaignInput{
+		Name:           payload.Name,
+		QueryTemplates: payload.QueryTemplates,
+		Cron:           payload.Cron,
+		TargetCount:    payload.TargetCount,
+		ScamTypeFocus:  payload.ScamTypeFocus,
+		Enabled:        enabled,
+	})
+	if err != nil {
+		writeCaseCollectionCampaignError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, apimodel.CaseCollectionCampaignResponse{Campaign: toCaseCollectionCampaignItem(campaign)})
+}
+
+// GetCaseCollectionCampaignHandle 返回指定定时采集计划（管理员）。
+func GetCaseCollectionCampaignHandle(c *gin.Context) {
+	campaignID := strings.TrimSpace(c.Param("campaignId"))
+	if campaignID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "campaignId 不能为空"})
+		return
+	}
+	campaign, err := casecollection.DefaultService().GetCampaign(campaignID)
+	if err != nil {
+		writeCaseCollectionCampaignError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, apimodel.CaseCollectionCampaignResponse{Campaign: toCaseCollectionCampaignItem(campaign)})
+}
+
+// PatchCaseCollectionCampaignHandle 修改定时采集计划，仅修改请求中出现的字段（管理员）。
+func PatchCaseCollectionCampaignHandle(c *gin.Context) {
+	campaignID := strings.TrimSpace(c.Param("campaignId"))
+	if campaignID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "campaignId 不能为空"})
+		return
+	}
+	var payload apimodel.PatchCaseCollectionCampaignRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误: " + err.Error()})
+		return
+	}
+
+	campaign, err := casecollection.DefaultService().UpdateCampaign(campaignID, getCurrentUserID(c), casecollection.CampaignPatch{
+		Name:           payload.Name,
+		QueryTemplates: payload.QueryTemplates,
+		Cron:           payload.Cron,
+		TargetCount:    payload.TargetCount,
+		ScamTypeFocus:  payload.ScamTypeFocus,
+		Enabled:        payload.Enabled,
+	})
+	if err != nil {
+		writeCaseCollectionCampaignError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, apimodel.CaseCollectionCampaignResponse{Campaign: toCaseCollectionCampaignItem(campaign)})
+}
+
+// DeleteCaseCollectionCampaignHandle 删除定时采集计划及其运行历史（管理员）。
+func DeleteCaseCollectionCampaignHandle(c *gin.Context) {
+	campaignID := strings.TrimSpace(c.Param("campaignId"))
+	if campaignID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "campaignId 不能为空"})
+		return
+	}
+	if err := casecollection.DefaultService().DeleteCampaign(campaignID); err != nil {
+		writeCaseCollectionCampaignError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "定时采集计划已删除"})
+}
+
+// RunCaseCollectionCampaignHandle 立即触发一次定时采集计划（管理员）。
+func RunCaseCollectionCampaignHandle(c *gin.Context) {
+	campaignID := strings.TrimSpace(c.Param("campaignId"))
+	if campaignID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "campaignId 不能为空"})
+		return
+	}
+	run, err := casecollection.DefaultService().RunCampaignNow(campaignID, getCurrentUserID(c))
+	if err != nil {
+		writeCaseCollectionCampaignError(c, err)
+		return
+	}
+	c.JSON(http.StatusAccepted, apimodel.CaseCollectionCampaignRunResponse{Message: "定时采集计划已触发", Run: toCaseCollectionCampaignRunItem(run)})
+}
+
+// ListCaseCollectionCampaignRunsHandle 返回定时采集计划的运行历史（管理员）。
+func ListCaseCollectionCampaignRunsHandle(c *gin.Context) {
+	campaignID := strings.TrimSpace(c.Param("campaignId"))
+	if campaignID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "campaignId 不能为空"})
+		return
+	}
+	limit := 0
+	if raw := strings.TrimSpace(c.Query("limit")); raw != "" {
+		value, err := strconv.Atoi(raw)
+		if err != nil || value < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit 必须为正整数"})
+			return
+		}
+		limit = value
+	}
+	runs, err := casecollection.DefaultService().ListCampaignRuns(campaignID, limit)
+	if err != nil {
+		writeCaseCollectionCampaignError(c, err)
+		return
+	}
+	items := make([]apimodel.CaseCollectionCampaignRunItem, 0, len(runs))
+	for _, run := range runs {
+		items = append(items, toCaseCollectionCampaignRunItem(run))
+	}
+	c.JSON(http.StatusOK, apimodel.CaseCollectionCampaignRunListResponse{Runs: items})
+}
+
+func writeCaseCollectionCampaignError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, casecollection.ErrCampaignNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "定时采集计划不存在"})
+	case errors.Is(err, casecollection.ErrCampaignRunning):
+		c.JSON(http.StatusConflict, gin.H{"error": "定时采集计划上一轮任务仍在运行"})
+	case errors.Is(err, casecollection.ErrInvalidCampaign):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "定时采集计划参数错误: " + err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "定时采集计划操作失败: " + err.Error()})
+	}
+}
+
+func toCaseCollectionCampaignItem(campaign casecollection.Campaign) apimodel.CaseCollectionCampaignItem {
+	item := apimodel.CaseCollectionCampaignItem{
+		CampaignID:     campaign.CampaignID,
+		Name:           campaign.Name,
+		QueryTemplates: append([]string{}, campaign.QueryTemplates...),
+		Cron:           campaign.Cron,
+		TargetCount:    campaign.TargetCount,
+		ScamTypeFocus:  campaign.ScamTypeFocus,
+		Enabled:        campaign.Enabled,
+		CreatedBy:      campaign.CreatedBy,
+		UpdatedBy:      campaign.UpdatedBy,
+		CreatedAt:      campaign.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:      campaign.UpdatedAt.Format(time.RFC3339),
+	}
+	if campaign.NextRunAt != nil {
+		item.NextRunAt = campaign.NextRunAt.Format(time.RFC3339)
+	}
+	if campaign.LastRunAt != nil {
+		item.LastRunAt = campaign.LastRunAt.Format(time.RFC3339)
+	}
+	return item
+}
+
+func toCaseCollectionCampaignRunItem(run casecollection.CampaignRun) apimodel.CaseCollectionCampaignRunItem {
+	queries := make([]apimodel.CaseCollectionCampaignRunQueryItem, 0, len(run.Queries))
+	for _, query := range run.Queries {
+		item := apimodel.CaseCollectionCampaignRunQueryItem{
+			Template:   query.Template,
+			Query:      query.Query,
+			JobID:      query.JobID,
+			SkipReason: query.SkipReason,
+		}
+		if query.Job != nil {
+			job := toCaseCollectionJobItem(*query.Job)
+			item.Job = &job
+		}
+		queries = append(queries, item)
+	}
+	return apimodel.CaseCollectionCampaignRunItem{
+		RunID:       run.RunID,
+		CampaignID:  run.CampaignID,
+		Trigger:     run.Trigger,
+		Status:      run.Status,
+		SkipReason:  run.SkipReason,
+		Queries:     queries,
+		TriggeredBy: run.TriggeredBy,
+		ScheduledAt: run.ScheduledAt.Format(time.RFC3339),
+		CreatedAt:   run.CreatedAt.Format(time.RFC3339),
+	}
+}
@@ -1,10 +1,14 @@
 package httpapi
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	apimodel "antifraud/internal/modules/multi_agent/adapters/inbound/http/models"
+	"antifraud/internal/modules/multi_agent/application/casecollection"
 	"antifraud/internal/modules/multi_agent/application/queue"
 
 	"github.com/gin-gonic/gin"
@@ -12,20 +16,18 @@ import (
 
 // CaseCollectionEnqueuer 定义案件采集处理器依赖的后台入队接口。
 type CaseCollectionEnqueuer interface {
-	EnqueueCaseCollectionTask(userID string, request queue.CaseCollectionEnqueueRequest) error
+	EnqueueCaseCollectionTask(userID string, request queue.CaseCollectionEnqueueRequest) (casecollection.Job, error)
 }
 
-type caseCollectionEnqueuerFunc func(userID string, request queue.CaseCollectionEnqueueRequest) error
+type caseCollectionEnqueuerFunc func(userID string, request queue.CaseCollectionEnqueueRequest) (casecollection.Job, error)
 
-func (f caseCollectionEnqueuerFunc) EnqueueCaseCollectionTask(userID string, request queue.CaseCollectionEnqueueRequest) error {
+func (f caseCollectionEnqueuerFunc) EnqueueCaseCollectionTask(userID string, request queue.CaseCollectionEnqueueRequest) (casecollection.Job, error) {
 	return f(userID, request)
 }
 
 // CollectCaseCollectionHandle 是默认案件采集处理器。
 func CollectCaseCollectionHandle(c *gin.Context) {
-	NewCollectCaseCollectionHandle(caseCollectionEnqueuerFunc(func(userID string, request queue.CaseCollectionEnqueueRequest) error {
-		return queue.EnqueueCaseCollectionTask(userID, request)
-	}))(c)
+	NewCollectCaseCollectionHandle(caseCollectionEnqueuerFunc(queue.EnqueueCaseCollectionTask))(c)
 }
 
 // NewCollectCaseCollectionHandle 创建可注入后台入队器的案件采集处理器。
@@ -41,7 +43,7 @@ func NewCollectCaseCollectionHandle(enqueuer CaseCollectionEnqueuer) gin.Handler
 			c.JSON(http.StatusBadRequest, gin.H{"error": "query 不能为空"})
 			return
 		}
-		if payload.CaseCount <= 0 || payload.CaseCount > 20 {
+		if payload.CaseCount <= 0 || payload.CaseCount > casecollection.MaxCaseCount {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "case_count 取值范围应为 1-20"})
 			return
 		}
@@ -50,16 +52,129 @@ func NewCollectCaseCollectionHandle(enqueuer CaseCollectionEnqueuer) gin.Handler
 			return
 		}
 
-		if err := enqueuer.EnqueueCaseCollectionTask(getCurrentUserID(c), queue.CaseCollectionEnqueueRequest{
+		job, err := enqueuer.EnqueueCaseCollectionTask(getCurrentUserID(c), queue.CaseCollectionEnqueueRequest{
 			Query:     payload.Query,
 			CaseCount: payload.CaseCount,
-		}); err != nil {
+		})
+		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "案件采集入队失败: " + err.Error()})
 			return
 		}
 
-		c.JSON(http.StatusAccepted, gin.H{
-			"message": "案件采集任务已在后台启动",
+		c.JSON(http.StatusAccepted, apimodel.CaseCollectionJobResponse{
+			Message: "案件采集任务已在后台启动",
+			Job:     toCaseCollectionJobItem(job),
 		})
 	}
 }
+
+// ListCaseCollectionJobsHandle 返回最近的案件采集任务，支持 status 与 limit 筛选（管理员）。
+func ListCaseCollectionJobsHandle(c *gin.Context) {
+	status := strings.TrimSpace(c.Query("status"))
+	if status != "" && !casecollection.IsJobStatus(status) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status 仅支持 running、completed、failed、canceled"})
+		return
+	}
+	limit := 0
+	if raw := strings.TrimSpace(c.Query("limit")); raw != "" {
+		value, err := strconv.Atoi(raw)
+		if err != nil || value < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit 必须为正整数"})
+			return
+		}
+		limit = value
+	}
+
+	jobs, err := casecollection.DefaultService().List(status, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询案件采集任务失败: " + err.Error()})
+		return
+	}
+	items := make([]apimodel.CaseCollectionJobItem, 0, len(jobs))
+	for _, job := range jobs {
+		items = append(items, toCaseCollectionJobItem(job))
+	}
+	c.JSON(http.StatusOK, apimodel.CaseCollectionJobListResponse{Jobs: items})
+}
+
+// GetCaseCollectionJobHandle 返回指定案件采集任务的进度与产出（管理员）。
+func GetCaseCollectionJobHandle(c *gin.Context) {
+	jobID := strings.TrimSpace(c.Param("jobId"))
+	if jobID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "jobId 不能为空"})
+		return
+	}
+	job, err := casecollection.DefaultService().Get(jobID)
+	if err != nil {
+		writeCaseCollectionJobError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, apimodel.CaseCollectionJobResponse{Job: toCaseCollectionJobItem(job)})
+}
+
+// CancelCaseCollectionJobHandle 取消运行中的案件采集任务，已提交的待审核案件保留（管理员）。
+func CancelCaseCollectionJobHandle(c *gin.Context) {
+	jobID := strings.TrimSpace(c.Param("jobId"))
+	if jobID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "jobId 不能为空"})
+		return
+	}
+	job, err := casecollection.DefaultService().Cancel(jobID)
+	if err != nil {
+		writeCaseCollectionJobError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, apimodel.CaseCollectionJobResponse{Message: "案件采集任务已取消", Job: toCaseCollectionJobItem(job)})
+}
+
+// RetryCaseCollectionJobHandle 以原任务的主题与数量重新发起采集（管理员）。
+func RetryCaseCollectionJobHandle(c *gin.Context) {
+	jobID := strings.TrimSpace(c.Param("jobId"))
+	if jobID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "jobId 不能为空"})
+		return
+	}
+	job, err := casecollection.DefaultService().Retry(jobID, getCurrentUserID(c))
+	if err != nil {
+		writeCaseCollectionJobError(c, err)
+		return
+	}
+	c.JSON(http.StatusAccepted, apimodel.CaseCollectionJobResponse{Message: "案件采集任务已重新启动", Job: toCaseCollectionJobItem(job)})
+}
+
+func writeCaseCollectionJobError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, casecollection.ErrJobNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "案件采集任务不存在"})
+	case errors.Is(err, casecollection.ErrJobFinished):
+		c.JSON(http.StatusConflict, gin.H{"error": "案件采集任务已结束"})
+	case errors.Is(err, casecollection.ErrJobRunning):
+		c.JSON(http.StatusConflict, gin.H{"error": "案件采集任务仍在运行，无法重试"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "案件采集任务操作失败: " + err.Error()})
+	}
+}
+
+func toCaseCollectionJobItem(job casecollection.Job) apimodel.CaseCollectionJobItem {
+	item := apimodel.CaseCollectionJobItem{
+		JobID:          job.JobID,
+		Query:          job.Query,
+		RequestedCount: job.RequestedCount,
+		Status:         job.Status,
+		RoundsUsed:     job.RoundsUsed,
+		SearchQueries:  append([]string{}, job.SearchQueries...),
+		RecordIDs:      append([]string{}, job.RecordIDs...),
+		CreatedCount:   job.CreatedCount,
+		DuplicateCount: job.DuplicateCount,
+		LastError:      job.LastError,
+		CreatedBy:      job.CreatedBy,
+		RetryOf:        job.RetryOf,
+		CampaignID:     job.CampaignID,
+		StartedAt:      job.StartedAt.Format(time.RFC3339),
+		UpdatedAt:      job.UpdatedAt.Format(time.RFC3339),
+	}
+	if job.FinishedAt != nil {
+		item.FinishedAt = job.FinishedAt.Format(time.RFC3339)
+	}
+	return item
+}
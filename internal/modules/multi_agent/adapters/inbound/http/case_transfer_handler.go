@@ -0,0 +1,240 @@
+package httpapi
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	apimodel "antifraud/internal/modules/multi_agent/adapters/inbound/http/models"
+	"antifraud/internal/modules/multi_agent/application/casetransfer"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StartHistoricalCaseImportHandle 上传 JSONL/CSV 文件并在后台批量导入历史案件（管理员）。
+// 支持 multipart 字段 file，或直接以请求体上传文件内容；format 为空时按文件名后缀推断。
+func StartHistoricalCaseImportHandle(c *gin.Context) {
+	batchSize := 0
+	if raw := strings.TrimSpace(c.Query("batch_size")); raw != "" {
+		value, err := strconv.Atoi(raw)
+		if err != nil || value < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "batch_size 必须为非负整数"})
+			return
+		}
+		batchSize = value
+	}
+
+	fileName, payload, err := readCaseImportFile(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "读取导入文件失败: " + err.Error()})
+		return
+	}
+
+	job, err := casetransfer.DefaultService().StartImport(getCurrentUserID(c), fileName, c.Query("format"), payload, batchSize)
+	if err != nil {
+		switch {
+		case errors.Is(err, casetransfer.ErrImportRunning):
+			c.JSON(http.StatusConflict, gin.H{"error": "已有进行中的历史案件导入任务"})
+		case errors.Is(err, casetransfer.ErrUnsupportedFormat):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "不支持的导入格式，仅支持 jsonl 或 csv"})
+		case errors.Is(err, casetransfer.ErrInvalidImportFile):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "导入文件无效: " + err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "启动历史案件导入失败: " + err.Error()})
+		}
+		return
+	}
+	c.JSON(http.StatusAccepted, apimodel.HistoricalCaseImportJobResponse{Job: toHistoricalCaseImportJobItem(job)})
+}
+
+// ListHistoricalCaseImportsHandle 返回最近的历史案件导入任务（管理员）。
+func ListHistoricalCaseImportsHandle(c *gin.Context) {
+	jobs, err := casetransfer.DefaultService().List(20)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询历史案件导入任务失败: " + err.Error()})
+		return
+	}
+	items := make([]apimodel.HistoricalCaseImportJobItem, 0, len(jobs))
+	for _, job := range jobs {
+		items = append(items, toHistoricalCaseImportJobItem(job))
+	}
+	c.JSON(http.StatusOK, apimodel.HistoricalCaseImportJobListResponse{Jobs: items})
+}
+
+// GetHistoricalCaseImportHandle 返回指定导入任务的进度（管理员）。
+func GetHistoricalCaseImportHandle(c *gin.Context) {
+	jobID := strings.TrimSpace(c.Param("jobId"))
+	if jobID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "jobId 不能为空"})
+		return
+	}
+	job, err := casetransfer.DefaultService().Get(jobID)
+	if err != nil {
+		writeHistoricalCaseImportLookupError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, apimodel.HistoricalCaseImportJobResponse{Job: toHistoricalCaseImportJobItem(job)})
+}
+
+// GetHistoricalCaseImportErrorsHandle 返回导入任务的错误报告（管理员）。
+// format=csv 时以附件形式下载，默认返回 JSON。
+func GetHistoricalCaseImportErrorsHandle(c *gin.Context) {
+	jobID := strings.TrimSpace(c.Param("jobId"))
+	if jobID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "jobId 不能为空"})
+		return
+	}
+	format := strings.ToLower(strings.TrimSpace(c.DefaultQuery("format", "json")))
+	if format != "json" && format != casetransfer.FormatCSV {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format 仅支持 json 或 csv"})
+		return
+	}
+
+	service := casetransfer.DefaultService()
+	job, err := service.Get(jobID)
+	if err != nil {
+		writeHistoricalCaseImportLookupError(c, err)
+		return
+	}
+	rowErrors, err := service.ListRowErrors(job.JobID)
+	if err != nil {
+		writeHistoricalCaseImportLookupError(c, err)
+		return
+	}
+
+	if format == casetransfer.FormatCSV {
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", job.JobID+"-errors.csv"))
+		c.Status(http.StatusOK)
+		if err := casetransfer.WriteErrorReportCSV(c.Writer, rowErrors); err != nil {
+			log.Printf("[case_import] write error report failed: job_id=%s err=%v", job.JobID, err)
+		}
+		return
+	}
+
+	items := make([]apimodel.HistoricalCaseImportRowError, 0, len(rowErrors))
+	for _, item := range rowErrors {
+		items = append(items, apimodel.HistoricalCaseImportRowError{
+			RowNumber:       item.RowNumber,
+			Status:          item.Status,
+			Title:           item.Title,
+			Error:           item.Error,
+			DuplicateCaseID: item.DuplicateCaseID,
+			Similarity:      item.Similarity,
+		})
+	}
+	c.JSON(http.StatusOK, apimodel.HistoricalCaseImportErrorReportResponse{
+		JobID:  job.JobID,
+		Status: job.Status,
+		Total:  len(items),
+		Errors: items,
+	})
+}
+
+// ExportHistoricalCasesHandle 以流式附件导出整个历史案件库（管理员）。
+// format 支持 jsonl（默认）与 csv；include_vectors=true 时附带 embedding 向量。
+func ExportHistoricalCasesHandle(c *gin.Context) {
+	format, err := casetransfer.NormalizeFormat(c.DefaultQuery("format", casetransfer.FormatJSONL), "")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "不支持的导出格式，仅支持 jsonl 或 csv"})
+		return
+	}
+	includeVectors := false
+	if raw := strings.TrimSpace(c.Query("include_vectors")); raw != "" {
+		value, parseErr := strconv.ParseBool(raw)
+		if parseErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "include_vectors 必须为布尔值"})
+			return
+		}
+		includeVectors = value
+	}
+
+	contentType := "application/x-ndjson; charset=utf-8"
+	if format == casetransfer.FormatCSV {
+		contentType = "text/csv; charset=utf-8"
+	}
+	fileName := fmt.Sprintf("historical_cases_%s.%s", time.Now().Format("20060102150405"), format)
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName))
+	c.Status(http.StatusOK)
+
+	// 响应头已发出，中途失败只能截断输出并记录日志。
+	count, err := casetransfer.DefaultService().Export(c.Request.Context(), c.Writer, casetransfer.ExportOptions{
+		Format:         format,
+		IncludeVectors: includeVectors,
+	})
+	if err != nil {
+		log.Printf("[case_export] export interrupted: exported=%d err=%v", count, err)
+		return
+	}
+	log.Printf("[case_export] export completed: format=%s include_vectors=%t exported=%d", format, includeVectors, count)
+}
+
+func readCaseImportFile(c *gin.Context) (string, []byte, error) {
+	limit := int64(casetransfer.MaxImportFileBytes)
+	if strings.HasPrefix(strings.ToLower(c.ContentType()), "multipart/") {
+		header, err := c.FormFile("file")
+		if err != nil {
+			return "", nil, fmt.Errorf("缺少 file 字段: %w", err)
+		}
+		if header.Size > limit {
+			return "", nil, fmt.Errorf("文件超过 %d 字节上限", limit)
+		}
+		file, err := header.Open()
+		if err != nil {
+			return "", nil, err
+		}
+		defer file.Close()
+		payload, err := io.ReadAll(io.LimitReader(file, limit+1))
+		if err != nil {
+			return "", nil, err
+		}
+		return header.Filename, payload, nil
+	}
+
+	payload, err := io.ReadAll(io.LimitReader(c.Request.Body, limit+1))
+	if err != nil {
+		return "", nil, err
+	}
+	if len(payload) == 0 {
+		return "", nil, fmt.Errorf("请求体为空")
+	}
+	return strings.TrimSpace(c.Query("file_name")), payload, nil
+}
+
+func writeHistoricalCaseImportLookupError(c *gin.Context, err error) {
+	if errors.Is(err, casetransfer.ErrJobNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "历史案件导入任务不存在"})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "查询历史案件导入任务失败: " + err.Error()})
+}
+
+func toHistoricalCaseImportJobItem(job casetransfer.Job) apimodel.HistoricalCaseImportJobItem {
+	item := apimodel.HistoricalCaseImportJobItem{
+		JobID:         job.JobID,
+		Format:        job.Format,
+		FileName:      job.FileName,
+		Status:        job.Status,
+		BatchSize:     job.BatchSize,
+		TotalRows:     job.TotalRows,
+		ProcessedRows: job.ProcessedRows,
+		ImportedRows:  job.ImportedRows,
+		InvalidRows:   job.InvalidRows,
+		DuplicateRows: job.DuplicateRows,
+		FailedRows:    job.FailedRows,
+		LastError:     job.LastError,
+		CreatedBy:     job.CreatedBy,
+		StartedAt:     job.StartedAt.Format(time.RFC3339),
+		UpdatedAt:     job.UpdatedAt.Format(time.RFC3339),
+	}
+	if job.FinishedAt != nil {
+		item.FinishedAt = job.FinishedAt.Format(time.RFC3339)
+	}
+	return item
+}
@@ -0,0 +1,138 @@
+package httpapi
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	apimodel "antifraud/internal/modules/multi_agent/adapters/inbound/http/models"
+	"antifraud/internal/modules/multi_agent/application/migration"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StartEmbeddingMigrationHandle 启动 embedding 模型迁移任务（管理员）。
+func StartEmbeddingMigrationHandle(c *gin.Context) {
+	var payload apimodel.StartEmbeddingMigrationRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误: " + err.Error()})
+		return
+	}
+	if payload.BatchSize < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "batch_size 不能为负数"})
+		return
+	}
+
+	service := migration.DefaultService()
+	job, err := service.Start(getCurrentUserID(c), payload.TargetModel, payload.BatchSize)
+	if err != nil {
+		switch {
+		case errors.Is(err, migration.ErrMigrationRunning):
+			c.JSON(http.StatusConflict, gin.H{"error": "已有进行中的 embedding 迁移任务"})
+		case errors.Is(err, migration.ErrInvalidTargetModel):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "目标模型无效: " + err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "启动 embedding 迁移失败: " + err.Error()})
+		}
+		return
+	}
+	c.JSON(http.StatusAccepted, buildEmbeddingMigrationJobResponse(service, job))
+}
+
+// ListEmbeddingMigrationsHandle 返回最近的 embedding 迁移任务（管理员）。
+func ListEmbeddingMigrationsHandle(c *gin.Context) {
+	service := migration.DefaultService()
+	jobs, err := service.List(20)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询 embedding 迁移任务失败: " + err.Error()})
+		return
+	}
+	items := make([]apimodel.EmbeddingMigrationJobItem, 0, len(jobs))
+	for _, job := range jobs {
+		items = append(items, toEmbeddingMigrationJobItem(job))
+	}
+	c.JSON(http.StatusOK, apimodel.EmbeddingMigrationJobListResponse{
+		ActiveModel:     service.ActiveModel(),
+		ConfiguredModel: service.ConfiguredModel(),
+		Jobs:            items,
+	})
+}
+
+// GetEmbeddingMigrationHandle 返回指定 embedding 迁移任务的进度（管理员）。
+func GetEmbeddingMigrationHandle(c *gin.Context) {
+	jobID := strings.TrimSpace(c.Param("jobId"))
+	if jobID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "jobId 不能为空"})
+		return
+	}
+	service := migration.DefaultService()
+	job, err := service.Get(jobID)
+	if err != nil {
+		writeEmbeddingMigrationLookupError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, buildEmbeddingMigrationJobResponse(service, job))
+}
+
+// CancelEmbeddingMigrationHandle 取消运行中的 embedding 迁移任务，已生成的暂存向量会被丢弃（管理员）。
+func CancelEmbeddingMigrationHandle(c *gin.Context) {
+	jobID := strings.TrimSpace(c.Param("jobId"))
+	if jobID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "jobId 不能为空"})
+		return
+	}
+	service := migration.DefaultService()
+	job, err := service.Cancel(jobID)
+	if err != nil {
+		if errors.Is(err, migration.ErrJobNotRunning) {
+			c.JSON(http.StatusConflict, gin.H{"error": "任务未在运行或已进入切换阶段，无法取消"})
+			return
+		}
+		writeEmbeddingMigrationLookupError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, buildEmbeddingMigrationJobResponse(service, job))
+}
+
+func writeEmbeddingMigrationLookupError(c *gin.Context, err error) {
+	if errors.Is(err, migration.ErrJobNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "embedding 迁移任务不存在"})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "查询 embedding 迁移任务失败: " + err.Error()})
+}
+
+func buildEmbeddingMigrationJobResponse(service *migration.Service, job migration.Job) apimodel.EmbeddingMigrationJobResponse {
+	return apimodel.EmbeddingMigrationJobResponse{
+		ActiveModel:     service.ActiveModel(),
+		ConfiguredModel: service.ConfiguredModel(),
+		Job:             toEmbeddingMigrationJobItem(job),
+	}
+}
+
+func toEmbeddingMigrationJobItem(job migration.Job) apimodel.EmbeddingMigrationJobItem {
+	item := apimodel.EmbeddingMigrationJobItem{
+		JobID:             job.JobID,
+		SourceModel:       job.SourceModel,
+		TargetModel:       job.TargetModel,
+		Status:            job.Status,
+		Phase:             job.Phase,
+		BatchSize:         job.BatchSize,
+		Sweep:             job.Sweep,
+		HistoricalTotal:   job.HistoricalTotal,
+		HistoricalDone:    job.HistoricalDone,
+		HistoricalFailed:  job.HistoricalFailed,
+		UserHistoryTotal:  job.UserHistoryTotal,
+		UserHistoryDone:   job.UserHistoryDone,
+		UserHistoryFailed: job.UserHistoryFailed,
+		LastError:         job.LastError,
+		CreatedBy:         job.CreatedBy,
+		StartedAt:         job.StartedAt.Format(time.RFC3339),
+		UpdatedAt:         job.UpdatedAt.Format(time.RFC3339),
+	}
+	if job.FinishedAt != nil {
+		item.FinishedAt = job.FinishedAt.Format(time.RFC3339)
+	}
+	return item
+}
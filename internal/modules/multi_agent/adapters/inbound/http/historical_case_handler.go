@@ -23,6 +23,11 @@ func CreateHistoricalCaseHandle(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误: " + err.Error()})
 		return
 	}
+	citations, err := parseCaseCitationItems(payload.Citations)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误: " + err.Error()})
+		return
+	}
 
 	record, err := defaultCaseLibraryService.CreateHistoricalCase(c.Request.Context(), getCurrentUserID(c), case_library.CreateHistoricalCaseInput{
 		Title:           payload.Title,
@@ -34,33 +39,10 @@ func CreateHistoricalCaseHandle(c *gin.Context) {
 		Keywords:        payload.Keywords,
 		ViolatedLaw:     payload.ViolatedLaw,
 		Suggestion:      payload.Suggestion,
+		Citations:       citations,
 	})
 	if err != nil {
-		if case_library.IsValidationError(err) {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"error":                 err.Error(),
-				"allowed_target_groups": append([]string{}, defaultCaseLibraryService.ListTargetGroups()...),
-				"allowed_risk_levels":   append([]string{}, case_library.FixedRiskLevels...),
-				"allowed_scam_types":    append([]string{}, defaultCaseLibraryService.ListScamTypes()...),
-			})
-			return
-		}
-		if duplicateErr, ok := case_library.AsDuplicateHistoricalCaseError(err); ok && duplicateErr != nil {
-			c.JSON(http.StatusConflict, gin.H{
-				"error":   err.Error(),
-				"message": "历史案件重复，已存在高度相似案件",
-				"duplicate_case": gin.H{
-					"case_id":      strings.TrimSpace(duplicateErr.TopMatch.CaseID),
-					"title":        strings.TrimSpace(duplicateErr.TopMatch.Title),
-					"target_group": strings.TrimSpace(duplicateErr.TopMatch.TargetGroup),
-					"risk_level":   strings.TrimSpace(duplicateErr.TopMatch.RiskLevel),
-					"scam_type":    strings.TrimSpace(duplicateErr.TopMatch.ScamType),
-					"similarity":   duplicateErr.TopMatch.Similarity,
-				},
-			})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "历史案件入库失败: " + err.Error()})
+		writeHistoricalCaseWriteError(c, err, "历史案件入库失败: ")
 		return
 	}
 
@@ -78,6 +60,7 @@ func CreateHistoricalCaseHandle(c *gin.Context) {
 			Keywords:           append([]string{}, record.Keywords...),
 			ViolatedLaw:        record.ViolatedLaw,
 			Suggestion:         record.Suggestion,
+			Citations:          toCaseCitationItems(record.Citations),
 			EmbeddingModel:     record.EmbeddingModel,
 			EmbeddingDimension: record.EmbeddingDimension,
 			CreatedAt:          record.CreatedAt.Format(time.RFC3339),
@@ -85,6 +68,35 @@ func CreateHistoricalCaseHandle(c *gin.Context) {
 	})
 }
 
+// writeHistoricalCaseWriteError 输出历史案件写入（创建、编辑、回滚）的错误响应：校验失败 400，重复 409，其余 500。
+func writeHistoricalCaseWriteError(c *gin.Context, err error, fallbackPrefix string) {
+	if case_library.IsValidationError(err) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":                 err.Error(),
+			"allowed_target_groups": append([]string{}, defaultCaseLibraryService.ListTargetGroups()...),
+			"allowed_risk_levels":   append([]string{}, case_library.FixedRiskLevels...),
+			"allowed_scam_types":    append([]string{}, defaultCaseLibraryService.ListScamTypes()...),
+		})
+		return
+	}
+	if duplicateErr, ok := case_library.AsDuplicateHistoricalCaseError(err); ok && duplicateErr != nil {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   err.Error(),
+			"message": "历史案件重复，已存在高度相似案件",
+			"duplicate_case": gin.H{
+				"case_id":      strings.TrimSpace(duplicateErr.TopMatch.CaseID),
+				"title":        strings.TrimSpace(duplicateErr.TopMatch.Title),
+				"target_group": strings.TrimSpace(duplicateErr.TopMatch.TargetGroup),
+				"risk_level":   strings.TrimSpace(duplicateErr.TopMatch.RiskLevel),
+				"scam_type":    strings.TrimSpace(duplicateErr.TopMatch.ScamType),
+				"similarity":   duplicateErr.TopMatch.Similarity,
+			},
+		})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": fallbackPrefix + err.Error()})
+}
+
 // GetHistoricalCasePreviewHandle 返回历史案件预览列表。
 // 仅包含标题、目标人群、风险等级以及 case_id（便于前端点详情）。
 func GetHistoricalCasePreviewHandle(c *gin.Context) {
@@ -127,7 +139,7 @@ func GetHistoricalCasePreviewHandle(c *gin.Context) {
 	})
 }
 
-// GetHistoricalCaseDetailHandle 返回指定 case_id 的完整历史案件详情（包含 embedding 向量）。
+// GetHistoricalCaseDetailHandle 返回指定 case_id 的完整历史案件详情（包含 embedding 向量）；已合并的旧 case_id 返回保留案件。
 func GetHistoricalCaseDetailHandle(c *gin.Context) {
 	caseID := strings.TrimSpace(c.Param("caseId"))
 	if caseID == "" {
@@ -145,26 +157,68 @@ func GetHistoricalCaseDetailHandle(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, apimodel.HistoricalCaseDetailResponse{
-		Case: apimodel.HistoricalCaseDetailItem{
-			CaseID:             record.CaseID,
-			CreatedBy:          strings.TrimSpace(record.CreatedBy),
-			Title:              record.Title,
-			TargetGroup:        record.TargetGroup,
-			RiskLevel:          record.RiskLevel,
-			ScamType:           record.ScamType,
-			CaseDescription:    record.CaseDescription,
-			TypicalScripts:     append([]string{}, record.TypicalScripts...),
-			Keywords:           append([]string{}, record.Keywords...),
-			ViolatedLaw:        record.ViolatedLaw,
-			Suggestion:         record.Suggestion,
-			EmbeddingVector:    append([]float64{}, record.EmbeddingVector...),
-			EmbeddingModel:     record.EmbeddingModel,
-			EmbeddingDimension: record.EmbeddingDimension,
-			CreatedAt:          record.CreatedAt.Format(time.RFC3339),
-			UpdatedAt:          record.UpdatedAt.Format(time.RFC3339),
-		},
-	})
+	c.JSON(http.StatusOK, buildHistoricalCaseDetailResponse(record, caseID))
+}
+
+func toHistoricalCaseDetailItem(record case_library.HistoricalCaseRecord) apimodel.HistoricalCaseDetailItem {
+	return apimodel.HistoricalCaseDetailItem{
+		CaseID:             record.CaseID,
+		CreatedBy:          strings.TrimSpace(record.CreatedBy),
+		Title:              record.Title,
+		TargetGroup:        record.TargetGroup,
+		RiskLevel:          record.RiskLevel,
+		ScamType:           record.ScamType,
+		CaseDescription:    record.CaseDescription,
+		TypicalScripts:     append([]string{}, record.TypicalScripts...),
+		Keywords:           append([]string{}, record.Keywords...),
+		ViolatedLaw:        record.ViolatedLaw,
+		Suggestion:         record.Suggestion,
+		Citations:          toCaseCitationItems(record.Citations),
+		EmbeddingVector:    append([]float64{}, record.EmbeddingVector...),
+		EmbeddingModel:     record.EmbeddingModel,
+		EmbeddingDimension: record.EmbeddingDimension,
+		Revision:           record.Revision,
+		UpdatedBy:          strings.TrimSpace(record.UpdatedBy),
+		CreatedAt:          record.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:          record.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+func toCaseCitationItems(citations []case_library.CaseCitation) []apimodel.CaseCitationItem {
+	items := make([]apimodel.CaseCitationItem, 0, len(citations))
+	for _, citation := range citations {
+		item := apimodel.CaseCitationItem{
+			URL:           citation.URL,
+			Title:         citation.Title,
+			PublishedDate: citation.PublishedDate,
+		}
+		if !citation.RetrievedAt.IsZero() {
+			item.RetrievedAt = citation.RetrievedAt.Format(time.RFC3339)
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// parseCaseCitationItems 把请求中的来源引用转换为案件库格式，retrieved_at 需为 RFC3339 时间；链接由案件库统一校验。
+func parseCaseCitationItems(items []apimodel.CaseCitationItem) ([]case_library.CaseCitation, error) {
+	citations := make([]case_library.CaseCitation, 0, len(items))
+	for index, item := range items {
+		citation := case_library.CaseCitation{
+			URL:           item.URL,
+			Title:         item.Title,
+			PublishedDate: item.PublishedDate,
+		}
+		if retrievedAt := strings.TrimSpace(item.RetrievedAt); retrievedAt != "" {
+			parsed, err := time.Parse(time.RFC3339, retrievedAt)
+			if err != nil {
+				return nil, fmt.Errorf("citations[%d].retrieved_at 需为 RFC3339 时间", index)
+			}
+			citation.RetrievedAt = parsed
+		}
+		citations = append(citations, citation)
+	}
+	return citations, nil
 }
 
 // DeleteHistoricalCaseHandle 删除指定 case_id 的历史案件。
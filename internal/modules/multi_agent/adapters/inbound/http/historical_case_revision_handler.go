@@ -0,0 +1,208 @@
+package httpapi
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	apimodel "antifraud/internal/modules/multi_agent/adapters/inbound/http/models"
+	"antifraud/internal/modules/multi_agent/adapters/outbound/case_library"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReplaceHistoricalCaseHandle 整体编辑历史案件（PUT），保留 case_id 与创建时间，旧内容记入版本历史。
+func ReplaceHistoricalCaseHandle(c *gin.Context) {
+	caseID := strings.TrimSpace(c.Param("caseId"))
+	if caseID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "caseId 不能为空"})
+		return
+	}
+	var payload apimodel.ReplaceHistoricalCaseRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误: " + err.Error()})
+		return
+	}
+
+	typicalScripts := append([]string{}, payload.TypicalScripts...)
+	keywords := append([]string{}, payload.Keywords...)
+	record, exists, err := defaultCaseLibraryService.UpdateHistoricalCase(c.Request.Context(), getCurrentUserID(c), caseID, payload.Revision, case_library.UpdateHistoricalCaseInput{
+		Title:           &payload.Title,
+		TargetGroup:     &payload.TargetGroup,
+		RiskLevel:       &payload.RiskLevel,
+		ScamType:        &payload.ScamType,
+		CaseDescription: &payload.CaseDescription,
+		TypicalScripts:  &typicalScripts,
+		Keywords:        &keywords,
+		ViolatedLaw:     &payload.ViolatedLaw,
+		Suggestion:      &payload.Suggestion,
+	})
+	writeHistoricalCaseEditResult(c, record, exists, err)
+}
+
+// PatchHistoricalCaseHandle 部分编辑历史案件（PATCH），仅修改请求中出现的字段。
+func PatchHistoricalCaseHandle(c *gin.Context) {
+	caseID := strings.TrimSpace(c.Param("caseId"))
+	if caseID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "caseId 不能为空"})
+		return
+	}
+	var payload apimodel.PatchHistoricalCaseRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误: " + err.Error()})
+		return
+	}
+
+	record, exists, err := defaultCaseLibraryService.UpdateHistoricalCase(c.Request.Context(), getCurrentUserID(c), caseID, payload.Revision, case_library.UpdateHistoricalCaseInput{
+		Title:           payload.Title,
+		TargetGroup:     payload.TargetGroup,
+		RiskLevel:       payload.RiskLevel,
+		ScamType:        payload.ScamType,
+		CaseDescription: payload.CaseDescription,
+		TypicalScripts:  payload.TypicalScripts,
+		Keywords:        payload.Keywords,
+		ViolatedLaw:     payload.ViolatedLaw,
+		Suggestion:      payload.Suggestion,
+	})
+	writeHistoricalCaseEditResult(c, record, exists, err)
+}
+
+// GetHistoricalCaseRevisionsHandle 返回历史案件的全部版本（含当前版本），按版本号倒序。
+func GetHistoricalCaseRevisionsHandle(c *gin.Context) {
+	caseID := strings.TrimSpace(c.Param("caseId"))
+	if caseID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "caseId 不能为空"})
+		return
+	}
+
+	revisions, exists, err := defaultCaseLibraryService.ListHistoricalCaseRevisions(caseID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "历史案件版本查询失败: " + err.Error()})
+		return
+	}
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "历史案件不存在"})
+		return
+	}
+
+	items := make([]apimodel.HistoricalCaseRevisionItem, 0, len(revisions))
+	for index, revision := range revisions {
+		items = append(items, toHistoricalCaseRevisionItem(revision, index == 0))
+	}
+	c.JSON(http.StatusOK, apimodel.HistoricalCaseRevisionListResponse{
+		CaseID:          caseID,
+		CurrentRevision: revisions[0].Revision,
+		Revisions:       items,
+	})
+}
+
+// GetHistoricalCaseRevisionDiffHandle 比较指定版本与 against 版本（默认当前版本）的字段差异。
+func GetHistoricalCaseRevisionDiffHandle(c *gin.Context) {
+	caseID := strings.TrimSpace(c.Param("caseId"))
+	if caseID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "caseId 不能为空"})
+		return
+	}
+	fromRevision, err := strconv.Atoi(strings.TrimSpace(c.Param("revision")))
+	if err != nil || fromRevision < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "revision 必须为正整数"})
+		return
+	}
+	toRevision := 0
+	if raw := strings.TrimSpace(c.Query("against")); raw != "" && raw != "current" {
+		toRevision, err = strconv.Atoi(raw)
+		if err != nil || toRevision < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "against 必须为正整数或 current"})
+			return
+		}
+	}
+
+	from, to, changes, exists, err := defaultCaseLibraryService.DiffHistoricalCaseRevisions(caseID, fromRevision, toRevision)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "历史案件版本对比失败: " + err.Error()})
+		return
+	}
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "历史案件或版本不存在"})
+		return
+	}
+
+	items := make([]apimodel.HistoricalCaseFieldChangeItem, 0, len(changes))
+	for _, change := range changes {
+		items = append(items, apimodel.HistoricalCaseFieldChangeItem{
+			Field:   change.Field,
+			Before:  change.Before,
+			After:   change.After,
+			Added:   append([]string{}, change.Added...),
+			Removed: append([]string{}, change.Removed...),
+		})
+	}
+	c.JSON(http.StatusOK, apimodel.HistoricalCaseRevisionDiffResponse{
+		CaseID:       caseID,
+		FromRevision: from.Revision,
+		ToRevision:   to.Revision,
+		Changes:      items,
+	})
+}
+
+// RollbackHistoricalCaseHandle 将历史案件恢复为指定旧版本，回滚本身会生成新版本。
+func RollbackHistoricalCaseHandle(c *gin.Context) {
+	caseID := strings.TrimSpace(c.Param("caseId"))
+	if caseID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "caseId 不能为空"})
+		return
+	}
+	revision, err := strconv.Atoi(strings.TrimSpace(c.Param("revision")))
+	if err != nil || revision < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "revision 必须为正整数"})
+		return
+	}
+
+	record, exists, err := defaultCaseLibraryService.RollbackHistoricalCase(c.Request.Context(), getCurrentUserID(c), caseID, revision)
+	writeHistoricalCaseEditResult(c, record, exists, err)
+}
+
+func writeHistoricalCaseEditResult(c *gin.Context, record case_library.HistoricalCaseRecord, exists bool, err error) {
+	if err != nil {
+		if errors.Is(err, case_library.ErrHistoricalCaseRevisionConflict) {
+			c.JSON(http.StatusConflict, gin.H{"error": "历史案件已被他人修改，请刷新后重试"})
+			return
+		}
+		writeHistoricalCaseWriteError(c, err, "历史案件更新失败: ")
+		return
+	}
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "历史案件不存在"})
+		return
+	}
+	c.JSON(http.StatusOK, apimodel.HistoricalCaseDetailResponse{Case: toHistoricalCaseDetailItem(record)})
+}
+
+func toHistoricalCaseRevisionItem(revision case_library.HistoricalCaseRevision, isCurrent bool) apimodel.HistoricalCaseRevisionItem {
+	item := apimodel.HistoricalCaseRevisionItem{
+		Revision:     revision.Revision,
+		IsCurrent:    isCurrent,
+		EditedBy:     revision.EditedBy,
+		CreatedAt:    revision.CreatedAt.Format(time.RFC3339),
+		ReplacedBy:   revision.ReplacedBy,
+		ChangeType:   revision.ChangeType,
+		RestoredFrom: revision.RestoredFrom,
+		Content: apimodel.CreateHistoricalCaseRequest{
+			Title:           revision.Content.Title,
+			TargetGroup:     revision.Content.TargetGroup,
+			RiskLevel:       revision.Content.RiskLevel,
+			ScamType:        revision.Content.ScamType,
+			CaseDescription: revision.Content.CaseDescription,
+			TypicalScripts:  append([]string{}, revision.Content.TypicalScripts...),
+			Keywords:        append([]string{}, revision.Content.Keywords...),
+			ViolatedLaw:     revision.Content.ViolatedLaw,
+			Suggestion:      revision.Content.Suggestion,
+		},
+	}
+	if !revision.ReplacedAt.IsZero() {
+		item.ReplacedAt = revision.ReplacedAt.Format(time.RFC3339)
+	}
+	return item
+}
@@ -0,0 +1,260 @@
+package httpapi
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	apimodel "antifraud/internal/modules/multi_agent/adapters/inbound/http/models"
+	"antifraud/internal/modules/multi_agent/adapters/outbound/mediastore"
+	"antifraud/internal/modules/multi_agent/adapters/outbound/state"
+	"antifraud/internal/modules/multi_agent/core"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// maxMultipartMediaFiles 是单次 multipart 分析请求可携带的文件数上限。
+	maxMultipartMediaFiles = 20
+	// maxMultipartTextBytes 是 multipart 中 text 字段的长度上限。
+	maxMultipartTextBytes = 64 * 1024
+)
+
+// CreateMediaUploadHandle 创建可续传的分片上传会话，返回服务端决定的分片大小与分片数。
+func CreateMediaUploadHandle(c *gin.Context) {
+	var req apimodel.CreateMediaUploadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误: " + err.Error()})
+		return
+	}
+	session, err := mediastore.DefaultStore().CreateUpload(getCurrentUserID(c), req.FileName, req.MimeType, req.TotalSize)
+	if err != nil {
+		writeMediaUploadError(c, "创建上传会话失败: ", err)
+		return
+	}
+	c.JSON(http.StatusCreated, apimodel.MediaUploadResponse{Upload: toMediaUploadItem(session)})
+}
+
+// GetMediaUploadHandle 查询上传会话进度，客户端断线后据此补传缺失分片。
+func GetMediaUploadHandle(c *gin.Context) {
+	uploadID := strings.TrimSpace(c.Param("uploadId"))
+	if uploadID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "uploadId 不能为空"})
+		return
+	}
+	session, err := mediastore.DefaultStore().GetUpload(getCurrentUserID(c), uploadID)
+	if err != nil {
+		writeMediaUploadError(c, "查询上传会话失败: ", err)
+		return
+	}
+	c.JSON(http.StatusOK, apimodel.MediaUploadResponse{Upload: toMediaUploadItem(session)})
+}
+
+// PutMediaUploadChunkHandle 以原始请求体写入一个分片，重复提交同一分片会覆盖旧内容。
+func PutMediaUploadChunkHandle(c *gin.Context) {
+	uploadID := strings.TrimSpace(c.Param("uploadId"))
+	if uploadID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "uploadId 不能为空"})
+		return
+	}
+	index, err := strconv.Atoi(strings.TrimSpace(c.Param("index")))
+	if err != nil || index < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "分片序号必须为非负整数"})
+		return
+	}
+	session, err := mediastore.DefaultStore().PutChunk(getCurrentUserID(c), uploadID, index, c.Request.Body)
+	if err != nil {
+		writeMediaUploadError(c, "上传分片失败: ", err)
+		return
+	}
+	c.JSON(http.StatusOK, apimodel.MediaUploadResponse{Upload: toMediaUploadItem(session)})
+}
+
+// CompleteMediaUploadHandle 合并全部分片为媒体文件，返回可在分析请求中引用的 media_id。
+func CompleteMediaUploadHandle(c *gin.Context) {
+	uploadID := strings.TrimSpace(c.Param("uploadId"))
+	if uploadID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "uploadId 不能为空"})
+		return
+	}
+	var req apimodel.CompleteMediaUploadRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误: " + err.Error()})
+		return
+	}
+	media, err := mediastore.DefaultStore().CompleteUpload(getCurrentUserID(c), uploadID, req.SHA256)
+	if err != nil {
+		writeMediaUploadError(c, "完成上传失败: ", err)
+		return
+	}
+	c.JSON(http.StatusOK, apimodel.CompleteMediaUploadResponse{Media: toMediaItem(media)})
+}
+
+// AbortMediaUploadHandle 取消上传会话并删除已接收的分片。
+func AbortMediaUploadHandle(c *gin.Context) {
+	uploadID := strings.TrimSpace(c.Param("uploadId"))
+	if uploadID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "uploadId 不能为空"})
+		return
+	}
+	if err := mediastore.DefaultStore().AbortUpload(getCurrentUserID(c), uploadID); err != nil {
+		writeMediaUploadError(c, "取消上传失败: ", err)
+		return
+	}
+	c.JSON(http.StatusOK, apimodel.AbortMediaUploadResponse{
+		UploadID: uploadID,
+		Message:  "上传会话已取消",
+	})
+}
+
+// readMultimodalMultipart 逐个读取 multipart 分段：text 字段为文本描述，videos/audios/images 与 inputs.<模态名>
+// 字段的文件直接流式写入媒体存储，不在内存中整体缓存。返回值包含已写入的媒体 ID，失败时由调用方清理。
+func readMultimodalMultipart(c *gin.Context, userID string) (apimodel.MultimodalScamAnalyzeRequest, []string, error) {
+	var payload apimodel.MultimodalScamAnalyzeRequest
+	uploaded := make([]string, 0)
+	reader, err := c.Request.MultipartReader()
+	if err != nil {
+		return payload, uploaded, fmt.Errorf("%w: %v", mediastore.ErrInvalidMedia, err)
+	}
+
+	store := mediastore.DefaultStore()
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return payload, uploaded, fmt.Errorf("%w: %v", mediastore.ErrInvalidMedia, err)
+		}
+
+		fieldName := strings.TrimSpace(part.FormName())
+		if part.FileName() == "" {
+			if fieldName == "text" {
+				text, readErr := io.ReadAll(io.LimitReader(part, maxMultipartTextBytes+1))
+				if readErr != nil {
+					_ = part.Close()
+					return payload, uploaded, readErr
+				}
+				if len(text) > maxMultipartTextBytes {
+					_ = part.Close()
+					return payload, uploaded, fmt.Errorf("%w: text 超过 %d 字节", mediastore.ErrInvalidMedia, maxMultipartTextBytes)
+				}
+				payload.Text = string(text)
+			}
+			_ = part.Close()
+			continue
+		}
+
+		modality, ok := multipartFieldModality(fieldName)
+		if !ok || !isSupportedMediaModality(modality) {
+			_ = part.Close()
+			return payload, uploaded, fmt.Errorf("%w: 不支持的文件字段 %s", mediastore.ErrInvalidMedia, fieldName)
+		}
+		if len(uploaded) >= maxMultipartMediaFiles {
+			_ = part.Close()
+			return payload, uploaded, fmt.Errorf("%w: 单次最多上传 %d 个文件", mediastore.ErrInvalidMedia, maxMultipartMediaFiles)
+		}
+		media, err := store.Put(userID, part.FileName(), part.Header.Get("Content-Type"), part)
+		_ = part.Close()
+		if err != nil {
+			return payload, uploaded, fmt.Errorf("文件 %s: %w", part.FileName(), err)
+		}
+		uploaded = append(uploaded, media.MediaID)
+		if payload.Media == nil {
+			payload.Media = map[string][]string{}
+		}
+		payload.Media[modality] = append(payload.Media[modality], media.MediaID)
+	}
+	return payload, uploaded, nil
+}
+
+// multipartFieldModality 把 multipart 文件字段名映射为模态名，兼容 JSON 请求中的复数字段名。
+func multipartFieldModality(fieldName string) (string, bool) {
+	switch fieldName {
+	case "videos", "video":
+		return state.ModalityVideo, true
+	case "audios", "audio":
+		return state.ModalityAudio, true
+	case "images", "image":
+		return state.ModalityImage, true
+	}
+	if kind, ok := strings.CutPrefix(fieldName, "inputs."); ok && strings.TrimSpace(kind) != "" {
+		return strings.TrimSpace(kind), true
+	}
+	return "", false
+}
+
+// isSupportedMediaModality 判断媒体引用的模态名是否为内置模态或已注册的扩展模态。
+func isSupportedMediaModality(kind string) bool {
+	switch kind {
+	case state.ModalityVideo, state.ModalityAudio, state.ModalityImage:
+		return true
+	}
+	_, ok := multi_agent.LookupModalityAnalyzer(kind)
+	return ok
+}
+
+// discardUploadedMedia 删除本次请求临时写入的媒体，失败只记录日志，残留文件由过期清理兜底。
+func discardUploadedMedia(userID string, mediaIDs []string) {
+	store := mediastore.DefaultStore()
+	for _, mediaID := range mediaIDs {
+		if _, err := store.Delete(userID, mediaID); err != nil {
+			log.Printf("[media_store] discard uploaded media failed: media_id=%s err=%v", mediaID, err)
+		}
+	}
+}
+
+func writeMediaUploadError(c *gin.Context, prefix string, err error) {
+	switch {
+	case errors.Is(err, mediastore.ErrMediaTooLarge):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": prefix + err.Error()})
+	case errors.Is(err, mediastore.ErrUploadNotFound), errors.Is(err, mediastore.ErrMediaNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": prefix + err.Error()})
+	case errors.Is(err, mediastore.ErrUploadExpired):
+		c.JSON(http.StatusGone, gin.H{"error": prefix + "上传会话已过期，请重新创建"})
+	case errors.Is(err, mediastore.ErrUploadCompleted):
+		c.JSON(http.StatusConflict, gin.H{"error": prefix + "上传会话已完成"})
+	case errors.Is(err, mediastore.ErrInvalidMedia),
+		errors.Is(err, mediastore.ErrInvalidChunk),
+		errors.Is(err, mediastore.ErrUploadIncomplete),
+		errors.Is(err, mediastore.ErrChecksumMismatch):
+		c.JSON(http.StatusBadRequest, gin.H{"error": prefix + err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": prefix + err.Error()})
+	}
+}
+
+func toMediaUploadItem(session mediastore.UploadSession) apimodel.MediaUploadItem {
+	return apimodel.MediaUploadItem{
+		UploadID:       session.UploadID,
+		FileName:       session.FileName,
+		MimeType:       session.MIME,
+		TotalSize:      session.TotalSize,
+		ChunkSize:      session.ChunkSize,
+		TotalChunks:    session.TotalChunks,
+		ReceivedChunks: append([]int{}, session.ReceivedChunks...),
+		ReceivedBytes:  session.ReceivedBytes,
+		Status:         session.Status,
+		MediaID:        session.MediaID,
+		ExpiresAt:      session.ExpiresAt.Format(time.RFC3339),
+		CreatedAt:      session.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:      session.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+func toMediaItem(media mediastore.Media) apimodel.MediaItem {
+	return apimodel.MediaItem{
+		MediaID:   media.MediaID,
+		Ref:       mediastore.MediaRef(media.MediaID),
+		FileName:  media.FileName,
+		MimeType:  media.MIME,
+		Size:      media.Size,
+		SHA256:    media.SHA256,
+		CreatedAt: media.CreatedAt.Format(time.RFC3339),
+	}
+}
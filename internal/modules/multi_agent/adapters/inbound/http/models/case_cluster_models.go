@@ -0,0 +1,71 @@
+package models
+
+// StartCaseClusterJobRequest 启动近似案件聚类任务请求体，数值为 0 时使用默认值。
+type StartCaseClusterJobRequest struct {
+	Threshold     float64 `json:"threshold"`
+	KeywordWeight float64 `json:"keyword_weight"`
+	ScamType      string  `json:"scam_type"`
+}
+
+// CaseClusterMemberItem 聚类成员条目，merged_into 非空表示该案件已被合并。
+type CaseClusterMemberItem struct {
+	CaseID            string  `json:"case_id"`
+	Title             string  `json:"title"`
+	ScamType          string  `json:"scam_type"`
+	CreatedAt         string  `json:"created_at"`
+	AverageSimilarity float64 `json:"average_similarity"`
+	MergedInto        string  `json:"merged_into,omitempty"`
+}
+
+// CaseClusterItem 近似案件聚类条目。
+type CaseClusterItem struct {
+	ClusterID            int                     `json:"cluster_id"`
+	SuggestedCanonicalID string                  `json:"suggested_canonical_id"`
+	MinSimilarity        float64                 `json:"min_similarity"`
+	MaxSimilarity        float64                 `json:"max_similarity"`
+	Members              []CaseClusterMemberItem `json:"members"`
+}
+
+// CaseClusterJobItem 近似案件聚类任务条目，clusters 仅在任务详情中返回。
+type CaseClusterJobItem struct {
+	JobID          string            `json:"job_id"`
+	Status         string            `json:"status"`
+	Threshold      float64           `json:"threshold"`
+	KeywordWeight  float64           `json:"keyword_weight"`
+	ScamType       string            `json:"scam_type,omitempty"`
+	EmbeddingModel string            `json:"embedding_model,omitempty"`
+	ScannedCases   int               `json:"scanned_cases"`
+	SkippedCases   int               `json:"skipped_cases"`
+	ComparedPairs  int64             `json:"compared_pairs"`
+	ClusterCount   int               `json:"cluster_count"`
+	Clusters       []CaseClusterItem `json:"clusters,omitempty"`
+	LastError      string            `json:"last_error,omitempty"`
+	CreatedBy      string            `json:"created_by"`
+	StartedAt      string            `json:"started_at"`
+	FinishedAt     string            `json:"finished_at,omitempty"`
+	UpdatedAt      string            `json:"updated_at"`
+}
+
+// CaseClusterJobResponse 单个聚类任务响应体。
+type CaseClusterJobResponse struct {
+	Job CaseClusterJobItem `json:"job"`
+}
+
+// CaseClusterJobListResponse 聚类任务列表响应体。
+type CaseClusterJobListResponse struct {
+	Jobs []CaseClusterJobItem `json:"jobs"`
+}
+
+// MergeHistoricalCasesRequest 合并近似案件请求体，Revision 大于 0 时用于保留案件的并发校验。
+type MergeHistoricalCasesRequest struct {
+	MergedCaseIDs []string `json:"merged_case_ids" binding:"required"`
+	Revision      int      `json:"revision"`
+}
+
+// MergedHistoricalCaseItem 已并入当前案件的旧案件条目。
+type MergedHistoricalCaseItem struct {
+	CaseID   string `json:"case_id"`
+	Title    string `json:"title"`
+	MergedBy string `json:"merged_by"`
+	MergedAt string `json:"merged_at"`
+}
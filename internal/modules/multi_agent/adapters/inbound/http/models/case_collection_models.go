@@ -5,3 +5,114 @@ type CaseCollectionRequest struct {
 	Query     string `json:"query"`
 	CaseCount int    `json:"case_count"`
 }
+
+// CaseCollectionJobItem 案件采集任务条目。
+type CaseCollectionJobItem struct {
+	JobID          string   `json:"job_id"`
+	Query          string   `json:"query"`
+	RequestedCount int      `json:"requested_count"`
+	Status         string   `json:"status"`
+	RoundsUsed     int      `json:"rounds_used"`
+	SearchQueries  []string `json:"search_queries"`
+	RecordIDs      []string `json:"record_ids"`
+	CreatedCount   int      `json:"created_count"`
+	DuplicateCount int      `json:"duplicate_count"`
+	LastError      string   `json:"last_error,omitempty"`
+	CreatedBy      string   `json:"created_by"`
+	RetryOf        string   `json:"retry_of,omitempty"`
+	CampaignID     string   `json:"campaign_id,omitempty"`
+	StartedAt      string   `json:"started_at"`
+	FinishedAt     string   `json:"finished_at,omitempty"`
+	UpdatedAt      string   `json:"updated_at"`
+}
+
+// CaseCollectionJobResponse 单个案件采集任务响应体。
+type CaseCollectionJobResponse struct {
+	Message string                `json:"message,omitempty"`
+	Job     CaseCollectionJobItem `json:"job"`
+}
+
+// CaseCollectionJobListResponse 案件采集任务列表响应体。
+type CaseCollectionJobListResponse struct {
+	Jobs []CaseCollectionJobItem `json:"jobs"`
+}
+
+// CaseCollectionCampaignRequest 是创建定时采集计划的请求体。
+type CaseCollectionCampaignRequest struct {
+	Name           string   `json:"name"`
+	QueryTemplates []string `json:"query_templates"`
+	Cron           string   `json:"cron"`
+	TargetCount    int      `json:"target_count"`
+	ScamTypeFocus  string   `json:"scam_type_focus"`
+	Enabled        *bool    `json:"enabled"`
+}
+
+// PatchCaseCollectionCampaignRequest 是修改定时采集计划的请求体，缺省字段保持不变。
+type PatchCaseCollectionCampaignRequest struct {
+	Name           *string  `json:"name"`
+	QueryTemplates []string `json:"query_templates"`
+	Cron           *string  `json:"cron"`
+	TargetCount    *int     `json:"target_count"`
+	ScamTypeFocus  *string  `json:"scam_type_focus"`
+	Enabled        *bool    `json:"enabled"`
+}
+
+// CaseCollectionCampaignItem 定时采集计划条目。
+type CaseCollectionCampaignItem struct {
+	CampaignID     string   `json:"campaign_id"`
+	Name           string   `json:"name"`
+	QueryTemplates []string `json:"query_templates"`
+	Cron           string   `json:"cron"`
+	TargetCount    int      `json:"target_count"`
+	ScamTypeFocus  string   `json:"scam_type_focus,omitempty"`
+	Enabled        bool     `json:"enabled"`
+	NextRunAt      string   `json:"next_run_at,omitempty"`
+	LastRunAt      string   `json:"last_run_at,omitempty"`
+	CreatedBy      string   `json:"created_by"`
+	UpdatedBy      string   `json:"updated_by"`
+	CreatedAt      string   `json:"created_at"`
+	UpdatedAt      string   `json:"updated_at"`
+}
+
+// CaseCollectionCampaignResponse 单个定时采集计划响应体。
+type CaseCollectionCampaignResponse struct {
+	Campaign CaseCollectionCampaignItem `json:"campaign"`
+}
+
+// CaseCollectionCampaignListResponse 定时采集计划列表响应体。
+type CaseCollectionCampaignListResponse struct {
+	Campaigns []CaseCollectionCampaignItem `json:"campaigns"`
+}
+
+// CaseCollectionCampaignRunQueryItem 计划运行中单个检索词的执行情况。
+type CaseCollectionCampaignRunQueryItem struct {
+	Template   string                 `json:"template"`
+	Query      string                 `json:"query"`
+	JobID      string                 `json:"job_id,omitempty"`
+	SkipReason string                 `json:"skip_reason,omitempty"`
+	Job        *CaseCollectionJobItem `json:"job,omitempty"`
+}
+
+// CaseCollectionCampaignRunItem 定时采集计划运行记录。
+type CaseCollectionCampaignRunItem struct {
+	RunID       string                               `json:"run_id"`
+	CampaignID  string                               `json:"campaign_id"`
+	Trigger     string                               `json:"trigger"`
+	Status      string                               `json:"status"`
+	SkipReason  string                               `json:"skip_reason,omitempty"`
+	Queries     []CaseCollectionCampaignRunQueryItem `json:"queries"`
+	TriggeredBy string                               `json:"triggered_by,omitempty"`
+	ScheduledAt string                               `json:"scheduled_at"`
+	CreatedAt   string                               `json:"created_at"`
+}
+
+// CaseCollectionCampaignRunResponse 单次计划运行响应体。
+type CaseCollectionCampaignRunResponse struct {
+	Message string                        `json:"message,omitempty"`
+	Run     CaseCollectionCampaignRunItem `json:"run"`
+}
+
+// CaseCollectionCampaignRunListResponse 计划运行历史响应体。
+type CaseCollectionCampaignRunListResponse struct {
+	Runs []CaseCollectionCampaignRunItem `json:"runs"`
+}
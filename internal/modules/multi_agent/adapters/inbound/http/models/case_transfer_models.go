@@ -0,0 +1,49 @@
+package models
+
+// HistoricalCaseImportJobItem 历史案件批量导入任务条目。
+type HistoricalCaseImportJobItem struct {
+	JobID         string `json:"job_id"`
+	Format        string `json:"format"`
+	FileName      string `json:"file_name"`
+	Status        string `json:"status"`
+	BatchSize     int    `json:"batch_size"`
+	TotalRows     int    `json:"total_rows"`
+	ProcessedRows int    `json:"processed_rows"`
+	ImportedRows  int    `json:"imported_rows"`
+	InvalidRows   int    `json:"invalid_rows"`
+	DuplicateRows int    `json:"duplicate_rows"`
+	FailedRows    int    `json:"failed_rows"`
+	LastError     string `json:"last_error,omitempty"`
+	CreatedBy     string `json:"created_by"`
+	StartedAt     string `json:"started_at"`
+	FinishedAt    string `json:"finished_at,omitempty"`
+	UpdatedAt     string `json:"updated_at"`
+}
+
+// HistoricalCaseImportJobResponse 单个导入任务响应体。
+type HistoricalCaseImportJobResponse struct {
+	Job HistoricalCaseImportJobItem `json:"job"`
+}
+
+// HistoricalCaseImportJobListResponse 导入任务列表响应体。
+type HistoricalCaseImportJobListResponse struct {
+	Jobs []HistoricalCaseImportJobItem `json:"jobs"`
+}
+
+// HistoricalCaseImportRowError 导入错误报告中的一行。
+type HistoricalCaseImportRowError struct {
+	RowNumber       int     `json:"row_number"`
+	Status          string  `json:"status"`
+	Title           string  `json:"title,omitempty"`
+	Error           string  `json:"error"`
+	DuplicateCaseID string  `json:"duplicate_case_id,omitempty"`
+	Similarity      float64 `json:"similarity,omitempty"`
+}
+
+// HistoricalCaseImportErrorReportResponse 导入错误报告响应体。
+type HistoricalCaseImportErrorReportResponse struct {
+	JobID  string                         `json:"job_id"`
+	Status string                         `json:"status"`
+	Total  int                            `json:"total"`
+	Errors []HistoricalCaseImportRowError `json:"errors"`
+}
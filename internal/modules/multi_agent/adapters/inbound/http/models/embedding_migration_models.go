@@ -0,0 +1,43 @@
+package models
+
+// StartEmbeddingMigrationRequest 启动 embedding 模型迁移请求体。
+type StartEmbeddingMigrationRequest struct {
+	TargetModel string `json:"target_model"`
+	BatchSize   int    `json:"batch_size"`
+}
+
+// EmbeddingMigrationJobItem embedding 模型迁移任务条目。
+type EmbeddingMigrationJobItem struct {
+	JobID             string `json:"job_id"`
+	SourceModel       string `json:"source_model"`
+	TargetModel       string `json:"target_model"`
+	Status            string `json:"status"`
+	Phase             string `json:"phase"`
+	BatchSize         int    `json:"batch_size"`
+	Sweep             int    `json:"sweep"`
+	HistoricalTotal   int64  `json:"historical_total"`
+	HistoricalDone    int64  `json:"historical_done"`
+	HistoricalFailed  int64  `json:"historical_failed"`
+	UserHistoryTotal  int64  `json:"user_history_total"`
+	UserHistoryDone   int64  `json:"user_history_done"`
+	UserHistoryFailed int64  `json:"user_history_failed"`
+	LastError         string `json:"last_error,omitempty"`
+	CreatedBy         string `json:"created_by"`
+	StartedAt         string `json:"started_at"`
+	FinishedAt        string `json:"finished_at,omitempty"`
+	UpdatedAt         string `json:"updated_at"`
+}
+
+// EmbeddingMigrationJobResponse 单个迁移任务响应体。
+type EmbeddingMigrationJobResponse struct {
+	ActiveModel     string                    `json:"active_model"`
+	ConfiguredModel string                    `json:"configured_model"`
+	Job             EmbeddingMigrationJobItem `json:"job"`
+}
+
+// EmbeddingMigrationJobListResponse 迁移任务列表响应体。
+type EmbeddingMigrationJobListResponse struct {
+	ActiveModel     string                      `json:"active_model"`
+	ConfiguredModel string                      `json:"configured_model"`
+	Jobs            []EmbeddingMigrationJobItem `json:"jobs"`
+}
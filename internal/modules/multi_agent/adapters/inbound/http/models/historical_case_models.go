@@ -2,33 +2,35 @@ package models
 
 // CreateHistoricalCaseRequest 上传历史案件请求体。
 type CreateHistoricalCaseRequest struct {
-	Title           string   `json:"title"`
-	TargetGroup     string   `json:"target_group"`
-	RiskLevel       string   `json:"risk_level"`
-	ScamType        string   `json:"scam_type"`
-	CaseDescription string   `json:"case_description"`
-	TypicalScripts  []string `json:"typical_scripts"`
-	Keywords        []string `json:"keywords"`
-	ViolatedLaw     string   `json:"violated_law"`
-	Suggestion      string   `json:"suggestion"`
+	Title           string             `json:"title"`
+	TargetGroup     string             `json:"target_group"`
+	RiskLevel       string             `json:"risk_level"`
+	ScamType        string             `json:"scam_type"`
+	CaseDescription string             `json:"case_description"`
+	TypicalScripts  []string           `json:"typical_scripts"`
+	Keywords        []string           `json:"keywords"`
+	ViolatedLaw     string             `json:"violated_law"`
+	Suggestion      string             `json:"suggestion"`
+	Citations       []CaseCitationItem `json:"citations"`
 }
 
 // HistoricalCaseItem 历史案件入库成功后的回显信息。
 type HistoricalCaseItem struct {
-	CaseID             string   `json:"case_id"`
-	CreatedBy          string   `json:"created_by"`
-	Title              string   `json:"title"`
-	TargetGroup        string   `json:"target_group"`
-	RiskLevel          string   `json:"risk_level"`
-	ScamType           string   `json:"scam_type"`
-	CaseDescription    string   `json:"case_description"`
-	TypicalScripts     []string `json:"typical_scripts"`
-	Keywords           []string `json:"keywords"`
-	ViolatedLaw        string   `json:"violated_law"`
-	Suggestion         string   `json:"suggestion"`
-	EmbeddingModel     string   `json:"embedding_model"`
-	EmbeddingDimension int      `json:"embedding_dimension"`
-	CreatedAt          string   `json:"created_at"`
+	CaseID             string             `json:"case_id"`
+	CreatedBy          string             `json:"created_by"`
+	Title              string             `json:"title"`
+	TargetGroup        string             `json:"target_group"`
+	RiskLevel          string             `json:"risk_level"`
+	ScamType           string             `json:"scam_type"`
+	CaseDescription    string             `json:"case_description"`
+	TypicalScripts     []string           `json:"typical_scripts"`
+	Keywords           []string           `json:"keywords"`
+	ViolatedLaw        string             `json:"violated_law"`
+	Suggestion         string             `json:"suggestion"`
+	Citations          []CaseCitationItem `json:"citations"`
+	EmbeddingModel     string             `json:"embedding_model"`
+	EmbeddingDimension int                `json:"embedding_dimension"`
+	CreatedAt          string             `json:"created_at"`
 }
 
 // CreateHistoricalCaseResponse 上传历史案件成功响应体。
@@ -59,27 +61,98 @@ type HistoricalCasePreviewResponse struct {
 
 // HistoricalCaseDetailItem 历史案件详情条目（包含向量）。
 type HistoricalCaseDetailItem struct {
-	CaseID             string    `json:"case_id"`
-	CreatedBy          string    `json:"created_by"`
-	Title              string    `json:"title"`
-	TargetGroup        string    `json:"target_group"`
-	RiskLevel          string    `json:"risk_level"`
-	ScamType           string    `json:"scam_type"`
-	CaseDescription    string    `json:"case_description"`
-	TypicalScripts     []string  `json:"typical_scripts"`
-	Keywords           []string  `json:"keywords"`
-	ViolatedLaw        string    `json:"violated_law"`
-	Suggestion         string    `json:"suggestion"`
-	EmbeddingVector    []float64 `json:"embedding_vector"`
-	EmbeddingModel     string    `json:"embedding_model"`
-	EmbeddingDimension int       `json:"embedding_dimension"`
-	CreatedAt          string    `json:"created_at"`
-	UpdatedAt          string    `json:"updated_at"`
+	CaseID             string             `json:"case_id"`
+	CreatedBy          string             `json:"created_by"`
+	Title              string             `json:"title"`
+	TargetGroup        string             `json:"target_group"`
+	RiskLevel          string             `json:"risk_level"`
+	ScamType           string             `json:"scam_type"`
+	CaseDescription    string             `json:"case_description"`
+	TypicalScripts     []string           `json:"typical_scripts"`
+	Keywords           []string           `json:"keywords"`
+	ViolatedLaw        string             `json:"violated_law"`
+	Suggestion         string             `json:"suggestion"`
+	Citations          []CaseCitationItem `json:"citations"`
+	EmbeddingVector    []float64          `json:"embedding_vector"`
+	EmbeddingModel     string             `json:"embedding_model"`
+	EmbeddingDimension int                `json:"embedding_dimension"`
+	Revision           int                `json:"revision"`
+	UpdatedBy          string             `json:"updated_by"`
+	CreatedAt          string             `json:"created_at"`
+	UpdatedAt          string             `json:"updated_at"`
+}
+
+// CaseCitationItem 案件来源引用条目，retrieved_at 为检索到该来源的时间。
+type CaseCitationItem struct {
+	URL           string `json:"url"`
+	Title         string `json:"title,omitempty"`
+	PublishedDate string `json:"published_date,omitempty"`
+	RetrievedAt   string `json:"retrieved_at,omitempty"`
 }
 
 // HistoricalCaseDetailResponse 历史案件详情响应体。
 type HistoricalCaseDetailResponse struct {
 	Case HistoricalCaseDetailItem `json:"case"`
+	// RedirectedFrom 非空表示请求的案件已被合并，返回的是其保留案件。
+	RedirectedFrom string                     `json:"redirected_from,omitempty"`
+	MergedFrom     []MergedHistoricalCaseItem `json:"merged_from,omitempty"`
+}
+
+// ReplaceHistoricalCaseRequest 整体编辑历史案件请求体（PUT），Revision 大于 0 时用于并发校验。
+type ReplaceHistoricalCaseRequest struct {
+	CreateHistoricalCaseRequest
+	Revision int `json:"revision"`
+}
+
+// PatchHistoricalCaseRequest 部分编辑历史案件请求体（PATCH），未提供的字段保持不变。
+type PatchHistoricalCaseRequest struct {
+	Title           *string   `json:"title"`
+	TargetGroup     *string   `json:"target_group"`
+	RiskLevel       *string   `json:"risk_level"`
+	ScamType        *string   `json:"scam_type"`
+	CaseDescription *string   `json:"case_description"`
+	TypicalScripts  *[]string `json:"typical_scripts"`
+	Keywords        *[]string `json:"keywords"`
+	ViolatedLaw     *string   `json:"violated_law"`
+	Suggestion      *string   `json:"suggestion"`
+	Revision        int       `json:"revision"`
+}
+
+// HistoricalCaseRevisionItem 历史案件版本条目。
+type HistoricalCaseRevisionItem struct {
+	Revision     int                         `json:"revision"`
+	IsCurrent    bool                        `json:"is_current"`
+	EditedBy     string                      `json:"edited_by"`
+	CreatedAt    string                      `json:"created_at"`
+	ReplacedBy   string                      `json:"replaced_by,omitempty"`
+	ReplacedAt   string                      `json:"replaced_at,omitempty"`
+	ChangeType   string                      `json:"change_type,omitempty"`
+	RestoredFrom int                         `json:"restored_from,omitempty"`
+	Content      CreateHistoricalCaseRequest `json:"content"`
+}
+
+// HistoricalCaseRevisionListResponse 历史案件版本列表响应体。
+type HistoricalCaseRevisionListResponse struct {
+	CaseID          string                       `json:"case_id"`
+	CurrentRevision int                          `json:"current_revision"`
+	Revisions       []HistoricalCaseRevisionItem `json:"revisions"`
+}
+
+// HistoricalCaseFieldChangeItem 两个版本之间单个字段的差异。
+type HistoricalCaseFieldChangeItem struct {
+	Field   string   `json:"field"`
+	Before  string   `json:"before"`
+	After   string   `json:"after"`
+	Added   []string `json:"added,omitempty"`
+	Removed []string `json:"removed,omitempty"`
+}
+
+// HistoricalCaseRevisionDiffResponse 历史案件版本差异响应体。
+type HistoricalCaseRevisionDiffResponse struct {
+	CaseID       string                          `json:"case_id"`
+	FromRevision int                             `json:"from_revision"`
+	ToRevision   int                             `json:"to_revision"`
+	Changes      []HistoricalCaseFieldChangeItem `json:"changes"`
 }
 
 // DeleteHistoricalCaseResponse 删除历史案件响应体。
@@ -87,3 +160,19 @@ type DeleteHistoricalCaseResponse struct {
 	CaseID  string `json:"case_id"`
 	Message string `json:"message"`
 }
+
+// CitationMatchItem 引用了指定来源的案件条目，kind 为 historical_case 或 pending_review。
+type CitationMatchItem struct {
+	Kind      string             `json:"kind"`
+	ID        string             `json:"id"`
+	Title     string             `json:"title"`
+	CreatedAt string             `json:"created_at"`
+	Citations []CaseCitationItem `json:"citations"`
+}
+
+// CitationMatchResponse 按来源查询案件响应体。
+type CitationMatchResponse struct {
+	Source string              `json:"source"`
+	Total  int                 `json:"total"`
+	Cases  []CitationMatchItem `json:"cases"`
+}
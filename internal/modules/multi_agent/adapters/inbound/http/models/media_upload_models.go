@@ -0,0 +1,57 @@
+package models
+
+// CreateMediaUploadRequest 创建分片上传会话请求体。
+type CreateMediaUploadRequest struct {
+	FileName  string `json:"file_name"`
+	MimeType  string `json:"mime_type"`
+	TotalSize int64  `json:"total_size" binding:"required"`
+}
+
+// CompleteMediaUploadRequest 完成分片上传请求体，sha256 为可选的整文件摘要（十六进制）。
+type CompleteMediaUploadRequest struct {
+	SHA256 string `json:"sha256"`
+}
+
+// MediaUploadItem 分片上传会话状态。
+type MediaUploadItem struct {
+	UploadID       string `json:"upload_id"`
+	FileName       string `json:"file_name"`
+	MimeType       string `json:"mime_type,omitempty"`
+	TotalSize      int64  `json:"total_size"`
+	ChunkSize      int64  `json:"chunk_size"`
+	TotalChunks    int    `json:"total_chunks"`
+	ReceivedChunks []int  `json:"received_chunks"`
+	ReceivedBytes  int64  `json:"received_bytes"`
+	Status         string `json:"status"`
+	MediaID        string `json:"media_id,omitempty"`
+	ExpiresAt      string `json:"expires_at"`
+	CreatedAt      string `json:"created_at"`
+	UpdatedAt      string `json:"updated_at"`
+}
+
+// MediaUploadResponse 分片上传会话响应。
+type MediaUploadResponse struct {
+	Upload MediaUploadItem `json:"upload"`
+}
+
+// MediaItem 已上传媒体的元数据，ref 可直接放入 videos/audios/images/inputs 数组。
+type MediaItem struct {
+	MediaID   string `json:"media_id"`
+	Ref       string `json:"ref"`
+	FileName  string `json:"file_name"`
+	MimeType  string `json:"mime_type"`
+	Size      int64  `json:"size"`
+	SHA256    string `json:"sha256"`
+	CreatedAt string `json:"created_at"`
+}
+
+// CompleteMediaUploadResponse 完成分片上传响应。
+type CompleteMediaUploadResponse struct {
+	Media MediaItem `json:"media"`
+}
+
+// AbortMediaUploadResponse 取消分片上传响应。
+type AbortMediaUploadResponse struct {
+	UploadID string `json:"upload_id"`
+	Message  string `json:"message"`
+}
@@ -6,6 +6,10 @@ type MultimodalScamAnalyzeRequest struct {
 	Videos []string `json:"videos"`
 	Audios []string `json:"audios"`
 	Images []string `json:"images"`
+	// Inputs 按模态名提交已注册的扩展输入（如 {"pdf": ["<base64>"]}）。
+	Inputs map[string][]string `json:"inputs,omitempty"`
+	// Media 按模态名引用已上传的媒体 ID（如 {"video": ["MEDIA-XXXX"]}），可与 base64 输入混用。
+	Media map[string][]string `json:"media,omitempty"`
 }
 
 // ImageQuickAnalyzeRequest 单图快速风险识别请求体。
@@ -35,6 +39,9 @@ type MultimodalTaskPayload struct {
 	VideoInsights []string `json:"video_insights,omitempty"`
 	AudioInsights []string `json:"audio_insights,omitempty"`
 	ImageInsights []string `json:"image_insights,omitempty"`
+	// Inputs/Insights 保存扩展模态的原始输入与解读结果，key 为模态名。
+	Inputs   map[string][]string `json:"inputs,omitempty"`
+	Insights map[string][]string `json:"insights,omitempty"`
 }
 
 // MultimodalTaskItem 多模态任务详情。
@@ -46,13 +53,39 @@ type MultimodalTaskItem struct {
 	ScamType    string                `json:"scam_type,omitempty"`
 	RiskScore   int                   `json:"risk_score,omitempty"`
 	RiskSummary string                `json:"risk_summary,omitempty"`
+	RuleVersion string                `json:"rule_version,omitempty"`
 	CreatedAt   string                `json:"created_at"`
 	UpdatedAt   string                `json:"updated_at"`
 	Payload     MultimodalTaskPayload `json:"payload"`
-	Summary     string                `json:"summary"`
-	Report      string                `json:"report,omitempty"`
-	Error       string                `json:"error,omitempty"`
-	HistoryRef  string                `json:"history_ref,omitempty"`
+	// Media 列出载荷中以 blob 引用保存的原始媒体及其下载地址，payload 中对应项为 blob:<sha256>。
+	Media      []MultimodalTaskMediaItem `json:"media,omitempty"`
+	Summary    string                    `json:"summary"`
+	Report     string                    `json:"report,omitempty"`
+	Error      string                    `json:"error,omitempty"`
+	HistoryRef string                    `json:"history_ref,omitempty"`
+	// MediaPurgedAt 非空表示原始媒体已按保留策略清除，解读与报告仍保留。
+	MediaPurgedAt string `json:"media_purged_at,omitempty"`
+	// Indicators 为从文本、ASR 转写与各模态解读中抽取的结构化线索，子智能体完成前为空。
+	Indicators []MultimodalTaskIndicator `json:"indicators,omitempty"`
+}
+
+// MultimodalTaskIndicator 任务抽取出的一条结构化线索。
+type MultimodalTaskIndicator struct {
+	Kind        string   `json:"kind"`
+	Label       string   `json:"label"`
+	Value       string   `json:"value"`
+	Fingerprint string   `json:"fingerprint,omitempty"`
+	Sources     []string `json:"sources,omitempty"`
+}
+
+// MultimodalTaskMediaItem 任务载荷中一项已转存为 blob 的原始媒体。
+type MultimodalTaskMediaItem struct {
+	Modality string `json:"modality"`
+	Index    int    `json:"index"`
+	Key      string `json:"key"`
+	MimeType string `json:"mime_type,omitempty"`
+	Size     int64  `json:"size,omitempty"`
+	URL      string `json:"url"`
 }
 
 // MultimodalTaskListItem 多模态任务状态列表条目（轻量，不返回原始payload）。
@@ -95,11 +128,28 @@ type DeleteMultimodalHistoryResponse struct {
 	Message  string `json:"message"`
 }
 
+// CancelMultimodalTaskResponse 取消任务响应体。
+type CancelMultimodalTaskResponse struct {
+	TaskID  string `json:"task_id"`
+	Status  string `json:"status"`
+	Message string `json:"message"`
+}
+
 // MultimodalTaskDetailResponse 单任务查询响应。
 type MultimodalTaskDetailResponse struct {
 	Task MultimodalTaskItem `json:"task"`
 }
 
+// MultimodalTaskProgressEvent 任务进度 SSE 事件载荷。
+type MultimodalTaskProgressEvent struct {
+	ID        uint64                 `json:"id"`
+	TaskID    string                 `json:"task_id"`
+	Stage     string                 `json:"stage"`
+	Message   string                 `json:"message"`
+	Data      map[string]interface{} `json:"data,omitempty"`
+	CreatedAt string                 `json:"created_at"`
+}
+
 // MultimodalRiskLevelStats 风险等级统计（高/中/低）。
 type MultimodalRiskLevelStats struct {
 	High   int `json:"high"`
@@ -132,3 +182,52 @@ type MultimodalRiskOverviewResponse struct {
 	Trend    []MultimodalRiskTrendItem   `json:"trend"`
 	Analysis MultimodalRiskTrendAnalysis `json:"analysis"`
 }
+
+// MultimodalRiskDimension 风险解释中的单个评分维度得分。
+type MultimodalRiskDimension struct {
+	Key   string `json:"key"`
+	Label string `json:"label"`
+	Score int    `json:"score"`
+}
+
+// MultimodalRiskFactor 风险解释中的单个命中因子。
+type MultimodalRiskFactor struct {
+	Key            string `json:"key"`
+	Label          string `json:"label"`
+	Dimension      string `json:"dimension"`
+	DimensionLabel string `json:"dimension_label"`
+	Weight         int    `json:"weight"`
+}
+
+// MultimodalDynamicRiskLevel 风险解释中的动态阈值判级过程。
+type MultimodalDynamicRiskLevel struct {
+	BaseScore               int    `json:"base_score"`
+	HistoricalScore         int    `json:"historical_score"`
+	DynamicThreshold        int    `json:"dynamic_threshold"`
+	KnowledgeBaseHit        string `json:"knowledge_base_hit"`
+	KnowledgeBaseAdjustment int    `json:"knowledge_base_adjustment"`
+	UserHistoryHit          string `json:"user_history_hit"`
+	UserHistoryAdjustment   int    `json:"user_history_adjustment"`
+	AdjustedScore           int    `json:"adjusted_score"`
+	Summary                 string `json:"summary"`
+}
+
+// MultimodalRiskExplanationResponse 单条历史案件的风险等级解释。
+// source 为 breakdown 表示来自完整评分明细；risk_summary 表示早期记录由摘要回推；none 表示无可用明细。
+type MultimodalRiskExplanationResponse struct {
+	RecordID            string                      `json:"record_id"`
+	RiskLevel           string                      `json:"risk_level"`
+	RiskScore           int                         `json:"risk_score"`
+	RuleVersion         string                      `json:"rule_version,omitempty"`
+	Source              string                      `json:"source"`
+	Headline            string                      `json:"headline"`
+	Reasons             []string                    `json:"reasons"`
+	Dimensions          []MultimodalRiskDimension   `json:"dimensions"`
+	Factors             []MultimodalRiskFactor      `json:"factors"`
+	HitRules            []string                    `json:"hit_rules"`
+	KeyEvidence         []string                    `json:"key_evidence"`
+	VictimActionStage   string                      `json:"victim_action_stage,omitempty"`
+	SimilarCaseStrength string                      `json:"similar_case_strength,omitempty"`
+	MultimodalEvidence  string                      `json:"multimodal_evidence,omitempty"`
+	Dynamic             *MultimodalDynamicRiskLevel `json:"dynamic,omitempty"`
+}
@@ -3,11 +3,15 @@ package models
 // PendingReviewPreviewItem 待审核案件预览条目。
 type PendingReviewPreviewItem struct {
 	RecordID    string `json:"record_id"`
+	UserID      string `json:"user_id"`
 	Title       string `json:"title"`
 	TargetGroup string `json:"target_group"`
 	RiskLevel   string `json:"risk_level"`
 	ScamType    string `json:"scam_type"`
 	ViolatedLaw string `json:"violated_law"`
+	Source      string `json:"source"`
+	ClaimedBy   string `json:"claimed_by,omitempty"`
+	ClaimedAt   string `json:"claimed_at,omitempty"`
 	CreatedAt   string `json:"created_at"`
 }
 
@@ -19,19 +23,24 @@ type PendingReviewPreviewResponse struct {
 
 // PendingReviewDetailItem 待审核案件详情条目。
 type PendingReviewDetailItem struct {
-	RecordID        string   `json:"record_id"`
-	UserID          string   `json:"user_id"`
-	Title           string   `json:"title"`
-	TargetGroup     string   `json:"target_group"`
-	RiskLevel       string   `json:"risk_level"`
-	ScamType        string   `json:"scam_type"`
-	CaseDescription string   `json:"case_description"`
-	TypicalScripts  []string `json:"typical_scripts"`
-	Keywords        []string `json:"keywords"`
-	ViolatedLaw     string   `json:"violated_law"`
-	Suggestion      string   `json:"suggestion"`
-	CreatedAt       string   `json:"created_at"`
-	UpdatedAt       string   `json:"updated_at"`
+	RecordID        string             `json:"record_id"`
+	UserID          string             `json:"user_id"`
+	Title           string             `json:"title"`
+	TargetGroup     string             `json:"target_group"`
+	RiskLevel       string             `json:"risk_level"`
+	ScamType        string             `json:"scam_type"`
+	CaseDescription string             `json:"case_description"`
+	TypicalScripts  []string           `json:"typical_scripts"`
+	Keywords        []string           `json:"keywords"`
+	ViolatedLaw     string             `json:"violated_law"`
+	Suggestion      string             `json:"suggestion"`
+	Citations       []CaseCitationItem `json:"citations"`
+	Source          string             `json:"source"`
+	ClaimedBy       string             `json:"claimed_by,omitempty"`
+	ClaimedAt       string             `json:"claimed_at,omitempty"`
+	UpdatedBy       string             `json:"updated_by,omitempty"`
+	CreatedAt       string             `json:"created_at"`
+	UpdatedAt       string             `json:"updated_at"`
 }
 
 // PendingReviewDetailResponse 待审核案件详情响应体。
@@ -50,3 +59,56 @@ type RejectReviewResponse struct {
 	Message  string `json:"message"`
 	RecordID string `json:"record_id"`
 }
+
+// ApproveReviewRequest 审核通过请求体（可选）。
+type ApproveReviewRequest struct {
+	Comment string `json:"comment"`
+}
+
+// RejectReviewRequest 审核拒绝请求体（可选），reason 会写入审核审计日志。
+type RejectReviewRequest struct {
+	Reason string `json:"reason"`
+}
+
+// PatchPendingReviewRequest 待审核案件字段编辑请求体，未出现的字段保持不变。
+type PatchPendingReviewRequest struct {
+	Title           *string   `json:"title"`
+	TargetGroup     *string   `json:"target_group"`
+	RiskLevel       *string   `json:"risk_level"`
+	ScamType        *string   `json:"scam_type"`
+	CaseDescription *string   `json:"case_description"`
+	TypicalScripts  *[]string `json:"typical_scripts"`
+	Keywords        *[]string `json:"keywords"`
+	ViolatedLaw     *string   `json:"violated_law"`
+	Suggestion      *string   `json:"suggestion"`
+}
+
+// PendingReviewCommentRequest 审核意见请求体。
+type PendingReviewCommentRequest struct {
+	Comment string `json:"comment" binding:"required"`
+}
+
+// PendingReviewAuditItem 审核审计日志条目。
+type PendingReviewAuditItem struct {
+	ID         uint                            `json:"id"`
+	RecordID   string                          `json:"record_id"`
+	Action     string                          `json:"action"`
+	ReviewerID string                          `json:"reviewer_id"`
+	Title      string                          `json:"title"`
+	Reason     string                          `json:"reason,omitempty"`
+	Comment    string                          `json:"comment,omitempty"`
+	Changes    []HistoricalCaseFieldChangeItem `json:"changes,omitempty"`
+	CaseID     string                          `json:"case_id,omitempty"`
+	CreatedAt  string                          `json:"created_at"`
+}
+
+// PendingReviewAuditResponse 审核审计日志列表响应体。
+type PendingReviewAuditResponse struct {
+	Total int                      `json:"total"`
+	Logs  []PendingReviewAuditItem `json:"logs"`
+}
+
+// PendingReviewCommentResponse 审核意见响应体。
+type PendingReviewCommentResponse struct {
+	Log PendingReviewAuditItem `json:"log"`
+}
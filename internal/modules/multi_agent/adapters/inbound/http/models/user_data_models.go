@@ -0,0 +1,16 @@
+package models
+
+// PurgeUserDataRequest 用户数据清除请求体，export_id 为最近一次完整导出返回的导出凭证。
+type PurgeUserDataRequest struct {
+	ExportID string `json:"export_id" binding:"required"`
+}
+
+// PurgeUserDataResponse 用户数据清除响应体。
+type PurgeUserDataResponse struct {
+	UserID         string `json:"user_id"`
+	ExportID       string `json:"export_id"`
+	HistoryDeleted int    `json:"history_deleted"`
+	TasksDeleted   int    `json:"tasks_deleted"`
+	PurgedAt       string `json:"purged_at"`
+	Message        string `json:"message"`
+}
@@ -1,6 +1,7 @@
 package httpapi
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"sort"
@@ -8,37 +9,74 @@ import (
 	"time"
 
 	apimodel "antifraud/internal/modules/multi_agent/adapters/inbound/http/models"
+	"antifraud/internal/modules/multi_agent/adapters/outbound/mediastore"
 	"antifraud/internal/modules/multi_agent/adapters/outbound/state"
 	"antifraud/internal/modules/multi_agent/application/queue"
+	"antifraud/internal/modules/multi_agent/core"
+	"antifraud/internal/modules/multi_agent/domain/indicators"
 
 	"github.com/gin-gonic/gin"
 )
 
 // AnalyzeMultimodalScamHandle 处理多模态诈骗智能助手分析请求。
+// 支持 JSON（base64 或 media 引用）与 multipart/form-data（文件直接流式写入媒体存储）两种提交方式。
 func AnalyzeMultimodalScamHandle(c *gin.Context) {
+	userID := getCurrentUserID(c)
 	var payload apimodel.MultimodalScamAnalyzeRequest
-	if err := c.ShouldBindJSON(&payload); err != nil {
+	var uploadedMediaIDs []string
+	if strings.HasPrefix(strings.ToLower(c.ContentType()), "multipart/") {
+		parsed, mediaIDs, err := readMultimodalMultipart(c, userID)
+		uploadedMediaIDs = mediaIDs
+		if err != nil {
+			discardUploadedMedia(userID, uploadedMediaIDs)
+			writeMediaUploadError(c, "读取上传文件失败: ", err)
+			return
+		}
+		payload = parsed
+	} else if err := c.ShouldBindJSON(&payload); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误: " + err.Error()})
 		return
 	}
+	// multipart 上传的文件只服务于本次分析：任务载荷直接引用其 blob 内容，入队结束后删除媒体只释放媒体自身的引用。
+	defer discardUploadedMedia(userID, uploadedMediaIDs)
 
 	hasText := strings.TrimSpace(payload.Text) != ""
 	hasVideos := len(payload.Videos) > 0
 	hasAudios := len(payload.Audios) > 0
 	hasImages := len(payload.Images) > 0
-	if !hasText && !hasVideos && !hasAudios && !hasImages {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "至少提供 text/videos/audios/images 其中一种输入"})
+	hasInputs := false
+	for kind, items := range payload.Inputs {
+		if _, ok := multi_agent.LookupModalityAnalyzer(kind); !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "不支持的输入类型: " + kind})
+			return
+		}
+		hasInputs = hasInputs || len(items) > 0
+	}
+	for kind, mediaIDs := range payload.Media {
+		if !isSupportedMediaModality(kind) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "不支持的输入类型: " + kind})
+			return
+		}
+		hasInputs = hasInputs || len(mediaIDs) > 0
+	}
+	if !hasText && !hasVideos && !hasAudios && !hasImages && !hasInputs {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "至少提供 text/videos/audios/images/inputs/media 其中一种输入"})
 		return
 	}
 
-	userID := getCurrentUserID(c)
-	task, err := queue.EnqueueMultimodalTask(userID, queue.EnqueueRequest{
-		Text:   payload.Text,
-		Videos: payload.Videos,
-		Audios: payload.Audios,
-		Images: payload.Images,
+	task, err := queue.EnqueueMultimodalTask(c.Request.Context(), userID, queue.EnqueueRequest{
+		Text:     payload.Text,
+		Videos:   payload.Videos,
+		Audios:   payload.Audios,
+		Images:   payload.Images,
+		Inputs:   payload.Inputs,
+		MediaIDs: payload.Media,
 	})
 	if err != nil {
+		if errors.Is(err, mediastore.ErrMediaNotFound) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "引用的媒体不存在或已过期: " + err.Error()})
+			return
+		}
 		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "任务入队失败: " + err.Error()})
 		return
 	}
@@ -57,7 +95,7 @@ func GetMultimodalTaskStateHandle(c *gin.Context) {
 	tasks := make([]apimodel.MultimodalTaskListItem, 0, len(view.Pending))
 
 	for _, task := range view.Pending {
-		tasks = append(tasks, toTaskListItem(task))
+		tasks = append(tasks, toTaskListItem(state.RestoreTaskPII(task)))
 	}
 
 	sort.Slice(tasks, func(i, j int) bool {
@@ -76,7 +114,8 @@ func GetMultimodalHistoryHandle(c *gin.Context) {
 	view := queue.GetUserTaskState(userID)
 
 	history := make([]apimodel.MultimodalHistoryItem, 0, len(view.History))
-	for _, item := range view.History {
+	for _, record := range view.History {
+		item := state.RestoreCaseHistoryPII(record)
 		history = append(history, apimodel.MultimodalHistoryItem{
 			RecordID:    item.RecordID,
 			Title:       item.Title,
@@ -134,12 +173,42 @@ func GetMultimodalTaskDetailHandle(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, apimodel.MultimodalTaskDetailResponse{Task: toTaskItem(task)})
+	c.JSON(http.StatusOK, apimodel.MultimodalTaskDetailResponse{Task: toTaskItem(state.RestoreTaskPII(task))})
+}
+
+// CancelMultimodalTaskHandle 取消当前用户指定的进行中任务。
+func CancelMultimodalTaskHandle(c *gin.Context) {
+	userID := getCurrentUserID(c)
+	taskID := strings.TrimSpace(c.Param("taskId"))
+	if taskID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "taskId 不能为空"})
+		return
+	}
+
+	task, cancelled, err := queue.CancelMultimodalTask(userID, taskID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "取消任务失败: " + err.Error()})
+		return
+	}
+	if !cancelled {
+		if _, exists := state.GetTaskDetailByID(userID, taskID); exists {
+			c.JSON(http.StatusConflict, gin.H{"error": "任务已结束，无法取消"})
+			return
+		}
+		c.JSON(http.StatusNotFound, gin.H{"error": "任务不存在"})
+		return
+	}
+
+	c.JSON(http.StatusOK, apimodel.CancelMultimodalTaskResponse{
+		TaskID:  task.TaskID,
+		Status:  task.Status,
+		Message: "任务已取消，正在执行的分析将尽快停止",
+	})
 }
 
 // toTaskItem 将内部任务结构转换为 API 任务详情结构。
 func toTaskItem(task state.TaskRecord) apimodel.MultimodalTaskItem {
-	return apimodel.MultimodalTaskItem{
+	item := apimodel.MultimodalTaskItem{
 		TaskID:      task.TaskID,
 		UserID:      task.UserID,
 		Title:       task.Title,
@@ -147,6 +216,7 @@ func toTaskItem(task state.TaskRecord) apimodel.MultimodalTaskItem {
 		ScamType:    task.ScamType,
 		RiskScore:   task.RiskScore,
 		RiskSummary: strings.TrimSpace(task.RiskSummary),
+		RuleVersion: strings.TrimSpace(task.RuleVersion),
 		CreatedAt:   task.CreatedAt.Format(time.RFC3339),
 		UpdatedAt:   task.UpdatedAt.Format(time.RFC3339),
 		Payload: apimodel.MultimodalTaskPayload{
@@ -157,12 +227,28 @@ func toTaskItem(task state.TaskRecord) apimodel.MultimodalTaskItem {
 			VideoInsights: append([]string{}, task.Payload.VideoInsights...),
 			AudioInsights: append([]string{}, task.Payload.AudioInsights...),
 			ImageInsights: append([]string{}, task.Payload.ImageInsights...),
+			Inputs:        state.CloneModalityLists(task.Payload.ExtraInputs),
+			Insights:      state.CloneModalityLists(task.Payload.ExtraInsights),
 		},
+		Media:      toTaskMediaItems(task),
 		Summary:    strings.TrimSpace(task.Summary),
 		Report:     task.Report,
 		Error:      task.Error,
 		HistoryRef: task.HistoryRef,
 	}
+	if task.MediaPurgedAt != nil {
+		item.MediaPurgedAt = task.MediaPurgedAt.Format(time.RFC3339)
+	}
+	for _, indicator := range task.Indicators {
+		item.Indicators = append(item.Indicators, apimodel.MultimodalTaskIndicator{
+			Kind:        indicator.Kind,
+			Label:       indicators.KindLabel(indicator.Kind),
+			Value:       indicator.Value,
+			Fingerprint: indicator.Fingerprint,
+			Sources:     append([]string{}, indicator.Sources...),
+		})
+	}
+	return item
 }
 
 // toTaskListItem 将内部任务结构转换为任务列表项结构。
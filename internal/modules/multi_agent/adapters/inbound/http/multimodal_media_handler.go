@@ -0,0 +1,103 @@
+package httpapi
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+
+	apimodel "antifraud/internal/modules/multi_agent/adapters/inbound/http/models"
+	"antifraud/internal/modules/multi_agent/adapters/outbound/blobstore"
+	"antifraud/internal/modules/multi_agent/adapters/outbound/state"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetMultimodalTaskMediaHandle 流式返回任务或历史案件载荷中引用的原始媒体。
+// 说明：
+// 1) 只允许下载当前用户任务/历史载荷中实际引用的 blob，避免凭摘要读取他人媒体；
+// 2) 本地后端支持 Range 请求，便于历史详情页拖动播放音视频；
+// 3) blob 内容不可变，以摘要作为 ETag 并允许客户端长期缓存。
+func GetMultimodalTaskMediaHandle(c *gin.Context) {
+	userID := getCurrentUserID(c)
+	taskID := strings.TrimSpace(c.Param("taskId"))
+	if taskID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "taskId 不能为空"})
+		return
+	}
+	key := strings.ToLower(strings.TrimSpace(c.Param("blobKey")))
+	if !blobstore.IsKey(key) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "媒体标识格式不正确"})
+		return
+	}
+
+	task, exists := state.GetTaskDetailByID(userID, taskID)
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "任务不存在"})
+		return
+	}
+	if !slices.Contains(state.PayloadBlobKeys(task.Payload), key) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "媒体不存在"})
+		return
+	}
+
+	blob, reader, err := blobstore.DefaultStore().Open(c.Request.Context(), key)
+	if err != nil {
+		if errors.Is(err, blobstore.ErrBlobNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "媒体不存在"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "读取媒体失败: " + err.Error()})
+		return
+	}
+	defer reader.Close()
+
+	c.Header("Cache-Control", "private, max-age=86400, immutable")
+	c.Header("ETag", `"`+key+`"`)
+	c.Header("X-Content-Type-Options", "nosniff")
+	if seeker, ok := reader.(io.ReadSeeker); ok {
+		c.Header("Content-Type", blob.MIME)
+		http.ServeContent(c.Writer, c.Request, "", blob.CreatedAt, seeker)
+		return
+	}
+	c.DataFromReader(http.StatusOK, blob.Size, blob.MIME, reader, nil)
+}
+
+// toTaskMediaItems 按 视频/音频/图片/扩展模态 的顺序列出载荷中的 blob 引用及其下载地址。
+func toTaskMediaItems(task state.TaskRecord) []apimodel.MultimodalTaskMediaItem {
+	if len(state.PayloadBlobKeys(task.Payload)) == 0 {
+		return nil
+	}
+	modalities := []string{state.ModalityVideo, state.ModalityAudio, state.ModalityImage}
+	extra := make([]string, 0, len(task.Payload.ExtraInputs))
+	for modality := range task.Payload.ExtraInputs {
+		extra = append(extra, modality)
+	}
+	sort.Strings(extra)
+	modalities = append(modalities, extra...)
+
+	store := blobstore.DefaultStore()
+	items := make([]apimodel.MultimodalTaskMediaItem, 0)
+	for _, modality := range modalities {
+		for index, input := range task.Payload.ModalityInputs(modality) {
+			key, ok := blobstore.ParseRef(input)
+			if !ok {
+				continue
+			}
+			item := apimodel.MultimodalTaskMediaItem{
+				Modality: modality,
+				Index:    index,
+				Key:      key,
+				URL:      "/api/scam/multimodal/tasks/" + task.TaskID + "/media/" + key,
+			}
+			if blob, err := store.Stat(key); err == nil {
+				item.MimeType = blob.MIME
+				item.Size = blob.Size
+			}
+			items = append(items, item)
+		}
+	}
+	return items
+}
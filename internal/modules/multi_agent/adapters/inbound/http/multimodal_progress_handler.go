@@ -0,0 +1,161 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	apimodel "antifraud/internal/modules/multi_agent/adapters/inbound/http/models"
+	"antifraud/internal/modules/multi_agent/adapters/outbound/state"
+	"antifraud/internal/platform/redaction"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 轮询兜底用于感知其他实例写入的事件与任务归档；保活注释避免代理因空闲断开长连接。
+const (
+	taskProgressPageSize          = 200
+	taskProgressPollInterval      = time.Second
+	taskProgressKeepAliveInterval = 15 * time.Second
+)
+
+// StreamMultimodalTaskProgressHandle 以 SSE 推送当前用户指定任务的阶段事件。
+// 流程：
+// 1) 先补发 Last-Event-ID（或 after_id）之后的历史事件，支持断线续读；
+// 2) 本进程写入事件时立即推送，其他实例写入的事件由定时轮询兜底；
+// 3) 任务归档（完成/失败/取消）后推送 done 事件（携带任务详情与报告）并结束连接。
+func StreamMultimodalTaskProgressHandle(c *gin.Context) {
+	userID := getCurrentUserID(c)
+	taskID := strings.TrimSpace(c.Param("taskId"))
+	if taskID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "taskId 不能为空"})
+		return
+	}
+	if _, exists := state.GetTaskDetailByID(userID, taskID); !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "任务不存在"})
+		return
+	}
+
+	lastEventID, err := parseTaskProgressCursor(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "事件游标格式错误: " + err.Error()})
+		return
+	}
+
+	notify, unsubscribe := state.SubscribeTaskProgress(taskID)
+	defer unsubscribe()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	pollTicker := time.NewTicker(taskProgressPollInterval)
+	defer pollTicker.Stop()
+	keepAliveTicker := time.NewTicker(taskProgressKeepAliveInterval)
+	defer keepAliveTicker.Stop()
+
+	streamCtx := c.Request.Context()
+	for {
+		// 先判断是否已归档再读取事件：阶段事件均在归档前写入，保证 done 之前不会漏推事件。
+		_, inProgress := state.GetTask(userID, taskID)
+
+		for {
+			events, err := state.ListTaskProgressEvents(userID, taskID, lastEventID, taskProgressPageSize)
+			if err != nil {
+				writeTaskProgressSSE(c, 0, "error", gin.H{"error": "查询任务进度失败: " + err.Error()})
+				return
+			}
+			for _, event := range events {
+				if event.Stage == state.TaskProgressStageFinalReport {
+					event = restoreTaskProgressReport(userID, event)
+				}
+				writeTaskProgressSSE(c, event.ID, "progress", toTaskProgressEvent(event))
+				lastEventID = event.ID
+			}
+			if len(events) < taskProgressPageSize {
+				break
+			}
+		}
+
+		if !inProgress {
+			task, exists := state.GetTaskDetailByID(userID, taskID)
+			if !exists {
+				writeTaskProgressSSE(c, 0, "error", gin.H{"error": "任务不存在"})
+				return
+			}
+			writeTaskProgressSSE(c, 0, "done", apimodel.MultimodalTaskDetailResponse{Task: toTaskItem(state.RestoreTaskPII(task))})
+			return
+		}
+
+		select {
+		case <-streamCtx.Done():
+			return
+		case <-notify:
+		case <-pollTicker.C:
+		case <-keepAliveTicker.C:
+			_, _ = fmt.Fprint(c.Writer, ": keep-alive\n\n")
+			c.Writer.Flush()
+		}
+	}
+}
+
+// parseTaskProgressCursor 读取续读游标：优先使用浏览器自动携带的 Last-Event-ID，其次是 after_id 查询参数。
+func parseTaskProgressCursor(c *gin.Context) (uint64, error) {
+	raw := strings.TrimSpace(c.GetHeader("Last-Event-ID"))
+	if raw == "" {
+		raw = strings.TrimSpace(c.Query("after_id"))
+	}
+	if raw == "" {
+		return 0, nil
+	}
+	return strconv.ParseUint(raw, 10, 64)
+}
+
+// writeTaskProgressSSE 写出一条 SSE 事件；id 为 0 时不写 id 字段，避免覆盖客户端的续读游标。
+func writeTaskProgressSSE(c *gin.Context, id uint64, eventType string, payload interface{}) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		data = []byte(`{"error":"encode event failed"}`)
+	}
+	var builder strings.Builder
+	if id > 0 {
+		builder.WriteString(fmt.Sprintf("id: %d\n", id))
+	}
+	builder.WriteString("event: " + eventType + "\n")
+	builder.WriteString("data: " + string(data) + "\n\n")
+	_, _ = c.Writer.WriteString(builder.String())
+	c.Writer.Flush()
+}
+
+// restoreTaskProgressReport 还原 final_report 事件中报告的占位符，其他阶段事件不含用户文本，原样推送。
+func restoreTaskProgressReport(userID string, event state.TaskProgressEvent) state.TaskProgressEvent {
+	report, ok := event.Data["report"].(string)
+	if !ok || report == "" {
+		return event
+	}
+	data := make(map[string]interface{}, len(event.Data))
+	for key, value := range event.Data {
+		data[key] = value
+	}
+	data["report"] = redaction.NewRedactor(state.GetTaskRedactionMapping(userID, event.TaskID)).Restore(report)
+	event.Data = data
+	return event
+}
+
+// toTaskProgressEvent 将内部进度事件转换为 API 事件结构。
+func toTaskProgressEvent(event state.TaskProgressEvent) apimodel.MultimodalTaskProgressEvent {
+	return apimodel.MultimodalTaskProgressEvent{
+		ID:        event.ID,
+		TaskID:    event.TaskID,
+		Stage:     event.Stage,
+		Message:   event.Message,
+		Data:      event.Data,
+		CreatedAt: event.CreatedAt.Format(time.RFC3339),
+	}
+}
@@ -14,9 +14,17 @@ import (
 var reviewCaseLibraryService = case_library.DefaultService()
 var rejectPendingReview = reviewCaseLibraryService.RejectPendingReview
 
-// GetPendingReviewCasesHandle 返回所有待审核案件预览列表。
+// GetPendingReviewCasesHandle 返回待审核案件预览列表。
+// 支持 scam_type、source（user/agent）、claimed_by（审核员 ID、me 或 none）、
+// older_than/newer_than（Go duration，如 24h）筛选。
 func GetPendingReviewCasesHandle(c *gin.Context) {
-	previews, err := reviewCaseLibraryService.ListPendingReviewPreviews()
+	filter, err := parsePendingReviewFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	previews, err := reviewCaseLibraryService.ListPendingReviewPreviews(filter)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "待审核案件查询失败: " + err.Error()})
 		return
@@ -24,15 +32,22 @@ func GetPendingReviewCasesHandle(c *gin.Context) {
 
 	items := make([]apimodel.PendingReviewPreviewItem, 0, len(previews))
 	for _, p := range previews {
-		items = append(items, apimodel.PendingReviewPreviewItem{
+		item := apimodel.PendingReviewPreviewItem{
 			RecordID:    p.RecordID,
+			UserID:      p.UserID,
 			Title:       p.Title,
 			TargetGroup: p.TargetGroup,
 			RiskLevel:   p.RiskLevel,
 			ScamType:    p.ScamType,
 			ViolatedLaw: p.ViolatedLaw,
+			Source:      p.Source,
+			ClaimedBy:   p.ClaimedBy,
 			CreatedAt:   p.CreatedAt.Format(time.RFC3339),
-		})
+		}
+		if !p.ClaimedAt.IsZero() {
+			item.ClaimedAt = p.ClaimedAt.Format(time.RFC3339)
+		}
+		items = append(items, item)
 	}
 
 	c.JSON(http.StatusOK, apimodel.PendingReviewPreviewResponse{
@@ -61,36 +76,25 @@ func GetPendingReviewCaseDetailHandle(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, apimodel.PendingReviewDetailResponse{
-		Case: apimodel.PendingReviewDetailItem{
-			RecordID:        record.RecordID,
-			UserID:          record.UserID,
-			Title:           record.Title,
-			TargetGroup:     record.TargetGroup,
-			RiskLevel:       record.RiskLevel,
-			ScamType:        record.ScamType,
-			CaseDescription: record.CaseDescription,
-			TypicalScripts:  append([]string{}, record.TypicalScripts...),
-			Keywords:        append([]string{}, record.Keywords...),
-			ViolatedLaw:     record.ViolatedLaw,
-			Suggestion:      record.Suggestion,
-			CreatedAt:       record.CreatedAt.Format(time.RFC3339),
-			UpdatedAt:       record.UpdatedAt.Format(time.RFC3339),
-		},
-	})
+	c.JSON(http.StatusOK, apimodel.PendingReviewDetailResponse{Case: toPendingReviewDetailItem(record)})
 }
 
-// ApprovePendingReviewCaseHandle 审核通过待审核案件，入库知识库。
+// ApprovePendingReviewCaseHandle 审核通过待审核案件，入库知识库；请求体可附带 comment。
 func ApprovePendingReviewCaseHandle(c *gin.Context) {
 	recordID := strings.TrimSpace(c.Param("recordId"))
 	if recordID == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "recordId 不能为空"})
 		return
 	}
+	var payload apimodel.ApproveReviewRequest
+	if err := bindOptionalJSON(c, &payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误: " + err.Error()})
+		return
+	}
 
-	record, err := reviewCaseLibraryService.ApprovePendingReview(c.Request.Context(), recordID)
+	record, err := reviewCaseLibraryService.ApprovePendingReview(c.Request.Context(), recordID, getCurrentUserID(c), payload.Comment)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "审核入库失败: " + err.Error()})
+		writePendingReviewActionError(c, err, "审核入库失败: ")
 		return
 	}
 
@@ -100,20 +104,21 @@ func ApprovePendingReviewCaseHandle(c *gin.Context) {
 	})
 }
 
-// RejectPendingReviewCaseHandle 审核拒绝待审核案件，从待审核列表移除。
+// RejectPendingReviewCaseHandle 审核拒绝待审核案件，从待审核列表移除；请求体可附带拒绝原因 reason。
 func RejectPendingReviewCaseHandle(c *gin.Context) {
 	recordID := strings.TrimSpace(c.Param("recordId"))
 	if recordID == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "recordId 不能为空"})
 		return
 	}
+	var payload apimodel.RejectReviewRequest
+	if err := bindOptionalJSON(c, &payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误: " + err.Error()})
+		return
+	}
 
-	if err := rejectPendingReview(c.Request.Context(), recordID); err != nil {
-		if strings.Contains(err.Error(), "not found or already processed") {
-			c.JSON(http.StatusNotFound, gin.H{"error": "待审核案件不存在或已处理"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "审核拒绝失败: " + err.Error()})
+	if err := rejectPendingReview(c.Request.Context(), recordID, getCurrentUserID(c), payload.Reason); err != nil {
+		writePendingReviewActionError(c, err, "审核拒绝失败: ")
 		return
 	}
 
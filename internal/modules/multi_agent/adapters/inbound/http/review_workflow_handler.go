@@ -0,0 +1,250 @@
+package httpapi
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	apimodel "antifraud/internal/modules/multi_agent/adapters/inbound/http/models"
+	"antifraud/internal/modules/multi_agent/adapters/outbound/case_library"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ClaimPendingReviewCaseHandle 认领待审核案件，认领期间其他审核员不能编辑、通过或拒绝。
+func ClaimPendingReviewCaseHandle(c *gin.Context) {
+	recordID := strings.TrimSpace(c.Param("recordId"))
+	if recordID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "recordId 不能为空"})
+		return
+	}
+
+	record, err := reviewCaseLibraryService.ClaimPendingReview(c.Request.Context(), recordID, getCurrentUserID(c))
+	if err != nil {
+		writePendingReviewActionError(c, err, "认领待审核案件失败: ")
+		return
+	}
+	c.JSON(http.StatusOK, apimodel.PendingReviewDetailResponse{Case: toPendingReviewDetailItem(record)})
+}
+
+// ReleasePendingReviewCaseHandle 释放本人对待审核案件的认领。
+func ReleasePendingReviewCaseHandle(c *gin.Context) {
+	recordID := strings.TrimSpace(c.Param("recordId"))
+	if recordID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "recordId 不能为空"})
+		return
+	}
+
+	record, err := reviewCaseLibraryService.ReleasePendingReview(c.Request.Context(), recordID, getCurrentUserID(c))
+	if err != nil {
+		writePendingReviewActionError(c, err, "释放待审核案件失败: ")
+		return
+	}
+	c.JSON(http.StatusOK, apimodel.PendingReviewDetailResponse{Case: toPendingReviewDetailItem(record)})
+}
+
+// PatchPendingReviewCaseHandle 在入库前修改待审核案件字段，仅修改请求中出现的字段。
+func PatchPendingReviewCaseHandle(c *gin.Context) {
+	recordID := strings.TrimSpace(c.Param("recordId"))
+	if recordID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "recordId 不能为空"})
+		return
+	}
+	var payload apimodel.PatchPendingReviewRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误: " + err.Error()})
+		return
+	}
+
+	record, err := reviewCaseLibraryService.UpdatePendingReview(c.Request.Context(), recordID, getCurrentUserID(c), case_library.UpdateHistoricalCaseInput{
+		Title:           payload.Title,
+		TargetGroup:     payload.TargetGroup,
+		RiskLevel:       payload.RiskLevel,
+		ScamType:        payload.ScamType,
+		CaseDescription: payload.CaseDescription,
+		TypicalScripts:  payload.TypicalScripts,
+		Keywords:        payload.Keywords,
+		ViolatedLaw:     payload.ViolatedLaw,
+		Suggestion:      payload.Suggestion,
+	})
+	if err != nil {
+		writePendingReviewActionError(c, err, "待审核案件更新失败: ")
+		return
+	}
+	c.JSON(http.StatusOK, apimodel.PendingReviewDetailResponse{Case: toPendingReviewDetailItem(record)})
+}
+
+// AddPendingReviewCommentHandle 为待审核案件追加审核意见。
+func AddPendingReviewCommentHandle(c *gin.Context) {
+	recordID := strings.TrimSpace(c.Param("recordId"))
+	if recordID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "recordId 不能为空"})
+		return
+	}
+	var payload apimodel.PendingReviewCommentRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误: " + err.Error()})
+		return
+	}
+
+	log, err := reviewCaseLibraryService.AddPendingReviewComment(c.Request.Context(), recordID, getCurrentUserID(c), payload.Comment)
+	if err != nil {
+		writePendingReviewActionError(c, err, "添加审核意见失败: ")
+		return
+	}
+	c.JSON(http.StatusCreated, apimodel.PendingReviewCommentResponse{Log: toPendingReviewAuditItem(log)})
+}
+
+// GetPendingReviewCaseAuditHandle 返回指定待审核案件的审核审计日志，案件处理完成后仍可查询。
+func GetPendingReviewCaseAuditHandle(c *gin.Context) {
+	recordID := strings.TrimSpace(c.Param("recordId"))
+	if recordID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "recordId 不能为空"})
+		return
+	}
+	writePendingReviewAuditLogs(c, case_library.PendingReviewAuditFilter{RecordID: recordID})
+}
+
+// GetPendingReviewAuditLogsHandle 按审核员、动作筛选全部审核审计日志。
+func GetPendingReviewAuditLogsHandle(c *gin.Context) {
+	action := strings.TrimSpace(c.Query("action"))
+	if action != "" && !case_library.IsPendingReviewAuditAction(action) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "action 仅支持 claim、release、edit、comment、approve、reject"})
+		return
+	}
+	limit := 0
+	if raw := strings.TrimSpace(c.Query("limit")); raw != "" {
+		value, err := strconv.Atoi(raw)
+		if err != nil || value < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit 必须为正整数"})
+			return
+		}
+		limit = value
+	}
+	writePendingReviewAuditLogs(c, case_library.PendingReviewAuditFilter{
+		ReviewerID: strings.TrimSpace(c.Query("reviewer_id")),
+		Action:     action,
+		Limit:      limit,
+	})
+}
+
+func writePendingReviewAuditLogs(c *gin.Context, filter case_library.PendingReviewAuditFilter) {
+	logs, err := reviewCaseLibraryService.ListPendingReviewAuditLogs(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "审核日志查询失败: " + err.Error()})
+		return
+	}
+	items := make([]apimodel.PendingReviewAuditItem, 0, len(logs))
+	for _, log := range logs {
+		items = append(items, toPendingReviewAuditItem(log))
+	}
+	c.JSON(http.StatusOK, apimodel.PendingReviewAuditResponse{Total: len(items), Logs: items})
+}
+
+func parsePendingReviewFilter(c *gin.Context) (case_library.PendingReviewFilter, error) {
+	filter := case_library.PendingReviewFilter{
+		ScamType:  strings.TrimSpace(c.Query("scam_type")),
+		ClaimedBy: strings.TrimSpace(c.Query("claimed_by")),
+	}
+	switch source := strings.ToLower(strings.TrimSpace(c.Query("source"))); source {
+	case "", case_library.PendingReviewSourceUser, case_library.PendingReviewSourceAgent:
+		filter.Source = source
+	default:
+		return filter, fmt.Errorf("source 仅支持 user 或 agent")
+	}
+	if filter.ClaimedBy == "me" {
+		filter.ClaimedBy = getCurrentUserID(c)
+	}
+	for _, item := range []struct {
+		name   string
+		target *time.Duration
+	}{
+		{name: "older_than", target: &filter.OlderThan},
+		{name: "newer_than", target: &filter.NewerThan},
+	} {
+		raw := strings.TrimSpace(c.Query(item.name))
+		if raw == "" {
+			continue
+		}
+		value, err := time.ParseDuration(raw)
+		if err != nil || value <= 0 {
+			return filter, fmt.Errorf("%s 必须为正的时长，如 30m、24h", item.name)
+		}
+		*item.target = value
+	}
+	return filter, nil
+}
+
+// bindOptionalJSON 解析可选请求体，空请求体视为全部字段取零值。
+func bindOptionalJSON(c *gin.Context, target interface{}) error {
+	if err := c.ShouldBindJSON(target); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	return nil
+}
+
+func writePendingReviewActionError(c *gin.Context, err error, fallbackPrefix string) {
+	switch {
+	case errors.Is(err, case_library.ErrPendingReviewNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "待审核案件不存在或已处理"})
+	case errors.Is(err, case_library.ErrPendingReviewClaimedByOther):
+		c.JSON(http.StatusConflict, gin.H{"error": "待审核案件已被其他审核员认领: " + err.Error()})
+	default:
+		writeHistoricalCaseWriteError(c, err, fallbackPrefix)
+	}
+}
+
+func toPendingReviewDetailItem(record case_library.PendingReviewRecord) apimodel.PendingReviewDetailItem {
+	item := apimodel.PendingReviewDetailItem{
+		RecordID:        record.RecordID,
+		UserID:          record.UserID,
+		Title:           record.Title,
+		TargetGroup:     record.TargetGroup,
+		RiskLevel:       record.RiskLevel,
+		ScamType:        record.ScamType,
+		CaseDescription: record.CaseDescription,
+		TypicalScripts:  append([]string{}, record.TypicalScripts...),
+		Keywords:        append([]string{}, record.Keywords...),
+		ViolatedLaw:     record.ViolatedLaw,
+		Suggestion:      record.Suggestion,
+		Citations:       toCaseCitationItems(record.Citations),
+		Source:          record.Source,
+		ClaimedBy:       record.ClaimedBy,
+		UpdatedBy:       record.UpdatedBy,
+		CreatedAt:       record.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:       record.UpdatedAt.Format(time.RFC3339),
+	}
+	if !record.ClaimedAt.IsZero() {
+		item.ClaimedAt = record.ClaimedAt.Format(time.RFC3339)
+	}
+	return item
+}
+
+func toPendingReviewAuditItem(log case_library.PendingReviewAuditLog) apimodel.PendingReviewAuditItem {
+	changes := make([]apimodel.HistoricalCaseFieldChangeItem, 0, len(log.Changes))
+	for _, change := range log.Changes {
+		changes = append(changes, apimodel.HistoricalCaseFieldChangeItem{
+			Field:   change.Field,
+			Before:  change.Before,
+			After:   change.After,
+			Added:   append([]string{}, change.Added...),
+			Removed: append([]string{}, change.Removed...),
+		})
+	}
+	return apimodel.PendingReviewAuditItem{
+		ID:         log.ID,
+		RecordID:   log.RecordID,
+		Action:     log.Action,
+		ReviewerID: log.ReviewerID,
+		Title:      log.Title,
+		Reason:     log.Reason,
+		Comment:    log.Comment,
+		Changes:    changes,
+		CaseID:     log.CaseID,
+		CreatedAt:  log.CreatedAt.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,80 @@
+package httpapi
+
+import (
+	"net/http"
+	"strings"
+
+	apimodel "antifraud/internal/modules/multi_agent/adapters/inbound/http/models"
+	"antifraud/internal/modules/multi_agent/adapters/outbound/state"
+	"antifraud/internal/modules/multi_agent/domain/explanation"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetMultimodalRiskExplanationHandle 返回当前用户某条历史案件"为什么是这个风险等级"的结构化解释。
+func GetMultimodalRiskExplanationHandle(c *gin.Context) {
+	userID := getCurrentUserID(c)
+	recordID := strings.TrimSpace(c.Param("recordId"))
+	if recordID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "recordId 不能为空"})
+		return
+	}
+
+	record, ok := state.GetCaseHistoryRecord(userID, recordID)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "历史案件不存在"})
+		return
+	}
+
+	result := explanation.BuildRiskExplanation(record)
+
+	dimensions := make([]apimodel.MultimodalRiskDimension, 0, len(result.Dimensions))
+	for _, item := range result.Dimensions {
+		dimensions = append(dimensions, apimodel.MultimodalRiskDimension{
+			Key:   item.Key,
+			Label: item.Label,
+			Score: item.Score,
+		})
+	}
+	factors := make([]apimodel.MultimodalRiskFactor, 0, len(result.Factors))
+	for _, item := range result.Factors {
+		factors = append(factors, apimodel.MultimodalRiskFactor{
+			Key:            item.Key,
+			Label:          item.Label,
+			Dimension:      item.Dimension,
+			DimensionLabel: item.DimensionLabel,
+			Weight:         item.Weight,
+		})
+	}
+
+	response := apimodel.MultimodalRiskExplanationResponse{
+		RecordID:            result.RecordID,
+		RiskLevel:           result.RiskLevel,
+		RiskScore:           result.RiskScore,
+		RuleVersion:         result.RuleVersion,
+		Source:              result.Source,
+		Headline:            result.Headline,
+		Reasons:             result.Reasons,
+		Dimensions:          dimensions,
+		Factors:             factors,
+		HitRules:            result.HitRules,
+		KeyEvidence:         result.KeyEvidence,
+		VictimActionStage:   result.VictimActionStage,
+		SimilarCaseStrength: result.SimilarCaseStrength,
+		MultimodalEvidence:  result.MultimodalEvidence,
+	}
+	if result.Dynamic != nil {
+		response.Dynamic = &apimodel.MultimodalDynamicRiskLevel{
+			BaseScore:               result.Dynamic.BaseScore,
+			HistoricalScore:         result.Dynamic.HistoricalScore,
+			DynamicThreshold:        result.Dynamic.DynamicThreshold,
+			KnowledgeBaseHit:        result.Dynamic.KnowledgeBaseHit,
+			KnowledgeBaseAdjustment: result.Dynamic.KnowledgeBaseAdjustment,
+			UserHistoryHit:          result.Dynamic.UserHistoryHit,
+			UserHistoryAdjustment:   result.Dynamic.UserHistoryAdjustment,
+			AdjustedScore:           result.Dynamic.AdjustedScore,
+			Summary:                 result.Dynamic.Summary,
+		}
+	}
+	c.JSON(http.StatusOK, response)
+}
@@ -10,6 +10,7 @@ import (
 
 	httpapi "antifraud/internal/modules/multi_agent/adapters/inbound/http"
 	apimodel "antifraud/internal/modules/multi_agent/adapters/inbound/http/models"
+	"antifraud/internal/modules/multi_agent/application/casecollection"
 	"antifraud/internal/modules/multi_agent/application/queue"
 
 	"github.com/gin-gonic/gin"
@@ -21,10 +22,18 @@ type stubCaseCollectionEnqueuer struct {
 	err        error
 }
 
-func (s *stubCaseCollectionEnqueuer) EnqueueCaseCollectionTask(userID string, request queue.CaseCollectionEnqueueRequest) error {
+func (s *stubCaseCollectionEnqueuer) EnqueueCaseCollectionTask(userID string, request queue.CaseCollectionEnqueueRequest) (casecollection.Job, error) {
 	s.lastUserID = userID
 	s.lastReq = request
-	return s.err
+	if s.err != nil {
+		return casecollection.Job{}, s.err
+	}
+	return casecollection.Job{
+		JobID:          "CASECOL-TEST",
+		Query:          request.Query,
+		RequestedCount: request.CaseCount,
+		Status:         casecollection.JobStatusRunning,
+	}, nil
 }
 
 func TestNewCollectCaseCollectionHandleSuccess(t *testing.T) {
@@ -64,6 +73,10 @@ func TestNewCollectCaseCollectionHandleSuccess(t *testing.T) {
 	if payload["message"] != "案件采集任务已在后台启动" {
 		t.Fatalf("unexpected response payload: %+v", payload)
 	}
+	job, _ := payload["job"].(map[string]interface{})
+	if job["job_id"] != "CASECOL-TEST" || job["status"] != casecollection.JobStatusRunning {
+		t.Fatalf("expected job snapshot in response, got %+v", payload)
+	}
 }
 
 func TestNewCollectCaseCollectionHandleBadRequest(t *testing.T) {
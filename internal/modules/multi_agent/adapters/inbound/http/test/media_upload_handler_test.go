@@ -0,0 +1,236 @@
+package httpapi_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	httpapi "antifraud/internal/modules/multi_agent/adapters/inbound/http"
+	apimodel "antifraud/internal/modules/multi_agent/adapters/inbound/http/models"
+	"antifraud/internal/modules/multi_agent/adapters/outbound/blobstore"
+	"antifraud/internal/modules/multi_agent/adapters/outbound/mediastore"
+	"antifraud/internal/modules/multi_agent/adapters/outbound/state"
+	"antifraud/internal/platform/database"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func setupMediaUploadRouter(t *testing.T, options mediastore.Options) (*gin.Engine, *mediastore.Store) {
+	t.Helper()
+
+	oldDB := database.DB
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "media_upload_test.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open sqlite failed: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("get sql db failed: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	database.DB = db
+	if err := database.InitMainDBSchemas(); err != nil {
+		t.Fatalf("init main db schemas failed: %v", err)
+	}
+	options.Dir = t.TempDir()
+	store := mediastore.NewStore(options)
+	previous := mediastore.SetDefaultStore(store)
+	blobBackend, err := blobstore.NewLocalBackend(t.TempDir())
+	if err != nil {
+		t.Fatalf("create blob backend failed: %v", err)
+	}
+	previousBlobs := blobstore.SetDefaultStore(blobstore.NewStore(blobBackend, blobstore.Options{}))
+	t.Cleanup(func() {
+		blobstore.SetDefaultStore(previousBlobs)
+		mediastore.SetDefaultStore(previous)
+		database.DB = oldDB
+		_ = sqlDB.Close()
+	})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		if userID := c.GetHeader("X-Test-User"); userID != "" {
+			c.Set("userID", userID)
+		} else {
+			c.Set("userID", "u-media")
+		}
+		c.Next()
+	})
+	router.POST("/analyze", httpapi.AnalyzeMultimodalScamHandle)
+	router.POST("/uploads", httpapi.CreateMediaUploadHandle)
+	router.GET("/uploads/:uploadId", httpapi.GetMediaUploadHandle)
+	router.PUT("/uploads/:uploadId/chunks/:index", httpapi.PutMediaUploadChunkHandle)
+	router.POST("/uploads/:uploadId/complete", httpapi.CompleteMediaUploadHandle)
+	router.DELETE("/uploads/:uploadId", httpapi.AbortMediaUploadHandle)
+	router.GET("/tasks/:taskId/media/:blobKey", httpapi.GetMultimodalTaskMediaHandle)
+	return router, store
+}
+
+func serveMediaRequest(router *gin.Engine, method string, path string, contentType string, body []byte) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, bytes.NewReader(body))
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	return resp
+}
+
+func TestMediaUploadHandlers_ChunkedUploadThenAnalyzeByReference(t *testing.T) {
+	router, _ := setupMediaUploadRouter(t, mediastore.Options{ChunkBytes: 4})
+	content := []byte("\x89PNG\r\n\x1a\nscreen")
+
+	body, _ := json.Marshal(apimodel.CreateMediaUploadRequest{FileName: "shot.png", TotalSize: int64(len(content))})
+	resp := serveMediaRequest(router, http.MethodPost, "/uploads", "application/json", body)
+	if resp.Code != http.StatusCreated {
+		t.Fatalf("create upload failed: code=%d body=%s", resp.Code, resp.Body.String())
+	}
+	var created apimodel.MediaUploadResponse
+	_ = json.Unmarshal(resp.Body.Bytes(), &created)
+	upload := created.Upload
+	if upload.TotalChunks != 4 || upload.ChunkSize != 4 {
+		t.Fatalf("unexpected upload session: %+v", upload)
+	}
+
+	for index := 0; index < upload.TotalChunks; index++ {
+		end := min((index+1)*4, len(content))
+		path := "/uploads/" + upload.UploadID + "/chunks/" + strconv.Itoa(index)
+		if resp := serveMediaRequest(router, http.MethodPut, path, "application/octet-stream", content[index*4:end]); resp.Code != http.StatusOK {
+			t.Fatalf("put chunk %d failed: code=%d body=%s", index, resp.Code, resp.Body.String())
+		}
+	}
+	if resp := serveMediaRequest(router, http.MethodPut, "/uploads/"+upload.UploadID+"/chunks/x", "", []byte("a")); resp.Code != http.StatusBadRequest {
+		t.Fatalf("expected invalid index rejected, got %d", resp.Code)
+	}
+	resp = serveMediaRequest(router, http.MethodGet, "/uploads/"+upload.UploadID, "", nil)
+	var progress apimodel.MediaUploadResponse
+	_ = json.Unmarshal(resp.Body.Bytes(), &progress)
+	if resp.Code != http.StatusOK || len(progress.Upload.ReceivedChunks) != 4 || progress.Upload.ReceivedBytes != int64(len(content)) {
+		t.Fatalf("unexpected upload progress: code=%d body=%s", resp.Code, resp.Body.String())
+	}
+
+	resp = serveMediaRequest(router, http.MethodPost, "/uploads/"+upload.UploadID+"/complete", "", nil)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("complete upload failed: code=%d body=%s", resp.Code, resp.Body.String())
+	}
+	var completed apimodel.CompleteMediaUploadResponse
+	_ = json.Unmarshal(resp.Body.Bytes(), &completed)
+	if completed.Media.MimeType != "image/png" || completed.Media.Ref != "media:"+completed.Media.MediaID {
+		t.Fatalf("unexpected completed media: %+v", completed.Media)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/analyze", strings.NewReader(`{"media":{"image":["`+completed.Media.MediaID+`"]}}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Test-User", "u-other")
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	if resp.Code != http.StatusBadRequest {
+		t.Fatalf("expected other user's media rejected, got code=%d body=%s", resp.Code, resp.Body.String())
+	}
+
+	analyzeBody := `{"text":"对方让我截图","media":{"image":["` + completed.Media.MediaID + `"]}}`
+	resp = serveMediaRequest(router, http.MethodPost, "/analyze", "application/json", []byte(analyzeBody))
+	if resp.Code != http.StatusAccepted {
+		t.Fatalf("analyze by reference failed: code=%d body=%s", resp.Code, resp.Body.String())
+	}
+	var enqueued apimodel.MultimodalScamEnqueueResponse
+	_ = json.Unmarshal(resp.Body.Bytes(), &enqueued)
+	task, ok := state.GetTaskDetailByID("u-media", enqueued.TaskID)
+	if !ok || len(task.Payload.Images) != 1 || !strings.HasPrefix(task.Payload.Images[0], blobstore.RefPrefix) {
+		t.Fatalf("expected referenced media stored as blob reference, got %+v ok=%v", task.Payload.Images, ok)
+	}
+	key, _ := blobstore.ParseRef(task.Payload.Images[0])
+	if _, raw, err := blobstore.DefaultStore().ReadAll(t.Context(), key); err != nil || !bytes.Equal(raw, content) {
+		t.Fatalf("expected blob content matches upload, got %q err=%v", raw, err)
+	}
+	if resp := serveMediaRequest(router, http.MethodPost, "/analyze", "application/json", []byte(`{"images":["`+task.Payload.Images[0]+`"]}`)); resp.Code != http.StatusBadRequest {
+		t.Fatalf("expected client supplied blob reference rejected, got code=%d body=%s", resp.Code, resp.Body.String())
+	}
+
+	if resp := serveMediaRequest(router, http.MethodDelete, "/uploads/"+upload.UploadID, "", nil); resp.Code != http.StatusOK {
+		t.Fatalf("abort upload failed: code=%d body=%s", resp.Code, resp.Body.String())
+	}
+	if resp := serveMediaRequest(router, http.MethodGet, "/uploads/"+upload.UploadID, "", nil); resp.Code != http.StatusNotFound {
+		t.Fatalf("expected aborted upload missing, got %d", resp.Code)
+	}
+}
+
+func TestAnalyzeMultimodalScamHandle_MultipartStreamsFilesIntoStore(t *testing.T) {
+	router, _ := setupMediaUploadRouter(t, mediastore.Options{MaxFileBytes: 64})
+	image := []byte("\x89PNG\r\n\x1a\nmultipart")
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	_ = writer.WriteField("text", "客服让我下载会议软件")
+	part, _ := writer.CreateFormFile("images", "chat.png")
+	_, _ = part.Write(image)
+	_ = writer.Close()
+
+	resp := serveMediaRequest(router, http.MethodPost, "/analyze", writer.FormDataContentType(), body.Bytes())
+	if resp.Code != http.StatusAccepted {
+		t.Fatalf("multipart analyze failed: code=%d body=%s", resp.Code, resp.Body.String())
+	}
+	var enqueued apimodel.MultimodalScamEnqueueResponse
+	_ = json.Unmarshal(resp.Body.Bytes(), &enqueued)
+	task, ok := state.GetTaskDetailByID("u-media", enqueued.TaskID)
+	if !ok || task.Payload.Text != "客服让我下载会议软件" || len(task.Payload.Images) != 1 {
+		t.Fatalf("unexpected multipart task payload: %+v ok=%v", task.Payload, ok)
+	}
+	key, ok := blobstore.ParseRef(task.Payload.Images[0])
+	if !ok {
+		t.Fatalf("expected multipart image stored as blob reference, got %q", task.Payload.Images[0])
+	}
+	resp = serveMediaRequest(router, http.MethodGet, "/tasks/"+enqueued.TaskID+"/media/"+key, "", nil)
+	if resp.Code != http.StatusOK || resp.Header().Get("Content-Type") != "image/png" || !bytes.Equal(resp.Body.Bytes(), image) {
+		t.Fatalf("unexpected media download: code=%d type=%s body=%q", resp.Code, resp.Header().Get("Content-Type"), resp.Body.Bytes())
+	}
+	req := httptest.NewRequest(http.MethodGet, "/tasks/"+enqueued.TaskID+"/media/"+key, nil)
+	req.Header.Set("X-Test-User", "u-other")
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	if resp.Code != http.StatusNotFound {
+		t.Fatalf("expected other user's media download rejected, got %d", resp.Code)
+	}
+	if resp := serveMediaRequest(router, http.MethodGet, "/tasks/"+enqueued.TaskID+"/media/"+strings.Repeat("0", 64), "", nil); resp.Code != http.StatusNotFound {
+		t.Fatalf("expected unreferenced blob rejected, got %d", resp.Code)
+	}
+	// 一次性上传的媒体入队后即删除，内容只剩任务自身的引用。
+	if blob, err := blobstore.DefaultStore().Stat(key); err != nil || blob.RefCount != 1 {
+		t.Fatalf("expected one-off multipart media discarded after enqueue, got %+v err=%v", blob, err)
+	}
+
+	body.Reset()
+	writer = multipart.NewWriter(&body)
+	part, _ = writer.CreateFormFile("images", "large.png")
+	_, _ = part.Write(make([]byte, 65))
+	_ = writer.Close()
+	if resp := serveMediaRequest(router, http.MethodPost, "/analyze", writer.FormDataContentType(), body.Bytes()); resp.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected oversized file rejected, got code=%d body=%s", resp.Code, resp.Body.String())
+	}
+
+	body.Reset()
+	writer = multipart.NewWriter(&body)
+	part, _ = writer.CreateFormFile("images", "ok.png")
+	_, _ = part.Write(image)
+	part, _ = writer.CreateFormFile("attachments", "unknown.bin")
+	_, _ = part.Write([]byte("x"))
+	_ = writer.Close()
+	if resp := serveMediaRequest(router, http.MethodPost, "/analyze", writer.FormDataContentType(), body.Bytes()); resp.Code != http.StatusBadRequest {
+		t.Fatalf("expected unknown file field rejected, got code=%d body=%s", resp.Code, resp.Body.String())
+	}
+	if blob, err := blobstore.DefaultStore().Stat(key); err != nil || blob.RefCount != 1 {
+		t.Fatalf("expected media from rejected request cleaned up, got %+v err=%v", blob, err)
+	}
+}
@@ -0,0 +1,60 @@
+package httpapi_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	httpapi "antifraud/internal/modules/multi_agent/adapters/inbound/http"
+	apimodel "antifraud/internal/modules/multi_agent/adapters/inbound/http/models"
+	"antifraud/internal/modules/multi_agent/adapters/outbound/state"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestMultimodalListHandles_RestoreRedactedPlaceholders(t *testing.T) {
+	setupTaskProgressDB(t)
+	pending := state.CreateTask("1", state.TaskPayload{Text: "[姓名_1]来电要求转账"})
+	state.SaveTaskRedactionMapping("1", pending.TaskID, map[string]string{"[姓名_1]": "张三"})
+	state.AddCaseHistory(state.CaseHistoryRecord{
+		RecordID:    "TASK-LIST-PII",
+		UserID:      "1",
+		Title:       "[电话号码_1]冒充客服",
+		CaseSummary: "对方用[电话号码_1]索要验证码",
+		ScamType:    "冒充客服类",
+		RiskLevel:   "高",
+		RiskScore:   80,
+		Report:      "report",
+	})
+	state.SaveTaskRedactionMapping("1", "TASK-LIST-PII", map[string]string{"[电话号码_1]": "13800138000"})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("userID", uint(1))
+		c.Next()
+	})
+	router.GET("/tasks", httpapi.GetMultimodalTaskStateHandle)
+	router.GET("/history", httpapi.GetMultimodalHistoryHandle)
+
+	tasksResp := httptest.NewRecorder()
+	router.ServeHTTP(tasksResp, httptest.NewRequest(http.MethodGet, "/tasks", nil))
+	var tasks apimodel.MultimodalTaskStateResponse
+	if err := json.Unmarshal(tasksResp.Body.Bytes(), &tasks); err != nil {
+		t.Fatalf("decode task list failed: %v body=%s", err, tasksResp.Body.String())
+	}
+	if len(tasks.Tasks) != 1 || tasks.Tasks[0].TaskID != pending.TaskID || tasks.Tasks[0].Title != "张三来电要求转账" {
+		t.Fatalf("expected task title restored, got %+v", tasks.Tasks)
+	}
+
+	historyResp := httptest.NewRecorder()
+	router.ServeHTTP(historyResp, httptest.NewRequest(http.MethodGet, "/history", nil))
+	var history apimodel.MultimodalHistoryResponse
+	if err := json.Unmarshal(historyResp.Body.Bytes(), &history); err != nil {
+		t.Fatalf("decode history failed: %v body=%s", err, historyResp.Body.String())
+	}
+	if len(history.History) != 1 || history.History[0].Title != "13800138000冒充客服" || history.History[0].CaseSummary != "对方用13800138000索要验证码" {
+		t.Fatalf("expected history restored, got %+v", history.History)
+	}
+}
@@ -0,0 +1,199 @@
+package httpapi_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	httpapi "antifraud/internal/modules/multi_agent/adapters/inbound/http"
+	apimodel "antifraud/internal/modules/multi_agent/adapters/inbound/http/models"
+	"antifraud/internal/modules/multi_agent/adapters/outbound/state"
+	statemodel "antifraud/internal/modules/multi_agent/adapters/outbound/state/model"
+	"antifraud/internal/platform/database"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type sseFrame struct {
+	ID    string
+	Event string
+	Data  string
+}
+
+func setupTaskProgressDB(t *testing.T) {
+	t.Helper()
+
+	oldDB := database.DB
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "task_progress_test.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open sqlite failed: %v", err)
+	}
+	if err := db.AutoMigrate(&statemodel.PendingTaskEntity{}, &statemodel.HistoryCaseEntity{}, &statemodel.TaskProgressEventEntity{}, &statemodel.TaskRedactionMappingEntity{}); err != nil {
+		t.Fatalf("migrate state tables failed: %v", err)
+	}
+	database.DB = db
+	t.Cleanup(func() {
+		database.DB = oldDB
+		if sqlDB, err := db.DB(); err == nil {
+			_ = sqlDB.Close()
+		}
+	})
+}
+
+func newTaskProgressRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("userID", uint(1))
+		c.Next()
+	})
+	router.GET("/tasks/:taskId/events", httpapi.StreamMultimodalTaskProgressHandle)
+	return router
+}
+
+func parseSSEFrames(t *testing.T, body string) []sseFrame {
+	t.Helper()
+	frames := make([]sseFrame, 0)
+	current := sseFrame{}
+	scanner := bufio.NewScanner(strings.NewReader(body))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if current.Event != "" {
+				frames = append(frames, current)
+			}
+			current = sseFrame{}
+		case strings.HasPrefix(line, "id: "):
+			current.ID = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "event: "):
+			current.Event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			current.Data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+	return frames
+}
+
+func TestStreamMultimodalTaskProgressHandle_ReplaysEventsAndFinishesWithDone(t *testing.T) {
+	setupTaskProgressDB(t)
+
+	task := state.CreateTask("1", state.TaskPayload{Text: "冒充客服要求退款"})
+	state.AppendTaskProgressEvent("1", task.TaskID, state.TaskProgressStageQueued, "任务已入队，等待处理", nil)
+	state.AppendTaskProgressEvent("1", task.TaskID, state.TaskProgressStageToolCall, "调用工具 search_similar_cases", map[string]interface{}{
+		"tool": "search_similar_cases",
+	})
+	state.MarkTaskCompleted("1", task.TaskID, "", "最终报告")
+
+	req := httptest.NewRequest(http.MethodGet, "/tasks/"+task.TaskID+"/events", nil)
+	resp := httptest.NewRecorder()
+	newTaskProgressRouter().ServeHTTP(resp, req)
+
+	if resp.Code != http.StatusOK {
+		t.Fatalf("unexpected status: got=%d body=%s", resp.Code, resp.Body.String())
+	}
+	if contentType := resp.Header().Get("Content-Type"); !strings.HasPrefix(contentType, "text/event-stream") {
+		t.Fatalf("unexpected content type: %q", contentType)
+	}
+
+	frames := parseSSEFrames(t, resp.Body.String())
+	if len(frames) != 3 {
+		t.Fatalf("expected 2 progress frames and 1 done frame, got %+v", frames)
+	}
+	var second apimodel.MultimodalTaskProgressEvent
+	if err := json.Unmarshal([]byte(frames[1].Data), &second); err != nil {
+		t.Fatalf("decode progress event failed: %v", err)
+	}
+	if frames[1].Event != "progress" || second.Stage != state.TaskProgressStageToolCall || second.Data["tool"] != "search_similar_cases" {
+		t.Fatalf("unexpected tool call frame: %+v", frames[1])
+	}
+
+	var done apimodel.MultimodalTaskDetailResponse
+	if err := json.Unmarshal([]byte(frames[2].Data), &done); err != nil {
+		t.Fatalf("decode done event failed: %v", err)
+	}
+	if frames[2].Event != "done" || done.Task.Status != state.TaskStatusCompleted || done.Task.Report != "最终报告" {
+		t.Fatalf("unexpected done frame: %+v", frames[2])
+	}
+}
+
+func TestStreamMultimodalTaskProgressHandle_RestoresRedactedReportForOwner(t *testing.T) {
+	setupTaskProgressDB(t)
+
+	task := state.CreateTask("1", state.TaskPayload{Text: "对方让我向[银行卡号_1]转账"})
+	state.SaveTaskRedactionMapping("1", task.TaskID, map[string]string{"[银行卡号_1]": "6222021234567890128"})
+	state.AppendTaskProgressEvent("1", task.TaskID, state.TaskProgressStageFinalReport, "最终报告已生成", map[string]interface{}{
+		"report": "请勿向[银行卡号_1]转账",
+	})
+	state.MarkTaskCompleted("1", task.TaskID, "", "请勿向[银行卡号_1]转账")
+
+	req := httptest.NewRequest(http.MethodGet, "/tasks/"+task.TaskID+"/events", nil)
+	resp := httptest.NewRecorder()
+	newTaskProgressRouter().ServeHTTP(resp, req)
+
+	frames := parseSSEFrames(t, resp.Body.String())
+	if len(frames) != 2 {
+		t.Fatalf("expected final report frame and done frame, got %+v", frames)
+	}
+	var report apimodel.MultimodalTaskProgressEvent
+	if err := json.Unmarshal([]byte(frames[0].Data), &report); err != nil || report.Data["report"] != "请勿向6222021234567890128转账" {
+		t.Fatalf("expected restored report in progress event, got %s err=%v", frames[0].Data, err)
+	}
+	var done apimodel.MultimodalTaskDetailResponse
+	if err := json.Unmarshal([]byte(frames[1].Data), &done); err != nil {
+		t.Fatalf("decode done event failed: %v", err)
+	}
+	if done.Task.Report != "请勿向6222021234567890128转账" || done.Task.Payload.Text != "对方让我向6222021234567890128转账" {
+		t.Fatalf("expected restored task in done event, got %+v", done.Task)
+	}
+	if stored, _ := state.GetTaskDetailByID("1", task.TaskID); stored.Report != "请勿向[银行卡号_1]转账" {
+		t.Fatalf("expected stored report to keep placeholders, got %q", stored.Report)
+	}
+}
+
+func TestStreamMultimodalTaskProgressHandle_ResumesFromLastEventID(t *testing.T) {
+	setupTaskProgressDB(t)
+
+	task := state.CreateTask("1", state.TaskPayload{Text: "冒充公检法"})
+	state.AppendTaskProgressEvent("1", task.TaskID, state.TaskProgressStageQueued, "任务已入队，等待处理", nil)
+	state.AppendTaskProgressEvent("1", task.TaskID, state.TaskProgressStageStarted, "任务开始分析", nil)
+	state.MarkTaskFailed("1", task.TaskID, "", "upstream error")
+
+	events, err := state.ListTaskProgressEvents("1", task.TaskID, 0, 0)
+	if err != nil || len(events) != 2 {
+		t.Fatalf("expected 2 stored events, got %d err=%v", len(events), err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/tasks/"+task.TaskID+"/events", nil)
+	req.Header.Set("Last-Event-ID", strconv.FormatUint(events[0].ID, 10))
+	resp := httptest.NewRecorder()
+	newTaskProgressRouter().ServeHTTP(resp, req)
+
+	frames := parseSSEFrames(t, resp.Body.String())
+	if len(frames) != 2 || frames[0].ID != strconv.FormatUint(events[1].ID, 10) || frames[1].Event != "done" {
+		t.Fatalf("expected resume from second event then done, got %+v", frames)
+	}
+}
+
+func TestStreamMultimodalTaskProgressHandle_NotFound(t *testing.T) {
+	setupTaskProgressDB(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/tasks/TASK-MISSING/events", nil)
+	resp := httptest.NewRecorder()
+	newTaskProgressRouter().ServeHTTP(resp, req)
+
+	if resp.Code != http.StatusNotFound {
+		t.Fatalf("unexpected status: got=%d body=%s", resp.Code, resp.Body.String())
+	}
+}
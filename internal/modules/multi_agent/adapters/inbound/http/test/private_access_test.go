@@ -6,4 +6,4 @@ import (
 )
 
 //go:linkname rejectPendingReview antifraud/internal/modules/multi_agent/adapters/inbound/http.rejectPendingReview
-var rejectPendingReview func(context.Context, string) error
+var rejectPendingReview func(context.Context, string, string, string) error
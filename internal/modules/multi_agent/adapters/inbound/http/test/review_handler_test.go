@@ -3,12 +3,13 @@ package httpapi_test
 import (
 	"context"
 	"encoding/json"
-	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	httpapi "antifraud/internal/modules/multi_agent/adapters/inbound/http"
+	"antifraud/internal/modules/multi_agent/adapters/outbound/case_library"
 
 	"github.com/gin-gonic/gin"
 )
@@ -22,23 +23,26 @@ func TestRejectPendingReviewCaseHandleSuccess(t *testing.T) {
 	})
 
 	calledRecordID := ""
-	rejectPendingReview = func(_ context.Context, recordID string) error {
+	calledReason := ""
+	rejectPendingReview = func(_ context.Context, recordID string, _ string, reason string) error {
 		calledRecordID = recordID
+		calledReason = reason
 		return nil
 	}
 
 	router := gin.New()
 	router.POST("/cases/:recordId/reject", httpapi.RejectPendingReviewCaseHandle)
 
-	req := httptest.NewRequest(http.MethodPost, "/cases/PR-001/reject", nil)
+	req := httptest.NewRequest(http.MethodPost, "/cases/PR-001/reject", strings.NewReader(`{"reason":"案情描述不完整"}`))
+	req.Header.Set("Content-Type", "application/json")
 	resp := httptest.NewRecorder()
 	router.ServeHTTP(resp, req)
 
 	if resp.Code != http.StatusOK {
 		t.Fatalf("unexpected status: got=%d body=%s", resp.Code, resp.Body.String())
 	}
-	if calledRecordID != "PR-001" {
-		t.Fatalf("unexpected record id: %q", calledRecordID)
+	if calledRecordID != "PR-001" || calledReason != "案情描述不完整" {
+		t.Fatalf("unexpected reject call: record=%q reason=%q", calledRecordID, calledReason)
 	}
 
 	payload := map[string]interface{}{}
@@ -58,8 +62,8 @@ func TestRejectPendingReviewCaseHandleNotFound(t *testing.T) {
 		rejectPendingReview = originalReject
 	})
 
-	rejectPendingReview = func(_ context.Context, recordID string) error {
-		return errors.New("pending review case not found or already processed")
+	rejectPendingReview = func(_ context.Context, recordID string, _ string, _ string) error {
+		return case_library.ErrPendingReviewNotFound
 	}
 
 	router := gin.New()
@@ -73,3 +77,27 @@ func TestRejectPendingReviewCaseHandleNotFound(t *testing.T) {
 		t.Fatalf("unexpected status: got=%d body=%s", resp.Code, resp.Body.String())
 	}
 }
+
+func TestRejectPendingReviewCaseHandleClaimedByOther(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	originalReject := rejectPendingReview
+	t.Cleanup(func() {
+		rejectPendingReview = originalReject
+	})
+
+	rejectPendingReview = func(_ context.Context, _ string, _ string, _ string) error {
+		return case_library.ErrPendingReviewClaimedByOther
+	}
+
+	router := gin.New()
+	router.POST("/cases/:recordId/reject", httpapi.RejectPendingReviewCaseHandle)
+
+	req := httptest.NewRequest(http.MethodPost, "/cases/PR-409/reject", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	if resp.Code != http.StatusConflict {
+		t.Fatalf("unexpected status: got=%d body=%s", resp.Code, resp.Body.String())
+	}
+}
@@ -0,0 +1,75 @@
+package httpapi_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	httpapi "antifraud/internal/modules/multi_agent/adapters/inbound/http"
+	apimodel "antifraud/internal/modules/multi_agent/adapters/inbound/http/models"
+	"antifraud/internal/modules/multi_agent/adapters/outbound/state"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestGetMultimodalRiskExplanationHandle(t *testing.T) {
+	setupTaskProgressDB(t)
+	state.AddCaseHistory(state.CaseHistoryRecord{
+		RecordID:    "TASK-EXPLAIN",
+		UserID:      "1",
+		Title:       "冒充客服退款",
+		CaseSummary: "对方要求远程控制并索要验证码",
+		ScamType:    "冒充客服类",
+		RiskLevel:   "高",
+		RiskScore:   72,
+		RiskSummary: `{"score":72}`,
+		RiskBreakdown: &state.RiskBreakdown{
+			Score:       72,
+			RuleVersion: "2026.03-baseline",
+			Dimensions:  map[string]int{"requested_actions": 42},
+			HitFactors: []state.RiskFactorHit{
+				{Key: "verification_code_request", Label: "要求验证码", Dimension: "requested_actions", Weight: 18},
+				{Key: "remote_control_request", Label: "要求远程控制", Dimension: "requested_actions", Weight: 24},
+			},
+			HitRules: []string{"要求验证码", "要求远程控制", "组合规则：远程控制+验证码"},
+			Dynamic:  &state.DynamicRiskBreakdown{BaseScore: 72, HistoricalScore: 30, DynamicThreshold: 55, AdjustedScore: 72, RiskLevel: "高"},
+		},
+		Payload: state.TaskPayload{Text: "对方要求远程控制"},
+		Report:  "report",
+	})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("userID", uint(1))
+		c.Next()
+	})
+	router.GET("/history/:recordId/explanation", httpapi.GetMultimodalRiskExplanationHandle)
+
+	missing := httptest.NewRecorder()
+	router.ServeHTTP(missing, httptest.NewRequest(http.MethodGet, "/history/TASK-OTHER/explanation", nil))
+	if missing.Code != http.StatusNotFound {
+		t.Fatalf("unexpected status for missing record: got=%d want=%d", missing.Code, http.StatusNotFound)
+	}
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/history/TASK-EXPLAIN/explanation", nil))
+	if resp.Code != http.StatusOK {
+		t.Fatalf("unexpected status: got=%d body=%s", resp.Code, resp.Body.String())
+	}
+
+	var payload apimodel.MultimodalRiskExplanationResponse
+	if err := json.Unmarshal(resp.Body.Bytes(), &payload); err != nil {
+		t.Fatalf("decode response failed: %v", err)
+	}
+	if payload.Source != "breakdown" || payload.RuleVersion != "2026.03-baseline" || payload.RiskLevel != "高" {
+		t.Fatalf("unexpected explanation header: %+v", payload)
+	}
+	if len(payload.Factors) != 2 || payload.Factors[0].Key != "remote_control_request" {
+		t.Fatalf("unexpected factors: %+v", payload.Factors)
+	}
+	if payload.Dynamic == nil || payload.Dynamic.DynamicThreshold != 55 || payload.Headline == "" || len(payload.Reasons) == 0 {
+		t.Fatalf("unexpected explanation body: %+v", payload)
+	}
+}
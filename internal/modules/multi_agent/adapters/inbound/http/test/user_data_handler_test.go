@@ -0,0 +1,69 @@
+package httpapi_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	httpapi "antifraud/internal/modules/multi_agent/adapters/inbound/http"
+	apimodel "antifraud/internal/modules/multi_agent/adapters/inbound/http/models"
+	"antifraud/internal/modules/multi_agent/adapters/outbound/mediastore"
+	"antifraud/internal/modules/multi_agent/adapters/outbound/state"
+	"antifraud/internal/modules/multi_agent/application/retention"
+)
+
+func TestUserDataHandlers_ExportThenPurge(t *testing.T) {
+	router, _ := setupMediaUploadRouter(t, mediastore.Options{})
+	previous := retention.SetDefaultService(retention.NewService(retention.Options{ExportTTL: 10 * time.Minute}))
+	t.Cleanup(func() { retention.SetDefaultService(previous) })
+	router.GET("/data/export", httpapi.ExportUserDataHandle)
+	router.POST("/data/purge", httpapi.PurgeUserDataHandle)
+
+	record := state.AddCaseHistory(state.CaseHistoryRecord{
+		UserID:      "u-media",
+		Title:       "冒充客服",
+		CaseSummary: "摘要",
+		RiskLevel:   "中",
+		RiskScore:   50,
+		Payload:     state.TaskPayload{Text: "录音"},
+		Report:      "报告",
+	})
+
+	if resp := serveMediaRequest(router, http.MethodPost, "/data/purge", "application/json", []byte(`{}`)); resp.Code != http.StatusBadRequest {
+		t.Fatalf("expected missing export_id rejected, got %d", resp.Code)
+	}
+	body, _ := json.Marshal(apimodel.PurgeUserDataRequest{ExportID: "EXPORT-UNKNOWN"})
+	if resp := serveMediaRequest(router, http.MethodPost, "/data/purge", "application/json", body); resp.Code != http.StatusNotFound {
+		t.Fatalf("expected unknown export rejected, got %d", resp.Code)
+	}
+
+	resp := serveMediaRequest(router, http.MethodGet, "/data/export", "", nil)
+	if resp.Code != http.StatusOK || resp.Header().Get("Content-Type") != "application/zip" {
+		t.Fatalf("export failed: code=%d headers=%v", resp.Code, resp.Header())
+	}
+	exportID := resp.Header().Get("X-Export-Id")
+	archive, err := zip.NewReader(bytes.NewReader(resp.Body.Bytes()), int64(resp.Body.Len()))
+	if err != nil || len(archive.File) != 3 {
+		t.Fatalf("expected zip with history, tasks and manifest, err=%v", err)
+	}
+
+	body, _ = json.Marshal(apimodel.PurgeUserDataRequest{ExportID: exportID})
+	resp = serveMediaRequest(router, http.MethodPost, "/data/purge", "application/json", body)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("purge failed: code=%d body=%s", resp.Code, resp.Body.String())
+	}
+	var purged apimodel.PurgeUserDataResponse
+	_ = json.Unmarshal(resp.Body.Bytes(), &purged)
+	if purged.HistoryDeleted != 1 || purged.ExportID != exportID || purged.PurgedAt == "" {
+		t.Fatalf("unexpected purge response: %+v", purged)
+	}
+	if _, exists := state.GetCaseHistoryRecord("u-media", record.RecordID); exists {
+		t.Fatal("expected history purged")
+	}
+	if resp := serveMediaRequest(router, http.MethodPost, "/data/purge", "application/json", body); resp.Code != http.StatusConflict {
+		t.Fatalf("expected reused export rejected, got %d", resp.Code)
+	}
+}
@@ -0,0 +1,82 @@
+package httpapi
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	apimodel "antifraud/internal/modules/multi_agent/adapters/inbound/http/models"
+	"antifraud/internal/modules/multi_agent/adapters/outbound/state"
+	"antifraud/internal/modules/multi_agent/application/retention"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ExportUserDataHandle 以 zip 流式导出当前用户的全部任务、历史案件与原始媒体。
+// 导出凭证通过 X-Export-Id 响应头返回，仅在压缩包完整写出后生效，可用于随后的数据清除。
+func ExportUserDataHandle(c *gin.Context) {
+	userID := getCurrentUserID(c)
+	service := retention.DefaultService()
+	export := service.NewExport(userID, time.Now())
+
+	fileName := fmt.Sprintf("user_data_%s.zip", export.CreatedAt.Format("20060102150405"))
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName))
+	c.Header("X-Export-Id", export.ExportID)
+	c.Header("Cache-Control", "no-store")
+	c.Status(http.StatusOK)
+
+	// 响应头已发出，中途失败只能截断输出并记录日志，此时导出凭证不会生效。
+	export, err := service.WriteExport(c.Request.Context(), export, c.Writer)
+	if err != nil {
+		log.Printf("[user_data] export interrupted: user=%s export=%s err=%v", userID, export.ExportID, err)
+		return
+	}
+	log.Printf("[user_data] export completed: user=%s export=%s history=%d tasks=%d media=%d",
+		userID, export.ExportID, export.HistoryCount, export.TaskCount, export.MediaCount)
+}
+
+// PurgeUserDataHandle 凭导出凭证清除当前用户的全部任务、历史案件、进度事件与原始媒体引用。
+func PurgeUserDataHandle(c *gin.Context) {
+	userID := getCurrentUserID(c)
+	var payload apimodel.PurgeUserDataRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误: " + err.Error()})
+		return
+	}
+
+	export, err := retention.DefaultService().PurgeUserData(userID, strings.TrimSpace(payload.ExportID), time.Now())
+	if err != nil {
+		switch {
+		case errors.Is(err, retention.ErrExportNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "导出凭证不存在，请先完整导出数据"})
+		case errors.Is(err, retention.ErrExportExpired):
+			c.JSON(http.StatusConflict, gin.H{"error": "导出凭证已过期，请重新导出"})
+		case errors.Is(err, retention.ErrExportAlreadyUsed):
+			c.JSON(http.StatusConflict, gin.H{"error": "导出凭证已使用"})
+		case errors.Is(err, retention.ErrDataChangedAfterExport):
+			c.JSON(http.StatusConflict, gin.H{"error": "导出后数据有变化，请重新导出后再清除"})
+		case errors.Is(err, state.ErrUserTasksProcessing):
+			c.JSON(http.StatusConflict, gin.H{"error": "仍有分析中的任务，请等待完成后再清除"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "清除用户数据失败: " + err.Error()})
+		}
+		return
+	}
+
+	purgedAt := ""
+	if export.PurgedAt != nil {
+		purgedAt = export.PurgedAt.Format(time.RFC3339)
+	}
+	c.JSON(http.StatusOK, apimodel.PurgeUserDataResponse{
+		UserID:         userID,
+		ExportID:       export.ExportID,
+		HistoryDeleted: export.HistoryPurged,
+		TasksDeleted:   export.TasksPurged,
+		PurgedAt:       purgedAt,
+		Message:        "用户数据清除成功",
+	})
+}
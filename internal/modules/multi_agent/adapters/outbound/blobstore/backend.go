@@ -0,0 +1,186 @@
+package blobstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	appcfg "antifraud/internal/platform/config"
+)
+
+// Backend 是 blob 内容的底层存储，key 为内容的 SHA-256 十六进制摘要。
+// 本地文件系统为内置实现，对象存储可通过 RegisterBackend 接入；元数据与引用计数统一由 Store 维护。
+type Backend interface {
+	// Put 写入 key 对应的内容；key 已存在时可直接返回，内容寻址保证同 key 内容一致。
+	Put(ctx context.Context, key string, content io.Reader, size int64) error
+	// Open 返回 key 对应内容的读取流，不存在时返回 ErrBlobNotFound。
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete 删除 key 对应内容，不存在时视为成功。
+	Delete(ctx context.Context, key string) error
+}
+
+// BackendFactory 按 blob_store 配置创建一个存储后端。
+type BackendFactory func(cfg appcfg.BlobStoreConfig) (Backend, error)
+
+var (
+	backendsMu sync.RWMutex
+	backends   = map[string]BackendFactory{}
+)
+
+func init() {
+	mustRegisterBackend(appcfg.BlobStoreBackendLocal, func(cfg appcfg.BlobStoreConfig) (Backend, error) {
+		return NewLocalBackend(cfg.Dir)
+	})
+}
+
+// RegisterBackend 注册一个 blob 存储后端；名称为空或重复注册时返回错误。
+func RegisterBackend(name string, factory BackendFactory) error {
+	trimmedName := strings.ToLower(strings.TrimSpace(name))
+	if trimmedName == "" {
+		return fmt.Errorf("blob store backend name is empty")
+	}
+	if factory == nil {
+		return fmt.Errorf("blob store backend %q factory is nil", trimmedName)
+	}
+
+	backendsMu.Lock()
+	defer backendsMu.Unlock()
+	if _, exists := backends[trimmedName]; exists {
+		return fmt.Errorf("blob store backend %q already registered", trimmedName)
+	}
+	backends[trimmedName] = factory
+	return nil
+}
+
+func mustRegisterBackend(name string, factory BackendFactory) {
+	if err := RegisterBackend(name, factory); err != nil {
+		panic(err)
+	}
+}
+
+// BackendNames 返回已注册的后端名称（按字母序）。
+func BackendNames() []string {
+	backendsMu.RLock()
+	defer backendsMu.RUnlock()
+	names := make([]string, 0, len(backends))
+	for name := range backends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewBackend 按 blob_store.backend 创建存储后端，未配置时使用本地文件系统。
+func NewBackend(cfg appcfg.BlobStoreConfig) (Backend, error) {
+	name := strings.ToLower(strings.TrimSpace(cfg.Backend))
+	if name == "" {
+		name = appcfg.BlobStoreBackendLocal
+	}
+
+	backendsMu.RLock()
+	factory, ok := backends[name]
+	backendsMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown blob store backend %q, available: %s", name, strings.Join(BackendNames(), ", "))
+	}
+
+	backend, err := factory(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("create blob store backend %q failed: %w", name, err)
+	}
+	return backend, nil
+}
+
+// LocalBackend 把 blob 保存在本地目录，按摘要前两位分桶，避免单目录文件过多。
+type LocalBackend struct {
+	dir string
+}
+
+// NewLocalBackend 创建以 dir 为根目录的本地后端，目录不存在时自动创建。
+func NewLocalBackend(dir string) (*LocalBackend, error) {
+	trimmed := strings.TrimSpace(dir)
+	if trimmed == "" {
+		return nil, fmt.Errorf("blob store dir is empty")
+	}
+	if err := os.MkdirAll(trimmed, 0o755); err != nil {
+		return nil, err
+	}
+	return &LocalBackend{dir: trimmed}, nil
+}
+
+// Dir 返回本地后端的根目录。
+func (b *LocalBackend) Dir() string {
+	return b.dir
+}
+
+// Put 先写入同目录临时文件再原子改名，并发写入同一 key 时以先完成者为准。
+func (b *LocalBackend) Put(_ context.Context, key string, content io.Reader, size int64) error {
+	target, err := b.path(key)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(target); err == nil {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(target), key+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tempPath := file.Name()
+	written, copyErr := io.Copy(file, content)
+	closeErr := file.Close()
+	if copyErr == nil && closeErr == nil && size >= 0 && written != size {
+		copyErr = fmt.Errorf("blob %s size mismatch: want %d, got %d", key, size, written)
+	}
+	if copyErr != nil || closeErr != nil {
+		_ = os.Remove(tempPath)
+		return errors.Join(copyErr, closeErr)
+	}
+	if err := os.Rename(tempPath, target); err != nil {
+		_ = os.Remove(tempPath)
+		return err
+	}
+	return nil
+}
+
+// Open 打开 key 对应的文件。
+func (b *LocalBackend) Open(_ context.Context, key string) (io.ReadCloser, error) {
+	target, err := b.path(key)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(target)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrBlobNotFound, key)
+	}
+	return file, err
+}
+
+// Delete 删除 key 对应的文件。
+func (b *LocalBackend) Delete(_ context.Context, key string) error {
+	target, err := b.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(target); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (b *LocalBackend) path(key string) (string, error) {
+	if !IsKey(key) {
+		return "", fmt.Errorf("%w: %q", ErrInvalidKey, key)
+	}
+	return filepath.Join(b.dir, key[:2], key), nil
+}
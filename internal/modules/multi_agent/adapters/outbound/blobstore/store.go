@@ -0,0 +1,471 @@
+package blobstore
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	appcfg "antifraud/internal/platform/config"
+	"antifraud/internal/platform/database"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RefPrefix 是任务与历史记录中引用 blob 的前缀，形如 blob:<sha256>。
+const RefPrefix = "blob:"
+
+const (
+	// defaultDir 是未配置 blob_store 时本地后端使用的目录。
+	defaultDir = "DB/blobs"
+	// defaultOrphanGrace 是引用计数归零的 blob 被清理前的默认保留时长。
+	defaultOrphanGrace = time.Hour
+	// janitorInterval 是孤儿 blob 的清理周期。
+	janitorInterval = time.Hour
+)
+
+var (
+	ErrBlobNotFound = errors.New("blob not found")
+	ErrInvalidKey   = errors.New("invalid blob key")
+)
+
+// storeDB 返回 blob 元数据与引用关系所在的主业务库。
+var storeDB = func() *gorm.DB { return database.DB }
+
+// blobEntity 记录一份内容的元数据，RefCount 为 blob_references 中引用它的所有者数量。
+type blobEntity struct {
+	SHA256    string    `gorm:"primaryKey;size:64"`
+	MIME      string    `gorm:"size:128;not null"`
+	Size      int64     `gorm:"not null"`
+	RefCount  int       `gorm:"default:0;not null;index"`
+	CreatedAt time.Time `gorm:"not null"`
+	UpdatedAt time.Time `gorm:"index;not null"`
+}
+
+func (blobEntity) TableName() string {
+	return "content_blobs"
+}
+
+// blobReferenceEntity 记录某个所有者（如待处理任务、历史案件）对 blob 的一次引用。
+type blobReferenceEntity struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement"`
+	OwnerKind string    `gorm:"size:32;not null;uniqueIndex:idx_blob_reference_owner"`
+	OwnerID   string    `gorm:"size:64;not null;uniqueIndex:idx_blob_reference_owner"`
+	SHA256    string    `gorm:"size:64;not null;uniqueIndex:idx_blob_reference_owner;index"`
+	CreatedAt time.Time `gorm:"not null"`
+}
+
+func (blobReferenceEntity) TableName() string {
+	return "blob_references"
+}
+
+func init() {
+	database.RegisterMainDBSchemaInitializer("blob_store", initStoreSchema)
+}
+
+func initStoreSchema(db *gorm.DB) error {
+	if db == nil {
+		return fmt.Errorf("blob store schema db is nil")
+	}
+	return db.AutoMigrate(&blobEntity{}, &blobReferenceEntity{})
+}
+
+// schemaReady 记录已建表的数据库（按 gorm 配置区分），避免每次读写都执行 AutoMigrate。
+var schemaReady sync.Map
+
+// EnsureSchema 确保 db 上存在 blob 元数据与引用表；依赖 blob 引用的模块应在开启事务前调用。
+func EnsureSchema(db *gorm.DB) error {
+	if db == nil {
+		return fmt.Errorf("blob store schema db is nil")
+	}
+	if _, ok := schemaReady.Load(db.Config); ok {
+		return nil
+	}
+	if err := initStoreSchema(db); err != nil {
+		return err
+	}
+	schemaReady.Store(db.Config, struct{}{})
+	return nil
+}
+
+// Blob 是一份内容寻址 blob 的元数据。
+type Blob struct {
+	Key       string
+	MIME      string
+	Size      int64
+	RefCount  int
+	CreatedAt time.Time
+}
+
+// Options 是 blob 存储参数：OrphanGrace 为引用计数归零后的保留时长，期间可被重新引用。
+type Options struct {
+	OrphanGrace time.Duration
+}
+
+// OptionsFromConfig 把 blob_store 配置转换为存储参数。
+func OptionsFromConfig(cfg appcfg.BlobStoreConfig) Options {
+	return Options{OrphanGrace: time.Duration(cfg.OrphanGraceMinutes) * time.Minute}
+}
+
+// Store 以 SHA-256 为键保存媒体内容：相同内容只存一份，按所有者维护引用计数，
+// 引用计数归零且超过保留时长的 blob 由清理任务删除。
+type Store struct {
+	backend Backend
+	options Options
+	// mu 串行化写入与清理，避免清理删除正在被重新写入的同一 blob。
+	mu sync.Mutex
+}
+
+var (
+	defaultStoreMu sync.Mutex
+	defaultStore   *Store
+)
+
+// NewStore 使用给定后端创建存储；backend 为空时使用默认目录的本地后端，零值参数取默认值。
+func NewStore(backend Backend, options Options) *Store {
+	if backend == nil {
+		backend = &LocalBackend{dir: defaultDir}
+	}
+	if options.OrphanGrace <= 0 {
+		options.OrphanGrace = defaultOrphanGrace
+	}
+	return &Store{backend: backend, options: options}
+}
+
+// DefaultStore 返回按 blob_store 配置创建的进程级 blob 存储。
+func DefaultStore() *Store {
+	defaultStoreMu.Lock()
+	defer defaultStoreMu.Unlock()
+	if defaultStore == nil {
+		var backend Backend
+		options := Options{}
+		if cfg, err := appcfg.LoadConfig("internal/platform/config/config.json"); err == nil && cfg != nil {
+			options = OptionsFromConfig(cfg.BlobStore)
+			if backend, err = NewBackend(cfg.BlobStore); err != nil {
+				log.Printf("[blob_store] %v, fallback to local dir %s", err, defaultDir)
+			}
+		}
+		defaultStore = NewStore(backend, options)
+	}
+	return defaultStore
+}
+
+// SetDefaultStore 替换进程级 blob 存储并返回原存储，供测试与自定义后端使用。
+func SetDefaultStore(store *Store) *Store {
+	defaultStoreMu.Lock()
+	defer defaultStoreMu.Unlock()
+	previous := defaultStore
+	defaultStore = store
+	return previous
+}
+
+// Backend 返回存储当前使用的后端。
+func (s *Store) Backend() Backend {
+	return s.backend
+}
+
+// Ref 返回引用 blob 的字符串。
+func Ref(key string) string {
+	return RefPrefix + key
+}
+
+// ParseRef 解析 blob:<sha256> 引用，返回合法的 key。
+func ParseRef(value string) (string, bool) {
+	key, ok := strings.CutPrefix(strings.TrimSpace(value), RefPrefix)
+	if !ok || !IsKey(key) {
+		return "", false
+	}
+	return key, true
+}
+
+// IsKey 判断是否为小写十六进制的 SHA-256 摘要。
+func IsKey(key string) bool {
+	if len(key) != sha256.Size*2 {
+		return false
+	}
+	for _, ch := range key {
+		if (ch < '0' || ch > '9') && (ch < 'a' || ch > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// Put 写入一份内容并返回其元数据，相同内容重复写入时只刷新元数据的更新时间。
+// 新写入的 blob 引用计数为 0，调用方应在保留时长内通过 SetReferences 登记引用。
+func (s *Store) Put(ctx context.Context, mimeType string, content []byte) (Blob, error) {
+	if len(content) == 0 {
+		return Blob{}, fmt.Errorf("blob content is empty")
+	}
+	digest := sha256.Sum256(content)
+	return s.write(ctx, hex.EncodeToString(digest[:]), mimeType, bytes.NewReader(content), int64(len(content)))
+}
+
+// PutReader 以流式方式写入一份内容：先落入临时文件并计算摘要，再按摘要写入后端，不在内存中保留全文。
+// content 返回的读取错误会原样透传（可用 errors.Is 判断），此时不写入任何内容；引用约定与 Put 相同。
+func (s *Store) PutReader(ctx context.Context, mimeType string, content io.Reader) (Blob, error) {
+	temp, err := os.CreateTemp("", "blob-*.tmp")
+	if err != nil {
+		return Blob{}, fmt.Errorf("create blob temp file failed: %w", err)
+	}
+	defer func() {
+		_ = temp.Close()
+		_ = os.Remove(temp.Name())
+	}()
+
+	hasher := sha256.New()
+	size, err := io.Copy(io.MultiWriter(temp, hasher), content)
+	if err != nil {
+		return Blob{}, fmt.Errorf("spool blob content failed: %w", err)
+	}
+	if size == 0 {
+		return Blob{}, fmt.Errorf("blob content is empty")
+	}
+	if _, err := temp.Seek(0, io.SeekStart); err != nil {
+		return Blob{}, fmt.Errorf("rewind blob temp file failed: %w", err)
+	}
+	return s.write(ctx, hex.EncodeToString(hasher.Sum(nil)), mimeType, temp, size)
+}
+
+func (s *Store) write(ctx context.Context, key string, mimeType string, content io.Reader, size int64) (Blob, error) {
+	db := storeDB()
+	if err := EnsureSchema(db); err != nil {
+		return Blob{}, err
+	}
+	mimeType = strings.TrimSpace(mimeType)
+	if mimeType == "" {
+		mimeType = "application/octet-stream"
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	entity := blobEntity{SHA256: key, MIME: mimeType, Size: size, CreatedAt: now, UpdatedAt: now}
+	if err := db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "sha256"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{"updated_at": now}),
+	}).Create(&entity).Error; err != nil {
+		return Blob{}, err
+	}
+	if err := s.backend.Put(ctx, key, content, size); err != nil {
+		return Blob{}, fmt.Errorf("write blob %s failed: %w", key, err)
+	}
+	return s.Stat(key)
+}
+
+// Stat 返回 blob 元数据。
+func (s *Store) Stat(key string) (Blob, error) {
+	if !IsKey(key) {
+		return Blob{}, fmt.Errorf("%w: %q", ErrInvalidKey, key)
+	}
+	db := storeDB()
+	if err := EnsureSchema(db); err != nil {
+		return Blob{}, err
+	}
+	var entity blobEntity
+	query := db.Where("sha256 = ?", key).Limit(1).Find(&entity)
+	if query.Error != nil {
+		return Blob{}, query.Error
+	}
+	if query.RowsAffected == 0 {
+		return Blob{}, fmt.Errorf("%w: %s", ErrBlobNotFound, key)
+	}
+	return blobFromEntity(entity), nil
+}
+
+// Open 返回 blob 元数据与内容读取流，调用方负责关闭。
+func (s *Store) Open(ctx context.Context, key string) (Blob, io.ReadCloser, error) {
+	blob, err := s.Stat(key)
+	if err != nil {
+		return Blob{}, nil, err
+	}
+	reader, err := s.backend.Open(ctx, key)
+	if err != nil {
+		return Blob{}, nil, err
+	}
+	return blob, reader, nil
+}
+
+// ReadAll 读取 blob 全部内容。
+func (s *Store) ReadAll(ctx context.Context, key string) (Blob, []byte, error) {
+	blob, reader, err := s.Open(ctx, key)
+	if err != nil {
+		return Blob{}, nil, err
+	}
+	defer reader.Close()
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return Blob{}, nil, err
+	}
+	return blob, content, nil
+}
+
+// SetReferences 把所有者引用的 blob 集合替换为 keys，并按差集增减引用计数；keys 为空即释放全部引用。
+// tx 非空时在调用方事务内执行，使引用变更与业务数据原子提交；引用不存在的 blob 返回 ErrBlobNotFound。
+func (s *Store) SetReferences(tx *gorm.DB, ownerKind string, ownerID string, keys []string) error {
+	if tx == nil {
+		db := storeDB()
+		if err := EnsureSchema(db); err != nil {
+			return err
+		}
+		return db.Transaction(func(tx *gorm.DB) error {
+			return s.SetReferences(tx, ownerKind, ownerID, keys)
+		})
+	}
+	ownerKind = strings.TrimSpace(ownerKind)
+	ownerID = strings.TrimSpace(ownerID)
+	if ownerKind == "" || ownerID == "" {
+		return fmt.Errorf("blob reference owner is empty")
+	}
+
+	wanted := make(map[string]struct{}, len(keys))
+	for _, key := range keys {
+		if !IsKey(key) {
+			return fmt.Errorf("%w: %q", ErrInvalidKey, key)
+		}
+		wanted[key] = struct{}{}
+	}
+	var existing []blobReferenceEntity
+	if err := tx.Where("owner_kind = ? AND owner_id = ?", ownerKind, ownerID).Find(&existing).Error; err != nil {
+		return err
+	}
+	removed := make([]string, 0)
+	for _, ref := range existing {
+		if _, ok := wanted[ref.SHA256]; ok {
+			delete(wanted, ref.SHA256)
+			continue
+		}
+		removed = append(removed, ref.SHA256)
+	}
+	added := make([]string, 0, len(wanted))
+	for key := range wanted {
+		added = append(added, key)
+	}
+	sort.Strings(added)
+
+	now := time.Now()
+	if len(removed) > 0 {
+		if err := tx.Where("owner_kind = ? AND owner_id = ? AND sha256 IN ?", ownerKind, ownerID, removed).
+			Delete(&blobReferenceEntity{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&blobEntity{}).Where("sha256 IN ?", removed).Updates(map[string]interface{}{
+			"ref_count":  gorm.Expr("ref_count - 1"),
+			"updated_at": now,
+		}).Error; err != nil {
+			return err
+		}
+	}
+	if len(added) > 0 {
+		var known int64
+		if err := tx.Model(&blobEntity{}).Where("sha256 IN ?", added).Count(&known).Error; err != nil {
+			return err
+		}
+		if known != int64(len(added)) {
+			return fmt.Errorf("%w: owner %s/%s references unknown blob", ErrBlobNotFound, ownerKind, ownerID)
+		}
+		refs := make([]blobReferenceEntity, 0, len(added))
+		for _, key := range added {
+			refs = append(refs, blobReferenceEntity{OwnerKind: ownerKind, OwnerID: ownerID, SHA256: key, CreatedAt: now})
+		}
+		if err := tx.Create(&refs).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&blobEntity{}).Where("sha256 IN ?", added).Updates(map[string]interface{}{
+			"ref_count":  gorm.Expr("ref_count + 1"),
+			"updated_at": now,
+		}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SweepOrphans 删除引用计数归零且超过保留时长的 blob，返回删除数量。
+func (s *Store) SweepOrphans(ctx context.Context, now time.Time) (int, error) {
+	db := storeDB()
+	if err := EnsureSchema(db); err != nil {
+		return 0, err
+	}
+	cutoff := now.Add(-s.options.OrphanGrace)
+	var keys []string
+	if err := db.Model(&blobEntity{}).
+		Where("ref_count <= 0 AND updated_at < ?", cutoff).
+		Pluck("sha256", &keys).Error; err != nil {
+		return 0, err
+	}
+
+	swept := 0
+	for _, key := range keys {
+		deleted, err := s.sweepOne(ctx, db, key, cutoff)
+		if err != nil {
+			return swept, err
+		}
+		if deleted {
+			swept++
+		}
+	}
+	return swept, nil
+}
+
+func (s *Store) sweepOne(ctx context.Context, db *gorm.DB, key string, cutoff time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// 条件删除保证清理期间被重新引用或重新写入的 blob 不会被误删。
+	result := db.Where("sha256 = ? AND ref_count <= 0 AND updated_at < ?", key, cutoff).Delete(&blobEntity{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+	if err := s.backend.Delete(ctx, key); err != nil {
+		log.Printf("[blob_store] delete orphan blob content failed: key=%s err=%v", key, err)
+	}
+	return true, nil
+}
+
+// StartJanitor 启动后台清理：立即执行一次，随后每小时清理一次孤儿 blob，ctx 取消后退出。
+func (s *Store) StartJanitor(ctx context.Context) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	go func() {
+		ticker := time.NewTicker(janitorInterval)
+		defer ticker.Stop()
+		for {
+			swept, err := s.SweepOrphans(ctx, time.Now())
+			if err != nil {
+				log.Printf("[blob_store] sweep orphan blobs failed: %v", err)
+			} else if swept > 0 {
+				log.Printf("[blob_store] swept orphan blobs=%d", swept)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func blobFromEntity(entity blobEntity) Blob {
+	return Blob{
+		Key:       entity.SHA256,
+		MIME:      entity.MIME,
+		Size:      entity.Size,
+		RefCount:  entity.RefCount,
+		CreatedAt: entity.CreatedAt,
+	}
+}
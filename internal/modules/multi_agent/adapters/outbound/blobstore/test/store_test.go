@@ -0,0 +1,215 @@
+package blobstore_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"antifraud/internal/modules/multi_agent/adapters/outbound/blobstore"
+	appcfg "antifraud/internal/platform/config"
+	"antifraud/internal/platform/database"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func setupBlobStore(t *testing.T) (*blobstore.Store, *blobstore.LocalBackend) {
+	t.Helper()
+
+	oldDB := database.DB
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "blob_store_test.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open sqlite failed: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("get sql db failed: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	database.DB = db
+	t.Cleanup(func() {
+		database.DB = oldDB
+		_ = sqlDB.Close()
+	})
+
+	backend, err := blobstore.NewLocalBackend(t.TempDir())
+	if err != nil {
+		t.Fatalf("create local backend failed: %v", err)
+	}
+	return blobstore.NewStore(backend, blobstore.Options{OrphanGrace: time.Minute}), backend
+}
+
+func TestBlobStore_DeduplicatesContentAndCountsReferences(t *testing.T) {
+	store, _ := setupBlobStore(t)
+	ctx := context.Background()
+
+	first, err := store.Put(ctx, "image/png", []byte("same-bytes"))
+	if err != nil {
+		t.Fatalf("put blob failed: %v", err)
+	}
+	second, err := store.Put(ctx, "image/jpeg", []byte("same-bytes"))
+	if err != nil || second.Key != first.Key || second.MIME != "image/png" {
+		t.Fatalf("expected identical content deduplicated, got %+v err=%v", second, err)
+	}
+	other, err := store.Put(ctx, "audio/mpeg", []byte("other-bytes"))
+	if err != nil {
+		t.Fatalf("put other blob failed: %v", err)
+	}
+	if ref, ok := blobstore.ParseRef(blobstore.Ref(first.Key)); !ok || ref != first.Key {
+		t.Fatalf("expected ref round trip, got %q ok=%v", ref, ok)
+	}
+	if _, ok := blobstore.ParseRef("blob:../../etc/passwd"); ok {
+		t.Fatal("expected malformed ref rejected")
+	}
+
+	if err := store.SetReferences(nil, "task", "T-1", []string{first.Key, other.Key}); err != nil {
+		t.Fatalf("set references failed: %v", err)
+	}
+	if err := store.SetReferences(nil, "history", "H-1", []string{first.Key}); err != nil {
+		t.Fatalf("set references failed: %v", err)
+	}
+	if err := store.SetReferences(nil, "task", "T-1", []string{first.Key}); err != nil {
+		t.Fatalf("replace references failed: %v", err)
+	}
+	if blob, _ := store.Stat(first.Key); blob.RefCount != 2 {
+		t.Fatalf("expected shared blob ref_count=2, got %d", blob.RefCount)
+	}
+	if blob, _ := store.Stat(other.Key); blob.RefCount != 0 {
+		t.Fatalf("expected dropped blob ref_count=0, got %d", blob.RefCount)
+	}
+	missing := strings.Repeat("a", 64)
+	if err := store.SetReferences(nil, "task", "T-2", []string{missing}); !errors.Is(err, blobstore.ErrBlobNotFound) {
+		t.Fatalf("expected unknown blob reference rejected, got %v", err)
+	}
+
+	_, reader, err := store.Open(ctx, first.Key)
+	if err != nil {
+		t.Fatalf("open blob failed: %v", err)
+	}
+	content, _ := io.ReadAll(reader)
+	_ = reader.Close()
+	if !bytes.Equal(content, []byte("same-bytes")) {
+		t.Fatalf("unexpected blob content %q", content)
+	}
+}
+
+func TestBlobStore_PutReaderStreamsContentAndAbortsOnReadError(t *testing.T) {
+	store, _ := setupBlobStore(t)
+	ctx := context.Background()
+
+	streamed, err := store.PutReader(ctx, "video/mp4", strings.NewReader("streamed-bytes"))
+	if err != nil {
+		t.Fatalf("put reader failed: %v", err)
+	}
+	buffered, err := store.Put(ctx, "video/mp4", []byte("streamed-bytes"))
+	if err != nil || buffered.Key != streamed.Key || streamed.Size != int64(len("streamed-bytes")) {
+		t.Fatalf("expected streamed and buffered writes share a key, got %+v vs %+v err=%v", streamed, buffered, err)
+	}
+	if _, raw, err := store.ReadAll(ctx, streamed.Key); err != nil || string(raw) != "streamed-bytes" {
+		t.Fatalf("unexpected streamed content %q err=%v", raw, err)
+	}
+
+	errBroken := errors.New("broken reader")
+	if _, err := store.PutReader(ctx, "video/mp4", io.MultiReader(strings.NewReader("partial"), iotest.ErrReader(errBroken))); !errors.Is(err, errBroken) {
+		t.Fatalf("expected reader error passed through, got %v", err)
+	}
+	if swept, err := store.SweepOrphans(ctx, time.Now().Add(time.Hour)); err != nil || swept != 1 {
+		t.Fatalf("expected only the streamed blob recorded, swept=%d err=%v", swept, err)
+	}
+}
+
+func TestBlobStore_SweepOrphansRespectsGraceAndReferences(t *testing.T) {
+	store, _ := setupBlobStore(t)
+	ctx := context.Background()
+
+	kept, _ := store.Put(ctx, "image/png", []byte("kept"))
+	orphan, _ := store.Put(ctx, "image/png", []byte("orphan"))
+	if err := store.SetReferences(nil, "history", "H-1", []string{kept.Key, orphan.Key}); err != nil {
+		t.Fatalf("set references failed: %v", err)
+	}
+	if err := store.SetReferences(nil, "history", "H-1", []string{kept.Key}); err != nil {
+		t.Fatalf("release reference failed: %v", err)
+	}
+
+	if swept, err := store.SweepOrphans(ctx, time.Now()); err != nil || swept != 0 {
+		t.Fatalf("expected orphan kept within grace period, swept=%d err=%v", swept, err)
+	}
+	swept, err := store.SweepOrphans(ctx, time.Now().Add(2*time.Minute))
+	if err != nil || swept != 1 {
+		t.Fatalf("expected one orphan swept, swept=%d err=%v", swept, err)
+	}
+	if _, _, err := store.ReadAll(ctx, orphan.Key); !errors.Is(err, blobstore.ErrBlobNotFound) {
+		t.Fatalf("expected swept blob missing, got %v", err)
+	}
+	if _, raw, err := store.ReadAll(ctx, kept.Key); err != nil || string(raw) != "kept" {
+		t.Fatalf("expected referenced blob kept, got %q err=%v", raw, err)
+	}
+
+	// 清理后重新写入相同内容应可恢复。
+	if _, err := store.Put(ctx, "image/png", []byte("orphan")); err != nil {
+		t.Fatalf("re-put swept blob failed: %v", err)
+	}
+	if _, raw, err := store.ReadAll(ctx, orphan.Key); err != nil || string(raw) != "orphan" {
+		t.Fatalf("expected re-put blob readable, got %q err=%v", raw, err)
+	}
+}
+
+type memoryBackend struct {
+	blobs map[string][]byte
+}
+
+func (b *memoryBackend) Put(_ context.Context, key string, content io.Reader, _ int64) error {
+	raw, err := io.ReadAll(content)
+	b.blobs[key] = raw
+	return err
+}
+
+func (b *memoryBackend) Open(_ context.Context, key string) (io.ReadCloser, error) {
+	raw, ok := b.blobs[key]
+	if !ok {
+		return nil, blobstore.ErrBlobNotFound
+	}
+	return io.NopCloser(bytes.NewReader(raw)), nil
+}
+
+func (b *memoryBackend) Delete(_ context.Context, key string) error {
+	delete(b.blobs, key)
+	return nil
+}
+
+func TestBlobStore_BackendRegistry(t *testing.T) {
+	backend := &memoryBackend{blobs: map[string][]byte{}}
+	if err := blobstore.RegisterBackend(" Memory-Test ", func(appcfg.BlobStoreConfig) (blobstore.Backend, error) {
+		return backend, nil
+	}); err != nil {
+		t.Fatalf("register backend failed: %v", err)
+	}
+	if err := blobstore.RegisterBackend("memory-test", func(appcfg.BlobStoreConfig) (blobstore.Backend, error) {
+		return backend, nil
+	}); err == nil {
+		t.Fatal("expected duplicate backend registration rejected")
+	}
+	if _, err := blobstore.NewBackend(appcfg.BlobStoreConfig{Backend: "s3-missing"}); err == nil || !strings.Contains(err.Error(), "local") {
+		t.Fatalf("expected unknown backend error listing available backends, got %v", err)
+	}
+
+	created, err := blobstore.NewBackend(appcfg.BlobStoreConfig{Backend: "memory-test"})
+	if err != nil || created != backend {
+		t.Fatalf("expected registered backend, got %v err=%v", created, err)
+	}
+	setupBlobStore(t)
+	store := blobstore.NewStore(created, blobstore.Options{})
+	blob, err := store.Put(context.Background(), "video/mp4", []byte("clip"))
+	if err != nil || !bytes.Equal(backend.blobs[blob.Key], []byte("clip")) {
+		t.Fatalf("expected content written to custom backend, got %+v err=%v", blob, err)
+	}
+}
@@ -120,6 +120,7 @@ func historicalCaseRecordsEqual(left HistoricalCaseRecord, right HistoricalCaseR
 
 	if !equalStringSlices(leftNormalized.TypicalScripts, rightNormalized.TypicalScripts) ||
 		!equalStringSlices(leftNormalized.Keywords, rightNormalized.Keywords) ||
+		!equalCaseCitations(leftNormalized.Citations, rightNormalized.Citations) ||
 		!equalFloatSlices(leftNormalized.EmbeddingVector, rightNormalized.EmbeddingVector) {
 		return false
 	}
@@ -140,6 +141,21 @@ func equalStringSlices(left []string, right []string) bool {
 	return true
 }
 
+func equalCaseCitations(left []CaseCitation, right []CaseCitation) bool {
+	if len(left) != len(right) {
+		return false
+	}
+	for index := range left {
+		if left[index].URL != right[index].URL ||
+			left[index].Title != right[index].Title ||
+			left[index].PublishedDate != right[index].PublishedDate ||
+			!equalTimeValue(left[index].RetrievedAt, right[index].RetrievedAt) {
+			return false
+		}
+	}
+	return true
+}
+
 func equalFloatSlices(left []float64, right []float64) bool {
 	if len(left) != len(right) {
 		return false
@@ -0,0 +1,184 @@
+package case_library
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	model "antifraud/internal/modules/multi_agent/adapters/outbound/case_library/model"
+	"antifraud/internal/platform/database"
+)
+
+type CaseCitation = model.CaseCitation
+
+// MaxCaseCitations 是单个案件保留的来源引用上限。
+const MaxCaseCitations = 10
+
+// caseCitationJSON 是来源引用在数据库中的 JSON 存储格式。
+type caseCitationJSON struct {
+	URL           string    `json:"url"`
+	Title         string    `json:"title,omitempty"`
+	PublishedDate string    `json:"published_date,omitempty"`
+	RetrievedAt   time.Time `json:"retrieved_at,omitempty"`
+}
+
+// normalizeCaseCitations 去除首尾空格、按 URL 去重，只接受 http/https 链接，超出上限的引用会被截断。
+func normalizeCaseCitations(citations []CaseCitation) ([]CaseCitation, error) {
+	normalized := make([]CaseCitation, 0, len(citations))
+	seen := map[string]struct{}{}
+	for index, citation := range citations {
+		rawURL := strings.TrimSpace(citation.URL)
+		if !IsCitationURL(rawURL) {
+			return nil, newValidationError("citations[%d].url is invalid, only http/https links are allowed", index)
+		}
+		if _, ok := seen[rawURL]; ok {
+			continue
+		}
+		seen[rawURL] = struct{}{}
+		normalized = append(normalized, CaseCitation{
+			URL:           rawURL,
+			Title:         strings.TrimSpace(citation.Title),
+			PublishedDate: strings.TrimSpace(citation.PublishedDate),
+			RetrievedAt:   citation.RetrievedAt,
+		})
+		if len(normalized) >= MaxCaseCitations {
+			break
+		}
+	}
+	return normalized, nil
+}
+
+// IsCitationURL 判断 raw 是否为可作为来源引用的 http/https 链接。
+func IsCitationURL(raw string) bool {
+	parsed, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || parsed.Hostname() == "" {
+		return false
+	}
+	return parsed.Scheme == "http" || parsed.Scheme == "https"
+}
+
+func encodeCaseCitations(citations []CaseCitation) string {
+	if len(citations) == 0 {
+		return ""
+	}
+	rows := make([]caseCitationJSON, 0, len(citations))
+	for _, citation := range citations {
+		rows = append(rows, caseCitationJSON(citation))
+	}
+	payload, err := json.Marshal(rows)
+	if err != nil {
+		return ""
+	}
+	return string(payload)
+}
+
+func decodeCaseCitations(raw string) []CaseCitation {
+	trimmed := strings.TrimSpace(raw)
+	if trimmed == "" {
+		return []CaseCitation{}
+	}
+	var rows []caseCitationJSON
+	if err := json.Unmarshal([]byte(trimmed), &rows); err != nil {
+		return []CaseCitation{}
+	}
+	citations := make([]CaseCitation, 0, len(rows))
+	for _, row := range rows {
+		citations = append(citations, CaseCitation(row))
+	}
+	return citations
+}
+
+const (
+	CitationMatchHistoricalCase = "historical_case"
+	CitationMatchPendingReview  = "pending_review"
+)
+
+// CitationMatch 表示一条引用了指定来源的案件；Kind 区分历史案件与待审核案件，Citations 只含命中的引用。
+type CitationMatch struct {
+	Kind      string
+	ID        string
+	Title     string
+	CreatedAt time.Time
+	Citations []CaseCitation
+}
+
+// FindCasesByCitation 查找引用了指定来源的历史案件与待审核案件，用于核实来源或处理下架请求。
+// source 为完整 http/https 链接时按链接精确匹配（忽略末尾斜杠），否则按域名匹配（包含子域名）。
+func FindCasesByCitation(source string) ([]CitationMatch, error) {
+	matcher, needle := citationMatcher(source)
+	if matcher == nil {
+		return nil, newValidationError("source must be an http/https link or a domain")
+	}
+
+	db, err := database.GetHistoricalCaseDB()
+	if err != nil {
+		return nil, err
+	}
+
+	pattern := "%" + needle + "%"
+	var cases []historicalCaseEntity
+	if err := db.Select("case_id", "title", "citations", "created_at").
+		Where("citations LIKE ?", pattern).Order("created_at desc").Find(&cases).Error; err != nil {
+		return nil, fmt.Errorf("query historical cases by citation failed: %w", err)
+	}
+	var reviews []pendingReviewEntity
+	if err := db.Select("record_id", "title", "citations", "created_at").
+		Where("citations LIKE ?", pattern).Order("created_at desc").Find(&reviews).Error; err != nil {
+		return nil, fmt.Errorf("query pending reviews by citation failed: %w", err)
+	}
+
+	matches := make([]CitationMatch, 0, len(cases)+len(reviews))
+	appendMatch := func(kind string, id string, title string, createdAt time.Time, raw string) {
+		matched := make([]CaseCitation, 0, 1)
+		for _, citation := range decodeCaseCitations(raw) {
+			if matcher(citation.URL) {
+				matched = append(matched, citation)
+			}
+		}
+		if len(matched) == 0 {
+			return
+		}
+		matches = append(matches, CitationMatch{
+			Kind:      kind,
+			ID:        strings.TrimSpace(id),
+			Title:     strings.TrimSpace(title),
+			CreatedAt: createdAt,
+			Citations: matched,
+		})
+	}
+	for _, entity := range cases {
+		appendMatch(CitationMatchHistoricalCase, entity.CaseID, entity.Title, entity.CreatedAt, entity.Citations)
+	}
+	for _, entity := range reviews {
+		appendMatch(CitationMatchPendingReview, entity.RecordID, entity.Title, entity.CreatedAt, entity.Citations)
+	}
+	return matches, nil
+}
+
+// citationMatcher 返回来源匹配函数以及用于 LIKE 预筛选的子串。
+// 链接在 JSON 中可能被转义（如 & 写为 \u0026），因此预筛选只使用主机名。
+func citationMatcher(source string) (func(string) bool, string) {
+	trimmed := strings.TrimSpace(source)
+	if IsCitationURL(trimmed) {
+		target := strings.TrimRight(trimmed, "/")
+		parsed, _ := url.Parse(trimmed)
+		return func(candidate string) bool {
+			return strings.TrimRight(strings.TrimSpace(candidate), "/") == target
+		}, strings.ToLower(parsed.Hostname())
+	}
+
+	domain := strings.TrimPrefix(strings.ToLower(strings.TrimSuffix(trimmed, "/")), "*.")
+	if domain == "" || strings.ContainsAny(domain, "/:?#% ") || !strings.Contains(domain, ".") {
+		return nil, ""
+	}
+	return func(candidate string) bool {
+		parsed, err := url.Parse(strings.TrimSpace(candidate))
+		if err != nil {
+			return false
+		}
+		host := strings.ToLower(parsed.Hostname())
+		return host == domain || strings.HasSuffix(host, "."+domain)
+	}, domain
+}
@@ -0,0 +1,205 @@
+package case_library
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"antifraud/internal/platform/database"
+)
+
+// HistoricalCaseEmbeddingSource 是 embedding 迁移遍历历史案件时的一条记录。
+type HistoricalCaseEmbeddingSource struct {
+	ID                   uint
+	CaseID               string
+	EmbeddingText        string
+	EmbeddingModel       string
+	StagedEmbeddingModel string
+}
+
+// ListHistoricalCaseEmbeddingSources 按主键升序分批列出历史案件及其 embedding 输入文本，afterID 为上一批最后一条的主键。
+func ListHistoricalCaseEmbeddingSources(afterID uint, limit int) ([]HistoricalCaseEmbeddingSource, error) {
+	if limit <= 0 {
+		return []HistoricalCaseEmbeddingSource{}, nil
+	}
+	db, err := database.GetHistoricalCaseDB()
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]historicalCaseEntity, 0, limit)
+	if err := db.Select("id", "case_id", "title", "scam_type", "case_description", "keywords", "embedding_model", "staged_embedding_model").
+		Where("id > ?", afterID).
+		Order("id asc").
+		Limit(limit).
+		Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("list historical cases for re-embedding failed: %w", err)
+	}
+
+	sources := make([]HistoricalCaseEmbeddingSource, 0, len(rows))
+	for _, row := range rows {
+		sources = append(sources, HistoricalCaseEmbeddingSource{
+			ID:     row.ID,
+			CaseID: strings.TrimSpace(row.CaseID),
+			EmbeddingText: BuildEmbeddingInput(CreateHistoricalCaseInput{
+				Title:           strings.TrimSpace(row.Title),
+				ScamType:        strings.TrimSpace(row.ScamType),
+				CaseDescription: strings.TrimSpace(row.CaseDescription),
+				Keywords:        decodeStringList(row.Keywords),
+			}),
+			EmbeddingModel:       strings.TrimSpace(row.EmbeddingModel),
+			StagedEmbeddingModel: strings.TrimSpace(row.StagedEmbeddingModel),
+		})
+	}
+	return sources, nil
+}
+
+// StageHistoricalCaseEmbeddings 按目标模型批量重新生成历史案件向量并写入暂存列，不影响当前检索。
+// 返回值与 sources 一一对应，nil 表示该条暂存成功。
+func StageHistoricalCaseEmbeddings(ctx context.Context, sources []HistoricalCaseEmbeddingSource, modelName string) []error {
+	errs := make([]error, len(sources))
+	if len(sources) == 0 {
+		return errs
+	}
+	targetModel := strings.TrimSpace(modelName)
+	fail := func(err error) []error {
+		for index := range errs {
+			errs[index] = err
+		}
+		return errs
+	}
+	if targetModel == "" {
+		return fail(fmt.Errorf("target embedding model is empty"))
+	}
+
+	texts := make([]string, 0, len(sources))
+	for _, source := range sources {
+		texts = append(texts, source.EmbeddingText)
+	}
+	vectors, _, err := generateCaseEmbeddingsWithModel(ctx, texts, targetModel)
+	if err != nil {
+		return fail(fmt.Errorf("generate embeddings failed: %w", err))
+	}
+
+	db, err := database.GetHistoricalCaseDB()
+	if err != nil {
+		return fail(err)
+	}
+	for index, source := range sources {
+		if _, ok := normalizeL2Vector(vectors[index]); !ok {
+			errs[index] = fmt.Errorf("generate embedding failed: case_id=%s err=empty or invalid vector", source.CaseID)
+			continue
+		}
+		cleanVector := append([]float64{}, vectors[index]...)
+		result := db.Model(&historicalCaseEntity{}).
+			Where("case_id = ?", strings.TrimSpace(source.CaseID)).
+			Updates(map[string]interface{}{
+				"staged_embedding_vector":    encodeFloatList(cleanVector),
+				"staged_embedding_model":     targetModel,
+				"staged_embedding_dimension": len(cleanVector),
+			})
+		if result.Error != nil {
+			errs[index] = fmt.Errorf("stage historical case embedding failed: case_id=%s err=%w", source.CaseID, result.Error)
+			continue
+		}
+		if result.RowsAffected == 0 {
+			errs[index] = fmt.Errorf("historical case not found: case_id=%s", source.CaseID)
+		}
+	}
+	return errs
+}
+
+// CountHistoricalCaseEmbeddings 返回案件总数，以及已具备目标模型向量（正式或暂存）的案件数。
+func CountHistoricalCaseEmbeddings(modelName string) (int64, int64, error) {
+	db, err := database.GetHistoricalCaseDB()
+	if err != nil {
+		return 0, 0, err
+	}
+	var total int64
+	if err := db.Model(&historicalCaseEntity{}).Count(&total).Error; err != nil {
+		return 0, 0, fmt.Errorf("count historical cases failed: %w", err)
+	}
+	var ready int64
+	trimmedModel := strings.TrimSpace(modelName)
+	if err := db.Model(&historicalCaseEntity{}).
+		Where("embedding_model = ? OR staged_embedding_model = ?", trimmedModel, trimmedModel).
+		Count(&ready).Error; err != nil {
+		return 0, 0, fmt.Errorf("count migrated historical cases failed: %w", err)
+	}
+	return total, ready, nil
+}
+
+// PromoteStagedHistoricalCaseEmbeddings 用一条 UPDATE 原子地把目标模型的暂存向量切换为正式向量，
+// 随后按 DB 重建 Redis 向量缓存（同时刷新 ANN 索引版本号）并失效图谱缓存。
+func PromoteStagedHistoricalCaseEmbeddings(modelName string) (int64, error) {
+	trimmedModel := strings.TrimSpace(modelName)
+	if trimmedModel == "" {
+		return 0, fmt.Errorf("target embedding model is empty")
+	}
+	db, err := database.GetHistoricalCaseDB()
+	if err != nil {
+		return 0, err
+	}
+
+	result := db.Exec(`UPDATE historical_case_library
+		SET embedding_vector = staged_embedding_vector,
+			embedding_model = staged_embedding_model,
+			embedding_dimension = staged_embedding_dimension,
+			staged_embedding_vector = '',
+			staged_embedding_model = '',
+			staged_embedding_dimension = 0,
+			updated_at = ?
+		WHERE staged_embedding_model = ?`, time.Now(), trimmedModel)
+	if result.Error != nil {
+		return 0, fmt.Errorf("promote staged historical case embeddings failed: %w", result.Error)
+	}
+
+	records, err := queryAllHistoricalCasesFromDB()
+	if err != nil {
+		return result.RowsAffected, fmt.Errorf("reload historical cases after promotion failed: %w", err)
+	}
+	if err := replaceHistoricalCaseVectorCache(records); err != nil {
+		log.Printf("[case_library] rebuild vector cache after embedding promotion failed: %v", err)
+	}
+	touchHistoricalCaseGraphCacheVersion()
+	return result.RowsAffected, nil
+}
+
+// ClearStagedHistoricalCaseEmbeddings 清空全部暂存向量，用于放弃迁移。
+func ClearStagedHistoricalCaseEmbeddings() error {
+	db, err := database.GetHistoricalCaseDB()
+	if err != nil {
+		return err
+	}
+	if err := db.Exec(`UPDATE historical_case_library
+		SET staged_embedding_vector = '', staged_embedding_model = '', staged_embedding_dimension = 0
+		WHERE staged_embedding_model <> ''`).Error; err != nil {
+		return fmt.Errorf("clear staged historical case embeddings failed: %w", err)
+	}
+	return nil
+}
+
+// DominantHistoricalCaseEmbeddingModel 返回案件库中使用最多的 embedding 模型；库为空时返回空串。
+func DominantHistoricalCaseEmbeddingModel() (string, error) {
+	db, err := database.GetHistoricalCaseDB()
+	if err != nil {
+		return "", err
+	}
+	var row struct {
+		EmbeddingModel string
+		Total          int64
+	}
+	result := db.Model(&historicalCaseEntity{}).
+		Select("embedding_model, COUNT(*) AS total").
+		Where("embedding_model <> ''").
+		Group("embedding_model").
+		Order("total desc").
+		Limit(1).
+		Scan(&row)
+	if result.Error != nil {
+		return "", fmt.Errorf("query dominant embedding model failed: %w", result.Error)
+	}
+	return strings.TrimSpace(row.EmbeddingModel), nil
+}
@@ -20,9 +20,16 @@ func insertHistoricalCasePrepared(userID string, prepared preparedHistoricalCase
 		Keywords:           encodeStringList(prepared.normalizedInput.Keywords),
 		ViolatedLaw:        prepared.normalizedInput.ViolatedLaw,
 		Suggestion:         prepared.normalizedInput.Suggestion,
+		Citations:          encodeCaseCitations(prepared.normalizedInput.Citations),
 		EmbeddingVector:    encodeFloatList(prepared.vector),
 		EmbeddingModel:     strings.TrimSpace(prepared.modelName),
 		EmbeddingDimension: len(prepared.vector),
+		Revision:           1,
+	}
+	if len(prepared.stagedVector) > 0 && strings.TrimSpace(prepared.stagedModel) != "" {
+		entity.StagedEmbeddingVector = encodeFloatList(prepared.stagedVector)
+		entity.StagedEmbeddingModel = strings.TrimSpace(prepared.stagedModel)
+		entity.StagedEmbeddingDimension = len(prepared.stagedVector)
 	}
 
 	db, err := database.GetHistoricalCaseDB()
@@ -0,0 +1,218 @@
+package case_library
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+
+	"antifraud/internal/platform/lexicalindex"
+)
+
+const (
+	// hybridRRFConstant 是倒数排名融合（RRF）的平滑常数，取业界常用的 60。
+	hybridRRFConstant = 60
+	// minHybridChannelCandidates 是每个召回通道至少取回的候选数，保证融合时两个通道有足够重叠。
+	minHybridChannelCandidates = 50
+)
+
+// HybridSearchQuery 是混合检索的输入。
+// Text 走 BM25 关键词通道，Vector 走向量通道；两者至少提供一个，缺失的通道直接跳过。
+type HybridSearchQuery struct {
+	Text   string
+	Vector []float64
+	TopK   int
+	Filter SimilarCaseRecallFilter
+}
+
+// HybridCaseResult 是混合检索的一条结果。
+// Similarity 始终是查询向量与案件向量的余弦相似度（无查询向量时为 0）；
+// VectorRank/LexicalRank 为该案件在各通道中的名次，0 表示未被该通道召回。
+type HybridCaseResult struct {
+	SimilarCaseResult
+	FusionScore  float64
+	VectorRank   int
+	LexicalRank  int
+	LexicalScore float64
+	MatchedTerms []string
+}
+
+type hybridLexicalHit struct {
+	result       SimilarCaseResult
+	score        float64
+	matchedTerms []string
+}
+
+// SearchHybrid 同时执行向量召回与 BM25 关键词召回，并用倒数排名融合（RRF）合并两路结果：
+// score = Σ 1/(60 + rank)。
+// 关键词通道覆盖标题、描述、典型话术、关键词等字段，能补足纯向量检索对账号、App 名称、固定话术的漏召回。
+// 两个通道共享 target_group/scam_type 过滤条件，索引不可用时回退到快照暴力扫描。
+func SearchHybrid(query HybridSearchQuery) ([]HybridCaseResult, int, error) {
+	text := strings.TrimSpace(query.Text)
+	normalizedQuery, hasVector := normalizeL2Vector(query.Vector)
+	if text == "" && !hasVector {
+		return nil, 0, fmt.Errorf("hybrid search requires query text or a valid embedding vector")
+	}
+
+	appliedTopK := normalizeTopK(query.TopK)
+	channelLimit := appliedTopK * 4
+	if channelLimit < minHybridChannelCandidates {
+		channelLimit = minHybridChannelCandidates
+	}
+	filter := resolveQueryEmbeddingModel(query.Filter)
+	lexicalFilter := filter
+	lexicalFilter.EmbeddingModel = ""
+
+	var vectorResults []SimilarCaseResult
+	var lexicalHits []hybridLexicalHit
+	indexed := withReadyHistoricalCaseVectorIndex(func(a *historicalCaseANNIndex) {
+		if hasVector {
+			var mismatched bool
+			if vectorResults, mismatched = a.searchLocked(normalizedQuery, channelLimit, filter); mismatched {
+				log.Printf("[case_library] hybrid search skipped vector channel: no vectors of model %s", filter.EmbeddingModel)
+			}
+		}
+		if text != "" {
+			lexicalHits = a.lexicalSearchLocked(text, normalizedQuery, filter.EmbeddingModel, channelLimit, lexicalFilter)
+		}
+	})
+	if !indexed {
+		cases, err := snapshotHistoricalCaseVectorCache()
+		if err != nil {
+			return nil, 0, err
+		}
+		if hasVector && containsEmbeddingModel(cases, filter.EmbeddingModel) {
+			vectorResults = collectSimilarCaseResults(normalizedQuery, cases, filter)
+			sortSimilarCaseResults(vectorResults)
+			if len(vectorResults) > channelLimit {
+				vectorResults = vectorResults[:channelLimit]
+			}
+		}
+		if text != "" {
+			lexicalHits = scanHistoricalCasesLexical(cases, text, normalizedQuery, filter.EmbeddingModel, channelLimit, lexicalFilter)
+		}
+	}
+
+	results := fuseHybridResults(vectorResults, lexicalHits)
+	if len(results) > appliedTopK {
+		results = results[:appliedTopK]
+	}
+	return results, appliedTopK, nil
+}
+
+// lexicalSearchLocked 在索引内的 BM25 通道检索，并为命中案件补算向量相似度（仅限与查询同一模型的向量）。
+func (a *historicalCaseANNIndex) lexicalSearchLocked(text string, normalizedQuery []float64, queryModel string, limit int, filter SimilarCaseRecallFilter) []hybridLexicalHit {
+	var accept func(string) bool
+	if filter.TargetGroup != "" || filter.ScamType != "" {
+		accept = func(caseID string) bool {
+			return matchSimilarCaseRecallFilter(a.records[caseID], filter)
+		}
+	}
+
+	hits := a.lexical.Search(text, limit, accept)
+	results := make([]hybridLexicalHit, 0, len(hits))
+	for _, hit := range hits {
+		item, ok := a.records[hit.ID]
+		if !ok {
+			continue
+		}
+		similarity := 0.0
+		if len(normalizedQuery) > 0 && embeddingModelCompatible(item.EmbeddingModel, queryModel) {
+			if caseVector, ok := a.index.Vector(hit.ID); ok {
+				similarity = cosineSimilarityNormalized(normalizedQuery, caseVector)
+			}
+		}
+		results = append(results, hybridLexicalHit{
+			result:       similarCaseResultFromRecord(item, similarity),
+			score:        hit.Score,
+			matchedTerms: hit.MatchedTerms,
+		})
+	}
+	return results
+}
+
+// scanHistoricalCasesLexical 是索引不可用时的关键词通道：按当前快照临时构建 BM25 后检索。
+func scanHistoricalCasesLexical(cases []HistoricalCaseRecord, text string, normalizedQuery []float64, queryModel string, limit int, filter SimilarCaseRecallFilter) []hybridLexicalHit {
+	lexical := lexicalindex.NewBM25(lexicalindex.Options{})
+	byID := make(map[string]HistoricalCaseRecord, len(cases))
+	for _, item := range cases {
+		caseID := strings.TrimSpace(item.CaseID)
+		if caseID == "" || !matchSimilarCaseRecallFilter(item, filter) {
+			continue
+		}
+		if lexical.Upsert(caseID, historicalCaseLexicalFields(item)...) {
+			byID[caseID] = item
+		}
+	}
+
+	hits := lexical.Search(text, limit, nil)
+	results := make([]hybridLexicalHit, 0, len(hits))
+	for _, hit := range hits {
+		item := byID[hit.ID]
+		similarity := 0.0
+		if len(normalizedQuery) > 0 && embeddingModelCompatible(item.EmbeddingModel, queryModel) {
+			if caseVector, ok := normalizeL2Vector(item.EmbeddingVector); ok {
+				similarity = cosineSimilarityNormalized(normalizedQuery, caseVector)
+			}
+		}
+		results = append(results, hybridLexicalHit{
+			result:       similarCaseResultFromRecord(item, similarity),
+			score:        hit.Score,
+			matchedTerms: hit.MatchedTerms,
+		})
+	}
+	return results
+}
+
+// fuseHybridResults 按 RRF 合并两路有序结果；同分时依次按向量相似度、创建时间降序。
+func fuseHybridResults(vectorResults []SimilarCaseResult, lexicalHits []hybridLexicalHit) []HybridCaseResult {
+	fused := make(map[string]*HybridCaseResult, len(vectorResults)+len(lexicalHits))
+	entryFor := func(result SimilarCaseResult) *HybridCaseResult {
+		entry, ok := fused[result.CaseID]
+		if !ok {
+			entry = &HybridCaseResult{SimilarCaseResult: result}
+			fused[result.CaseID] = entry
+		}
+		return entry
+	}
+
+	for index, item := range vectorResults {
+		entry := entryFor(item)
+		entry.VectorRank = index + 1
+		entry.FusionScore += 1.0 / float64(hybridRRFConstant+index+1)
+	}
+	for index, hit := range lexicalHits {
+		entry := entryFor(hit.result)
+		entry.LexicalRank = index + 1
+		entry.LexicalScore = hit.score
+		entry.MatchedTerms = append([]string{}, hit.matchedTerms...)
+		entry.FusionScore += 1.0 / float64(hybridRRFConstant+index+1)
+	}
+
+	results := make([]HybridCaseResult, 0, len(fused))
+	for _, entry := range fused {
+		results = append(results, *entry)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if math.Abs(results[i].FusionScore-results[j].FusionScore) > 1e-12 {
+			return results[i].FusionScore > results[j].FusionScore
+		}
+		if math.Abs(results[i].Similarity-results[j].Similarity) > 1e-12 {
+			return results[i].Similarity > results[j].Similarity
+		}
+		return results[i].CreatedAt.After(results[j].CreatedAt)
+	})
+	return results
+}
+
+// historicalCaseLexicalFields 返回参与关键词索引的字段；标题与关键词加权，便于精确短语优先命中。
+func historicalCaseLexicalFields(record HistoricalCaseRecord) []lexicalindex.Field {
+	return []lexicalindex.Field{
+		{Text: record.Title, Weight: 2},
+		{Text: strings.Join(record.Keywords, " "), Weight: 2},
+		{Text: strings.Join(record.TypicalScripts, " "), Weight: 1},
+		{Text: record.CaseDescription, Weight: 1},
+		{Text: record.ScamType, Weight: 1},
+	}
+}
@@ -0,0 +1,229 @@
+package case_library
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	model "antifraud/internal/modules/multi_agent/adapters/outbound/case_library/model"
+	"antifraud/internal/platform/database"
+
+	"gorm.io/gorm"
+)
+
+// MaxMergeHistoricalCases 是单次合并允许并入保留案件的案件数上限。
+const MaxMergeHistoricalCases = 20
+
+type historicalCaseRedirectEntity = model.HistoricalCaseRedirectEntity
+
+// HistoricalCaseRedirect 表示一条合并跳转：FromCaseID 已并入 ToCaseID。
+type HistoricalCaseRedirect struct {
+	FromCaseID string
+	ToCaseID   string
+	FromTitle  string
+	MergedBy   string
+	CreatedAt  time.Time
+}
+
+// MergeHistoricalCases 把 mergedIDs 指向的近似重复案件合并进保留案件 canonicalID。
+// 保留案件的其余字段不变，典型话术、关键词与来源引用取并集；合并产生一个 change_type 为 merge 的新版本。
+// 被合并的案件及其版本历史会被删除，并记录旧 ID 到保留案件的跳转，审核日志中对旧 ID 的引用同步改指保留案件。
+// expectedRevision 大于 0 时要求保留案件当前版本号一致，否则返回 ErrHistoricalCaseRevisionConflict。
+func MergeHistoricalCases(ctx context.Context, editorID string, canonicalID string, expectedRevision int, mergedIDs []string) (HistoricalCaseRecord, bool, error) {
+	current, found, err := loadHistoricalCaseEntity(canonicalID)
+	if err != nil || !found {
+		return HistoricalCaseRecord{}, found, err
+	}
+	if expectedRevision > 0 && expectedRevision != normalizeHistoricalCaseRevision(current.Revision) {
+		return HistoricalCaseRecord{}, true, ErrHistoricalCaseRevisionConflict
+	}
+
+	canonicalCaseID := strings.TrimSpace(current.CaseID)
+	mergedCaseIDs := normalizeStringList(mergedIDs)
+	if len(mergedCaseIDs) == 0 {
+		return HistoricalCaseRecord{}, true, newValidationError("merged_case_ids is required")
+	}
+	if len(mergedCaseIDs) > MaxMergeHistoricalCases {
+		return HistoricalCaseRecord{}, true, newValidationError("at most %d cases can be merged at once", MaxMergeHistoricalCases)
+	}
+	mergedEntities := make([]historicalCaseEntity, 0, len(mergedCaseIDs))
+	for _, caseID := range mergedCaseIDs {
+		if caseID == canonicalCaseID {
+			return HistoricalCaseRecord{}, true, newValidationError("case %s cannot be merged into itself", caseID)
+		}
+		entity, found, err := loadHistoricalCaseEntity(caseID)
+		if err != nil {
+			return HistoricalCaseRecord{}, true, err
+		}
+		if !found {
+			if target, redirected, err := ResolveHistoricalCaseID(caseID); err == nil && redirected {
+				return HistoricalCaseRecord{}, true, newValidationError("case %s has already been merged into %s", caseID, target)
+			}
+			return HistoricalCaseRecord{}, true, newValidationError("case %s not found", caseID)
+		}
+		mergedEntities = append(mergedEntities, entity)
+	}
+
+	previous := contentFromEntity(current)
+	content := previous
+	citations := decodeCaseCitations(current.Citations)
+	for _, entity := range mergedEntities {
+		source := recordFromEntity(entity)
+		content.TypicalScripts = append(append([]string{}, content.TypicalScripts...), source.TypicalScripts...)
+		content.Keywords = append(append([]string{}, content.Keywords...), source.Keywords...)
+		citations = append(citations, source.Citations...)
+	}
+	normalized, err := normalizeAndValidateInput(content)
+	if err != nil {
+		return HistoricalCaseRecord{}, true, err
+	}
+	citations, err = normalizeCaseCitations(citations)
+	if err != nil {
+		return HistoricalCaseRecord{}, true, err
+	}
+
+	editor := normalizeUserID(editorID)
+	now := time.Now()
+	updates := map[string]interface{}{
+		"typical_scripts": encodeStringList(normalized.TypicalScripts),
+		"keywords":        encodeStringList(normalized.Keywords),
+		"citations":       encodeCaseCitations(citations),
+		"revision":        normalizeHistoricalCaseRevision(current.Revision) + 1,
+		"updated_by":      editor,
+		"updated_at":      now,
+	}
+	if BuildEmbeddingInput(previous) != BuildEmbeddingInput(normalized) {
+		prepared, err := prepareHistoricalCaseInput(ctx, normalized)
+		if err != nil {
+			return HistoricalCaseRecord{}, true, err
+		}
+		// 参与合并的案件本就彼此相似，查重时全部排除。
+		if duplicateErr := detectDuplicateHistoricalCaseExcluding(prepared.vector, append([]string{canonicalCaseID}, mergedCaseIDs...)...); duplicateErr != nil {
+			return HistoricalCaseRecord{}, true, duplicateErr
+		}
+		setPreparedEmbeddingUpdates(updates, prepared)
+	}
+
+	db, err := database.GetHistoricalCaseDB()
+	if err != nil {
+		return HistoricalCaseRecord{}, true, err
+	}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		snapshot := revisionEntityFromCurrent(current)
+		snapshot.ReplacedBy = editor
+		snapshot.ChangeType = HistoricalCaseChangeMerge
+		snapshot.CreatedAt = now
+		result := tx.Model(&historicalCaseEntity{}).
+			Where("case_id = ? AND revision = ?", canonicalCaseID, current.Revision).
+			Updates(updates)
+		if result.Error != nil {
+			return fmt.Errorf("update canonical historical case failed: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return ErrHistoricalCaseRevisionConflict
+		}
+		if err := tx.Create(&snapshot).Error; err != nil {
+			return fmt.Errorf("save historical case revision failed: %w", err)
+		}
+
+		result = tx.Where("case_id IN ?", mergedCaseIDs).Delete(&historicalCaseEntity{})
+		if result.Error != nil {
+			return fmt.Errorf("delete merged historical cases failed: %w", result.Error)
+		}
+		if result.RowsAffected != int64(len(mergedCaseIDs)) {
+			return ErrHistoricalCaseRevisionConflict
+		}
+		if err := tx.Where("case_id IN ?", mergedCaseIDs).Delete(&historicalCaseRevisionEntity{}).Error; err != nil {
+			return fmt.Errorf("delete merged historical case revisions failed: %w", err)
+		}
+
+		// 此前已并入被合并案件的跳转直接改指保留案件，避免出现跳转链。
+		if err := tx.Model(&historicalCaseRedirectEntity{}).
+			Where("to_case_id IN ?", mergedCaseIDs).
+			Update("to_case_id", canonicalCaseID).Error; err != nil {
+			return fmt.Errorf("update historical case redirects failed: %w", err)
+		}
+		redirects := make([]historicalCaseRedirectEntity, 0, len(mergedEntities))
+		for _, entity := range mergedEntities {
+			redirects = append(redirects, historicalCaseRedirectEntity{
+				FromCaseID: strings.TrimSpace(entity.CaseID),
+				ToCaseID:   canonicalCaseID,
+				FromTitle:  strings.TrimSpace(entity.Title),
+				MergedBy:   editor,
+				CreatedAt:  now,
+			})
+		}
+		if err := tx.Create(&redirects).Error; err != nil {
+			return fmt.Errorf("save historical case redirects failed: %w", err)
+		}
+		if err := tx.Model(&pendingReviewAuditEntity{}).
+			Where("case_id IN ?", mergedCaseIDs).
+			Update("case_id", canonicalCaseID).Error; err != nil {
+			return fmt.Errorf("redirect pending review audit logs failed: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return HistoricalCaseRecord{}, true, err
+	}
+
+	updated, found, err := loadHistoricalCaseEntity(canonicalCaseID)
+	if err != nil {
+		return HistoricalCaseRecord{}, true, err
+	}
+	if !found {
+		return HistoricalCaseRecord{}, true, fmt.Errorf("historical case %s disappeared after merge", canonicalCaseID)
+	}
+	for _, caseID := range mergedCaseIDs {
+		removeHistoricalCaseVectorCache(caseID)
+	}
+	record := recordFromEntity(updated)
+	upsertHistoricalCaseVectorCache(record)
+	touchHistoricalCaseGraphCacheVersion()
+	return record, true, nil
+}
+
+// ResolveHistoricalCaseID 查找已合并案件 ID 的跳转目标；caseID 没有跳转记录时 redirected 为 false。
+func ResolveHistoricalCaseID(caseID string) (target string, redirected bool, err error) {
+	trimmedCaseID := strings.TrimSpace(caseID)
+	if trimmedCaseID == "" {
+		return "", false, nil
+	}
+	db, err := database.GetHistoricalCaseDB()
+	if err != nil {
+		return "", false, err
+	}
+	var entity historicalCaseRedirectEntity
+	query := db.Where("from_case_id = ?", trimmedCaseID).Limit(1).Find(&entity)
+	if query.Error != nil {
+		return "", false, fmt.Errorf("query historical case redirect failed: %w", query.Error)
+	}
+	if query.RowsAffected == 0 {
+		return "", false, nil
+	}
+	return strings.TrimSpace(entity.ToCaseID), true, nil
+}
+
+// ListMergedHistoricalCases 返回已并入 caseID 的案件跳转记录，按合并时间倒序。
+func ListMergedHistoricalCases(caseID string) ([]HistoricalCaseRedirect, error) {
+	db, err := database.GetHistoricalCaseDB()
+	if err != nil {
+		return nil, err
+	}
+	rows := make([]historicalCaseRedirectEntity, 0)
+	if err := db.Where("to_case_id = ?", strings.TrimSpace(caseID)).Order("created_at desc").Order("id desc").Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("query merged historical cases failed: %w", err)
+	}
+	redirects := make([]HistoricalCaseRedirect, 0, len(rows))
+	for _, row := range rows {
+		redirects = append(redirects, HistoricalCaseRedirect{
+			FromCaseID: strings.TrimSpace(row.FromCaseID),
+			ToCaseID:   strings.TrimSpace(row.ToCaseID),
+			FromTitle:  strings.TrimSpace(row.FromTitle),
+			MergedBy:   normalizeUserID(row.MergedBy),
+			CreatedAt:  row.CreatedAt,
+		})
+	}
+	return redirects, nil
+}
@@ -22,6 +22,24 @@ type CreateHistoricalCaseInput struct {
 	Keywords        []string
 	ViolatedLaw     string
 	Suggestion      string
+	// Citations 为案件依据的公开来源，不参与向量化、查重与版本管理。
+	Citations []CaseCitation
+	// Embedding 为导入文件携带的预计算向量，仅当其模型与当前固定模型一致时复用，否则重新向量化。
+	Embedding *CaseEmbedding
+}
+
+// CaseEmbedding 表示一条案件已生成的向量及其所属模型。
+type CaseEmbedding struct {
+	Model  string
+	Vector []float64
+}
+
+// CaseCitation 表示案件的一条来源引用，RetrievedAt 为检索到该来源的时间。
+type CaseCitation struct {
+	URL           string
+	Title         string
+	PublishedDate string
+	RetrievedAt   time.Time
 }
 
 // HistoricalCaseRecord 表示历史案件完整记录模型。
@@ -37,11 +55,42 @@ type HistoricalCaseRecord struct {
 	Keywords           []string
 	ViolatedLaw        string
 	Suggestion         string
+	Citations          []CaseCitation
 	EmbeddingVector    []float64
 	EmbeddingModel     string
 	EmbeddingDimension int
-	CreatedAt          time.Time
-	UpdatedAt          time.Time
+	// Revision 为当前版本号，创建时为 1，每次编辑或回滚加 1；UpdatedBy 为最近一次编辑人。
+	Revision  int
+	UpdatedBy string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// UpdateHistoricalCaseInput 表示编辑历史案件的输入载荷，nil 字段保持原值不变。
+type UpdateHistoricalCaseInput struct {
+	Title           *string
+	TargetGroup     *string
+	RiskLevel       *string
+	ScamType        *string
+	CaseDescription *string
+	TypicalScripts  *[]string
+	Keywords        *[]string
+	ViolatedLaw     *string
+	Suggestion      *string
+}
+
+// HistoricalCaseRevision 表示历史案件的一个历史版本快照。
+type HistoricalCaseRevision struct {
+	CaseID     string
+	Revision   int
+	Content    CreateHistoricalCaseInput
+	EditedBy   string
+	ReplacedBy string
+	ChangeType string
+	// RestoredFrom 仅在 ChangeType 为 rollback 时有值，表示替换该版本时回滚到的版本号。
+	RestoredFrom int
+	CreatedAt    time.Time
+	ReplacedAt   time.Time
 }
 
 // HistoricalCasePreview 表示历史案件预览模型。
@@ -79,35 +128,91 @@ type HistoricalCaseEntity struct {
 	Keywords           string    `gorm:"type:text;not null"`
 	ViolatedLaw        string    `gorm:"type:text;not null"`
 	Suggestion         string    `gorm:"type:text;not null"`
+	Citations          string    `gorm:"type:text"`
 	EmbeddingVector    string    `gorm:"type:text;not null"`
 	EmbeddingModel     string    `gorm:"size:128;not null"`
 	EmbeddingDimension int       `gorm:"not null"`
+	Revision           int       `gorm:"not null;default:1"`
+	UpdatedBy          string    `gorm:"size:64"`
 	CreatedAt          time.Time `gorm:"index"`
 	UpdatedAt          time.Time
+
+	// Staged* 保存 embedding 迁移期间按目标模型生成的向量，切换完成后写回 Embedding* 并清空。
+	StagedEmbeddingVector    string `gorm:"type:text"`
+	StagedEmbeddingModel     string `gorm:"size:128;index"`
+	StagedEmbeddingDimension int
 }
 
 func (HistoricalCaseEntity) TableName() string {
 	return "historical_case_library"
 }
 
+// HistoricalCaseRevisionEntity 是 historical_case_revisions 表 ORM 映射实体。
+// 每行保存被编辑或回滚替换掉的一个旧版本：EditedBy 为该版本的作者，ReplacedBy 为替换它的编辑人。
+type HistoricalCaseRevisionEntity struct {
+	ID              uint      `gorm:"primaryKey"`
+	CaseID          string    `gorm:"size:32;uniqueIndex:idx_historical_case_revision,priority:1;not null"`
+	Revision        int       `gorm:"uniqueIndex:idx_historical_case_revision,priority:2;not null"`
+	Title           string    `gorm:"type:text;not null"`
+	TargetGroup     string    `gorm:"size:32;not null"`
+	RiskLevel       string    `gorm:"size:16;not null"`
+	ScamType        string    `gorm:"size:64;not null"`
+	CaseDescription string    `gorm:"type:text;not null"`
+	TypicalScripts  string    `gorm:"type:text;not null"`
+	Keywords        string    `gorm:"type:text;not null"`
+	ViolatedLaw     string    `gorm:"type:text;not null"`
+	Suggestion      string    `gorm:"type:text;not null"`
+	EditedBy        string    `gorm:"size:64;not null"`
+	VersionAt       time.Time `gorm:"not null"`
+	ReplacedBy      string    `gorm:"size:64;not null"`
+	ChangeType      string    `gorm:"size:16;not null"`
+	RestoredFrom    int       `gorm:"not null;default:0"`
+	CreatedAt       time.Time `gorm:"index"`
+}
+
+func (HistoricalCaseRevisionEntity) TableName() string {
+	return "historical_case_revisions"
+}
+
+// HistoricalCaseRedirectEntity 是 historical_case_redirects 表 ORM 映射实体。
+// 案件被合并后保留 FromCaseID → ToCaseID 的跳转，旧 ID 的引用据此定位到保留案件。
+type HistoricalCaseRedirectEntity struct {
+	ID         uint      `gorm:"primaryKey"`
+	FromCaseID string    `gorm:"size:32;uniqueIndex;not null"`
+	ToCaseID   string    `gorm:"size:32;index;not null"`
+	FromTitle  string    `gorm:"type:text;not null"`
+	MergedBy   string    `gorm:"size:64;not null"`
+	CreatedAt  time.Time `gorm:"index"`
+}
+
+func (HistoricalCaseRedirectEntity) TableName() string {
+	return "historical_case_redirects"
+}
+
 // PendingReviewEntity 是 pending_review_cases 表 ORM 映射实体。
+// Source 标记提交来源（user 为分析流程代用户提交，agent 为案件采集 Agent 提交）；ClaimedBy/ClaimedAt 记录审核认领。
 type PendingReviewEntity struct {
-	ID                 uint      `gorm:"primaryKey"`
-	RecordID           string    `gorm:"size:32;uniqueIndex;not null"`
-	UserID             string    `gorm:"size:64;index;not null"`
-	Title              string    `gorm:"type:text;not null"`
-	TargetGroup        string    `gorm:"size:32;index;not null"`
-	RiskLevel          string    `gorm:"size:16;index;not null;default:'中'"`
-	ScamType           string    `gorm:"size:64;index;not null;default:'其他诈骗类'"`
-	CaseDescription    string    `gorm:"type:text;not null"`
-	TypicalScripts     string    `gorm:"type:text;not null"`
-	Keywords           string    `gorm:"type:text;not null"`
-	ViolatedLaw        string    `gorm:"type:text;not null"`
-	Suggestion         string    `gorm:"type:text;not null"`
-	EmbeddingVector    string    `gorm:"type:text;not null"`
-	EmbeddingModel     string    `gorm:"size:128;not null"`
-	EmbeddingDimension int       `gorm:"not null"`
-	CreatedAt          time.Time `gorm:"index"`
+	ID                 uint       `gorm:"primaryKey"`
+	RecordID           string     `gorm:"size:32;uniqueIndex;not null"`
+	UserID             string     `gorm:"size:64;index;not null"`
+	Title              string     `gorm:"type:text;not null"`
+	TargetGroup        string     `gorm:"size:32;index;not null"`
+	RiskLevel          string     `gorm:"size:16;index;not null;default:'中'"`
+	ScamType           string     `gorm:"size:64;index;not null;default:'其他诈骗类'"`
+	CaseDescription    string     `gorm:"type:text;not null"`
+	TypicalScripts     string     `gorm:"type:text;not null"`
+	Keywords           string     `gorm:"type:text;not null"`
+	ViolatedLaw        string     `gorm:"type:text;not null"`
+	Suggestion         string     `gorm:"type:text;not null"`
+	Citations          string     `gorm:"type:text"`
+	EmbeddingVector    string     `gorm:"type:text;not null"`
+	EmbeddingModel     string     `gorm:"size:128;not null"`
+	EmbeddingDimension int        `gorm:"not null"`
+	Source             string     `gorm:"size:16;index;not null;default:'user'"`
+	ClaimedBy          string     `gorm:"size:64;index;not null;default:''"`
+	ClaimedAt          *time.Time `gorm:"index"`
+	UpdatedBy          string     `gorm:"size:64;not null;default:''"`
+	CreatedAt          time.Time  `gorm:"index"`
 	UpdatedAt          time.Time
 }
 
@@ -128,6 +233,11 @@ type PendingReviewRecord struct {
 	Keywords        []string
 	ViolatedLaw     string
 	Suggestion      string
+	Citations       []CaseCitation
+	Source          string
+	ClaimedBy       string
+	ClaimedAt       time.Time
+	UpdatedBy       string
 	CreatedAt       time.Time
 	UpdatedAt       time.Time
 }
@@ -135,10 +245,33 @@ type PendingReviewRecord struct {
 // PendingReviewPreview 表示待审核案件预览模型。
 type PendingReviewPreview struct {
 	RecordID    string
+	UserID      string
 	Title       string
 	TargetGroup string
 	RiskLevel   string
 	ScamType    string
 	ViolatedLaw string
+	Source      string
+	ClaimedBy   string
+	ClaimedAt   time.Time
 	CreatedAt   time.Time
 }
+
+// PendingReviewAuditEntity 是 pending_review_audit_logs 表 ORM 映射实体。
+// 审核记录在待审核案件通过或拒绝后仍然保留，Title 为动作发生时的案件标题快照。
+type PendingReviewAuditEntity struct {
+	ID         uint      `gorm:"primaryKey"`
+	RecordID   string    `gorm:"size:32;index;not null"`
+	Action     string    `gorm:"size:16;index;not null"`
+	ReviewerID string    `gorm:"size:64;index;not null"`
+	Title      string    `gorm:"type:text;not null"`
+	Reason     string    `gorm:"type:text;not null"`
+	Comment    string    `gorm:"type:text;not null"`
+	Changes    string    `gorm:"type:text;not null"`
+	CaseID     string    `gorm:"size:32;not null;default:''"`
+	CreatedAt  time.Time `gorm:"index"`
+}
+
+func (PendingReviewAuditEntity) TableName() string {
+	return "pending_review_audit_logs"
+}
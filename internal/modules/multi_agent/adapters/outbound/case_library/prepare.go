@@ -3,6 +3,7 @@ package case_library
 import (
 	"context"
 	"fmt"
+	"log"
 	"strings"
 
 	"antifraud/internal/platform/embedding"
@@ -12,9 +13,18 @@ type preparedHistoricalCaseInput struct {
 	normalizedInput CreateHistoricalCaseInput
 	vector          []float64
 	modelName       string
+	// stagedVector/stagedModel 是 embedding 迁移进行中双写的目标模型向量。
+	stagedVector []float64
+	stagedModel  string
 }
 
-var generateCaseEmbedding = embedding.GenerateVector
+var (
+	generateCaseEmbedding           = embedding.GenerateVector
+	generateCaseEmbeddingWithModel  = embedding.GenerateVectorWithModel
+	generateCaseEmbeddings          = embedding.GenerateVectors
+	generateCaseEmbeddingsWithModel = embedding.GenerateVectorsWithModel
+	historicalCaseMigrationTarget   = embedding.MigrationTargetModel
+)
 
 func prepareHistoricalCaseInput(ctx context.Context, input CreateHistoricalCaseInput) (preparedHistoricalCaseInput, error) {
 	normalizedInput, err := normalizeAndValidateInput(input)
@@ -28,9 +38,115 @@ func prepareHistoricalCaseInput(ctx context.Context, input CreateHistoricalCaseI
 		return preparedHistoricalCaseInput{}, fmt.Errorf("generate embedding failed: %w", err)
 	}
 
-	return preparedHistoricalCaseInput{
+	return alignPreparedEmbedding(ctx, preparedHistoricalCaseInput{
 		normalizedInput: normalizedInput,
 		vector:          append([]float64{}, vector...),
 		modelName:       strings.TrimSpace(modelName),
-	}, nil
+	})
+}
+
+// alignPreparedEmbedding 让待写入向量与当前固定模型一致（如待审核记录生成于迁移之前），
+// 并在 embedding 迁移进行中为目标模型双写一份向量；双写失败只记录日志，由迁移任务收尾补齐。
+func alignPreparedEmbedding(ctx context.Context, prepared preparedHistoricalCaseInput) (preparedHistoricalCaseInput, error) {
+	embeddingText := BuildEmbeddingInput(prepared.normalizedInput)
+	activeModel := strings.TrimSpace(historicalCaseQueryEmbeddingModel())
+	if activeModel != "" && prepared.modelName != activeModel {
+		vector, modelName, err := generateCaseEmbeddingWithModel(ctx, embeddingText, activeModel)
+		if err != nil {
+			return preparedHistoricalCaseInput{}, fmt.Errorf("generate embedding with active model failed: %w", err)
+		}
+		prepared.vector = append([]float64{}, vector...)
+		prepared.modelName = strings.TrimSpace(modelName)
+	}
+
+	targetModel := strings.TrimSpace(historicalCaseMigrationTarget())
+	if targetModel != "" && targetModel != prepared.modelName {
+		vector, _, err := generateCaseEmbeddingWithModel(ctx, embeddingText, targetModel)
+		if err != nil {
+			log.Printf("[case_library] dual-write migration embedding failed: model=%s err=%v", targetModel, err)
+		} else {
+			prepared.stagedVector = append([]float64{}, vector...)
+			prepared.stagedModel = targetModel
+		}
+	}
+	return prepared, nil
+}
+
+// prepareHistoricalCaseInputs 批量校验并向量化，返回值与 inputs 一一对应。
+// 校验失败的条目不参与向量化；携带当前固定模型预计算向量的条目直接复用，
+// 其余条目合并为一次批量 embedding 调用（相同文本命中缓存），迁移进行中同样批量双写目标模型向量。
+func prepareHistoricalCaseInputs(ctx context.Context, inputs []CreateHistoricalCaseInput) ([]preparedHistoricalCaseInput, []error) {
+	prepared := make([]preparedHistoricalCaseInput, len(inputs))
+	errs := make([]error, len(inputs))
+	activeModel := strings.TrimSpace(historicalCaseQueryEmbeddingModel())
+	texts := make([]string, 0, len(inputs))
+	indexes := make([]int, 0, len(inputs))
+	for index, input := range inputs {
+		normalizedInput, err := normalizeAndValidateInput(input)
+		if err != nil {
+			errs[index] = err
+			continue
+		}
+		prepared[index].normalizedInput = normalizedInput
+		if reusable := reusableCaseEmbedding(input.Embedding, activeModel); reusable != nil {
+			prepared[index].vector = append([]float64{}, reusable.Vector...)
+			prepared[index].modelName = activeModel
+			continue
+		}
+		texts = append(texts, BuildEmbeddingInput(normalizedInput))
+		indexes = append(indexes, index)
+	}
+
+	if len(texts) > 0 {
+		vectors, modelName, err := generateCaseEmbeddings(ctx, texts)
+		if err != nil {
+			for _, index := range indexes {
+				errs[index] = fmt.Errorf("generate embedding failed: %w", err)
+			}
+		} else {
+			modelName = strings.TrimSpace(modelName)
+			for offset, index := range indexes {
+				prepared[index].vector = append([]float64{}, vectors[offset]...)
+				prepared[index].modelName = modelName
+			}
+		}
+	}
+
+	targetModel := strings.TrimSpace(historicalCaseMigrationTarget())
+	if targetModel == "" {
+		return prepared, errs
+	}
+	stagedTexts := make([]string, 0, len(inputs))
+	stagedIndexes := make([]int, 0, len(inputs))
+	for index := range prepared {
+		if errs[index] != nil || prepared[index].modelName == targetModel {
+			continue
+		}
+		stagedTexts = append(stagedTexts, BuildEmbeddingInput(prepared[index].normalizedInput))
+		stagedIndexes = append(stagedIndexes, index)
+	}
+	if len(stagedTexts) == 0 {
+		return prepared, errs
+	}
+	stagedVectors, _, err := generateCaseEmbeddingsWithModel(ctx, stagedTexts, targetModel)
+	if err != nil {
+		log.Printf("[case_library] dual-write migration embeddings failed: model=%s err=%v", targetModel, err)
+		return prepared, errs
+	}
+	for offset, index := range stagedIndexes {
+		prepared[index].stagedVector = append([]float64{}, stagedVectors[offset]...)
+		prepared[index].stagedModel = targetModel
+	}
+	return prepared, errs
+}
+
+// reusableCaseEmbedding 返回可直接复用的预计算向量：未固定模型、模型不一致或向量为空时返回 nil，由调用方重新向量化。
+func reusableCaseEmbedding(precomputed *CaseEmbedding, activeModel string) *CaseEmbedding {
+	if precomputed == nil || activeModel == "" || len(precomputed.Vector) == 0 {
+		return nil
+	}
+	if strings.TrimSpace(precomputed.Model) != activeModel {
+		return nil
+	}
+	return precomputed
 }
@@ -10,15 +10,19 @@ import (
 
 	model "antifraud/internal/modules/multi_agent/adapters/outbound/case_library/model"
 	"antifraud/internal/platform/database"
+	"antifraud/internal/platform/redaction"
+
+	"gorm.io/gorm"
 )
 
 type PendingReviewRecord = model.PendingReviewRecord
 type PendingReviewPreview = model.PendingReviewPreview
 type pendingReviewEntity = model.PendingReviewEntity
 
-// CreatePendingReview 将案件写入待审核表。
+// CreatePendingReview 将案件写入待审核表，提交来源取自 ctx（见 WithPendingReviewSource）。
+// 案件库跨用户共享，写入前会移除文本中的身份证号、银行卡号、电话、地址与姓名。
 func CreatePendingReview(ctx context.Context, userID string, input CreateHistoricalCaseInput) (PendingReviewRecord, error) {
-	prepared, err := prepareHistoricalCaseInput(ctx, input)
+	prepared, err := prepareHistoricalCaseInput(ctx, stripCaseInputPII(input))
 	if err != nil {
 		return PendingReviewRecord{}, err
 	}
@@ -38,9 +42,11 @@ func CreatePendingReview(ctx context.Context, userID string, input CreateHistori
 		Keywords:           encodeStringList(prepared.normalizedInput.Keywords),
 		ViolatedLaw:        prepared.normalizedInput.ViolatedLaw,
 		Suggestion:         prepared.normalizedInput.Suggestion,
+		Citations:          encodeCaseCitations(prepared.normalizedInput.Citations),
 		EmbeddingVector:    encodeFloatList(prepared.vector),
 		EmbeddingModel:     strings.TrimSpace(prepared.modelName),
 		EmbeddingDimension: len(prepared.vector),
+		Source:             pendingReviewSourceFromContext(ctx),
 	}
 
 	db, err := database.GetHistoricalCaseDB()
@@ -53,36 +59,89 @@ func CreatePendingReview(ctx context.Context, userID string, input CreateHistori
 	return pendingReviewRecordFromEntity(entity), nil
 }
 
+// stripCaseInputPII 对案件的自由文本字段做不可逆脱敏，编号占位符统一为类别标记；风险等级、诈骗类型与引用链接保持不变。
+func stripCaseInputPII(input CreateHistoricalCaseInput) CreateHistoricalCaseInput {
+	originalEmbeddingText := ""
+	if input.Embedding != nil {
+		originalEmbeddingText = BuildEmbeddingInput(input)
+	}
+	input.Title = redaction.Strip(input.Title)
+	input.TargetGroup = redaction.Strip(input.TargetGroup)
+	input.CaseDescription = redaction.Strip(input.CaseDescription)
+	input.ViolatedLaw = redaction.Strip(input.ViolatedLaw)
+	input.Suggestion = redaction.Strip(input.Suggestion)
+	input.TypicalScripts = stripStringList(input.TypicalScripts)
+	input.Keywords = stripStringList(input.Keywords)
+	// 脱敏改写了向量化文本时，预计算向量已不对应入库内容，丢弃后重新向量化。
+	if input.Embedding != nil && BuildEmbeddingInput(input) != originalEmbeddingText {
+		input.Embedding = nil
+	}
+	return input
+}
+
+func stripStringList(items []string) []string {
+	if items == nil {
+		return nil
+	}
+	stripped := make([]string, len(items))
+	for i, item := range items {
+		stripped[i] = redaction.Strip(item)
+	}
+	return stripped
+}
+
 // APPEND_MARKER
 
-// ListPendingReviewPreviews 返回所有待审核案件预览。
-func ListPendingReviewPreviews() ([]PendingReviewPreview, error) {
+// ListPendingReviewPreviews 按筛选条件返回待审核案件预览，最新提交的在前。
+func ListPendingReviewPreviews(filter PendingReviewFilter) ([]PendingReviewPreview, error) {
 	db, err := database.GetHistoricalCaseDB()
 	if err != nil {
 		return nil, err
 	}
 
+	now := time.Now()
+	query := db.Select("record_id", "user_id", "title", "target_group", "risk_level", "scam_type", "violated_law", "source", "claimed_by", "claimed_at", "created_at")
+	if scamType := strings.TrimSpace(filter.ScamType); scamType != "" {
+		query = query.Where("scam_type = ?", scamType)
+	}
+	if source := strings.TrimSpace(filter.Source); source != "" {
+		query = query.Where("source = ?", normalizePendingReviewSource(source))
+	}
+	claimCutoff := now.Add(-pendingReviewClaimTTL)
+	switch claimedBy := strings.TrimSpace(filter.ClaimedBy); claimedBy {
+	case "":
+	case "none":
+		query = query.Where("(claimed_by = '' OR claimed_at IS NULL OR claimed_at < ?)", claimCutoff)
+	default:
+		query = query.Where("claimed_by = ? AND claimed_at >= ?", claimedBy, claimCutoff)
+	}
+	if filter.OlderThan > 0 {
+		query = query.Where("created_at <= ?", now.Add(-filter.OlderThan))
+	}
+	if filter.NewerThan > 0 {
+		query = query.Where("created_at >= ?", now.Add(-filter.NewerThan))
+	}
+
 	var rows []pendingReviewEntity
-	if err := db.Select("record_id", "title", "target_group", "risk_level", "scam_type", "violated_law", "created_at").
-		Order("created_at desc").
-		Find(&rows).Error; err != nil {
+	if err := query.Order("created_at desc").Find(&rows).Error; err != nil {
 		return nil, fmt.Errorf("query pending review previews failed: %w", err)
 	}
 
 	previews := make([]PendingReviewPreview, 0, len(rows))
 	for _, row := range rows {
-		normalizedRiskLevel := normalizeRiskLevel(row.RiskLevel)
-		if normalizedRiskLevel == "" {
-			normalizedRiskLevel = strings.TrimSpace(row.RiskLevel)
-		}
+		record := pendingReviewRecordFromEntity(row)
 		previews = append(previews, PendingReviewPreview{
-			RecordID:    strings.TrimSpace(row.RecordID),
-			Title:       strings.TrimSpace(row.Title),
-			TargetGroup: strings.TrimSpace(row.TargetGroup),
-			RiskLevel:   normalizedRiskLevel,
-			ScamType:    strings.TrimSpace(row.ScamType),
-			ViolatedLaw: strings.TrimSpace(row.ViolatedLaw),
-			CreatedAt:   row.CreatedAt,
+			RecordID:    record.RecordID,
+			UserID:      record.UserID,
+			Title:       record.Title,
+			TargetGroup: record.TargetGroup,
+			RiskLevel:   record.RiskLevel,
+			ScamType:    record.ScamType,
+			ViolatedLaw: record.ViolatedLaw,
+			Source:      record.Source,
+			ClaimedBy:   record.ClaimedBy,
+			ClaimedAt:   record.ClaimedAt,
+			CreatedAt:   record.CreatedAt,
 		})
 	}
 	return previews, nil
@@ -113,33 +172,48 @@ func GetPendingReviewByID(recordID string) (PendingReviewRecord, bool, error) {
 
 // APPEND_MARKER_2
 
-// RejectPendingReview 审核拒绝：删除待审核记录，不写入历史案件库。
-func RejectPendingReview(ctx context.Context, recordID string) error {
-	trimmed := strings.TrimSpace(recordID)
-	if trimmed == "" {
-		return fmt.Errorf("recordID is required")
+// RejectPendingReview 审核拒绝：删除待审核记录，不写入历史案件库；拒绝原因与案件标题记入审计日志。
+func RejectPendingReview(ctx context.Context, recordID string, reviewerID string, reason string) error {
+	entity, err := loadPendingReviewEntity(recordID)
+	if err != nil {
+		return err
+	}
+	reviewer := normalizeUserID(reviewerID)
+	if err := ensurePendingReviewActionAllowed(entity, reviewer, time.Now()); err != nil {
+		return err
 	}
 
 	db, err := database.GetHistoricalCaseDB()
 	if err != nil {
 		return err
 	}
-
-	result := db.WithContext(ctx).Where("record_id = ?", trimmed).Delete(&pendingReviewEntity{})
-	if result.Error != nil {
-		return fmt.Errorf("delete pending review failed: %w", result.Error)
-	}
-	if result.RowsAffected == 0 {
-		return fmt.Errorf("pending review case not found or already processed")
-	}
-	return nil
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("record_id = ?", entity.RecordID).Delete(&pendingReviewEntity{})
+		if result.Error != nil {
+			return fmt.Errorf("delete pending review failed: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return ErrPendingReviewNotFound
+		}
+		return writePendingReviewAudit(tx, &pendingReviewAuditEntity{
+			RecordID:   entity.RecordID,
+			Action:     PendingReviewActionReject,
+			ReviewerID: reviewer,
+			Title:      entity.Title,
+			Reason:     reason,
+		})
+	})
 }
 
-// ApprovePendingReview 审核通过：读取待审核记录 → 直接写入历史案件库 → 删除待审核记录。
-func ApprovePendingReview(ctx context.Context, recordID string) (HistoricalCaseRecord, error) {
-	trimmed := strings.TrimSpace(recordID)
-	if trimmed == "" {
-		return HistoricalCaseRecord{}, fmt.Errorf("recordID is required")
+// ApprovePendingReview 审核通过：读取待审核记录 → 直接写入历史案件库 → 删除待审核记录并记录审计日志。
+func ApprovePendingReview(ctx context.Context, recordID string, reviewerID string, comment string) (HistoricalCaseRecord, error) {
+	entity, err := loadPendingReviewEntity(recordID)
+	if err != nil {
+		return HistoricalCaseRecord{}, err
+	}
+	reviewer := normalizeUserID(reviewerID)
+	if err := ensurePendingReviewActionAllowed(entity, reviewer, time.Now()); err != nil {
+		return HistoricalCaseRecord{}, err
 	}
 
 	db, err := database.GetHistoricalCaseDB()
@@ -147,15 +221,6 @@ func ApprovePendingReview(ctx context.Context, recordID string) (HistoricalCaseR
 		return HistoricalCaseRecord{}, err
 	}
 
-	var entity pendingReviewEntity
-	query := db.Where("record_id = ?", trimmed).Limit(1).Find(&entity)
-	if query.Error != nil {
-		return HistoricalCaseRecord{}, fmt.Errorf("query pending review failed: %w", query.Error)
-	}
-	if query.RowsAffected == 0 {
-		return HistoricalCaseRecord{}, fmt.Errorf("pending review case not found or already processed")
-	}
-
 	vector := decodeFloatList(entity.EmbeddingVector)
 	if len(vector) == 0 {
 		return HistoricalCaseRecord{}, fmt.Errorf("pending review record missing embedding vector")
@@ -164,26 +229,34 @@ func ApprovePendingReview(ctx context.Context, recordID string) (HistoricalCaseR
 		return HistoricalCaseRecord{}, fmt.Errorf("pending review embedding dimension mismatch")
 	}
 
-	record, createErr := insertHistoricalCasePrepared(entity.UserID, preparedHistoricalCaseInput{
-		normalizedInput: CreateHistoricalCaseInput{
-			Title:           strings.TrimSpace(entity.Title),
-			TargetGroup:     strings.TrimSpace(entity.TargetGroup),
-			RiskLevel:       strings.TrimSpace(entity.RiskLevel),
-			ScamType:        strings.TrimSpace(entity.ScamType),
-			CaseDescription: strings.TrimSpace(entity.CaseDescription),
-			TypicalScripts:  decodeStringList(entity.TypicalScripts),
-			Keywords:        decodeStringList(entity.Keywords),
-			ViolatedLaw:     strings.TrimSpace(entity.ViolatedLaw),
-			Suggestion:      strings.TrimSpace(entity.Suggestion),
-		},
-		vector:    vector,
-		modelName: strings.TrimSpace(entity.EmbeddingModel),
+	prepared, err := alignPreparedEmbedding(ctx, preparedHistoricalCaseInput{
+		normalizedInput: contentFromPendingReviewEntity(entity),
+		vector:          vector,
+		modelName:       strings.TrimSpace(entity.EmbeddingModel),
 	})
+	if err != nil {
+		return HistoricalCaseRecord{}, fmt.Errorf("approve pending review failed: %w", err)
+	}
+
+	record, createErr := insertHistoricalCasePrepared(entity.UserID, prepared)
 	if createErr != nil {
 		return HistoricalCaseRecord{}, fmt.Errorf("approve and create historical case failed: %w", createErr)
 	}
 
-	if err := db.Where("record_id = ?", trimmed).Delete(&pendingReviewEntity{}).Error; err != nil {
+	err = db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("record_id = ?", entity.RecordID).Delete(&pendingReviewEntity{}).Error; err != nil {
+			return err
+		}
+		return writePendingReviewAudit(tx, &pendingReviewAuditEntity{
+			RecordID:   entity.RecordID,
+			Action:     PendingReviewActionApprove,
+			ReviewerID: reviewer,
+			Title:      entity.Title,
+			Comment:    comment,
+			CaseID:     record.CaseID,
+		})
+	})
+	if err != nil {
 		return record, fmt.Errorf("delete pending review record failed (case already created): %w", err)
 	}
 
@@ -204,7 +277,7 @@ func pendingReviewRecordFromEntity(entity pendingReviewEntity) PendingReviewReco
 		normalizedRiskLevel = strings.TrimSpace(entity.RiskLevel)
 	}
 
-	return PendingReviewRecord{
+	record := PendingReviewRecord{
 		RecordID:        strings.TrimSpace(entity.RecordID),
 		UserID:          strings.TrimSpace(entity.UserID),
 		Title:           strings.TrimSpace(entity.Title),
@@ -216,7 +289,16 @@ func pendingReviewRecordFromEntity(entity pendingReviewEntity) PendingReviewReco
 		Keywords:        decodeStringList(entity.Keywords),
 		ViolatedLaw:     strings.TrimSpace(entity.ViolatedLaw),
 		Suggestion:      strings.TrimSpace(entity.Suggestion),
+		Citations:       decodeCaseCitations(entity.Citations),
+		Source:          normalizePendingReviewSource(entity.Source),
+		UpdatedBy:       strings.TrimSpace(entity.UpdatedBy),
 		CreatedAt:       entity.CreatedAt,
 		UpdatedAt:       entity.UpdatedAt,
 	}
+	// 过期认领视为未认领，避免前端展示已失效的占用状态。
+	if pendingReviewClaimActive(entity, time.Now()) {
+		record.ClaimedBy = strings.TrimSpace(entity.ClaimedBy)
+		record.ClaimedAt = *entity.ClaimedAt
+	}
+	return record
 }
@@ -0,0 +1,419 @@
+package case_library
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	model "antifraud/internal/modules/multi_agent/adapters/outbound/case_library/model"
+	"antifraud/internal/platform/database"
+
+	"gorm.io/gorm"
+)
+
+const (
+	PendingReviewSourceUser  = "user"
+	PendingReviewSourceAgent = "agent"
+)
+
+const (
+	PendingReviewActionClaim   = "claim"
+	PendingReviewActionRelease = "release"
+	PendingReviewActionEdit    = "edit"
+	PendingReviewActionComment = "comment"
+	PendingReviewActionApprove = "approve"
+	PendingReviewActionReject  = "reject"
+)
+
+// pendingReviewClaimTTL 是认领的有效期，过期后其他审核员可以直接接手，避免案件被长期占用。
+const pendingReviewClaimTTL = 30 * time.Minute
+
+const maxPendingReviewAuditLogs = 500
+
+var (
+	// ErrPendingReviewNotFound 表示待审核案件不存在或已被通过/拒绝。
+	ErrPendingReviewNotFound = errors.New("pending review case not found or already processed")
+	// ErrPendingReviewClaimedByOther 表示案件已被其他审核员认领且认领尚未过期。
+	ErrPendingReviewClaimedByOther = errors.New("pending review case is claimed by another reviewer")
+)
+
+type pendingReviewAuditEntity = model.PendingReviewAuditEntity
+
+type pendingReviewSourceContextKey struct{}
+
+// PendingReviewFilter 是待审核队列的筛选条件，零值表示不过滤。
+// ClaimedBy 为 "none" 时只返回未认领（含认领已过期）的案件。
+type PendingReviewFilter struct {
+	ScamType  string
+	Source    string
+	ClaimedBy string
+	OlderThan time.Duration
+	NewerThan time.Duration
+}
+
+// PendingReviewAuditFilter 是审核审计日志的查询条件。
+type PendingReviewAuditFilter struct {
+	RecordID   string
+	ReviewerID string
+	Action     string
+	Limit      int
+}
+
+// PendingReviewAuditLog 表示一条审核动作记录；Changes 仅在 edit 动作时非空。
+type PendingReviewAuditLog struct {
+	ID         uint
+	RecordID   string
+	Action     string
+	ReviewerID string
+	Title      string
+	Reason     string
+	Comment    string
+	Changes    []HistoricalCaseFieldChange
+	CaseID     string
+	CreatedAt  time.Time
+}
+
+// WithPendingReviewSource 在 ctx 上标记待审核案件的提交来源，未标记时按 user 处理。
+func WithPendingReviewSource(ctx context.Context, source string) context.Context {
+	return context.WithValue(ctx, pendingReviewSourceContextKey{}, normalizePendingReviewSource(source))
+}
+
+func pendingReviewSourceFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return PendingReviewSourceUser
+	}
+	source, _ := ctx.Value(pendingReviewSourceContextKey{}).(string)
+	return normalizePendingReviewSource(source)
+}
+
+func normalizePendingReviewSource(source string) string {
+	if strings.EqualFold(strings.TrimSpace(source), PendingReviewSourceAgent) {
+		return PendingReviewSourceAgent
+	}
+	return PendingReviewSourceUser
+}
+
+// ClaimPendingReview 认领待审核案件；本人重复认领会刷新认领时间。
+func ClaimPendingReview(ctx context.Context, recordID string, reviewerID string) (PendingReviewRecord, error) {
+	entity, err := loadPendingReviewEntity(recordID)
+	if err != nil {
+		return PendingReviewRecord{}, err
+	}
+	reviewer := normalizeUserID(reviewerID)
+	now := time.Now()
+	if err := ensurePendingReviewActionAllowed(entity, reviewer, now); err != nil {
+		return PendingReviewRecord{}, err
+	}
+
+	db, err := database.GetHistoricalCaseDB()
+	if err != nil {
+		return PendingReviewRecord{}, err
+	}
+	err = db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 条件更新防止两个审核员同时认领同一案件。
+		result := tx.Model(&pendingReviewEntity{}).
+			Where("record_id = ? AND (claimed_by = '' OR claimed_by = ? OR claimed_at IS NULL OR claimed_at < ?)", entity.RecordID, reviewer, now.Add(-pendingReviewClaimTTL)).
+			Updates(map[string]interface{}{"claimed_by": reviewer, "claimed_at": now})
+		if result.Error != nil {
+			return fmt.Errorf("claim pending review failed: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return ErrPendingReviewClaimedByOther
+		}
+		return writePendingReviewAudit(tx, &pendingReviewAuditEntity{
+			RecordID:   entity.RecordID,
+			Action:     PendingReviewActionClaim,
+			ReviewerID: reviewer,
+			Title:      entity.Title,
+		})
+	})
+	if err != nil {
+		return PendingReviewRecord{}, err
+	}
+	entity.ClaimedBy = reviewer
+	entity.ClaimedAt = &now
+	return pendingReviewRecordFromEntity(entity), nil
+}
+
+// ReleasePendingReview 释放本人持有的认领；案件未被认领时直接返回当前记录。
+func ReleasePendingReview(ctx context.Context, recordID string, reviewerID string) (PendingReviewRecord, error) {
+	entity, err := loadPendingReviewEntity(recordID)
+	if err != nil {
+		return PendingReviewRecord{}, err
+	}
+	reviewer := normalizeUserID(reviewerID)
+	now := time.Now()
+	if !pendingReviewClaimActive(entity, now) {
+		return pendingReviewRecordFromEntity(entity), nil
+	}
+	if err := ensurePendingReviewActionAllowed(entity, reviewer, now); err != nil {
+		return PendingReviewRecord{}, err
+	}
+
+	db, err := database.GetHistoricalCaseDB()
+	if err != nil {
+		return PendingReviewRecord{}, err
+	}
+	err = db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&pendingReviewEntity{}).
+			Where("record_id = ? AND claimed_by = ?", entity.RecordID, reviewer).
+			Updates(map[string]interface{}{"claimed_by": "", "claimed_at": nil})
+		if result.Error != nil {
+			return fmt.Errorf("release pending review failed: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return ErrPendingReviewClaimedByOther
+		}
+		return writePendingReviewAudit(tx, &pendingReviewAuditEntity{
+			RecordID:   entity.RecordID,
+			Action:     PendingReviewActionRelease,
+			ReviewerID: reviewer,
+			Title:      entity.Title,
+		})
+	})
+	if err != nil {
+		return PendingReviewRecord{}, err
+	}
+	entity.ClaimedBy = ""
+	entity.ClaimedAt = nil
+	return pendingReviewRecordFromEntity(entity), nil
+}
+
+// UpdatePendingReview 在入库前修改待审核案件字段，input 中为 nil 的字段保持不变，修改后的文本同样移除个人信息。
+// 参与 embedding 的字段变化时重新向量化并与历史案件库查重；逐字段差异写入审计日志。
+func UpdatePendingReview(ctx context.Context, recordID string, reviewerID string, input UpdateHistoricalCaseInput) (PendingReviewRecord, error) {
+	entity, err := loadPendingReviewEntity(recordID)
+	if err != nil {
+		return PendingReviewRecord{}, err
+	}
+	reviewer := normalizeUserID(reviewerID)
+	if err := ensurePendingReviewActionAllowed(entity, reviewer, time.Now()); err != nil {
+		return PendingReviewRecord{}, err
+	}
+
+	previous := contentFromPendingReviewEntity(entity)
+	normalized, err := normalizeAndValidateInput(stripCaseInputPII(mergeHistoricalCaseInput(previous, input)))
+	if err != nil {
+		return PendingReviewRecord{}, err
+	}
+	changes := diffHistoricalCaseContent(previous, normalized)
+	if len(changes) == 0 {
+		return pendingReviewRecordFromEntity(entity), nil
+	}
+
+	updates := map[string]interface{}{
+		"title":            normalized.Title,
+		"target_group":     normalized.TargetGroup,
+		"risk_level":       normalized.RiskLevel,
+		"scam_type":        normalized.ScamType,
+		"case_description": normalized.CaseDescription,
+		"typical_scripts":  encodeStringList(normalized.TypicalScripts),
+		"keywords":         encodeStringList(normalized.Keywords),
+		"violated_law":     normalized.ViolatedLaw,
+		"suggestion":       normalized.Suggestion,
+		"updated_by":       reviewer,
+	}
+	if BuildEmbeddingInput(previous) != BuildEmbeddingInput(normalized) {
+		prepared, err := prepareHistoricalCaseInput(ctx, normalized)
+		if err != nil {
+			return PendingReviewRecord{}, err
+		}
+		if duplicateErr := detectDuplicateHistoricalCase(prepared.vector); duplicateErr != nil {
+			return PendingReviewRecord{}, duplicateErr
+		}
+		updates["embedding_vector"] = encodeFloatList(prepared.vector)
+		updates["embedding_model"] = strings.TrimSpace(prepared.modelName)
+		updates["embedding_dimension"] = len(prepared.vector)
+	}
+	encodedChanges, err := json.Marshal(changes)
+	if err != nil {
+		return PendingReviewRecord{}, fmt.Errorf("encode pending review changes failed: %w", err)
+	}
+
+	db, err := database.GetHistoricalCaseDB()
+	if err != nil {
+		return PendingReviewRecord{}, err
+	}
+	err = db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&pendingReviewEntity{}).Where("record_id = ?", entity.RecordID).Updates(updates)
+		if result.Error != nil {
+			return fmt.Errorf("update pending review failed: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return ErrPendingReviewNotFound
+		}
+		return writePendingReviewAudit(tx, &pendingReviewAuditEntity{
+			RecordID:   entity.RecordID,
+			Action:     PendingReviewActionEdit,
+			ReviewerID: reviewer,
+			Title:      normalized.Title,
+			Changes:    string(encodedChanges),
+		})
+	})
+	if err != nil {
+		return PendingReviewRecord{}, err
+	}
+
+	updated, err := loadPendingReviewEntity(entity.RecordID)
+	if err != nil {
+		return PendingReviewRecord{}, err
+	}
+	return pendingReviewRecordFromEntity(updated), nil
+}
+
+// AddPendingReviewComment 为待审核案件追加审核意见，不要求认领。
+func AddPendingReviewComment(ctx context.Context, recordID string, reviewerID string, comment string) (PendingReviewAuditLog, error) {
+	trimmedComment := strings.TrimSpace(comment)
+	if trimmedComment == "" {
+		return PendingReviewAuditLog{}, newValidationError("comment is required")
+	}
+	entity, err := loadPendingReviewEntity(recordID)
+	if err != nil {
+		return PendingReviewAuditLog{}, err
+	}
+
+	db, err := database.GetHistoricalCaseDB()
+	if err != nil {
+		return PendingReviewAuditLog{}, err
+	}
+	row := pendingReviewAuditEntity{
+		RecordID:   entity.RecordID,
+		Action:     PendingReviewActionComment,
+		ReviewerID: normalizeUserID(reviewerID),
+		Title:      entity.Title,
+		Comment:    trimmedComment,
+	}
+	if err := writePendingReviewAudit(db.WithContext(ctx), &row); err != nil {
+		return PendingReviewAuditLog{}, err
+	}
+	return pendingReviewAuditLogFromEntity(row), nil
+}
+
+// ListPendingReviewAuditLogs 按时间倒序返回审核审计日志；案件通过或拒绝后其日志仍可查询。
+func ListPendingReviewAuditLogs(filter PendingReviewAuditFilter) ([]PendingReviewAuditLog, error) {
+	db, err := database.GetHistoricalCaseDB()
+	if err != nil {
+		return nil, err
+	}
+	limit := filter.Limit
+	if limit <= 0 || limit > maxPendingReviewAuditLogs {
+		limit = maxPendingReviewAuditLogs
+	}
+
+	query := db.Model(&pendingReviewAuditEntity{})
+	if recordID := strings.TrimSpace(filter.RecordID); recordID != "" {
+		query = query.Where("record_id = ?", recordID)
+	}
+	if reviewerID := strings.TrimSpace(filter.ReviewerID); reviewerID != "" {
+		query = query.Where("reviewer_id = ?", reviewerID)
+	}
+	if action := strings.TrimSpace(filter.Action); action != "" {
+		query = query.Where("action = ?", action)
+	}
+
+	var rows []pendingReviewAuditEntity
+	if err := query.Order("created_at desc").Order("id desc").Limit(limit).Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("query pending review audit logs failed: %w", err)
+	}
+	logs := make([]PendingReviewAuditLog, 0, len(rows))
+	for _, row := range rows {
+		logs = append(logs, pendingReviewAuditLogFromEntity(row))
+	}
+	return logs, nil
+}
+
+// IsPendingReviewAuditAction 判断 action 是否为已知的审核动作。
+func IsPendingReviewAuditAction(action string) bool {
+	switch strings.TrimSpace(action) {
+	case PendingReviewActionClaim, PendingReviewActionRelease, PendingReviewActionEdit,
+		PendingReviewActionComment, PendingReviewActionApprove, PendingReviewActionReject:
+		return true
+	default:
+		return false
+	}
+}
+
+func loadPendingReviewEntity(recordID string) (pendingReviewEntity, error) {
+	trimmed := strings.TrimSpace(recordID)
+	if trimmed == "" {
+		return pendingReviewEntity{}, fmt.Errorf("recordID is required")
+	}
+	db, err := database.GetHistoricalCaseDB()
+	if err != nil {
+		return pendingReviewEntity{}, err
+	}
+	var entity pendingReviewEntity
+	query := db.Where("record_id = ?", trimmed).Limit(1).Find(&entity)
+	if query.Error != nil {
+		return pendingReviewEntity{}, fmt.Errorf("query pending review failed: %w", query.Error)
+	}
+	if query.RowsAffected == 0 {
+		return pendingReviewEntity{}, ErrPendingReviewNotFound
+	}
+	return entity, nil
+}
+
+func pendingReviewClaimActive(entity pendingReviewEntity, now time.Time) bool {
+	if strings.TrimSpace(entity.ClaimedBy) == "" || entity.ClaimedAt == nil {
+		return false
+	}
+	return now.Sub(*entity.ClaimedAt) < pendingReviewClaimTTL
+}
+
+// ensurePendingReviewActionAllowed 校验审核员是否可以处理案件：未认领、认领已过期或本人认领时允许。
+func ensurePendingReviewActionAllowed(entity pendingReviewEntity, reviewerID string, now time.Time) error {
+	if !pendingReviewClaimActive(entity, now) {
+		return nil
+	}
+	if strings.TrimSpace(entity.ClaimedBy) == strings.TrimSpace(reviewerID) {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrPendingReviewClaimedByOther, strings.TrimSpace(entity.ClaimedBy))
+}
+
+func writePendingReviewAudit(tx *gorm.DB, row *pendingReviewAuditEntity) error {
+	row.Title = strings.TrimSpace(row.Title)
+	row.Reason = strings.TrimSpace(row.Reason)
+	row.Comment = strings.TrimSpace(row.Comment)
+	if err := tx.Create(row).Error; err != nil {
+		return fmt.Errorf("write pending review audit log failed: %w", err)
+	}
+	return nil
+}
+
+func contentFromPendingReviewEntity(entity pendingReviewEntity) CreateHistoricalCaseInput {
+	return CreateHistoricalCaseInput{
+		Title:           strings.TrimSpace(entity.Title),
+		TargetGroup:     strings.TrimSpace(entity.TargetGroup),
+		RiskLevel:       strings.TrimSpace(entity.RiskLevel),
+		ScamType:        strings.TrimSpace(entity.ScamType),
+		CaseDescription: strings.TrimSpace(entity.CaseDescription),
+		TypicalScripts:  decodeStringList(entity.TypicalScripts),
+		Keywords:        decodeStringList(entity.Keywords),
+		ViolatedLaw:     strings.TrimSpace(entity.ViolatedLaw),
+		Suggestion:      strings.TrimSpace(entity.Suggestion),
+		Citations:       decodeCaseCitations(entity.Citations),
+	}
+}
+
+func pendingReviewAuditLogFromEntity(row pendingReviewAuditEntity) PendingReviewAuditLog {
+	var changes []HistoricalCaseFieldChange
+	if strings.TrimSpace(row.Changes) != "" {
+		_ = json.Unmarshal([]byte(row.Changes), &changes)
+	}
+	return PendingReviewAuditLog{
+		ID:         row.ID,
+		RecordID:   strings.TrimSpace(row.RecordID),
+		Action:     strings.TrimSpace(row.Action),
+		ReviewerID: strings.TrimSpace(row.ReviewerID),
+		Title:      strings.TrimSpace(row.Title),
+		Reason:     strings.TrimSpace(row.Reason),
+		Comment:    strings.TrimSpace(row.Comment),
+		Changes:    changes,
+		CaseID:     strings.TrimSpace(row.CaseID),
+		CreatedAt:  row.CreatedAt,
+	}
+}
@@ -0,0 +1,446 @@
+package case_library
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	model "antifraud/internal/modules/multi_agent/adapters/outbound/case_library/model"
+	"antifraud/internal/platform/database"
+
+	"gorm.io/gorm"
+)
+
+const (
+	HistoricalCaseChangeUpdate   = "update"
+	HistoricalCaseChangeRollback = "rollback"
+	HistoricalCaseChangeMerge    = "merge"
+)
+
+// ErrHistoricalCaseRevisionConflict 表示编辑基于的版本已被其他人修改。
+var ErrHistoricalCaseRevisionConflict = errors.New("historical case has been modified by another editor")
+
+type UpdateHistoricalCaseInput = model.UpdateHistoricalCaseInput
+type HistoricalCaseRevision = model.HistoricalCaseRevision
+type historicalCaseRevisionEntity = model.HistoricalCaseRevisionEntity
+
+// HistoricalCaseFieldChange 表示两个版本之间单个字段的差异。
+// 列表字段额外给出逐项增删，Before/After 为顿号拼接后的展示文本。
+type HistoricalCaseFieldChange struct {
+	Field   string
+	Before  string
+	After   string
+	Added   []string
+	Removed []string
+}
+
+// UpdateHistoricalCase 编辑历史案件并保留旧版本，case_id 与 created_at 保持不变；编辑与回滚写入的文本同样移除个人信息。
+// expectedRevision 大于 0 时要求当前版本号一致，否则返回 ErrHistoricalCaseRevisionConflict。
+// 只有参与 embedding 的字段（标题、诈骗类型、描述、关键词）变化时才重新向量化并查重；内容无变化时不产生新版本。
+func UpdateHistoricalCase(ctx context.Context, editorID string, caseID string, expectedRevision int, input UpdateHistoricalCaseInput) (HistoricalCaseRecord, bool, error) {
+	current, found, err := loadHistoricalCaseEntity(caseID)
+	if err != nil || !found {
+		return HistoricalCaseRecord{}, found, err
+	}
+	if expectedRevision > 0 && expectedRevision != normalizeHistoricalCaseRevision(current.Revision) {
+		return HistoricalCaseRecord{}, true, ErrHistoricalCaseRevisionConflict
+	}
+	merged := mergeHistoricalCaseInput(contentFromEntity(current), input)
+	record, err := applyHistoricalCaseChange(ctx, editorID, current, merged, HistoricalCaseChangeUpdate, 0)
+	return record, true, err
+}
+
+// RollbackHistoricalCase 把历史案件恢复为指定旧版本的内容；回滚本身也会产生一个新版本，可再次回滚。
+func RollbackHistoricalCase(ctx context.Context, editorID string, caseID string, revision int) (HistoricalCaseRecord, bool, error) {
+	current, found, err := loadHistoricalCaseEntity(caseID)
+	if err != nil || !found {
+		return HistoricalCaseRecord{}, found, err
+	}
+	if revision >= normalizeHistoricalCaseRevision(current.Revision) {
+		return HistoricalCaseRecord{}, true, newValidationError("revision %d is not an earlier revision of case %s", revision, current.CaseID)
+	}
+	target, found, err := loadHistoricalCaseRevisionEntity(current.CaseID, revision)
+	if err != nil {
+		return HistoricalCaseRecord{}, true, err
+	}
+	if !found {
+		return HistoricalCaseRecord{}, true, newValidationError("revision %d not found", revision)
+	}
+	record, err := applyHistoricalCaseChange(ctx, editorID, current, contentFromRevisionEntity(target), HistoricalCaseChangeRollback, revision)
+	return record, true, err
+}
+
+// ListHistoricalCaseRevisions 返回案件的全部版本（含当前版本），按版本号倒序。
+func ListHistoricalCaseRevisions(caseID string) ([]HistoricalCaseRevision, bool, error) {
+	current, found, err := loadHistoricalCaseEntity(caseID)
+	if err != nil || !found {
+		return nil, found, err
+	}
+	db, err := database.GetHistoricalCaseDB()
+	if err != nil {
+		return nil, true, err
+	}
+	rows := make([]historicalCaseRevisionEntity, 0)
+	if err := db.Where("case_id = ?", current.CaseID).Order("revision desc").Find(&rows).Error; err != nil {
+		return nil, true, fmt.Errorf("query historical case revisions failed: %w", err)
+	}
+
+	revisions := make([]HistoricalCaseRevision, 0, len(rows)+1)
+	revisions = append(revisions, currentRevisionFromEntity(current))
+	for _, row := range rows {
+		revisions = append(revisions, revisionFromEntity(row))
+	}
+	return revisions, true, nil
+}
+
+// GetHistoricalCaseRevision 返回指定版本；revision 为当前版本号时返回当前内容。
+func GetHistoricalCaseRevision(caseID string, revision int) (HistoricalCaseRevision, bool, error) {
+	current, found, err := loadHistoricalCaseEntity(caseID)
+	if err != nil || !found {
+		return HistoricalCaseRevision{}, false, err
+	}
+	if revision == normalizeHistoricalCaseRevision(current.Revision) {
+		return currentRevisionFromEntity(current), true, nil
+	}
+	row, found, err := loadHistoricalCaseRevisionEntity(current.CaseID, revision)
+	if err != nil || !found {
+		return HistoricalCaseRevision{}, false, err
+	}
+	return revisionFromEntity(row), true, nil
+}
+
+// DiffHistoricalCaseRevisions 比较两个版本的内容差异，toRevision 不大于 0 时与当前版本比较。
+func DiffHistoricalCaseRevisions(caseID string, fromRevision int, toRevision int) (HistoricalCaseRevision, HistoricalCaseRevision, []HistoricalCaseFieldChange, bool, error) {
+	from, found, err := GetHistoricalCaseRevision(caseID, fromRevision)
+	if err != nil || !found {
+		return HistoricalCaseRevision{}, HistoricalCaseRevision{}, nil, found, err
+	}
+	var to HistoricalCaseRevision
+	if toRevision <= 0 {
+		revisions, found, err := ListHistoricalCaseRevisions(caseID)
+		if err != nil || !found {
+			return HistoricalCaseRevision{}, HistoricalCaseRevision{}, nil, found, err
+		}
+		to = revisions[0]
+	} else {
+		to, found, err = GetHistoricalCaseRevision(caseID, toRevision)
+		if err != nil || !found {
+			return HistoricalCaseRevision{}, HistoricalCaseRevision{}, nil, found, err
+		}
+	}
+	return from, to, diffHistoricalCaseContent(from.Content, to.Content), true, nil
+}
+
+func applyHistoricalCaseChange(ctx context.Context, editorID string, current historicalCaseEntity, content CreateHistoricalCaseInput, changeType string, restoredFrom int) (HistoricalCaseRecord, error) {
+	normalized, err := normalizeAndValidateInput(stripCaseInputPII(content))
+	if err != nil {
+		return HistoricalCaseRecord{}, err
+	}
+	previous := contentFromEntity(current)
+	if len(diffHistoricalCaseContent(previous, normalized)) == 0 {
+		return recordFromEntity(current), nil
+	}
+
+	editor := normalizeUserID(editorID)
+	now := time.Now()
+	updates := map[string]interface{}{
+		"title":            normalized.Title,
+		"target_group":     normalized.TargetGroup,
+		"risk_level":       normalized.RiskLevel,
+		"scam_type":        normalized.ScamType,
+		"case_description": normalized.CaseDescription,
+		"typical_scripts":  encodeStringList(normalized.TypicalScripts),
+		"keywords":         encodeStringList(normalized.Keywords),
+		"violated_law":     normalized.ViolatedLaw,
+		"suggestion":       normalized.Suggestion,
+		"revision":         normalizeHistoricalCaseRevision(current.Revision) + 1,
+		"updated_by":       editor,
+		"updated_at":       now,
+	}
+	if BuildEmbeddingInput(previous) != BuildEmbeddingInput(normalized) {
+		prepared, err := prepareHistoricalCaseInput(ctx, normalized)
+		if err != nil {
+			return HistoricalCaseRecord{}, err
+		}
+		if duplicateErr := detectDuplicateHistoricalCaseExcluding(prepared.vector, current.CaseID); duplicateErr != nil {
+			return HistoricalCaseRecord{}, duplicateErr
+		}
+		setPreparedEmbeddingUpdates(updates, prepared)
+	}
+
+	db, err := database.GetHistoricalCaseDB()
+	if err != nil {
+		return HistoricalCaseRecord{}, err
+	}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		snapshot := revisionEntityFromCurrent(current)
+		snapshot.ReplacedBy = editor
+		snapshot.ChangeType = changeType
+		snapshot.RestoredFrom = restoredFrom
+		snapshot.CreatedAt = now
+		result := tx.Model(&historicalCaseEntity{}).
+			Where("case_id = ? AND revision = ?", current.CaseID, current.Revision).
+			Updates(updates)
+		if result.Error != nil {
+			return fmt.Errorf("update historical case failed: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return ErrHistoricalCaseRevisionConflict
+		}
+		if err := tx.Create(&snapshot).Error; err != nil {
+			return fmt.Errorf("save historical case revision failed: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return HistoricalCaseRecord{}, err
+	}
+
+	updated, found, err := loadHistoricalCaseEntity(current.CaseID)
+	if err != nil {
+		return HistoricalCaseRecord{}, err
+	}
+	if !found {
+		return HistoricalCaseRecord{}, fmt.Errorf("historical case %s disappeared after update", current.CaseID)
+	}
+	record := recordFromEntity(updated)
+	upsertHistoricalCaseVectorCache(record)
+	touchHistoricalCaseGraphCacheVersion()
+	return record, nil
+}
+
+// setPreparedEmbeddingUpdates 把重新生成的向量写入待更新列。
+func setPreparedEmbeddingUpdates(updates map[string]interface{}, prepared preparedHistoricalCaseInput) {
+	updates["embedding_vector"] = encodeFloatList(prepared.vector)
+	updates["embedding_model"] = strings.TrimSpace(prepared.modelName)
+	updates["embedding_dimension"] = len(prepared.vector)
+	// 旧的暂存向量对应旧文本，必须随正式向量一起替换或清空。
+	updates["staged_embedding_vector"] = ""
+	updates["staged_embedding_model"] = ""
+	updates["staged_embedding_dimension"] = 0
+	if len(prepared.stagedVector) > 0 && strings.TrimSpace(prepared.stagedModel) != "" {
+		updates["staged_embedding_vector"] = encodeFloatList(prepared.stagedVector)
+		updates["staged_embedding_model"] = strings.TrimSpace(prepared.stagedModel)
+		updates["staged_embedding_dimension"] = len(prepared.stagedVector)
+	}
+}
+
+// detectDuplicateHistoricalCaseExcluding 与 detectDuplicateHistoricalCase 相同，但忽略指定案件（通常是案件自身）的旧向量。
+func detectDuplicateHistoricalCaseExcluding(queryVector []float64, caseIDs ...string) error {
+	excluded := make(map[string]struct{}, len(caseIDs))
+	for _, caseID := range caseIDs {
+		excluded[strings.TrimSpace(caseID)] = struct{}{}
+	}
+	results, _, err := searchHistoricalCasesByVector(queryVector, len(excluded)+1)
+	if err != nil {
+		return fmt.Errorf("compare with historical case library failed: %w", err)
+	}
+	for _, item := range results {
+		if _, skip := excluded[strings.TrimSpace(item.CaseID)]; skip {
+			continue
+		}
+		if item.Similarity >= pendingReviewDuplicateThreshold {
+			return &DuplicateHistoricalCaseError{TopMatch: item}
+		}
+		return nil
+	}
+	return nil
+}
+
+func mergeHistoricalCaseInput(base CreateHistoricalCaseInput, patch UpdateHistoricalCaseInput) CreateHistoricalCaseInput {
+	merged := base
+	if patch.Title != nil {
+		merged.Title = *patch.Title
+	}
+	if patch.TargetGroup != nil {
+		merged.TargetGroup = *patch.TargetGroup
+	}
+	if patch.RiskLevel != nil {
+		merged.RiskLevel = *patch.RiskLevel
+	}
+	if patch.ScamType != nil {
+		merged.ScamType = *patch.ScamType
+	}
+	if patch.CaseDescription != nil {
+		merged.CaseDescription = *patch.CaseDescription
+	}
+	if patch.TypicalScripts != nil {
+		merged.TypicalScripts = append([]string{}, (*patch.TypicalScripts)...)
+	}
+	if patch.Keywords != nil {
+		merged.Keywords = append([]string{}, (*patch.Keywords)...)
+	}
+	if patch.ViolatedLaw != nil {
+		merged.ViolatedLaw = *patch.ViolatedLaw
+	}
+	if patch.Suggestion != nil {
+		merged.Suggestion = *patch.Suggestion
+	}
+	return merged
+}
+
+func diffHistoricalCaseContent(before CreateHistoricalCaseInput, after CreateHistoricalCaseInput) []HistoricalCaseFieldChange {
+	changes := make([]HistoricalCaseFieldChange, 0)
+	addScalar := func(field string, left string, right string) {
+		if strings.TrimSpace(left) != strings.TrimSpace(right) {
+			changes = append(changes, HistoricalCaseFieldChange{Field: field, Before: left, After: right})
+		}
+	}
+	addList := func(field string, left []string, right []string) {
+		added, removed := diffStringLists(left, right)
+		if len(added) == 0 && len(removed) == 0 && strings.Join(left, "\n") == strings.Join(right, "\n") {
+			return
+		}
+		changes = append(changes, HistoricalCaseFieldChange{
+			Field:   field,
+			Before:  strings.Join(left, "、"),
+			After:   strings.Join(right, "、"),
+			Added:   added,
+			Removed: removed,
+		})
+	}
+
+	addScalar("title", before.Title, after.Title)
+	addScalar("target_group", before.TargetGroup, after.TargetGroup)
+	addScalar("risk_level", before.RiskLevel, after.RiskLevel)
+	addScalar("scam_type", before.ScamType, after.ScamType)
+	addScalar("case_description", before.CaseDescription, after.CaseDescription)
+	addList("typical_scripts", before.TypicalScripts, after.TypicalScripts)
+	addList("keywords", before.Keywords, after.Keywords)
+	addScalar("violated_law", before.ViolatedLaw, after.ViolatedLaw)
+	addScalar("suggestion", before.Suggestion, after.Suggestion)
+	return changes
+}
+
+func diffStringLists(before []string, after []string) ([]string, []string) {
+	beforeSet := make(map[string]struct{}, len(before))
+	for _, item := range before {
+		beforeSet[item] = struct{}{}
+	}
+	afterSet := make(map[string]struct{}, len(after))
+	for _, item := range after {
+		afterSet[item] = struct{}{}
+	}
+	added := make([]string, 0)
+	for _, item := range after {
+		if _, ok := beforeSet[item]; !ok {
+			added = append(added, item)
+		}
+	}
+	removed := make([]string, 0)
+	for _, item := range before {
+		if _, ok := afterSet[item]; !ok {
+			removed = append(removed, item)
+		}
+	}
+	return added, removed
+}
+
+func loadHistoricalCaseEntity(caseID string) (historicalCaseEntity, bool, error) {
+	trimmedCaseID := strings.TrimSpace(caseID)
+	if trimmedCaseID == "" {
+		return historicalCaseEntity{}, false, nil
+	}
+	db, err := database.GetHistoricalCaseDB()
+	if err != nil {
+		return historicalCaseEntity{}, false, err
+	}
+	var entity historicalCaseEntity
+	query := db.Where("case_id = ?", trimmedCaseID).Limit(1).Find(&entity)
+	if query.Error != nil {
+		return historicalCaseEntity{}, false, fmt.Errorf("query historical case failed: %w", query.Error)
+	}
+	return entity, query.RowsAffected > 0, nil
+}
+
+func loadHistoricalCaseRevisionEntity(caseID string, revision int) (historicalCaseRevisionEntity, bool, error) {
+	db, err := database.GetHistoricalCaseDB()
+	if err != nil {
+		return historicalCaseRevisionEntity{}, false, err
+	}
+	var entity historicalCaseRevisionEntity
+	query := db.Where("case_id = ? AND revision = ?", strings.TrimSpace(caseID), revision).Limit(1).Find(&entity)
+	if query.Error != nil {
+		return historicalCaseRevisionEntity{}, false, fmt.Errorf("query historical case revision failed: %w", query.Error)
+	}
+	return entity, query.RowsAffected > 0, nil
+}
+
+func contentFromEntity(entity historicalCaseEntity) CreateHistoricalCaseInput {
+	record := recordFromEntity(entity)
+	return CreateHistoricalCaseInput{
+		Title:           record.Title,
+		TargetGroup:     record.TargetGroup,
+		RiskLevel:       record.RiskLevel,
+		ScamType:        record.ScamType,
+		CaseDescription: record.CaseDescription,
+		TypicalScripts:  record.TypicalScripts,
+		Keywords:        record.Keywords,
+		ViolatedLaw:     record.ViolatedLaw,
+		Suggestion:      record.Suggestion,
+	}
+}
+
+func contentFromRevisionEntity(entity historicalCaseRevisionEntity) CreateHistoricalCaseInput {
+	return CreateHistoricalCaseInput{
+		Title:           strings.TrimSpace(entity.Title),
+		TargetGroup:     strings.TrimSpace(entity.TargetGroup),
+		RiskLevel:       strings.TrimSpace(entity.RiskLevel),
+		ScamType:        strings.TrimSpace(entity.ScamType),
+		CaseDescription: strings.TrimSpace(entity.CaseDescription),
+		TypicalScripts:  decodeStringList(entity.TypicalScripts),
+		Keywords:        decodeStringList(entity.Keywords),
+		ViolatedLaw:     strings.TrimSpace(entity.ViolatedLaw),
+		Suggestion:      strings.TrimSpace(entity.Suggestion),
+	}
+}
+
+func revisionEntityFromCurrent(current historicalCaseEntity) historicalCaseRevisionEntity {
+	content := contentFromEntity(current)
+	versionAt := current.UpdatedAt
+	if versionAt.IsZero() {
+		versionAt = current.CreatedAt
+	}
+	return historicalCaseRevisionEntity{
+		CaseID:          strings.TrimSpace(current.CaseID),
+		Revision:        normalizeHistoricalCaseRevision(current.Revision),
+		Title:           content.Title,
+		TargetGroup:     content.TargetGroup,
+		RiskLevel:       content.RiskLevel,
+		ScamType:        content.ScamType,
+		CaseDescription: content.CaseDescription,
+		TypicalScripts:  encodeStringList(content.TypicalScripts),
+		Keywords:        encodeStringList(content.Keywords),
+		ViolatedLaw:     content.ViolatedLaw,
+		Suggestion:      content.Suggestion,
+		EditedBy:        normalizeUserID(firstNonEmpty(current.UpdatedBy, current.CreatedBy)),
+		VersionAt:       versionAt,
+	}
+}
+
+func currentRevisionFromEntity(current historicalCaseEntity) HistoricalCaseRevision {
+	snapshot := revisionEntityFromCurrent(current)
+	return HistoricalCaseRevision{
+		CaseID:    snapshot.CaseID,
+		Revision:  snapshot.Revision,
+		Content:   contentFromEntity(current),
+		EditedBy:  snapshot.EditedBy,
+		CreatedAt: snapshot.VersionAt,
+	}
+}
+
+func revisionFromEntity(entity historicalCaseRevisionEntity) HistoricalCaseRevision {
+	return HistoricalCaseRevision{
+		CaseID:       strings.TrimSpace(entity.CaseID),
+		Revision:     entity.Revision,
+		Content:      contentFromRevisionEntity(entity),
+		EditedBy:     normalizeUserID(entity.EditedBy),
+		ReplacedBy:   normalizeUserID(entity.ReplacedBy),
+		ChangeType:   strings.TrimSpace(entity.ChangeType),
+		RestoredFrom: entity.RestoredFrom,
+		CreatedAt:    entity.VersionAt,
+		ReplacedAt:   entity.CreatedAt,
+	}
+}
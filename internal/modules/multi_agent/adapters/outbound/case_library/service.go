@@ -33,6 +33,10 @@ func (s *Service) DeleteHistoricalCaseByID(caseID string) (bool, error) {
 	return DeleteHistoricalCaseByID(caseID)
 }
 
+func (s *Service) FindCasesByCitation(source string) ([]CitationMatch, error) {
+	return FindCasesByCitation(source)
+}
+
 func (s *Service) ListScamTypes() []string {
 	return ListScamTypes()
 }
@@ -41,18 +45,62 @@ func (s *Service) ListTargetGroups() []string {
 	return ListTargetGroups()
 }
 
-func (s *Service) ListPendingReviewPreviews() ([]PendingReviewPreview, error) {
-	return ListPendingReviewPreviews()
+func (s *Service) ListPendingReviewPreviews(filter PendingReviewFilter) ([]PendingReviewPreview, error) {
+	return ListPendingReviewPreviews(filter)
 }
 
 func (s *Service) GetPendingReviewByID(recordID string) (PendingReviewRecord, bool, error) {
 	return GetPendingReviewByID(recordID)
 }
 
-func (s *Service) ApprovePendingReview(ctx context.Context, recordID string) (HistoricalCaseRecord, error) {
-	return ApprovePendingReview(ctx, recordID)
+func (s *Service) ApprovePendingReview(ctx context.Context, recordID string, reviewerID string, comment string) (HistoricalCaseRecord, error) {
+	return ApprovePendingReview(ctx, recordID, reviewerID, comment)
+}
+
+func (s *Service) RejectPendingReview(ctx context.Context, recordID string, reviewerID string, reason string) error {
+	return RejectPendingReview(ctx, recordID, reviewerID, reason)
+}
+
+func (s *Service) ClaimPendingReview(ctx context.Context, recordID string, reviewerID string) (PendingReviewRecord, error) {
+	return ClaimPendingReview(ctx, recordID, reviewerID)
+}
+
+func (s *Service) ReleasePendingReview(ctx context.Context, recordID string, reviewerID string) (PendingReviewRecord, error) {
+	return ReleasePendingReview(ctx, recordID, reviewerID)
+}
+
+func (s *Service) UpdatePendingReview(ctx context.Context, recordID string, reviewerID string, input UpdateHistoricalCaseInput) (PendingReviewRecord, error) {
+	return UpdatePendingReview(ctx, recordID, reviewerID, input)
+}
+
+func (s *Service) AddPendingReviewComment(ctx context.Context, recordID string, reviewerID string, comment string) (PendingReviewAuditLog, error) {
+	return AddPendingReviewComment(ctx, recordID, reviewerID, comment)
+}
+
+func (s *Service) ListPendingReviewAuditLogs(filter PendingReviewAuditFilter) ([]PendingReviewAuditLog, error) {
+	return ListPendingReviewAuditLogs(filter)
+}
+
+func (s *Service) UpdateHistoricalCase(ctx context.Context, editorID string, caseID string, expectedRevision int, input UpdateHistoricalCaseInput) (HistoricalCaseRecord, bool, error) {
+	return UpdateHistoricalCase(ctx, editorID, caseID, expectedRevision, input)
+}
+
+func (s *Service) RollbackHistoricalCase(ctx context.Context, editorID string, caseID string, revision int) (HistoricalCaseRecord, bool, error) {
+	return RollbackHistoricalCase(ctx, editorID, caseID, revision)
+}
+
+func (s *Service) MergeHistoricalCases(ctx context.Context, editorID string, canonicalID string, expectedRevision int, mergedIDs []string) (HistoricalCaseRecord, bool, error) {
+	return MergeHistoricalCases(ctx, editorID, canonicalID, expectedRevision, mergedIDs)
+}
+
+func (s *Service) ListMergedHistoricalCases(caseID string) ([]HistoricalCaseRedirect, error) {
+	return ListMergedHistoricalCases(caseID)
+}
+
+func (s *Service) ListHistoricalCaseRevisions(caseID string) ([]HistoricalCaseRevision, bool, error) {
+	return ListHistoricalCaseRevisions(caseID)
 }
 
-func (s *Service) RejectPendingReview(ctx context.Context, recordID string) error {
-	return RejectPendingReview(ctx, recordID)
+func (s *Service) DiffHistoricalCaseRevisions(caseID string, fromRevision int, toRevision int) (HistoricalCaseRevision, HistoricalCaseRevision, []HistoricalCaseFieldChange, bool, error) {
+	return DiffHistoricalCaseRevisions(caseID, fromRevision, toRevision)
 }
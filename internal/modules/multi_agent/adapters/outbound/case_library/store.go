@@ -17,6 +17,8 @@ import (
 	model "antifraud/internal/modules/multi_agent/adapters/outbound/case_library/model"
 	"antifraud/internal/platform/cache"
 	"antifraud/internal/platform/database"
+
+	"gorm.io/gorm"
 )
 
 const (
@@ -51,6 +53,7 @@ func IsValidationError(err error) bool {
 }
 
 type CreateHistoricalCaseInput = model.CreateHistoricalCaseInput
+type CaseEmbedding = model.CaseEmbedding
 type HistoricalCaseRecord = model.HistoricalCaseRecord
 type HistoricalCasePreview = model.HistoricalCasePreview
 type HistoricalCasePreviewPage = model.HistoricalCasePreviewPage
@@ -63,8 +66,9 @@ const (
 )
 
 // CreateHistoricalCase 将历史案件写入独立数据库，并保存 embedding 向量。
+// 案件库跨用户共享，写入前与待审核案件一样移除文本中的个人信息。
 func CreateHistoricalCase(ctx context.Context, userID string, input CreateHistoricalCaseInput) (HistoricalCaseRecord, error) {
-	prepared, err := prepareHistoricalCaseInput(ctx, input)
+	prepared, err := prepareHistoricalCaseInput(ctx, stripCaseInputPII(input))
 	if err != nil {
 		return HistoricalCaseRecord{}, err
 	}
@@ -74,6 +78,35 @@ func CreateHistoricalCase(ctx context.Context, userID string, input CreateHistor
 	return insertHistoricalCasePrepared(userID, prepared)
 }
 
+// HistoricalCaseBatchResult 是批量写入中单条输入的结果，Err 非空时 Record 为零值。
+type HistoricalCaseBatchResult struct {
+	Record HistoricalCaseRecord
+	Err    error
+}
+
+// CreateHistoricalCases 批量写入历史案件，返回值与 inputs 一一对应。
+// 先批量向量化，再按顺序逐条查重入库；已写入的条目会参与后续条目的查重，因此同批内的重复案件也会被拦截。
+func CreateHistoricalCases(ctx context.Context, userID string, inputs []CreateHistoricalCaseInput) []HistoricalCaseBatchResult {
+	results := make([]HistoricalCaseBatchResult, len(inputs))
+	stripped := make([]CreateHistoricalCaseInput, len(inputs))
+	for index, input := range inputs {
+		stripped[index] = stripCaseInputPII(input)
+	}
+	prepared, errs := prepareHistoricalCaseInputs(ctx, stripped)
+	for index := range inputs {
+		if errs[index] != nil {
+			results[index].Err = errs[index]
+			continue
+		}
+		if duplicateErr := detectDuplicateHistoricalCase(prepared[index].vector); duplicateErr != nil {
+			results[index].Err = duplicateErr
+			continue
+		}
+		results[index].Record, results[index].Err = insertHistoricalCasePrepared(userID, prepared[index])
+	}
+	return results
+}
+
 // ListHistoricalCasePreviews 返回历史案件预览数据，用于列表页展示。
 func ListHistoricalCasePreviews() ([]HistoricalCasePreview, error) {
 	return listHistoricalCasePreviewsFromDB()
@@ -229,7 +262,7 @@ func normalizeHistoricalCasePreviewPagination(page int, pageSize int) (int, int)
 	return normalizedPage, normalizedPageSize
 }
 
-// GetHistoricalCaseByID 根据 case_id 返回完整历史案件详情。
+// GetHistoricalCaseByID 根据 case_id 返回完整历史案件详情；已合并的案件 ID 返回其保留案件。
 func GetHistoricalCaseByID(caseID string) (HistoricalCaseRecord, bool, error) {
 	trimmedCaseID := strings.TrimSpace(caseID)
 	if trimmedCaseID == "" {
@@ -254,7 +287,12 @@ func GetHistoricalCaseByID(caseID string) (HistoricalCaseRecord, bool, error) {
 		return HistoricalCaseRecord{}, false, fmt.Errorf("query historical case detail failed: %w", query.Error)
 	}
 	if query.RowsAffected == 0 {
-		return HistoricalCaseRecord{}, false, nil
+		// 已被合并的案件跳转到保留案件，调用方可通过返回记录的 CaseID 判断是否发生跳转。
+		target, redirected, err := ResolveHistoricalCaseID(trimmedCaseID)
+		if err != nil || !redirected || target == trimmedCaseID {
+			return HistoricalCaseRecord{}, false, err
+		}
+		return GetHistoricalCaseByID(target)
 	}
 
 	record := recordFromEntity(entity)
@@ -274,11 +312,24 @@ func DeleteHistoricalCaseByID(caseID string) (bool, error) {
 		return false, err
 	}
 
-	result := db.Where("case_id = ?", trimmedCaseID).Delete(&historicalCaseEntity{})
-	if result.Error != nil {
-		return false, fmt.Errorf("delete historical case failed: %w", result.Error)
+	var deleted bool
+	err = db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("case_id = ?", trimmedCaseID).Delete(&historicalCaseEntity{})
+		if result.Error != nil {
+			return fmt.Errorf("delete historical case failed: %w", result.Error)
+		}
+		deleted = result.RowsAffected > 0
+		if err := tx.Where("case_id = ?", trimmedCaseID).Delete(&historicalCaseRevisionEntity{}).Error; err != nil {
+			return fmt.Errorf("delete historical case revisions failed: %w", err)
+		}
+		if err := tx.Where("to_case_id = ?", trimmedCaseID).Delete(&historicalCaseRedirectEntity{}).Error; err != nil {
+			return fmt.Errorf("delete historical case redirects failed: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return false, err
 	}
-	deleted := result.RowsAffected > 0
 	if deleted {
 		removeHistoricalCaseVectorCache(trimmedCaseID)
 		touchHistoricalCaseGraphCacheVersion()
@@ -319,6 +370,11 @@ func normalizeAndValidateInput(input CreateHistoricalCaseInput) (CreateHistorica
 		ViolatedLaw:     strings.TrimSpace(input.ViolatedLaw),
 		Suggestion:      strings.TrimSpace(input.Suggestion),
 	}
+	citations, err := normalizeCaseCitations(input.Citations)
+	if err != nil {
+		return CreateHistoricalCaseInput{}, err
+	}
+	normalized.Citations = citations
 
 	if normalized.Title == "" {
 		return CreateHistoricalCaseInput{}, newValidationError("title is required")
@@ -719,10 +775,29 @@ func recordFromEntity(entity historicalCaseEntity) HistoricalCaseRecord {
 		Keywords:           decodeStringList(entity.Keywords),
 		ViolatedLaw:        strings.TrimSpace(entity.ViolatedLaw),
 		Suggestion:         strings.TrimSpace(entity.Suggestion),
+		Citations:          decodeCaseCitations(entity.Citations),
 		EmbeddingVector:    decodeFloatList(entity.EmbeddingVector),
 		EmbeddingModel:     strings.TrimSpace(entity.EmbeddingModel),
 		EmbeddingDimension: entity.EmbeddingDimension,
+		Revision:           normalizeHistoricalCaseRevision(entity.Revision),
+		UpdatedBy:          normalizeUserID(firstNonEmpty(entity.UpdatedBy, entity.CreatedBy)),
 		CreatedAt:          entity.CreatedAt,
 		UpdatedAt:          entity.UpdatedAt,
 	}
 }
+
+func normalizeHistoricalCaseRevision(revision int) int {
+	if revision < 1 {
+		return 1
+	}
+	return revision
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if trimmed := strings.TrimSpace(value); trimmed != "" {
+			return trimmed
+		}
+	}
+	return ""
+}
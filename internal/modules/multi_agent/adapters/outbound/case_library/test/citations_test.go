@@ -0,0 +1,85 @@
+package case_library_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	case_library "antifraud/internal/modules/multi_agent/adapters/outbound/case_library"
+)
+
+func TestPendingReviewCitations_CarriedToHistoricalCase(t *testing.T) {
+	stubHistoricalCaseVectorCache(t)
+	generateCaseEmbedding = func(_ context.Context, input string) ([]float64, string, error) {
+		return []float64{float64(len(input)), 1, 0}, "mock-citation", nil
+	}
+
+	retrievedAt := time.Date(2026, 3, 14, 8, 0, 0, 0, time.UTC)
+	created, err := case_library.CreatePendingReview(context.Background(), "admin", case_library.CreateHistoricalCaseInput{
+		Title:           "冒充客服退款诈骗",
+		TargetGroup:     "老人",
+		RiskLevel:       "高",
+		ScamType:        "冒充客服类",
+		CaseDescription: "受害人收到自称客服电话，被诱导下载远程控制软件并转账。",
+		Citations: []case_library.CaseCitation{
+			{URL: " https://news.gov.cn/a?id=1&from=search ", Title: " 警方通报 ", PublishedDate: "2026-03-13", RetrievedAt: retrievedAt},
+			{URL: "https://news.gov.cn/a?id=1&from=search", Title: "重复"},
+			{URL: "https://blog.example.com/b", Title: "转载"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("create pending review failed: %v", err)
+	}
+	if len(created.Citations) != 2 {
+		t.Fatalf("expected deduplicated citations, got %+v", created.Citations)
+	}
+	first := created.Citations[0]
+	if first.URL != "https://news.gov.cn/a?id=1&from=search" || first.Title != "警方通报" || !first.RetrievedAt.Equal(retrievedAt) {
+		t.Fatalf("unexpected normalized citation: %+v", first)
+	}
+
+	detail, found, err := case_library.GetPendingReviewByID(created.RecordID)
+	if err != nil || !found || len(detail.Citations) != 2 {
+		t.Fatalf("expected pending review detail with citations, got %+v found=%v err=%v", detail, found, err)
+	}
+
+	matches, err := case_library.FindCasesByCitation("gov.cn")
+	if err != nil || len(matches) != 1 || matches[0].Kind != case_library.CitationMatchPendingReview || len(matches[0].Citations) != 1 {
+		t.Fatalf("expected pending review matched by domain, got %+v err=%v", matches, err)
+	}
+
+	approved, err := case_library.ApprovePendingReview(context.Background(), created.RecordID, "reviewer", "")
+	if err != nil {
+		t.Fatalf("approve pending review failed: %v", err)
+	}
+	if len(approved.Citations) != 2 || approved.Citations[1].URL != "https://blog.example.com/b" {
+		t.Fatalf("expected citations carried to historical case, got %+v", approved.Citations)
+	}
+
+	matches, err = case_library.FindCasesByCitation("https://news.gov.cn/a?id=1&from=search/")
+	if err != nil || len(matches) != 1 || matches[0].Kind != case_library.CitationMatchHistoricalCase || matches[0].ID != approved.CaseID {
+		t.Fatalf("expected historical case matched by exact url, got %+v err=%v", matches, err)
+	}
+	if matches, err := case_library.FindCasesByCitation("https://news.gov.cn/other"); err != nil || len(matches) != 0 {
+		t.Fatalf("expected no match for another url, got %+v err=%v", matches, err)
+	}
+	if _, err := case_library.FindCasesByCitation("not a domain"); !case_library.IsValidationError(err) {
+		t.Fatalf("expected validation error for invalid source, got %v", err)
+	}
+}
+
+func TestCreatePendingReview_RejectsInvalidCitationURL(t *testing.T) {
+	stubHistoricalCaseVectorCache(t)
+
+	_, err := case_library.CreatePendingReview(context.Background(), "admin", case_library.CreateHistoricalCaseInput{
+		Title:           "冒充客服退款诈骗",
+		TargetGroup:     "老人",
+		RiskLevel:       "高",
+		ScamType:        "冒充客服类",
+		CaseDescription: "受害人收到自称客服电话，被诱导下载远程控制软件并转账。",
+		Citations:       []case_library.CaseCitation{{URL: "javascript:alert(1)"}},
+	})
+	if !case_library.IsValidationError(err) {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+}
@@ -0,0 +1,118 @@
+package case_library_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	case_library "antifraud/internal/modules/multi_agent/adapters/outbound/case_library"
+)
+
+func TestEmbeddingMigration_StagesPromotesAndRefusesCrossModelSearch(t *testing.T) {
+	stubHistoricalCaseVectorCache(t)
+
+	originalWithModel := generateCaseEmbeddingWithModel
+	originalBatchWithModel := generateCaseEmbeddingsWithModel
+	originalTarget := historicalCaseMigrationTarget
+	t.Cleanup(func() {
+		generateCaseEmbeddingWithModel = originalWithModel
+		generateCaseEmbeddingsWithModel = originalBatchWithModel
+		historicalCaseMigrationTarget = originalTarget
+	})
+
+	embeddingCalls := 0
+	generateCaseEmbedding = func(_ context.Context, _ string) ([]float64, string, error) {
+		vector := make([]float64, 4)
+		vector[embeddingCalls%2] = 1
+		embeddingCalls++
+		return vector, "mock-v1", nil
+	}
+	generateCaseEmbeddingWithModel = func(_ context.Context, _ string, model string) ([]float64, string, error) {
+		return []float64{0, 0, 1, 0}, model, nil
+	}
+	generateCaseEmbeddingsWithModel = func(_ context.Context, inputs []string, model string) ([][]float64, string, error) {
+		vectors := make([][]float64, len(inputs))
+		for index := range inputs {
+			vectors[index] = []float64{0, 0, 1, 0}
+		}
+		return vectors, model, nil
+	}
+	historicalCaseMigrationTarget = func() string { return "" }
+
+	for _, title := range []string{"冒充客服退款案件", "虚假投资理财案件"} {
+		if _, err := case_library.CreateHistoricalCase(context.Background(), "admin-migration", case_library.CreateHistoricalCaseInput{
+			Title:           title,
+			TargetGroup:     "老人",
+			RiskLevel:       "高",
+			ScamType:        "冒充客服类",
+			CaseDescription: title + "，嫌疑人诱导受害人转账。",
+		}); err != nil {
+			t.Fatalf("create historical case failed: %v", err)
+		}
+	}
+
+	queryVector := []float64{0, 0, 1, 0}
+	_, _, err := case_library.SearchTopKSimilarCasesByVectorWithFilter(queryVector, 3, case_library.SimilarCaseRecallFilter{EmbeddingModel: "mock-v2"})
+	if !errors.Is(err, case_library.ErrEmbeddingModelMismatch) {
+		t.Fatalf("expected cross-model search refused before migration, got %v", err)
+	}
+
+	// 迁移进行中新写入的案件应双写目标模型向量。
+	historicalCaseMigrationTarget = func() string { return "mock-v2" }
+	if _, err := case_library.CreateHistoricalCase(context.Background(), "admin-migration", case_library.CreateHistoricalCaseInput{
+		Title:           "冒充公检法案件",
+		TargetGroup:     "老人",
+		RiskLevel:       "高",
+		ScamType:        "冒充公检法类",
+		CaseDescription: "嫌疑人冒充警察要求受害人转入安全账户。",
+	}); err != nil {
+		t.Fatalf("create historical case during migration failed: %v", err)
+	}
+	total, ready, err := case_library.CountHistoricalCaseEmbeddings("mock-v2")
+	if err != nil || total != 3 || ready != 1 {
+		t.Fatalf("expected only the dual-written case ready, total=%d ready=%d err=%v", total, ready, err)
+	}
+
+	sources, err := case_library.ListHistoricalCaseEmbeddingSources(0, 10)
+	if err != nil || len(sources) != 3 {
+		t.Fatalf("list embedding sources failed: %+v err=%v", sources, err)
+	}
+	pending := make([]case_library.HistoricalCaseEmbeddingSource, 0, len(sources))
+	for _, source := range sources {
+		if source.StagedEmbeddingModel == "mock-v2" {
+			continue
+		}
+		if source.EmbeddingText == "" {
+			t.Fatalf("expected embedding text rebuilt for %s", source.CaseID)
+		}
+		pending = append(pending, source)
+	}
+	for _, err := range case_library.StageHistoricalCaseEmbeddings(context.Background(), pending, "mock-v2") {
+		if err != nil {
+			t.Fatalf("stage embedding failed: %v", err)
+		}
+	}
+	if _, ready, _ := case_library.CountHistoricalCaseEmbeddings("mock-v2"); ready != 3 {
+		t.Fatalf("expected all cases staged, ready=%d", ready)
+	}
+
+	// 暂存期间检索仍只看旧模型向量。
+	if _, _, err := case_library.SearchTopKSimilarCasesByVectorWithFilter(queryVector, 3, case_library.SimilarCaseRecallFilter{EmbeddingModel: "mock-v2"}); !errors.Is(err, case_library.ErrEmbeddingModelMismatch) {
+		t.Fatalf("staged vectors must not serve queries, got %v", err)
+	}
+
+	promoted, err := case_library.PromoteStagedHistoricalCaseEmbeddings("mock-v2")
+	if err != nil || promoted != 3 {
+		t.Fatalf("promote staged embeddings failed: promoted=%d err=%v", promoted, err)
+	}
+	results, _, err := case_library.SearchTopKSimilarCasesByVectorWithFilter(queryVector, 3, case_library.SimilarCaseRecallFilter{EmbeddingModel: "mock-v2"})
+	if err != nil || len(results) != 3 || results[0].Similarity < 0.999 {
+		t.Fatalf("expected promoted vectors searchable with target model, results=%+v err=%v", results, err)
+	}
+	if _, _, err := case_library.SearchTopKSimilarCasesByVectorWithFilter(queryVector, 3, case_library.SimilarCaseRecallFilter{EmbeddingModel: "mock-v1"}); !errors.Is(err, case_library.ErrEmbeddingModelMismatch) {
+		t.Fatalf("expected old model queries refused after cutover, got %v", err)
+	}
+	if dominant, err := case_library.DominantHistoricalCaseEmbeddingModel(); err != nil || dominant != "mock-v2" {
+		t.Fatalf("expected dominant model mock-v2, got %q err=%v", dominant, err)
+	}
+}
@@ -0,0 +1,127 @@
+package case_library_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	case_library "antifraud/internal/modules/multi_agent/adapters/outbound/case_library"
+)
+
+func TestSearchHybrid_FusesKeywordAndVectorRecall(t *testing.T) {
+	stubHistoricalCaseVectorCache(t)
+
+	embeddingCalls := 0
+	generateCaseEmbedding = func(_ context.Context, _ string) ([]float64, string, error) {
+		vector := make([]float64, 8)
+		vector[embeddingCalls%8] = 1
+		embeddingCalls++
+		return vector, "mock-hybrid", nil
+	}
+
+	inputs := []case_library.CreateHistoricalCaseInput{
+		{Title: "冒充客服退款案件", ScamType: "冒充客服类", CaseDescription: "嫌疑人冒充电商客服，以退款为由诱导受害人转账。"},
+		{Title: "虚假投资理财案件", ScamType: "虚假投资理财类", CaseDescription: "嫌疑人拉受害人进入投资群，诱导在虚假平台充值。"},
+		{Title: "冒充公检法案件", ScamType: "冒充公检法类", CaseDescription: "嫌疑人冒充警察称受害人涉嫌洗钱，要求配合调查。"},
+		{
+			Title:           "安全账户转账案件",
+			ScamType:        "冒充公检法类",
+			CaseDescription: "嫌疑人要求受害人把存款转入所谓安全账户。",
+			TypicalScripts:  []string{"请立即把钱转到安全账户 6222020200112233"},
+			Keywords:        []string{"安全账户", "QuickSupport"},
+		},
+	}
+	created := make([]case_library.HistoricalCaseRecord, 0, len(inputs))
+	for i, input := range inputs {
+		input.TargetGroup = "老人"
+		input.RiskLevel = "高"
+		record, err := case_library.CreateHistoricalCase(context.Background(), "admin-hybrid", input)
+		if err != nil {
+			t.Fatalf("create historical case %d failed: %v", i, err)
+		}
+		created = append(created, record)
+	}
+	semanticNeighbour := created[0]
+	keywordTarget := created[3]
+
+	results, appliedTopK, err := case_library.SearchHybrid(case_library.HybridSearchQuery{
+		Text:   "对方让我转到 6222020200112233",
+		Vector: semanticNeighbour.EmbeddingVector,
+		TopK:   3,
+	})
+	if err != nil {
+		t.Fatalf("hybrid search failed: %v", err)
+	}
+	if appliedTopK != 3 || len(results) != 3 {
+		t.Fatalf("unexpected hybrid result size: top_k=%d results=%+v", appliedTopK, results)
+	}
+	if results[0].CaseID != keywordTarget.CaseID || results[0].LexicalRank != 1 || results[0].VectorRank == 0 {
+		t.Fatalf("expected keyword-matched case fused to the top, got %+v", results[0])
+	}
+	if !containsString(results[0].MatchedTerms, "6222020200112233") {
+		t.Fatalf("expected account number in matched terms, got %v", results[0].MatchedTerms)
+	}
+	if results[1].CaseID != semanticNeighbour.CaseID || results[1].Similarity < 0.999 {
+		t.Fatalf("expected nearest vector neighbour ranked second, got %+v", results[1])
+	}
+
+	lexicalOnly, _, err := case_library.SearchHybrid(case_library.HybridSearchQuery{
+		Text:   "quicksupport",
+		Filter: case_library.SimilarCaseRecallFilter{ScamType: "冒充公检法类"},
+	})
+	if err != nil {
+		t.Fatalf("lexical-only hybrid search failed: %v", err)
+	}
+	if len(lexicalOnly) != 1 || lexicalOnly[0].CaseID != keywordTarget.CaseID || lexicalOnly[0].VectorRank != 0 {
+		t.Fatalf("expected only the keyword case from lexical channel, got %+v", lexicalOnly)
+	}
+
+	filtered, _, err := case_library.SearchHybrid(case_library.HybridSearchQuery{
+		Text:   "6222020200112233",
+		Vector: semanticNeighbour.EmbeddingVector,
+		Filter: case_library.SimilarCaseRecallFilter{ScamType: "冒充客服类"},
+	})
+	if err != nil {
+		t.Fatalf("filtered hybrid search failed: %v", err)
+	}
+	if len(filtered) != 1 || filtered[0].CaseID != semanticNeighbour.CaseID || filtered[0].LexicalRank != 0 {
+		t.Fatalf("filter should apply to both channels, got %+v", filtered)
+	}
+
+	if _, _, err := case_library.SearchHybrid(case_library.HybridSearchQuery{Text: "   "}); err == nil {
+		t.Fatal("expected error when neither text nor vector is provided")
+	}
+
+	historicalCaseVectorIndexVersionGet = func(string, interface{}) (bool, error) {
+		return false, errors.New("redis unavailable")
+	}
+	fallback, _, err := case_library.SearchHybrid(case_library.HybridSearchQuery{
+		Text:   "对方让我转到 6222020200112233",
+		Vector: semanticNeighbour.EmbeddingVector,
+		TopK:   3,
+	})
+	if err != nil {
+		t.Fatalf("hybrid search fallback failed: %v", err)
+	}
+	if fmt.Sprint(caseIDsOf(fallback)) != fmt.Sprint(caseIDsOf(results)) {
+		t.Fatalf("fallback scan should rank like the index: index=%v fallback=%v", caseIDsOf(results), caseIDsOf(fallback))
+	}
+}
+
+func containsString(values []string, target string) bool {
+	for _, value := range values {
+		if value == target {
+			return true
+		}
+	}
+	return false
+}
+
+func caseIDsOf(results []case_library.HybridCaseResult) []string {
+	ids := make([]string, 0, len(results))
+	for _, item := range results {
+		ids = append(ids, item.CaseID)
+	}
+	return ids
+}
@@ -0,0 +1,144 @@
+package case_library_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	case_library "antifraud/internal/modules/multi_agent/adapters/outbound/case_library"
+)
+
+func createMergeTestCase(t *testing.T, title string, scripts []string, keywords []string, citationURL string) case_library.HistoricalCaseRecord {
+	t.Helper()
+	input := case_library.CreateHistoricalCaseInput{
+		Title:           title,
+		TargetGroup:     "老人",
+		RiskLevel:       "高",
+		ScamType:        "冒充客服类",
+		CaseDescription: "受害人收到自称客服电话，被诱导下载远程控制软件并转账。",
+		TypicalScripts:  scripts,
+		Keywords:        keywords,
+	}
+	if citationURL != "" {
+		input.Citations = []case_library.CaseCitation{{URL: citationURL}}
+	}
+	created, err := case_library.CreateHistoricalCase(context.Background(), "admin", input)
+	if err != nil {
+		t.Fatalf("create historical case %s failed: %v", title, err)
+	}
+	return created
+}
+
+func TestMergeHistoricalCases_CombinesContentAndRedirects(t *testing.T) {
+	stubHistoricalCaseVectorCache(t)
+	generateCaseEmbedding = func(_ context.Context, input string) ([]float64, string, error) {
+		return []float64{float64(len(input)), 1, 0}, "mock-merge", nil
+	}
+
+	canonical := createMergeTestCase(t, "冒充客服退款诈骗", []string{"我是平台客服"}, []string{"客服", "退款"}, "https://news.gov.cn/a")
+	first := createMergeTestCase(t, "冒充电商客服诈骗", []string{"我是平台客服", "需要开通借贷"}, []string{"客服", "借贷"}, "https://police.gov.cn/b")
+	second := createMergeTestCase(t, "假客服理赔诈骗", []string{"快递丢失可以理赔"}, []string{"理赔"}, "https://news.gov.cn/a")
+
+	merged, found, err := case_library.MergeHistoricalCases(context.Background(), "admin-b", canonical.CaseID, 1, []string{first.CaseID, second.CaseID, first.CaseID})
+	if err != nil || !found {
+		t.Fatalf("merge historical cases failed: found=%v err=%v", found, err)
+	}
+	if merged.CaseID != canonical.CaseID || merged.Title != canonical.Title || merged.Revision != 2 || merged.UpdatedBy != "admin-b" {
+		t.Fatalf("expected canonical case kept with a new revision, got %+v", merged)
+	}
+	if len(merged.TypicalScripts) != 3 || merged.TypicalScripts[2] != "快递丢失可以理赔" {
+		t.Fatalf("expected combined scripts, got %v", merged.TypicalScripts)
+	}
+	if len(merged.Keywords) != 4 || len(merged.Citations) != 2 {
+		t.Fatalf("expected combined keywords and citations, got keywords=%v citations=%+v", merged.Keywords, merged.Citations)
+	}
+
+	revisions, _, err := case_library.ListHistoricalCaseRevisions(canonical.CaseID)
+	if err != nil || len(revisions) != 2 || revisions[1].ChangeType != case_library.HistoricalCaseChangeMerge {
+		t.Fatalf("expected merge recorded in revision history, got %+v err=%v", revisions, err)
+	}
+
+	redirected, found, err := case_library.GetHistoricalCaseByID(first.CaseID)
+	if err != nil || !found || redirected.CaseID != canonical.CaseID {
+		t.Fatalf("expected merged case id to resolve to canonical case, got %+v found=%v err=%v", redirected, found, err)
+	}
+	if _, found, _ := case_library.ListHistoricalCaseRevisions(second.CaseID); found {
+		t.Fatalf("expected merged case removed")
+	}
+	redirects, err := case_library.ListMergedHistoricalCases(canonical.CaseID)
+	if err != nil || len(redirects) != 2 || redirects[0].MergedBy != "admin-b" {
+		t.Fatalf("expected two redirects to canonical case, got %+v err=%v", redirects, err)
+	}
+
+	if _, _, err := case_library.MergeHistoricalCases(context.Background(), "admin-b", canonical.CaseID, 0, []string{second.CaseID}); !case_library.IsValidationError(err) {
+		t.Fatalf("expected validation error for already merged case, got %v", err)
+	}
+	if _, _, err := case_library.MergeHistoricalCases(context.Background(), "admin-b", canonical.CaseID, 0, []string{canonical.CaseID}); !case_library.IsValidationError(err) {
+		t.Fatalf("expected validation error for self merge, got %v", err)
+	}
+	if _, _, err := case_library.MergeHistoricalCases(context.Background(), "admin-b", canonical.CaseID, 1, []string{"HCASE-MISSING"}); !errors.Is(err, case_library.ErrHistoricalCaseRevisionConflict) {
+		t.Fatalf("expected revision conflict for stale revision, got %v", err)
+	}
+
+	// 保留案件再次被合并时，旧跳转直接指向新的保留案件。
+	survivor := createMergeTestCase(t, "冒充平台客服诈骗汇总", []string{"我是平台客服"}, []string{"客服"}, "")
+	if _, _, err := case_library.MergeHistoricalCases(context.Background(), "admin-c", survivor.CaseID, 0, []string{canonical.CaseID}); err != nil {
+		t.Fatalf("merge canonical into survivor failed: %v", err)
+	}
+	target, redirectedFound, err := case_library.ResolveHistoricalCaseID(first.CaseID)
+	if err != nil || !redirectedFound || target != survivor.CaseID {
+		t.Fatalf("expected redirect chain flattened to survivor, got %q found=%v err=%v", target, redirectedFound, err)
+	}
+
+	deleted, err := case_library.DeleteHistoricalCaseByID(survivor.CaseID)
+	if err != nil || !deleted {
+		t.Fatalf("delete survivor failed: deleted=%v err=%v", deleted, err)
+	}
+	if _, found, err := case_library.GetHistoricalCaseByID(first.CaseID); err != nil || found {
+		t.Fatalf("expected redirects removed with deleted case, found=%v err=%v", found, err)
+	}
+}
+
+func TestMergeHistoricalCases_RedirectsAuditLogReferences(t *testing.T) {
+	stubHistoricalCaseVectorCache(t)
+	generateCaseEmbedding = func(_ context.Context, input string) ([]float64, string, error) {
+		return []float64{float64(len(input)), 1, 0}, "mock-merge", nil
+	}
+
+	pending, err := case_library.CreatePendingReview(context.Background(), "collector", case_library.CreateHistoricalCaseInput{
+		Title:           "刷单返利诈骗",
+		TargetGroup:     "老人",
+		RiskLevel:       "高",
+		ScamType:        "冒充客服类",
+		CaseDescription: "受害人被拉入刷单群，先小额返利后被诱导大额充值。",
+		Keywords:        []string{"刷单"},
+	})
+	if err != nil {
+		t.Fatalf("create pending review failed: %v", err)
+	}
+	approved, err := case_library.ApprovePendingReview(context.Background(), pending.RecordID, "reviewer", "")
+	if err != nil {
+		t.Fatalf("approve pending review failed: %v", err)
+	}
+	canonical := createMergeTestCase(t, "冒充客服退款诈骗", []string{"我是平台客服"}, []string{"客服"}, "")
+
+	if _, _, err := case_library.MergeHistoricalCases(context.Background(), "admin", canonical.CaseID, 0, []string{approved.CaseID}); err != nil {
+		t.Fatalf("merge failed: %v", err)
+	}
+	logs, err := case_library.ListPendingReviewAuditLogs(case_library.PendingReviewAuditFilter{RecordID: pending.RecordID})
+	if err != nil || len(logs) == 0 {
+		t.Fatalf("list audit logs failed: %+v err=%v", logs, err)
+	}
+	redirected := 0
+	for _, entry := range logs {
+		if entry.CaseID == approved.CaseID {
+			t.Fatalf("expected audit log case id redirected to canonical case, got %+v", entry)
+		}
+		if entry.CaseID == canonical.CaseID {
+			redirected++
+		}
+	}
+	if redirected == 0 {
+		t.Fatalf("expected approve audit log pointing at canonical case, got %+v", logs)
+	}
+}
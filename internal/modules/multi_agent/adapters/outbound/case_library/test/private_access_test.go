@@ -17,6 +17,21 @@ import (
 //go:linkname generateCaseEmbedding antifraud/internal/modules/multi_agent/adapters/outbound/case_library.generateCaseEmbedding
 var generateCaseEmbedding func(context.Context, string) ([]float64, string, error)
 
+//go:linkname generateCaseEmbeddingWithModel antifraud/internal/modules/multi_agent/adapters/outbound/case_library.generateCaseEmbeddingWithModel
+var generateCaseEmbeddingWithModel func(context.Context, string, string) ([]float64, string, error)
+
+//go:linkname generateCaseEmbeddings antifraud/internal/modules/multi_agent/adapters/outbound/case_library.generateCaseEmbeddings
+var generateCaseEmbeddings func(context.Context, []string) ([][]float64, string, error)
+
+//go:linkname generateCaseEmbeddingsWithModel antifraud/internal/modules/multi_agent/adapters/outbound/case_library.generateCaseEmbeddingsWithModel
+var generateCaseEmbeddingsWithModel func(context.Context, []string, string) ([][]float64, string, error)
+
+//go:linkname historicalCaseMigrationTarget antifraud/internal/modules/multi_agent/adapters/outbound/case_library.historicalCaseMigrationTarget
+var historicalCaseMigrationTarget func() string
+
+//go:linkname historicalCaseQueryEmbeddingModel antifraud/internal/modules/multi_agent/adapters/outbound/case_library.historicalCaseQueryEmbeddingModel
+var historicalCaseQueryEmbeddingModel func() string
+
 //go:linkname searchHistoricalCasesByVector antifraud/internal/modules/multi_agent/adapters/outbound/case_library.searchHistoricalCasesByVector
 var searchHistoricalCasesByVector func([]float64, int) ([]case_library.SimilarCaseResult, int, error)
 
@@ -41,6 +56,12 @@ var historicalCaseVectorCacheHashGetAll func(string) (map[string]string, error)
 //go:linkname historicalCaseVectorCacheDelete antifraud/internal/modules/multi_agent/adapters/outbound/case_library.historicalCaseVectorCacheDelete
 var historicalCaseVectorCacheDelete func(string) error
 
+//go:linkname historicalCaseVectorIndexVersionGet antifraud/internal/modules/multi_agent/adapters/outbound/case_library.historicalCaseVectorIndexVersionGet
+var historicalCaseVectorIndexVersionGet func(string, interface{}) (bool, error)
+
+//go:linkname historicalCaseVectorIndexVersionSet antifraud/internal/modules/multi_agent/adapters/outbound/case_library.historicalCaseVectorIndexVersionSet
+var historicalCaseVectorIndexVersionSet func(string, interface{}, time.Duration) error
+
 //go:linkname historicalCaseDBOnce antifraud/internal/platform/database.historicalCaseDBOnce
 var historicalCaseDBOnce sync.Once
 
@@ -112,6 +112,54 @@ func TestCreatePendingReview_StoresEmbeddingFields(t *testing.T) {
 	}
 }
 
+func TestCreatePendingReview_StripsPIIBeforeEmbeddingAndStorage(t *testing.T) {
+	resetHistoricalCaseDB()
+	dbPath, err := prepareHistoricalCaseDBPath()
+	if err != nil {
+		t.Fatalf("prepare historical case db path failed: %v", err)
+	}
+	t.Setenv("HISTORICAL_CASE_DB_PATH", dbPath)
+
+	originalGenerateCaseEmbedding := generateCaseEmbedding
+	originalSearchHistoricalCases := searchHistoricalCasesByVector
+	t.Cleanup(func() {
+		generateCaseEmbedding = originalGenerateCaseEmbedding
+		searchHistoricalCasesByVector = originalSearchHistoricalCases
+		resetHistoricalCaseDB()
+		_ = os.Remove(dbPath)
+	})
+
+	var embeddedText string
+	generateCaseEmbedding = func(ctx context.Context, input string) ([]float64, string, error) {
+		embeddedText = input
+		return []float64{0.7, 0.8, 0.9}, "mock-embedding", nil
+	}
+	searchHistoricalCasesByVector = func(queryVector []float64, topK int) ([]case_library.SimilarCaseResult, int, error) {
+		return []case_library.SimilarCaseResult{}, 1, nil
+	}
+
+	record, err := case_library.CreatePendingReview(context.Background(), "u1", case_library.CreateHistoricalCaseInput{
+		Title:           "冒充客服诈骗",
+		TargetGroup:     "老人",
+		RiskLevel:       "高",
+		ScamType:        "冒充客服类",
+		CaseDescription: "受害人（联系人王五，电话13800138000）被要求向[银行卡号_1]转账。",
+		TypicalScripts:  []string{"请拨打 13800138000 办理退款"},
+	})
+	if err != nil {
+		t.Fatalf("create pending review failed: %v", err)
+	}
+	if strings.Contains(embeddedText, "13800138000") || strings.Contains(embeddedText, "王五") {
+		t.Fatalf("expected PII stripped before embedding, got %q", embeddedText)
+	}
+	if record.CaseDescription != "受害人（联系人[姓名]，电话[电话号码]）被要求向[银行卡号]转账。" {
+		t.Fatalf("unexpected stored description: %q", record.CaseDescription)
+	}
+	if len(record.TypicalScripts) != 1 || record.TypicalScripts[0] != "请拨打 [电话号码] 办理退款" {
+		t.Fatalf("unexpected stored scripts: %+v", record.TypicalScripts)
+	}
+}
+
 func TestRejectPendingReview_DeletesRecord(t *testing.T) {
 	resetHistoricalCaseDB()
 	dbPath, err := prepareHistoricalCaseDBPath()
@@ -147,7 +195,7 @@ func TestRejectPendingReview_DeletesRecord(t *testing.T) {
 		t.Fatalf("create pending review failed: %v", err)
 	}
 
-	if err := case_library.RejectPendingReview(context.Background(), record.RecordID); err != nil {
+	if err := case_library.RejectPendingReview(context.Background(), record.RecordID, "admin", ""); err != nil {
 		t.Fatalf("reject pending review failed: %v", err)
 	}
 
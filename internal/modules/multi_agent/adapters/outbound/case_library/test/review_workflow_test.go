@@ -0,0 +1,130 @@
+package case_library_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	case_library "antifraud/internal/modules/multi_agent/adapters/outbound/case_library"
+)
+
+func TestPendingReviewWorkflow_ClaimEditCommentAndAudit(t *testing.T) {
+	stubHistoricalCaseVectorCache(t)
+
+	embedCalls := 0
+	generateCaseEmbedding = func(_ context.Context, input string) ([]float64, string, error) {
+		embedCalls++
+		return []float64{float64(len(input)), 1, 0}, "mock-review", nil
+	}
+
+	userSubmitted, err := case_library.CreatePendingReview(context.Background(), "u1", case_library.CreateHistoricalCaseInput{
+		Title:           "冒充客服诈骗",
+		TargetGroup:     "老人",
+		RiskLevel:       "高",
+		ScamType:        "冒充客服类",
+		CaseDescription: "受害人收到自称客服电话，被诱导下载远程控制软件并转账。",
+	})
+	if err != nil {
+		t.Fatalf("create user pending review failed: %v", err)
+	}
+	agentCtx := case_library.WithPendingReviewSource(context.Background(), case_library.PendingReviewSourceAgent)
+	agentSubmitted, err := case_library.CreatePendingReview(agentCtx, "admin", case_library.CreateHistoricalCaseInput{
+		Title:           "虚假投资诈骗",
+		TargetGroup:     "青年",
+		RiskLevel:       "中",
+		ScamType:        "虚假投资理财类",
+		CaseDescription: "以高收益理财为诱饵引导受害人多次充值。",
+	})
+	if err != nil {
+		t.Fatalf("create agent pending review failed: %v", err)
+	}
+	if userSubmitted.Source != case_library.PendingReviewSourceUser || agentSubmitted.Source != case_library.PendingReviewSourceAgent {
+		t.Fatalf("unexpected sources: user=%q agent=%q", userSubmitted.Source, agentSubmitted.Source)
+	}
+
+	previews, err := case_library.ListPendingReviewPreviews(case_library.PendingReviewFilter{Source: case_library.PendingReviewSourceAgent})
+	if err != nil || len(previews) != 1 || previews[0].RecordID != agentSubmitted.RecordID {
+		t.Fatalf("expected agent filter to return only the agent case, got %+v err=%v", previews, err)
+	}
+	previews, _ = case_library.ListPendingReviewPreviews(case_library.PendingReviewFilter{ScamType: "冒充客服类"})
+	if len(previews) != 1 || previews[0].RecordID != userSubmitted.RecordID {
+		t.Fatalf("expected scam type filter to return the user case, got %+v", previews)
+	}
+	previews, _ = case_library.ListPendingReviewPreviews(case_library.PendingReviewFilter{OlderThan: time.Hour})
+	if len(previews) != 0 {
+		t.Fatalf("expected no case older than one hour, got %+v", previews)
+	}
+
+	claimed, err := case_library.ClaimPendingReview(context.Background(), userSubmitted.RecordID, "reviewer-a")
+	if err != nil || claimed.ClaimedBy != "reviewer-a" {
+		t.Fatalf("claim failed: %+v err=%v", claimed, err)
+	}
+	if _, err := case_library.ClaimPendingReview(context.Background(), userSubmitted.RecordID, "reviewer-b"); !errors.Is(err, case_library.ErrPendingReviewClaimedByOther) {
+		t.Fatalf("expected claim conflict, got %v", err)
+	}
+	previews, _ = case_library.ListPendingReviewPreviews(case_library.PendingReviewFilter{ClaimedBy: "none"})
+	if len(previews) != 1 || previews[0].RecordID != agentSubmitted.RecordID {
+		t.Fatalf("expected unclaimed filter to skip the claimed case, got %+v", previews)
+	}
+
+	description := "受害人接到自称电商客服的电话，称快递丢失需要退款，随后被诱导开通借贷并转账。"
+	if _, err := case_library.UpdatePendingReview(context.Background(), userSubmitted.RecordID, "reviewer-b", case_library.UpdateHistoricalCaseInput{
+		CaseDescription: &description,
+	}); !errors.Is(err, case_library.ErrPendingReviewClaimedByOther) {
+		t.Fatalf("expected edit by another reviewer to be rejected, got %v", err)
+	}
+	callsBeforeEdit := embedCalls
+	edited, err := case_library.UpdatePendingReview(context.Background(), userSubmitted.RecordID, "reviewer-a", case_library.UpdateHistoricalCaseInput{
+		CaseDescription: &description,
+	})
+	if err != nil {
+		t.Fatalf("edit pending review failed: %v", err)
+	}
+	if edited.CaseDescription != description || edited.UpdatedBy != "reviewer-a" || embedCalls != callsBeforeEdit+1 {
+		t.Fatalf("expected description edit to re-embed, got %+v calls=%d", edited, embedCalls)
+	}
+
+	if _, err := case_library.AddPendingReviewComment(context.Background(), userSubmitted.RecordID, "reviewer-b", "  "); !case_library.IsValidationError(err) {
+		t.Fatalf("expected validation error for empty comment, got %v", err)
+	}
+	if _, err := case_library.AddPendingReviewComment(context.Background(), userSubmitted.RecordID, "reviewer-b", "缺少转账金额"); err != nil {
+		t.Fatalf("comment failed: %v", err)
+	}
+
+	if err := case_library.RejectPendingReview(context.Background(), userSubmitted.RecordID, "reviewer-a", "与已有案件情节雷同"); err != nil {
+		t.Fatalf("reject failed: %v", err)
+	}
+	logs, err := case_library.ListPendingReviewAuditLogs(case_library.PendingReviewAuditFilter{RecordID: userSubmitted.RecordID})
+	if err != nil {
+		t.Fatalf("list audit logs failed: %v", err)
+	}
+	actions := make([]string, 0, len(logs))
+	for _, log := range logs {
+		actions = append(actions, log.Action)
+	}
+	expected := []string{case_library.PendingReviewActionReject, case_library.PendingReviewActionComment, case_library.PendingReviewActionEdit, case_library.PendingReviewActionClaim}
+	if len(actions) != len(expected) {
+		t.Fatalf("unexpected audit actions: %v", actions)
+	}
+	for index := range expected {
+		if actions[index] != expected[index] {
+			t.Fatalf("unexpected audit actions: %v", actions)
+		}
+	}
+	if logs[0].Reason != "与已有案件情节雷同" || len(logs[2].Changes) != 1 || logs[2].Changes[0].Field != "case_description" {
+		t.Fatalf("unexpected audit details: %+v", logs)
+	}
+
+	approved, err := case_library.ApprovePendingReview(context.Background(), agentSubmitted.RecordID, "reviewer-b", "信息完整")
+	if err != nil {
+		t.Fatalf("approve failed: %v", err)
+	}
+	logs, _ = case_library.ListPendingReviewAuditLogs(case_library.PendingReviewAuditFilter{ReviewerID: "reviewer-b", Action: case_library.PendingReviewActionApprove})
+	if len(logs) != 1 || logs[0].CaseID != approved.CaseID || logs[0].Comment != "信息完整" {
+		t.Fatalf("expected approve audit with case id, got %+v", logs)
+	}
+	if _, err := case_library.ClaimPendingReview(context.Background(), agentSubmitted.RecordID, "reviewer-a"); !errors.Is(err, case_library.ErrPendingReviewNotFound) {
+		t.Fatalf("expected processed case to be gone, got %v", err)
+	}
+}
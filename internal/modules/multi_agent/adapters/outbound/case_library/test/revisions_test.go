@@ -0,0 +1,131 @@
+package case_library_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	case_library "antifraud/internal/modules/multi_agent/adapters/outbound/case_library"
+)
+
+func TestUpdateHistoricalCase_VersionsDiffsAndRollsBack(t *testing.T) {
+	stubHistoricalCaseVectorCache(t)
+
+	embedCalls := 0
+	generateCaseEmbedding = func(_ context.Context, input string) ([]float64, string, error) {
+		embedCalls++
+		return []float64{float64(len(input)), 1, 0}, "mock-revision", nil
+	}
+
+	created, err := case_library.CreateHistoricalCase(context.Background(), "admin-a", case_library.CreateHistoricalCaseInput{
+		Title:           "冒充客服诈骗",
+		TargetGroup:     "老人",
+		RiskLevel:       "高",
+		ScamType:        "冒充客服类",
+		CaseDescription: "受害人收到自称客服电话，被诱导下载远程控制软件并转账。",
+		TypicalScripts:  []string{"我是平台客服"},
+		Keywords:        []string{"客服", "退款"},
+	})
+	if err != nil {
+		t.Fatalf("create historical case failed: %v", err)
+	}
+	if created.Revision != 1 || embedCalls != 1 {
+		t.Fatalf("expected revision 1 after one embedding, got revision=%d calls=%d", created.Revision, embedCalls)
+	}
+
+	// 自身旧向量不应被判定为重复。
+	searchHistoricalCasesByVector = func([]float64, int) ([]case_library.SimilarCaseResult, int, error) {
+		return []case_library.SimilarCaseResult{{CaseID: created.CaseID, Similarity: 1}}, 1, nil
+	}
+
+	scripts := []string{"我是平台客服", "现在帮你退款"}
+	riskLevel := "中"
+	updated, found, err := case_library.UpdateHistoricalCase(context.Background(), "admin-b", created.CaseID, 1, case_library.UpdateHistoricalCaseInput{
+		TypicalScripts: &scripts,
+		RiskLevel:      &riskLevel,
+	})
+	if err != nil || !found {
+		t.Fatalf("patch historical case failed: found=%v err=%v", found, err)
+	}
+	if updated.Revision != 2 || updated.UpdatedBy != "admin-b" || updated.RiskLevel != "中" || embedCalls != 1 {
+		t.Fatalf("expected non-embedding edit without re-embedding, got %+v calls=%d", updated, embedCalls)
+	}
+	if updated.CaseID != created.CaseID || !updated.CreatedAt.Equal(created.CreatedAt) || updated.CreatedBy != "admin-a" {
+		t.Fatalf("edit should keep identity fields, got %+v", updated)
+	}
+
+	if _, _, err := case_library.UpdateHistoricalCase(context.Background(), "admin-c", created.CaseID, 1, case_library.UpdateHistoricalCaseInput{
+		RiskLevel: &riskLevel,
+	}); !errors.Is(err, case_library.ErrHistoricalCaseRevisionConflict) {
+		t.Fatalf("expected revision conflict for stale revision, got %v", err)
+	}
+
+	description := "受害人接到自称电商客服的电话，称快递丢失需要退款，随后被诱导开通借贷并转账。"
+	updated, _, err = case_library.UpdateHistoricalCase(context.Background(), "admin-b", created.CaseID, 0, case_library.UpdateHistoricalCaseInput{
+		CaseDescription: &description,
+	})
+	if err != nil {
+		t.Fatalf("update description failed: %v", err)
+	}
+	if updated.Revision != 3 || embedCalls != 2 || updated.EmbeddingVector[0] == created.EmbeddingVector[0] {
+		t.Fatalf("expected description change to re-embed, got revision=%d calls=%d vector=%v", updated.Revision, embedCalls, updated.EmbeddingVector)
+	}
+	cached, found, err := case_library.GetHistoricalCaseByID(created.CaseID)
+	if err != nil || !found || cached.Revision != 3 || cached.CaseDescription != description {
+		t.Fatalf("expected vector cache to hold the latest revision, got %+v err=%v", cached, err)
+	}
+
+	unchanged, _, err := case_library.UpdateHistoricalCase(context.Background(), "admin-b", created.CaseID, 0, case_library.UpdateHistoricalCaseInput{
+		CaseDescription: &description,
+	})
+	if err != nil || unchanged.Revision != 3 {
+		t.Fatalf("no-op edit should not create a revision, got revision=%d err=%v", unchanged.Revision, err)
+	}
+
+	revisions, found, err := case_library.ListHistoricalCaseRevisions(created.CaseID)
+	if err != nil || !found || len(revisions) != 3 {
+		t.Fatalf("expected 3 revisions, got %+v err=%v", revisions, err)
+	}
+	if revisions[0].Revision != 3 || revisions[2].Revision != 1 || revisions[2].EditedBy != "admin-a" || revisions[2].ReplacedBy != "admin-b" {
+		t.Fatalf("unexpected revision history: %+v", revisions)
+	}
+
+	_, _, changes, found, err := case_library.DiffHistoricalCaseRevisions(created.CaseID, 1, 0)
+	if err != nil || !found {
+		t.Fatalf("diff revisions failed: found=%v err=%v", found, err)
+	}
+	changedFields := map[string]case_library.HistoricalCaseFieldChange{}
+	for _, change := range changes {
+		changedFields[change.Field] = change
+	}
+	if len(changedFields) != 3 || changedFields["risk_level"].Before != "高" || len(changedFields["typical_scripts"].Added) != 1 {
+		t.Fatalf("unexpected diff: %+v", changes)
+	}
+	if _, ok := changedFields["case_description"]; !ok {
+		t.Fatalf("expected case_description in diff: %+v", changes)
+	}
+
+	rolledBack, _, err := case_library.RollbackHistoricalCase(context.Background(), "admin-c", created.CaseID, 1)
+	if err != nil {
+		t.Fatalf("rollback failed: %v", err)
+	}
+	if rolledBack.Revision != 4 || rolledBack.RiskLevel != "高" || rolledBack.CaseDescription != created.CaseDescription || len(rolledBack.TypicalScripts) != 1 {
+		t.Fatalf("expected rollback to restore revision 1 content, got %+v", rolledBack)
+	}
+	revisions, _, _ = case_library.ListHistoricalCaseRevisions(created.CaseID)
+	if revisions[1].Revision != 3 || revisions[1].ChangeType != case_library.HistoricalCaseChangeRollback || revisions[1].RestoredFrom != 1 {
+		t.Fatalf("expected replaced revision to record the rollback, got %+v", revisions[1])
+	}
+
+	if _, _, err := case_library.RollbackHistoricalCase(context.Background(), "admin-c", created.CaseID, 4); !case_library.IsValidationError(err) {
+		t.Fatalf("expected validation error when rolling back to the current revision, got %v", err)
+	}
+
+	deleted, err := case_library.DeleteHistoricalCaseByID(created.CaseID)
+	if err != nil || !deleted {
+		t.Fatalf("delete failed: deleted=%v err=%v", deleted, err)
+	}
+	if _, found, _ := case_library.ListHistoricalCaseRevisions(created.CaseID); found {
+		t.Fatal("expected revisions to be unavailable after delete")
+	}
+}
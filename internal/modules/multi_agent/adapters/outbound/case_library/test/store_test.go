@@ -122,3 +122,181 @@ func TestCreateHistoricalCase_DuplicateHistoricalCaseRejected(t *testing.T) {
 		t.Fatalf("duplicate create should not insert rows, got count=%d", count)
 	}
 }
+
+func TestCreateHistoricalCases_BatchEmbedsOnceAndDedupesWithinBatch(t *testing.T) {
+	stubHistoricalCaseVectorCache(t)
+	originalGenerateCaseEmbeddings := generateCaseEmbeddings
+	t.Cleanup(func() {
+		generateCaseEmbeddings = originalGenerateCaseEmbeddings
+	})
+	searchHistoricalCasesByVector = case_library.SearchTopKSimilarCasesByVector
+
+	batchCalls := 0
+	generateCaseEmbeddings = func(_ context.Context, inputs []string) ([][]float64, string, error) {
+		batchCalls++
+		vectors := make([][]float64, len(inputs))
+		for index, input := range inputs {
+			vector := []float64{0, 0, 0}
+			switch {
+			case strings.Contains(input, "客服"):
+				vector[0] = 1
+			default:
+				vector[1] = 1
+			}
+			vectors[index] = vector
+		}
+		return vectors, "mock-batch", nil
+	}
+	generateCaseEmbedding = func(context.Context, string) ([]float64, string, error) {
+		t.Fatal("batch create should not embed case by case")
+		return nil, "", nil
+	}
+
+	customerService := case_library.CreateHistoricalCaseInput{
+		Title:           "冒充客服诈骗",
+		TargetGroup:     "老人",
+		RiskLevel:       "高",
+		ScamType:        "冒充客服类",
+		CaseDescription: "受害人收到自称客服电话，被诱导下载远程控制软件并转账。",
+	}
+	investment := case_library.CreateHistoricalCaseInput{
+		Title:           "虚假投资理财",
+		TargetGroup:     "老人",
+		RiskLevel:       "高",
+		ScamType:        "虚假投资理财类",
+		CaseDescription: "受害人被拉入投资群，在虚假平台充值后无法提现。",
+	}
+	invalid := investment
+	invalid.Title = ""
+
+	results := case_library.CreateHistoricalCases(context.Background(), "admin-batch", []case_library.CreateHistoricalCaseInput{
+		customerService, invalid, investment, customerService,
+	})
+	if len(results) != 4 || batchCalls != 1 {
+		t.Fatalf("expected one batch embedding call for 4 inputs, calls=%d results=%+v", batchCalls, results)
+	}
+	if results[0].Err != nil || results[0].Record.EmbeddingModel != "mock-batch" {
+		t.Fatalf("expected first case created, got %+v", results[0])
+	}
+	if results[1].Err == nil || !case_library.IsValidationError(results[1].Err) {
+		t.Fatalf("expected validation error for missing title, got %v", results[1].Err)
+	}
+	if results[2].Err != nil || results[2].Record.CaseID == "" {
+		t.Fatalf("expected third case created, got %+v", results[2])
+	}
+	duplicateErr, ok := case_library.AsDuplicateHistoricalCaseError(results[3].Err)
+	if !ok || duplicateErr.TopMatch.CaseID != results[0].Record.CaseID {
+		t.Fatalf("expected repeated case rejected against the earlier row of the same batch, got %v", results[3].Err)
+	}
+}
+
+func TestCreateHistoricalCases_ReusesPrecomputedVectorOfActiveModel(t *testing.T) {
+	stubHistoricalCaseVectorCache(t)
+	originalGenerateCaseEmbeddings := generateCaseEmbeddings
+	originalActiveModel := historicalCaseQueryEmbeddingModel
+	t.Cleanup(func() {
+		generateCaseEmbeddings = originalGenerateCaseEmbeddings
+		historicalCaseQueryEmbeddingModel = originalActiveModel
+	})
+	historicalCaseQueryEmbeddingModel = func() string { return "pinned-v1" }
+	searchHistoricalCasesByVector = func([]float64, int) ([]case_library.SimilarCaseResult, int, error) {
+		return []case_library.SimilarCaseResult{}, 0, nil
+	}
+
+	embeddedTexts := make([]string, 0, 1)
+	generateCaseEmbeddings = func(_ context.Context, inputs []string) ([][]float64, string, error) {
+		embeddedTexts = append(embeddedTexts, inputs...)
+		vectors := make([][]float64, len(inputs))
+		for index := range inputs {
+			vectors[index] = []float64{0, 0, 1}
+		}
+		return vectors, "pinned-v1", nil
+	}
+
+	matching := case_library.CreateHistoricalCaseInput{
+		Title:           "冒充客服诈骗",
+		TargetGroup:     "老人",
+		RiskLevel:       "高",
+		ScamType:        "冒充客服类",
+		CaseDescription: "受害人收到自称客服电话，被诱导下载远程控制软件并转账。",
+		Embedding:       &case_library.CaseEmbedding{Model: "pinned-v1", Vector: []float64{1, 0, 0}},
+	}
+	otherModel := case_library.CreateHistoricalCaseInput{
+		Title:           "虚假投资理财",
+		TargetGroup:     "老人",
+		RiskLevel:       "高",
+		ScamType:        "虚假投资理财类",
+		CaseDescription: "受害人被拉入投资群，在虚假平台充值后无法提现。",
+		Embedding:       &case_library.CaseEmbedding{Model: "legacy-v0", Vector: []float64{0, 1, 0}},
+	}
+
+	results := case_library.CreateHistoricalCases(context.Background(), "admin-import", []case_library.CreateHistoricalCaseInput{matching, otherModel})
+	if len(results) != 2 || results[0].Err != nil || results[1].Err != nil {
+		t.Fatalf("batch create failed: %+v", results)
+	}
+	if got := results[0].Record; got.EmbeddingModel != "pinned-v1" || len(got.EmbeddingVector) != 3 || got.EmbeddingVector[0] != 1 {
+		t.Fatalf("expected vector of the active model to be reused, got model=%s vector=%v", got.EmbeddingModel, got.EmbeddingVector)
+	}
+	if got := results[1].Record; got.EmbeddingModel != "pinned-v1" || len(got.EmbeddingVector) != 3 || got.EmbeddingVector[2] != 1 {
+		t.Fatalf("expected vector of another model to be regenerated, got model=%s vector=%v", got.EmbeddingModel, got.EmbeddingVector)
+	}
+	if len(embeddedTexts) != 1 || !strings.Contains(embeddedTexts[0], otherModel.Title) {
+		t.Fatalf("expected only the mismatched case to be embedded, got %v", embeddedTexts)
+	}
+}
+
+func TestCreateHistoricalCase_StripsPIIForAdminAndBatchWrites(t *testing.T) {
+	stubHistoricalCaseVectorCache(t)
+	originalGenerateCaseEmbeddings := generateCaseEmbeddings
+	t.Cleanup(func() {
+		generateCaseEmbeddings = originalGenerateCaseEmbeddings
+	})
+
+	embeddedTexts := make([]string, 0, 2)
+	generateCaseEmbedding = func(_ context.Context, input string) ([]float64, string, error) {
+		embeddedTexts = append(embeddedTexts, input)
+		return []float64{1, 0, 0}, "mock-embedding", nil
+	}
+	generateCaseEmbeddings = func(_ context.Context, inputs []string) ([][]float64, string, error) {
+		embeddedTexts = append(embeddedTexts, inputs...)
+		vectors := make([][]float64, len(inputs))
+		for index := range inputs {
+			vectors[index] = []float64{0, 1, 0}
+		}
+		return vectors, "mock-batch", nil
+	}
+	searchHistoricalCasesByVector = func([]float64, int) ([]case_library.SimilarCaseResult, int, error) {
+		return []case_library.SimilarCaseResult{}, 0, nil
+	}
+
+	input := case_library.CreateHistoricalCaseInput{
+		Title:           "冒充客服诈骗",
+		TargetGroup:     "老人",
+		RiskLevel:       "高",
+		ScamType:        "冒充客服类",
+		CaseDescription: "受害人（联系人王五，电话13800138000）被要求转账。",
+		TypicalScripts:  []string{"请拨打 13800138000 办理退款"},
+	}
+	record, err := case_library.CreateHistoricalCase(context.Background(), "admin", input)
+	if err != nil {
+		t.Fatalf("create historical case failed: %v", err)
+	}
+	results := case_library.CreateHistoricalCases(context.Background(), "admin-batch", []case_library.CreateHistoricalCaseInput{input})
+	if len(results) != 1 || results[0].Err != nil {
+		t.Fatalf("batch create historical case failed: %+v", results)
+	}
+
+	for _, text := range embeddedTexts {
+		if strings.Contains(text, "13800138000") || strings.Contains(text, "王五") {
+			t.Fatalf("expected PII stripped before embedding, got %q", text)
+		}
+	}
+	for _, created := range []case_library.HistoricalCaseRecord{record, results[0].Record} {
+		if created.CaseDescription != "受害人（联系人[姓名]，电话[电话号码]）被要求转账。" {
+			t.Fatalf("unexpected stored description: %q", created.CaseDescription)
+		}
+		if len(created.TypicalScripts) != 1 || created.TypicalScripts[0] != "请拨打 [电话号码] 办理退款" {
+			t.Fatalf("unexpected stored scripts: %+v", created.TypicalScripts)
+		}
+	}
+}
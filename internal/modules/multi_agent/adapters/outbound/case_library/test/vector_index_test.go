@@ -0,0 +1,182 @@
+package case_library_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	case_library "antifraud/internal/modules/multi_agent/adapters/outbound/case_library"
+)
+
+func TestSearchTopKSimilarCases_UsesIncrementalVectorIndex(t *testing.T) {
+	versionReads := stubHistoricalCaseVectorCache(t)
+
+	vectors := map[string][]float64{}
+	embeddingCalls := 0
+	generateCaseEmbedding = func(_ context.Context, input string) ([]float64, string, error) {
+		embeddingCalls++
+		vector := make([]float64, 8)
+		vector[embeddingCalls%8] = 1
+		vector[(embeddingCalls+3)%8] = float64(embeddingCalls) / 10
+		vectors[input] = vector
+		return vector, "mock-ann", nil
+	}
+
+	scamTypes := []string{"冒充客服类", "虚假投资理财类"}
+	created := make([]case_library.HistoricalCaseRecord, 0, 6)
+	for i := 0; i < 6; i++ {
+		record, err := case_library.CreateHistoricalCase(context.Background(), "admin-ann", case_library.CreateHistoricalCaseInput{
+			Title:           fmt.Sprintf("向量索引测试案件%d", i),
+			TargetGroup:     "老人",
+			RiskLevel:       "高",
+			ScamType:        scamTypes[i%2],
+			CaseDescription: fmt.Sprintf("第%d起案件：嫌疑人冒充平台工作人员，诱导受害人下载远程控制软件并转账。", i),
+		})
+		if err != nil {
+			t.Fatalf("create historical case %d failed: %v", i, err)
+		}
+		created = append(created, record)
+	}
+
+	target := created[3]
+	results, _, err := case_library.SearchTopKSimilarCasesByVector(target.EmbeddingVector, 3)
+	if err != nil {
+		t.Fatalf("search similar cases failed: %v", err)
+	}
+	if len(results) != 3 || results[0].CaseID != target.CaseID || results[0].Similarity < 0.999 {
+		t.Fatalf("expected target case ranked first, got %+v", results)
+	}
+
+	filtered, _, err := case_library.SearchTopKSimilarCasesByVectorWithConditions(target.EmbeddingVector, 5, "", scamTypes[0])
+	if err != nil {
+		t.Fatalf("filtered search failed: %v", err)
+	}
+	if len(filtered) != 3 {
+		t.Fatalf("expected 3 cases of scam type %s, got %+v", scamTypes[0], filtered)
+	}
+	for _, item := range filtered {
+		if item.ScamType != scamTypes[0] {
+			t.Fatalf("filtered search returned unexpected scam type: %+v", item)
+		}
+	}
+
+	if _, err := case_library.DeleteHistoricalCaseByID(target.CaseID); err != nil {
+		t.Fatalf("delete historical case failed: %v", err)
+	}
+	afterDelete, _, err := case_library.SearchTopKSimilarCasesByVector(target.EmbeddingVector, 5)
+	if err != nil {
+		t.Fatalf("search after delete failed: %v", err)
+	}
+	if len(afterDelete) != 5 {
+		t.Fatalf("expected 5 remaining cases, got %+v", afterDelete)
+	}
+	for _, item := range afterDelete {
+		if item.CaseID == target.CaseID {
+			t.Fatalf("deleted case should be removed from vector index: %+v", afterDelete)
+		}
+	}
+
+	recall, err := case_library.SelfCheckHistoricalCaseVectorIndexRecall(10, 3)
+	if err != nil {
+		t.Fatalf("recall self-check failed: %v", err)
+	}
+	if !recall.Ready || !recall.Healthy || recall.IndexSize != 5 || recall.Samples == 0 {
+		t.Fatalf("unexpected recall self-check result: %+v", recall)
+	}
+	if *versionReads == 0 {
+		t.Fatal("expected search to consult vector index version")
+	}
+}
+
+// stubHistoricalCaseVectorCache 用内存 map 替换 Redis 向量缓存与索引版本号读写，返回版本号读取次数。
+func stubHistoricalCaseVectorCache(t *testing.T) *int {
+	t.Helper()
+	resetHistoricalCaseDB()
+	dbPath, err := prepareHistoricalCaseDBPath()
+	if err != nil {
+		t.Fatalf("prepare historical case db path failed: %v", err)
+	}
+	t.Setenv("HISTORICAL_CASE_DB_PATH", dbPath)
+	t.Cleanup(func() {
+		resetHistoricalCaseDB()
+		_ = os.Remove(dbPath)
+	})
+
+	originalGenerateCaseEmbedding := generateCaseEmbedding
+	originalSearchHistoricalCases := searchHistoricalCasesByVector
+	originalReadyGet := historicalCaseVectorCacheReadyGet
+	originalReadySet := historicalCaseVectorCacheReadySet
+	originalHashGet := historicalCaseVectorCacheHashGet
+	originalHashSet := historicalCaseVectorCacheHashSetJSON
+	originalHashGetAll := historicalCaseVectorCacheHashGetAll
+	originalHashDelete := historicalCaseVectorCacheHashDelete
+	originalDelete := historicalCaseVectorCacheDelete
+	originalVersionGet := historicalCaseVectorIndexVersionGet
+	originalVersionSet := historicalCaseVectorIndexVersionSet
+	t.Cleanup(func() {
+		generateCaseEmbedding = originalGenerateCaseEmbedding
+		searchHistoricalCasesByVector = originalSearchHistoricalCases
+		historicalCaseVectorCacheReadyGet = originalReadyGet
+		historicalCaseVectorCacheReadySet = originalReadySet
+		historicalCaseVectorCacheHashGet = originalHashGet
+		historicalCaseVectorCacheHashSetJSON = originalHashSet
+		historicalCaseVectorCacheHashGetAll = originalHashGetAll
+		historicalCaseVectorCacheHashDelete = originalHashDelete
+		historicalCaseVectorCacheDelete = originalDelete
+		historicalCaseVectorIndexVersionGet = originalVersionGet
+		historicalCaseVectorIndexVersionSet = originalVersionSet
+	})
+
+	searchHistoricalCasesByVector = func([]float64, int) ([]case_library.SimilarCaseResult, int, error) {
+		return []case_library.SimilarCaseResult{}, 1, nil
+	}
+
+	cacheStore := map[string]case_library.HistoricalCaseRecord{}
+	version := ""
+	versionReads := 0
+	historicalCaseVectorCacheReadyGet = func(_ string, out interface{}) (bool, error) {
+		*(out.(*bool)) = true
+		return true, nil
+	}
+	historicalCaseVectorCacheReadySet = func(string, interface{}, time.Duration) error { return nil }
+	historicalCaseVectorCacheHashGet = func(_ string, field string, out interface{}) (bool, error) {
+		record, ok := cacheStore[field]
+		if ok {
+			*(out.(*case_library.HistoricalCaseRecord)) = record
+		}
+		return ok, nil
+	}
+	historicalCaseVectorCacheHashSetJSON = func(_ string, field string, value interface{}) error {
+		cacheStore[field] = value.(case_library.HistoricalCaseRecord)
+		return nil
+	}
+	historicalCaseVectorCacheHashGetAll = func(string) (map[string]string, error) {
+		values := map[string]string{}
+		for caseID, record := range cacheStore {
+			encoded, _ := json.Marshal(record)
+			values[caseID] = string(encoded)
+		}
+		return values, nil
+	}
+	historicalCaseVectorCacheHashDelete = func(_ string, field string) error {
+		delete(cacheStore, field)
+		return nil
+	}
+	historicalCaseVectorCacheDelete = func(string) error {
+		cacheStore = map[string]case_library.HistoricalCaseRecord{}
+		return nil
+	}
+	historicalCaseVectorIndexVersionGet = func(_ string, out interface{}) (bool, error) {
+		versionReads++
+		*(out.(*string)) = version
+		return version != "", nil
+	}
+	historicalCaseVectorIndexVersionSet = func(_ string, value interface{}, _ time.Duration) error {
+		version = value.(string)
+		return nil
+	}
+	return &versionReads
+}
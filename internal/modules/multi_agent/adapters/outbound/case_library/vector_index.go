@@ -0,0 +1,398 @@
+package case_library
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"antifraud/internal/platform/cache"
+	"antifraud/internal/platform/lexicalindex"
+	"antifraud/internal/platform/vectorindex"
+)
+
+const historicalCaseVectorIndexVersionKey = "cache:case_library:vector_records_version"
+
+const (
+	// maxExactFilterCandidates 以内的过滤结果直接精确扫描，比在图上过滤更快也更准。
+	maxExactFilterCandidates = 1024
+	// defaultVectorIndexRecallSamples 是召回自检默认抽样的查询数。
+	defaultVectorIndexRecallSamples = 50
+	// minHealthyVectorIndexRecall 是召回自检判定健康的最低 recall@k。
+	minHealthyVectorIndexRecall = 0.95
+)
+
+var (
+	historicalCaseVectorIndexVersionGet = cache.GetJSON
+	historicalCaseVectorIndexVersionSet = cache.SetJSON
+)
+
+var historicalCaseVectorIndexOptions = vectorindex.Options{M: 16, EfConstruction: 200, EfSearch: 64}
+
+// historicalCaseANNIndex 是 Redis 向量快照在本进程内的 HNSW 索引。
+// 设计说明：
+// 1) Redis 仍是多实例共享的权威缓存，索引只是本进程的检索加速结构；
+// 2) 每次写缓存都会刷新 Redis 中的版本号，检索前比较版本号（一次 GET），不一致时从快照整体重建；
+// 3) 本进程的增量写入在版本号连续时直接增量更新索引，避免自己的写入触发重建；
+// 4) 读取版本号失败（如 Redis 不可用）时不使用索引，回退到原有的快照暴力扫描；
+// 5) 同一份快照还维护一份 BM25 倒排索引（lexical），供混合检索的关键词通道使用。
+type historicalCaseANNIndex struct {
+	mu      sync.Mutex
+	index   *vectorindex.HNSW
+	lexical *lexicalindex.BM25
+	records map[string]HistoricalCaseRecord
+	// byTargetGroup/byScamType 是过滤条件的倒排集合，用于估算过滤后的候选规模。
+	byTargetGroup map[string]map[string]struct{}
+	byScamType    map[string]map[string]struct{}
+	// byModel 按 embedding 模型记录已进入向量图的案件，用于拒绝跨模型比较。
+	byModel map[string]map[string]struct{}
+	version string
+	ready   bool
+}
+
+// unnamedEmbeddingModelKey 是未记录模型名的旧数据在 byModel 中的键。
+const unnamedEmbeddingModelKey = "-"
+
+var historicalCaseVectorIndex = &historicalCaseANNIndex{}
+
+// HistoricalCaseVectorIndexRecallResult 是 ANN 索引召回自检结果。
+type HistoricalCaseVectorIndexRecallResult struct {
+	CheckedAt time.Time
+	Ready     bool
+	Healthy   bool
+	IndexSize int
+	Samples   int
+	TopK      int
+	// Recall 为抽样查询的平均 recall@k（以精确扫描为基准）。
+	Recall float64
+	// MissedCaseIDs 为精确扫描命中但 ANN 未召回的案件（去重，最多 20 条）。
+	MissedCaseIDs []string
+}
+
+// searchHistoricalCaseVectorIndex 使用 ANN 索引检索；ok 为 false 时调用方应回退到暴力扫描，
+// mismatched 为 true 表示索引中没有与查询模型兼容的向量。
+func searchHistoricalCaseVectorIndex(normalizedQuery []float64, topK int, filter SimilarCaseRecallFilter) ([]SimilarCaseResult, bool, bool) {
+	var results []SimilarCaseResult
+	mismatched := false
+	ok := withReadyHistoricalCaseVectorIndex(func(a *historicalCaseANNIndex) {
+		results, mismatched = a.searchLocked(normalizedQuery, topK, filter)
+	})
+	return results, mismatched, ok
+}
+
+// withReadyHistoricalCaseVectorIndex 确认索引与 Redis 版本一致（必要时重建）后持锁执行 fn；返回 false 表示索引不可用。
+func withReadyHistoricalCaseVectorIndex(fn func(a *historicalCaseANNIndex)) bool {
+	version, ok := currentHistoricalCaseVectorIndexVersion()
+	if !ok {
+		return false
+	}
+
+	ann := historicalCaseVectorIndex
+	ann.mu.Lock()
+	defer ann.mu.Unlock()
+
+	if !ann.ready || ann.version != version {
+		if err := ann.rebuildLocked(version); err != nil {
+			log.Printf("[case_library] rebuild vector index failed, fallback to full scan: %v", err)
+			return false
+		}
+	}
+	fn(ann)
+	return true
+}
+
+// rebuildLocked 从 Redis 快照（未就绪时回源 DB）重建索引；version 为空时生成新版本号写回 Redis。
+func (a *historicalCaseANNIndex) rebuildLocked(version string) error {
+	records, err := snapshotHistoricalCaseVectorCache()
+	if err != nil {
+		return err
+	}
+	// 快照过程中可能因漂移修复而重写缓存并刷新版本号，以快照之后的版本为准。
+	if latest, ok := currentHistoricalCaseVectorIndexVersion(); ok {
+		version = latest
+	}
+	if strings.TrimSpace(version) == "" {
+		version = newHistoricalCaseVectorIndexVersion()
+		if err := historicalCaseVectorIndexVersionSet(historicalCaseVectorIndexVersionKey, version, 0); err != nil {
+			return fmt.Errorf("write vector index version failed: %w", err)
+		}
+	}
+
+	a.index = vectorindex.NewHNSW(historicalCaseVectorIndexOptions)
+	a.lexical = lexicalindex.NewBM25(lexicalindex.Options{})
+	a.records = make(map[string]HistoricalCaseRecord, len(records))
+	a.byTargetGroup = map[string]map[string]struct{}{}
+	a.byScamType = map[string]map[string]struct{}{}
+	a.byModel = map[string]map[string]struct{}{}
+	for _, record := range records {
+		a.upsertLocked(record)
+	}
+	a.version = version
+	a.ready = true
+	log.Printf("[case_library] vector index rebuilt: records=%d version=%s", a.index.Len(), version)
+	return nil
+}
+
+func (a *historicalCaseANNIndex) upsertLocked(record HistoricalCaseRecord) {
+	caseID := strings.TrimSpace(record.CaseID)
+	if caseID == "" {
+		return
+	}
+	a.removeLocked(caseID)
+	indexedVector := a.index.Upsert(caseID, record.EmbeddingVector)
+	indexedText := a.lexical.Upsert(caseID, historicalCaseLexicalFields(record)...)
+	if !indexedVector && !indexedText {
+		return
+	}
+	stored := cloneHistoricalCaseRecord(record)
+	stored.CaseID = caseID
+	stored.EmbeddingVector = nil
+	a.records[caseID] = stored
+	addToStringSet(a.byTargetGroup, stored.TargetGroup, caseID)
+	addToStringSet(a.byScamType, stored.ScamType, caseID)
+	if indexedVector {
+		addToStringSet(a.byModel, embeddingModelKey(stored.EmbeddingModel), caseID)
+	}
+}
+
+func embeddingModelKey(model string) string {
+	if trimmed := strings.TrimSpace(model); trimmed != "" {
+		return trimmed
+	}
+	return unnamedEmbeddingModelKey
+}
+
+func (a *historicalCaseANNIndex) removeLocked(caseID string) {
+	existing, ok := a.records[caseID]
+	if !ok {
+		return
+	}
+	a.index.Remove(caseID)
+	a.lexical.Remove(caseID)
+	delete(a.records, caseID)
+	removeFromStringSet(a.byTargetGroup, existing.TargetGroup, caseID)
+	removeFromStringSet(a.byScamType, existing.ScamType, caseID)
+	removeFromStringSet(a.byModel, embeddingModelKey(existing.EmbeddingModel), caseID)
+}
+
+func (a *historicalCaseANNIndex) searchLocked(normalizedQuery []float64, topK int, filter SimilarCaseRecallFilter) ([]SimilarCaseResult, bool) {
+	filter, mismatched := a.resolveModelFilterLocked(normalizeSimilarCaseRecallFilter(filter))
+	if mismatched {
+		return []SimilarCaseResult{}, true
+	}
+	var accept func(string) bool
+	if filter.TargetGroup != "" || filter.ScamType != "" || filter.EmbeddingModel != "" {
+		accept = func(caseID string) bool {
+			return matchSimilarCaseRecallFilter(a.records[caseID], filter)
+		}
+	}
+
+	var hits []vectorindex.Hit
+	if candidates, narrowed := a.filterCandidatesLocked(filter); narrowed && len(candidates) <= maxExactFilterCandidates {
+		if len(candidates) == 0 {
+			return []SimilarCaseResult{}, false
+		}
+		hits = a.index.Exact(normalizedQuery, topK, accept)
+	} else {
+		hits = a.index.Search(normalizedQuery, topK, accept)
+	}
+
+	results := make([]SimilarCaseResult, 0, len(hits))
+	for _, hit := range hits {
+		item, ok := a.records[hit.ID]
+		if !ok {
+			continue
+		}
+		results = append(results, similarCaseResultFromRecord(item, hit.Similarity))
+	}
+	sortSimilarCaseResults(results)
+	return results, false
+}
+
+// resolveModelFilterLocked 处理查询模型条件：
+// 1) 向量图中没有任何兼容记录时返回 mismatched；
+// 2) 全部记录都兼容（常态，单一模型）时去掉模型条件，避免无意义的过滤开销。
+func (a *historicalCaseANNIndex) resolveModelFilterLocked(filter SimilarCaseRecallFilter) (SimilarCaseRecallFilter, bool) {
+	if filter.EmbeddingModel == "" {
+		return filter, false
+	}
+	compatible := len(a.byModel[filter.EmbeddingModel]) + len(a.byModel[unnamedEmbeddingModelKey])
+	total := a.index.Len()
+	if total > 0 && compatible == 0 {
+		return filter, true
+	}
+	if compatible >= total {
+		filter.EmbeddingModel = ""
+	}
+	return filter, false
+}
+
+// filterCandidatesLocked 返回过滤条件对应的最小候选集合；无过滤条件时 narrowed 为 false。
+func (a *historicalCaseANNIndex) filterCandidatesLocked(filter SimilarCaseRecallFilter) (map[string]struct{}, bool) {
+	var smallest map[string]struct{}
+	narrowed := false
+	if filter.TargetGroup != "" {
+		smallest = a.byTargetGroup[filter.TargetGroup]
+		narrowed = true
+	}
+	if filter.ScamType != "" {
+		candidates := a.byScamType[filter.ScamType]
+		if !narrowed || len(candidates) < len(smallest) {
+			smallest = candidates
+		}
+		narrowed = true
+	}
+	return smallest, narrowed
+}
+
+// applyHistoricalCaseVectorIndexChange 在本进程写缓存后增量维护索引。
+// previousVersion 为写入前读到的版本号：与索引版本一致说明期间没有其他实例写入，可直接增量更新并采用新版本号；
+// 否则标记索引失效，下次检索时整体重建。
+func applyHistoricalCaseVectorIndexChange(previousVersion string, nextVersion string, mutate func(a *historicalCaseANNIndex)) {
+	ann := historicalCaseVectorIndex
+	ann.mu.Lock()
+	defer ann.mu.Unlock()
+
+	if !ann.ready {
+		return
+	}
+	if previousVersion == "" || previousVersion != ann.version || nextVersion == "" {
+		ann.ready = false
+		return
+	}
+	mutate(ann)
+	ann.version = nextVersion
+}
+
+// touchHistoricalCaseVectorIndexVersion 刷新 Redis 中的索引版本号，返回写入前后的版本号；失败时返回空串。
+func touchHistoricalCaseVectorIndexVersion() (string, string) {
+	previous, _ := currentHistoricalCaseVectorIndexVersion()
+	next := newHistoricalCaseVectorIndexVersion()
+	if err := historicalCaseVectorIndexVersionSet(historicalCaseVectorIndexVersionKey, next, 0); err != nil {
+		log.Printf("[case_library] touch vector index version failed: %v", err)
+		return previous, ""
+	}
+	return previous, next
+}
+
+func currentHistoricalCaseVectorIndexVersion() (string, bool) {
+	var version string
+	found, err := historicalCaseVectorIndexVersionGet(historicalCaseVectorIndexVersionKey, &version)
+	if err != nil {
+		return "", false
+	}
+	if !found {
+		return "", true
+	}
+	return strings.TrimSpace(version), true
+}
+
+func newHistoricalCaseVectorIndexVersion() string {
+	return fmt.Sprintf("%d", time.Now().UnixNano())
+}
+
+// SelfCheckHistoricalCaseVectorIndexRecall 以索引内案件向量为查询抽样，对比 ANN 与精确扫描的 top-k 重合率。
+// samples/topK 非正时使用默认值；索引未就绪时先按当前缓存重建。
+func SelfCheckHistoricalCaseVectorIndexRecall(samples int, topK int) (HistoricalCaseVectorIndexRecallResult, error) {
+	if samples <= 0 {
+		samples = defaultVectorIndexRecallSamples
+	}
+	appliedTopK := normalizeTopK(topK)
+	result := HistoricalCaseVectorIndexRecallResult{CheckedAt: time.Now(), TopK: appliedTopK}
+
+	version, ok := currentHistoricalCaseVectorIndexVersion()
+	if !ok {
+		return result, fmt.Errorf("read vector index version failed")
+	}
+
+	ann := historicalCaseVectorIndex
+	ann.mu.Lock()
+	defer ann.mu.Unlock()
+	if !ann.ready || ann.version != version {
+		if err := ann.rebuildLocked(version); err != nil {
+			return result, err
+		}
+	}
+
+	result.Ready = true
+	result.IndexSize = ann.index.Len()
+	if result.IndexSize == 0 {
+		result.Recall = 1
+		result.Healthy = true
+		return result, nil
+	}
+
+	caseIDs := ann.index.IDs()
+	step := 1
+	if len(caseIDs) > samples {
+		step = len(caseIDs) / samples
+	}
+	missed := map[string]struct{}{}
+	var recallSum float64
+	for i := 0; i < len(caseIDs) && result.Samples < samples; i += step {
+		query, ok := ann.index.Vector(caseIDs[i])
+		if !ok {
+			continue
+		}
+		exact := ann.index.Exact(query, appliedTopK, nil)
+		approx := ann.index.Search(query, appliedTopK, nil)
+		approxIDs := make(map[string]struct{}, len(approx))
+		for _, hit := range approx {
+			approxIDs[hit.ID] = struct{}{}
+		}
+		matched := 0
+		for _, hit := range exact {
+			if _, ok := approxIDs[hit.ID]; ok {
+				matched++
+				continue
+			}
+			missed[hit.ID] = struct{}{}
+		}
+		if len(exact) > 0 {
+			recallSum += float64(matched) / float64(len(exact))
+		} else {
+			recallSum++
+		}
+		result.Samples++
+	}
+
+	if result.Samples > 0 {
+		result.Recall = recallSum / float64(result.Samples)
+	}
+	result.Healthy = result.Recall >= minHealthyVectorIndexRecall
+	for caseID := range missed {
+		result.MissedCaseIDs = append(result.MissedCaseIDs, caseID)
+	}
+	sort.Strings(result.MissedCaseIDs)
+	if len(result.MissedCaseIDs) > 20 {
+		result.MissedCaseIDs = result.MissedCaseIDs[:20]
+	}
+	return result, nil
+}
+
+func addToStringSet(sets map[string]map[string]struct{}, key string, value string) {
+	trimmedKey := strings.TrimSpace(key)
+	if trimmedKey == "" {
+		return
+	}
+	set, ok := sets[trimmedKey]
+	if !ok {
+		set = map[string]struct{}{}
+		sets[trimmedKey] = set
+	}
+	set[value] = struct{}{}
+}
+
+func removeFromStringSet(sets map[string]map[string]struct{}, key string, value string) {
+	trimmedKey := strings.TrimSpace(key)
+	set, ok := sets[trimmedKey]
+	if !ok {
+		return
+	}
+	delete(set, value)
+	if len(set) == 0 {
+		delete(sets, trimmedKey)
+	}
+}
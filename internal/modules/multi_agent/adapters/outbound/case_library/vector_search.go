@@ -2,6 +2,7 @@ package case_library
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"math"
@@ -11,6 +12,7 @@ import (
 
 	"antifraud/internal/platform/cache"
 	"antifraud/internal/platform/database"
+	"antifraud/internal/platform/embedding"
 )
 
 const (
@@ -33,6 +35,12 @@ var (
 	historicalCaseVectorCacheHashDelete  = cache.HashDelete
 )
 
+// historicalCaseQueryEmbeddingModel 返回未显式指定模型时查询向量所属的模型（即当前固定的 embedding 模型）。
+var historicalCaseQueryEmbeddingModel = embedding.ActiveModel
+
+// ErrEmbeddingModelMismatch 表示案件库中没有与查询向量同一模型的向量，拒绝跨模型比较。
+var ErrEmbeddingModelMismatch = errors.New("embedding model mismatch: historical case library has no vectors of the query model")
+
 // SimilarCaseResult represents one ranked case from vector search.
 type SimilarCaseResult struct {
 	CaseID          string
@@ -48,9 +56,12 @@ type SimilarCaseResult struct {
 }
 
 // SimilarCaseRecallFilter defines optional exact-match filters for vector recall.
+// EmbeddingModel is the model of the query vector; records embedded by another model are
+// never compared against it. Empty means the currently pinned embedding model.
 type SimilarCaseRecallFilter struct {
-	TargetGroup string
-	ScamType    string
+	TargetGroup    string
+	ScamType       string
+	EmbeddingModel string
 }
 
 // QueryAllHistoricalCases keeps the full database query behavior for non-search callers.
@@ -121,21 +132,34 @@ func SearchTopKSimilarCasesByVectorWithConditions(queryVector []float64, topK in
 
 // SearchTopKSimilarCasesByVectorWithFilter executes cosine similarity search from
 // distributed Redis cache with optional exact-match filters.
+// The in-process HNSW index (see vector_index.go) is used when its version matches Redis;
+// otherwise it falls back to a brute-force scan over the Redis snapshot.
 func SearchTopKSimilarCasesByVectorWithFilter(queryVector []float64, topK int, filter SimilarCaseRecallFilter) ([]SimilarCaseResult, int, error) {
 	normalizedQuery, ok := normalizeL2Vector(queryVector)
 	if !ok {
 		return nil, 0, fmt.Errorf("query embedding vector is empty or invalid")
 	}
 
+	appliedTopK := normalizeTopK(topK)
+	filter = resolveQueryEmbeddingModel(filter)
+	if results, mismatched, ok := searchHistoricalCaseVectorIndex(normalizedQuery, appliedTopK, filter); ok {
+		if mismatched {
+			return nil, appliedTopK, fmt.Errorf("%w: model=%s", ErrEmbeddingModelMismatch, filter.EmbeddingModel)
+		}
+		return results, appliedTopK, nil
+	}
+
 	cases, err := snapshotHistoricalCaseVectorCache()
 	if err != nil {
 		return nil, 0, err
 	}
 
-	appliedTopK := normalizeTopK(topK)
 	if len(cases) == 0 {
 		return []SimilarCaseResult{}, appliedTopK, nil
 	}
+	if !containsEmbeddingModel(cases, filter.EmbeddingModel) {
+		return nil, appliedTopK, fmt.Errorf("%w: model=%s", ErrEmbeddingModelMismatch, filter.EmbeddingModel)
+	}
 
 	results := collectSimilarCaseResults(normalizedQuery, cases, normalizeSimilarCaseRecallFilter(filter))
 	sortSimilarCaseResults(results)
@@ -158,22 +182,26 @@ func collectSimilarCaseResults(normalizedQuery []float64, cases []HistoricalCase
 		}
 
 		sim := cosineSimilarityNormalized(normalizedQuery, normalizedCaseVector)
-		results = append(results, SimilarCaseResult{
-			CaseID:          item.CaseID,
-			Title:           item.Title,
-			TargetGroup:     item.TargetGroup,
-			RiskLevel:       item.RiskLevel,
-			ScamType:        item.ScamType,
-			CaseDescription: item.CaseDescription,
-			Keywords:        append([]string{}, item.Keywords...),
-			ViolatedLaw:     item.ViolatedLaw,
-			Similarity:      sim,
-			CreatedAt:       item.CreatedAt,
-		})
+		results = append(results, similarCaseResultFromRecord(item, sim))
 	}
 	return results
 }
 
+func similarCaseResultFromRecord(item HistoricalCaseRecord, similarity float64) SimilarCaseResult {
+	return SimilarCaseResult{
+		CaseID:          item.CaseID,
+		Title:           item.Title,
+		TargetGroup:     item.TargetGroup,
+		RiskLevel:       item.RiskLevel,
+		ScamType:        item.ScamType,
+		CaseDescription: item.CaseDescription,
+		Keywords:        append([]string{}, item.Keywords...),
+		ViolatedLaw:     item.ViolatedLaw,
+		Similarity:      similarity,
+		CreatedAt:       item.CreatedAt,
+	}
+}
+
 func sortSimilarCaseResults(results []SimilarCaseResult) {
 	sort.Slice(results, func(i, j int) bool {
 		if math.Abs(results[i].Similarity-results[j].Similarity) > 1e-12 {
@@ -185,11 +213,22 @@ func sortSimilarCaseResults(results []SimilarCaseResult) {
 
 func normalizeSimilarCaseRecallFilter(filter SimilarCaseRecallFilter) SimilarCaseRecallFilter {
 	return SimilarCaseRecallFilter{
-		TargetGroup: strings.TrimSpace(filter.TargetGroup),
-		ScamType:    strings.TrimSpace(filter.ScamType),
+		TargetGroup:    strings.TrimSpace(filter.TargetGroup),
+		ScamType:       strings.TrimSpace(filter.ScamType),
+		EmbeddingModel: strings.TrimSpace(filter.EmbeddingModel),
 	}
 }
 
+// resolveQueryEmbeddingModel 规范化过滤条件，并在未指定查询模型时补上当前固定的 embedding 模型。
+func resolveQueryEmbeddingModel(filter SimilarCaseRecallFilter) SimilarCaseRecallFilter {
+	normalized := normalizeSimilarCaseRecallFilter(filter)
+	if normalized.EmbeddingModel == "" {
+		normalized.EmbeddingModel = strings.TrimSpace(historicalCaseQueryEmbeddingModel())
+	}
+	return normalized
+}
+
+// matchSimilarCaseRecallFilter 判断记录是否满足过滤条件；未记录模型名的旧数据视为与任意模型兼容。
 func matchSimilarCaseRecallFilter(record HistoricalCaseRecord, filter SimilarCaseRecallFilter) bool {
 	if filter.TargetGroup != "" && strings.TrimSpace(record.TargetGroup) != filter.TargetGroup {
 		return false
@@ -197,9 +236,30 @@ func matchSimilarCaseRecallFilter(record HistoricalCaseRecord, filter SimilarCas
 	if filter.ScamType != "" && strings.TrimSpace(record.ScamType) != filter.ScamType {
 		return false
 	}
+	if !embeddingModelCompatible(record.EmbeddingModel, filter.EmbeddingModel) {
+		return false
+	}
 	return true
 }
 
+func embeddingModelCompatible(recordModel string, queryModel string) bool {
+	trimmedRecordModel := strings.TrimSpace(recordModel)
+	return queryModel == "" || trimmedRecordModel == "" || trimmedRecordModel == queryModel
+}
+
+// containsEmbeddingModel 判断快照中是否存在可与 model 比较的记录；快照为空或未指定模型时视为存在。
+func containsEmbeddingModel(cases []HistoricalCaseRecord, model string) bool {
+	if model == "" || len(cases) == 0 {
+		return true
+	}
+	for _, item := range cases {
+		if embeddingModelCompatible(item.EmbeddingModel, model) {
+			return true
+		}
+	}
+	return false
+}
+
 func snapshotHistoricalCaseVectorCache() ([]HistoricalCaseRecord, error) {
 	records, ready, err := loadHistoricalCaseVectorCacheFromRedis()
 	if err != nil {
@@ -322,6 +382,7 @@ func replaceHistoricalCaseVectorCache(records []HistoricalCaseRecord) error {
 	if err := historicalCaseVectorCacheReadySet(historicalCaseVectorCacheReadyKey, true, 0); err != nil {
 		return err
 	}
+	touchHistoricalCaseVectorIndexVersion()
 	return nil
 }
 
@@ -343,6 +404,10 @@ func upsertHistoricalCaseVectorCache(record HistoricalCaseRecord) {
 		log.Printf("[case_library] upsert vector cache failed: case_id=%s err=%v", trimmedCaseID, err)
 		return
 	}
+	previousVersion, nextVersion := touchHistoricalCaseVectorIndexVersion()
+	applyHistoricalCaseVectorIndexChange(previousVersion, nextVersion, func(a *historicalCaseANNIndex) {
+		a.upsertLocked(normalized)
+	})
 	if err := historicalCaseVectorCacheReadySet(historicalCaseVectorCacheReadyKey, true, 0); err != nil {
 		log.Printf("[case_library] mark vector cache ready failed: case_id=%s err=%v", trimmedCaseID, err)
 	}
@@ -364,6 +429,10 @@ func removeHistoricalCaseVectorCache(caseID string) {
 		log.Printf("[case_library] remove vector cache failed: case_id=%s err=%v", trimmedCaseID, err)
 		return
 	}
+	previousVersion, nextVersion := touchHistoricalCaseVectorIndexVersion()
+	applyHistoricalCaseVectorIndexChange(previousVersion, nextVersion, func(a *historicalCaseANNIndex) {
+		a.removeLocked(trimmedCaseID)
+	})
 	if err := historicalCaseVectorCacheReadySet(historicalCaseVectorCacheReadyKey, true, 0); err != nil {
 		log.Printf("[case_library] mark vector cache ready failed: case_id=%s err=%v", trimmedCaseID, err)
 	}
@@ -403,6 +472,16 @@ func WarmupHistoricalCaseVectorCache() error {
 			len(report.MismatchedCaseIDs),
 		)
 	}
+
+	recall, err := SelfCheckHistoricalCaseVectorIndexRecall(0, 0)
+	if err != nil {
+		log.Printf("[case_library] vector index recall self-check skipped: %v", err)
+		return nil
+	}
+	if !recall.Healthy {
+		log.Printf("[case_library] vector index recall below threshold: recall=%.3f samples=%d size=%d missed=%v",
+			recall.Recall, recall.Samples, recall.IndexSize, recall.MissedCaseIDs)
+	}
 	return nil
 }
 
@@ -427,9 +506,12 @@ func cloneHistoricalCaseRecord(record HistoricalCaseRecord) HistoricalCaseRecord
 		Keywords:           append([]string{}, record.Keywords...),
 		ViolatedLaw:        strings.TrimSpace(record.ViolatedLaw),
 		Suggestion:         strings.TrimSpace(record.Suggestion),
+		Citations:          append([]CaseCitation{}, record.Citations...),
 		EmbeddingVector:    append([]float64{}, record.EmbeddingVector...),
 		EmbeddingModel:     strings.TrimSpace(record.EmbeddingModel),
 		EmbeddingDimension: record.EmbeddingDimension,
+		Revision:           normalizeHistoricalCaseRevision(record.Revision),
+		UpdatedBy:          strings.TrimSpace(firstNonEmpty(record.UpdatedBy, record.CreatedBy)),
 		CreatedAt:          record.CreatedAt,
 		UpdatedAt:          record.UpdatedAt,
 	}
@@ -0,0 +1,470 @@
+package mediastore
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"log"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"antifraud/internal/modules/multi_agent/adapters/outbound/blobstore"
+	appcfg "antifraud/internal/platform/config"
+	"antifraud/internal/platform/database"
+
+	"gorm.io/gorm"
+)
+
+// MediaRefPrefix 是任务输入中引用已上传媒体的前缀，形如 media:MEDIA-XXXX。
+const MediaRefPrefix = "media:"
+
+const (
+	// janitorInterval 是过期媒体与上传会话的清理周期。
+	janitorInterval = time.Hour
+	// blobOwnerMedia 是媒体在 blob_references 中登记引用时使用的所有者类型。
+	blobOwnerMedia = "media"
+)
+
+var (
+	ErrMediaNotFound    = errors.New("media not found")
+	ErrMediaTooLarge    = errors.New("media exceeds size limit")
+	ErrInvalidMedia     = errors.New("invalid media")
+	ErrChecksumMismatch = errors.New("media checksum mismatch")
+)
+
+// storeDB 返回媒体元数据所在的主业务库。
+var storeDB = func() *gorm.DB { return database.DB }
+
+// mediaEntity 记录上传媒体的归属与文件信息，内容本身按 SHA256 保存在 blob 存储中。
+type mediaEntity struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement"`
+	MediaID   string    `gorm:"size:64;uniqueIndex;not null"`
+	UserID    string    `gorm:"size:64;index;not null"`
+	FileName  string    `gorm:"size:255"`
+	MIME      string    `gorm:"size:128;not null"`
+	Size      int64     `gorm:"not null"`
+	SHA256    string    `gorm:"size:64;not null"`
+	CreatedAt time.Time `gorm:"index"`
+}
+
+func (mediaEntity) TableName() string {
+	return "media_blobs"
+}
+
+func init() {
+	database.RegisterMainDBSchemaInitializer("media_store", initStoreSchema)
+}
+
+func initStoreSchema(db *gorm.DB) error {
+	if db == nil {
+		return fmt.Errorf("media store schema db is nil")
+	}
+	return db.AutoMigrate(&mediaEntity{}, &uploadSessionEntity{}, &uploadChunkEntity{})
+}
+
+// Media 是一份已上传媒体的元数据，内容以 SHA256 为键保存在 blob 存储中，媒体存续期间持有该 blob 的一次引用。
+type Media struct {
+	MediaID   string
+	UserID    string
+	FileName  string
+	MIME      string
+	Size      int64
+	SHA256    string
+	CreatedAt time.Time
+}
+
+// Options 是媒体存储参数：Dir 为分片上传的暂存目录，MaxFileBytes 为单个文件上限，ChunkBytes 为分片上传的分片大小，
+// SessionTTL 为上传会话有效期，Retention 为媒体文件保留时长。
+type Options struct {
+	Dir          string
+	MaxFileBytes int64
+	ChunkBytes   int64
+	SessionTTL   time.Duration
+	Retention    time.Duration
+}
+
+// OptionsFromConfig 将配置文件中的 media_store 转换为存储参数。
+func OptionsFromConfig(cfg appcfg.MediaStoreConfig) Options {
+	return Options{
+		Dir:          cfg.Dir,
+		MaxFileBytes: int64(cfg.MaxFileMB) * 1024 * 1024,
+		ChunkBytes:   int64(cfg.ChunkSizeKB) * 1024,
+		SessionTTL:   time.Duration(cfg.SessionTTLMinutes) * time.Minute,
+		Retention:    time.Duration(cfg.RetentionHours) * time.Hour,
+	}
+}
+
+// Store 是上传媒体的归属与分片会话管理：合并后的内容写入 blob 存储（与任务载荷共用同一份内容），
+// 元数据与分片上传会话记录在主业务库，分片在合并前暂存于 Dir。
+// 多模态分析请求通过 media:<id> 引用已上传的媒体，入队时换成 blob:<sha256> 引用，避免把大文件以 base64 塞进 JSON。
+type Store struct {
+	options Options
+
+	// completeMu 串行化分片合并，避免同一会话被重复合并。
+	completeMu sync.Mutex
+}
+
+var (
+	defaultStoreMu sync.Mutex
+	defaultStore   *Store
+)
+
+// NewStore 创建媒体存储，未设置的参数使用默认值。
+func NewStore(options Options) *Store {
+	options.Dir = strings.TrimSpace(options.Dir)
+	if options.Dir == "" {
+		options.Dir = "DB/media"
+	}
+	options.Dir = filepath.Clean(options.Dir)
+	if options.MaxFileBytes <= 0 {
+		options.MaxFileBytes = 200 * 1024 * 1024
+	}
+	if options.ChunkBytes <= 0 {
+		options.ChunkBytes = 4 * 1024 * 1024
+	}
+	if options.ChunkBytes > options.MaxFileBytes {
+		options.ChunkBytes = options.MaxFileBytes
+	}
+	if options.SessionTTL <= 0 {
+		options.SessionTTL = 24 * time.Hour
+	}
+	if options.Retention <= 0 {
+		options.Retention = 72 * time.Hour
+	}
+	return &Store{options: options}
+}
+
+// DefaultStore 返回按 media_store 配置创建的进程级媒体存储。
+func DefaultStore() *Store {
+	defaultStoreMu.Lock()
+	defer defaultStoreMu.Unlock()
+	if defaultStore == nil {
+		options := Options{}
+		if cfg, err := appcfg.LoadConfig("internal/platform/config/config.json"); err == nil && cfg != nil {
+			options = OptionsFromConfig(cfg.MediaStore)
+		}
+		defaultStore = NewStore(options)
+	}
+	return defaultStore
+}
+
+// SetDefaultStore 替换进程级媒体存储并返回原存储，供测试与自定义目录使用。
+func SetDefaultStore(store *Store) *Store {
+	defaultStoreMu.Lock()
+	defer defaultStoreMu.Unlock()
+	previous := defaultStore
+	defaultStore = store
+	return previous
+}
+
+// Options 返回存储当前生效的参数。
+func (s *Store) Options() Options {
+	return s.options
+}
+
+// MediaRef 返回任务输入中引用媒体的字符串。
+func MediaRef(mediaID string) string {
+	return MediaRefPrefix + mediaID
+}
+
+// BlobRef 返回媒体内容在 blob 存储中的引用，任务载荷以此引用媒体而无需读取内容。
+func (m Media) BlobRef() string {
+	return blobstore.Ref(m.SHA256)
+}
+
+// ParseMediaRef 解析 media:<id> 引用，不是引用时返回 false。
+func ParseMediaRef(value string) (string, bool) {
+	trimmed := strings.TrimSpace(value)
+	if !strings.HasPrefix(trimmed, MediaRefPrefix) {
+		return "", false
+	}
+	mediaID := strings.TrimSpace(strings.TrimPrefix(trimmed, MediaRefPrefix))
+	return mediaID, mediaID != ""
+}
+
+// Put 以流式方式写入一个媒体文件，超过单文件上限时返回 ErrMediaTooLarge。
+// mimeType 为空或为 application/octet-stream 时按文件头推断。
+func (s *Store) Put(userID string, fileName string, mimeType string, reader io.Reader) (Media, error) {
+	return s.writeMedia(userID, fileName, mimeType, reader, "")
+}
+
+// Get 返回当前用户的媒体元数据，媒体不存在或不属于该用户时返回 ErrMediaNotFound。
+func (s *Store) Get(userID string, mediaID string) (Media, error) {
+	entity, err := s.loadMedia(mediaID)
+	if err != nil {
+		return Media{}, err
+	}
+	if entity.UserID != strings.TrimSpace(userID) {
+		return Media{}, ErrMediaNotFound
+	}
+	return toMedia(entity), nil
+}
+
+// ReadAll 从 blob 存储读取媒体内容，不校验归属；调用方需先通过 Get 确认媒体属于当前用户。
+func (s *Store) ReadAll(mediaID string) (Media, []byte, error) {
+	entity, err := s.loadMedia(mediaID)
+	if err != nil {
+		return Media{}, nil, err
+	}
+	_, raw, err := blobstore.DefaultStore().ReadAll(context.Background(), entity.SHA256)
+	if err != nil {
+		if errors.Is(err, blobstore.ErrBlobNotFound) {
+			return Media{}, nil, ErrMediaNotFound
+		}
+		return Media{}, nil, fmt.Errorf("读取媒体内容失败: %w", err)
+	}
+	return toMedia(entity), raw, nil
+}
+
+// Delete 删除当前用户的媒体并释放其 blob 引用，返回 false 表示媒体不存在或不属于该用户。
+// 仍被任务或历史记录引用的内容不受影响，引用全部释放后由 blob 清理任务回收。
+func (s *Store) Delete(userID string, mediaID string) (bool, error) {
+	db := storeDB()
+	if db == nil {
+		return false, fmt.Errorf("media store db is nil")
+	}
+	entity, err := s.loadMedia(mediaID)
+	if errors.Is(err, ErrMediaNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if entity.UserID != strings.TrimSpace(userID) {
+		return false, nil
+	}
+	return deleteMedia(db, entity)
+}
+
+// PurgeExpired 删除超过保留时长的媒体与已过期的上传会话，返回删除的媒体数与会话数。
+func (s *Store) PurgeExpired(now time.Time) (int, int, error) {
+	db := storeDB()
+	if db == nil {
+		return 0, 0, fmt.Errorf("media store db is nil")
+	}
+
+	var expiredMedia []mediaEntity
+	if err := db.Where("created_at < ?", now.Add(-s.options.Retention)).Find(&expiredMedia).Error; err != nil {
+		return 0, 0, err
+	}
+	for _, entity := range expiredMedia {
+		if _, err := deleteMedia(db, entity); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	var expiredSessions []uploadSessionEntity
+	if err := db.Where("expires_at < ?", now).Find(&expiredSessions).Error; err != nil {
+		return len(expiredMedia), 0, err
+	}
+	for _, session := range expiredSessions {
+		if err := s.removeSession(db, session); err != nil {
+			return len(expiredMedia), 0, err
+		}
+	}
+	return len(expiredMedia), len(expiredSessions), nil
+}
+
+// StartJanitor 立即并按固定周期清理过期媒体与上传会话，ctx 取消后停止。
+func (s *Store) StartJanitor(ctx context.Context) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	go func() {
+		ticker := time.NewTicker(janitorInterval)
+		defer ticker.Stop()
+		for {
+			media, sessions, err := s.PurgeExpired(time.Now())
+			if err != nil {
+				log.Printf("[media_store] purge expired media failed: %v", err)
+			} else if media > 0 || sessions > 0 {
+				log.Printf("[media_store] purged expired media=%d upload_sessions=%d", media, sessions)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// writeMedia 把 reader 流式写入 blob 存储，写入过程中校验大小上限与摘要，校验失败时不保留任何内容；
+// 写入成功后在同一事务内创建媒体元数据并登记对 blob 的引用。
+func (s *Store) writeMedia(userID string, fileName string, mimeType string, reader io.Reader, expectedSHA256 string) (Media, error) {
+	userID = strings.TrimSpace(userID)
+	if userID == "" {
+		return Media{}, fmt.Errorf("%w: 缺少上传用户", ErrInvalidMedia)
+	}
+	db := storeDB()
+	if db == nil {
+		return Media{}, fmt.Errorf("media store db is nil")
+	}
+	if err := blobstore.EnsureSchema(db); err != nil {
+		return Media{}, err
+	}
+
+	// 先读出文件头推断 MIME，blob 元数据需要在写入时确定类型。
+	head := make([]byte, 512)
+	headSize, err := io.ReadFull(reader, head)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return Media{}, fmt.Errorf("写入媒体文件失败: %w", err)
+	}
+	if headSize == 0 {
+		return Media{}, fmt.Errorf("%w: 媒体内容为空", ErrInvalidMedia)
+	}
+	head = head[:headSize]
+	resolvedMIME := resolveMIME(mimeType, head)
+	guard := &guardedReader{
+		reader:   io.MultiReader(bytes.NewReader(head), reader),
+		limit:    s.options.MaxFileBytes,
+		hasher:   sha256.New(),
+		expected: strings.ToLower(strings.TrimSpace(expectedSHA256)),
+	}
+	blob, err := blobstore.DefaultStore().PutReader(context.Background(), resolvedMIME, guard)
+	if guard.err != nil {
+		return Media{}, guard.err
+	}
+	if err != nil {
+		return Media{}, fmt.Errorf("写入媒体文件失败: %w", err)
+	}
+
+	entity := mediaEntity{
+		MediaID:   newID("MEDIA-"),
+		UserID:    userID,
+		FileName:  truncateFileName(fileName),
+		MIME:      resolvedMIME,
+		Size:      blob.Size,
+		SHA256:    blob.Key,
+		CreatedAt: time.Now(),
+	}
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&entity).Error; err != nil {
+			return err
+		}
+		return blobstore.DefaultStore().SetReferences(tx, blobOwnerMedia, entity.MediaID, []string{entity.SHA256})
+	}); err != nil {
+		return Media{}, err
+	}
+	return toMedia(entity), nil
+}
+
+// deleteMedia 在同一事务内删除媒体元数据并释放其 blob 引用，返回 false 表示媒体已被并发删除。
+func deleteMedia(db *gorm.DB, entity mediaEntity) (bool, error) {
+	if err := blobstore.EnsureSchema(db); err != nil {
+		return false, err
+	}
+	deleted := false
+	err := db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Delete(&mediaEntity{}, entity.ID)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		deleted = true
+		return blobstore.DefaultStore().SetReferences(tx, blobOwnerMedia, entity.MediaID, nil)
+	})
+	return deleted && err == nil, err
+}
+
+func (s *Store) loadMedia(mediaID string) (mediaEntity, error) {
+	db := storeDB()
+	if db == nil {
+		return mediaEntity{}, fmt.Errorf("media store db is nil")
+	}
+	var entity mediaEntity
+	err := db.Where("media_id = ?", strings.TrimSpace(mediaID)).Take(&entity).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return mediaEntity{}, ErrMediaNotFound
+	}
+	if err != nil {
+		return mediaEntity{}, err
+	}
+	return entity, nil
+}
+
+func toMedia(entity mediaEntity) Media {
+	return Media{
+		MediaID:   entity.MediaID,
+		UserID:    entity.UserID,
+		FileName:  entity.FileName,
+		MIME:      entity.MIME,
+		Size:      entity.Size,
+		SHA256:    entity.SHA256,
+		CreatedAt: entity.CreatedAt,
+	}
+}
+
+// guardedReader 在读取过程中累计大小与摘要：超过上限或读到结尾时摘要不符即返回错误，
+// 使 blob 存储放弃本次写入；err 记录触发的校验错误，便于调用方原样返回。
+type guardedReader struct {
+	reader   io.Reader
+	limit    int64
+	read     int64
+	hasher   hash.Hash
+	expected string
+	err      error
+}
+
+func (g *guardedReader) Read(p []byte) (int, error) {
+	if g.err != nil {
+		return 0, g.err
+	}
+	n, err := g.reader.Read(p)
+	g.read += int64(n)
+	g.hasher.Write(p[:n])
+	if g.read > g.limit {
+		g.err = fmt.Errorf("%w: 超过 %d 字节", ErrMediaTooLarge, g.limit)
+		return n, g.err
+	}
+	if errors.Is(err, io.EOF) && g.expected != "" {
+		if digest := hex.EncodeToString(g.hasher.Sum(nil)); digest != g.expected {
+			g.err = fmt.Errorf("%w: 期望 %s，实际 %s", ErrChecksumMismatch, g.expected, digest)
+			return n, g.err
+		}
+	}
+	return n, err
+}
+
+func resolveMIME(declared string, head []byte) string {
+	mimeType := strings.ToLower(strings.TrimSpace(declared))
+	if index := strings.Index(mimeType, ";"); index >= 0 {
+		mimeType = strings.TrimSpace(mimeType[:index])
+	}
+	if mimeType != "" && mimeType != "application/octet-stream" {
+		return mimeType
+	}
+	sniffed := http.DetectContentType(head)
+	if index := strings.Index(sniffed, ";"); index >= 0 {
+		sniffed = sniffed[:index]
+	}
+	return sniffed
+}
+
+func truncateFileName(fileName string) string {
+	name := filepath.Base(strings.TrimSpace(strings.ReplaceAll(fileName, "\\", "/")))
+	if name == "." || name == "/" {
+		return ""
+	}
+	runes := []rune(name)
+	if len(runes) > 200 {
+		runes = runes[:200]
+	}
+	return string(runes)
+}
+
+func newID(prefix string) string {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return fmt.Sprintf("%s%d", prefix, time.Now().UnixNano())
+	}
+	return prefix + strings.ToUpper(hex.EncodeToString(buf))
+}
@@ -0,0 +1,206 @@
+package mediastore_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"antifraud/internal/modules/multi_agent/adapters/outbound/blobstore"
+	"antifraud/internal/modules/multi_agent/adapters/outbound/mediastore"
+	"antifraud/internal/platform/database"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func setupMediaStore(t *testing.T, options mediastore.Options) (*mediastore.Store, *blobstore.Store) {
+	t.Helper()
+
+	oldDB := database.DB
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "media_store_test.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open sqlite failed: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("get sql db failed: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	database.DB = db
+	if err := database.InitMainDBSchemas(); err != nil {
+		t.Fatalf("init main db schemas failed: %v", err)
+	}
+	blobBackend, err := blobstore.NewLocalBackend(t.TempDir())
+	if err != nil {
+		t.Fatalf("create blob backend failed: %v", err)
+	}
+	blobs := blobstore.NewStore(blobBackend, blobstore.Options{})
+	previousBlobs := blobstore.SetDefaultStore(blobs)
+	t.Cleanup(func() {
+		blobstore.SetDefaultStore(previousBlobs)
+		database.DB = oldDB
+		_ = sqlDB.Close()
+	})
+
+	options.Dir = t.TempDir()
+	return mediastore.NewStore(options), blobs
+}
+
+func TestMediaStore_ChunkedUploadResumesAndVerifiesChecksum(t *testing.T) {
+	store, _ := setupMediaStore(t, mediastore.Options{MaxFileBytes: 1024, ChunkBytes: 4})
+	content := []byte("0123456789")
+	digest := sha256.Sum256(content)
+
+	session, err := store.CreateUpload("u-1", "../clip.mp4", "video/mp4", int64(len(content)))
+	if err != nil {
+		t.Fatalf("create upload failed: %v", err)
+	}
+	if session.TotalChunks != 3 || session.ChunkSize != 4 || session.FileName != "clip.mp4" || session.Status != mediastore.UploadStatusUploading {
+		t.Fatalf("unexpected upload session: %+v", session)
+	}
+
+	if _, err := store.PutChunk("u-1", session.UploadID, 2, bytes.NewReader(content[8:])); err != nil {
+		t.Fatalf("put last chunk failed: %v", err)
+	}
+	if _, err := store.PutChunk("u-1", session.UploadID, 0, bytes.NewReader(content[:3])); !errors.Is(err, mediastore.ErrInvalidChunk) {
+		t.Fatalf("expected short chunk rejected, got %v", err)
+	}
+	if _, err := store.PutChunk("u-1", session.UploadID, 3, bytes.NewReader(content[:4])); !errors.Is(err, mediastore.ErrInvalidChunk) {
+		t.Fatalf("expected out of range chunk rejected, got %v", err)
+	}
+	if _, err := store.PutChunk("u-2", session.UploadID, 0, bytes.NewReader(content[:4])); !errors.Is(err, mediastore.ErrUploadNotFound) {
+		t.Fatalf("expected other user's upload hidden, got %v", err)
+	}
+	if _, err := store.CompleteUpload("u-1", session.UploadID, ""); !errors.Is(err, mediastore.ErrUploadIncomplete) {
+		t.Fatalf("expected incomplete upload error, got %v", err)
+	}
+
+	// 模拟断线重连：查询进度后只补传缺失分片，重复分片可安全重传。
+	progress, err := store.GetUpload("u-1", session.UploadID)
+	if err != nil || len(progress.ReceivedChunks) != 1 || progress.ReceivedChunks[0] != 2 || progress.ReceivedBytes != 2 {
+		t.Fatalf("unexpected upload progress: %+v err=%v", progress, err)
+	}
+	for index := 0; index < 2; index++ {
+		if _, err := store.PutChunk("u-1", session.UploadID, index, bytes.NewReader(content[index*4:index*4+4])); err != nil {
+			t.Fatalf("put chunk %d failed: %v", index, err)
+		}
+	}
+	if _, err := store.PutChunk("u-1", session.UploadID, 1, bytes.NewReader(content[4:8])); err != nil {
+		t.Fatalf("re-put chunk failed: %v", err)
+	}
+
+	if _, err := store.CompleteUpload("u-1", session.UploadID, strings.Repeat("0", 64)); !errors.Is(err, mediastore.ErrChecksumMismatch) {
+		t.Fatalf("expected checksum mismatch, got %v", err)
+	}
+	media, err := store.CompleteUpload("u-1", session.UploadID, strings.ToUpper(hex.EncodeToString(digest[:])))
+	if err != nil {
+		t.Fatalf("complete upload failed: %v", err)
+	}
+	if media.Size != int64(len(content)) || media.MIME != "video/mp4" || media.SHA256 != hex.EncodeToString(digest[:]) {
+		t.Fatalf("unexpected media: %+v", media)
+	}
+	again, err := store.CompleteUpload("u-1", session.UploadID, "")
+	if err != nil || again.MediaID != media.MediaID {
+		t.Fatalf("expected idempotent completion, got %+v err=%v", again, err)
+	}
+	if _, err := store.PutChunk("u-1", session.UploadID, 0, bytes.NewReader(content[:4])); !errors.Is(err, mediastore.ErrUploadCompleted) {
+		t.Fatalf("expected completed upload to reject chunks, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(store.Options().Dir, "uploads", session.UploadID)); !os.IsNotExist(err) {
+		t.Fatalf("expected chunk directory removed after completion, stat err=%v", err)
+	}
+
+	_, raw, err := store.ReadAll(media.MediaID)
+	if err != nil || !bytes.Equal(raw, content) {
+		t.Fatalf("expected merged content, got %q err=%v", raw, err)
+	}
+	if _, err := store.Get("u-2", media.MediaID); !errors.Is(err, mediastore.ErrMediaNotFound) {
+		t.Fatalf("expected media hidden from other users, got %v", err)
+	}
+}
+
+func TestMediaStore_PutEnforcesLimitAndSniffsMIME(t *testing.T) {
+	store, blobs := setupMediaStore(t, mediastore.Options{MaxFileBytes: 16, ChunkBytes: 8})
+
+	if _, err := store.Put("u-1", "big.bin", "", bytes.NewReader(make([]byte, 17))); !errors.Is(err, mediastore.ErrMediaTooLarge) {
+		t.Fatalf("expected size limit error, got %v", err)
+	}
+	if _, err := store.CreateUpload("u-1", "big.bin", "", 17); !errors.Is(err, mediastore.ErrMediaTooLarge) {
+		t.Fatalf("expected upload session size limit error, got %v", err)
+	}
+	if _, err := store.Put("u-1", "empty.png", "", bytes.NewReader(nil)); !errors.Is(err, mediastore.ErrInvalidMedia) {
+		t.Fatalf("expected empty media rejected, got %v", err)
+	}
+	if swept, err := blobs.SweepOrphans(t.Context(), time.Now().Add(24*time.Hour)); err != nil || swept != 0 {
+		t.Fatalf("expected rejected uploads leave no blobs, swept=%d err=%v", swept, err)
+	}
+
+	png := []byte("\x89PNG\r\n\x1a\n0000")
+	media, err := store.Put("u-1", "shot.png", "application/octet-stream", bytes.NewReader(png))
+	if err != nil {
+		t.Fatalf("put media failed: %v", err)
+	}
+	if media.MIME != "image/png" || media.BlobRef() != blobstore.Ref(media.SHA256) {
+		t.Fatalf("expected sniffed mime and blob ref, got %+v", media)
+	}
+	if blob, err := blobs.Stat(media.SHA256); err != nil || blob.MIME != "image/png" || blob.RefCount != 1 {
+		t.Fatalf("expected media content stored as referenced blob, got %+v err=%v", blob, err)
+	}
+	if deleted, err := store.Delete("u-2", media.MediaID); err != nil || deleted {
+		t.Fatalf("expected other user unable to delete, deleted=%v err=%v", deleted, err)
+	}
+	if deleted, err := store.Delete("u-1", media.MediaID); err != nil || !deleted {
+		t.Fatalf("delete media failed: deleted=%v err=%v", deleted, err)
+	}
+	if _, _, err := store.ReadAll(media.MediaID); !errors.Is(err, mediastore.ErrMediaNotFound) {
+		t.Fatalf("expected deleted media missing, got %v", err)
+	}
+	if blob, err := blobs.Stat(media.SHA256); err != nil || blob.RefCount != 0 {
+		t.Fatalf("expected delete to release blob reference, got %+v err=%v", blob, err)
+	}
+}
+
+func TestMediaStore_PurgeExpiredRemovesStaleMediaAndSessions(t *testing.T) {
+	store, blobs := setupMediaStore(t, mediastore.Options{MaxFileBytes: 64, ChunkBytes: 8, SessionTTL: time.Minute, Retention: time.Hour})
+
+	media, err := store.Put("u-1", "a.mp3", "audio/mpeg", strings.NewReader("audio"))
+	if err != nil {
+		t.Fatalf("put media failed: %v", err)
+	}
+	session, err := store.CreateUpload("u-1", "b.mp4", "video/mp4", 16)
+	if err != nil {
+		t.Fatalf("create upload failed: %v", err)
+	}
+	if _, err := store.PutChunk("u-1", session.UploadID, 0, strings.NewReader("01234567")); err != nil {
+		t.Fatalf("put chunk failed: %v", err)
+	}
+
+	if mediaCount, sessionCount, err := store.PurgeExpired(time.Now()); err != nil || mediaCount != 0 || sessionCount != 0 {
+		t.Fatalf("expected nothing purged yet, media=%d sessions=%d err=%v", mediaCount, sessionCount, err)
+	}
+	mediaCount, sessionCount, err := store.PurgeExpired(time.Now().Add(2 * time.Hour))
+	if err != nil || mediaCount != 1 || sessionCount != 1 {
+		t.Fatalf("expected stale media and session purged, media=%d sessions=%d err=%v", mediaCount, sessionCount, err)
+	}
+	if _, err := store.Get("u-1", media.MediaID); !errors.Is(err, mediastore.ErrMediaNotFound) {
+		t.Fatalf("expected purged media missing, got %v", err)
+	}
+	if blob, err := blobs.Stat(media.SHA256); err != nil || blob.RefCount != 0 {
+		t.Fatalf("expected purge to release blob reference, got %+v err=%v", blob, err)
+	}
+	if _, err := store.GetUpload("u-1", session.UploadID); !errors.Is(err, mediastore.ErrUploadNotFound) {
+		t.Fatalf("expected purged session missing, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(store.Options().Dir, "uploads", session.UploadID)); !os.IsNotExist(err) {
+		t.Fatalf("expected purged chunk directory removed, stat err=%v", err)
+	}
+}
@@ -0,0 +1,353 @@
+package mediastore
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	UploadStatusUploading = "uploading"
+	UploadStatusCompleted = "completed"
+)
+
+var (
+	ErrUploadNotFound   = errors.New("upload session not found")
+	ErrUploadExpired    = errors.New("upload session expired")
+	ErrUploadCompleted  = errors.New("upload session already completed")
+	ErrUploadIncomplete = errors.New("upload session has missing chunks")
+	ErrInvalidChunk     = errors.New("invalid upload chunk")
+)
+
+type uploadSessionEntity struct {
+	ID          uint      `gorm:"primaryKey;autoIncrement"`
+	UploadID    string    `gorm:"size:64;uniqueIndex;not null"`
+	UserID      string    `gorm:"size:64;index;not null"`
+	FileName    string    `gorm:"size:255"`
+	MIME        string    `gorm:"size:128"`
+	TotalSize   int64     `gorm:"not null"`
+	ChunkSize   int64     `gorm:"not null"`
+	TotalChunks int       `gorm:"not null"`
+	Status      string    `gorm:"size:32;index;not null"`
+	MediaID     string    `gorm:"size:64"`
+	ExpiresAt   time.Time `gorm:"index;not null"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+func (uploadSessionEntity) TableName() string {
+	return "media_upload_sessions"
+}
+
+type uploadChunkEntity struct {
+	ID         uint   `gorm:"primaryKey;autoIncrement"`
+	UploadID   string `gorm:"size:64;not null;uniqueIndex:idx_media_upload_chunk"`
+	ChunkIndex int    `gorm:"not null;uniqueIndex:idx_media_upload_chunk"`
+	Size       int64  `gorm:"not null"`
+	CreatedAt  time.Time
+}
+
+func (uploadChunkEntity) TableName() string {
+	return "media_upload_chunks"
+}
+
+// UploadSession 是一次可续传的分片上传。客户端按 ChunkSize 切分文件，分片序号从 0 开始，
+// 除最后一片外每片大小必须等于 ChunkSize；断线后查询 ReceivedChunks 只补传缺失的分片。
+type UploadSession struct {
+	UploadID       string
+	UserID         string
+	FileName       string
+	MIME           string
+	TotalSize      int64
+	ChunkSize      int64
+	TotalChunks    int
+	ReceivedChunks []int
+	ReceivedBytes  int64
+	Status         string
+	MediaID        string
+	ExpiresAt      time.Time
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// CreateUpload 创建分片上传会话，totalSize 超过单文件上限时返回 ErrMediaTooLarge。
+func (s *Store) CreateUpload(userID string, fileName string, mimeType string, totalSize int64) (UploadSession, error) {
+	userID = strings.TrimSpace(userID)
+	if userID == "" {
+		return UploadSession{}, fmt.Errorf("%w: 缺少上传用户", ErrInvalidMedia)
+	}
+	if totalSize <= 0 {
+		return UploadSession{}, fmt.Errorf("%w: total_size 必须大于 0", ErrInvalidMedia)
+	}
+	if totalSize > s.options.MaxFileBytes {
+		return UploadSession{}, fmt.Errorf("%w: 超过 %d 字节", ErrMediaTooLarge, s.options.MaxFileBytes)
+	}
+	db := storeDB()
+	if db == nil {
+		return UploadSession{}, fmt.Errorf("media store db is nil")
+	}
+
+	now := time.Now()
+	entity := uploadSessionEntity{
+		UploadID:    newID("UPLOAD-"),
+		UserID:      userID,
+		FileName:    truncateFileName(fileName),
+		MIME:        strings.TrimSpace(mimeType),
+		TotalSize:   totalSize,
+		ChunkSize:   s.options.ChunkBytes,
+		TotalChunks: int((totalSize + s.options.ChunkBytes - 1) / s.options.ChunkBytes),
+		Status:      UploadStatusUploading,
+		ExpiresAt:   now.Add(s.options.SessionTTL),
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := db.Create(&entity).Error; err != nil {
+		return UploadSession{}, err
+	}
+	return toUploadSession(entity, nil), nil
+}
+
+// GetUpload 返回当前用户的上传会话及已接收分片。
+func (s *Store) GetUpload(userID string, uploadID string) (UploadSession, error) {
+	db := storeDB()
+	if db == nil {
+		return UploadSession{}, fmt.Errorf("media store db is nil")
+	}
+	entity, err := loadUploadSession(db, userID, uploadID)
+	if err != nil {
+		return UploadSession{}, err
+	}
+	chunks, err := listUploadChunks(db, entity.UploadID)
+	if err != nil {
+		return UploadSession{}, err
+	}
+	return toUploadSession(entity, chunks), nil
+}
+
+// PutChunk 写入第 index 个分片；重复上传同一分片会覆盖旧内容，便于断线后安全重试。
+func (s *Store) PutChunk(userID string, uploadID string, index int, reader io.Reader) (UploadSession, error) {
+	db := storeDB()
+	if db == nil {
+		return UploadSession{}, fmt.Errorf("media store db is nil")
+	}
+	entity, err := loadUploadSession(db, userID, uploadID)
+	if err != nil {
+		return UploadSession{}, err
+	}
+	if err := checkUploadWritable(entity, time.Now()); err != nil {
+		return UploadSession{}, err
+	}
+	if index < 0 || index >= entity.TotalChunks {
+		return UploadSession{}, fmt.Errorf("%w: 分片序号需在 0-%d 之间", ErrInvalidChunk, entity.TotalChunks-1)
+	}
+	expected := entity.ChunkSize
+	if index == entity.TotalChunks-1 {
+		expected = entity.TotalSize - int64(entity.TotalChunks-1)*entity.ChunkSize
+	}
+
+	uploadDir := s.uploadDir(entity.UploadID)
+	if err := os.MkdirAll(uploadDir, 0o755); err != nil {
+		return UploadSession{}, fmt.Errorf("创建分片目录失败: %w", err)
+	}
+	temp, err := os.CreateTemp(uploadDir, strconv.Itoa(index)+".*.tmp")
+	if err != nil {
+		return UploadSession{}, fmt.Errorf("创建分片文件失败: %w", err)
+	}
+	tempPath := temp.Name()
+	defer os.Remove(tempPath)
+	written, err := io.Copy(temp, io.LimitReader(reader, expected+1))
+	closeErr := temp.Close()
+	if err != nil {
+		return UploadSession{}, fmt.Errorf("写入分片失败: %w", err)
+	}
+	if closeErr != nil {
+		return UploadSession{}, fmt.Errorf("写入分片失败: %w", closeErr)
+	}
+	if written != expected {
+		return UploadSession{}, fmt.Errorf("%w: 分片 %d 应为 %d 字节，实际收到 %d 字节", ErrInvalidChunk, index, expected, written)
+	}
+	if err := os.Rename(tempPath, s.chunkPath(entity.UploadID, index)); err != nil {
+		return UploadSession{}, fmt.Errorf("保存分片失败: %w", err)
+	}
+
+	chunk := uploadChunkEntity{UploadID: entity.UploadID, ChunkIndex: index, Size: written, CreatedAt: time.Now()}
+	if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&chunk).Error; err != nil {
+		return UploadSession{}, err
+	}
+	if err := db.Model(&uploadSessionEntity{}).Where("id = ?", entity.ID).Update("updated_at", time.Now()).Error; err != nil {
+		return UploadSession{}, err
+	}
+	return s.GetUpload(userID, uploadID)
+}
+
+// CompleteUpload 校验分片齐全后按序合并为媒体文件；expectedSHA256 非空时校验合并结果的摘要。
+// 已完成的会话再次调用直接返回同一媒体，便于客户端在响应丢失后重试。
+func (s *Store) CompleteUpload(userID string, uploadID string, expectedSHA256 string) (Media, error) {
+	s.completeMu.Lock()
+	defer s.completeMu.Unlock()
+
+	db := storeDB()
+	if db == nil {
+		return Media{}, fmt.Errorf("media store db is nil")
+	}
+	entity, err := loadUploadSession(db, userID, uploadID)
+	if err != nil {
+		return Media{}, err
+	}
+	if entity.Status == UploadStatusCompleted {
+		return s.Get(userID, entity.MediaID)
+	}
+	if err := checkUploadWritable(entity, time.Now()); err != nil {
+		return Media{}, err
+	}
+	chunks, err := listUploadChunks(db, entity.UploadID)
+	if err != nil {
+		return Media{}, err
+	}
+	if missing := entity.TotalChunks - len(chunks); missing > 0 {
+		return Media{}, fmt.Errorf("%w: 缺少 %d 个分片", ErrUploadIncomplete, missing)
+	}
+
+	files := make([]*os.File, 0, entity.TotalChunks)
+	defer func() {
+		for _, file := range files {
+			_ = file.Close()
+		}
+	}()
+	readers := make([]io.Reader, 0, entity.TotalChunks)
+	for index := 0; index < entity.TotalChunks; index++ {
+		file, err := os.Open(s.chunkPath(entity.UploadID, index))
+		if err != nil {
+			return Media{}, fmt.Errorf("读取分片 %d 失败: %w", index, err)
+		}
+		files = append(files, file)
+		readers = append(readers, file)
+	}
+	media, err := s.writeMedia(userID, entity.FileName, entity.MIME, io.MultiReader(readers...), expectedSHA256)
+	if err != nil {
+		return Media{}, err
+	}
+
+	result := db.Model(&uploadSessionEntity{}).
+		Where("id = ? AND status = ?", entity.ID, UploadStatusUploading).
+		Updates(map[string]interface{}{
+			"status":     UploadStatusCompleted,
+			"media_id":   media.MediaID,
+			"updated_at": time.Now(),
+		})
+	if result.Error != nil || result.RowsAffected == 0 {
+		_, _ = s.Delete(userID, media.MediaID)
+		if result.Error != nil {
+			return Media{}, result.Error
+		}
+		return Media{}, ErrUploadCompleted
+	}
+	if err := db.Where("upload_id = ?", entity.UploadID).Delete(&uploadChunkEntity{}).Error; err != nil {
+		log.Printf("[media_store] clear upload chunks failed: upload_id=%s err=%v", entity.UploadID, err)
+	}
+	if err := os.RemoveAll(s.uploadDir(entity.UploadID)); err != nil {
+		log.Printf("[media_store] remove upload dir failed: upload_id=%s err=%v", entity.UploadID, err)
+	}
+	return media, nil
+}
+
+// AbortUpload 取消上传会话并删除已接收的分片；已合并的媒体不受影响。
+func (s *Store) AbortUpload(userID string, uploadID string) error {
+	db := storeDB()
+	if db == nil {
+		return fmt.Errorf("media store db is nil")
+	}
+	entity, err := loadUploadSession(db, userID, uploadID)
+	if err != nil {
+		return err
+	}
+	return s.removeSession(db, entity)
+}
+
+func (s *Store) removeSession(db *gorm.DB, entity uploadSessionEntity) error {
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("upload_id = ?", entity.UploadID).Delete(&uploadChunkEntity{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&uploadSessionEntity{}, entity.ID).Error
+	}); err != nil {
+		return err
+	}
+	if err := os.RemoveAll(s.uploadDir(entity.UploadID)); err != nil {
+		log.Printf("[media_store] remove upload dir failed: upload_id=%s err=%v", entity.UploadID, err)
+	}
+	return nil
+}
+
+func (s *Store) uploadDir(uploadID string) string {
+	return filepath.Join(s.options.Dir, "uploads", filepath.Base(uploadID))
+}
+
+func (s *Store) chunkPath(uploadID string, index int) string {
+	return filepath.Join(s.uploadDir(uploadID), strconv.Itoa(index)+".part")
+}
+
+func checkUploadWritable(entity uploadSessionEntity, now time.Time) error {
+	if entity.Status == UploadStatusCompleted {
+		return ErrUploadCompleted
+	}
+	if now.After(entity.ExpiresAt) {
+		return ErrUploadExpired
+	}
+	return nil
+}
+
+func loadUploadSession(db *gorm.DB, userID string, uploadID string) (uploadSessionEntity, error) {
+	var entity uploadSessionEntity
+	err := db.Where("upload_id = ? AND user_id = ?", strings.TrimSpace(uploadID), strings.TrimSpace(userID)).Take(&entity).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return uploadSessionEntity{}, ErrUploadNotFound
+	}
+	if err != nil {
+		return uploadSessionEntity{}, err
+	}
+	return entity, nil
+}
+
+func listUploadChunks(db *gorm.DB, uploadID string) ([]uploadChunkEntity, error) {
+	var chunks []uploadChunkEntity
+	if err := db.Where("upload_id = ?", uploadID).Order("chunk_index ASC").Find(&chunks).Error; err != nil {
+		return nil, err
+	}
+	return chunks, nil
+}
+
+func toUploadSession(entity uploadSessionEntity, chunks []uploadChunkEntity) UploadSession {
+	session := UploadSession{
+		UploadID:       entity.UploadID,
+		UserID:         entity.UserID,
+		FileName:       entity.FileName,
+		MIME:           entity.MIME,
+		TotalSize:      entity.TotalSize,
+		ChunkSize:      entity.ChunkSize,
+		TotalChunks:    entity.TotalChunks,
+		ReceivedChunks: make([]int, 0, len(chunks)),
+		Status:         entity.Status,
+		MediaID:        entity.MediaID,
+		ExpiresAt:      entity.ExpiresAt,
+		CreatedAt:      entity.CreatedAt,
+		UpdatedAt:      entity.UpdatedAt,
+	}
+	for _, chunk := range chunks {
+		session.ReceivedChunks = append(session.ReceivedChunks, chunk.ChunkIndex)
+		session.ReceivedBytes += chunk.Size
+	}
+	if entity.Status == UploadStatusCompleted {
+		session.ReceivedBytes = entity.TotalSize
+	}
+	return session
+}
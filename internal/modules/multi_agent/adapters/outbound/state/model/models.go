@@ -2,15 +2,119 @@ package model
 
 import "time"
 
+// 内置模态名，与 TaskPayload 中的专用字段一一对应。
+const (
+	ModalityImage = "image"
+	ModalityVideo = "video"
+	ModalityAudio = "audio"
+)
+
 // TaskPayload 保存任务原始输入和各子模态解读结果。
+// image/video/audio 使用专用字段；其他通过 ModalityAnalyzer 注册的模态（如 pdf、url）
+// 以模态名为 key 存放在 ExtraInputs/ExtraInsights 中，新增模态无需修改本结构。
 type TaskPayload struct {
-	Text          string   `json:"text"`
-	Videos        []string `json:"videos"`
-	Audios        []string `json:"audios"`
-	Images        []string `json:"images"`
-	VideoInsights []string `json:"video_insights,omitempty"`
-	AudioInsights []string `json:"audio_insights,omitempty"`
-	ImageInsights []string `json:"image_insights,omitempty"`
+	Text          string              `json:"text"`
+	Videos        []string            `json:"videos"`
+	Audios        []string            `json:"audios"`
+	Images        []string            `json:"images"`
+	VideoInsights []string            `json:"video_insights,omitempty"`
+	AudioInsights []string            `json:"audio_insights,omitempty"`
+	ImageInsights []string            `json:"image_insights,omitempty"`
+	ExtraInputs   map[string][]string `json:"extra_inputs,omitempty"`
+	ExtraInsights map[string][]string `json:"extra_insights,omitempty"`
+}
+
+// ModalityInputs 按模态名读取原始输入，返回拷贝。
+func (p TaskPayload) ModalityInputs(modality string) []string {
+	switch modality {
+	case ModalityImage:
+		return append([]string{}, p.Images...)
+	case ModalityVideo:
+		return append([]string{}, p.Videos...)
+	case ModalityAudio:
+		return append([]string{}, p.Audios...)
+	}
+	return append([]string{}, p.ExtraInputs[modality]...)
+}
+
+// SetModalityInputs 按模态名写入原始输入；空列表会移除扩展模态的 key。
+func (p *TaskPayload) SetModalityInputs(modality string, items []string) {
+	switch modality {
+	case ModalityImage:
+		p.Images = append([]string{}, items...)
+	case ModalityVideo:
+		p.Videos = append([]string{}, items...)
+	case ModalityAudio:
+		p.Audios = append([]string{}, items...)
+	default:
+		p.ExtraInputs = setModalityList(p.ExtraInputs, modality, items)
+	}
+}
+
+// ModalityInsights 按模态名读取子智能体解读结果，返回拷贝。
+func (p TaskPayload) ModalityInsights(modality string) []string {
+	switch modality {
+	case ModalityImage:
+		return append([]string{}, p.ImageInsights...)
+	case ModalityVideo:
+		return append([]string{}, p.VideoInsights...)
+	case ModalityAudio:
+		return append([]string{}, p.AudioInsights...)
+	}
+	return append([]string{}, p.ExtraInsights[modality]...)
+}
+
+// SetModalityInsights 按模态名写入子智能体解读结果；空列表会移除扩展模态的 key。
+func (p *TaskPayload) SetModalityInsights(modality string, items []string) {
+	switch modality {
+	case ModalityImage:
+		p.ImageInsights = append([]string{}, items...)
+	case ModalityVideo:
+		p.VideoInsights = append([]string{}, items...)
+	case ModalityAudio:
+		p.AudioInsights = append([]string{}, items...)
+	default:
+		p.ExtraInsights = setModalityList(p.ExtraInsights, modality, items)
+	}
+}
+
+// Clone 返回深拷贝，避免调用方共享底层切片与 map。
+func (p TaskPayload) Clone() TaskPayload {
+	return TaskPayload{
+		Text:          p.Text,
+		Videos:        append([]string{}, p.Videos...),
+		Audios:        append([]string{}, p.Audios...),
+		Images:        append([]string{}, p.Images...),
+		VideoInsights: append([]string{}, p.VideoInsights...),
+		AudioInsights: append([]string{}, p.AudioInsights...),
+		ImageInsights: append([]string{}, p.ImageInsights...),
+		ExtraInputs:   CloneModalityLists(p.ExtraInputs),
+		ExtraInsights: CloneModalityLists(p.ExtraInsights),
+	}
+}
+
+// CloneModalityLists 深拷贝按模态名分组的列表；结果为空时返回 nil，保持 omitempty 生效。
+func CloneModalityLists(source map[string][]string) map[string][]string {
+	var cloned map[string][]string
+	for modality, items := range source {
+		cloned = setModalityList(cloned, modality, items)
+	}
+	return cloned
+}
+
+func setModalityList(target map[string][]string, modality string, items []string) map[string][]string {
+	if len(items) == 0 {
+		delete(target, modality)
+		if len(target) == 0 {
+			return nil
+		}
+		return target
+	}
+	if target == nil {
+		target = map[string][]string{}
+	}
+	target[modality] = append([]string{}, items...)
+	return target
 }
 
 // TaskRecord 表示“任务视角”的统一记录模型。
@@ -22,6 +126,7 @@ type TaskRecord struct {
 	ScamType    string      `json:"scam_type,omitempty"`
 	RiskScore   int         `json:"risk_score,omitempty"`
 	RiskSummary string      `json:"risk_summary,omitempty"`
+	RuleVersion string      `json:"rule_version,omitempty"`
 	CreatedAt   time.Time   `json:"created_at"`
 	UpdatedAt   time.Time   `json:"updated_at"`
 	Payload     TaskPayload `json:"payload"`
@@ -29,6 +134,11 @@ type TaskRecord struct {
 	Report      string      `json:"report,omitempty"`
 	Error       string      `json:"error,omitempty"`
 	HistoryRef  string      `json:"history_ref,omitempty"`
+	Attempts    int         `json:"attempts,omitempty"`
+	// MediaPurgedAt 为历史案件原始媒体按保留策略被清除的时间。
+	MediaPurgedAt *time.Time `json:"media_purged_at,omitempty"`
+	// Indicators 为子智能体完成后抽取的结构化线索，抽取前为空。
+	Indicators []Indicator `json:"indicators,omitempty"`
 }
 
 // CaseHistoryRecord 表示“历史案件视角”的归档记录模型。
@@ -42,9 +152,61 @@ type CaseHistoryRecord struct {
 	RiskLevel   string      `json:"risk_level"`
 	RiskScore   int         `json:"risk_score,omitempty"`
 	RiskSummary string      `json:"risk_summary,omitempty"`
+	RuleVersion string      `json:"rule_version,omitempty"`
 	CreatedAt   time.Time   `json:"created_at"`
 	Payload     TaskPayload `json:"payload"`
 	Report      string      `json:"report,omitempty"`
+	// RiskBreakdown 为归档时的完整评分明细，早期记录为空。
+	RiskBreakdown *RiskBreakdown `json:"risk_breakdown,omitempty"`
+	// MediaPurgedAt 为原始媒体按保留策略被清除的时间，解读与报告仍保留。
+	MediaPurgedAt *time.Time `json:"media_purged_at,omitempty"`
+	// Indicators 为从文本、ASR 转写与各模态解读中确定性抽取的结构化线索，早期记录为空。
+	Indicators []Indicator `json:"indicators,omitempty"`
+}
+
+// RiskBreakdown 记录一次风险评分的完整依据，用于事后解释"为什么是这个风险等级"。
+type RiskBreakdown struct {
+	Score               int                   `json:"score"`
+	RuleVersion         string                `json:"rule_version,omitempty"`
+	Dimensions          map[string]int        `json:"dimensions,omitempty"`
+	HitFactors          []RiskFactorHit       `json:"hit_factors,omitempty"`
+	HitRules            []string              `json:"hit_rules,omitempty"`
+	KeyEvidence         []string              `json:"key_evidence,omitempty"`
+	VictimActionStage   string                `json:"victim_action_stage,omitempty"`
+	SimilarCaseStrength string                `json:"similar_case_strength,omitempty"`
+	MultimodalEvidence  string                `json:"multimodal_evidence,omitempty"`
+	Dynamic             *DynamicRiskBreakdown `json:"dynamic,omitempty"`
+}
+
+// RiskFactorHit 是命中的单个风险因子及其计分。
+type RiskFactorHit struct {
+	Key       string `json:"key"`
+	Label     string `json:"label"`
+	Dimension string `json:"dimension"`
+	Weight    int    `json:"weight"`
+}
+
+// DynamicRiskBreakdown 记录动态阈值判级过程：历史分推导的阈值，以及知识库/用户历史相似命中带来的加减分。
+type DynamicRiskBreakdown struct {
+	BaseScore               int    `json:"base_score"`
+	HistoricalScore         int    `json:"historical_score"`
+	DynamicThreshold        int    `json:"dynamic_threshold"`
+	KnowledgeBaseHit        string `json:"knowledge_base_hit"`
+	KnowledgeBaseAdjustment int    `json:"knowledge_base_adjustment"`
+	UserHistoryHit          string `json:"user_history_hit"`
+	UserHistoryAdjustment   int    `json:"user_history_adjustment"`
+	AdjustedScore           int    `json:"adjusted_score"`
+	RiskLevel               string `json:"risk_level"`
+}
+
+// Indicator 是一条结构化线索（电话号码、链接、域名、收款账号、收款码内容、App 名称、微信号、QQ 号等）。
+// 电话号码与银行卡号与其余落库内容一致保存为任务内脱敏占位符，Fingerprint 为原值归一后的 SHA-256，
+// 可用于跨案件关联同一线索而不暴露原文。Sources 为线索出现的来源（text 或模态名），按首次出现排序。
+type Indicator struct {
+	Kind        string   `json:"kind"`
+	Value       string   `json:"value"`
+	Fingerprint string   `json:"fingerprint,omitempty"`
+	Sources     []string `json:"sources,omitempty"`
 }
 
 // UserStateView 是用户维度的聚合视图模型。
@@ -54,6 +216,16 @@ type UserStateView struct {
 	History []CaseHistoryRecord   `json:"history"`
 }
 
+// TaskProgressEvent 表示任务执行过程中的阶段事件，用于实时进度推送。
+type TaskProgressEvent struct {
+	ID        uint64                 `json:"id"`
+	TaskID    string                 `json:"task_id"`
+	Stage     string                 `json:"stage"`
+	Message   string                 `json:"message"`
+	Data      map[string]interface{} `json:"data,omitempty"`
+	CreatedAt time.Time              `json:"created_at"`
+}
+
 // PendingTaskEntity 是 pending_tasks 表的 ORM 映射实体。
 type PendingTaskEntity struct {
 	TaskID string `gorm:"primaryKey;size:64"`
@@ -68,11 +240,22 @@ type PendingTaskEntity struct {
 	PayloadVideoInsights string `gorm:"type:text"`
 	PayloadAudioInsights string `gorm:"type:text"`
 	PayloadImageInsights string `gorm:"type:text"`
+	// 扩展模态的输入与解读，JSON 对象字符串（key 为模态名）。
+	PayloadExtraInputs   string `gorm:"type:text"`
+	PayloadExtraInsights string `gorm:"type:text"`
+	// Indicators 为子智能体完成后抽取的结构化线索（[]Indicator 的 JSON 编码），归档时随任务写入历史。
+	Indicators string `gorm:"type:text"`
 
 	Report     string `gorm:"type:text"`
 	Error      string `gorm:"type:text"`
 	HistoryRef string `gorm:"size:64"`
 
+	// 队列租约字段：worker 领取任务后持有租约并定期心跳续期，租约过期的任务会被重新入队。
+	LeaseOwner     string     `gorm:"size:96;index"`
+	LeaseExpiresAt *time.Time `gorm:"index"`
+	HeartbeatAt    *time.Time
+	Attempts       int `gorm:"default:0;not null"`
+
 	CreatedAt time.Time `gorm:"index;not null"`
 	UpdatedAt time.Time `gorm:"index;not null"`
 }
@@ -92,6 +275,12 @@ type HistoryCaseEntity struct {
 	RiskLevel   string `gorm:"size:32;index"`
 	RiskScore   int    `gorm:"default:0"`
 	RiskSummary string `gorm:"type:text"`
+	// RuleVersion 为评分规则集版本，未经评分工具归档的记录为空。
+	RuleVersion string `gorm:"size:64;index"`
+	// RiskBreakdown 为 RiskBreakdown 的 JSON 编码。
+	RiskBreakdown string `gorm:"type:text"`
+	// Indicators 为 []Indicator 的 JSON 编码。
+	Indicators string `gorm:"type:text"`
 
 	PayloadText          string `gorm:"type:text"`
 	PayloadVideos        string `gorm:"type:text"`
@@ -100,8 +289,13 @@ type HistoryCaseEntity struct {
 	PayloadVideoInsights string `gorm:"type:text"`
 	PayloadAudioInsights string `gorm:"type:text"`
 	PayloadImageInsights string `gorm:"type:text"`
+	// 扩展模态的输入与解读，JSON 对象字符串（key 为模态名）。
+	PayloadExtraInputs   string `gorm:"type:text"`
+	PayloadExtraInsights string `gorm:"type:text"`
 
 	Report string `gorm:"type:text"`
+	// MediaPurgedAt 为保留策略清除原始媒体的时间，未清除时为空。
+	MediaPurgedAt *time.Time `gorm:"index"`
 
 	CreatedAt time.Time `gorm:"index;not null"`
 	UpdatedAt time.Time `gorm:"index;not null"`
@@ -110,3 +304,34 @@ type HistoryCaseEntity struct {
 func (HistoryCaseEntity) TableName() string {
 	return "history_cases"
 }
+
+// TaskProgressEventEntity 是 task_progress_events 表的 ORM 映射实体。
+// 自增 ID 同时作为 SSE 事件 ID，客户端断线重连时据此续读。
+type TaskProgressEventEntity struct {
+	ID      uint64 `gorm:"primaryKey;autoIncrement"`
+	TaskID  string `gorm:"size:64;index;not null"`
+	UserID  string `gorm:"index;not null"`
+	Stage   string `gorm:"size:64;not null"`
+	Message string `gorm:"size:255"`
+	Data    string `gorm:"type:text"`
+
+	CreatedAt time.Time `gorm:"index;not null"`
+}
+
+func (TaskProgressEventEntity) TableName() string {
+	return "task_progress_events"
+}
+
+// TaskRedactionMappingEntity 是 task_redaction_mappings 表的 ORM 映射实体。
+// Mapping 为占位符到原文的 JSON 对象，是任务内唯一保存敏感原文的位置，随历史案件一同删除。
+type TaskRedactionMappingEntity struct {
+	TaskID  string `gorm:"primaryKey;size:64"`
+	UserID  string `gorm:"index;not null"`
+	Mapping string `gorm:"type:text"`
+
+	UpdatedAt time.Time `gorm:"not null"`
+}
+
+func (TaskRedactionMappingEntity) TableName() string {
+	return "task_redaction_mappings"
+}
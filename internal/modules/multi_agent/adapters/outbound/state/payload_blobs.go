@@ -0,0 +1,333 @@
+package state
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"antifraud/internal/modules/multi_agent/adapters/outbound/blobstore"
+
+	"gorm.io/gorm"
+)
+
+const (
+	blobOwnerPendingTask = "pending_task"
+	blobOwnerHistoryCase = "history_case"
+)
+
+// inlineMediaMigrationBatchSize 是迁移内联媒体时每批读取的行数。
+const inlineMediaMigrationBatchSize = 50
+
+// externalizePayloadMedia 把载荷中内联的 base64 data URL 写入 blob 存储并替换为 blob:<sha256> 引用。
+// 说明：
+// 1) 只处理原始输入（视频/音频/图片与扩展模态输入），解读文本不变；
+// 2) 单项写入失败时保留内联内容并记录日志，不阻断任务落库。
+func externalizePayloadMedia(payload TaskPayload) TaskPayload {
+	if !hasInlinePayloadMedia(payload) {
+		return payload
+	}
+	store := blobstore.DefaultStore()
+	payload.Videos = externalizeMediaList(store, payload.Videos)
+	payload.Audios = externalizeMediaList(store, payload.Audios)
+	payload.Images = externalizeMediaList(store, payload.Images)
+	if len(payload.ExtraInputs) > 0 {
+		inputs := make(map[string][]string, len(payload.ExtraInputs))
+		for modality, items := range payload.ExtraInputs {
+			inputs[modality] = externalizeMediaList(store, items)
+		}
+		payload.ExtraInputs = inputs
+	}
+	return payload
+}
+
+func externalizeMediaList(store *blobstore.Store, items []string) []string {
+	if len(items) == 0 {
+		return items
+	}
+	result := make([]string, 0, len(items))
+	for _, item := range items {
+		mimeType, raw, ok := parseInlineMedia(item)
+		if !ok {
+			result = append(result, item)
+			continue
+		}
+		blob, err := store.Put(context.Background(), mimeType, raw)
+		if err != nil {
+			log.Printf("[state] externalize inline media failed, keep inline: err=%v", err)
+			result = append(result, item)
+			continue
+		}
+		result = append(result, blobstore.Ref(blob.Key))
+	}
+	return result
+}
+
+// hydratePayloadMedia 把载荷中的 blob 引用还原为 data URL，供分析智能体直接消费。
+// 入队时保留为引用的已上传媒体也在这里才读取内容；类型无法识别的视频按 video/mp4 交给分析智能体。
+func hydratePayloadMedia(payload TaskPayload) (TaskPayload, error) {
+	if len(payloadBlobKeys(payload)) == 0 {
+		return payload, nil
+	}
+	store := blobstore.DefaultStore()
+	var err error
+	if payload.Videos, err = hydrateMediaList(store, payload.Videos, "video/mp4"); err != nil {
+		return payload, err
+	}
+	if payload.Audios, err = hydrateMediaList(store, payload.Audios, ""); err != nil {
+		return payload, err
+	}
+	if payload.Images, err = hydrateMediaList(store, payload.Images, ""); err != nil {
+		return payload, err
+	}
+	if len(payload.ExtraInputs) > 0 {
+		inputs := make(map[string][]string, len(payload.ExtraInputs))
+		for modality, items := range payload.ExtraInputs {
+			if inputs[modality], err = hydrateMediaList(store, items, ""); err != nil {
+				return payload, err
+			}
+		}
+		payload.ExtraInputs = inputs
+	}
+	return payload, nil
+}
+
+func hydrateMediaList(store *blobstore.Store, items []string, fallbackMIME string) ([]string, error) {
+	if len(items) == 0 {
+		return items, nil
+	}
+	result := make([]string, 0, len(items))
+	for _, item := range items {
+		key, ok := blobstore.ParseRef(item)
+		if !ok {
+			result = append(result, item)
+			continue
+		}
+		blob, raw, err := store.ReadAll(context.Background(), key)
+		if err != nil {
+			return nil, fmt.Errorf("read blob %s failed: %w", key, err)
+		}
+		mimeType := blob.MIME
+		if fallbackMIME != "" && (mimeType == "" || mimeType == "application/octet-stream") {
+			mimeType = fallbackMIME
+		}
+		result = append(result, fmt.Sprintf("data:%s;base64,%s", mimeType, base64.StdEncoding.EncodeToString(raw)))
+	}
+	return result, nil
+}
+
+// PayloadBlobKeys 返回载荷原始输入中引用的全部 blob key（去重、排序）。
+func PayloadBlobKeys(payload TaskPayload) []string {
+	return payloadBlobKeys(payload)
+}
+
+func payloadBlobKeys(payload TaskPayload) []string {
+	seen := make(map[string]struct{})
+	collect := func(items []string) {
+		for _, item := range items {
+			if key, ok := blobstore.ParseRef(item); ok {
+				seen[key] = struct{}{}
+			}
+		}
+	}
+	collect(payload.Videos)
+	collect(payload.Audios)
+	collect(payload.Images)
+	for _, items := range payload.ExtraInputs {
+		collect(items)
+	}
+	keys := make([]string, 0, len(seen))
+	for key := range seen {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// entityMediaPayload 从表实体的媒体列解码原始输入，用于计算引用与迁移，不涉及解读等其他列。
+func entityMediaPayload(videos, audios, images, extraInputs string) TaskPayload {
+	return TaskPayload{
+		Videos:      decodeStringList(videos),
+		Audios:      decodeStringList(audios),
+		Images:      decodeStringList(images),
+		ExtraInputs: decodeModalityLists(extraInputs),
+	}
+}
+
+func hasInlinePayloadMedia(payload TaskPayload) bool {
+	lists := [][]string{payload.Videos, payload.Audios, payload.Images}
+	for _, items := range payload.ExtraInputs {
+		lists = append(lists, items)
+	}
+	for _, items := range lists {
+		for _, item := range items {
+			if _, _, ok := parseInlineMedia(item); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// parseInlineMedia 解析 base64 data URL，非 data URL 或解码失败时返回 false。
+func parseInlineMedia(item string) (string, []byte, bool) {
+	trimmed := strings.TrimSpace(item)
+	header, data, ok := strings.Cut(strings.TrimPrefix(trimmed, "data:"), ",")
+	if !ok || !strings.HasPrefix(trimmed, "data:") || !strings.HasSuffix(header, ";base64") {
+		return "", nil, false
+	}
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(data))
+	if err != nil || len(raw) == 0 {
+		return "", nil, false
+	}
+	return strings.TrimSpace(strings.TrimSuffix(header, ";base64")), raw, true
+}
+
+// setPendingBlobReferences 在事务内把待处理任务的 blob 引用同步为其媒体列中的引用。
+func setPendingBlobReferences(tx *gorm.DB, entity pendingTaskEntity) error {
+	payload := entityMediaPayload(entity.PayloadVideos, entity.PayloadAudios, entity.PayloadImages, entity.PayloadExtraInputs)
+	return blobstore.DefaultStore().SetReferences(tx, blobOwnerPendingTask, entity.TaskID, payloadBlobKeys(payload))
+}
+
+// setHistoryBlobReferences 在事务内把历史案件的 blob 引用同步为其媒体列中的引用。
+func setHistoryBlobReferences(tx *gorm.DB, entity historyCaseEntity) error {
+	payload := entityMediaPayload(entity.PayloadVideos, entity.PayloadAudios, entity.PayloadImages, entity.PayloadExtraInputs)
+	return blobstore.DefaultStore().SetReferences(tx, blobOwnerHistoryCase, entity.RecordID, payloadBlobKeys(payload))
+}
+
+// releaseBlobReferences 在事务内释放所有者持有的全部 blob 引用。
+func releaseBlobReferences(tx *gorm.DB, ownerKind string, ownerID string) error {
+	return blobstore.DefaultStore().SetReferences(tx, ownerKind, ownerID, nil)
+}
+
+// MigrateInlinePayloadMedia 把 pending_tasks 与 history_cases 中内联存储的 base64 媒体迁移到 blob 存储，
+// 行内只保留 blob 引用。可重复执行：已迁移的行不再包含内联媒体，会被直接跳过。
+// 返回成功迁移的行数；单行失败只记录日志，待下次启动重试。
+func MigrateInlinePayloadMedia() (int, error) {
+	db := currentStateDB()
+	if db == nil {
+		return 0, fmt.Errorf("state db is nil")
+	}
+	ensureStateSchema(db)
+
+	pendingMigrated, err := migratePendingInlineMedia(db)
+	if err != nil {
+		return pendingMigrated, err
+	}
+	historyMigrated, err := migrateHistoryInlineMedia(db)
+	return pendingMigrated + historyMigrated, err
+}
+
+func migratePendingInlineMedia(db *gorm.DB) (int, error) {
+	migrated := 0
+	lastID := ""
+	for {
+		rows := make([]pendingTaskEntity, 0, inlineMediaMigrationBatchSize)
+		if err := db.Model(&pendingTaskEntity{}).
+			Select("task_id", "payload_videos", "payload_audios", "payload_images", "payload_extra_inputs").
+			Where("task_id > ?", lastID).
+			Where("payload_videos <> '' OR payload_audios <> '' OR payload_images <> '' OR payload_extra_inputs <> ''").
+			Order("task_id asc").
+			Limit(inlineMediaMigrationBatchSize).
+			Find(&rows).Error; err != nil {
+			return migrated, err
+		}
+		if len(rows) == 0 {
+			return migrated, nil
+		}
+		for _, row := range rows {
+			lastID = row.TaskID
+			payload := entityMediaPayload(row.PayloadVideos, row.PayloadAudios, row.PayloadImages, row.PayloadExtraInputs)
+			if !hasInlinePayloadMedia(payload) {
+				continue
+			}
+			externalized := externalizePayloadMedia(payload)
+			updated := row
+			updated.PayloadVideos = encodeStringList(externalized.Videos)
+			updated.PayloadAudios = encodeStringList(externalized.Audios)
+			updated.PayloadImages = encodeStringList(externalized.Images)
+			updated.PayloadExtraInputs = encodeModalityLists(externalized.ExtraInputs)
+			changed := false
+			err := db.Transaction(func(tx *gorm.DB) error {
+				// 以原媒体列为条件更新，任务已被归档或删除时不写入引用。
+				result := tx.Model(&pendingTaskEntity{}).
+					Where("task_id = ? AND payload_videos = ? AND payload_audios = ? AND payload_images = ? AND payload_extra_inputs = ?",
+						row.TaskID, row.PayloadVideos, row.PayloadAudios, row.PayloadImages, row.PayloadExtraInputs).
+					Updates(map[string]interface{}{
+						"payload_videos":       updated.PayloadVideos,
+						"payload_audios":       updated.PayloadAudios,
+						"payload_images":       updated.PayloadImages,
+						"payload_extra_inputs": updated.PayloadExtraInputs,
+					})
+				if result.Error != nil || result.RowsAffected == 0 {
+					return result.Error
+				}
+				changed = true
+				return setPendingBlobReferences(tx, updated)
+			})
+			if err != nil {
+				log.Printf("[state] migrate pending task inline media failed: task=%s err=%v", row.TaskID, err)
+			} else if changed {
+				migrated++
+			}
+		}
+	}
+}
+
+func migrateHistoryInlineMedia(db *gorm.DB) (int, error) {
+	migrated := 0
+	lastID := ""
+	for {
+		rows := make([]historyCaseEntity, 0, inlineMediaMigrationBatchSize)
+		if err := db.Model(&historyCaseEntity{}).
+			Select("record_id", "payload_videos", "payload_audios", "payload_images", "payload_extra_inputs").
+			Where("record_id > ?", lastID).
+			Where("payload_videos <> '' OR payload_audios <> '' OR payload_images <> '' OR payload_extra_inputs <> ''").
+			Order("record_id asc").
+			Limit(inlineMediaMigrationBatchSize).
+			Find(&rows).Error; err != nil {
+			return migrated, err
+		}
+		if len(rows) == 0 {
+			return migrated, nil
+		}
+		for _, row := range rows {
+			lastID = row.RecordID
+			payload := entityMediaPayload(row.PayloadVideos, row.PayloadAudios, row.PayloadImages, row.PayloadExtraInputs)
+			if !hasInlinePayloadMedia(payload) {
+				continue
+			}
+			externalized := externalizePayloadMedia(payload)
+			updated := row
+			updated.PayloadVideos = encodeStringList(externalized.Videos)
+			updated.PayloadAudios = encodeStringList(externalized.Audios)
+			updated.PayloadImages = encodeStringList(externalized.Images)
+			updated.PayloadExtraInputs = encodeModalityLists(externalized.ExtraInputs)
+			changed := false
+			err := db.Transaction(func(tx *gorm.DB) error {
+				// 以原媒体列为条件更新，历史记录已被重写或删除时不写入引用。
+				result := tx.Model(&historyCaseEntity{}).
+					Where("record_id = ? AND payload_videos = ? AND payload_audios = ? AND payload_images = ? AND payload_extra_inputs = ?",
+						row.RecordID, row.PayloadVideos, row.PayloadAudios, row.PayloadImages, row.PayloadExtraInputs).
+					Updates(map[string]interface{}{
+						"payload_videos":       updated.PayloadVideos,
+						"payload_audios":       updated.PayloadAudios,
+						"payload_images":       updated.PayloadImages,
+						"payload_extra_inputs": updated.PayloadExtraInputs,
+					})
+				if result.Error != nil || result.RowsAffected == 0 {
+					return result.Error
+				}
+				changed = true
+				return setHistoryBlobReferences(tx, updated)
+			})
+			if err != nil {
+				log.Printf("[state] migrate history case inline media failed: record=%s err=%v", row.RecordID, err)
+			} else if changed {
+				migrated++
+			}
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"antifraud/internal/platform/redaction"
+
+	"gorm.io/gorm"
+)
+
+// SaveTaskRedactionMapping 合并保存任务的脱敏映射（占位符 -> 原文）。
+// 入队、子智能体解读与工具调用会分多次追加新的占位符，已有占位符保持原值不被覆盖。
+func SaveTaskRedactionMapping(userID, taskID string, mapping map[string]string) {
+	db := currentStateDB()
+	if db == nil || len(mapping) == 0 {
+		return
+	}
+	ensureStateSchema(db)
+
+	uid := normalizeUserID(userID)
+	tid := strings.TrimSpace(taskID)
+	if tid == "" {
+		return
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		return mergeTaskRedactionMapping(tx, uid, tid, mapping)
+	})
+	if err != nil {
+		log.Printf("[state] save redaction mapping failed: user=%s task=%s err=%v", uid, tid, err)
+	}
+}
+
+// mergeTaskRedactionMapping 在调用方事务内把 mapping 合并进已保存的映射，已有占位符保持原值。
+func mergeTaskRedactionMapping(tx *gorm.DB, uid, tid string, mapping map[string]string) error {
+	var entity taskRedactionMappingEntity
+	merged := map[string]string{}
+	if err := tx.Where("task_id = ? AND user_id = ?", tid, uid).First(&entity).Error; err == nil {
+		merged = decodeRedactionMapping(entity.Mapping)
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	for placeholder, original := range mapping {
+		if _, exists := merged[placeholder]; !exists {
+			merged[placeholder] = original
+		}
+	}
+	encoded, err := json.Marshal(merged)
+	if err != nil {
+		return err
+	}
+	return tx.Save(&taskRedactionMappingEntity{
+		TaskID:    tid,
+		UserID:    uid,
+		Mapping:   string(encoded),
+		UpdatedAt: time.Now(),
+	}).Error
+}
+
+// GetTaskRedactionMapping 返回任务的脱敏映射；任务没有命中敏感信息或映射已删除时返回空映射。
+func GetTaskRedactionMapping(userID, taskID string) map[string]string {
+	db := currentStateDB()
+	tid := strings.TrimSpace(taskID)
+	if db == nil || tid == "" {
+		return map[string]string{}
+	}
+	ensureStateSchema(db)
+
+	var entity taskRedactionMappingEntity
+	if err := db.Where("task_id = ? AND user_id = ?", tid, normalizeUserID(userID)).First(&entity).Error; err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Printf("[state] load redaction mapping failed: task=%s err=%v", tid, err)
+		}
+		return map[string]string{}
+	}
+	return decodeRedactionMapping(entity.Mapping)
+}
+
+// RestoreTaskPII 按任务的脱敏映射把标题、文本、解读、报告与结构化线索中的占位符还原为原文，仅用于向任务所属用户本人展示或导出。
+func RestoreTaskPII(task TaskRecord) TaskRecord {
+	mapping := GetTaskRedactionMapping(task.UserID, task.TaskID)
+	if len(mapping) == 0 {
+		return task
+	}
+	restorer := redaction.NewRedactor(mapping)
+	task.Title = restorer.Restore(task.Title)
+	task.RiskSummary = restorer.Restore(task.RiskSummary)
+	task.Summary = restorer.Restore(task.Summary)
+	task.Report = restorer.Restore(task.Report)
+	task.Payload = restorePayloadPII(restorer, task.Payload)
+	task.Indicators = restoreIndicatorsPII(restorer, task.Indicators)
+	return task
+}
+
+// RestoreCaseHistoryPII 是 RestoreTaskPII 的历史案件版本，历史记录 ID 即原任务 ID。
+func RestoreCaseHistoryPII(record CaseHistoryRecord) CaseHistoryRecord {
+	mapping := GetTaskRedactionMapping(record.UserID, record.RecordID)
+	if len(mapping) == 0 {
+		return record
+	}
+	restorer := redaction.NewRedactor(mapping)
+	record.Title = restorer.Restore(record.Title)
+	record.CaseSummary = restorer.Restore(record.CaseSummary)
+	record.RiskSummary = restorer.Restore(record.RiskSummary)
+	record.Report = restorer.Restore(record.Report)
+	record.Payload = restorePayloadPII(restorer, record.Payload)
+	record.Indicators = restoreIndicatorsPII(restorer, record.Indicators)
+	return record
+}
+
+func restorePayloadPII(restorer *redaction.Redactor, payload TaskPayload) TaskPayload {
+	payload.Text = restorer.Restore(payload.Text)
+	payload.VideoInsights = restorer.RestoreList(payload.VideoInsights)
+	payload.AudioInsights = restorer.RestoreList(payload.AudioInsights)
+	payload.ImageInsights = restorer.RestoreList(payload.ImageInsights)
+	if len(payload.ExtraInsights) > 0 {
+		extra := make(map[string][]string, len(payload.ExtraInsights))
+		for kind, items := range payload.ExtraInsights {
+			extra[kind] = restorer.RestoreList(items)
+		}
+		payload.ExtraInsights = extra
+	}
+	return payload
+}
+
+func restoreIndicatorsPII(restorer *redaction.Redactor, indicators []Indicator) []Indicator {
+	if len(indicators) == 0 {
+		return indicators
+	}
+	restored := make([]Indicator, len(indicators))
+	for i, indicator := range indicators {
+		indicator.Value = restorer.Restore(indicator.Value)
+		indicator.Sources = append([]string{}, indicator.Sources...)
+		restored[i] = indicator
+	}
+	return restored
+}
+
+// deleteTaskRedactionMapping 删除任务的脱敏映射，随历史案件一同清理。
+func deleteTaskRedactionMapping(db *gorm.DB, userID, taskID string) {
+	if err := db.Where("task_id = ? AND user_id = ?", taskID, userID).Delete(&taskRedactionMappingEntity{}).Error; err != nil {
+		log.Printf("[state] delete redaction mapping failed: user=%s task=%s err=%v", userID, taskID, err)
+	}
+}
+
+func decodeRedactionMapping(value string) map[string]string {
+	mapping := map[string]string{}
+	if strings.TrimSpace(value) == "" {
+		return mapping
+	}
+	if err := json.Unmarshal([]byte(value), &mapping); err != nil {
+		return map[string]string{}
+	}
+	return mapping
+}
@@ -0,0 +1,307 @@
+package state
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"antifraud/internal/modules/multi_agent/adapters/outbound/blobstore"
+
+	"gorm.io/gorm"
+)
+
+// retentionBatchSize 是保留策略清理与用户数据导出时每批读取的默认行数。
+const retentionBatchSize = 100
+
+// ErrUserTasksProcessing 表示用户仍有执行中（含已请求取消、等待工作协程回收）的任务，
+// 此时清除数据会被随后的归档重新写入，需等任务结束后再试。
+var ErrUserTasksProcessing = errors.New("user has processing tasks")
+
+// UserDataPurgeResult 汇总一次用户数据清除删除的记录数。
+type UserDataPurgeResult struct {
+	HistoryDeleted int
+	TasksDeleted   int
+}
+
+// PurgeHistoryMedia 清除 cutoff 之前归档的历史案件中的原始媒体，最多处理 limit 条，返回本批清除的记录数。
+// 说明：
+// 1) 只移除 blob 引用与内联 data URL，文本、外部链接、各模态解读、报告与评分均保留；
+// 2) 同一事务内同步 blob 引用，引用归零的内容由 blob 清理任务在宽限期后删除；
+// 3) 以原媒体列为条件更新，与并发的删除、迁移互不覆盖；清除后写入 media_purged_at，不再重复处理。
+func PurgeHistoryMedia(cutoff time.Time, limit int) (int, error) {
+	db := currentStateDB()
+	if db == nil {
+		return 0, fmt.Errorf("state db is nil")
+	}
+	ensureStateSchema(db)
+	if limit <= 0 {
+		limit = retentionBatchSize
+	}
+
+	rows := make([]historyCaseEntity, 0, limit)
+	if err := db.Model(&historyCaseEntity{}).
+		Select("record_id", "user_id", "payload_videos", "payload_audios", "payload_images", "payload_extra_inputs").
+		Where("created_at < ? AND media_purged_at IS NULL", cutoff).
+		Where("payload_videos <> '' OR payload_audios <> '' OR payload_images <> '' OR payload_extra_inputs <> ''").
+		Order("created_at asc").
+		Limit(limit).
+		Find(&rows).Error; err != nil {
+		return 0, err
+	}
+
+	purged := 0
+	for _, row := range rows {
+		stripped := stripPayloadMedia(entityMediaPayload(row.PayloadVideos, row.PayloadAudios, row.PayloadImages, row.PayloadExtraInputs))
+		updated := row
+		updated.PayloadVideos = encodeStringList(stripped.Videos)
+		updated.PayloadAudios = encodeStringList(stripped.Audios)
+		updated.PayloadImages = encodeStringList(stripped.Images)
+		updated.PayloadExtraInputs = encodeModalityLists(stripped.ExtraInputs)
+		now := time.Now()
+		changed := false
+		err := db.Transaction(func(tx *gorm.DB) error {
+			// updated_at 不变：清除媒体不应让旧记录在任务列表中被排到前面。
+			result := tx.Model(&historyCaseEntity{}).
+				Where("record_id = ? AND payload_videos = ? AND payload_audios = ? AND payload_images = ? AND payload_extra_inputs = ?",
+					row.RecordID, row.PayloadVideos, row.PayloadAudios, row.PayloadImages, row.PayloadExtraInputs).
+				Updates(map[string]interface{}{
+					"payload_videos":       updated.PayloadVideos,
+					"payload_audios":       updated.PayloadAudios,
+					"payload_images":       updated.PayloadImages,
+					"payload_extra_inputs": updated.PayloadExtraInputs,
+					"media_purged_at":      now,
+				})
+			if result.Error != nil || result.RowsAffected == 0 {
+				return result.Error
+			}
+			changed = true
+			return setHistoryBlobReferences(tx, updated)
+		})
+		if err != nil {
+			log.Printf("[state] purge history media failed: record=%s err=%v", row.RecordID, err)
+		} else if changed {
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+// DeleteExpiredHistory 删除指定风险等级下 cutoff 之前归档的历史案件，最多处理 limit 条，返回本批删除的记录数。
+// 风险等级按 normalizeRiskLevel 的口径匹配：非“高/低”的存量取值归入“中”。
+// 每条记录复用 DeleteCaseHistory，一并释放 blob 引用、删除进度事件并通知删除观察者。
+func DeleteExpiredHistory(riskLevel string, cutoff time.Time, limit int) (int, error) {
+	db := currentStateDB()
+	if db == nil {
+		return 0, fmt.Errorf("state db is nil")
+	}
+	ensureStateSchema(db)
+	if limit <= 0 {
+		limit = retentionBatchSize
+	}
+
+	query := db.Model(&historyCaseEntity{}).
+		Select("record_id", "user_id").
+		Where("created_at < ?", cutoff)
+	switch level := normalizeRiskLevel(riskLevel); level {
+	case "高", "低":
+		query = query.Where("risk_level = ?", level)
+	default:
+		query = query.Where("risk_level IS NULL OR risk_level NOT IN ?", []string{"高", "低"})
+	}
+	rows := make([]historyCaseEntity, 0, limit)
+	if err := query.Order("created_at asc").Limit(limit).Find(&rows).Error; err != nil {
+		return 0, err
+	}
+
+	deleted := 0
+	for _, row := range rows {
+		ok, err := DeleteCaseHistory(row.UserID, row.RecordID)
+		if err != nil {
+			log.Printf("[state] delete expired history failed: user=%s record=%s err=%v", row.UserID, row.RecordID, err)
+			continue
+		}
+		if ok {
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// StreamUserHistory 按 record_id 分批读取用户全部历史案件（含载荷与报告）并依次交给 fn，
+// 用于导出用户数据，不会一次性把全部记录加载进内存；fn 返回错误时提前结束。
+func StreamUserHistory(userID string, fn func(CaseHistoryRecord) error) error {
+	db := currentStateDB()
+	if db == nil {
+		return fmt.Errorf("state db is nil")
+	}
+	ensureStateSchema(db)
+
+	uid := normalizeUserID(userID)
+	lastID := ""
+	for {
+		rows := make([]historyCaseEntity, 0, retentionBatchSize)
+		if err := db.Where("user_id = ? AND record_id > ?", uid, lastID).
+			Order("record_id asc").
+			Limit(retentionBatchSize).
+			Find(&rows).Error; err != nil {
+			return err
+		}
+		for _, row := range rows {
+			lastID = row.RecordID
+			if err := fn(historyFromEntity(row)); err != nil {
+				return err
+			}
+		}
+		if len(rows) < retentionBatchSize {
+			return nil
+		}
+	}
+}
+
+// ListUserPendingTasks 返回用户尚未归档的全部任务（含载荷），媒体保持 blob 引用形式。
+func ListUserPendingTasks(userID string) ([]TaskRecord, error) {
+	db := currentStateDB()
+	if db == nil {
+		return nil, fmt.Errorf("state db is nil")
+	}
+	ensureStateSchema(db)
+
+	rows := make([]pendingTaskEntity, 0)
+	if err := db.Where("user_id = ?", normalizeUserID(userID)).
+		Order("created_at asc").
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	tasks := make([]TaskRecord, 0, len(rows))
+	for _, row := range rows {
+		tasks = append(tasks, taskFromPendingEntity(row))
+	}
+	return tasks, nil
+}
+
+// UserDataChangedSince 判断用户任务或历史案件在 since 之后是否有新增或更新，
+// 用于确认“导出后清除”时清除范围与用户已导出的内容一致。
+func UserDataChangedSince(userID string, since time.Time) (bool, error) {
+	db := currentStateDB()
+	if db == nil {
+		return false, fmt.Errorf("state db is nil")
+	}
+	ensureStateSchema(db)
+
+	uid := normalizeUserID(userID)
+	var count int64
+	if err := db.Model(&pendingTaskEntity{}).
+		Where("user_id = ? AND updated_at > ?", uid, since).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	if count > 0 {
+		return true, nil
+	}
+	if err := db.Model(&historyCaseEntity{}).
+		Where("user_id = ? AND updated_at > ?", uid, since).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// PurgeUserData 删除用户的全部待处理任务、历史案件、任务进度事件与脱敏映射，并释放其持有的 blob 引用。
+// 说明：
+// 1) 仍有执行中任务时返回 ErrUserTasksProcessing，避免清除后被工作协程重新归档；
+// 2) 任务与历史在同一事务内删除，失败时整体回滚；
+// 3) 删除成功后逐条通知历史删除观察者，由其清理检索索引等派生数据。
+func PurgeUserData(userID string) (UserDataPurgeResult, error) {
+	db := currentStateDB()
+	if db == nil {
+		return UserDataPurgeResult{}, fmt.Errorf("state db is nil")
+	}
+	ensureStateSchema(db)
+
+	uid := normalizeUserID(userID)
+	var result UserDataPurgeResult
+	historyIDs := make([]string, 0)
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var running int64
+		if err := tx.Model(&pendingTaskEntity{}).
+			Where("user_id = ? AND status IN ?", uid, []string{TaskStatusProcessing, TaskStatusCancelled}).
+			Count(&running).Error; err != nil {
+			return err
+		}
+		if running > 0 {
+			return ErrUserTasksProcessing
+		}
+
+		taskIDs := make([]string, 0)
+		if err := tx.Model(&pendingTaskEntity{}).Where("user_id = ?", uid).Pluck("task_id", &taskIDs).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&historyCaseEntity{}).Where("user_id = ?", uid).Pluck("record_id", &historyIDs).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", uid).Delete(&pendingTaskEntity{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", uid).Delete(&historyCaseEntity{}).Error; err != nil {
+			return err
+		}
+		for _, taskID := range taskIDs {
+			if err := releaseBlobReferences(tx, blobOwnerPendingTask, taskID); err != nil {
+				return err
+			}
+		}
+		for _, recordID := range historyIDs {
+			if err := releaseBlobReferences(tx, blobOwnerHistoryCase, recordID); err != nil {
+				return err
+			}
+		}
+		result = UserDataPurgeResult{HistoryDeleted: len(historyIDs), TasksDeleted: len(taskIDs)}
+		return nil
+	})
+	if err != nil {
+		return UserDataPurgeResult{}, err
+	}
+
+	if err := db.Where("user_id = ?", uid).Delete(&taskProgressEventEntity{}).Error; err != nil {
+		log.Printf("[state] purge user task progress failed: user=%s err=%v", uid, err)
+	}
+	if err := db.Where("user_id = ?", uid).Delete(&taskRedactionMappingEntity{}).Error; err != nil {
+		log.Printf("[state] purge user redaction mappings failed: user=%s err=%v", uid, err)
+	}
+	for _, recordID := range historyIDs {
+		publishHistoryRemoved(uid, recordID)
+	}
+	return result, nil
+}
+
+// stripPayloadMedia 移除载荷原始输入中由本系统保存的媒体（blob 引用与内联 data URL），保留外部链接等其他输入。
+func stripPayloadMedia(payload TaskPayload) TaskPayload {
+	payload.Videos = stripMediaList(payload.Videos)
+	payload.Audios = stripMediaList(payload.Audios)
+	payload.Images = stripMediaList(payload.Images)
+	if len(payload.ExtraInputs) > 0 {
+		inputs := make(map[string][]string, len(payload.ExtraInputs))
+		for modality, items := range payload.ExtraInputs {
+			if kept := stripMediaList(items); len(kept) > 0 {
+				inputs[modality] = kept
+			}
+		}
+		payload.ExtraInputs = inputs
+	}
+	return payload
+}
+
+func stripMediaList(items []string) []string {
+	kept := make([]string, 0, len(items))
+	for _, item := range items {
+		if _, ok := blobstore.ParseRef(item); ok {
+			continue
+		}
+		if _, _, ok := parseInlineMedia(item); ok {
+			continue
+		}
+		kept = append(kept, item)
+	}
+	return kept
+}
@@ -4,6 +4,7 @@ import (
 	"crypto/rand"
 	"encoding/base64"
 	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
@@ -11,6 +12,7 @@ import (
 	"sync"
 	"time"
 
+	"antifraud/internal/modules/multi_agent/adapters/outbound/blobstore"
 	model "antifraud/internal/modules/multi_agent/adapters/outbound/state/model"
 	"antifraud/internal/platform/database"
 
@@ -22,8 +24,7 @@ const (
 	TaskStatusProcessing = "processing"
 	TaskStatusCompleted  = "completed"
 	TaskStatusFailed     = "failed"
-
-	pendingTaskTTL = 20 * time.Minute
+	TaskStatusCancelled  = "cancelled"
 )
 
 // 结构体模型已迁移至 multi_agent/state/model 目录。
@@ -31,13 +32,27 @@ const (
 type TaskPayload = model.TaskPayload
 type TaskRecord = model.TaskRecord
 type CaseHistoryRecord = model.CaseHistoryRecord
+type RiskBreakdown = model.RiskBreakdown
+type RiskFactorHit = model.RiskFactorHit
+type DynamicRiskBreakdown = model.DynamicRiskBreakdown
+type Indicator = model.Indicator
 type UserStateView = model.UserStateView
 type pendingTaskEntity = model.PendingTaskEntity
 type historyCaseEntity = model.HistoryCaseEntity
+type TaskProgressEvent = model.TaskProgressEvent
+type taskProgressEventEntity = model.TaskProgressEventEntity
+type taskRedactionMappingEntity = model.TaskRedactionMappingEntity
+
+const (
+	ModalityImage = model.ModalityImage
+	ModalityVideo = model.ModalityVideo
+	ModalityAudio = model.ModalityAudio
+)
 
 var stateSchemaOnce sync.Once
 var historyObserversMu sync.RWMutex
 var historyObservers []func(CaseHistoryRecord)
+var historyRemovedObservers []func(userID, recordID string)
 
 func init() {
 	database.RegisterMainDBSchemaInitializer("multi_agent_state", initStateSchema)
@@ -53,13 +68,17 @@ func ensureStateSchema(db *gorm.DB) {
 			log.Printf("[state] auto migrate state tables failed: %v", err)
 		}
 	})
+	// 任务与历史的媒体以 blob 引用保存，引用关系表需与状态表同库存在。
+	if err := blobstore.EnsureSchema(db); err != nil {
+		log.Printf("[state] auto migrate blob tables failed: %v", err)
+	}
 }
 
 func initStateSchema(db *gorm.DB) error {
 	if db == nil {
 		return fmt.Errorf("state db is nil")
 	}
-	return db.AutoMigrate(&pendingTaskEntity{}, &historyCaseEntity{})
+	return db.AutoMigrate(&pendingTaskEntity{}, &historyCaseEntity{}, &taskProgressEventEntity{}, &taskRedactionMappingEntity{})
 }
 
 // RegisterHistoryObserver 注册历史归档事件观察者。
@@ -88,10 +107,47 @@ func publishHistoryAdded(record CaseHistoryRecord) {
 	}
 }
 
+// RegisterHistoryRemovedObserver 注册历史记录删除事件观察者，用户删除、保留策略到期与用户数据清除均会触发。
+func RegisterHistoryRemovedObserver(observer func(userID, recordID string)) {
+	if observer == nil {
+		return
+	}
+	historyObserversMu.Lock()
+	defer historyObserversMu.Unlock()
+	historyRemovedObservers = append(historyRemovedObservers, observer)
+}
+
+func publishHistoryRemoved(userID, recordID string) {
+	historyObserversMu.RLock()
+	observers := append([]func(string, string){}, historyRemovedObservers...)
+	historyObserversMu.RUnlock()
+	for _, observer := range observers {
+		func() {
+			defer func() {
+				if recover() != nil {
+					log.Printf("[state] history removed observer panic recovered: record=%s", recordID)
+				}
+			}()
+			observer(userID, recordID)
+		}()
+	}
+}
+
 // CreateTask 创建任务并落库到 pending_tasks。
+// 载荷中内联的 base64 媒体先写入 blob 存储，行内与返回值只保留 blob:<sha256> 引用。
 func CreateTask(userID string, payload TaskPayload) TaskRecord {
+	return CreateTaskWithRedactionMapping(userID, payload, nil)
+}
+
+// CreateTaskWithRedactionMapping 创建任务，并在同一事务内保存入队时生成的脱敏映射。
+// 任务行提交后即可被 worker 领取，映射必须与任务同时可见，否则 worker 会以空映射重新编号占位符。
+func CreateTaskWithRedactionMapping(userID string, payload TaskPayload, redactionMapping map[string]string) TaskRecord {
 	uid := normalizeUserID(userID)
 	now := time.Now()
+	db := currentStateDB()
+	if db != nil {
+		payload = externalizePayloadMedia(payload)
+	}
 	task := TaskRecord{
 		TaskID:    newID("TASK"),
 		UserID:    uid,
@@ -107,10 +163,11 @@ func CreateTask(userID string, payload TaskPayload) TaskRecord {
 			VideoInsights: append([]string{}, payload.VideoInsights...),
 			AudioInsights: append([]string{}, payload.AudioInsights...),
 			ImageInsights: append([]string{}, payload.ImageInsights...),
+			ExtraInputs:   model.CloneModalityLists(payload.ExtraInputs),
+			ExtraInsights: model.CloneModalityLists(payload.ExtraInsights),
 		},
 	}
 
-	db := currentStateDB()
 	if db == nil {
 		log.Printf("[state] create task skipped: db not initialized")
 		return task
@@ -118,7 +175,17 @@ func CreateTask(userID string, payload TaskPayload) TaskRecord {
 	ensureStateSchema(db)
 
 	entity := pendingEntityFromTask(task)
-	if err := db.Create(&entity).Error; err != nil {
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&entity).Error; err != nil {
+			return err
+		}
+		if len(redactionMapping) > 0 {
+			if err := mergeTaskRedactionMapping(tx, entity.UserID, entity.TaskID, redactionMapping); err != nil {
+				return err
+			}
+		}
+		return setPendingBlobReferences(tx, entity)
+	}); err != nil {
 		log.Printf("[state] create pending task failed: user=%s task=%s err=%v", uid, task.TaskID, err)
 	}
 
@@ -150,23 +217,26 @@ func MarkTaskProcessing(userID, taskID string) {
 }
 
 // MarkTaskCompleted 将任务从 pending 迁移到 history，并写入最终报告。
-func MarkTaskCompleted(userID, taskID, report string) {
+// workerID 非空时仅当该 worker 仍持有 processing 状态的租约才归档，避免租约丢失后旧 worker 覆盖被回收或已取消的任务；
+// workerID 为空表示不经 worker 的系统归档。返回任务是否由本次调用归档。
+func MarkTaskCompleted(userID, taskID, workerID, report string) bool {
 	db := currentStateDB()
 	if db == nil {
-		return
+		return false
 	}
 	ensureStateSchema(db)
 
 	uid := normalizeUserID(userID)
 	tid := strings.TrimSpace(taskID)
 	if tid == "" {
-		return
+		return false
 	}
 	trimmedReport := strings.TrimSpace(report)
 
+	finalized := false
 	err := db.Transaction(func(tx *gorm.DB) error {
 		var pending pendingTaskEntity
-		if err := tx.Where("task_id = ? AND user_id = ?", tid, uid).First(&pending).Error; err != nil {
+		if err := leasedPendingQuery(tx, uid, tid, workerID).First(&pending).Error; err != nil {
 			if errors.Is(err, gorm.ErrRecordNotFound) {
 				return nil
 			}
@@ -205,6 +275,9 @@ func MarkTaskCompleted(userID, taskID, report string) {
 				PayloadVideoInsights: pending.PayloadVideoInsights,
 				PayloadAudioInsights: pending.PayloadAudioInsights,
 				PayloadImageInsights: pending.PayloadImageInsights,
+				PayloadExtraInputs:   pending.PayloadExtraInputs,
+				PayloadExtraInsights: pending.PayloadExtraInsights,
+				Indicators:           pending.Indicators,
 				Report:               firstNonEmpty(trimmedReport, pending.Report),
 				CreatedAt:            pending.CreatedAt,
 				UpdatedAt:            time.Now(),
@@ -213,6 +286,9 @@ func MarkTaskCompleted(userID, taskID, report string) {
 			if err := tx.Create(&history).Error; err != nil {
 				return err
 			}
+			if err := setHistoryBlobReferences(tx, history); err != nil {
+				return err
+			}
 		} else if trimmedReport != "" {
 			if err := tx.Model(&historyCaseEntity{}).
 				Where("record_id = ? AND user_id = ?", tid, uid).
@@ -224,15 +300,22 @@ func MarkTaskCompleted(userID, taskID, report string) {
 		if err := tx.Where("task_id = ? AND user_id = ?", tid, uid).Delete(&pendingTaskEntity{}).Error; err != nil {
 			return err
 		}
+		if err := releaseBlobReferences(tx, blobOwnerPendingTask, tid); err != nil {
+			return err
+		}
+		finalized = true
 		return nil
 	})
 	if err != nil {
 		log.Printf("[state] mark completed failed: user=%s task=%s err=%v", uid, tid, err)
+		return false
 	}
+	return finalized
 }
 
 // UpdateTaskInsights 更新任务的子模态解读摘要。
-func UpdateTaskInsights(userID, taskID string, videoInsights []string, audioInsights []string, imageInsights []string) {
+// insights 以模态名为 key；内置模态写入专用列，其他模态写入 payload_extra_insights。
+func UpdateTaskInsights(userID, taskID string, insights map[string][]string) {
 	db := currentStateDB()
 	if db == nil {
 		return
@@ -245,20 +328,26 @@ func UpdateTaskInsights(userID, taskID string, videoInsights []string, audioInsi
 		return
 	}
 
+	var payload TaskPayload
+	for modality, items := range insights {
+		payload.SetModalityInsights(strings.TrimSpace(modality), items)
+	}
+
 	if err := db.Model(&pendingTaskEntity{}).
 		Where("task_id = ? AND user_id = ?", tid, uid).
 		Updates(map[string]interface{}{
-			"payload_video_insights": encodeStringList(videoInsights),
-			"payload_audio_insights": encodeStringList(audioInsights),
-			"payload_image_insights": encodeStringList(imageInsights),
+			"payload_video_insights": encodeStringList(payload.VideoInsights),
+			"payload_audio_insights": encodeStringList(payload.AudioInsights),
+			"payload_image_insights": encodeStringList(payload.ImageInsights),
+			"payload_extra_insights": encodeModalityLists(payload.ExtraInsights),
 			"updated_at":             time.Now(),
 		}).Error; err != nil {
 		log.Printf("[state] update insights failed: user=%s task=%s err=%v", uid, tid, err)
 	}
 }
 
-// MarkTaskFailed 将失败任务写入历史并从 pending 删除。
-func MarkTaskFailed(userID, taskID, errMsg string) {
+// UpdateTaskIndicators 保存任务抽取出的结构化线索；任务未经 write_user_history_case 归档时由 MarkTaskCompleted 带入历史。
+func UpdateTaskIndicators(userID, taskID string, indicators []Indicator) {
 	db := currentStateDB()
 	if db == nil {
 		return
@@ -271,9 +360,34 @@ func MarkTaskFailed(userID, taskID, errMsg string) {
 		return
 	}
 
+	if err := db.Model(&pendingTaskEntity{}).
+		Where("task_id = ? AND user_id = ?", tid, uid).
+		Updates(map[string]interface{}{
+			"indicators": encodeIndicators(indicators),
+			"updated_at": time.Now(),
+		}).Error; err != nil {
+		log.Printf("[state] update indicators failed: user=%s task=%s err=%v", uid, tid, err)
+	}
+}
+
+// MarkTaskFailed 将失败任务写入历史并从 pending 删除；workerID 与返回值的含义同 MarkTaskCompleted。
+func MarkTaskFailed(userID, taskID, workerID, errMsg string) bool {
+	db := currentStateDB()
+	if db == nil {
+		return false
+	}
+	ensureStateSchema(db)
+
+	uid := normalizeUserID(userID)
+	tid := strings.TrimSpace(taskID)
+	if tid == "" {
+		return false
+	}
+
+	finalized := false
 	err := db.Transaction(func(tx *gorm.DB) error {
 		var pending pendingTaskEntity
-		if err := tx.Where("task_id = ? AND user_id = ?", tid, uid).First(&pending).Error; err != nil {
+		if err := leasedPendingQuery(tx, uid, tid, workerID).First(&pending).Error; err != nil {
 			if errors.Is(err, gorm.ErrRecordNotFound) {
 				return nil
 			}
@@ -302,6 +416,9 @@ func MarkTaskFailed(userID, taskID, errMsg string) {
 			PayloadVideoInsights: pending.PayloadVideoInsights,
 			PayloadAudioInsights: pending.PayloadAudioInsights,
 			PayloadImageInsights: pending.PayloadImageInsights,
+			PayloadExtraInputs:   pending.PayloadExtraInputs,
+			PayloadExtraInsights: pending.PayloadExtraInsights,
+			Indicators:           pending.Indicators,
 			Report:               reason,
 			CreatedAt:            time.Now(),
 			UpdatedAt:            time.Now(),
@@ -313,14 +430,32 @@ func MarkTaskFailed(userID, taskID, errMsg string) {
 		if err := tx.Create(&history).Error; err != nil {
 			return err
 		}
+		if err := setHistoryBlobReferences(tx, history); err != nil {
+			return err
+		}
 		if err := tx.Where("task_id = ? AND user_id = ?", tid, uid).Delete(&pendingTaskEntity{}).Error; err != nil {
 			return err
 		}
+		if err := releaseBlobReferences(tx, blobOwnerPendingTask, tid); err != nil {
+			return err
+		}
+		finalized = true
 		return nil
 	})
 	if err != nil {
 		log.Printf("[state] mark failed failed: user=%s task=%s err=%v", uid, tid, err)
+		return false
+	}
+	return finalized
+}
+
+// leasedPendingQuery 按任务与用户定位 pending 记录；workerID 非空时与 RenewTaskLease 一样要求该 worker 持有 processing 租约。
+func leasedPendingQuery(tx *gorm.DB, userID, taskID, workerID string) *gorm.DB {
+	query := tx.Where("task_id = ? AND user_id = ?", taskID, userID)
+	if owner := strings.TrimSpace(workerID); owner != "" {
+		query = query.Where("lease_owner = ? AND status = ?", owner, TaskStatusProcessing)
 	}
+	return query
 }
 
 // GetTask 查询进行中任务。
@@ -336,8 +471,6 @@ func GetTask(userID, taskID string) (TaskRecord, bool) {
 	if tid == "" {
 		return TaskRecord{}, false
 	}
-	expireStalePendingTasks(uid, tid)
-
 	var entity pendingTaskEntity
 	query := db.Where("task_id = ? AND user_id = ?", tid, uid).Limit(1).Find(&entity)
 	if query.Error != nil {
@@ -362,8 +495,6 @@ func GetTaskDetailByID(userID, id string) (TaskRecord, bool) {
 	if targetID == "" {
 		return TaskRecord{}, false
 	}
-	expireStalePendingTasks(uid, targetID)
-
 	var pending pendingTaskEntity
 	pendingQuery := db.Where("task_id = ? AND user_id = ?", targetID, uid).Limit(1).Find(&pending)
 	if pendingQuery.Error != nil {
@@ -401,7 +532,6 @@ func GetUserStateView(userID string) UserStateView {
 		return UserStateView{UserID: uid, Pending: map[string]TaskRecord{}, History: []CaseHistoryRecord{}}
 	}
 	ensureStateSchema(db)
-	expireStalePendingTasks(uid, "")
 
 	pendingRows := make([]pendingTaskEntity, 0)
 	historyRows := make([]historyCaseEntity, 0)
@@ -452,57 +582,20 @@ func GetUserStateView(userID string) UserStateView {
 	}
 }
 
-// expireStalePendingTasks 清理超时未完成的 pending 任务。
-// 规则：
-// 1) 仅处理 created_at 早于 pendingTaskTTL 的记录；
-// 2) 仅清理 pending/processing 状态；
-// 3) 当传入 taskID 时只检查该任务，否则检查当前用户全部任务；
-// 4) 过期任务直接删除，不迁移到历史表。
-func expireStalePendingTasks(userID, taskID string) {
-	db := currentStateDB()
-	if db == nil {
-		return
-	}
-	ensureStateSchema(db)
-
-	uid := normalizeUserID(userID)
-	tid := strings.TrimSpace(taskID)
-	cutoff := time.Now().Add(-pendingTaskTTL)
-
-	query := db.Model(&pendingTaskEntity{}).
-		Where("created_at <= ?", cutoff).
-		Where("status IN ?", []string{TaskStatusPending, TaskStatusProcessing})
-	if tid != "" {
-		query = query.Where("user_id = ? AND task_id = ?", uid, tid)
-	} else {
-		query = query.Where("user_id = ?", uid)
-	}
-
-	staleRows := make([]pendingTaskEntity, 0)
-	if err := query.Find(&staleRows).Error; err != nil {
-		log.Printf("[state] query stale pending failed: user=%s task=%s err=%v", uid, firstNonEmpty(tid, "<all>"), err)
-		return
-	}
-	if len(staleRows) == 0 {
-		return
-	}
-
-	for _, row := range staleRows {
-		staleTaskID := strings.TrimSpace(row.TaskID)
-		if staleTaskID == "" {
-			continue
-		}
-		if err := db.Where("task_id = ? AND user_id = ?", staleTaskID, uid).Delete(&pendingTaskEntity{}).Error; err != nil {
-			log.Printf("[state] delete stale pending failed: user=%s task=%s err=%v", uid, staleTaskID, err)
-		}
-	}
-}
-
 // AddCaseHistory 直接写入历史记录（用于工具显式归档场景）。
-func AddCaseHistory(userID, taskID, title, summary, scamType, riskLevel string, riskScore int, riskSummary string, payload TaskPayload, report string) CaseHistoryRecord {
-	uid := normalizeUserID(userID)
+// input 中 RecordID 为空时生成新编号；Status、CreatedAt、RuleVersion 与 MediaPurgedAt 由写入时决定，传入值被忽略。
+// RiskBreakdown 为评分明细，可为空；非空时其规则集版本同时写入 RuleVersion 列便于按版本检索。
+// Indicators 为任务抽取的结构化线索，可为空。
+// 载荷中内联的 base64 媒体与 CreateTask 一样转存为 blob 引用，相同内容与原任务共享同一 blob。
+func AddCaseHistory(input CaseHistoryRecord) CaseHistoryRecord {
+	uid := normalizeUserID(input.UserID)
 	now := time.Now()
-	recordID := strings.TrimSpace(taskID)
+	db := currentStateDB()
+	payload := input.Payload
+	if db != nil {
+		payload = externalizePayloadMedia(payload)
+	}
+	recordID := strings.TrimSpace(input.RecordID)
 	if recordID == "" {
 		recordID = newID("TASK")
 	}
@@ -510,13 +603,13 @@ func AddCaseHistory(userID, taskID, title, summary, scamType, riskLevel string,
 	record := CaseHistoryRecord{
 		RecordID:    recordID,
 		UserID:      uid,
-		Title:       normalizeCaseTitle(title, summary),
+		Title:       normalizeCaseTitle(input.Title, input.CaseSummary),
 		Status:      TaskStatusCompleted,
-		CaseSummary: strings.TrimSpace(summary),
-		ScamType:    strings.TrimSpace(scamType),
-		RiskLevel:   normalizeRiskLevel(riskLevel),
-		RiskScore:   normalizeRiskScore(riskScore),
-		RiskSummary: strings.TrimSpace(riskSummary),
+		CaseSummary: strings.TrimSpace(input.CaseSummary),
+		ScamType:    strings.TrimSpace(input.ScamType),
+		RiskLevel:   normalizeRiskLevel(input.RiskLevel),
+		RiskScore:   normalizeRiskScore(input.RiskScore),
+		RiskSummary: strings.TrimSpace(input.RiskSummary),
 		CreatedAt:   now,
 		Payload: TaskPayload{
 			Text:          strings.TrimSpace(payload.Text),
@@ -526,11 +619,17 @@ func AddCaseHistory(userID, taskID, title, summary, scamType, riskLevel string,
 			VideoInsights: append([]string{}, payload.VideoInsights...),
 			AudioInsights: append([]string{}, payload.AudioInsights...),
 			ImageInsights: append([]string{}, payload.ImageInsights...),
+			ExtraInputs:   model.CloneModalityLists(payload.ExtraInputs),
+			ExtraInsights: model.CloneModalityLists(payload.ExtraInsights),
 		},
-		Report: strings.TrimSpace(report),
+		Report:        strings.TrimSpace(input.Report),
+		RiskBreakdown: cloneRiskBreakdown(input.RiskBreakdown),
+		Indicators:    decodeIndicators(encodeIndicators(input.Indicators)),
+	}
+	if record.RiskBreakdown != nil {
+		record.RuleVersion = strings.TrimSpace(record.RiskBreakdown.RuleVersion)
 	}
 
-	db := currentStateDB()
 	if db == nil {
 		return record
 	}
@@ -541,7 +640,10 @@ func AddCaseHistory(userID, taskID, title, summary, scamType, riskLevel string,
 		if err := tx.Where("record_id = ? AND user_id = ?", entity.RecordID, entity.UserID).Delete(&historyCaseEntity{}).Error; err != nil {
 			return err
 		}
-		return tx.Create(&entity).Error
+		if err := tx.Create(&entity).Error; err != nil {
+			return err
+		}
+		return setHistoryBlobReferences(tx, entity)
 	})
 	if err != nil {
 		log.Printf("[state] add case history failed: user=%s record=%s err=%v", uid, recordID, err)
@@ -575,6 +677,56 @@ func GetCaseHistory(userID string) []CaseHistoryRecord {
 	return result
 }
 
+// ListCaseHistoryByIndicator 返回当前用户结构化线索中包含指定指纹的历史案件（按时间倒序），
+// 供其他子系统按号码、链接、收款账号等关联同一线索的案件；指纹由 indicators.Fingerprint 按原值计算。
+func ListCaseHistoryByIndicator(userID, fingerprint string) []CaseHistoryRecord {
+	db := currentStateDB()
+	trimmed := strings.ToLower(strings.TrimSpace(fingerprint))
+	if db == nil || trimmed == "" || strings.ContainsAny(trimmed, `%_"\`) {
+		return []CaseHistoryRecord{}
+	}
+	ensureStateSchema(db)
+
+	uid := normalizeUserID(userID)
+	rows := make([]historyCaseEntity, 0)
+	if err := db.Where("user_id = ? AND indicators LIKE ?", uid, `%"fingerprint":"`+trimmed+`"%`).Order("created_at desc").Find(&rows).Error; err != nil {
+		log.Printf("[state] list case history by indicator failed: user=%s err=%v", uid, err)
+		return []CaseHistoryRecord{}
+	}
+
+	result := make([]CaseHistoryRecord, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, historyFromEntity(row))
+	}
+	return result
+}
+
+// GetCaseHistoryRecord 按记录 ID 读取当前用户的一条历史案件（含评分明细）。
+func GetCaseHistoryRecord(userID, recordID string) (CaseHistoryRecord, bool) {
+	db := currentStateDB()
+	if db == nil {
+		return CaseHistoryRecord{}, false
+	}
+	ensureStateSchema(db)
+
+	uid := normalizeUserID(userID)
+	rid := strings.TrimSpace(recordID)
+	if rid == "" {
+		return CaseHistoryRecord{}, false
+	}
+
+	var entity historyCaseEntity
+	query := db.Where("record_id = ? AND user_id = ?", rid, uid).Limit(1).Find(&entity)
+	if query.Error != nil {
+		log.Printf("[state] get case history record failed: user=%s record=%s err=%v", uid, rid, query.Error)
+		return CaseHistoryRecord{}, false
+	}
+	if query.RowsAffected == 0 {
+		return CaseHistoryRecord{}, false
+	}
+	return historyFromEntity(entity), true
+}
+
 // DeleteCaseHistory 按记录 ID 删除当前用户的一条历史案件。
 func DeleteCaseHistory(userID, recordID string) (bool, error) {
 	db := currentStateDB()
@@ -589,11 +741,24 @@ func DeleteCaseHistory(userID, recordID string) (bool, error) {
 		return false, nil
 	}
 
-	result := db.Where("record_id = ? AND user_id = ?", rid, uid).Delete(&historyCaseEntity{})
-	if result.Error != nil {
-		return false, result.Error
+	deleted := false
+	err := db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("record_id = ? AND user_id = ?", rid, uid).Delete(&historyCaseEntity{})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		deleted = true
+		return releaseBlobReferences(tx, blobOwnerHistoryCase, rid)
+	})
+	if err != nil {
+		return false, err
+	}
+	if deleted {
+		deleteTaskProgressEvents(db, uid, rid)
+		deleteTaskRedactionMapping(db, uid, rid)
+		publishHistoryRemoved(uid, rid)
 	}
-	return result.RowsAffected > 0, nil
+	return deleted, nil
 }
 
 // pendingEntityFromTask 将业务层 TaskRecord 转换为 pending_tasks 表实体。
@@ -614,6 +779,8 @@ func pendingEntityFromTask(task TaskRecord) pendingTaskEntity {
 		PayloadVideoInsights: encodeStringList(task.Payload.VideoInsights),
 		PayloadAudioInsights: encodeStringList(task.Payload.AudioInsights),
 		PayloadImageInsights: encodeStringList(task.Payload.ImageInsights),
+		PayloadExtraInputs:   encodeModalityLists(task.Payload.ExtraInputs),
+		PayloadExtraInsights: encodeModalityLists(task.Payload.ExtraInsights),
 		Report:               strings.TrimSpace(task.Report),
 		Error:                strings.TrimSpace(task.Error),
 		HistoryRef:           strings.TrimSpace(task.HistoryRef),
@@ -638,6 +805,8 @@ func taskFromPendingEntity(entity pendingTaskEntity) TaskRecord {
 		Report:     strings.TrimSpace(entity.Report),
 		Error:      strings.TrimSpace(entity.Error),
 		HistoryRef: strings.TrimSpace(entity.HistoryRef),
+		Attempts:   entity.Attempts,
+		Indicators: decodeIndicators(entity.Indicators),
 		Payload: TaskPayload{
 			Text:          strings.TrimSpace(entity.PayloadText),
 			Videos:        decodeStringList(entity.PayloadVideos),
@@ -646,6 +815,8 @@ func taskFromPendingEntity(entity pendingTaskEntity) TaskRecord {
 			VideoInsights: decodeStringList(entity.PayloadVideoInsights),
 			AudioInsights: decodeStringList(entity.PayloadAudioInsights),
 			ImageInsights: decodeStringList(entity.PayloadImageInsights),
+			ExtraInputs:   decodeModalityLists(entity.PayloadExtraInputs),
+			ExtraInsights: decodeModalityLists(entity.PayloadExtraInsights),
 		},
 	}
 }
@@ -666,6 +837,9 @@ func historyEntityFromRecord(record CaseHistoryRecord, status string) historyCas
 		RiskLevel:            normalizeRiskLevel(record.RiskLevel),
 		RiskScore:            normalizeRiskScore(record.RiskScore),
 		RiskSummary:          strings.TrimSpace(record.RiskSummary),
+		RuleVersion:          strings.TrimSpace(record.RuleVersion),
+		RiskBreakdown:        encodeRiskBreakdown(record.RiskBreakdown),
+		Indicators:           encodeIndicators(record.Indicators),
 		PayloadText:          strings.TrimSpace(record.Payload.Text),
 		PayloadVideos:        encodeStringList(record.Payload.Videos),
 		PayloadAudios:        encodeStringList(record.Payload.Audios),
@@ -673,7 +847,10 @@ func historyEntityFromRecord(record CaseHistoryRecord, status string) historyCas
 		PayloadVideoInsights: encodeStringList(record.Payload.VideoInsights),
 		PayloadAudioInsights: encodeStringList(record.Payload.AudioInsights),
 		PayloadImageInsights: encodeStringList(record.Payload.ImageInsights),
+		PayloadExtraInputs:   encodeModalityLists(record.Payload.ExtraInputs),
+		PayloadExtraInsights: encodeModalityLists(record.Payload.ExtraInsights),
 		Report:               strings.TrimSpace(record.Report),
+		MediaPurgedAt:        record.MediaPurgedAt,
 		CreatedAt:            record.CreatedAt,
 		UpdatedAt:            time.Now(),
 	}
@@ -700,6 +877,7 @@ func historyFromEntity(entity historyCaseEntity) CaseHistoryRecord {
 		RiskLevel:   normalizeRiskLevel(entity.RiskLevel),
 		RiskScore:   normalizeRiskScore(entity.RiskScore),
 		RiskSummary: strings.TrimSpace(entity.RiskSummary),
+		RuleVersion: strings.TrimSpace(entity.RuleVersion),
 		CreatedAt:   entity.CreatedAt,
 		Report:      strings.TrimSpace(entity.Report),
 		Payload: TaskPayload{
@@ -710,7 +888,12 @@ func historyFromEntity(entity historyCaseEntity) CaseHistoryRecord {
 			VideoInsights: decodeStringList(entity.PayloadVideoInsights),
 			AudioInsights: decodeStringList(entity.PayloadAudioInsights),
 			ImageInsights: decodeStringList(entity.PayloadImageInsights),
+			ExtraInputs:   decodeModalityLists(entity.PayloadExtraInputs),
+			ExtraInsights: decodeModalityLists(entity.PayloadExtraInsights),
 		},
+		RiskBreakdown: decodeRiskBreakdown(entity.RiskBreakdown),
+		MediaPurgedAt: entity.MediaPurgedAt,
+		Indicators:    decodeIndicators(entity.Indicators),
 	}
 }
 
@@ -735,6 +918,7 @@ func taskFromHistoryEntity(entity historyCaseEntity) TaskRecord {
 		ScamType:    strings.TrimSpace(record.ScamType),
 		RiskScore:   normalizeRiskScore(record.RiskScore),
 		RiskSummary: strings.TrimSpace(record.RiskSummary),
+		RuleVersion: strings.TrimSpace(record.RuleVersion),
 		CreatedAt:   record.CreatedAt,
 		UpdatedAt:   entity.UpdatedAt,
 		Payload: TaskPayload{
@@ -745,9 +929,13 @@ func taskFromHistoryEntity(entity historyCaseEntity) TaskRecord {
 			VideoInsights: append([]string{}, record.Payload.VideoInsights...),
 			AudioInsights: append([]string{}, record.Payload.AudioInsights...),
 			ImageInsights: append([]string{}, record.Payload.ImageInsights...),
+			ExtraInputs:   model.CloneModalityLists(record.Payload.ExtraInputs),
+			ExtraInsights: model.CloneModalityLists(record.Payload.ExtraInsights),
 		},
-		Summary: strings.TrimSpace(record.CaseSummary),
-		Report:  report,
+		Summary:       strings.TrimSpace(record.CaseSummary),
+		Report:        report,
+		MediaPurgedAt: record.MediaPurgedAt,
+		Indicators:    record.Indicators,
 	}
 }
 
@@ -800,6 +988,95 @@ func decodeStringList(value string) []string {
 	return result
 }
 
+// CloneModalityLists 深拷贝按模态名分组的列表（扩展模态的输入或解读）。
+func CloneModalityLists(source map[string][]string) map[string][]string {
+	return model.CloneModalityLists(source)
+}
+
+// encodeModalityLists 将按模态名分组的列表编码为 JSON 对象字符串，空 map 返回空字符串。
+func encodeModalityLists(lists map[string][]string) string {
+	cloned := model.CloneModalityLists(lists)
+	if len(cloned) == 0 {
+		return ""
+	}
+	encoded, err := json.Marshal(cloned)
+	if err != nil {
+		return ""
+	}
+	return string(encoded)
+}
+
+// decodeModalityLists 与 encodeModalityLists 成对使用，解析失败时按空处理。
+func decodeModalityLists(value string) map[string][]string {
+	trimmed := strings.TrimSpace(value)
+	if trimmed == "" {
+		return nil
+	}
+	decoded := map[string][]string{}
+	if err := json.Unmarshal([]byte(trimmed), &decoded); err != nil {
+		return nil
+	}
+	return model.CloneModalityLists(decoded)
+}
+
+// encodeRiskBreakdown 将评分明细编码为 JSON 字符串，空明细存空串。
+func encodeRiskBreakdown(breakdown *RiskBreakdown) string {
+	if breakdown == nil {
+		return ""
+	}
+	encoded, err := json.Marshal(breakdown)
+	if err != nil {
+		return ""
+	}
+	return string(encoded)
+}
+
+// decodeRiskBreakdown 与 encodeRiskBreakdown 成对使用，解析失败时按无明细处理。
+func decodeRiskBreakdown(value string) *RiskBreakdown {
+	trimmed := strings.TrimSpace(value)
+	if trimmed == "" {
+		return nil
+	}
+	decoded := RiskBreakdown{}
+	if err := json.Unmarshal([]byte(trimmed), &decoded); err != nil {
+		return nil
+	}
+	return &decoded
+}
+
+// cloneRiskBreakdown 深拷贝评分明细，避免调用方后续修改影响已归档记录。
+func cloneRiskBreakdown(breakdown *RiskBreakdown) *RiskBreakdown {
+	if breakdown == nil {
+		return nil
+	}
+	return decodeRiskBreakdown(encodeRiskBreakdown(breakdown))
+}
+
+// encodeIndicators 将结构化线索编码为 JSON 字符串，空列表存空串。
+func encodeIndicators(indicators []Indicator) string {
+	if len(indicators) == 0 {
+		return ""
+	}
+	encoded, err := json.Marshal(indicators)
+	if err != nil {
+		return ""
+	}
+	return string(encoded)
+}
+
+// decodeIndicators 与 encodeIndicators 成对使用，解析失败时按无线索处理。
+func decodeIndicators(value string) []Indicator {
+	trimmed := strings.TrimSpace(value)
+	if trimmed == "" {
+		return nil
+	}
+	decoded := make([]Indicator, 0)
+	if err := json.Unmarshal([]byte(trimmed), &decoded); err != nil || len(decoded) == 0 {
+		return nil
+	}
+	return decoded
+}
+
 // firstNonEmpty 返回参数列表中第一个非空（trim 后）字符串。
 // 用途：在多候选值场景下做兜底选择。
 func firstNonEmpty(values ...string) string {
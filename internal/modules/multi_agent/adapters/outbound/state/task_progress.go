@@ -0,0 +1,179 @@
+package state
+
+import (
+	"encoding/json"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 任务进度阶段。阶段事件按发生顺序写入 task_progress_events，供 SSE 接口实时推送与断线续读。
+const (
+	TaskProgressStageMediaNormalized   = "media_normalized"
+	TaskProgressStageQueued            = "queued"
+	TaskProgressStageStarted           = "started"
+	TaskProgressStageSubAgentCompleted = "sub_agent_completed"
+	TaskProgressStageInsightsSaved     = "insights_saved"
+	TaskProgressStageMainAgentRound    = "main_agent_round"
+	TaskProgressStageToolCall          = "tool_call"
+	TaskProgressStageFinalReport       = "final_report"
+)
+
+const (
+	defaultTaskProgressEventQueryLimit  = 200
+	maxTaskProgressEventMessageRuneSize = 255
+)
+
+var taskProgressSubscribersMu sync.Mutex
+var taskProgressSubscribers = map[string]map[chan struct{}]struct{}{}
+
+// AppendTaskProgressEvent 追加一条任务阶段事件，并唤醒本进程内订阅该任务的 SSE 连接。
+// 事件写入失败只记录日志，不影响分析主流程。
+func AppendTaskProgressEvent(userID, taskID, stage, message string, data map[string]interface{}) {
+	db := currentStateDB()
+	if db == nil {
+		return
+	}
+	ensureStateSchema(db)
+
+	uid := normalizeUserID(userID)
+	tid := strings.TrimSpace(taskID)
+	normalizedStage := strings.TrimSpace(stage)
+	if tid == "" || normalizedStage == "" {
+		return
+	}
+
+	entity := taskProgressEventEntity{
+		TaskID:    tid,
+		UserID:    uid,
+		Stage:     normalizedStage,
+		Message:   truncateProgressMessage(message),
+		Data:      encodeProgressData(data),
+		CreatedAt: time.Now(),
+	}
+	if err := db.Create(&entity).Error; err != nil {
+		log.Printf("[state] append task progress failed: user=%s task=%s stage=%s err=%v", uid, tid, normalizedStage, err)
+		return
+	}
+	notifyTaskProgress(tid)
+}
+
+// ListTaskProgressEvents 按 ID 升序返回 afterID 之后的任务阶段事件。
+// limit <= 0 时使用默认上限，避免单次读取过多事件。
+func ListTaskProgressEvents(userID, taskID string, afterID uint64, limit int) ([]TaskProgressEvent, error) {
+	db := currentStateDB()
+	if db == nil {
+		return []TaskProgressEvent{}, nil
+	}
+	ensureStateSchema(db)
+
+	uid := normalizeUserID(userID)
+	tid := strings.TrimSpace(taskID)
+	if tid == "" {
+		return []TaskProgressEvent{}, nil
+	}
+	if limit <= 0 {
+		limit = defaultTaskProgressEventQueryLimit
+	}
+
+	rows := make([]taskProgressEventEntity, 0)
+	if err := db.Where("task_id = ? AND user_id = ? AND id > ?", tid, uid, afterID).
+		Order("id asc").
+		Limit(limit).
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	events := make([]TaskProgressEvent, 0, len(rows))
+	for _, row := range rows {
+		events = append(events, taskProgressEventFromEntity(row))
+	}
+	return events, nil
+}
+
+// SubscribeTaskProgress 订阅本进程内指定任务的新事件通知，返回的 cancel 必须在连接结束时调用。
+// 通知只表示“有新事件”，调用方仍需通过 ListTaskProgressEvents 读取；
+// 其他实例写入的事件不会触发通知，调用方应配合定时轮询兜底。
+func SubscribeTaskProgress(taskID string) (<-chan struct{}, func()) {
+	tid := strings.TrimSpace(taskID)
+	ch := make(chan struct{}, 1)
+
+	taskProgressSubscribersMu.Lock()
+	subscribers := taskProgressSubscribers[tid]
+	if subscribers == nil {
+		subscribers = map[chan struct{}]struct{}{}
+		taskProgressSubscribers[tid] = subscribers
+	}
+	subscribers[ch] = struct{}{}
+	taskProgressSubscribersMu.Unlock()
+
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			taskProgressSubscribersMu.Lock()
+			defer taskProgressSubscribersMu.Unlock()
+			delete(taskProgressSubscribers[tid], ch)
+			if len(taskProgressSubscribers[tid]) == 0 {
+				delete(taskProgressSubscribers, tid)
+			}
+		})
+	}
+	return ch, cancel
+}
+
+func notifyTaskProgress(taskID string) {
+	taskProgressSubscribersMu.Lock()
+	defer taskProgressSubscribersMu.Unlock()
+	for ch := range taskProgressSubscribers[taskID] {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// deleteTaskProgressEvents 删除任务的全部阶段事件，随历史案件一同清理。
+func deleteTaskProgressEvents(db *gorm.DB, userID, taskID string) {
+	if err := db.Where("task_id = ? AND user_id = ?", taskID, userID).Delete(&taskProgressEventEntity{}).Error; err != nil {
+		log.Printf("[state] delete task progress failed: user=%s task=%s err=%v", userID, taskID, err)
+	}
+}
+
+func taskProgressEventFromEntity(entity taskProgressEventEntity) TaskProgressEvent {
+	event := TaskProgressEvent{
+		ID:        entity.ID,
+		TaskID:    entity.TaskID,
+		Stage:     entity.Stage,
+		Message:   entity.Message,
+		CreatedAt: entity.CreatedAt,
+	}
+	if strings.TrimSpace(entity.Data) != "" {
+		data := map[string]interface{}{}
+		if err := json.Unmarshal([]byte(entity.Data), &data); err == nil && len(data) > 0 {
+			event.Data = data
+		}
+	}
+	return event
+}
+
+func encodeProgressData(data map[string]interface{}) string {
+	if len(data) == 0 {
+		return ""
+	}
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return ""
+	}
+	return string(encoded)
+}
+
+func truncateProgressMessage(message string) string {
+	runes := []rune(strings.TrimSpace(message))
+	if len(runes) <= maxTaskProgressEventMessageRuneSize {
+		return string(runes)
+	}
+	return string(runes[:maxTaskProgressEventMessageRuneSize])
+}
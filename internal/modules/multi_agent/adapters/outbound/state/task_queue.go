@@ -0,0 +1,347 @@
+package state
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ClaimNextPendingTask 以租约方式领取下一个待处理任务。
+// 规则：
+// 1) 按 created_at 先进先出，只领取 pending 状态任务；
+// 2) globalLimit > 0 时，当前 processing 任务数达到上限则不领取；
+// 3) perUserLimit > 0 时，跳过 processing 任务数已达上限的用户；
+// 4) 领取成功后写入 lease_owner/lease_expires_at/heartbeat_at 并累加 attempts。
+func ClaimNextPendingTask(workerID string, leaseTTL time.Duration, globalLimit int, perUserLimit int) (TaskRecord, bool) {
+	db := currentStateDB()
+	if db == nil {
+		return TaskRecord{}, false
+	}
+	ensureStateSchema(db)
+
+	owner := strings.TrimSpace(workerID)
+	if owner == "" || leaseTTL <= 0 {
+		return TaskRecord{}, false
+	}
+
+	var claimed pendingTaskEntity
+	found := false
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if globalLimit > 0 {
+			var processing int64
+			if err := tx.Model(&pendingTaskEntity{}).Where("status = ?", TaskStatusProcessing).Count(&processing).Error; err != nil {
+				return err
+			}
+			if processing >= int64(globalLimit) {
+				return nil
+			}
+		}
+
+		query := tx.Model(&pendingTaskEntity{}).Where("status = ?", TaskStatusPending)
+		if perUserLimit > 0 {
+			busyUsers := tx.Model(&pendingTaskEntity{}).
+				Select("user_id").
+				Where("status = ?", TaskStatusProcessing).
+				Group("user_id").
+				Having("COUNT(*) >= ?", perUserLimit)
+			query = query.Where("user_id NOT IN (?)", busyUsers)
+		}
+
+		var candidate pendingTaskEntity
+		result := query.Order("created_at asc").Limit(1).Find(&candidate)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		now := time.Now()
+		leaseExpiresAt := now.Add(leaseTTL)
+		update := tx.Model(&pendingTaskEntity{}).
+			Where("task_id = ? AND status = ?", candidate.TaskID, TaskStatusPending).
+			Updates(map[string]interface{}{
+				"status":           TaskStatusProcessing,
+				"lease_owner":      owner,
+				"lease_expires_at": leaseExpiresAt,
+				"heartbeat_at":     now,
+				"attempts":         gorm.Expr("attempts + 1"),
+				"updated_at":       now,
+			})
+		if update.Error != nil {
+			return update.Error
+		}
+		if update.RowsAffected == 0 {
+			return nil
+		}
+
+		candidate.Status = TaskStatusProcessing
+		candidate.LeaseOwner = owner
+		candidate.LeaseExpiresAt = &leaseExpiresAt
+		candidate.HeartbeatAt = &now
+		candidate.Attempts++
+		candidate.UpdatedAt = now
+		claimed = candidate
+		found = true
+		return nil
+	})
+	if err != nil {
+		log.Printf("[state] claim pending task failed: worker=%s err=%v", owner, err)
+		return TaskRecord{}, false
+	}
+	if !found {
+		return TaskRecord{}, false
+	}
+	task := taskFromPendingEntity(claimed)
+	// 分析智能体直接消费 data URL，领取时把 blob 引用还原为内联内容；还原失败时保留引用，由分析阶段报错。
+	if payload, err := hydratePayloadMedia(task.Payload); err != nil {
+		log.Printf("[state] hydrate task media failed: task=%s err=%v", task.TaskID, err)
+	} else {
+		task.Payload = payload
+	}
+	return task, true
+}
+
+// RenewTaskLease 由持有租约的 worker 定期调用，刷新心跳并延长租约。
+// 返回 false 表示租约已丢失（任务已被回收、取消或完成），调用方应停止续期。
+func RenewTaskLease(taskID, workerID string, leaseTTL time.Duration) bool {
+	db := currentStateDB()
+	if db == nil {
+		return false
+	}
+	ensureStateSchema(db)
+
+	tid := strings.TrimSpace(taskID)
+	owner := strings.TrimSpace(workerID)
+	if tid == "" || owner == "" || leaseTTL <= 0 {
+		return false
+	}
+
+	now := time.Now()
+	result := db.Model(&pendingTaskEntity{}).
+		Where("task_id = ? AND lease_owner = ? AND status = ?", tid, owner, TaskStatusProcessing).
+		Updates(map[string]interface{}{
+			"lease_expires_at": now.Add(leaseTTL),
+			"heartbeat_at":     now,
+			"updated_at":       now,
+		})
+	if result.Error != nil {
+		log.Printf("[state] renew task lease failed: task=%s worker=%s err=%v", tid, owner, result.Error)
+		return false
+	}
+	return result.RowsAffected > 0
+}
+
+// RecoverExpiredTaskLeases 回收租约已过期的 processing 任务。
+// 规则：
+// 1) 进程重启或 worker 异常退出后，其持有的租约不再续期，过期后由本函数回收；
+// 2) 旧版本遗留的无租约 processing 记录同样视为过期；
+// 3) attempts 未达到 maxAttempts 的任务重置为 pending 等待重新领取；
+// 4) 已达到 maxAttempts 的任务转为失败并归档到历史，避免反复崩溃的任务无限重试。
+// 5) 已被用户取消但持有者已失联的任务直接按取消归档。
+func RecoverExpiredTaskLeases(maxAttempts int) (requeued int, failed int) {
+	db := currentStateDB()
+	if db == nil {
+		return 0, 0
+	}
+	ensureStateSchema(db)
+
+	now := time.Now()
+	expiredRows := make([]pendingTaskEntity, 0)
+	if err := db.Model(&pendingTaskEntity{}).
+		Select("task_id", "user_id", "status", "attempts").
+		Where("status IN ?", []string{TaskStatusProcessing, TaskStatusCancelled}).
+		Where("lease_expires_at IS NULL OR lease_expires_at <= ?", now).
+		Find(&expiredRows).Error; err != nil {
+		log.Printf("[state] query expired task leases failed: err=%v", err)
+		return 0, 0
+	}
+
+	for _, row := range expiredRows {
+		tid := strings.TrimSpace(row.TaskID)
+		if tid == "" {
+			continue
+		}
+		if strings.TrimSpace(row.Status) == TaskStatusCancelled {
+			MarkTaskCancelled(row.UserID, tid)
+			continue
+		}
+		if maxAttempts > 0 && row.Attempts >= maxAttempts {
+			MarkTaskFailed(row.UserID, tid, "", fmt.Sprintf("task interrupted before completion after %d attempts", row.Attempts))
+			failed++
+			continue
+		}
+
+		result := db.Model(&pendingTaskEntity{}).
+			Where("task_id = ? AND status = ?", tid, TaskStatusProcessing).
+			Where("lease_expires_at IS NULL OR lease_expires_at <= ?", now).
+			Updates(map[string]interface{}{
+				"status":           TaskStatusPending,
+				"lease_owner":      "",
+				"lease_expires_at": nil,
+				"updated_at":       now,
+			})
+		if result.Error != nil {
+			log.Printf("[state] requeue expired task failed: user=%s task=%s err=%v", row.UserID, tid, result.Error)
+			continue
+		}
+		if result.RowsAffected > 0 {
+			requeued++
+		}
+	}
+	return requeued, failed
+}
+
+// CancelTask 取消当前用户的进行中任务。
+// 规则：
+// 1) pending 状态的任务尚未被领取，直接以 cancelled 状态归档到历史；
+// 2) processing 状态的任务先标记为 cancelled，由持有租约的 worker 中止分析后调用 MarkTaskCancelled 归档；
+// 3) 已是 cancelled 状态时幂等返回成功；
+// 4) 任务不在 pending_tasks 中（不存在或已结束）时返回 false。
+func CancelTask(userID, taskID string) (TaskRecord, bool, error) {
+	db := currentStateDB()
+	if db == nil {
+		return TaskRecord{}, false, fmt.Errorf("state db is not initialized")
+	}
+	ensureStateSchema(db)
+
+	uid := normalizeUserID(userID)
+	tid := strings.TrimSpace(taskID)
+	if tid == "" {
+		return TaskRecord{}, false, nil
+	}
+
+	var cancelled TaskRecord
+	found := false
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var pending pendingTaskEntity
+		query := tx.Where("task_id = ? AND user_id = ?", tid, uid).Limit(1).Find(&pending)
+		if query.Error != nil {
+			return query.Error
+		}
+		if query.RowsAffected == 0 {
+			return nil
+		}
+		found = true
+
+		now := time.Now()
+		switch strings.TrimSpace(pending.Status) {
+		case TaskStatusPending:
+			history := cancelledHistoryFromPending(pending, now)
+			if err := tx.Where("record_id = ? AND user_id = ?", tid, uid).Delete(&historyCaseEntity{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Create(&history).Error; err != nil {
+				return err
+			}
+			if err := setHistoryBlobReferences(tx, history); err != nil {
+				return err
+			}
+			if err := tx.Where("task_id = ? AND user_id = ?", tid, uid).Delete(&pendingTaskEntity{}).Error; err != nil {
+				return err
+			}
+			if err := releaseBlobReferences(tx, blobOwnerPendingTask, tid); err != nil {
+				return err
+			}
+			cancelled = taskFromHistoryEntity(history)
+			return nil
+		case TaskStatusProcessing:
+			if err := tx.Model(&pendingTaskEntity{}).
+				Where("task_id = ? AND user_id = ? AND status = ?", tid, uid, TaskStatusProcessing).
+				Updates(map[string]interface{}{
+					"status":     TaskStatusCancelled,
+					"updated_at": now,
+				}).Error; err != nil {
+				return err
+			}
+			pending.Status = TaskStatusCancelled
+			pending.UpdatedAt = now
+		}
+		cancelled = taskFromPendingEntity(pending)
+		return nil
+	})
+	if err != nil {
+		log.Printf("[state] cancel task failed: user=%s task=%s err=%v", uid, tid, err)
+		return TaskRecord{}, false, err
+	}
+	return cancelled, found, nil
+}
+
+// MarkTaskCancelled 将已标记 cancelled 的进行中任务归档到历史。
+// 说明：
+// 1) 仅处理 status=cancelled 的记录，租约被回收后重新排队的任务不受影响；
+// 2) 若分析流程在取消前已通过工具写入历史，则保留已有历史，仅删除 pending 记录。
+func MarkTaskCancelled(userID, taskID string) {
+	db := currentStateDB()
+	if db == nil {
+		return
+	}
+	ensureStateSchema(db)
+
+	uid := normalizeUserID(userID)
+	tid := strings.TrimSpace(taskID)
+	if tid == "" {
+		return
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var pending pendingTaskEntity
+		query := tx.Where("task_id = ? AND user_id = ? AND status = ?", tid, uid, TaskStatusCancelled).Limit(1).Find(&pending)
+		if query.Error != nil {
+			return query.Error
+		}
+		if query.RowsAffected == 0 {
+			return nil
+		}
+
+		var existing int64
+		if err := tx.Model(&historyCaseEntity{}).Where("record_id = ? AND user_id = ?", tid, uid).Count(&existing).Error; err != nil {
+			return err
+		}
+		if existing == 0 {
+			history := cancelledHistoryFromPending(pending, time.Now())
+			if err := tx.Create(&history).Error; err != nil {
+				return err
+			}
+			if err := setHistoryBlobReferences(tx, history); err != nil {
+				return err
+			}
+		}
+		if err := tx.Where("task_id = ? AND user_id = ?", tid, uid).Delete(&pendingTaskEntity{}).Error; err != nil {
+			return err
+		}
+		return releaseBlobReferences(tx, blobOwnerPendingTask, tid)
+	})
+	if err != nil {
+		log.Printf("[state] mark cancelled failed: user=%s task=%s err=%v", uid, tid, err)
+	}
+}
+
+// cancelledHistoryFromPending 构造取消任务的历史归档实体。
+// 取消任务没有风险结论，风险等级固定为“低”，避免触发风险告警或抬高历史风险统计。
+func cancelledHistoryFromPending(pending pendingTaskEntity, now time.Time) historyCaseEntity {
+	reason := "task cancelled by user"
+	return historyCaseEntity{
+		RecordID:             strings.TrimSpace(pending.TaskID),
+		UserID:               normalizeUserID(pending.UserID),
+		Title:                normalizeCaseTitle(pending.Title, reason),
+		CaseSummary:          reason,
+		Status:               TaskStatusCancelled,
+		RiskLevel:            normalizeRiskLevel("低"),
+		PayloadText:          pending.PayloadText,
+		PayloadVideos:        pending.PayloadVideos,
+		PayloadAudios:        pending.PayloadAudios,
+		PayloadImages:        pending.PayloadImages,
+		PayloadVideoInsights: pending.PayloadVideoInsights,
+		PayloadAudioInsights: pending.PayloadAudioInsights,
+		PayloadImageInsights: pending.PayloadImageInsights,
+		PayloadExtraInputs:   pending.PayloadExtraInputs,
+		PayloadExtraInsights: pending.PayloadExtraInsights,
+		Report:               reason,
+		CreatedAt:            pending.CreatedAt,
+		UpdatedAt:            now,
+	}
+}
@@ -0,0 +1,43 @@
+package state_test
+
+import (
+	"testing"
+
+	"antifraud/internal/modules/multi_agent/adapters/outbound/state"
+)
+
+func TestTaskIndicators_ArchivedRestoredAndSearchableByFingerprint(t *testing.T) {
+	setupStateBlobStore(t)
+	const phoneFingerprint = "9f2b5c0e6a1d4b7f8e3c2a1b0d9e8f7a6b5c4d3e2f1a0b9c8d7e6f5a4b3c2d1e"
+	task := state.CreateTask("u-indicator", state.TaskPayload{Text: "请回拨[电话号码_1]"})
+	state.SaveTaskRedactionMapping("u-indicator", task.TaskID, map[string]string{"[电话号码_1]": "13800138000"})
+	state.UpdateTaskIndicators("u-indicator", task.TaskID, []state.Indicator{
+		{Kind: "phone", Value: "[电话号码_1]", Fingerprint: phoneFingerprint, Sources: []string{"text"}},
+		{Kind: "app", Value: "安心贷", Fingerprint: "a1", Sources: []string{"text", "image"}},
+	})
+
+	pending, exists := state.GetTaskDetailByID("u-indicator", task.TaskID)
+	if !exists || len(pending.Indicators) != 2 {
+		t.Fatalf("expected pending task to carry indicators, got %+v", pending)
+	}
+
+	state.MarkTaskCompleted("u-indicator", task.TaskID, "", "报告")
+	records := state.ListCaseHistoryByIndicator("u-indicator", phoneFingerprint)
+	if len(records) != 1 || records[0].RecordID != task.TaskID {
+		t.Fatalf("expected archived case found by fingerprint, got %+v", records)
+	}
+	if records[0].Indicators[0].Value != "[电话号码_1]" {
+		t.Fatalf("expected stored indicator to keep placeholder, got %+v", records[0].Indicators)
+	}
+	restored := state.RestoreCaseHistoryPII(records[0])
+	if restored.Indicators[0].Value != "13800138000" || len(restored.Indicators[1].Sources) != 2 {
+		t.Fatalf("unexpected restored indicators: %+v", restored.Indicators)
+	}
+
+	if others := state.ListCaseHistoryByIndicator("u-other", phoneFingerprint); len(others) != 0 {
+		t.Fatalf("expected lookup scoped to owner, got %+v", others)
+	}
+	if wildcard := state.ListCaseHistoryByIndicator("u-indicator", "%"); len(wildcard) != 0 {
+		t.Fatalf("expected wildcard fingerprint rejected, got %+v", wildcard)
+	}
+}
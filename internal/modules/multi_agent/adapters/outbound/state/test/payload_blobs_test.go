@@ -0,0 +1,174 @@
+package state_test
+
+import (
+	"context"
+	"encoding/base64"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"antifraud/internal/modules/multi_agent/adapters/outbound/blobstore"
+	"antifraud/internal/modules/multi_agent/adapters/outbound/state"
+	statemodel "antifraud/internal/modules/multi_agent/adapters/outbound/state/model"
+	"antifraud/internal/platform/database"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func setupStateBlobStore(t *testing.T) (*gorm.DB, *blobstore.Store) {
+	t.Helper()
+
+	oldDB := database.DB
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "state_blob_test.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open sqlite failed: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("get sql db failed: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	database.DB = db
+	if err := database.InitMainDBSchemas(); err != nil {
+		t.Fatalf("init main db schemas failed: %v", err)
+	}
+	backend, err := blobstore.NewLocalBackend(t.TempDir())
+	if err != nil {
+		t.Fatalf("create blob backend failed: %v", err)
+	}
+	store := blobstore.NewStore(backend, blobstore.Options{OrphanGrace: time.Minute})
+	previous := blobstore.SetDefaultStore(store)
+	t.Cleanup(func() {
+		blobstore.SetDefaultStore(previous)
+		database.DB = oldDB
+		_ = sqlDB.Close()
+	})
+	return db, store
+}
+
+func dataURL(mimeType string, raw string) string {
+	return "data:" + mimeType + ";base64," + base64.StdEncoding.EncodeToString([]byte(raw))
+}
+
+func TestPayloadMedia_StoredAsBlobReferencesAcrossTaskLifecycle(t *testing.T) {
+	db, store := setupStateBlobStore(t)
+	image := dataURL("image/png", "png-bytes")
+
+	task := state.CreateTask("u-1", state.TaskPayload{
+		Text:        "对方发来截图",
+		Images:      []string{image},
+		ExtraInputs: map[string][]string{"pdf": {dataURL("application/pdf", "pdf-bytes"), "https://example.com/a.pdf"}},
+	})
+	key, ok := blobstore.ParseRef(task.Payload.Images[0])
+	if !ok {
+		t.Fatalf("expected image stored as blob ref, got %q", task.Payload.Images[0])
+	}
+	if task.Payload.ExtraInputs["pdf"][1] != "https://example.com/a.pdf" {
+		t.Fatalf("expected non data url input kept, got %+v", task.Payload.ExtraInputs)
+	}
+	var pending statemodel.PendingTaskEntity
+	if err := db.Where("task_id = ?", task.TaskID).First(&pending).Error; err != nil {
+		t.Fatalf("load pending row failed: %v", err)
+	}
+	if strings.Contains(pending.PayloadImages+pending.PayloadExtraInputs, base64.StdEncoding.EncodeToString([]byte("png-bytes"))) {
+		t.Fatal("expected pending row to hold no inline media")
+	}
+	if blob, _ := store.Stat(key); blob.RefCount != 1 || blob.MIME != "image/png" {
+		t.Fatalf("expected pending task to reference blob, got %+v", blob)
+	}
+
+	claimed, ok := state.ClaimNextPendingTask("worker-1", time.Minute, 0, 0)
+	if !ok || claimed.Payload.Images[0] != image {
+		t.Fatalf("expected claimed task hydrated to data url, got %+v ok=%v", claimed.Payload.Images, ok)
+	}
+
+	// 分析过程中归档历史使用已还原的载荷，相同内容复用同一 blob。
+	record := state.AddCaseHistory(state.CaseHistoryRecord{
+		RecordID:    task.TaskID,
+		UserID:      "u-1",
+		Title:       "截图诈骗",
+		CaseSummary: "疑似冒充客服",
+		RiskLevel:   "高",
+		RiskScore:   80,
+		Payload:     claimed.Payload,
+		Report:      "report",
+	})
+	if record.Payload.Images[0] != blobstore.Ref(key) {
+		t.Fatalf("expected history to reference same blob, got %q", record.Payload.Images[0])
+	}
+	state.MarkTaskCompleted("u-1", task.TaskID, "", "report")
+	if blob, _ := store.Stat(key); blob.RefCount != 1 {
+		t.Fatalf("expected reference moved from pending to history, got ref_count=%d", blob.RefCount)
+	}
+	detail, ok := state.GetTaskDetailByID("u-1", task.TaskID)
+	if !ok || len(state.PayloadBlobKeys(detail.Payload)) != 2 {
+		t.Fatalf("expected history detail to list blob refs, got %+v ok=%v", detail.Payload, ok)
+	}
+
+	if deleted, err := state.DeleteCaseHistory("u-1", task.TaskID); err != nil || !deleted {
+		t.Fatalf("delete history failed: deleted=%v err=%v", deleted, err)
+	}
+	if blob, _ := store.Stat(key); blob.RefCount != 0 {
+		t.Fatalf("expected reference released on delete, got ref_count=%d", blob.RefCount)
+	}
+	if swept, err := store.SweepOrphans(context.Background(), time.Now().Add(2*time.Minute)); err != nil || swept != 2 {
+		t.Fatalf("expected orphaned blobs swept, swept=%d err=%v", swept, err)
+	}
+}
+
+func TestMigrateInlinePayloadMedia_RewritesLegacyRows(t *testing.T) {
+	db, store := setupStateBlobStore(t)
+	video := dataURL("video/mp4", "legacy-video")
+	encode := func(item string) string { return base64.StdEncoding.EncodeToString([]byte(item)) }
+
+	now := time.Now()
+	if err := db.Create(&statemodel.HistoryCaseEntity{
+		RecordID:      "TASK-LEGACY",
+		UserID:        "u-1",
+		Title:         "旧记录",
+		Status:        state.TaskStatusCompleted,
+		PayloadVideos: encode(video),
+		PayloadImages: encode("https://example.com/a.png"),
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}).Error; err != nil {
+		t.Fatalf("insert legacy history failed: %v", err)
+	}
+	if err := db.Create(&statemodel.PendingTaskEntity{
+		TaskID:        "TASK-PENDING",
+		UserID:        "u-1",
+		Title:         "旧任务",
+		Status:        state.TaskStatusPending,
+		PayloadVideos: encode(video),
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}).Error; err != nil {
+		t.Fatalf("insert legacy pending failed: %v", err)
+	}
+
+	migrated, err := state.MigrateInlinePayloadMedia()
+	if err != nil || migrated != 2 {
+		t.Fatalf("expected two rows migrated, got %d err=%v", migrated, err)
+	}
+	if again, err := state.MigrateInlinePayloadMedia(); err != nil || again != 0 {
+		t.Fatalf("expected migration idempotent, got %d err=%v", again, err)
+	}
+
+	detail, ok := state.GetTaskDetailByID("u-1", "TASK-LEGACY")
+	if !ok || len(detail.Payload.Videos) != 1 || detail.Payload.Images[0] != "https://example.com/a.png" {
+		t.Fatalf("unexpected migrated history payload: %+v ok=%v", detail.Payload, ok)
+	}
+	key, ok := blobstore.ParseRef(detail.Payload.Videos[0])
+	if !ok {
+		t.Fatalf("expected migrated video ref, got %q", detail.Payload.Videos[0])
+	}
+	blob, raw, err := store.ReadAll(context.Background(), key)
+	if err != nil || string(raw) != "legacy-video" || blob.MIME != "video/mp4" || blob.RefCount != 2 {
+		t.Fatalf("unexpected migrated blob %+v content=%q err=%v", blob, raw, err)
+	}
+}
@@ -0,0 +1,75 @@
+package state_test
+
+import (
+	"testing"
+	"time"
+
+	"antifraud/internal/modules/multi_agent/adapters/outbound/state"
+)
+
+func TestTaskRedactionMapping_MergesRestoresAndDeletesWithHistory(t *testing.T) {
+	setupStateBlobStore(t)
+	task := state.CreateTask("u-pii", state.TaskPayload{Text: "我叫[姓名_1]，电话[电话号码_1]"})
+	state.SaveTaskRedactionMapping("u-pii", task.TaskID, map[string]string{"[姓名_1]": "张三", "[电话号码_1]": "13800138000"})
+	state.SaveTaskRedactionMapping("u-pii", task.TaskID, map[string]string{"[姓名_1]": "覆盖值", "[地址_1]": "幸福小区5栋"})
+
+	mapping := state.GetTaskRedactionMapping("u-pii", task.TaskID)
+	if len(mapping) != 3 || mapping["[姓名_1]"] != "张三" || mapping["[地址_1]"] != "幸福小区5栋" {
+		t.Fatalf("expected merged mapping keeping existing values, got %+v", mapping)
+	}
+	if other := state.GetTaskRedactionMapping("u-other", task.TaskID); len(other) != 0 {
+		t.Fatalf("expected mapping scoped to owner, got %+v", other)
+	}
+
+	state.UpdateTaskInsights("u-pii", task.TaskID, map[string][]string{state.ModalityImage: {"截图中收款人为[姓名_1]"}})
+	state.MarkTaskCompleted("u-pii", task.TaskID, "", "[姓名_1]，请勿向[电话号码_1]回拨")
+	detail, exists := state.GetTaskDetailByID("u-pii", task.TaskID)
+	if !exists || detail.Payload.Text != "我叫[姓名_1]，电话[电话号码_1]" {
+		t.Fatalf("expected stored task to keep placeholders, got %+v", detail)
+	}
+	restored := state.RestoreTaskPII(detail)
+	if restored.Payload.Text != "我叫张三，电话13800138000" || restored.Report != "张三，请勿向13800138000回拨" {
+		t.Fatalf("unexpected restored task: %+v", restored)
+	}
+	if len(restored.Payload.ImageInsights) != 1 || restored.Payload.ImageInsights[0] != "截图中收款人为张三" {
+		t.Fatalf("expected insights restored, got %+v", restored.Payload.ImageInsights)
+	}
+
+	if deleted, err := state.DeleteCaseHistory("u-pii", task.TaskID); err != nil || !deleted {
+		t.Fatalf("delete history failed: deleted=%t err=%v", deleted, err)
+	}
+	if mapping := state.GetTaskRedactionMapping("u-pii", task.TaskID); len(mapping) != 0 {
+		t.Fatalf("expected mapping deleted with history, got %+v", mapping)
+	}
+}
+
+func TestCreateTaskWithRedactionMapping_SavesMappingWithTaskRow(t *testing.T) {
+	setupStateBlobStore(t)
+	task := state.CreateTaskWithRedactionMapping("u-pii", state.TaskPayload{Text: "回拨[电话号码_1]"}, map[string]string{"[电话号码_1]": "13800138000"})
+
+	claimed, ok := state.ClaimNextPendingTask("worker-A", time.Minute, 0, 0)
+	if !ok || claimed.TaskID != task.TaskID {
+		t.Fatalf("expected task claimable, got %+v ok=%v", claimed, ok)
+	}
+	if mapping := state.GetTaskRedactionMapping("u-pii", task.TaskID); mapping["[电话号码_1]"] != "13800138000" {
+		t.Fatalf("expected mapping committed with task row, got %+v", mapping)
+	}
+	// worker 回写映射只追加新占位符，不覆盖入队时保存的原文。
+	state.SaveTaskRedactionMapping("u-pii", task.TaskID, map[string]string{"[电话号码_1]": "13900139000", "[姓名_1]": "张三"})
+	if mapping := state.GetTaskRedactionMapping("u-pii", task.TaskID); mapping["[电话号码_1]"] != "13800138000" || mapping["[姓名_1]"] != "张三" {
+		t.Fatalf("expected worker mapping merged, got %+v", mapping)
+	}
+}
+
+func TestPurgeUserData_DeletesRedactionMappings(t *testing.T) {
+	setupStateBlobStore(t)
+	task := state.CreateTask("u-purge-pii", state.TaskPayload{Text: "[电话号码_1]"})
+	state.SaveTaskRedactionMapping("u-purge-pii", task.TaskID, map[string]string{"[电话号码_1]": "13800138000"})
+
+	if _, err := state.PurgeUserData("u-purge-pii"); err != nil {
+		t.Fatalf("purge user data failed: %v", err)
+	}
+	if mapping := state.GetTaskRedactionMapping("u-purge-pii", task.TaskID); len(mapping) != 0 {
+		t.Fatalf("expected mapping purged, got %+v", mapping)
+	}
+}
@@ -0,0 +1,192 @@
+package state_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"antifraud/internal/modules/multi_agent/adapters/outbound/blobstore"
+	"antifraud/internal/modules/multi_agent/adapters/outbound/state"
+	statemodel "antifraud/internal/modules/multi_agent/adapters/outbound/state/model"
+
+	"gorm.io/gorm"
+)
+
+func backdateHistory(t *testing.T, db *gorm.DB, recordID string, age time.Duration) {
+	t.Helper()
+	createdAt := time.Now().Add(-age)
+	if err := db.Model(&statemodel.HistoryCaseEntity{}).
+		Where("record_id = ?", recordID).
+		Updates(map[string]interface{}{"created_at": createdAt, "updated_at": createdAt}).Error; err != nil {
+		t.Fatalf("backdate history failed: %v", err)
+	}
+}
+
+func TestPurgeHistoryMedia_DropsMediaKeepsInsightsAndReport(t *testing.T) {
+	db, store := setupStateBlobStore(t)
+	record := state.AddCaseHistory(state.CaseHistoryRecord{
+		UserID:      "u-retain",
+		Title:       "冒充客服",
+		CaseSummary: "对方索要验证码",
+		ScamType:    "冒充客服",
+		RiskLevel:   "中",
+		RiskScore:   55,
+		Payload: state.TaskPayload{
+			Text:          "客服电话录音",
+			Images:        []string{dataURL("image/png", "png-bytes"), "https://example.com/shot.png"},
+			ImageInsights: []string{"截图显示退款链接"},
+		},
+		Report: "完整报告",
+	})
+	key, ok := blobstore.ParseRef(record.Payload.Images[0])
+	if !ok {
+		t.Fatalf("expected image stored as blob ref, got %+v", record.Payload.Images)
+	}
+	fresh := state.AddCaseHistory(state.CaseHistoryRecord{
+		UserID:    "u-retain",
+		Title:     "新案件",
+		RiskLevel: "低",
+		RiskScore: 10,
+		Payload: state.TaskPayload{
+			Images: []string{dataURL("image/png", "fresh-bytes")},
+		},
+	})
+	backdateHistory(t, db, record.RecordID, 48*time.Hour)
+
+	purged, err := state.PurgeHistoryMedia(time.Now().Add(-24*time.Hour), 10)
+	if err != nil || purged != 1 {
+		t.Fatalf("expected one record purged, got %d err=%v", purged, err)
+	}
+	detail, exists := state.GetCaseHistoryRecord("u-retain", record.RecordID)
+	if !exists {
+		t.Fatal("expected history record kept")
+	}
+	if len(detail.Payload.Images) != 1 || detail.Payload.Images[0] != "https://example.com/shot.png" {
+		t.Fatalf("expected only stored media removed, got %+v", detail.Payload.Images)
+	}
+	if detail.Payload.Text != "客服电话录音" || len(detail.Payload.ImageInsights) != 1 || detail.Report != "完整报告" {
+		t.Fatalf("expected text, insights and report kept, got %+v", detail)
+	}
+	if detail.MediaPurgedAt == nil {
+		t.Fatal("expected media_purged_at set")
+	}
+	blob, err := store.Stat(key)
+	if err != nil || blob.RefCount != 0 {
+		t.Fatalf("expected blob reference released, got %+v err=%v", blob, err)
+	}
+
+	if again, err := state.PurgeHistoryMedia(time.Now().Add(-24*time.Hour), 10); err != nil || again != 0 {
+		t.Fatalf("expected purged record skipped, got %d err=%v", again, err)
+	}
+	if kept, _ := state.GetCaseHistoryRecord("u-retain", fresh.RecordID); len(kept.Payload.Images) != 1 || kept.MediaPurgedAt != nil {
+		t.Fatalf("expected fresh record untouched, got %+v", kept)
+	}
+}
+
+func TestDeleteExpiredHistory_MatchesRiskLevelAndNotifiesObservers(t *testing.T) {
+	db, _ := setupStateBlobStore(t)
+	low := state.AddCaseHistory(state.CaseHistoryRecord{
+		UserID:    "u-expire",
+		Title:     "低风险",
+		RiskLevel: "低",
+		RiskScore: 10,
+		Payload:   state.TaskPayload{Text: "a"},
+	})
+	high := state.AddCaseHistory(state.CaseHistoryRecord{
+		UserID:    "u-expire",
+		Title:     "高风险",
+		RiskLevel: "高",
+		RiskScore: 90,
+		Payload:   state.TaskPayload{Text: "b"},
+	})
+	backdateHistory(t, db, low.RecordID, 48*time.Hour)
+	backdateHistory(t, db, high.RecordID, 48*time.Hour)
+
+	var mu sync.Mutex
+	removed := map[string]string{}
+	state.RegisterHistoryRemovedObserver(func(userID, recordID string) {
+		mu.Lock()
+		defer mu.Unlock()
+		removed[recordID] = userID
+	})
+
+	deleted, err := state.DeleteExpiredHistory("低", time.Now().Add(-24*time.Hour), 10)
+	if err != nil || deleted != 1 {
+		t.Fatalf("expected one low risk record deleted, got %d err=%v", deleted, err)
+	}
+	if _, exists := state.GetCaseHistoryRecord("u-expire", low.RecordID); exists {
+		t.Fatal("expected low risk record deleted")
+	}
+	if _, exists := state.GetCaseHistoryRecord("u-expire", high.RecordID); !exists {
+		t.Fatal("expected high risk record kept")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if removed[low.RecordID] != "u-expire" {
+		t.Fatalf("expected removed observer notified, got %+v", removed)
+	}
+	if _, notified := removed[high.RecordID]; notified {
+		t.Fatal("expected no notification for kept record")
+	}
+}
+
+func TestPurgeUserData_RejectsProcessingTasksThenDeletesEverything(t *testing.T) {
+	db, store := setupStateBlobStore(t)
+	task := state.CreateTask("u-purge", state.TaskPayload{Images: []string{dataURL("image/png", "task-bytes")}})
+	record := state.AddCaseHistory(state.CaseHistoryRecord{
+		UserID:    "u-purge",
+		Title:     "历史",
+		RiskLevel: "高",
+		RiskScore: 80,
+		Payload: state.TaskPayload{
+			Audios: []string{dataURL("audio/mpeg", "audio-bytes")},
+		},
+		Report: "报告",
+	})
+	other := state.AddCaseHistory(state.CaseHistoryRecord{
+		UserID:    "u-other",
+		Title:     "他人历史",
+		RiskLevel: "低",
+		RiskScore: 5,
+		Payload:   state.TaskPayload{Text: "x"},
+	})
+	state.AppendTaskProgressEvent("u-purge", task.TaskID, state.TaskProgressStageQueued, "排队中", nil)
+
+	if err := db.Model(&statemodel.PendingTaskEntity{}).Where("task_id = ?", task.TaskID).Update("status", state.TaskStatusProcessing).Error; err != nil {
+		t.Fatalf("mark processing failed: %v", err)
+	}
+	if _, err := state.PurgeUserData("u-purge"); !errors.Is(err, state.ErrUserTasksProcessing) {
+		t.Fatalf("expected processing task to block purge, got %v", err)
+	}
+	if _, exists := state.GetCaseHistoryRecord("u-purge", record.RecordID); !exists {
+		t.Fatal("expected nothing deleted while blocked")
+	}
+	if err := db.Model(&statemodel.PendingTaskEntity{}).Where("task_id = ?", task.TaskID).Update("status", state.TaskStatusPending).Error; err != nil {
+		t.Fatalf("reset status failed: %v", err)
+	}
+
+	result, err := state.PurgeUserData("u-purge")
+	if err != nil {
+		t.Fatalf("purge user data failed: %v", err)
+	}
+	if result.HistoryDeleted != 1 || result.TasksDeleted != 1 {
+		t.Fatalf("unexpected purge result: %+v", result)
+	}
+	view := state.GetUserStateView("u-purge")
+	if len(view.Pending) != 0 || len(view.History) != 0 {
+		t.Fatalf("expected user state empty, got %+v", view)
+	}
+	events, err := state.ListTaskProgressEvents("u-purge", task.TaskID, 0, 10)
+	if err != nil || len(events) != 0 {
+		t.Fatalf("expected progress events deleted, got %d err=%v", len(events), err)
+	}
+	for _, key := range append(state.PayloadBlobKeys(task.Payload), state.PayloadBlobKeys(record.Payload)...) {
+		if blob, err := store.Stat(key); err != nil || blob.RefCount != 0 {
+			t.Fatalf("expected blob %s released, got %+v err=%v", key, blob, err)
+		}
+	}
+	if _, exists := state.GetCaseHistoryRecord("u-other", other.RecordID); !exists {
+		t.Fatal("expected other user's history kept")
+	}
+}
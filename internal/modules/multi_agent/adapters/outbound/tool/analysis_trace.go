@@ -0,0 +1,114 @@
+package tool
+
+import (
+	"context"
+	"sync"
+)
+
+type analysisTraceContextKey struct{}
+
+// AnalysisTrace 收集一次主智能体分析过程中关键工具的结构化输出。
+// 在线流程不绑定 trace；离线评测通过 BindAnalysisTrace 注入后读取风险评分、命中规则与最终报告字段，
+// 无需解析报告文本或依赖 history_cases 落库结果。
+type AnalysisTrace struct {
+	mu              sync.Mutex
+	riskAssessments []RiskAssessmentResult
+	finalReports    []FinalReportPayload
+}
+
+// NewAnalysisTrace 创建空的分析轨迹。
+func NewAnalysisTrace() *AnalysisTrace {
+	return &AnalysisTrace{}
+}
+
+// BindAnalysisTrace 将分析轨迹绑定到 ctx，后续工具调用会把结构化结果追加到 trace。
+func BindAnalysisTrace(ctx context.Context, trace *AnalysisTrace) context.Context {
+	if trace == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, analysisTraceContextKey{}, trace)
+}
+
+// RiskAssessments 按调用顺序返回风险评分工具的计算结果快照。
+func (t *AnalysisTrace) RiskAssessments() []RiskAssessmentResult {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	results := make([]RiskAssessmentResult, 0, len(t.riskAssessments))
+	for _, item := range t.riskAssessments {
+		results = append(results, cloneRiskAssessmentResult(item))
+	}
+	return results
+}
+
+// FinalReports 按调用顺序返回已通过校验的最终报告载荷快照。
+func (t *AnalysisTrace) FinalReports() []FinalReportPayload {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]FinalReportPayload{}, t.finalReports...)
+}
+
+// LatestRiskAssessment 返回最后一次风险评分结果；主智能体可能多次评分，以最后一次为准。
+func (t *AnalysisTrace) LatestRiskAssessment() (RiskAssessmentResult, bool) {
+	results := t.RiskAssessments()
+	if len(results) == 0 {
+		return RiskAssessmentResult{}, false
+	}
+	return results[len(results)-1], true
+}
+
+// LatestFinalReport 返回最后一次提交的最终报告载荷。
+func (t *AnalysisTrace) LatestFinalReport() (FinalReportPayload, bool) {
+	reports := t.FinalReports()
+	if len(reports) == 0 {
+		return FinalReportPayload{}, false
+	}
+	return reports[len(reports)-1], true
+}
+
+func recordRiskAssessment(ctx context.Context, result RiskAssessmentResult) {
+	trace := currentAnalysisTrace(ctx)
+	if trace == nil {
+		return
+	}
+	trace.mu.Lock()
+	defer trace.mu.Unlock()
+	trace.riskAssessments = append(trace.riskAssessments, cloneRiskAssessmentResult(result))
+}
+
+func recordFinalReport(ctx context.Context, payload FinalReportPayload) {
+	trace := currentAnalysisTrace(ctx)
+	if trace == nil {
+		return
+	}
+	// 与 FormatFinalReport 保持一致，记录归一化后的风险等级。
+	payload.RiskLevel = normalizeFinalReportRiskLevel(payload.RiskLevel)
+	trace.mu.Lock()
+	defer trace.mu.Unlock()
+	trace.finalReports = append(trace.finalReports, payload)
+}
+
+func currentAnalysisTrace(ctx context.Context) *AnalysisTrace {
+	if ctx == nil {
+		return nil
+	}
+	trace, _ := ctx.Value(analysisTraceContextKey{}).(*AnalysisTrace)
+	return trace
+}
+
+func cloneRiskAssessmentResult(result RiskAssessmentResult) RiskAssessmentResult {
+	cloned := result
+	cloned.HitRules = append([]string{}, result.HitRules...)
+	if result.DimensionBreakdown != nil {
+		cloned.DimensionBreakdown = make(map[string]int, len(result.DimensionBreakdown))
+		for key, value := range result.DimensionBreakdown {
+			cloned.DimensionBreakdown[key] = value
+		}
+	}
+	return cloned
+}
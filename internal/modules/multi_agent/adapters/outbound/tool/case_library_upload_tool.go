@@ -16,6 +16,11 @@ const defaultFraudViolatedLaw = "涉嫌违反《中华人民共和国刑法》
 
 var createPendingReview = case_library.CreatePendingReview
 
+// BindCaseCollectionSource 标记当前调用链来自案件采集 Agent，上传工具提交的待审核案件会记为 agent 来源。
+func BindCaseCollectionSource(ctx context.Context) context.Context {
+	return case_library.WithPendingReviewSource(ctx, case_library.PendingReviewSourceAgent)
+}
+
 // UploadHistoricalCaseToVectorDBInput 表示“上传向量数据库”工具输入。
 // 该工具会自动完成 embedding 生成并写入 historical_case_library。
 type UploadHistoricalCaseToVectorDBInput struct {
@@ -28,6 +33,7 @@ type UploadHistoricalCaseToVectorDBInput struct {
 	Keywords        []string `json:"keywords,omitempty"`
 	ViolatedLaw     string   `json:"violated_law,omitempty"`
 	Suggestion      string   `json:"suggestion,omitempty"`
+	SourceURLs      []string `json:"source_urls,omitempty"`
 }
 
 var UploadHistoricalCaseToVectorDBTool = openai.Tool{
@@ -67,6 +73,11 @@ var UploadHistoricalCaseToVectorDBTool = openai.Tool{
 					"type":        "string",
 					"description": "防范建议（可选）。可根据当前已确认的风险点提炼简洁防范建议；若信息不足以支撑建议，可不传。",
 				},
+				"source_urls": map[string]interface{}{
+					"type":        "array",
+					"items":       map[string]string{"type": "string"},
+					"description": fmt.Sprintf("案件依据的来源链接（案件采集时必填，最多 %d 条）。只能填写 search_web 结果中出现过的原始链接，不要编造或改写链接。", case_library.MaxCaseCitations),
+				},
 			},
 			"required": []string{"title", "target_group", "risk_level", "scam_type", "case_description"},
 		},
@@ -88,6 +99,21 @@ func (h *UploadHistoricalCaseToVectorDBHandler) Handle(ctx context.Context, args
 		}}, nil
 	}
 
+	citations, unknownSources := resolveSourceCitations(ctx, input.SourceURLs)
+	if len(unknownSources) > 0 {
+		return ToolResponse{Payload: map[string]interface{}{
+			"status":          "failed",
+			"error":           "source_urls must come from search_web results of this run",
+			"unknown_sources": unknownSources,
+		}}, nil
+	}
+	if searchCitationsFromContext(ctx) != nil && len(citations) == 0 {
+		return ToolResponse{Payload: map[string]interface{}{
+			"status": "failed",
+			"error":  "source_urls is required: cite at least one search_web result link this case is based on",
+		}}, nil
+	}
+
 	record, createErr := createPendingReview(ctx, CurrentUserID(ctx), case_library.CreateHistoricalCaseInput{
 		Title:           input.Title,
 		TargetGroup:     input.TargetGroup,
@@ -98,6 +124,7 @@ func (h *UploadHistoricalCaseToVectorDBHandler) Handle(ctx context.Context, args
 		Keywords:        append([]string{}, input.Keywords...),
 		ViolatedLaw:     normalizeViolatedLaw(input.ViolatedLaw),
 		Suggestion:      input.Suggestion,
+		Citations:       citations,
 	})
 	if createErr != nil {
 		payload := map[string]interface{}{
@@ -136,6 +163,7 @@ func (h *UploadHistoricalCaseToVectorDBHandler) Handle(ctx context.Context, args
 			"title":      record.Title,
 			"risk_level": record.RiskLevel,
 			"scam_type":  record.ScamType,
+			"citations":  len(record.Citations),
 			"created_at": record.CreatedAt.Format(time.RFC3339),
 		},
 	}}, nil
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strings"
 
+	"antifraud/internal/modules/multi_agent/domain/scoring"
 	openai "antifraud/internal/platform/llm"
 )
 
@@ -16,10 +17,15 @@ type DynamicRiskLevelInput struct {
 }
 
 type DynamicRiskLevelResult struct {
-	CurrentScore     int    `json:"current_score"`
-	HistoricalScore  int    `json:"historical_score"`
-	DynamicThreshold int    `json:"dynamic_threshold"`
-	RiskLevel        string `json:"risk_level"`
+	CurrentScore            int    `json:"current_score"`
+	HistoricalScore         int    `json:"historical_score"`
+	DynamicThreshold        int    `json:"dynamic_threshold"`
+	RiskLevel               string `json:"risk_level"`
+	BaseScore               int    `json:"base_score"`
+	KnowledgeBaseHit        string `json:"knowledge_base_hit"`
+	KnowledgeBaseAdjustment int    `json:"knowledge_base_adjustment"`
+	UserHistoryHit          string `json:"user_history_hit"`
+	UserHistoryAdjustment   int    `json:"user_history_adjustment"`
 }
 
 const (
@@ -73,12 +79,17 @@ func (h *DynamicRiskLevelHandler) Handle(ctx context.Context, args string) (Tool
 	if err != nil {
 		return ToolResponse{Payload: map[string]interface{}{"error": err.Error()}}, nil
 	}
-	return ToolResponse{Payload: map[string]interface{}{
-		"current_score":     result.CurrentScore,
-		"historical_score":  result.HistoricalScore,
-		"dynamic_threshold": result.DynamicThreshold,
-		"risk_level":        result.RiskLevel,
-	}}, nil
+	return ToolResponse{
+		Payload: map[string]interface{}{
+			"current_score":     result.CurrentScore,
+			"historical_score":  result.HistoricalScore,
+			"dynamic_threshold": result.DynamicThreshold,
+			"risk_level":        result.RiskLevel,
+		},
+		ContextMutator: func(base context.Context) context.Context {
+			return BindDynamicRiskLevel(base, result)
+		},
+	}, nil
 }
 
 func ResolveDynamicRiskLevel(currentScore int, historicalScore int, knowledgeBaseHit string, userHistoryHit string) (DynamicRiskLevelResult, error) {
@@ -107,27 +118,21 @@ func ResolveDynamicRiskLevel(currentScore int, historicalScore int, knowledgeBas
 	}
 
 	return DynamicRiskLevelResult{
-		CurrentScore:     adjustedScore,
-		HistoricalScore:  normalizeScore(historicalScore),
-		DynamicThreshold: threshold,
-		RiskLevel:        riskLevel,
+		CurrentScore:            adjustedScore,
+		HistoricalScore:         normalizeScore(historicalScore),
+		DynamicThreshold:        threshold,
+		RiskLevel:               riskLevel,
+		BaseScore:               normalizeScore(currentScore),
+		KnowledgeBaseHit:        kbLabel,
+		KnowledgeBaseAdjustment: kbAdjustment,
+		UserHistoryHit:          userLabel,
+		UserHistoryAdjustment:   userAdjustment,
 	}, nil
 }
 
+// DynamicThresholdFromHistoricalScore 按当前评分规则集的 threshold_bands 推导动态阈值。
 func DynamicThresholdFromHistoricalScore(historicalScore int) int {
-	score := normalizeScore(historicalScore)
-	switch {
-	case score <= 20:
-		return 60
-	case score <= 40:
-		return 55
-	case score <= 60:
-		return 50
-	case score <= 80:
-		return 45
-	default:
-		return 40
-	}
+	return scoring.Current().ThresholdFor(normalizeScore(historicalScore))
 }
 
 func normalizeScore(score int) int {
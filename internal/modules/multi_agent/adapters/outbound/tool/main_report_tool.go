@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"strings"
 
+	"antifraud/internal/modules/multi_agent/adapters/outbound/state"
+	"antifraud/internal/modules/multi_agent/domain/indicators"
 	openai "antifraud/internal/platform/llm"
 )
 
@@ -23,6 +25,8 @@ type FinalReportPayload struct {
 	NextActions          []string `json:"next_actions"`
 	AttackSteps          []string `json:"attack_steps"`
 	ScamKeywordSentences []string `json:"scam_keyword_sentences"`
+	// Indicators 由系统填入确定性抽取的结构化线索，不在工具 schema 中暴露给模型。
+	Indicators []state.Indicator `json:"indicators,omitempty"`
 }
 
 var FinalReportTool = openai.Tool{
@@ -175,11 +179,50 @@ func FormatFinalReport(payload FinalReportPayload) string {
 			report.WriteString(sentence)
 			report.WriteString("\n")
 		}
+		nextSectionID++
+	}
+
+	// 结构化线索放在最后，已有章节的编号与标题保持不变。
+	if len(payload.Indicators) > 0 {
+		report.WriteString("\n\n")
+		report.WriteString(fmt.Sprintf("%d. 结构化线索\n", nextSectionID))
+		for _, indicator := range payload.Indicators {
+			report.WriteString("- ")
+			report.WriteString(indicators.KindLabel(indicator.Kind))
+			report.WriteString(": ")
+			report.WriteString(strings.TrimSpace(indicator.Value))
+			if len(indicator.Sources) > 0 {
+				report.WriteString("（来源: ")
+				report.WriteString(formatIndicatorSources(indicator.Sources))
+				report.WriteString("）")
+			}
+			report.WriteString("\n")
+		}
 	}
 
 	return strings.TrimSpace(report.String())
 }
 
+// indicatorSourceLabels 与“多模态关键发现”章节的模态称呼保持一致，扩展模态直接使用模态名。
+var indicatorSourceLabels = map[string]string{
+	indicators.SourceText: "文本",
+	state.ModalityImage:   "图像",
+	state.ModalityVideo:   "视频",
+	state.ModalityAudio:   "音频",
+}
+
+func formatIndicatorSources(sources []string) string {
+	labels := make([]string, 0, len(sources))
+	for _, source := range sources {
+		if label, ok := indicatorSourceLabels[source]; ok {
+			labels = append(labels, label)
+			continue
+		}
+		labels = append(labels, source)
+	}
+	return strings.Join(labels, "、")
+}
+
 func sanitizeNonEmptyList(items []string) []string {
 	cleaned := make([]string, 0, len(items))
 	for _, item := range items {
@@ -216,6 +259,8 @@ func (h *FinalReportHandler) Handle(ctx context.Context, args string) (ToolRespo
 		return ToolResponse{Payload: map[string]interface{}{"error": fmt.Sprintf("invalid scam_type: %v", scamTypeErr)}}, nil
 	}
 	payload.ScamType = normalizedScamType
+	payload.Indicators = CurrentTaskIndicators(ctx)
+	recordFinalReport(ctx, payload)
 
 	return ToolResponse{
 		Payload:        map[string]interface{}{"status": "success", "message": "最终报告已提交"},
@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"strings"
 
+	"antifraud/internal/modules/multi_agent/adapters/outbound/state"
+	"antifraud/internal/modules/multi_agent/domain/scoring"
 	openai "antifraud/internal/platform/llm"
 )
 
@@ -39,6 +41,9 @@ type RiskAssessmentResult struct {
 	StructuredSummary  string         `json:"structured_summary"`
 	DimensionBreakdown map[string]int `json:"dimension_breakdown"`
 	HitRules           []string       `json:"hit_rules"`
+	RuleSetVersion     string         `json:"rule_set_version"`
+	// Breakdown 为随历史案件归档的完整评分明细。
+	Breakdown state.RiskBreakdown `json:"breakdown"`
 }
 
 var RiskAssessmentTool = openai.Tool{
@@ -107,6 +112,7 @@ func (h *RiskAssessmentHandler) Handle(ctx context.Context, args string) (ToolRe
 	if err != nil {
 		return ToolResponse{Payload: map[string]interface{}{"status": "failed", "error": err.Error()}}, nil
 	}
+	recordRiskAssessment(ctx, result)
 
 	return ToolResponse{
 		Payload: map[string]interface{}{
@@ -114,139 +120,77 @@ func (h *RiskAssessmentHandler) Handle(ctx context.Context, args string) (ToolRe
 			"report": result.StructuredSummary,
 		},
 		ContextMutator: func(base context.Context) context.Context {
-			return BindRiskAssessment(base, result.Score, result.StructuredSummary)
+			return BindRiskAssessment(base, result)
 		},
 	}, nil
 }
 
+// CalculateRiskAssessment 按当前生效的评分规则集计算风险分，权重与保底规则见 domain/scoring。
 func CalculateRiskAssessment(input RiskAssessmentInput) (RiskAssessmentResult, error) {
-	similarCaseStrength, err := normalizeStrength(input.SimilarCaseStrength)
-	if err != nil {
-		return RiskAssessmentResult{}, err
-	}
-	multimodalEvidence, err := normalizeStrength(input.MultimodalEvidence)
-	if err != nil {
-		return RiskAssessmentResult{}, err
-	}
-	victimActionStage, victimActionLabel, victimActionScore, err := normalizeVictimActionStage(input.VictimActionStage)
+	rules := scoring.Current()
+	outcome, err := rules.Score(scoring.Signals{
+		Factors:             input.factorSignals(),
+		SimilarCaseStrength: input.SimilarCaseStrength,
+		MultimodalEvidence:  input.MultimodalEvidence,
+		VictimActionStage:   input.VictimActionStage,
+	})
 	if err != nil {
 		return RiskAssessmentResult{}, err
 	}
 
 	keyEvidence := sanitizeRiskEvidence(input.KeyEvidence)
-	dimensions := map[string]int{
-		"social_engineering": 0,
-		"requested_actions":  0,
-		"evidence_strength":  0,
-		"loss_exposure":      victimActionScore,
-	}
-	hitRules := make([]string, 0, 16)
-	preliminarySignalCount := 0
-	terminalSignalCount := 0
-
-	addPreliminaryHit := func(condition bool, dimension string, score int, label string) {
-		if !condition {
-			return
-		}
-		dimensions[dimension] += score
-		hitRules = append(hitRules, label)
-		preliminarySignalCount++
-	}
+	structuredSummary := buildRiskStructuredSummary(outcome.Score, outcome.Dimensions, outcome.HitRules, outcome.VictimActionLabel, outcome.SimilarCaseLabel, outcome.MultimodalLabel, keyEvidence, outcome.RuleSetVersion)
 
-	addTerminalHit := func(condition bool, dimension string, score int, label string) {
-		if !condition {
-			return
-		}
-		dimensions[dimension] += score
-		hitRules = append(hitRules, label)
-		terminalSignalCount++
-	}
-
-	addPreliminaryHit(input.Impersonation, "social_engineering", 8, "冒充身份")
-	addPreliminaryHit(input.Urgency, "social_engineering", 5, "紧迫催促")
-	addPreliminaryHit(input.ThreatPressure, "social_engineering", 7, "恐吓施压")
-	addPreliminaryHit(input.BenefitInducement, "social_engineering", 6, "利益诱导")
-	addPreliminaryHit(input.ChannelSwitchRequest, "social_engineering", 7, "引导切换线路/平台")
-	addPreliminaryHit(input.InviteCodeRequest, "social_engineering", 6, "索要邀请码/口令")
-	addPreliminaryHit(input.VerificationProcessRequest, "social_engineering", 7, "前置认证/额度验证")
-	addPreliminaryHit(input.ScreenshotOrRecordRequest, "social_engineering", 5, "要求截图/录屏")
-	addPreliminaryHit(input.TrustBuildingPressure, "social_engineering", 6, "逐步建立信任")
-	addPreliminaryHit(input.LinkOrAppInstallRequest, "requested_actions", 10, "要求点击链接/安装应用")
-	addPreliminaryHit(input.FakeOfficialVisuals, "evidence_strength", 10, "出现仿冒官方视觉证据")
-
-	addTerminalHit(input.MoneyTransferRequest, "requested_actions", 20, "要求转账/充值")
-	addTerminalHit(input.VerificationCodeRequest, "requested_actions", 18, "要求验证码")
-	addTerminalHit(input.RemoteControlRequest, "requested_actions", 24, "要求远程控制")
-	addTerminalHit(input.SensitiveInfoRequest, "requested_actions", 16, "索要敏感信息")
-	addTerminalHit(input.PrivateAccountCollection, "requested_actions", 16, "要求向私人账户收款")
-
-	if similarCaseStrength.score > 0 {
-		dimensions["evidence_strength"] += similarCaseStrength.score
-		hitRules = append(hitRules, "相似案例支持："+similarCaseStrength.label)
+	hitFactors := make([]state.RiskFactorHit, 0, len(outcome.HitFactors))
+	for _, factor := range outcome.HitFactors {
+		hitFactors = append(hitFactors, state.RiskFactorHit{
+			Key:       factor.Key,
+			Label:     factor.Label,
+			Dimension: factor.Dimension,
+			Weight:    factor.Weight,
+		})
 	}
-	if multimodalEvidence.score > 0 {
-		dimensions["evidence_strength"] += multimodalEvidence.score
-		hitRules = append(hitRules, "多模态证据："+multimodalEvidence.label)
-	}
-	if victimActionLabel != "" {
-		hitRules = append(hitRules, "受害动作阶段："+victimActionLabel)
-	}
-
-	score := dimensions["social_engineering"] + dimensions["requested_actions"] + dimensions["evidence_strength"] + dimensions["loss_exposure"]
-
-	if terminalSignalCount == 0 {
-		switch {
-		case preliminarySignalCount >= 6:
-			score = maxInt(score, 45)
-		case preliminarySignalCount >= 4:
-			score = maxInt(score, 35)
-		case preliminarySignalCount >= 2:
-			score = maxInt(score, 25)
-		case preliminarySignalCount == 1:
-			score = maxInt(score, 15)
-		}
-	}
-
-	switch {
-	case terminalSignalCount >= 3:
-		score = maxInt(score, 78)
-	case terminalSignalCount >= 2:
-		score = maxInt(score, 68)
-	case terminalSignalCount == 1 && preliminarySignalCount >= 3:
-		score = maxInt(score, 58)
-	case terminalSignalCount == 1:
-		score = maxInt(score, 48)
-	}
-
-	if input.RemoteControlRequest && input.VerificationCodeRequest {
-		score = maxInt(score, 72)
-	}
-	if input.MoneyTransferRequest && input.PrivateAccountCollection {
-		score = maxInt(score, 76)
-	}
-	switch victimActionStage {
-	case "transferred_money":
-		score = maxInt(score, 78)
-	case "multiple_transfers":
-		score = maxInt(score, 88)
-	}
-	if score > 100 {
-		score = 100
-	}
-	structuredSummary := buildRiskStructuredSummary(score, dimensions, hitRules, victimActionLabel, similarCaseStrength.label, multimodalEvidence.label, keyEvidence)
 
 	return RiskAssessmentResult{
-		Score:              score,
+		Score:              outcome.Score,
 		StructuredSummary:  structuredSummary,
-		DimensionBreakdown: dimensions,
-		HitRules:           append([]string{}, hitRules...),
+		DimensionBreakdown: outcome.Dimensions,
+		HitRules:           append([]string{}, outcome.HitRules...),
+		RuleSetVersion:     outcome.RuleSetVersion,
+		Breakdown: state.RiskBreakdown{
+			Score:               outcome.Score,
+			RuleVersion:         outcome.RuleSetVersion,
+			Dimensions:          outcome.Dimensions,
+			HitFactors:          hitFactors,
+			HitRules:            append([]string{}, outcome.HitRules...),
+			KeyEvidence:         keyEvidence,
+			VictimActionStage:   outcome.VictimActionLabel,
+			SimilarCaseStrength: outcome.SimilarCaseLabel,
+			MultimodalEvidence:  outcome.MultimodalLabel,
+		},
 	}, nil
 }
 
-type normalizedStrength struct {
-	value string
-	label string
-	score int
+// factorSignals 把布尔因子按工具参数名展开，key 与 scoring.FactorKeys 一致。
+func (input RiskAssessmentInput) factorSignals() map[string]bool {
+	return map[string]bool{
+		"impersonation":                input.Impersonation,
+		"urgency":                      input.Urgency,
+		"threat_pressure":              input.ThreatPressure,
+		"benefit_inducement":           input.BenefitInducement,
+		"channel_switch_request":       input.ChannelSwitchRequest,
+		"invite_code_request":          input.InviteCodeRequest,
+		"verification_process_request": input.VerificationProcessRequest,
+		"screenshot_or_record_request": input.ScreenshotOrRecordRequest,
+		"trust_building_pressure":      input.TrustBuildingPressure,
+		"money_transfer_request":       input.MoneyTransferRequest,
+		"verification_code_request":    input.VerificationCodeRequest,
+		"remote_control_request":       input.RemoteControlRequest,
+		"link_or_app_install_request":  input.LinkOrAppInstallRequest,
+		"sensitive_info_request":       input.SensitiveInfoRequest,
+		"private_account_collection":   input.PrivateAccountCollection,
+		"fake_official_visuals":        input.FakeOfficialVisuals,
+	}
 }
 
 func buildStrengthSchema(description string) map[string]interface{} {
@@ -265,40 +209,6 @@ func buildVictimActionStageSchema() map[string]interface{} {
 	}
 }
 
-func normalizeStrength(raw string) (normalizedStrength, error) {
-	switch strings.TrimSpace(raw) {
-	case "", "none":
-		return normalizedStrength{value: "none", label: "无", score: 0}, nil
-	case "weak":
-		return normalizedStrength{value: "weak", label: "弱", score: 4}, nil
-	case "medium":
-		return normalizedStrength{value: "medium", label: "中", score: 8}, nil
-	case "strong":
-		return normalizedStrength{value: "strong", label: "强", score: 12}, nil
-	default:
-		return normalizedStrength{}, fmt.Errorf("strength must be one of none/weak/medium/strong")
-	}
-}
-
-func normalizeVictimActionStage(raw string) (string, string, int, error) {
-	switch strings.TrimSpace(raw) {
-	case "", "none":
-		return "none", "未操作", 0, nil
-	case "clicked_link":
-		return "clicked_link", "已点击链接", 8, nil
-	case "downloaded_app":
-		return "downloaded_app", "已下载应用", 12, nil
-	case "shared_sensitive_info":
-		return "shared_sensitive_info", "已提供敏感信息", 22, nil
-	case "transferred_money":
-		return "transferred_money", "已转账", 30, nil
-	case "multiple_transfers":
-		return "multiple_transfers", "已多次转账", 38, nil
-	default:
-		return "", "", 0, fmt.Errorf("victim_action_stage is invalid")
-	}
-}
-
 func sanitizeRiskEvidence(items []string) []string {
 	cleaned := make([]string, 0, len(items))
 	for _, item := range items {
@@ -314,7 +224,7 @@ func sanitizeRiskEvidence(items []string) []string {
 	return cleaned
 }
 
-func buildRiskStructuredSummary(score int, dimensions map[string]int, hitRules []string, victimActionLabel string, similarCaseLabel string, multimodalEvidenceLabel string, keyEvidence []string) string {
+func buildRiskStructuredSummary(score int, dimensions map[string]int, hitRules []string, victimActionLabel string, similarCaseLabel string, multimodalEvidenceLabel string, keyEvidence []string, ruleSetVersion string) string {
 	payload := map[string]interface{}{
 		"score": score,
 		"dimensions": map[string]int{
@@ -328,14 +238,8 @@ func buildRiskStructuredSummary(score int, dimensions map[string]int, hitRules [
 		"multimodal_evidence":   multimodalEvidenceLabel,
 		"hit_rules":             append([]string{}, hitRules...),
 		"key_evidence":          append([]string{}, keyEvidence...),
+		"rule_set_version":      ruleSetVersion,
 	}
 	bytes, _ := json.Marshal(payload)
 	return string(bytes)
 }
-
-func maxInt(left int, right int) int {
-	if left > right {
-		return left
-	}
-	return right
-}
@@ -0,0 +1,114 @@
+package tool
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	"antifraud/internal/modules/multi_agent/adapters/outbound/case_library"
+)
+
+// searchCitationRegistry 记录一次采集运行中 web_search 返回过的结果，供上传工具把 source_urls 还原为完整引用。
+type searchCitationRegistry struct {
+	mu      sync.Mutex
+	entries map[string]case_library.CaseCitation
+}
+
+type searchCitationContextKey struct{}
+
+// BindSearchCitations 在 ctx 上开启来源登记：此后上传工具只接受本次运行中 web_search 返回过的链接作为 source_urls，
+// 且至少需要一条来源。
+func BindSearchCitations(ctx context.Context) context.Context {
+	return context.WithValue(ctx, searchCitationContextKey{}, &searchCitationRegistry{
+		entries: map[string]case_library.CaseCitation{},
+	})
+}
+
+func searchCitationsFromContext(ctx context.Context) *searchCitationRegistry {
+	if ctx == nil {
+		return nil
+	}
+	registry, _ := ctx.Value(searchCitationContextKey{}).(*searchCitationRegistry)
+	return registry
+}
+
+type searchCitationPayload struct {
+	Status  string `json:"status"`
+	Results []struct {
+		Title         string `json:"title"`
+		URL           string `json:"url"`
+		PublishedDate string `json:"published_date"`
+	} `json:"results"`
+}
+
+// RecordSearchCitations 把一次 web_search 工具响应中的结果登记为可引用来源，检索时间记为当前时间。
+// ctx 未开启来源登记或响应不是成功结果时忽略。
+func RecordSearchCitations(ctx context.Context, payload map[string]interface{}) {
+	registry := searchCitationsFromContext(ctx)
+	if registry == nil || len(payload) == 0 {
+		return
+	}
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		return
+	}
+	var decoded searchCitationPayload
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		return
+	}
+	if status := strings.TrimSpace(decoded.Status); status != "" && status != "success" {
+		return
+	}
+
+	retrievedAt := time.Now()
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	for _, result := range decoded.Results {
+		rawURL := strings.TrimSpace(result.URL)
+		if !case_library.IsCitationURL(rawURL) {
+			continue
+		}
+		if _, exists := registry.entries[citationKey(rawURL)]; exists {
+			continue
+		}
+		registry.entries[citationKey(rawURL)] = case_library.CaseCitation{
+			URL:           rawURL,
+			Title:         strings.TrimSpace(result.Title),
+			PublishedDate: strings.TrimSpace(result.PublishedDate),
+			RetrievedAt:   retrievedAt,
+		}
+	}
+}
+
+// resolveSourceCitations 把上传参数中的 source_urls 转为来源引用。
+// 开启来源登记时，链接必须来自本次运行的 web_search 结果，返回值 unknown 为未登记的链接；
+// 未开启时按原链接记录，检索时间取当前时间。
+func resolveSourceCitations(ctx context.Context, sourceURLs []string) (citations []case_library.CaseCitation, unknown []string) {
+	registry := searchCitationsFromContext(ctx)
+	now := time.Now()
+	for _, sourceURL := range sourceURLs {
+		trimmed := strings.TrimSpace(sourceURL)
+		if trimmed == "" {
+			continue
+		}
+		if registry == nil {
+			citations = append(citations, case_library.CaseCitation{URL: trimmed, RetrievedAt: now})
+			continue
+		}
+		registry.mu.Lock()
+		citation, ok := registry.entries[citationKey(trimmed)]
+		registry.mu.Unlock()
+		if !ok {
+			unknown = append(unknown, trimmed)
+			continue
+		}
+		citations = append(citations, citation)
+	}
+	return citations, unknown
+}
+
+func citationKey(rawURL string) string {
+	return strings.TrimRight(strings.TrimSpace(rawURL), "/")
+}
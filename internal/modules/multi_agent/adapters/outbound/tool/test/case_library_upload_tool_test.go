@@ -100,3 +100,47 @@ func TestUploadHistoricalCaseToVectorDBHandler_DuplicateHistoricalCase(t *testin
 		t.Fatalf("unexpected duplicate case: %+v", duplicateCase)
 	}
 }
+
+func TestUploadHistoricalCaseToVectorDBHandler_ResolvesSourceURLsFromSearchResults(t *testing.T) {
+	originalCreatePendingReview := createPendingReview
+	t.Cleanup(func() {
+		createPendingReview = originalCreatePendingReview
+	})
+
+	var captured case_library.CreateHistoricalCaseInput
+	createPendingReview = func(ctx context.Context, userID string, input case_library.CreateHistoricalCaseInput) (case_library.PendingReviewRecord, error) {
+		captured = input
+		return case_library.PendingReviewRecord{RecordID: "PREV-1", Citations: input.Citations}, nil
+	}
+
+	ctx := agenttool.BindSearchCitations(context.Background())
+	agenttool.RecordSearchCitations(ctx, map[string]interface{}{
+		"status": "success",
+		"results": []map[string]interface{}{
+			{"title": "警方通报", "url": "https://news.gov.cn/a", "published_date": "2026-03-13"},
+		},
+	})
+	handler := &agenttool.UploadHistoricalCaseToVectorDBHandler{}
+	baseArgs := `"title":"冒充客服诈骗","target_group":"老人","risk_level":"高","scam_type":"冒充电商物流客服类","case_description":"受害人收到自称客服电话，被诱导下载远程控制软件并转账。"`
+
+	resp, err := handler.Handle(ctx, `{`+baseArgs+`}`)
+	if err != nil || resp.Payload["status"] != "failed" {
+		t.Fatalf("expected missing source_urls to fail, got %#v err=%v", resp.Payload, err)
+	}
+	resp, err = handler.Handle(ctx, `{`+baseArgs+`,"source_urls":["https://made-up.example.com/x"]}`)
+	if err != nil || resp.Payload["status"] != "failed" || resp.Payload["unknown_sources"] == nil {
+		t.Fatalf("expected unknown source to fail, got %#v err=%v", resp.Payload, err)
+	}
+
+	resp, err = handler.Handle(ctx, `{`+baseArgs+`,"source_urls":["https://news.gov.cn/a/"]}`)
+	if err != nil || resp.Payload["status"] != "success" {
+		t.Fatalf("expected upload success, got %#v err=%v", resp.Payload, err)
+	}
+	if len(captured.Citations) != 1 {
+		t.Fatalf("expected one citation, got %+v", captured.Citations)
+	}
+	citation := captured.Citations[0]
+	if citation.URL != "https://news.gov.cn/a" || citation.Title != "警方通报" || citation.PublishedDate != "2026-03-13" || citation.RetrievedAt.IsZero() {
+		t.Fatalf("unexpected citation: %+v", citation)
+	}
+}
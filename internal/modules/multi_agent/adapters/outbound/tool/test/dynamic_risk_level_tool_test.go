@@ -96,3 +96,19 @@ func TestResolveDynamicRiskLevel(t *testing.T) {
 		t.Fatalf("expected low hits to mildly adjust clearly higher scores, got %+v", lowHitMildAdjustment)
 	}
 }
+
+func TestResolveDynamicRiskLevel_ReportsAdjustments(t *testing.T) {
+	result, err := agenttool.ResolveDynamicRiskLevel(60, 65, "high", "low")
+	if err != nil {
+		t.Fatalf("resolve dynamic risk level failed: %v", err)
+	}
+	if result.BaseScore != 60 || result.KnowledgeBaseHit != "high" || result.KnowledgeBaseAdjustment != 8 {
+		t.Fatalf("unexpected knowledge base adjustment: %+v", result)
+	}
+	if result.UserHistoryHit != "low" || result.UserHistoryAdjustment != -4 {
+		t.Fatalf("unexpected user history adjustment: %+v", result)
+	}
+	if result.CurrentScore != result.BaseScore+result.KnowledgeBaseAdjustment+result.UserHistoryAdjustment {
+		t.Fatalf("adjusted score should equal base plus adjustments: %+v", result)
+	}
+}
@@ -0,0 +1,30 @@
+package tool_test
+
+import (
+	"strings"
+	"testing"
+
+	"antifraud/internal/modules/multi_agent/adapters/outbound/state"
+	agenttool "antifraud/internal/modules/multi_agent/adapters/outbound/tool"
+)
+
+func TestFormatFinalReport_AppendsIndicatorSectionLast(t *testing.T) {
+	report := agenttool.FormatFinalReport(agenttool.FinalReportPayload{
+		Summary:              "疑似贷款诈骗",
+		RiskLevel:            "高",
+		ScamKeywordSentences: []string{"先交保证金才能放款"},
+		Indicators: []state.Indicator{
+			{Kind: "phone", Value: "[电话号码_1]", Sources: []string{"text", state.ModalityImage}},
+			{Kind: "app", Value: "安心贷"},
+		},
+	})
+
+	if !strings.Contains(report, "6. 诈骗关键词句") || !strings.HasSuffix(report, "7. 结构化线索\n- 电话号码: [电话号码_1]（来源: 文本、图像）\n- App 名称: 安心贷") {
+		t.Fatalf("unexpected indicator section:\n%s", report)
+	}
+
+	withoutIndicators := agenttool.FormatFinalReport(agenttool.FinalReportPayload{Summary: "正常对话", RiskLevel: "低"})
+	if strings.Contains(withoutIndicators, "结构化线索") {
+		t.Fatalf("expected no indicator section without indicators:\n%s", withoutIndicators)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	agenttool "antifraud/internal/modules/multi_agent/adapters/outbound/tool"
+	"antifraud/internal/modules/multi_agent/domain/scoring"
 )
 
 func TestCalculateRiskAssessment_HighRiskSignals(t *testing.T) {
@@ -90,3 +91,16 @@ func TestCalculateRiskAssessment_RejectsInvalidEnums(t *testing.T) {
 		t.Fatal("expected error for invalid strength")
 	}
 }
+
+func TestCalculateRiskAssessment_StampsRuleSetVersion(t *testing.T) {
+	result, err := agenttool.CalculateRiskAssessment(agenttool.RiskAssessmentInput{Impersonation: true})
+	if err != nil {
+		t.Fatalf("calculate risk assessment failed: %v", err)
+	}
+	if result.RuleSetVersion == "" || result.RuleSetVersion != scoring.CurrentVersion() {
+		t.Fatalf("expected current rule set version, got %q", result.RuleSetVersion)
+	}
+	if !strings.Contains(result.StructuredSummary, `"rule_set_version":"`+result.RuleSetVersion+`"`) {
+		t.Fatalf("expected rule set version in structured summary, got %q", result.StructuredSummary)
+	}
+}
@@ -10,4 +10,4 @@ import (
 var loadWebSearchConfig func(string) (*appcfg.Config, error)
 
 //go:linkname newWebSearcher antifraud/internal/modules/multi_agent/adapters/outbound/tool.newWebSearcher
-var newWebSearcher func(appcfg.TavilyConfig) web_search_system.Searcher
+var newWebSearcher func(appcfg.Config) (web_search_system.Searcher, error)
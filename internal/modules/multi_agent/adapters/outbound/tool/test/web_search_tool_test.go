@@ -61,6 +61,28 @@ func TestExecuteWebSearchSuccess(t *testing.T) {
 	}
 }
 
+type recordingSearcher struct {
+	queries *[]string
+}
+
+func (s recordingSearcher) Search(ctx context.Context, query string, maxResults int) (web_search_system.SearchResponse, error) {
+	*s.queries = append(*s.queries, query)
+	return web_search_system.SearchResponse{Query: query}, nil
+}
+
+func TestExecuteWebSearchStripsPIIFromQuery(t *testing.T) {
+	var queries []string
+	_, err := agenttool.ExecuteWebSearch(context.Background(), recordingSearcher{queries: &queries}, agenttool.WebSearchInput{
+		Query: "13800138000 诈骗 [电话号码_2]",
+	})
+	if err != nil {
+		t.Fatalf("execute failed: %v", err)
+	}
+	if len(queries) != 1 || queries[0] != "[电话号码] 诈骗 [电话号码]" {
+		t.Fatalf("expected PII stripped from search query, got %+v", queries)
+	}
+}
+
 func TestExecuteWebSearchRejectsEmptyQuery(t *testing.T) {
 	_, err := agenttool.ExecuteWebSearch(context.Background(), stubSearcher{}, agenttool.WebSearchInput{Query: "   "})
 	if err == nil {
@@ -113,8 +135,8 @@ func TestWebSearchHandlerReturnsFailurePayload(t *testing.T) {
 			Tavily: appcfg.TavilyConfig{APIKey: "key"},
 		}, nil
 	}
-	newWebSearcher = func(cfg appcfg.TavilyConfig) web_search_system.Searcher {
-		return stubSearcher{err: errors.New("upstream failed")}
+	newWebSearcher = func(cfg appcfg.Config) (web_search_system.Searcher, error) {
+		return stubSearcher{err: errors.New("upstream failed")}, nil
 	}
 
 	resp, err := (&agenttool.WebSearchHandler{}).Handle(context.Background(), `{"query":"risk event"}`)
@@ -142,7 +164,7 @@ func TestWebSearchHandlerSuccess(t *testing.T) {
 			Tavily: appcfg.TavilyConfig{APIKey: "key"},
 		}, nil
 	}
-	newWebSearcher = func(cfg appcfg.TavilyConfig) web_search_system.Searcher {
+	newWebSearcher = func(cfg appcfg.Config) (web_search_system.Searcher, error) {
 		return stubSearcher{
 			result: web_search_system.SearchResponse{
 				Query:  "risk event",
@@ -151,7 +173,7 @@ func TestWebSearchHandlerSuccess(t *testing.T) {
 					{Title: "A", URL: "https://example.com/a", Content: "snippet"},
 				},
 			},
-		}
+		}, nil
 	}
 
 	resp, err := (&agenttool.WebSearchHandler{}).Handle(context.Background(), `{"query":"risk event","max_results":2}`)
@@ -9,6 +9,7 @@ import (
 	"antifraud/internal/modules/multi_agent/adapters/outbound/state"
 	"antifraud/internal/modules/multi_agent/adapters/outbound/user_history_index"
 	"antifraud/internal/modules/user_profile"
+	"antifraud/internal/platform/redaction"
 
 	openai "antifraud/internal/platform/llm"
 )
@@ -151,13 +152,41 @@ func noneIfEmpty(raw string) string {
 	return trimmed
 }
 
+// buildHistoryRiskBreakdown 合并评分工具与动态判级工具的结果，作为归档记录的评分明细。
+func buildHistoryRiskBreakdown(ctx context.Context, assessment RiskAssessmentContext) state.RiskBreakdown {
+	breakdown := assessment.Breakdown
+	breakdown.Score = assessment.Score
+	if strings.TrimSpace(breakdown.RuleVersion) == "" {
+		breakdown.RuleVersion = assessment.RuleSetVersion
+	}
+	if dynamic, ok := CurrentDynamicRiskLevel(ctx); ok {
+		breakdown.Dynamic = &state.DynamicRiskBreakdown{
+			BaseScore:               dynamic.BaseScore,
+			HistoricalScore:         dynamic.HistoricalScore,
+			DynamicThreshold:        dynamic.DynamicThreshold,
+			KnowledgeBaseHit:        dynamic.KnowledgeBaseHit,
+			KnowledgeBaseAdjustment: dynamic.KnowledgeBaseAdjustment,
+			UserHistoryHit:          dynamic.UserHistoryHit,
+			UserHistoryAdjustment:   dynamic.UserHistoryAdjustment,
+			AdjustedScore:           dynamic.CurrentScore,
+			RiskLevel:               dynamic.RiskLevel,
+		}
+	}
+	return breakdown
+}
+
+// QueryUserInfo 查询当前用户画像；真实姓名以任务内姓名占位符返回，不直接发送给模型。
 func QueryUserInfo(ctx context.Context, interval string) (map[string]interface{}, error) {
 	info, err := user_profile_system.BuildUserRiskInfo(CurrentUserID(ctx), interval)
 	if err != nil {
 		return nil, err
 	}
+	userName := info.UserName
+	if strings.TrimSpace(userName) != CurrentUserID(ctx) {
+		userName = redaction.FromContext(ctx).Protect(redaction.KindName, userName)
+	}
 	return map[string]interface{}{
-		"user_name":            info.UserName,
+		"user_name":            userName,
 		"age":                  info.Age,
 		"occupation":           info.Occupation,
 		"recent_tags":          info.RecentTags,
@@ -175,6 +204,8 @@ func QueryUserInfo(ctx context.Context, interval string) (map[string]interface{}
 // 1) 原始输入（text/videos/audios/images）来自 CurrentTaskPayload(ctx)
 // 2) 子模态洞察来自 CurrentTaskInsights(ctx)
 // 3) 最终报告来自 CurrentFinalReport(ctx)
+// 4) 结构化线索来自 CurrentTaskIndicators(ctx)
+// 模型生成的标题与摘要会再经过任务级脱敏，保证落库与向量化的文本只含占位符。
 func WriteUserHistoryCase(ctx context.Context, input WriteUserHistoryCaseInput) (map[string]interface{}, error) {
 	normalizedScamType, scamTypeErr := normalizeAndValidateScamType(input.ScamType)
 	if scamTypeErr != nil {
@@ -187,15 +218,32 @@ func WriteUserHistoryCase(ctx context.Context, input WriteUserHistoryCaseInput)
 	if strings.TrimSpace(assessment.StructuredSummary) == "" {
 		return nil, fmt.Errorf("risk assessment is missing, please call %s first", RiskAssessmentToolName)
 	}
-	record := state.AddCaseHistory(CurrentUserID(ctx), CurrentTaskID(ctx), input.Title, input.CaseSummary, normalizedScamType, input.RiskLevel, assessment.Score, assessment.StructuredSummary, state.TaskPayload{
-		Text:          payload.Text,
-		Videos:        append([]string{}, payload.Videos...),
-		Audios:        append([]string{}, payload.Audios...),
-		Images:        append([]string{}, payload.Images...),
-		VideoInsights: append([]string{}, insights.VideoInsights...),
-		AudioInsights: append([]string{}, insights.AudioInsights...),
-		ImageInsights: append([]string{}, insights.ImageInsights...),
-	}, CurrentFinalReport(ctx))
+	breakdown := buildHistoryRiskBreakdown(ctx, assessment)
+	redactor := redaction.FromContext(ctx)
+	record := state.AddCaseHistory(state.CaseHistoryRecord{
+		RecordID:      CurrentTaskID(ctx),
+		UserID:        CurrentUserID(ctx),
+		Title:         redactor.Redact(input.Title),
+		CaseSummary:   redactor.Redact(input.CaseSummary),
+		ScamType:      normalizedScamType,
+		RiskLevel:     input.RiskLevel,
+		RiskScore:     assessment.Score,
+		RiskSummary:   assessment.StructuredSummary,
+		RiskBreakdown: &breakdown,
+		Indicators:    CurrentTaskIndicators(ctx),
+		Payload: state.TaskPayload{
+			Text:          payload.Text,
+			Videos:        append([]string{}, payload.Videos...),
+			Audios:        append([]string{}, payload.Audios...),
+			Images:        append([]string{}, payload.Images...),
+			VideoInsights: append([]string{}, insights.VideoInsights...),
+			AudioInsights: append([]string{}, insights.AudioInsights...),
+			ImageInsights: append([]string{}, insights.ImageInsights...),
+			ExtraInputs:   payload.ExtraInputs,
+			ExtraInsights: insights.ExtraInsights,
+		},
+		Report: CurrentFinalReport(ctx),
+	})
 
 	result := map[string]interface{}{
 		"status":       "success",
@@ -210,6 +258,13 @@ func WriteUserHistoryCase(ctx context.Context, input WriteUserHistoryCaseInput)
 		"stored_level": record.RiskLevel,
 		"risk_score":   record.RiskScore,
 		"risk_summary": record.RiskSummary,
+		"rule_version": record.RuleVersion,
+	}
+	if record.RiskBreakdown != nil {
+		result["risk_breakdown"] = record.RiskBreakdown
+	}
+	if len(record.Indicators) > 0 {
+		result["indicators"] = record.Indicators
 	}
 
 	indexRecord, indexErr := user_history_index.UpsertHistoryVector(ctx, user_history_index.ArchiveInput{
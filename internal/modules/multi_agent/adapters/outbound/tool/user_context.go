@@ -3,6 +3,8 @@ package tool
 import (
 	"context"
 	"strings"
+
+	"antifraud/internal/modules/multi_agent/adapters/outbound/state"
 )
 
 type userIDContextKey struct{}
@@ -12,23 +14,29 @@ type taskInsightContextKey struct{}
 type finalReportContextKey struct{}
 type riskAssessmentContextKey struct{}
 type historicalScoreContextKey struct{}
+type dynamicRiskLevelContextKey struct{}
+type taskIndicatorsContextKey struct{}
 
 type TaskPayloadContext struct {
-	Text   string
-	Videos []string
-	Audios []string
-	Images []string
+	Text        string
+	Videos      []string
+	Audios      []string
+	Images      []string
+	ExtraInputs map[string][]string
 }
 
 type TaskInsightContext struct {
 	VideoInsights []string
 	AudioInsights []string
 	ImageInsights []string
+	ExtraInsights map[string][]string
 }
 
 type RiskAssessmentContext struct {
 	Score             int
 	StructuredSummary string
+	RuleSetVersion    string
+	Breakdown         state.RiskBreakdown
 }
 
 type HistoricalScoreContext struct {
@@ -84,16 +92,17 @@ func CurrentTaskID(ctx context.Context) string {
 	return strings.TrimSpace(tid)
 }
 
-// BindTaskPayload 将任务原始输入写入 ctx（文本 + 各模态 base64 列表）。
+// BindTaskPayload 将任务原始输入写入 ctx（文本 + 各模态 base64 列表，含扩展模态）。
 // write_user_history_case 在归档时会读取该 payload 并持久化到 history_cases。
-func BindTaskPayload(ctx context.Context, text string, videos []string, audios []string, images []string) context.Context {
-	payload := TaskPayloadContext{
-		Text:   strings.TrimSpace(text),
-		Videos: append([]string{}, videos...),
-		Audios: append([]string{}, audios...),
-		Images: append([]string{}, images...),
+func BindTaskPayload(ctx context.Context, payload state.TaskPayload) context.Context {
+	bound := TaskPayloadContext{
+		Text:        strings.TrimSpace(payload.Text),
+		Videos:      append([]string{}, payload.Videos...),
+		Audios:      append([]string{}, payload.Audios...),
+		Images:      append([]string{}, payload.Images...),
+		ExtraInputs: state.CloneModalityLists(payload.ExtraInputs),
 	}
-	return context.WithValue(ctx, taskPayloadContextKey{}, payload)
+	return context.WithValue(ctx, taskPayloadContextKey{}, bound)
 }
 
 // CurrentTaskPayload 从 ctx 读取原始任务输入。
@@ -108,20 +117,26 @@ func CurrentTaskPayload(ctx context.Context) TaskPayloadContext {
 		return TaskPayloadContext{}
 	}
 	return TaskPayloadContext{
-		Text:   strings.TrimSpace(payload.Text),
-		Videos: append([]string{}, payload.Videos...),
-		Audios: append([]string{}, payload.Audios...),
-		Images: append([]string{}, payload.Images...),
+		Text:        strings.TrimSpace(payload.Text),
+		Videos:      append([]string{}, payload.Videos...),
+		Audios:      append([]string{}, payload.Audios...),
+		Images:      append([]string{}, payload.Images...),
+		ExtraInputs: state.CloneModalityLists(payload.ExtraInputs),
 	}
 }
 
-// BindTaskInsights 将子模态分析结果（image/video/audio insights）写入 ctx。
+// BindTaskInsights 将子模态分析结果写入 ctx，insights 以模态名为 key。
 // 归档时会与原始 payload 一并写入历史记录。
-func BindTaskInsights(ctx context.Context, videoInsights []string, audioInsights []string, imageInsights []string) context.Context {
+func BindTaskInsights(ctx context.Context, insights map[string][]string) context.Context {
+	var payload state.TaskPayload
+	for modality, items := range insights {
+		payload.SetModalityInsights(strings.TrimSpace(modality), items)
+	}
 	insight := TaskInsightContext{
-		VideoInsights: append([]string{}, videoInsights...),
-		AudioInsights: append([]string{}, audioInsights...),
-		ImageInsights: append([]string{}, imageInsights...),
+		VideoInsights: payload.VideoInsights,
+		AudioInsights: payload.AudioInsights,
+		ImageInsights: payload.ImageInsights,
+		ExtraInsights: payload.ExtraInsights,
 	}
 	return context.WithValue(ctx, taskInsightContextKey{}, insight)
 }
@@ -141,9 +156,28 @@ func CurrentTaskInsights(ctx context.Context) TaskInsightContext {
 		VideoInsights: append([]string{}, insight.VideoInsights...),
 		AudioInsights: append([]string{}, insight.AudioInsights...),
 		ImageInsights: append([]string{}, insight.ImageInsights...),
+		ExtraInsights: state.CloneModalityLists(insight.ExtraInsights),
 	}
 }
 
+// BindTaskIndicators 将子智能体完成后抽取的结构化线索写入 ctx。
+// submit_final_report 把它们附加到报告末尾，write_user_history_case 随历史记录一并归档。
+func BindTaskIndicators(ctx context.Context, indicators []state.Indicator) context.Context {
+	return context.WithValue(ctx, taskIndicatorsContextKey{}, append([]state.Indicator{}, indicators...))
+}
+
+// CurrentTaskIndicators 从 ctx 读取结构化线索，未绑定时返回 nil。
+func CurrentTaskIndicators(ctx context.Context) []state.Indicator {
+	if ctx == nil {
+		return nil
+	}
+	indicators, ok := ctx.Value(taskIndicatorsContextKey{}).([]state.Indicator)
+	if !ok || len(indicators) == 0 {
+		return nil
+	}
+	return append([]state.Indicator{}, indicators...)
+}
+
 // BindFinalReport 将 submit_final_report 产生的最终报告文本写入 ctx。
 // write_user_history_case 在归档时读取该字段并落库。
 func BindFinalReport(ctx context.Context, report string) context.Context {
@@ -164,11 +198,13 @@ func CurrentFinalReport(ctx context.Context) string {
 	return strings.TrimSpace(report)
 }
 
-// BindRiskAssessment 将风险评分结果写入 ctx，供归档与报告阶段复用。
-func BindRiskAssessment(ctx context.Context, score int, structuredSummary string) context.Context {
+// BindRiskAssessment 将风险评分结果、所用规则集版本与评分明细写入 ctx，供归档与报告阶段复用。
+func BindRiskAssessment(ctx context.Context, result RiskAssessmentResult) context.Context {
 	return context.WithValue(ctx, riskAssessmentContextKey{}, RiskAssessmentContext{
-		Score:             score,
-		StructuredSummary: strings.TrimSpace(structuredSummary),
+		Score:             result.Score,
+		StructuredSummary: strings.TrimSpace(result.StructuredSummary),
+		RuleSetVersion:    strings.TrimSpace(result.RuleSetVersion),
+		Breakdown:         result.Breakdown,
 	})
 }
 
@@ -189,6 +225,7 @@ func CurrentRiskAssessment(ctx context.Context) RiskAssessmentContext {
 		assessment.Score = 100
 	}
 	assessment.StructuredSummary = strings.TrimSpace(assessment.StructuredSummary)
+	assessment.RuleSetVersion = strings.TrimSpace(assessment.RuleSetVersion)
 	return assessment
 }
 
@@ -221,3 +258,17 @@ func CurrentHistoricalScore(ctx context.Context) (int, bool) {
 	}
 	return historical.Score, true
 }
+
+// BindDynamicRiskLevel 将动态判级结果写入 ctx，归档时并入评分明细。
+func BindDynamicRiskLevel(ctx context.Context, result DynamicRiskLevelResult) context.Context {
+	return context.WithValue(ctx, dynamicRiskLevelContextKey{}, result)
+}
+
+// CurrentDynamicRiskLevel 返回当前上下文中的动态判级结果。
+func CurrentDynamicRiskLevel(ctx context.Context) (DynamicRiskLevelResult, bool) {
+	if ctx == nil {
+		return DynamicRiskLevelResult{}, false
+	}
+	result, ok := ctx.Value(dynamicRiskLevelContextKey{}).(DynamicRiskLevelResult)
+	return result, ok
+}
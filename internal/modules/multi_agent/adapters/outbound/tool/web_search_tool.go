@@ -7,6 +7,7 @@ import (
 
 	appcfg "antifraud/internal/platform/config"
 	openai "antifraud/internal/platform/llm"
+	"antifraud/internal/platform/redaction"
 	"antifraud/internal/platform/websearch"
 )
 
@@ -28,7 +29,7 @@ var WebSearchTool = openai.Tool{
 	Type: openai.ToolTypeFunction,
 	Function: &openai.FunctionDefinition{
 		Name:        WebSearchToolName,
-		Description: "使用 Tavily 执行联网搜索，返回摘要答案和相关网页结果。",
+		Description: "执行联网搜索，返回摘要答案和相关网页结果。",
 		Parameters: map[string]interface{}{
 			"type": "object",
 			"properties": map[string]interface{}{
@@ -47,8 +48,10 @@ var WebSearchTool = openai.Tool{
 }
 
 var loadWebSearchConfig = appcfg.LoadConfig
-var newWebSearcher = func(cfg appcfg.TavilyConfig) web_search_system.Searcher {
-	return web_search_system.NewTavilyClient(cfg)
+
+// newWebSearcher 按 web_search.provider 创建搜索器。
+var newWebSearcher = func(cfg appcfg.Config) (web_search_system.Searcher, error) {
+	return web_search_system.NewSearcher(cfg)
 }
 
 func ParseWebSearchInput(arguments string) (WebSearchInput, error) {
@@ -61,7 +64,8 @@ func ExecuteWebSearch(ctx context.Context, searcher web_search_system.Searcher,
 		return nil, fmt.Errorf("web searcher is nil")
 	}
 
-	trimmedQuery := strings.TrimSpace(input.Query)
+	// 搜索关键词发往第三方搜索服务，先不可逆地移除其中的个人信息。
+	trimmedQuery := strings.TrimSpace(redaction.Strip(input.Query))
 	if trimmedQuery == "" {
 		return nil, fmt.Errorf("query is empty")
 	}
@@ -78,13 +82,17 @@ func ExecuteWebSearch(ctx context.Context, searcher web_search_system.Searcher,
 		return nil, err
 	}
 
-	return map[string]interface{}{
+	payload := map[string]interface{}{
 		"query":       result.Query,
 		"answer":      result.Answer,
 		"max_results": resolvedMaxResults,
 		"results":     result.Results,
 		"status":      "success",
-	}, nil
+	}
+	if result.Provider != "" {
+		payload["provider"] = result.Provider
+	}
+	return payload, nil
 }
 
 type WebSearchHandler struct{}
@@ -107,7 +115,16 @@ func (h *WebSearchHandler) Handle(ctx context.Context, args string) (ToolRespons
 		}}, nil
 	}
 
-	payload, err := ExecuteWebSearch(ctx, newWebSearcher(cfg.Tavily), input)
+	searcher, err := newWebSearcher(*cfg)
+	if err != nil {
+		return ToolResponse{Payload: map[string]interface{}{
+			"query":  strings.TrimSpace(input.Query),
+			"status": "failed",
+			"error":  err.Error(),
+		}}, nil
+	}
+
+	payload, err := ExecuteWebSearch(ctx, searcher, input)
 	if err != nil {
 		return ToolResponse{Payload: map[string]interface{}{
 			"query":  strings.TrimSpace(input.Query),
@@ -0,0 +1,140 @@
+package user_history_index
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// HistoryVectorKey 是 embedding 迁移遍历用户历史索引时的一条记录键。
+type HistoryVectorKey struct {
+	RecordID             string
+	UserID               string
+	EmbeddingModel       string
+	StagedEmbeddingModel string
+}
+
+// ListHistoryVectorKeys 按 (user_id, record_id) 升序分批列出索引记录，after* 为上一批最后一条的键。
+func (s *Service) ListHistoryVectorKeys(afterUserID string, afterRecordID string, limit int) ([]HistoryVectorKey, error) {
+	if limit <= 0 {
+		return []HistoryVectorKey{}, nil
+	}
+	if err := s.repo.EnsureSchema(); err != nil {
+		return nil, err
+	}
+	rows, err := s.repo.ListAfter(strings.TrimSpace(afterUserID), strings.TrimSpace(afterRecordID), limit)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]HistoryVectorKey, 0, len(rows))
+	for _, row := range rows {
+		keys = append(keys, HistoryVectorKey{
+			RecordID:             strings.TrimSpace(row.RecordID),
+			UserID:               strings.TrimSpace(row.UserID),
+			EmbeddingModel:       strings.TrimSpace(row.EmbeddingModel),
+			StagedEmbeddingModel: strings.TrimSpace(row.StagedEmbeddingModel),
+		})
+	}
+	return keys, nil
+}
+
+// StageHistoryVectors 按目标模型为一批已索引的用户历史生成向量并写入暂存列，不影响当前检索。
+// 生成器支持批量时合并为一次批量调用；返回值与 inputs 一一对应，nil 表示该条暂存成功。
+func (s *Service) StageHistoryVectors(ctx context.Context, inputs []ArchiveInput, modelName string) []error {
+	errs := make([]error, len(inputs))
+	targetModel := strings.TrimSpace(modelName)
+	if targetModel == "" {
+		for index := range errs {
+			errs[index] = fmt.Errorf("target embedding model is empty")
+		}
+		return errs
+	}
+	if err := s.repo.EnsureSchema(); err != nil {
+		for index := range errs {
+			errs[index] = err
+		}
+		return errs
+	}
+
+	normalized := make([]ArchiveInput, 0, len(inputs))
+	texts := make([]string, 0, len(inputs))
+	indexes := make([]int, 0, len(inputs))
+	for index, input := range inputs {
+		item := normalizeArchiveInput(input)
+		if err := validateArchiveInput(item); err != nil {
+			errs[index] = err
+			continue
+		}
+		normalized = append(normalized, item)
+		texts = append(texts, BuildEmbeddingInput(item))
+		indexes = append(indexes, index)
+	}
+	if len(texts) == 0 {
+		return errs
+	}
+
+	vectors, err := s.generateWithModel(ctx, texts, targetModel)
+	if err != nil {
+		for _, index := range indexes {
+			errs[index] = fmt.Errorf("generate user history embedding failed: %w", err)
+		}
+		return errs
+	}
+	for offset, index := range indexes {
+		errs[index] = s.repo.SaveStaged(normalized[offset].RecordID, normalized[offset].UserID, vectors[offset], targetModel)
+	}
+	return errs
+}
+
+// generateWithModel 按指定模型生成一批向量，优先使用批量生成能力。
+func (s *Service) generateWithModel(ctx context.Context, texts []string, modelName string) ([][]float64, error) {
+	if generator, ok := s.vectorGen.(BatchVectorGenerator); ok {
+		vectors, _, err := generator.GenerateBatchWithModel(ctx, texts, modelName)
+		return vectors, err
+	}
+	generator, ok := s.vectorGen.(ModelVectorGenerator)
+	if !ok {
+		return nil, fmt.Errorf("vector generator does not support explicit embedding model")
+	}
+	vectors := make([][]float64, 0, len(texts))
+	for _, text := range texts {
+		vector, _, err := generator.GenerateWithModel(ctx, text, modelName)
+		if err != nil {
+			return nil, err
+		}
+		vectors = append(vectors, vector)
+	}
+	return vectors, nil
+}
+
+// CountHistoryVectors 返回索引总数，以及已具备目标模型向量（正式或暂存）的记录数。
+func (s *Service) CountHistoryVectors(modelName string) (int64, int64, error) {
+	if err := s.repo.EnsureSchema(); err != nil {
+		return 0, 0, err
+	}
+	return s.repo.CountByModel(strings.TrimSpace(modelName))
+}
+
+// PromoteStagedHistoryVectors 把目标模型的暂存向量整体切换为正式向量。
+func (s *Service) PromoteStagedHistoryVectors(modelName string) (int64, error) {
+	if err := s.repo.EnsureSchema(); err != nil {
+		return 0, err
+	}
+	return s.repo.PromoteStaged(strings.TrimSpace(modelName))
+}
+
+// ClearStagedHistoryVectors 清空全部暂存向量，用于放弃迁移。
+func (s *Service) ClearStagedHistoryVectors() error {
+	if err := s.repo.EnsureSchema(); err != nil {
+		return err
+	}
+	return s.repo.ClearStaged()
+}
+
+// DeleteHistoryVector 删除一条索引记录，用于清理源历史记录已被删除的孤儿向量。
+func (s *Service) DeleteHistoryVector(recordID string, userID string) error {
+	if err := s.repo.EnsureSchema(); err != nil {
+		return err
+	}
+	return s.repo.Delete(strings.TrimSpace(recordID), strings.TrimSpace(userID))
+}
@@ -3,9 +3,11 @@ package user_history_index
 import (
 	"context"
 	"fmt"
+	"log"
 	"math"
 	"sort"
 	"strings"
+	"time"
 
 	"antifraud/internal/platform/database"
 	"antifraud/internal/platform/embedding"
@@ -18,13 +20,35 @@ type VectorGenerator interface {
 	Generate(ctx context.Context, input string) ([]float64, string, error)
 }
 
+// ModelVectorGenerator 是可按指定模型生成向量的生成器，embedding 迁移与双写依赖该能力。
+type ModelVectorGenerator interface {
+	GenerateWithModel(ctx context.Context, input string, model string) ([]float64, string, error)
+}
+
+// BatchVectorGenerator 是可按指定模型批量生成向量的生成器，迁移时用于合并 embedding 请求。
+type BatchVectorGenerator interface {
+	GenerateBatchWithModel(ctx context.Context, inputs []string, model string) ([][]float64, string, error)
+}
+
 // Repository 定义用户历史向量索引仓储端口。
 type Repository interface {
 	EnsureSchema() error
 	Save(entity userHistoryVectorEntity) error
 	ListByUser(userID string) ([]userHistoryVectorEntity, error)
+	ListAfter(afterUserID string, afterRecordID string, limit int) ([]userHistoryVectorEntity, error)
+	SaveStaged(recordID string, userID string, vector []float64, modelName string) error
+	CountByModel(modelName string) (total int64, ready int64, err error)
+	PromoteStaged(modelName string) (int64, error)
+	ClearStaged() error
+	Delete(recordID string, userID string) error
 }
 
+// activeEmbeddingModel 返回当前固定的 embedding 模型，未指定查询模型时用于拒绝跨模型比较。
+var activeEmbeddingModel = embedding.ActiveModel
+
+// migrationTargetModel 返回正在迁移的目标模型，非空时写入路径双写目标模型向量。
+var migrationTargetModel = embedding.MigrationTargetModel
+
 // Service 编排用户历史向量索引。
 type Service struct {
 	repo      Repository
@@ -62,6 +86,7 @@ func (s *Service) UpsertHistoryVector(ctx context.Context, input ArchiveInput) (
 	}
 
 	entity := entityFromArchiveInput(normalized, vector, modelName)
+	s.stageMigrationVector(ctx, &entity, embeddingText)
 	if err := s.repo.EnsureSchema(); err != nil {
 		return IndexRecord{}, err
 	}
@@ -76,14 +101,20 @@ func (s *Service) SearchTopKSimilarHistoryByQuery(ctx context.Context, userID, q
 	if trimmedQuery == "" {
 		return nil, 0, fmt.Errorf("query is empty")
 	}
-	queryVector, _, err := s.vectorGen.Generate(ctx, trimmedQuery)
+	queryVector, modelName, err := s.vectorGen.Generate(ctx, trimmedQuery)
 	if err != nil {
 		return nil, 0, fmt.Errorf("generate query embedding failed: %w", err)
 	}
-	return s.SearchTopKSimilarHistoryByVector(userID, queryVector, topK)
+	return s.searchTopKSimilarHistory(userID, queryVector, modelName, topK)
 }
 
+// SearchTopKSimilarHistoryByVector 使用调用方提供的 query 向量召回，查询向量视为当前固定模型生成。
 func (s *Service) SearchTopKSimilarHistoryByVector(userID string, queryVector []float64, topK int) ([]SimilarHistoryResult, int, error) {
+	return s.searchTopKSimilarHistory(userID, queryVector, activeEmbeddingModel(), topK)
+}
+
+// searchTopKSimilarHistory 只与同一 embedding 模型的历史向量比较；queryModel 为空时不做模型约束。
+func (s *Service) searchTopKSimilarHistory(userID string, queryVector []float64, queryModel string, topK int) ([]SimilarHistoryResult, int, error) {
 	normalizedUserID := strings.TrimSpace(userID)
 	if normalizedUserID == "" {
 		return nil, 0, fmt.Errorf("user_id is empty")
@@ -101,19 +132,48 @@ func (s *Service) SearchTopKSimilarHistoryByVector(userID string, queryVector []
 	if err != nil {
 		return nil, appliedTopK, err
 	}
-	results := scoreSimilarities(normalizedUserID, normalizedQuery, rows)
+	results := scoreSimilarities(normalizedUserID, normalizedQuery, strings.TrimSpace(queryModel), rows)
 	if len(results) > appliedTopK {
 		results = results[:appliedTopK]
 	}
 	return results, appliedTopK, nil
 }
 
+// stageMigrationVector 在 embedding 迁移进行中为新写入的记录双写目标模型向量；失败只记录日志，由迁移任务收尾补齐。
+func (s *Service) stageMigrationVector(ctx context.Context, entity *userHistoryVectorEntity, embeddingText string) {
+	target := strings.TrimSpace(migrationTargetModel())
+	if target == "" || target == entity.EmbeddingModel {
+		return
+	}
+	generator, ok := s.vectorGen.(ModelVectorGenerator)
+	if !ok {
+		return
+	}
+	vector, _, err := generator.GenerateWithModel(ctx, embeddingText, target)
+	if err != nil {
+		log.Printf("[user_history_index] dual-write migration vector failed: record=%s model=%s err=%v", entity.RecordID, target, err)
+		return
+	}
+	cleanVector := sanitizeFloatList(vector)
+	entity.StagedEmbeddingVector = encodeFloatList(cleanVector)
+	entity.StagedEmbeddingModel = target
+	entity.StagedEmbeddingDimension = len(cleanVector)
+}
+
 type embeddingVectorGenerator struct{}
 
 func (embeddingVectorGenerator) Generate(ctx context.Context, input string) ([]float64, string, error) {
 	return embedding.GenerateVector(ctx, input)
 }
 
+func (embeddingVectorGenerator) GenerateWithModel(ctx context.Context, input string, model string) ([]float64, string, error) {
+	return embedding.GenerateVectorWithModel(ctx, input, model)
+}
+
+func (embeddingVectorGenerator) GenerateBatchWithModel(ctx context.Context, inputs []string, model string) ([][]float64, string, error) {
+	return embedding.GenerateVectorsWithModel(ctx, inputs, model)
+}
+
 type gormRepository struct {
 	db *gorm.DB
 }
@@ -148,9 +208,107 @@ func (r *gormRepository) ListByUser(userID string) ([]userHistoryVectorEntity, e
 	return rows, nil
 }
 
-func scoreSimilarities(normalizedUserID string, normalizedQuery []float64, rows []userHistoryVectorEntity) []SimilarHistoryResult {
+func (r *gormRepository) ListAfter(afterUserID string, afterRecordID string, limit int) ([]userHistoryVectorEntity, error) {
+	if r == nil || r.db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+	rows := make([]userHistoryVectorEntity, 0, limit)
+	if err := r.db.
+		Where("user_id > ? OR (user_id = ? AND record_id > ?)", afterUserID, afterUserID, afterRecordID).
+		Order("user_id asc").
+		Order("record_id asc").
+		Limit(limit).
+		Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("list user history vectors failed: %w", err)
+	}
+	return rows, nil
+}
+
+func (r *gormRepository) SaveStaged(recordID string, userID string, vector []float64, modelName string) error {
+	if r == nil || r.db == nil {
+		return fmt.Errorf("database not initialized")
+	}
+	cleanVector := sanitizeFloatList(vector)
+	result := r.db.Model(&userHistoryVectorEntity{}).
+		Where("record_id = ? AND user_id = ?", recordID, userID).
+		Updates(map[string]interface{}{
+			"staged_embedding_vector":    encodeFloatList(cleanVector),
+			"staged_embedding_model":     strings.TrimSpace(modelName),
+			"staged_embedding_dimension": len(cleanVector),
+		})
+	if result.Error != nil {
+		return fmt.Errorf("stage user history vector failed: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("user history vector not found: record_id=%s", recordID)
+	}
+	return nil
+}
+
+func (r *gormRepository) CountByModel(modelName string) (int64, int64, error) {
+	if r == nil || r.db == nil {
+		return 0, 0, fmt.Errorf("database not initialized")
+	}
+	var total int64
+	if err := r.db.Model(&userHistoryVectorEntity{}).Count(&total).Error; err != nil {
+		return 0, 0, fmt.Errorf("count user history vectors failed: %w", err)
+	}
+	var ready int64
+	if err := r.db.Model(&userHistoryVectorEntity{}).
+		Where("embedding_model = ? OR staged_embedding_model = ?", modelName, modelName).
+		Count(&ready).Error; err != nil {
+		return 0, 0, fmt.Errorf("count migrated user history vectors failed: %w", err)
+	}
+	return total, ready, nil
+}
+
+func (r *gormRepository) PromoteStaged(modelName string) (int64, error) {
+	if r == nil || r.db == nil {
+		return 0, fmt.Errorf("database not initialized")
+	}
+	result := r.db.Exec(`UPDATE user_history_vectors
+		SET embedding_vector = staged_embedding_vector,
+			embedding_model = staged_embedding_model,
+			embedding_dimension = staged_embedding_dimension,
+			staged_embedding_vector = '',
+			staged_embedding_model = '',
+			staged_embedding_dimension = 0,
+			updated_at = ?
+		WHERE staged_embedding_model = ?`, time.Now(), modelName)
+	if result.Error != nil {
+		return 0, fmt.Errorf("promote staged user history vectors failed: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+func (r *gormRepository) ClearStaged() error {
+	if r == nil || r.db == nil {
+		return fmt.Errorf("database not initialized")
+	}
+	if err := r.db.Exec(`UPDATE user_history_vectors
+		SET staged_embedding_vector = '', staged_embedding_model = '', staged_embedding_dimension = 0
+		WHERE staged_embedding_model <> ''`).Error; err != nil {
+		return fmt.Errorf("clear staged user history vectors failed: %w", err)
+	}
+	return nil
+}
+
+func (r *gormRepository) Delete(recordID string, userID string) error {
+	if r == nil || r.db == nil {
+		return fmt.Errorf("database not initialized")
+	}
+	if err := r.db.Where("record_id = ? AND user_id = ?", recordID, userID).Delete(&userHistoryVectorEntity{}).Error; err != nil {
+		return fmt.Errorf("delete user history vector failed: %w", err)
+	}
+	return nil
+}
+
+func scoreSimilarities(normalizedUserID string, normalizedQuery []float64, queryModel string, rows []userHistoryVectorEntity) []SimilarHistoryResult {
 	results := make([]SimilarHistoryResult, 0, len(rows))
 	for _, row := range rows {
+		if rowModel := strings.TrimSpace(row.EmbeddingModel); queryModel != "" && rowModel != "" && rowModel != queryModel {
+			continue
+		}
 		storedVector, err := decodeFloatList(row.EmbeddingVector)
 		if err != nil {
 			continue
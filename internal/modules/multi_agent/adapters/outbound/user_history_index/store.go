@@ -55,6 +55,11 @@ type userHistoryVectorEntity struct {
 	EmbeddingDimension int       `gorm:"not null"`
 	CreatedAt          time.Time `gorm:"index;not null"`
 	UpdatedAt          time.Time `gorm:"index;not null"`
+
+	// Staged* 保存 embedding 迁移期间按目标模型生成的向量，切换完成后写回 Embedding* 并清空。
+	StagedEmbeddingVector    string `gorm:"type:text"`
+	StagedEmbeddingModel     string `gorm:"size:128;index"`
+	StagedEmbeddingDimension int
 }
 
 func (userHistoryVectorEntity) TableName() string {
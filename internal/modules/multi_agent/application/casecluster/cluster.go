@@ -0,0 +1,288 @@
+package casecluster
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"antifraud/internal/modules/multi_agent/adapters/outbound/case_library"
+)
+
+// Cluster 是一组互相近似的历史案件；SuggestedCanonicalID 为建议保留的案件（与组内其他案件平均相似度最高）。
+// MinSimilarity/MaxSimilarity 为组内成立的相似边的综合得分范围。
+type Cluster struct {
+	ClusterID            int
+	SuggestedCanonicalID string
+	MinSimilarity        float64
+	MaxSimilarity        float64
+	Members              []ClusterMember
+}
+
+// ClusterMember 是聚类中的单个案件；MergedInto 非空表示该案件在聚类完成后已被合并。
+type ClusterMember struct {
+	CaseID            string
+	Title             string
+	ScamType          string
+	CreatedAt         time.Time
+	AverageSimilarity float64
+	MergedInto        string
+}
+
+type clusterCandidate struct {
+	record   case_library.HistoricalCaseRecord
+	keywords map[string]struct{}
+	norm     float64
+}
+
+// clusterResult 是一次聚类计算的产物。
+type clusterResult struct {
+	Model         string
+	Scanned       int
+	Skipped       int
+	ComparedPairs int64
+	Clusters      []Cluster
+	Total         int
+}
+
+// clusterCases 对同一 embedding 模型下的案件两两比较，综合得分不低于 threshold 的案件对视为近似，
+// 再按连通分量归并为聚类。向量维度或模型与多数案件不一致、缺少向量的案件被跳过。
+func clusterCases(records []case_library.HistoricalCaseRecord, options Options) clusterResult {
+	model := dominantModel(records)
+	candidates := make([]clusterCandidate, 0, len(records))
+	dimension := 0
+	for _, record := range records {
+		if len(record.EmbeddingVector) == 0 || strings.TrimSpace(record.EmbeddingModel) != model {
+			continue
+		}
+		if dimension == 0 {
+			dimension = len(record.EmbeddingVector)
+		}
+		if len(record.EmbeddingVector) != dimension {
+			continue
+		}
+		norm := vectorNorm(record.EmbeddingVector)
+		if norm == 0 {
+			continue
+		}
+		candidates = append(candidates, clusterCandidate{
+			record:   record,
+			keywords: keywordSet(record.Keywords),
+			norm:     norm,
+		})
+	}
+
+	result := clusterResult{
+		Model:   model,
+		Scanned: len(records),
+		Skipped: len(records) - len(candidates),
+	}
+	parents := make([]int, len(candidates))
+	for index := range parents {
+		parents[index] = index
+	}
+	edges := map[[2]int]float64{}
+	for i := 0; i < len(candidates); i++ {
+		for j := i + 1; j < len(candidates); j++ {
+			result.ComparedPairs++
+			score := pairScore(candidates[i], candidates[j], options.KeywordWeight)
+			if score < options.Threshold {
+				continue
+			}
+			edges[[2]int{i, j}] = score
+			union(parents, i, j)
+		}
+	}
+
+	groups := map[int][]int{}
+	for index := range candidates {
+		root := find(parents, index)
+		groups[root] = append(groups[root], index)
+	}
+	clusters := make([]Cluster, 0)
+	for _, members := range groups {
+		if len(members) < 2 {
+			continue
+		}
+		clusters = append(clusters, buildCluster(candidates, members, edges, options.KeywordWeight))
+	}
+	sort.SliceStable(clusters, func(i, j int) bool {
+		if len(clusters[i].Members) != len(clusters[j].Members) {
+			return len(clusters[i].Members) > len(clusters[j].Members)
+		}
+		if clusters[i].MaxSimilarity != clusters[j].MaxSimilarity {
+			return clusters[i].MaxSimilarity > clusters[j].MaxSimilarity
+		}
+		return clusters[i].SuggestedCanonicalID < clusters[j].SuggestedCanonicalID
+	})
+	result.Total = len(clusters)
+	if len(clusters) > maxStoredClusters {
+		clusters = clusters[:maxStoredClusters]
+	}
+	for index := range clusters {
+		clusters[index].ClusterID = index + 1
+	}
+	result.Clusters = clusters
+	return result
+}
+
+func buildCluster(candidates []clusterCandidate, members []int, edges map[[2]int]float64, keywordWeight float64) Cluster {
+	cluster := Cluster{MinSimilarity: 1}
+	for _, edgeScore := range edgesWithin(members, edges) {
+		cluster.MinSimilarity = math.Min(cluster.MinSimilarity, edgeScore)
+		cluster.MaxSimilarity = math.Max(cluster.MaxSimilarity, edgeScore)
+	}
+
+	canonical := -1
+	canonicalScore := -1.0
+	for _, member := range members {
+		total := 0.0
+		for _, other := range members {
+			if other != member {
+				total += pairScore(candidates[member], candidates[other], keywordWeight)
+			}
+		}
+		average := roundScore(total / float64(len(members)-1))
+		record := candidates[member].record
+		cluster.Members = append(cluster.Members, ClusterMember{
+			CaseID:            record.CaseID,
+			Title:             record.Title,
+			ScamType:          record.ScamType,
+			CreatedAt:         record.CreatedAt,
+			AverageSimilarity: average,
+		})
+		if canonical < 0 || average > canonicalScore || (average == canonicalScore && preferCanonical(record, candidates[canonical].record)) {
+			canonical, canonicalScore = member, average
+		}
+	}
+	cluster.SuggestedCanonicalID = candidates[canonical].record.CaseID
+	sort.SliceStable(cluster.Members, func(i, j int) bool {
+		if cluster.Members[i].AverageSimilarity != cluster.Members[j].AverageSimilarity {
+			return cluster.Members[i].AverageSimilarity > cluster.Members[j].AverageSimilarity
+		}
+		return cluster.Members[i].CreatedAt.Before(cluster.Members[j].CreatedAt)
+	})
+	cluster.MinSimilarity = roundScore(cluster.MinSimilarity)
+	cluster.MaxSimilarity = roundScore(cluster.MaxSimilarity)
+	return cluster
+}
+
+func edgesWithin(members []int, edges map[[2]int]float64) []float64 {
+	scores := make([]float64, 0)
+	for i := 0; i < len(members); i++ {
+		for j := i + 1; j < len(members); j++ {
+			left, right := members[i], members[j]
+			if left > right {
+				left, right = right, left
+			}
+			if score, ok := edges[[2]int{left, right}]; ok {
+				scores = append(scores, score)
+			}
+		}
+	}
+	return scores
+}
+
+// preferCanonical 在平均相似度相同时优先保留内容更完整、入库更早的案件。
+func preferCanonical(candidate case_library.HistoricalCaseRecord, current case_library.HistoricalCaseRecord) bool {
+	candidateSize := len(candidate.TypicalScripts) + len(candidate.Keywords)
+	currentSize := len(current.TypicalScripts) + len(current.Keywords)
+	if candidateSize != currentSize {
+		return candidateSize > currentSize
+	}
+	return candidate.CreatedAt.Before(current.CreatedAt)
+}
+
+// pairScore 返回两个案件的综合相似度：向量余弦相似度与关键词 Jaccard 相似度按 keywordWeight 加权；
+// 两个案件都没有关键词时只看向量相似度。
+func pairScore(left clusterCandidate, right clusterCandidate, keywordWeight float64) float64 {
+	vectorScore := cosineSimilarity(left, right)
+	if len(left.keywords) == 0 && len(right.keywords) == 0 {
+		return vectorScore
+	}
+	return (1-keywordWeight)*vectorScore + keywordWeight*jaccardSimilarity(left.keywords, right.keywords)
+}
+
+func cosineSimilarity(left clusterCandidate, right clusterCandidate) float64 {
+	dot := 0.0
+	for index, value := range left.record.EmbeddingVector {
+		dot += value * right.record.EmbeddingVector[index]
+	}
+	return dot / (left.norm * right.norm)
+}
+
+func jaccardSimilarity(left map[string]struct{}, right map[string]struct{}) float64 {
+	if len(left) == 0 || len(right) == 0 {
+		return 0
+	}
+	intersection := 0
+	for keyword := range left {
+		if _, ok := right[keyword]; ok {
+			intersection++
+		}
+	}
+	unionSize := len(left) + len(right) - intersection
+	if unionSize == 0 {
+		return 0
+	}
+	return float64(intersection) / float64(unionSize)
+}
+
+func keywordSet(keywords []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(keywords))
+	for _, keyword := range keywords {
+		if normalized := strings.ToLower(strings.TrimSpace(keyword)); normalized != "" {
+			set[normalized] = struct{}{}
+		}
+	}
+	return set
+}
+
+func vectorNorm(vector []float64) float64 {
+	sum := 0.0
+	for _, value := range vector {
+		sum += value * value
+	}
+	return math.Sqrt(sum)
+}
+
+// dominantModel 返回案件中使用最多的 embedding 模型，数量相同时取名称较小者以保证结果稳定。
+func dominantModel(records []case_library.HistoricalCaseRecord) string {
+	counts := map[string]int{}
+	for _, record := range records {
+		if model := strings.TrimSpace(record.EmbeddingModel); model != "" && len(record.EmbeddingVector) > 0 {
+			counts[model]++
+		}
+	}
+	best, bestCount := "", 0
+	for model, count := range counts {
+		if count > bestCount || (count == bestCount && model < best) {
+			best, bestCount = model, count
+		}
+	}
+	return best
+}
+
+func find(parents []int, index int) int {
+	for parents[index] != index {
+		parents[index] = parents[parents[index]]
+		index = parents[index]
+	}
+	return index
+}
+
+func union(parents []int, left int, right int) {
+	leftRoot, rightRoot := find(parents, left), find(parents, right)
+	if leftRoot == rightRoot {
+		return
+	}
+	if leftRoot < rightRoot {
+		parents[rightRoot] = leftRoot
+		return
+	}
+	parents[leftRoot] = rightRoot
+}
+
+func roundScore(score float64) float64 {
+	return math.Round(score*10000) / 10000
+}
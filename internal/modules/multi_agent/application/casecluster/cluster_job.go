@@ -0,0 +1,431 @@
+package casecluster
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"antifraud/internal/modules/multi_agent/adapters/outbound/case_library"
+	"antifraud/internal/platform/database"
+
+	"gorm.io/gorm"
+)
+
+const (
+	JobStatusRunning   = "running"
+	JobStatusCompleted = "completed"
+	JobStatusFailed    = "failed"
+
+	DefaultThreshold     = 0.85
+	MinThreshold         = 0.5
+	MaxThreshold         = 0.99
+	DefaultKeywordWeight = 0.2
+	MaxKeywordWeight     = 0.5
+
+	// maxStoredClusters 是单个任务保存的聚类数上限，按规模与相似度排序后截断。
+	maxStoredClusters = 200
+)
+
+var (
+	ErrJobRunning     = errors.New("a case clustering job is already running")
+	ErrJobNotFound    = errors.New("case clustering job not found")
+	ErrInvalidOptions = errors.New("invalid case clustering options")
+)
+
+// jobDB 返回聚类任务表所在的主业务库。
+var jobDB = func() *gorm.DB { return database.DB }
+
+type jobEntity struct {
+	ID             uint       `gorm:"primaryKey;autoIncrement"`
+	JobID          string     `gorm:"size:64;uniqueIndex;not null"`
+	Status         string     `gorm:"size:32;index;not null"`
+	Threshold      float64    `gorm:"not null"`
+	KeywordWeight  float64    `gorm:"not null"`
+	ScamType       string     `gorm:"size:64"`
+	EmbeddingModel string     `gorm:"size:128"`
+	ScannedCases   int        `gorm:"not null;default:0"`
+	SkippedCases   int        `gorm:"not null;default:0"`
+	ComparedPairs  int64      `gorm:"not null;default:0"`
+	ClusterCount   int        `gorm:"not null;default:0"`
+	Clusters       string     `gorm:"type:text"`
+	LastError      string     `gorm:"type:text"`
+	CreatedBy      string     `gorm:"size:64"`
+	StartedAt      time.Time  `gorm:"not null"`
+	FinishedAt     *time.Time `gorm:""`
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+func (jobEntity) TableName() string {
+	return "case_cluster_jobs"
+}
+
+func init() {
+	database.RegisterMainDBSchemaInitializer("case_cluster_job", initJobSchema)
+}
+
+func initJobSchema(db *gorm.DB) error {
+	if db == nil {
+		return fmt.Errorf("case cluster job schema db is nil")
+	}
+	return db.AutoMigrate(&jobEntity{})
+}
+
+// Options 是聚类参数：Threshold 为判定近似的综合相似度下限，KeywordWeight 为关键词 Jaccard 相似度在综合得分中的权重，
+// ScamType 非空时只在该诈骗类型内聚类。
+type Options struct {
+	Threshold     float64
+	KeywordWeight float64
+	ScamType      string
+}
+
+// Job 是一次近似案件聚类任务的快照。ClusterCount 为发现的聚类总数，Clusters 最多保存 200 个，
+// 列表接口返回的 Job 不含 Clusters。
+type Job struct {
+	JobID          string
+	Status         string
+	Options        Options
+	EmbeddingModel string
+	ScannedCases   int
+	SkippedCases   int
+	ComparedPairs  int64
+	ClusterCount   int
+	Clusters       []Cluster
+	LastError      string
+	CreatedBy      string
+	StartedAt      time.Time
+	FinishedAt     *time.Time
+	UpdatedAt      time.Time
+}
+
+// CaseStore 是聚类任务读取历史案件的端口。
+type CaseStore interface {
+	StreamAll(callback func(case_library.HistoricalCaseRecord) error) error
+	ResolveCaseID(caseID string) (target string, redirected bool, err error)
+}
+
+// Service 负责近似案件聚类任务的持久化与后台执行；聚类只读案件库，合并由管理员确认后单独发起。
+// 同一时刻只允许一个聚类任务运行。
+type Service struct {
+	cases CaseStore
+
+	mu      sync.Mutex
+	running map[string]struct{}
+}
+
+var (
+	defaultServiceOnce sync.Once
+	defaultService     *Service
+)
+
+// NewService 创建聚类任务服务，cases 为 nil 时直接读取历史案件库。
+func NewService(cases CaseStore) *Service {
+	if cases == nil {
+		cases = caseLibraryStore{}
+	}
+	return &Service{
+		cases:   cases,
+		running: map[string]struct{}{},
+	}
+}
+
+// DefaultService 返回进程级聚类任务服务。
+func DefaultService() *Service {
+	defaultServiceOnce.Do(func() {
+		defaultService = NewService(nil)
+	})
+	return defaultService
+}
+
+// ResumeInterruptedJobs 重新执行进程重启前仍在运行的聚类任务；聚类只读案件库，重跑结果与原任务等价。
+func (s *Service) ResumeInterruptedJobs() {
+	db := jobDB()
+	if db == nil {
+		return
+	}
+	var rows []jobEntity
+	if err := db.Where("status = ?", JobStatusRunning).Order("id asc").Find(&rows).Error; err != nil {
+		log.Printf("[case_cluster] query interrupted jobs failed: %v", err)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for index, row := range rows {
+		if _, ok := s.running[row.JobID]; ok {
+			continue
+		}
+		if index > 0 {
+			s.finish(row.JobID, JobStatusFailed, "superseded by an earlier running clustering job")
+			continue
+		}
+		log.Printf("[case_cluster] resuming job: job_id=%s", row.JobID)
+		s.launchLocked(row)
+	}
+}
+
+// Start 校验参数后创建聚类任务并在后台执行；阈值或权重为 0 时使用默认值。
+func (s *Service) Start(userID string, options Options) (Job, error) {
+	db := jobDB()
+	if db == nil {
+		return Job{}, fmt.Errorf("database not initialized")
+	}
+	normalized, err := normalizeOptions(options)
+	if err != nil {
+		return Job{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var running int64
+	if err := db.Model(&jobEntity{}).Where("status = ?", JobStatusRunning).Count(&running).Error; err != nil {
+		return Job{}, fmt.Errorf("query running case clustering job failed: %w", err)
+	}
+	if running > 0 {
+		return Job{}, ErrJobRunning
+	}
+
+	entity := jobEntity{
+		JobID:         newJobID(),
+		Status:        JobStatusRunning,
+		Threshold:     normalized.Threshold,
+		KeywordWeight: normalized.KeywordWeight,
+		ScamType:      normalized.ScamType,
+		CreatedBy:     strings.TrimSpace(userID),
+		StartedAt:     time.Now(),
+	}
+	if err := db.Create(&entity).Error; err != nil {
+		return Job{}, fmt.Errorf("create case clustering job failed: %w", err)
+	}
+	s.launchLocked(entity)
+	return jobFromEntity(entity, false), nil
+}
+
+// Get 返回指定任务及其聚类结果，聚类成员中已被合并的案件会标注合并去向。
+func (s *Service) Get(jobID string) (Job, error) {
+	entity, err := loadJob(jobID)
+	if err != nil {
+		return Job{}, err
+	}
+	job := jobFromEntity(entity, true)
+	for clusterIndex := range job.Clusters {
+		members := job.Clusters[clusterIndex].Members
+		for memberIndex := range members {
+			target, redirected, err := s.cases.ResolveCaseID(members[memberIndex].CaseID)
+			if err != nil {
+				log.Printf("[case_cluster] resolve merged case failed: case_id=%s err=%v", members[memberIndex].CaseID, err)
+				continue
+			}
+			if redirected {
+				members[memberIndex].MergedInto = target
+			}
+		}
+	}
+	return job, nil
+}
+
+// List 按创建时间倒序返回最近的聚类任务，不含聚类明细。
+func (s *Service) List(limit int) ([]Job, error) {
+	db := jobDB()
+	if db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	var rows []jobEntity
+	if err := db.Omit("clusters").Order("id desc").Limit(limit).Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("list case clustering jobs failed: %w", err)
+	}
+	jobs := make([]Job, 0, len(rows))
+	for _, row := range rows {
+		jobs = append(jobs, jobFromEntity(row, false))
+	}
+	return jobs, nil
+}
+
+func (s *Service) launchLocked(entity jobEntity) {
+	s.running[entity.JobID] = struct{}{}
+	go s.run(entity)
+}
+
+func (s *Service) run(job jobEntity) {
+	defer func() {
+		s.mu.Lock()
+		delete(s.running, job.JobID)
+		s.mu.Unlock()
+	}()
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			log.Printf("[case_cluster] panic recovered: job_id=%s err=%v", job.JobID, recovered)
+			s.finish(job.JobID, JobStatusFailed, fmt.Sprintf("panic: %v", recovered))
+		}
+	}()
+
+	if err := s.cluster(&job); err != nil {
+		log.Printf("[case_cluster] job failed: job_id=%s err=%v", job.JobID, err)
+		s.finish(job.JobID, JobStatusFailed, err.Error())
+		return
+	}
+	log.Printf("[case_cluster] job completed: job_id=%s scanned=%d clusters=%d", job.JobID, job.ScannedCases, job.ClusterCount)
+}
+
+func (s *Service) cluster(job *jobEntity) error {
+	scamType := strings.TrimSpace(job.ScamType)
+	records := make([]case_library.HistoricalCaseRecord, 0)
+	err := s.cases.StreamAll(func(record case_library.HistoricalCaseRecord) error {
+		if scamType == "" || strings.TrimSpace(record.ScamType) == scamType {
+			records = append(records, record)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("load historical cases failed: %w", err)
+	}
+
+	result := clusterCases(records, Options{
+		Threshold:     job.Threshold,
+		KeywordWeight: job.KeywordWeight,
+	})
+	encoded, err := json.Marshal(result.Clusters)
+	if err != nil {
+		return fmt.Errorf("encode clusters failed: %w", err)
+	}
+
+	db := jobDB()
+	if db == nil {
+		return fmt.Errorf("database not initialized")
+	}
+	now := time.Now()
+	job.EmbeddingModel = result.Model
+	job.ScannedCases = result.Scanned
+	job.ClusterCount = result.Total
+	update := db.Model(&jobEntity{}).
+		Where("job_id = ? AND status = ?", job.JobID, JobStatusRunning).
+		Updates(map[string]interface{}{
+			"status":          JobStatusCompleted,
+			"embedding_model": result.Model,
+			"scanned_cases":   result.Scanned,
+			"skipped_cases":   result.Skipped,
+			"compared_pairs":  result.ComparedPairs,
+			"cluster_count":   result.Total,
+			"clusters":        string(encoded),
+			"finished_at":     &now,
+		})
+	if update.Error != nil {
+		return fmt.Errorf("save case clustering result failed: %w", update.Error)
+	}
+	return nil
+}
+
+// finish 把运行中的任务置为终态，返回是否确实发生了状态变更。
+func (s *Service) finish(jobID string, status string, lastError string) bool {
+	db := jobDB()
+	if db == nil {
+		return false
+	}
+	now := time.Now()
+	updates := map[string]interface{}{
+		"status":      status,
+		"finished_at": &now,
+	}
+	if lastError != "" {
+		updates["last_error"] = lastError
+	}
+	result := db.Model(&jobEntity{}).Where("job_id = ? AND status = ?", jobID, JobStatusRunning).Updates(updates)
+	if result.Error != nil {
+		log.Printf("[case_cluster] update job status failed: job_id=%s status=%s err=%v", jobID, status, result.Error)
+		return false
+	}
+	return result.RowsAffected > 0
+}
+
+func normalizeOptions(options Options) (Options, error) {
+	normalized := Options{
+		Threshold:     options.Threshold,
+		KeywordWeight: options.KeywordWeight,
+		ScamType:      strings.TrimSpace(options.ScamType),
+	}
+	if normalized.Threshold == 0 {
+		normalized.Threshold = DefaultThreshold
+	}
+	if normalized.Threshold < MinThreshold || normalized.Threshold > MaxThreshold {
+		return Options{}, fmt.Errorf("%w: threshold must be between %.2f and %.2f", ErrInvalidOptions, MinThreshold, MaxThreshold)
+	}
+	if normalized.KeywordWeight == 0 {
+		normalized.KeywordWeight = DefaultKeywordWeight
+	}
+	if normalized.KeywordWeight < 0 || normalized.KeywordWeight > MaxKeywordWeight {
+		return Options{}, fmt.Errorf("%w: keyword_weight must be between 0 and %.2f", ErrInvalidOptions, MaxKeywordWeight)
+	}
+	return normalized, nil
+}
+
+func loadJob(jobID string) (jobEntity, error) {
+	db := jobDB()
+	if db == nil {
+		return jobEntity{}, fmt.Errorf("database not initialized")
+	}
+	var entity jobEntity
+	result := db.Where("job_id = ?", strings.TrimSpace(jobID)).Limit(1).Find(&entity)
+	if result.Error != nil {
+		return jobEntity{}, fmt.Errorf("query case clustering job failed: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return jobEntity{}, ErrJobNotFound
+	}
+	return entity, nil
+}
+
+func jobFromEntity(entity jobEntity, withClusters bool) Job {
+	job := Job{
+		JobID:  entity.JobID,
+		Status: entity.Status,
+		Options: Options{
+			Threshold:     entity.Threshold,
+			KeywordWeight: entity.KeywordWeight,
+			ScamType:      entity.ScamType,
+		},
+		EmbeddingModel: entity.EmbeddingModel,
+		ScannedCases:   entity.ScannedCases,
+		SkippedCases:   entity.SkippedCases,
+		ComparedPairs:  entity.ComparedPairs,
+		ClusterCount:   entity.ClusterCount,
+		LastError:      entity.LastError,
+		CreatedBy:      entity.CreatedBy,
+		StartedAt:      entity.StartedAt,
+		FinishedAt:     entity.FinishedAt,
+		UpdatedAt:      entity.UpdatedAt,
+	}
+	if withClusters && strings.TrimSpace(entity.Clusters) != "" {
+		if err := json.Unmarshal([]byte(entity.Clusters), &job.Clusters); err != nil {
+			log.Printf("[case_cluster] decode clusters failed: job_id=%s err=%v", entity.JobID, err)
+		}
+	}
+	return job
+}
+
+func newJobID() string {
+	buf := make([]byte, 6)
+	if _, err := rand.Read(buf); err != nil {
+		return fmt.Sprintf("CASECLU-%d", time.Now().UnixNano())
+	}
+	return "CASECLU-" + strings.ToUpper(hex.EncodeToString(buf))
+}
+
+type caseLibraryStore struct{}
+
+func (caseLibraryStore) StreamAll(callback func(case_library.HistoricalCaseRecord) error) error {
+	return case_library.StreamAllHistoricalCases(callback)
+}
+
+func (caseLibraryStore) ResolveCaseID(caseID string) (string, bool, error) {
+	return case_library.ResolveHistoricalCaseID(caseID)
+}
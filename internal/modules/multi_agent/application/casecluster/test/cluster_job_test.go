@@ -0,0 +1,149 @@
+package casecluster_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"antifraud/internal/modules/multi_agent/adapters/outbound/case_library"
+	"antifraud/internal/modules/multi_agent/application/casecluster"
+	"antifraud/internal/modules/multi_agent/test/testsupport"
+)
+
+type fakeCaseStore struct {
+	records   []case_library.HistoricalCaseRecord
+	redirects map[string]string
+	block     chan struct{}
+}
+
+func (f *fakeCaseStore) StreamAll(callback func(case_library.HistoricalCaseRecord) error) error {
+	if f.block != nil {
+		<-f.block
+	}
+	for _, record := range f.records {
+		if err := callback(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *fakeCaseStore) ResolveCaseID(caseID string) (string, bool, error) {
+	target, ok := f.redirects[caseID]
+	return target, ok, nil
+}
+
+func waitForClusterJob(t *testing.T, service *casecluster.Service, jobID string, status string) casecluster.Job {
+	return testsupport.WaitForStatus(t,
+		func() (casecluster.Job, error) { return service.Get(jobID) },
+		func(job casecluster.Job) string { return job.Status },
+		status)
+}
+
+func clusterRecord(caseID string, vector []float64, keywords []string, createdAt time.Time) case_library.HistoricalCaseRecord {
+	return case_library.HistoricalCaseRecord{
+		CaseID:          caseID,
+		Title:           "案件 " + caseID,
+		ScamType:        "冒充客服类",
+		Keywords:        keywords,
+		EmbeddingVector: vector,
+		EmbeddingModel:  "embed-v1",
+		CreatedAt:       createdAt,
+	}
+}
+
+func TestCaseClusterJob_GroupsNearDuplicates(t *testing.T) {
+	testsupport.SetupMainDB(t)
+
+	base := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	store := &fakeCaseStore{
+		records: []case_library.HistoricalCaseRecord{
+			clusterRecord("HCASE-A", []float64{1, 0.02, 0}, []string{"客服", "退款"}, base),
+			clusterRecord("HCASE-B", []float64{1, 0, 0}, []string{"客服", "退款"}, base.Add(time.Hour)),
+			clusterRecord("HCASE-C", []float64{0.98, 0.05, 0}, []string{"客服", "借贷"}, base.Add(2*time.Hour)),
+			// 向量相近但关键词完全不同，综合得分低于阈值。
+			clusterRecord("HCASE-D", []float64{0.95, 0.3, 0}, []string{"刷单"}, base.Add(3*time.Hour)),
+			clusterRecord("HCASE-E", []float64{0, 0, 1}, nil, base.Add(4*time.Hour)),
+			clusterRecord("HCASE-F", []float64{0, 0.01, 1}, nil, base.Add(5*time.Hour)),
+			{CaseID: "HCASE-G", EmbeddingVector: []float64{1, 0, 0}, EmbeddingModel: "embed-v0"},
+		},
+		redirects: map[string]string{"HCASE-C": "HCASE-A"},
+	}
+	service := casecluster.NewService(store)
+
+	if _, err := service.Start("admin", casecluster.Options{Threshold: 1.2}); !errors.Is(err, casecluster.ErrInvalidOptions) {
+		t.Fatalf("expected invalid options error, got %v", err)
+	}
+
+	job, err := service.Start("admin", casecluster.Options{})
+	if err != nil {
+		t.Fatalf("start clustering failed: %v", err)
+	}
+	if job.Options.Threshold != casecluster.DefaultThreshold || job.Options.KeywordWeight != casecluster.DefaultKeywordWeight {
+		t.Fatalf("expected default options, got %+v", job.Options)
+	}
+
+	finished := waitForClusterJob(t, service, job.JobID, casecluster.JobStatusCompleted)
+	if finished.EmbeddingModel != "embed-v1" || finished.ScannedCases != 7 || finished.SkippedCases != 1 || finished.ComparedPairs != 15 {
+		t.Fatalf("unexpected job counters: %+v", finished)
+	}
+	if finished.ClusterCount != 2 || len(finished.Clusters) != 2 {
+		t.Fatalf("expected two clusters, got %+v", finished.Clusters)
+	}
+
+	largest := finished.Clusters[0]
+	if largest.ClusterID != 1 || len(largest.Members) != 3 || largest.SuggestedCanonicalID != "HCASE-A" {
+		t.Fatalf("unexpected largest cluster: %+v", largest)
+	}
+	if largest.MinSimilarity > largest.MaxSimilarity || largest.MaxSimilarity <= 0.9 {
+		t.Fatalf("unexpected similarity range: %+v", largest)
+	}
+	for _, member := range largest.Members {
+		if member.CaseID == "HCASE-D" {
+			t.Fatalf("case with unrelated keywords should not be clustered: %+v", largest)
+		}
+		if member.CaseID == "HCASE-C" && member.MergedInto != "HCASE-A" {
+			t.Fatalf("expected merged member annotated, got %+v", member)
+		}
+	}
+	if keywordless := finished.Clusters[1]; keywordless.SuggestedCanonicalID != "HCASE-E" || len(keywordless.Members) != 2 {
+		t.Fatalf("expected keywordless cases clustered by vector only, got %+v", keywordless)
+	}
+
+	jobs, err := service.List(0)
+	if err != nil || len(jobs) != 1 || jobs[0].Clusters != nil || jobs[0].ClusterCount != 2 {
+		t.Fatalf("expected list without cluster details, got %+v err=%v", jobs, err)
+	}
+	if _, err := service.Get("CASECLU-MISSING"); !errors.Is(err, casecluster.ErrJobNotFound) {
+		t.Fatalf("expected job not found, got %v", err)
+	}
+}
+
+func TestCaseClusterJob_RejectsConcurrentJobsAndFiltersScamType(t *testing.T) {
+	testsupport.SetupMainDB(t)
+
+	other := clusterRecord("HCASE-X", []float64{1, 0, 0}, []string{"客服"}, time.Now())
+	other.ScamType = "刷单返利类"
+	store := &fakeCaseStore{
+		records: []case_library.HistoricalCaseRecord{
+			clusterRecord("HCASE-A", []float64{1, 0, 0}, []string{"客服"}, time.Now()),
+			other,
+		},
+		block: make(chan struct{}),
+	}
+	service := casecluster.NewService(store)
+
+	job, err := service.Start("admin", casecluster.Options{ScamType: " 冒充客服类 "})
+	if err != nil {
+		t.Fatalf("start clustering failed: %v", err)
+	}
+	if _, err := service.Start("admin", casecluster.Options{}); !errors.Is(err, casecluster.ErrJobRunning) {
+		t.Fatalf("expected running job conflict, got %v", err)
+	}
+	close(store.block)
+
+	finished := waitForClusterJob(t, service, job.JobID, casecluster.JobStatusCompleted)
+	if finished.Options.ScamType != "冒充客服类" || finished.ScannedCases != 1 || finished.ClusterCount != 0 {
+		t.Fatalf("expected scam type filter applied, got %+v", finished)
+	}
+}
@@ -0,0 +1,681 @@
+package casecollection
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"antifraud/internal/modules/multi_agent/adapters/outbound/case_library"
+	"antifraud/internal/platform/database"
+
+	"gorm.io/gorm"
+)
+
+const (
+	CampaignRunStatusStarted = "started"
+	CampaignRunStatusSkipped = "skipped"
+
+	CampaignTriggerSchedule = "schedule"
+	CampaignTriggerManual   = "manual"
+
+	// MaxCampaignQueryTemplates 是单个采集计划允许配置的检索词模板数量上限。
+	MaxCampaignQueryTemplates = 10
+
+	campaignSkipOverlap    = "previous run still running"
+	campaignSkipDuplicates = "all results were rejected as duplicates last run"
+	campaignSkipAllQueries = "all queries skipped"
+)
+
+var (
+	ErrCampaignNotFound = errors.New("case collection campaign not found")
+	ErrCampaignRunning  = errors.New("case collection campaign still has running jobs")
+	ErrInvalidCampaign  = errors.New("invalid case collection campaign")
+)
+
+// campaignPollInterval 是调度器检查到期计划的间隔，cron 精度为分钟，半分钟轮询足够。
+var campaignPollInterval = 30 * time.Second
+
+// scamTypeAllowed 校验计划关注的诈骗类型是否在配置的类型列表中。
+var scamTypeAllowed = func(scamType string) bool {
+	for _, allowed := range case_library.ListScamTypes() {
+		if strings.TrimSpace(allowed) == scamType {
+			return true
+		}
+	}
+	return false
+}
+
+type campaignEntity struct {
+	ID             uint       `gorm:"primaryKey;autoIncrement"`
+	CampaignID     string     `gorm:"size:64;uniqueIndex;not null"`
+	Name           string     `gorm:"size:128;not null"`
+	QueryTemplates string     `gorm:"type:text;not null"`
+	CronExpr       string     `gorm:"size:128;not null"`
+	TargetCount    int        `gorm:"not null"`
+	ScamTypeFocus  string     `gorm:"size:64"`
+	Enabled        bool       `gorm:"not null"`
+	NextRunAt      *time.Time `gorm:"index"`
+	LastRunAt      *time.Time `gorm:""`
+	CreatedBy      string     `gorm:"size:64;index"`
+	UpdatedBy      string     `gorm:"size:64"`
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+func (campaignEntity) TableName() string {
+	return "case_collection_campaigns"
+}
+
+type campaignRunEntity struct {
+	ID          uint      `gorm:"primaryKey;autoIncrement"`
+	RunID       string    `gorm:"size:64;uniqueIndex;not null"`
+	CampaignID  string    `gorm:"size:64;index;not null"`
+	Trigger     string    `gorm:"size:16;not null"`
+	Status      string    `gorm:"size:16;not null"`
+	SkipReason  string    `gorm:"type:text"`
+	Queries     string    `gorm:"type:text"`
+	TriggeredBy string    `gorm:"size:64"`
+	ScheduledAt time.Time `gorm:"not null"`
+	CreatedAt   time.Time
+}
+
+func (campaignRunEntity) TableName() string {
+	return "case_collection_campaign_runs"
+}
+
+func init() {
+	database.RegisterMainDBSchemaInitializer("case_collection_campaign", initCampaignSchema)
+}
+
+func initCampaignSchema(db *gorm.DB) error {
+	if db == nil {
+		return fmt.Errorf("case collection campaign schema db is nil")
+	}
+	return db.AutoMigrate(&campaignEntity{}, &campaignRunEntity{})
+}
+
+// Campaign 是定时案件采集计划。
+// QueryTemplates 支持占位符 {scam_type}、{date}（2006-01-02）、{month}（2006-01）与 {year}；
+// TargetCount 为每个检索词单次采集的目标案件数。
+type Campaign struct {
+	CampaignID     string
+	Name           string
+	QueryTemplates []string
+	Cron           string
+	TargetCount    int
+	ScamTypeFocus  string
+	Enabled        bool
+	NextRunAt      *time.Time
+	LastRunAt      *time.Time
+	CreatedBy      string
+	UpdatedBy      string
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// CampaignInput 是创建采集计划的参数。
+type CampaignInput struct {
+	Name           string
+	QueryTemplates []string
+	Cron           string
+	TargetCount    int
+	ScamTypeFocus  string
+	Enabled        bool
+}
+
+// CampaignPatch 是修改采集计划的参数，nil 字段保持不变。
+type CampaignPatch struct {
+	Name           *string
+	QueryTemplates []string
+	Cron           *string
+	TargetCount    *int
+	ScamTypeFocus  *string
+	Enabled        *bool
+}
+
+// CampaignRunQuery 是一次计划运行中单个检索词的执行情况：要么启动了采集任务，要么带有跳过原因。
+type CampaignRunQuery struct {
+	Template   string `json:"template"`
+	Query      string `json:"query"`
+	JobID      string `json:"job_id,omitempty"`
+	SkipReason string `json:"skip_reason,omitempty"`
+	// Job 为查询历史时补充的任务快照，不持久化。
+	Job *Job `json:"-"`
+}
+
+// CampaignRun 是采集计划的一次触发记录。
+type CampaignRun struct {
+	RunID       string
+	CampaignID  string
+	Trigger     string
+	Status      string
+	SkipReason  string
+	Queries     []CampaignRunQuery
+	TriggeredBy string
+	ScheduledAt time.Time
+	CreatedAt   time.Time
+}
+
+// CreateCampaign 创建采集计划；启用的计划立即按 cron 计算下一次运行时间。
+func (s *Service) CreateCampaign(userID string, input CampaignInput) (Campaign, error) {
+	db := jobDB()
+	if db == nil {
+		return Campaign{}, fmt.Errorf("database not initialized")
+	}
+	normalized, schedule, err := normalizeCampaignInput(input)
+	if err != nil {
+		return Campaign{}, err
+	}
+	trimmedUserID := strings.TrimSpace(userID)
+	entity := campaignEntity{
+		CampaignID:     newCampaignID(),
+		Name:           normalized.Name,
+		QueryTemplates: encodeList(normalized.QueryTemplates),
+		CronExpr:       schedule.String(),
+		TargetCount:    normalized.TargetCount,
+		ScamTypeFocus:  normalized.ScamTypeFocus,
+		Enabled:        normalized.Enabled,
+		CreatedBy:      trimmedUserID,
+		UpdatedBy:      trimmedUserID,
+	}
+	if entity.Enabled {
+		next := schedule.Next(time.Now())
+		entity.NextRunAt = &next
+	}
+	if err := db.Create(&entity).Error; err != nil {
+		return Campaign{}, fmt.Errorf("create case collection campaign failed: %w", err)
+	}
+	return campaignFromEntity(entity), nil
+}
+
+// UpdateCampaign 修改采集计划；cron 或启用状态变化时重新计算下一次运行时间。
+func (s *Service) UpdateCampaign(campaignID string, userID string, patch CampaignPatch) (Campaign, error) {
+	entity, err := loadCampaign(campaignID)
+	if err != nil {
+		return Campaign{}, err
+	}
+
+	input := CampaignInput{
+		Name:           entity.Name,
+		QueryTemplates: decodeList(entity.QueryTemplates),
+		Cron:           entity.CronExpr,
+		TargetCount:    entity.TargetCount,
+		ScamTypeFocus:  entity.ScamTypeFocus,
+		Enabled:        entity.Enabled,
+	}
+	if patch.Name != nil {
+		input.Name = *patch.Name
+	}
+	if patch.QueryTemplates != nil {
+		input.QueryTemplates = patch.QueryTemplates
+	}
+	if patch.Cron != nil {
+		input.Cron = *patch.Cron
+	}
+	if patch.TargetCount != nil {
+		input.TargetCount = *patch.TargetCount
+	}
+	if patch.ScamTypeFocus != nil {
+		input.ScamTypeFocus = *patch.ScamTypeFocus
+	}
+	if patch.Enabled != nil {
+		input.Enabled = *patch.Enabled
+	}
+	normalized, schedule, err := normalizeCampaignInput(input)
+	if err != nil {
+		return Campaign{}, err
+	}
+
+	updates := map[string]interface{}{
+		"name":            normalized.Name,
+		"query_templates": encodeList(normalized.QueryTemplates),
+		"cron_expr":       schedule.String(),
+		"target_count":    normalized.TargetCount,
+		"scam_type_focus": normalized.ScamTypeFocus,
+		"enabled":         normalized.Enabled,
+		"updated_by":      strings.TrimSpace(userID),
+	}
+	switch {
+	case !normalized.Enabled:
+		updates["next_run_at"] = nil
+	case !entity.Enabled || entity.NextRunAt == nil || schedule.String() != entity.CronExpr:
+		next := schedule.Next(time.Now())
+		updates["next_run_at"] = &next
+	}
+	if err := jobDB().Model(&campaignEntity{}).Where("campaign_id = ?", entity.CampaignID).Updates(updates).Error; err != nil {
+		return Campaign{}, fmt.Errorf("update case collection campaign failed: %w", err)
+	}
+	return s.GetCampaign(entity.CampaignID)
+}
+
+// DeleteCampaign 删除采集计划及其运行记录；已启动的采集任务不受影响，仍可在任务列表中查询。
+func (s *Service) DeleteCampaign(campaignID string) error {
+	entity, err := loadCampaign(campaignID)
+	if err != nil {
+		return err
+	}
+	return jobDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("campaign_id = ?", entity.CampaignID).Delete(&campaignRunEntity{}).Error; err != nil {
+			return fmt.Errorf("delete case collection campaign runs failed: %w", err)
+		}
+		if err := tx.Where("campaign_id = ?", entity.CampaignID).Delete(&campaignEntity{}).Error; err != nil {
+			return fmt.Errorf("delete case collection campaign failed: %w", err)
+		}
+		return nil
+	})
+}
+
+// GetCampaign 返回指定采集计划。
+func (s *Service) GetCampaign(campaignID string) (Campaign, error) {
+	entity, err := loadCampaign(campaignID)
+	if err != nil {
+		return Campaign{}, err
+	}
+	return campaignFromEntity(entity), nil
+}
+
+// ListCampaigns 按创建时间倒序返回全部采集计划。
+func (s *Service) ListCampaigns() ([]Campaign, error) {
+	db := jobDB()
+	if db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+	var rows []campaignEntity
+	if err := db.Order("id desc").Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("list case collection campaigns failed: %w", err)
+	}
+	campaigns := make([]Campaign, 0, len(rows))
+	for _, row := range rows {
+		campaigns = append(campaigns, campaignFromEntity(row))
+	}
+	return campaigns, nil
+}
+
+// RunCampaignNow 立即触发一次采集计划，不影响 cron 排期；上一轮仍有任务运行时返回 ErrCampaignRunning。
+func (s *Service) RunCampaignNow(campaignID string, userID string) (CampaignRun, error) {
+	entity, err := loadCampaign(campaignID)
+	if err != nil {
+		return CampaignRun{}, err
+	}
+	running, err := campaignHasRunningJobs(entity.CampaignID)
+	if err != nil {
+		return CampaignRun{}, err
+	}
+	if running {
+		return CampaignRun{}, ErrCampaignRunning
+	}
+	return s.triggerCampaign(entity, CampaignTriggerManual, strings.TrimSpace(userID), time.Now())
+}
+
+// ListCampaignRuns 按时间倒序返回计划的运行历史，并补充每个检索词对应任务的最新快照。
+func (s *Service) ListCampaignRuns(campaignID string, limit int) ([]CampaignRun, error) {
+	entity, err := loadCampaign(campaignID)
+	if err != nil {
+		return nil, err
+	}
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	var rows []campaignRunEntity
+	if err := jobDB().Where("campaign_id = ?", entity.CampaignID).Order("id desc").Limit(limit).Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("list case collection campaign runs failed: %w", err)
+	}
+
+	runs := make([]CampaignRun, 0, len(rows))
+	jobIDs := make([]string, 0)
+	for _, row := range rows {
+		run := campaignRunFromEntity(row)
+		for _, query := range run.Queries {
+			if query.JobID != "" {
+				jobIDs = append(jobIDs, query.JobID)
+			}
+		}
+		runs = append(runs, run)
+	}
+	jobs, err := loadJobsByID(jobIDs)
+	if err != nil {
+		return nil, err
+	}
+	for runIndex := range runs {
+		for queryIndex := range runs[runIndex].Queries {
+			if job, ok := jobs[runs[runIndex].Queries[queryIndex].JobID]; ok {
+				snapshot := job
+				runs[runIndex].Queries[queryIndex].Job = &snapshot
+			}
+		}
+	}
+	return runs, nil
+}
+
+// StartCampaignScheduler 在后台轮询到期的采集计划，ctx 结束时停止；重复调用不会启动多个调度循环。
+func (s *Service) StartCampaignScheduler(ctx context.Context) {
+	s.schedulerOnce.Do(func() {
+		go func() {
+			ticker := time.NewTicker(campaignPollInterval)
+			defer ticker.Stop()
+			for {
+				s.RunDueCampaigns(time.Now())
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+				}
+			}
+		}()
+	})
+}
+
+// RunDueCampaigns 触发 next_run_at 不晚于 now 的启用计划，返回本次产生的运行记录。
+// 停机期间错过的多次排期只补跑一次；排期通过条件更新抢占，避免同一排期被重复触发。
+func (s *Service) RunDueCampaigns(now time.Time) []CampaignRun {
+	db := jobDB()
+	if db == nil {
+		return nil
+	}
+	var due []campaignEntity
+	if err := db.Where("enabled = ? AND next_run_at IS NOT NULL AND next_run_at <= ?", true, now).Order("next_run_at asc").Find(&due).Error; err != nil {
+		log.Printf("[case_collection_campaign] query due campaigns failed: %v", err)
+		return nil
+	}
+
+	runs := make([]CampaignRun, 0, len(due))
+	for _, entity := range due {
+		schedule, err := ParseCronSchedule(entity.CronExpr)
+		if err != nil {
+			log.Printf("[case_collection_campaign] invalid cron, campaign disabled: campaign_id=%s cron=%q err=%v", entity.CampaignID, entity.CronExpr, err)
+			db.Model(&campaignEntity{}).Where("campaign_id = ?", entity.CampaignID).Updates(map[string]interface{}{"enabled": false, "next_run_at": nil})
+			continue
+		}
+		scheduledAt := *entity.NextRunAt
+		next := schedule.Next(now)
+		result := db.Model(&campaignEntity{}).
+			Where("campaign_id = ? AND enabled = ? AND next_run_at IS NOT NULL AND next_run_at <= ?", entity.CampaignID, true, now).
+			Updates(map[string]interface{}{"next_run_at": &next, "last_run_at": &now})
+		if result.Error != nil {
+			log.Printf("[case_collection_campaign] advance schedule failed: campaign_id=%s err=%v", entity.CampaignID, result.Error)
+			continue
+		}
+		if result.RowsAffected == 0 {
+			continue
+		}
+
+		running, err := campaignHasRunningJobs(entity.CampaignID)
+		if err != nil {
+			log.Printf("[case_collection_campaign] check running jobs failed: campaign_id=%s err=%v", entity.CampaignID, err)
+			continue
+		}
+		var run CampaignRun
+		if running {
+			log.Printf("[case_collection_campaign] skip overlapping run: campaign_id=%s scheduled_at=%s", entity.CampaignID, scheduledAt.Format(time.RFC3339))
+			run, err = saveCampaignRun(campaignRunEntity{
+				CampaignID:  entity.CampaignID,
+				Trigger:     CampaignTriggerSchedule,
+				Status:      CampaignRunStatusSkipped,
+				SkipReason:  campaignSkipOverlap,
+				ScheduledAt: scheduledAt,
+			}, nil)
+		} else {
+			run, err = s.triggerCampaign(entity, CampaignTriggerSchedule, "", scheduledAt)
+		}
+		if err != nil {
+			log.Printf("[case_collection_campaign] run campaign failed: campaign_id=%s err=%v", entity.CampaignID, err)
+			continue
+		}
+		runs = append(runs, run)
+	}
+	return runs
+}
+
+// triggerCampaign 为计划的每个检索词启动采集任务；上一轮结果全部因重复被拒的检索词本轮跳过一次。
+func (s *Service) triggerCampaign(entity campaignEntity, trigger string, triggeredBy string, scheduledAt time.Time) (CampaignRun, error) {
+	previous, err := previousCampaignQueries(entity.CampaignID)
+	if err != nil {
+		return CampaignRun{}, err
+	}
+	owner := triggeredBy
+	if owner == "" {
+		owner = entity.CreatedBy
+	}
+
+	queries := make([]CampaignRunQuery, 0)
+	started := 0
+	for _, template := range decodeList(entity.QueryTemplates) {
+		item := CampaignRunQuery{
+			Template: template,
+			Query:    renderCampaignQuery(template, entity.ScamTypeFocus, scheduledAt),
+		}
+		if last, ok := previous[template]; ok && allRejectedAsDuplicates(last) {
+			item.SkipReason = campaignSkipDuplicates
+			queries = append(queries, item)
+			continue
+		}
+		job, err := s.start(owner, item.Query, entity.TargetCount, "", entity.CampaignID)
+		if err != nil {
+			item.SkipReason = err.Error()
+		} else {
+			item.JobID = job.JobID
+			started++
+		}
+		queries = append(queries, item)
+	}
+
+	run := campaignRunEntity{
+		CampaignID:  entity.CampaignID,
+		Trigger:     trigger,
+		Status:      CampaignRunStatusStarted,
+		TriggeredBy: triggeredBy,
+		ScheduledAt: scheduledAt,
+	}
+	if started == 0 {
+		run.Status = CampaignRunStatusSkipped
+		run.SkipReason = campaignSkipAllQueries
+	}
+	log.Printf("[case_collection_campaign] campaign triggered: campaign_id=%s trigger=%s started=%d/%d",
+		entity.CampaignID, trigger, started, len(queries))
+	return saveCampaignRun(run, queries)
+}
+
+// previousCampaignQueries 返回上一次运行中每个模板对应的任务；上次被跳过的模板不在结果中，本轮正常执行。
+func previousCampaignQueries(campaignID string) (map[string]Job, error) {
+	var rows []campaignRunEntity
+	if err := jobDB().Where("campaign_id = ? AND status = ?", campaignID, CampaignRunStatusStarted).Order("id desc").Limit(1).Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("query previous campaign run failed: %w", err)
+	}
+	result := map[string]Job{}
+	if len(rows) == 0 {
+		return result, nil
+	}
+	queries := decodeCampaignRunQueries(rows[0].Queries)
+	jobIDs := make([]string, 0, len(queries))
+	for _, query := range queries {
+		if query.JobID != "" {
+			jobIDs = append(jobIDs, query.JobID)
+		}
+	}
+	jobs, err := loadJobsByID(jobIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, query := range queries {
+		if job, ok := jobs[query.JobID]; ok {
+			result[query.Template] = job
+		}
+	}
+	return result, nil
+}
+
+func allRejectedAsDuplicates(job Job) bool {
+	return job.Status != JobStatusRunning && job.CreatedCount == 0 && job.DuplicateCount > 0
+}
+
+func campaignHasRunningJobs(campaignID string) (bool, error) {
+	var count int64
+	if err := jobDB().Model(&jobEntity{}).Where("campaign_id = ? AND status = ?", campaignID, JobStatusRunning).Count(&count).Error; err != nil {
+		return false, fmt.Errorf("count running campaign jobs failed: %w", err)
+	}
+	return count > 0, nil
+}
+
+func renderCampaignQuery(template string, scamTypeFocus string, at time.Time) string {
+	rendered := strings.NewReplacer(
+		"{scam_type}", scamTypeFocus,
+		"{date}", at.Format("2006-01-02"),
+		"{month}", at.Format("2006-01"),
+		"{year}", at.Format("2006"),
+	).Replace(template)
+	if scamTypeFocus != "" && !strings.Contains(template, "{scam_type}") {
+		rendered = scamTypeFocus + " " + rendered
+	}
+	return strings.Join(strings.Fields(rendered), " ")
+}
+
+func normalizeCampaignInput(input CampaignInput) (CampaignInput, CronSchedule, error) {
+	normalized := CampaignInput{
+		Name:          strings.TrimSpace(input.Name),
+		TargetCount:   input.TargetCount,
+		ScamTypeFocus: strings.TrimSpace(input.ScamTypeFocus),
+		Enabled:       input.Enabled,
+	}
+	if normalized.Name == "" {
+		return CampaignInput{}, CronSchedule{}, fmt.Errorf("%w: name is required", ErrInvalidCampaign)
+	}
+	seen := map[string]struct{}{}
+	for _, template := range input.QueryTemplates {
+		trimmed := strings.TrimSpace(template)
+		if trimmed == "" {
+			continue
+		}
+		if _, ok := seen[trimmed]; ok {
+			continue
+		}
+		seen[trimmed] = struct{}{}
+		normalized.QueryTemplates = append(normalized.QueryTemplates, trimmed)
+	}
+	if len(normalized.QueryTemplates) == 0 || len(normalized.QueryTemplates) > MaxCampaignQueryTemplates {
+		return CampaignInput{}, CronSchedule{}, fmt.Errorf("%w: query_templates must contain 1-%d entries", ErrInvalidCampaign, MaxCampaignQueryTemplates)
+	}
+	if normalized.TargetCount <= 0 || normalized.TargetCount > MaxCaseCount {
+		return CampaignInput{}, CronSchedule{}, fmt.Errorf("%w: target_count must be between 1 and %d", ErrInvalidCampaign, MaxCaseCount)
+	}
+	if normalized.ScamTypeFocus != "" && !scamTypeAllowed(normalized.ScamTypeFocus) {
+		return CampaignInput{}, CronSchedule{}, fmt.Errorf("%w: unknown scam_type_focus %q", ErrInvalidCampaign, normalized.ScamTypeFocus)
+	}
+	schedule, err := ParseCronSchedule(input.Cron)
+	if err != nil {
+		return CampaignInput{}, CronSchedule{}, fmt.Errorf("%w: %v", ErrInvalidCampaign, err)
+	}
+	normalized.Cron = schedule.String()
+	return normalized, schedule, nil
+}
+
+func saveCampaignRun(run campaignRunEntity, queries []CampaignRunQuery) (CampaignRun, error) {
+	if queries == nil {
+		queries = []CampaignRunQuery{}
+	}
+	encoded, err := json.Marshal(queries)
+	if err != nil {
+		return CampaignRun{}, fmt.Errorf("encode campaign run queries failed: %w", err)
+	}
+	run.RunID = newCampaignRunID()
+	run.Queries = string(encoded)
+	if err := jobDB().Create(&run).Error; err != nil {
+		return CampaignRun{}, fmt.Errorf("save campaign run failed: %w", err)
+	}
+	return campaignRunFromEntity(run), nil
+}
+
+func loadCampaign(campaignID string) (campaignEntity, error) {
+	db := jobDB()
+	if db == nil {
+		return campaignEntity{}, fmt.Errorf("database not initialized")
+	}
+	var entity campaignEntity
+	result := db.Where("campaign_id = ?", strings.TrimSpace(campaignID)).Limit(1).Find(&entity)
+	if result.Error != nil {
+		return campaignEntity{}, fmt.Errorf("query case collection campaign failed: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return campaignEntity{}, ErrCampaignNotFound
+	}
+	return entity, nil
+}
+
+func loadJobsByID(jobIDs []string) (map[string]Job, error) {
+	jobs := map[string]Job{}
+	if len(jobIDs) == 0 {
+		return jobs, nil
+	}
+	var rows []jobEntity
+	if err := jobDB().Where("job_id IN ?", jobIDs).Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("query campaign jobs failed: %w", err)
+	}
+	for _, row := range rows {
+		jobs[row.JobID] = jobFromEntity(row)
+	}
+	return jobs, nil
+}
+
+func campaignFromEntity(entity campaignEntity) Campaign {
+	return Campaign{
+		CampaignID:     entity.CampaignID,
+		Name:           entity.Name,
+		QueryTemplates: decodeList(entity.QueryTemplates),
+		Cron:           entity.CronExpr,
+		TargetCount:    entity.TargetCount,
+		ScamTypeFocus:  entity.ScamTypeFocus,
+		Enabled:        entity.Enabled,
+		NextRunAt:      entity.NextRunAt,
+		LastRunAt:      entity.LastRunAt,
+		CreatedBy:      entity.CreatedBy,
+		UpdatedBy:      entity.UpdatedBy,
+		CreatedAt:      entity.CreatedAt,
+		UpdatedAt:      entity.UpdatedAt,
+	}
+}
+
+func campaignRunFromEntity(entity campaignRunEntity) CampaignRun {
+	return CampaignRun{
+		RunID:       entity.RunID,
+		CampaignID:  entity.CampaignID,
+		Trigger:     entity.Trigger,
+		Status:      entity.Status,
+		SkipReason:  entity.SkipReason,
+		Queries:     decodeCampaignRunQueries(entity.Queries),
+		TriggeredBy: entity.TriggeredBy,
+		ScheduledAt: entity.ScheduledAt,
+		CreatedAt:   entity.CreatedAt,
+	}
+}
+
+func decodeCampaignRunQueries(raw string) []CampaignRunQuery {
+	queries := make([]CampaignRunQuery, 0)
+	if strings.TrimSpace(raw) == "" {
+		return queries
+	}
+	if err := json.Unmarshal([]byte(raw), &queries); err != nil {
+		return []CampaignRunQuery{}
+	}
+	return queries
+}
+
+func newCampaignID() string {
+	return "CAMPAIGN-" + randomIDSuffix()
+}
+
+func newCampaignRunID() string {
+	return "CAMPRUN-" + randomIDSuffix()
+}
+
+func randomIDSuffix() string {
+	buffer := make([]byte, 6)
+	if _, err := rand.Read(buffer); err != nil {
+		return fmt.Sprintf("%d", time.Now().UnixNano())
+	}
+	return strings.ToUpper(hex.EncodeToString(buffer))
+}
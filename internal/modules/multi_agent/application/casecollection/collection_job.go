@@ -0,0 +1,460 @@
+package casecollection
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	multi_agent "antifraud/internal/modules/multi_agent/core"
+	"antifraud/internal/platform/database"
+
+	"gorm.io/gorm"
+)
+
+const (
+	JobStatusRunning   = "running"
+	JobStatusCompleted = "completed"
+	JobStatusFailed    = "failed"
+	JobStatusCanceled  = "canceled"
+
+	// MaxCaseCount 与案件采集智能体的单次目标数量上限保持一致。
+	MaxCaseCount = 20
+)
+
+var (
+	ErrJobNotFound       = errors.New("case collection job not found")
+	ErrJobFinished       = errors.New("case collection job already finished")
+	ErrJobRunning        = errors.New("case collection job is still running")
+	ErrInvalidCollection = errors.New("invalid case collection request")
+)
+
+// jobDB 返回采集任务表所在的主业务库。
+var jobDB = func() *gorm.DB { return database.DB }
+
+type jobEntity struct {
+	ID             uint       `gorm:"primaryKey;autoIncrement"`
+	JobID          string     `gorm:"size:64;uniqueIndex;not null"`
+	Query          string     `gorm:"type:text;not null"`
+	RequestedCount int        `gorm:"not null"`
+	Status         string     `gorm:"size:32;index;not null"`
+	RoundsUsed     int        `gorm:"not null;default:0"`
+	SearchQueries  string     `gorm:"type:text"`
+	RecordIDs      string     `gorm:"type:text"`
+	CreatedCount   int        `gorm:"not null;default:0"`
+	DuplicateCount int        `gorm:"not null;default:0"`
+	LastError      string     `gorm:"type:text"`
+	CreatedBy      string     `gorm:"size:64;index"`
+	RetryOf        string     `gorm:"size:64;index"`
+	CampaignID     string     `gorm:"size:64;index"`
+	StartedAt      time.Time  `gorm:"not null"`
+	FinishedAt     *time.Time `gorm:""`
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+func (jobEntity) TableName() string {
+	return "case_collection_jobs"
+}
+
+func init() {
+	database.RegisterMainDBSchemaInitializer("case_collection_job", initJobSchema)
+}
+
+func initJobSchema(db *gorm.DB) error {
+	if db == nil {
+		return fmt.Errorf("case collection job schema db is nil")
+	}
+	return db.AutoMigrate(&jobEntity{})
+}
+
+// Job 是一次案件采集任务的进度快照。
+// SearchQueries 为实际下发给搜索服务（Tavily）的检索词，RecordIDs 为已写入待审核队列的记录；
+// DuplicateCount 为因与已有案件重复被拒绝写入的次数，CampaignID 非空表示由定时采集计划触发。
+type Job struct {
+	JobID          string
+	Query          string
+	RequestedCount int
+	Status         string
+	RoundsUsed     int
+	SearchQueries  []string
+	RecordIDs      []string
+	CreatedCount   int
+	DuplicateCount int
+	LastError      string
+	CreatedBy      string
+	RetryOf        string
+	CampaignID     string
+	StartedAt      time.Time
+	FinishedAt     *time.Time
+	UpdatedAt      time.Time
+}
+
+// Collector 是采集任务驱动案件采集智能体的端口，进度事件回传给 observer。
+type Collector interface {
+	Collect(ctx context.Context, userID string, query string, caseCount int, observer multi_agent.CaseCollectionObserver) error
+}
+
+type agentCollector struct{}
+
+func (agentCollector) Collect(ctx context.Context, userID string, query string, caseCount int, observer multi_agent.CaseCollectionObserver) error {
+	return multi_agent.CollectCasesForUserContext(multi_agent.WithCaseCollectionObserver(ctx, observer), userID, query, caseCount)
+}
+
+// Service 负责案件采集任务的持久化、后台执行与取消。
+// 采集过程依赖模型对话上下文，进程重启后无法续跑，中断的任务会被标记为失败并可通过重试重新发起。
+type Service struct {
+	collector Collector
+
+	mu      sync.Mutex
+	running map[string]context.CancelFunc
+
+	schedulerOnce sync.Once
+}
+
+var (
+	defaultServiceOnce sync.Once
+	defaultService     *Service
+)
+
+// NewService 创建采集任务服务，collector 为 nil 时使用案件采集智能体。
+func NewService(collector Collector) *Service {
+	if collector == nil {
+		collector = agentCollector{}
+	}
+	return &Service{
+		collector: collector,
+		running:   map[string]context.CancelFunc{},
+	}
+}
+
+// DefaultService 返回进程级采集任务服务。
+func DefaultService() *Service {
+	defaultServiceOnce.Do(func() {
+		defaultService = NewService(nil)
+	})
+	return defaultService
+}
+
+// Start 创建采集任务并在后台执行。
+func (s *Service) Start(userID string, query string, caseCount int) (Job, error) {
+	return s.start(userID, query, caseCount, "", "")
+}
+
+// Retry 以原任务的检索主题与目标数量重新发起一次采集，原任务必须已结束。
+func (s *Service) Retry(jobID string, userID string) (Job, error) {
+	original, err := loadJob(jobID)
+	if err != nil {
+		return Job{}, err
+	}
+	if original.Status == JobStatusRunning {
+		return Job{}, ErrJobRunning
+	}
+	return s.start(userID, original.Query, original.RequestedCount, original.JobID, "")
+}
+
+// Cancel 取消运行中的采集任务；已写入待审核队列的案件保留，等待人工审核。
+func (s *Service) Cancel(jobID string) (Job, error) {
+	entity, err := loadJob(jobID)
+	if err != nil {
+		return Job{}, err
+	}
+	if entity.Status != JobStatusRunning {
+		return Job{}, ErrJobFinished
+	}
+
+	s.mu.Lock()
+	cancel, ok := s.running[entity.JobID]
+	s.mu.Unlock()
+	// 先落终态再取消 ctx，后台协程结束时的条件更新不会覆盖 canceled。
+	finishJob(entity.JobID, JobStatusCanceled, "canceled by administrator")
+	if ok {
+		cancel()
+	}
+	return s.Get(entity.JobID)
+}
+
+// ResumeInterruptedJobs 把进程重启前仍在运行的任务标记为失败，管理员可通过重试重新发起。
+func (s *Service) ResumeInterruptedJobs() {
+	db := jobDB()
+	if db == nil {
+		return
+	}
+	var rows []jobEntity
+	if err := db.Where("status = ?", JobStatusRunning).Find(&rows).Error; err != nil {
+		log.Printf("[case_collection_job] query interrupted jobs failed: %v", err)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, row := range rows {
+		if _, ok := s.running[row.JobID]; ok {
+			continue
+		}
+		log.Printf("[case_collection_job] marking interrupted job failed: job_id=%s created=%d/%d", row.JobID, row.CreatedCount, row.RequestedCount)
+		finishJob(row.JobID, JobStatusFailed, "interrupted by service restart")
+	}
+}
+
+// Get 返回指定采集任务。
+func (s *Service) Get(jobID string) (Job, error) {
+	entity, err := loadJob(jobID)
+	if err != nil {
+		return Job{}, err
+	}
+	return jobFromEntity(entity), nil
+}
+
+// List 按创建时间倒序返回最近的采集任务，status 为空时不过滤。
+func (s *Service) List(status string, limit int) ([]Job, error) {
+	db := jobDB()
+	if db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	query := db.Model(&jobEntity{})
+	if trimmed := strings.TrimSpace(status); trimmed != "" {
+		query = query.Where("status = ?", trimmed)
+	}
+	var rows []jobEntity
+	if err := query.Order("id desc").Limit(limit).Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("list case collection jobs failed: %w", err)
+	}
+	jobs := make([]Job, 0, len(rows))
+	for _, row := range rows {
+		jobs = append(jobs, jobFromEntity(row))
+	}
+	return jobs, nil
+}
+
+// IsJobStatus 判断 status 是否为已知的任务状态。
+func IsJobStatus(status string) bool {
+	switch strings.TrimSpace(status) {
+	case JobStatusRunning, JobStatusCompleted, JobStatusFailed, JobStatusCanceled:
+		return true
+	default:
+		return false
+	}
+}
+
+func (s *Service) start(userID string, query string, caseCount int, retryOf string, campaignID string) (Job, error) {
+	trimmedQuery := strings.TrimSpace(query)
+	if trimmedQuery == "" {
+		return Job{}, fmt.Errorf("%w: query is required", ErrInvalidCollection)
+	}
+	if caseCount <= 0 || caseCount > MaxCaseCount {
+		return Job{}, fmt.Errorf("%w: case_count must be between 1 and %d", ErrInvalidCollection, MaxCaseCount)
+	}
+	db := jobDB()
+	if db == nil {
+		return Job{}, fmt.Errorf("database not initialized")
+	}
+	trimmedUserID := strings.TrimSpace(userID)
+	if trimmedUserID == "" {
+		trimmedUserID = "demo-user"
+	}
+
+	entity := jobEntity{
+		JobID:          newJobID(),
+		Query:          trimmedQuery,
+		RequestedCount: caseCount,
+		Status:         JobStatusRunning,
+		SearchQueries:  encodeList(nil),
+		RecordIDs:      encodeList(nil),
+		CreatedBy:      trimmedUserID,
+		RetryOf:        strings.TrimSpace(retryOf),
+		CampaignID:     strings.TrimSpace(campaignID),
+		StartedAt:      time.Now(),
+	}
+	if err := db.Create(&entity).Error; err != nil {
+		return Job{}, fmt.Errorf("create case collection job failed: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.mu.Lock()
+	s.running[entity.JobID] = cancel
+	s.mu.Unlock()
+	go s.run(ctx, entity)
+	return jobFromEntity(entity), nil
+}
+
+func (s *Service) run(ctx context.Context, job jobEntity) {
+	defer func() {
+		s.mu.Lock()
+		if cancel, ok := s.running[job.JobID]; ok {
+			cancel()
+			delete(s.running, job.JobID)
+		}
+		s.mu.Unlock()
+	}()
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			log.Printf("[case_collection_job] panic recovered: job_id=%s err=%v", job.JobID, recovered)
+			finishJob(job.JobID, JobStatusFailed, fmt.Sprintf("panic: %v", recovered))
+		}
+	}()
+
+	progress := &jobProgress{jobID: job.JobID}
+	err := s.collector.Collect(ctx, job.CreatedBy, job.Query, job.RequestedCount, progress)
+	if err != nil {
+		if ctx.Err() != nil {
+			log.Printf("[case_collection_job] job canceled: job_id=%s created=%d/%d", job.JobID, progress.createdCount(), job.RequestedCount)
+			finishJob(job.JobID, JobStatusCanceled, "canceled by administrator")
+			return
+		}
+		log.Printf("[case_collection_job] job failed: job_id=%s query=%s created=%d/%d err=%v",
+			job.JobID, job.Query, progress.createdCount(), job.RequestedCount, err)
+		finishJob(job.JobID, JobStatusFailed, err.Error())
+		return
+	}
+	log.Printf("[case_collection_job] job completed: job_id=%s query=%s created=%d/%d",
+		job.JobID, job.Query, progress.createdCount(), job.RequestedCount)
+	finishJob(job.JobID, JobStatusCompleted, "")
+}
+
+// jobProgress 把采集智能体的进度事件逐条写回任务记录，便于运行中查询。
+type jobProgress struct {
+	jobID string
+
+	mu            sync.Mutex
+	rounds        int
+	searchQueries []string
+	recordIDs     []string
+	duplicates    int
+}
+
+func (p *jobProgress) RoundStarted(round int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.rounds = round
+	p.saveLocked(map[string]interface{}{"rounds_used": round})
+}
+
+func (p *jobProgress) SearchIssued(query string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.searchQueries = append(p.searchQueries, strings.TrimSpace(query))
+	p.saveLocked(map[string]interface{}{"search_queries": encodeList(p.searchQueries)})
+}
+
+func (p *jobProgress) PendingReviewCreated(recordID string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.recordIDs = append(p.recordIDs, strings.TrimSpace(recordID))
+	p.saveLocked(map[string]interface{}{
+		"record_ids":    encodeList(p.recordIDs),
+		"created_count": len(p.recordIDs),
+	})
+}
+
+func (p *jobProgress) DuplicateRejected(string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.duplicates++
+	p.saveLocked(map[string]interface{}{"duplicate_count": p.duplicates})
+}
+
+func (p *jobProgress) createdCount() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.recordIDs)
+}
+
+func (p *jobProgress) saveLocked(updates map[string]interface{}) {
+	db := jobDB()
+	if db == nil {
+		return
+	}
+	if err := db.Model(&jobEntity{}).Where("job_id = ?", p.jobID).Updates(updates).Error; err != nil {
+		log.Printf("[case_collection_job] save progress failed: job_id=%s err=%v", p.jobID, err)
+	}
+}
+
+// finishJob 把运行中的任务置为终态；已处于终态的任务不会被覆盖。
+func finishJob(jobID string, status string, lastError string) {
+	db := jobDB()
+	if db == nil {
+		return
+	}
+	now := time.Now()
+	updates := map[string]interface{}{
+		"status":      status,
+		"finished_at": &now,
+	}
+	if lastError != "" {
+		updates["last_error"] = lastError
+	}
+	if err := db.Model(&jobEntity{}).Where("job_id = ? AND status = ?", jobID, JobStatusRunning).Updates(updates).Error; err != nil {
+		log.Printf("[case_collection_job] update job status failed: job_id=%s status=%s err=%v", jobID, status, err)
+	}
+}
+
+func loadJob(jobID string) (jobEntity, error) {
+	db := jobDB()
+	if db == nil {
+		return jobEntity{}, fmt.Errorf("database not initialized")
+	}
+	var entity jobEntity
+	result := db.Where("job_id = ?", strings.TrimSpace(jobID)).Limit(1).Find(&entity)
+	if result.Error != nil {
+		return jobEntity{}, fmt.Errorf("query case collection job failed: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return jobEntity{}, ErrJobNotFound
+	}
+	return entity, nil
+}
+
+func jobFromEntity(entity jobEntity) Job {
+	return Job{
+		JobID:          entity.JobID,
+		Query:          entity.Query,
+		RequestedCount: entity.RequestedCount,
+		Status:         entity.Status,
+		RoundsUsed:     entity.RoundsUsed,
+		SearchQueries:  decodeList(entity.SearchQueries),
+		RecordIDs:      decodeList(entity.RecordIDs),
+		CreatedCount:   entity.CreatedCount,
+		DuplicateCount: entity.DuplicateCount,
+		LastError:      entity.LastError,
+		CreatedBy:      entity.CreatedBy,
+		RetryOf:        entity.RetryOf,
+		CampaignID:     entity.CampaignID,
+		StartedAt:      entity.StartedAt,
+		FinishedAt:     entity.FinishedAt,
+		UpdatedAt:      entity.UpdatedAt,
+	}
+}
+
+func encodeList(items []string) string {
+	if items == nil {
+		items = []string{}
+	}
+	encoded, err := json.Marshal(items)
+	if err != nil {
+		return "[]"
+	}
+	return string(encoded)
+}
+
+func decodeList(raw string) []string {
+	items := make([]string, 0)
+	if strings.TrimSpace(raw) == "" {
+		return items
+	}
+	if err := json.Unmarshal([]byte(raw), &items); err != nil {
+		return []string{}
+	}
+	return items
+}
+
+func newJobID() string {
+	return "CASECOL-" + randomIDSuffix()
+}
@@ -0,0 +1,153 @@
+package casecollection
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronSearchHorizon 是查找下一次触发时间的最远范围，超出视为表达式永远不会触发（如 2 月 30 日）。
+const cronSearchHorizon = 5 * 366 * 24 * time.Hour
+
+// CronSchedule 是解析后的 5 段 cron 表达式：分 时 日 月 周。
+// 支持 `*`、数字、`a-b` 区间、`*/n` 与 `a-b/n` 步长以及逗号列表；周取值 0-7，0 与 7 均表示周日。
+// 日与周同时受限时按标准 cron 语义取并集。
+type CronSchedule struct {
+	expr        string
+	minutes     [60]bool
+	hours       [24]bool
+	days        [32]bool
+	months      [13]bool
+	weekdays    [7]bool
+	dayStar     bool
+	weekdayStar bool
+}
+
+// ParseCronSchedule 解析 5 段 cron 表达式。
+func ParseCronSchedule(expr string) (CronSchedule, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return CronSchedule{}, fmt.Errorf("cron expression must have 5 fields (minute hour day month weekday), got %d", len(fields))
+	}
+
+	schedule := CronSchedule{expr: strings.Join(fields, " ")}
+	if err := parseCronField(fields[0], 0, 59, schedule.minutes[:]); err != nil {
+		return CronSchedule{}, fmt.Errorf("cron minute field: %w", err)
+	}
+	if err := parseCronField(fields[1], 0, 23, schedule.hours[:]); err != nil {
+		return CronSchedule{}, fmt.Errorf("cron hour field: %w", err)
+	}
+	if err := parseCronField(fields[2], 1, 31, schedule.days[:]); err != nil {
+		return CronSchedule{}, fmt.Errorf("cron day field: %w", err)
+	}
+	if err := parseCronField(fields[3], 1, 12, schedule.months[:]); err != nil {
+		return CronSchedule{}, fmt.Errorf("cron month field: %w", err)
+	}
+	var weekdays [8]bool
+	if err := parseCronField(fields[4], 0, 7, weekdays[:]); err != nil {
+		return CronSchedule{}, fmt.Errorf("cron weekday field: %w", err)
+	}
+	copy(schedule.weekdays[:], weekdays[:7])
+	if weekdays[7] {
+		schedule.weekdays[0] = true
+	}
+	schedule.dayStar = strings.HasPrefix(fields[2], "*")
+	schedule.weekdayStar = strings.HasPrefix(fields[4], "*")
+
+	if schedule.Next(time.Now()).IsZero() {
+		return CronSchedule{}, fmt.Errorf("cron expression %q never fires", schedule.expr)
+	}
+	return schedule, nil
+}
+
+// String 返回规范化后的表达式。
+func (s CronSchedule) String() string {
+	return s.expr
+}
+
+// Next 返回严格晚于 after 的下一次触发时间（精确到分钟，使用 after 的时区）；找不到时返回零值。
+func (s CronSchedule) Next(after time.Time) time.Time {
+	current := after.Truncate(time.Minute).Add(time.Minute)
+	limit := after.Add(cronSearchHorizon)
+	for !current.After(limit) {
+		if !s.months[int(current.Month())] {
+			current = time.Date(current.Year(), current.Month()+1, 1, 0, 0, 0, 0, current.Location())
+			continue
+		}
+		if !s.matchDay(current) {
+			current = time.Date(current.Year(), current.Month(), current.Day()+1, 0, 0, 0, 0, current.Location())
+			continue
+		}
+		if !s.hours[current.Hour()] {
+			current = time.Date(current.Year(), current.Month(), current.Day(), current.Hour()+1, 0, 0, 0, current.Location())
+			continue
+		}
+		if !s.minutes[current.Minute()] {
+			current = current.Add(time.Minute)
+			continue
+		}
+		return current
+	}
+	return time.Time{}
+}
+
+func (s CronSchedule) matchDay(at time.Time) bool {
+	dayMatched := s.days[at.Day()]
+	weekdayMatched := s.weekdays[int(at.Weekday())]
+	switch {
+	case s.dayStar && s.weekdayStar:
+		return true
+	case s.dayStar:
+		return weekdayMatched
+	case s.weekdayStar:
+		return dayMatched
+	default:
+		return dayMatched || weekdayMatched
+	}
+}
+
+func parseCronField(field string, min int, max int, target []bool) error {
+	for _, part := range strings.Split(field, ",") {
+		if part == "" {
+			return fmt.Errorf("empty list item in %q", field)
+		}
+		rangePart, step := part, 1
+		if index := strings.Index(part, "/"); index >= 0 {
+			value, err := strconv.Atoi(part[index+1:])
+			if err != nil || value <= 0 {
+				return fmt.Errorf("invalid step in %q", part)
+			}
+			rangePart, step = part[:index], value
+		}
+
+		start, end := min, max
+		switch {
+		case rangePart == "*":
+		case strings.Contains(rangePart, "-"):
+			bounds := strings.SplitN(rangePart, "-", 2)
+			low, lowErr := strconv.Atoi(bounds[0])
+			high, highErr := strconv.Atoi(bounds[1])
+			if lowErr != nil || highErr != nil || low > high {
+				return fmt.Errorf("invalid range %q", rangePart)
+			}
+			start, end = low, high
+		default:
+			value, err := strconv.Atoi(rangePart)
+			if err != nil {
+				return fmt.Errorf("invalid value %q", rangePart)
+			}
+			start, end = value, value
+			if strings.Contains(part, "/") {
+				end = max
+			}
+		}
+		if start < min || end > max {
+			return fmt.Errorf("value out of range %d-%d in %q", min, max, part)
+		}
+		for value := start; value <= end; value += step {
+			target[value] = true
+		}
+	}
+	return nil
+}
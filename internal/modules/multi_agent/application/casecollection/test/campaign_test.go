@@ -0,0 +1,221 @@
+package casecollection_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"antifraud/internal/modules/multi_agent/application/casecollection"
+	multi_agent "antifraud/internal/modules/multi_agent/core"
+	"antifraud/internal/modules/multi_agent/test/testsupport"
+)
+
+func TestParseCronSchedule(t *testing.T) {
+	location := time.FixedZone("CST", 8*3600)
+	base := time.Date(2026, 10, 16, 9, 7, 30, 0, location) // 周五
+
+	cases := []struct {
+		expr string
+		want time.Time
+	}{
+		{expr: "*/15 * * * *", want: time.Date(2026, 10, 16, 9, 15, 0, 0, location)},
+		{expr: "0 9 * * *", want: time.Date(2026, 10, 17, 9, 0, 0, 0, location)},
+		{expr: "30 8-10 * * 1-5", want: time.Date(2026, 10, 16, 9, 30, 0, 0, location)},
+		{expr: "0 6 * * 0", want: time.Date(2026, 10, 18, 6, 0, 0, 0, location)},
+		{expr: "0 6 * * 7", want: time.Date(2026, 10, 18, 6, 0, 0, 0, location)},
+		{expr: "0 0 1 1 *", want: time.Date(2027, 1, 1, 0, 0, 0, 0, location)},
+		// 日与周同时受限时取并集：20 号或周一，先到的是 10 月 19 日周一。
+		{expr: "0 0 20 * 1", want: time.Date(2026, 10, 19, 0, 0, 0, 0, location)},
+	}
+	for _, tc := range cases {
+		schedule, err := casecollection.ParseCronSchedule(tc.expr)
+		if err != nil {
+			t.Fatalf("parse %q failed: %v", tc.expr, err)
+		}
+		if got := schedule.Next(base); !got.Equal(tc.want) {
+			t.Fatalf("%q next after %s = %s, want %s", tc.expr, base, got, tc.want)
+		}
+	}
+
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "*/0 * * * *", "5-1 * * * *", "0 0 30 2 *"} {
+		if _, err := casecollection.ParseCronSchedule(expr); err == nil {
+			t.Fatalf("expected %q to be rejected", expr)
+		}
+	}
+}
+
+func TestCampaign_ValidatesInput(t *testing.T) {
+	testsupport.SetupMainDB(t)
+	service := casecollection.NewService(nil)
+
+	valid := casecollection.CampaignInput{Name: "每日冒充客服", QueryTemplates: []string{"冒充客服 {date}"}, Cron: "0 9 * * *", TargetCount: 2, Enabled: true}
+	for name, mutate := range map[string]func(*casecollection.CampaignInput){
+		"empty name":      func(input *casecollection.CampaignInput) { input.Name = " " },
+		"no templates":    func(input *casecollection.CampaignInput) { input.QueryTemplates = []string{" "} },
+		"bad cron":        func(input *casecollection.CampaignInput) { input.Cron = "every day" },
+		"count too large": func(input *casecollection.CampaignInput) { input.TargetCount = casecollection.MaxCaseCount + 1 },
+		"unknown type":    func(input *casecollection.CampaignInput) { input.ScamTypeFocus = "不存在的类型" },
+	} {
+		input := valid
+		mutate(&input)
+		if _, err := service.CreateCampaign("admin", input); !errors.Is(err, casecollection.ErrInvalidCampaign) {
+			t.Fatalf("%s: expected invalid campaign, got %v", name, err)
+		}
+	}
+
+	disabled := valid
+	disabled.Enabled = false
+	campaign, err := service.CreateCampaign("admin", disabled)
+	if err != nil {
+		t.Fatalf("create disabled campaign failed: %v", err)
+	}
+	if campaign.Enabled || campaign.NextRunAt != nil {
+		t.Fatalf("expected disabled campaign without next run, got %+v", campaign)
+	}
+	if runs := service.RunDueCampaigns(time.Now().Add(48 * time.Hour)); len(runs) != 0 {
+		t.Fatalf("expected disabled campaign not to run, got %+v", runs)
+	}
+
+	enabled := true
+	cron := "30 7 * * 1"
+	updated, err := service.UpdateCampaign(campaign.CampaignID, "admin-2", casecollection.CampaignPatch{Enabled: &enabled, Cron: &cron})
+	if err != nil {
+		t.Fatalf("enable campaign failed: %v", err)
+	}
+	if !updated.Enabled || updated.NextRunAt == nil || updated.NextRunAt.Weekday() != time.Monday || updated.UpdatedBy != "admin-2" {
+		t.Fatalf("expected enabled campaign to be scheduled on monday, got %+v", updated)
+	}
+}
+
+func TestCampaign_SchedulesRunsWithOverlapAndDuplicateSkipping(t *testing.T) {
+	testsupport.SetupMainDB(t)
+
+	var (
+		mu      sync.Mutex
+		queries []string
+	)
+	release := make(chan struct{})
+	blocking := false
+	service := casecollection.NewService(collectorFunc(func(ctx context.Context, _ string, query string, _ int, observer multi_agent.CaseCollectionObserver) error {
+		mu.Lock()
+		queries = append(queries, query)
+		block := blocking
+		mu.Unlock()
+		if block {
+			select {
+			case <-release:
+			case <-ctx.Done():
+			}
+		}
+		observer.RoundStarted(1)
+		if strings.Contains(query, "冒充客服") {
+			observer.DuplicateRejected("CASE-EXISTING")
+			observer.DuplicateRejected("CASE-EXISTING")
+			return errors.New("case collection exceeded max tool rounds (4) without pending review case")
+		}
+		observer.PendingReviewCreated("PREV-" + query)
+		return nil
+	}))
+
+	campaign, err := service.CreateCampaign("admin", casecollection.CampaignInput{
+		Name:           "投资理财日常采集",
+		QueryTemplates: []string{"{scam_type} 最新判决 {date}", "冒充客服 退款", "冒充客服 退款"},
+		Cron:           "0 9 * * *",
+		TargetCount:    2,
+		ScamTypeFocus:  "虚假投资理财类",
+		Enabled:        true,
+	})
+	if err != nil {
+		t.Fatalf("create campaign failed: %v", err)
+	}
+	if len(campaign.QueryTemplates) != 2 || campaign.NextRunAt == nil {
+		t.Fatalf("expected deduplicated templates and next run, got %+v", campaign)
+	}
+
+	firstAt := campaign.NextRunAt.Add(time.Minute)
+	runs := service.RunDueCampaigns(firstAt)
+	if len(runs) != 1 || runs[0].Status != casecollection.CampaignRunStatusStarted || runs[0].Trigger != casecollection.CampaignTriggerSchedule {
+		t.Fatalf("expected one scheduled run, got %+v", runs)
+	}
+	first := runs[0]
+	wantQuery := "虚假投资理财类 最新判决 " + campaign.NextRunAt.Format("2006-01-02")
+	if first.Queries[0].Query != wantQuery || first.Queries[1].Query != "虚假投资理财类 冒充客服 退款" {
+		t.Fatalf("unexpected rendered queries: %+v", first.Queries)
+	}
+	for _, query := range first.Queries {
+		if query.JobID == "" {
+			t.Fatalf("expected every query to start a job, got %+v", first.Queries)
+		}
+	}
+	waitForCollectionStatus(t, service, first.Queries[0].JobID, casecollection.JobStatusCompleted)
+	duplicated := waitForCollectionStatus(t, service, first.Queries[1].JobID, casecollection.JobStatusFailed)
+	if duplicated.DuplicateCount != 2 || duplicated.CreatedCount != 0 || duplicated.CampaignID != campaign.CampaignID {
+		t.Fatalf("expected duplicate-only job linked to campaign, got %+v", duplicated)
+	}
+	if runs := service.RunDueCampaigns(firstAt); len(runs) != 0 {
+		t.Fatalf("expected schedule to advance after a run, got %+v", runs)
+	}
+
+	secondAt := firstAt.Add(24 * time.Hour)
+	runs = service.RunDueCampaigns(secondAt)
+	if len(runs) != 1 {
+		t.Fatalf("expected second scheduled run, got %+v", runs)
+	}
+	second := runs[0]
+	if second.Queries[0].JobID == "" || second.Queries[1].JobID != "" || second.Queries[1].SkipReason == "" {
+		t.Fatalf("expected duplicate-only query to be skipped once, got %+v", second.Queries)
+	}
+	waitForCollectionStatus(t, service, second.Queries[0].JobID, casecollection.JobStatusCompleted)
+
+	mu.Lock()
+	blocking = true
+	mu.Unlock()
+	thirdAt := secondAt.Add(24 * time.Hour)
+	runs = service.RunDueCampaigns(thirdAt)
+	if len(runs) != 1 || runs[0].Queries[1].JobID == "" {
+		t.Fatalf("expected skipped query to run again on the following run, got %+v", runs)
+	}
+	third := runs[0]
+
+	overlap := service.RunDueCampaigns(thirdAt.Add(24 * time.Hour))
+	if len(overlap) != 1 || overlap[0].Status != casecollection.CampaignRunStatusSkipped || len(overlap[0].Queries) != 0 {
+		t.Fatalf("expected overlapping run to be skipped, got %+v", overlap)
+	}
+	if _, err := service.RunCampaignNow(campaign.CampaignID, "admin"); !errors.Is(err, casecollection.ErrCampaignRunning) {
+		t.Fatalf("expected manual run to be rejected while jobs are running, got %v", err)
+	}
+	close(release)
+	waitForCollectionStatus(t, service, third.Queries[0].JobID, casecollection.JobStatusCompleted)
+	waitForCollectionStatus(t, service, third.Queries[1].JobID, casecollection.JobStatusFailed)
+
+	history, err := service.ListCampaignRuns(campaign.CampaignID, 0)
+	if err != nil {
+		t.Fatalf("list campaign runs failed: %v", err)
+	}
+	if len(history) != 4 || history[0].RunID != overlap[0].RunID || history[3].RunID != first.RunID {
+		t.Fatalf("unexpected campaign history: %+v", history)
+	}
+	if history[3].Queries[0].Job == nil || history[3].Queries[0].Job.Status != casecollection.JobStatusCompleted {
+		t.Fatalf("expected history to include job snapshots, got %+v", history[3].Queries)
+	}
+
+	mu.Lock()
+	executed := len(queries)
+	mu.Unlock()
+	if executed != 5 {
+		t.Fatalf("expected 5 collector invocations, got %d: %v", executed, queries)
+	}
+
+	if err := service.DeleteCampaign(campaign.CampaignID); err != nil {
+		t.Fatalf("delete campaign failed: %v", err)
+	}
+	if _, err := service.GetCampaign(campaign.CampaignID); !errors.Is(err, casecollection.ErrCampaignNotFound) {
+		t.Fatalf("expected campaign to be deleted, got %v", err)
+	}
+	if job, err := service.Get(first.Queries[0].JobID); err != nil || job.CampaignID != campaign.CampaignID {
+		t.Fatalf("expected campaign jobs to be kept, got %+v err=%v", job, err)
+	}
+}
@@ -0,0 +1,144 @@
+package casecollection_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"antifraud/internal/modules/multi_agent/application/casecollection"
+	multi_agent "antifraud/internal/modules/multi_agent/core"
+	"antifraud/internal/modules/multi_agent/test/testsupport"
+	"antifraud/internal/platform/database"
+)
+
+type collectorFunc func(ctx context.Context, userID string, query string, caseCount int, observer multi_agent.CaseCollectionObserver) error
+
+func (f collectorFunc) Collect(ctx context.Context, userID string, query string, caseCount int, observer multi_agent.CaseCollectionObserver) error {
+	return f(ctx, userID, query, caseCount, observer)
+}
+
+func waitForCollectionStatus(t *testing.T, service *casecollection.Service, jobID string, status string) casecollection.Job {
+	return testsupport.WaitForStatus(t,
+		func() (casecollection.Job, error) { return service.Get(jobID) },
+		func(job casecollection.Job) string { return job.Status },
+		status)
+}
+
+func TestCollectionJob_PersistsProgressAndRetries(t *testing.T) {
+	testsupport.SetupMainDB(t)
+
+	attempts := 0
+	service := casecollection.NewService(collectorFunc(func(_ context.Context, userID string, query string, caseCount int, observer multi_agent.CaseCollectionObserver) error {
+		attempts++
+		observer.RoundStarted(1)
+		observer.SearchIssued(query + " 最新案例")
+		observer.RoundStarted(2)
+		observer.PendingReviewCreated("PREV-001")
+		if attempts == 1 {
+			return errors.New("case collection exceeded max tool rounds (4), created 1/2 pending review cases")
+		}
+		observer.PendingReviewCreated("PREV-002")
+		return nil
+	}))
+
+	if _, err := service.Start("admin", "  ", 2); !errors.Is(err, casecollection.ErrInvalidCollection) {
+		t.Fatalf("expected invalid request for empty query, got %v", err)
+	}
+
+	job, err := service.Start("admin", "冒充客服诈骗", 2)
+	if err != nil {
+		t.Fatalf("start job failed: %v", err)
+	}
+	failed := waitForCollectionStatus(t, service, job.JobID, casecollection.JobStatusFailed)
+	if failed.RoundsUsed != 2 || failed.CreatedCount != 1 || len(failed.RecordIDs) != 1 || failed.RecordIDs[0] != "PREV-001" {
+		t.Fatalf("expected partial progress to be persisted, got %+v", failed)
+	}
+	if len(failed.SearchQueries) != 1 || failed.SearchQueries[0] != "冒充客服诈骗 最新案例" || failed.LastError == "" || failed.FinishedAt == nil {
+		t.Fatalf("unexpected failed job snapshot: %+v", failed)
+	}
+	if _, err := service.Cancel(job.JobID); !errors.Is(err, casecollection.ErrJobFinished) {
+		t.Fatalf("expected cancel of finished job to fail, got %v", err)
+	}
+
+	retried, err := service.Retry(job.JobID, "admin-2")
+	if err != nil {
+		t.Fatalf("retry failed: %v", err)
+	}
+	if retried.RetryOf != job.JobID || retried.Query != job.Query || retried.RequestedCount != 2 || retried.CreatedBy != "admin-2" {
+		t.Fatalf("unexpected retried job: %+v", retried)
+	}
+	completed := waitForCollectionStatus(t, service, retried.JobID, casecollection.JobStatusCompleted)
+	if completed.CreatedCount != 2 || completed.LastError != "" {
+		t.Fatalf("unexpected completed job: %+v", completed)
+	}
+
+	jobs, err := service.List(casecollection.JobStatusFailed, 0)
+	if err != nil || len(jobs) != 1 || jobs[0].JobID != job.JobID {
+		t.Fatalf("expected status filter to return the failed job, got %+v err=%v", jobs, err)
+	}
+}
+
+func TestCollectionJob_CancelStopsRunningCollection(t *testing.T) {
+	testsupport.SetupMainDB(t)
+
+	started := make(chan struct{})
+	service := casecollection.NewService(collectorFunc(func(ctx context.Context, _ string, _ string, _ int, observer multi_agent.CaseCollectionObserver) error {
+		observer.RoundStarted(1)
+		observer.PendingReviewCreated("PREV-KEEP")
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	}))
+
+	job, err := service.Start("admin", "虚假投资理财", 3)
+	if err != nil {
+		t.Fatalf("start job failed: %v", err)
+	}
+	<-started
+	if _, err := service.Retry(job.JobID, "admin"); !errors.Is(err, casecollection.ErrJobRunning) {
+		t.Fatalf("expected retry of running job to fail, got %v", err)
+	}
+
+	canceled, err := service.Cancel(job.JobID)
+	if err != nil {
+		t.Fatalf("cancel failed: %v", err)
+	}
+	if canceled.Status != casecollection.JobStatusCanceled || canceled.CreatedCount != 1 {
+		t.Fatalf("unexpected canceled job: %+v", canceled)
+	}
+	// 后台协程退出后不应覆盖 canceled 终态。
+	time.Sleep(50 * time.Millisecond)
+	final := waitForCollectionStatus(t, service, job.JobID, casecollection.JobStatusCanceled)
+	if len(final.RecordIDs) != 1 || final.RecordIDs[0] != "PREV-KEEP" {
+		t.Fatalf("expected produced record ids to be kept, got %+v", final)
+	}
+}
+
+func TestCollectionJob_ResumeMarksInterruptedJobsFailed(t *testing.T) {
+	testsupport.SetupMainDB(t)
+
+	// 直接写入 running 记录模拟进程重启前遗留的任务：当前进程内没有对应的后台协程。
+	now := time.Now()
+	if err := database.DB.Table("case_collection_jobs").Create(map[string]interface{}{
+		"job_id":          "CASECOL-ORPHAN",
+		"query":           "刷单返利",
+		"requested_count": 1,
+		"status":          casecollection.JobStatusRunning,
+		"started_at":      now,
+		"created_at":      now,
+		"updated_at":      now,
+	}).Error; err != nil {
+		t.Fatalf("seed running job failed: %v", err)
+	}
+
+	service := casecollection.NewService(nil)
+	service.ResumeInterruptedJobs()
+	interrupted, err := service.Get("CASECOL-ORPHAN")
+	if err != nil {
+		t.Fatalf("get job failed: %v", err)
+	}
+	if interrupted.Status != casecollection.JobStatusFailed || interrupted.LastError != "interrupted by service restart" || interrupted.FinishedAt == nil {
+		t.Fatalf("expected interrupted job to be marked failed, got %+v", interrupted)
+	}
+}
@@ -0,0 +1,156 @@
+package casetransfer
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"antifraud/internal/modules/multi_agent/adapters/outbound/case_library"
+)
+
+// ExportOptions 控制导出内容。
+type ExportOptions struct {
+	Format         string
+	IncludeVectors bool
+}
+
+// exportedCase 是导出文件中的一条案件：前半部分与 CreateHistoricalCaseRequest 一致，可直接导回；
+// case_id 等元数据仅供核对，导入时忽略；向量在模型与目标环境当前固定模型一致时复用，否则重新生成。
+type exportedCase struct {
+	caseRow
+	CaseID             string    `json:"case_id"`
+	CreatedBy          string    `json:"created_by"`
+	CreatedAt          string    `json:"created_at"`
+	EmbeddingModel     string    `json:"embedding_model,omitempty"`
+	EmbeddingDimension int       `json:"embedding_dimension,omitempty"`
+	EmbeddingVector    []float64 `json:"embedding_vector,omitempty"`
+}
+
+// embedding 返回导入时可交给案件库复用的向量；维度与向量长度不一致时视为损坏，返回 nil。
+func (c exportedCase) embedding() *case_library.CaseEmbedding {
+	modelName := strings.TrimSpace(c.EmbeddingModel)
+	if modelName == "" || len(c.EmbeddingVector) == 0 {
+		return nil
+	}
+	if c.EmbeddingDimension != 0 && c.EmbeddingDimension != len(c.EmbeddingVector) {
+		return nil
+	}
+	return &case_library.CaseEmbedding{Model: modelName, Vector: c.EmbeddingVector}
+}
+
+// csvExportColumns 在导入列之后追加元数据列，向量列仅在 IncludeVectors 时输出。
+var csvExportColumns = append(append([]string{}, csvColumns...), "case_id", "created_by", "created_at")
+
+var csvVectorColumns = []string{"embedding_model", "embedding_dimension", "embedding_vector"}
+
+// Export 以流式方式把整个历史案件库写入 w，返回写出的案件数。
+// 逐条读取数据库并立即写出，不会把全库加载进内存；ctx 取消（如客户端断开）时提前结束。
+func (s *Service) Export(ctx context.Context, w io.Writer, options ExportOptions) (int, error) {
+	format, err := NormalizeFormat(options.Format, "")
+	if err != nil {
+		return 0, err
+	}
+
+	var write func(exportedCase) error
+	var flush func() error
+	switch format {
+	case FormatCSV:
+		writer := csv.NewWriter(w)
+		header := append([]string{}, csvExportColumns...)
+		if options.IncludeVectors {
+			header = append(header, csvVectorColumns...)
+		}
+		if err := writer.Write(header); err != nil {
+			return 0, fmt.Errorf("write csv header failed: %w", err)
+		}
+		write = func(item exportedCase) error {
+			return writer.Write(csvExportRecord(item, options.IncludeVectors))
+		}
+		flush = func() error {
+			writer.Flush()
+			return writer.Error()
+		}
+	default:
+		encoder := json.NewEncoder(w)
+		encoder.SetEscapeHTML(false)
+		write = func(item exportedCase) error {
+			return encoder.Encode(item)
+		}
+		flush = func() error { return nil }
+	}
+
+	count := 0
+	err = s.source.StreamAll(func(record case_library.HistoricalCaseRecord) error {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err := write(toExportedCase(record, options.IncludeVectors)); err != nil {
+			return fmt.Errorf("write exported case failed: %w", err)
+		}
+		count++
+		return nil
+	})
+	if flushErr := flush(); err == nil && flushErr != nil {
+		err = fmt.Errorf("flush export failed: %w", flushErr)
+	}
+	return count, err
+}
+
+func toExportedCase(record case_library.HistoricalCaseRecord, includeVectors bool) exportedCase {
+	item := exportedCase{
+		caseRow: caseRow{
+			Title:           record.Title,
+			TargetGroup:     record.TargetGroup,
+			RiskLevel:       record.RiskLevel,
+			ScamType:        record.ScamType,
+			CaseDescription: record.CaseDescription,
+			TypicalScripts:  append([]string{}, record.TypicalScripts...),
+			Keywords:        append([]string{}, record.Keywords...),
+			ViolatedLaw:     record.ViolatedLaw,
+			Suggestion:      record.Suggestion,
+			Citations:       toCitationRows(record.Citations),
+		},
+		CaseID:    strings.TrimSpace(record.CaseID),
+		CreatedBy: strings.TrimSpace(record.CreatedBy),
+		CreatedAt: record.CreatedAt.Format(time.RFC3339),
+	}
+	if includeVectors {
+		item.EmbeddingModel = strings.TrimSpace(record.EmbeddingModel)
+		item.EmbeddingDimension = record.EmbeddingDimension
+		item.EmbeddingVector = append([]float64{}, record.EmbeddingVector...)
+	}
+	return item
+}
+
+func csvExportRecord(item exportedCase, includeVectors bool) []string {
+	record := []string{
+		item.Title,
+		item.TargetGroup,
+		item.RiskLevel,
+		item.ScamType,
+		item.CaseDescription,
+		formatCSVList(item.TypicalScripts),
+		formatCSVList(item.Keywords),
+		item.ViolatedLaw,
+		item.Suggestion,
+		formatCSVCitations(item.Citations),
+		item.CaseID,
+		item.CreatedBy,
+		item.CreatedAt,
+	}
+	if includeVectors {
+		vector := ""
+		if len(item.EmbeddingVector) > 0 {
+			if encoded, err := json.Marshal(item.EmbeddingVector); err == nil {
+				vector = string(encoded)
+			}
+		}
+		record = append(record, item.EmbeddingModel, strconv.Itoa(item.EmbeddingDimension), vector)
+	}
+	return record
+}
@@ -0,0 +1,538 @@
+package casetransfer
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"antifraud/internal/modules/multi_agent/adapters/outbound/case_library"
+	"antifraud/internal/platform/database"
+
+	"gorm.io/gorm"
+)
+
+const (
+	JobStatusRunning   = "running"
+	JobStatusCompleted = "completed"
+	JobStatusFailed    = "failed"
+
+	RowStatusInvalid   = "invalid"
+	RowStatusDuplicate = "duplicate"
+	RowStatusFailed    = "failed"
+
+	// MaxImportFileBytes 是单个导入文件的大小上限。
+	MaxImportFileBytes = 20 << 20
+
+	defaultImportBatchSize = 20
+	maxImportBatchSize     = 200
+)
+
+var (
+	ErrImportRunning     = errors.New("a historical case import is already running")
+	ErrInvalidImportFile = errors.New("invalid historical case import file")
+	ErrJobNotFound       = errors.New("historical case import job not found")
+)
+
+// jobDB 返回导入任务表所在的主业务库。
+var jobDB = func() *gorm.DB { return database.DB }
+
+type jobEntity struct {
+	ID            uint       `gorm:"primaryKey;autoIncrement"`
+	JobID         string     `gorm:"size:64;uniqueIndex;not null"`
+	Format        string     `gorm:"size:16;not null"`
+	FileName      string     `gorm:"size:255"`
+	Status        string     `gorm:"size:32;index;not null"`
+	BatchSize     int        `gorm:"not null"`
+	TotalRows     int        `gorm:"not null;default:0"`
+	ProcessedRows int        `gorm:"not null;default:0"`
+	ImportedRows  int        `gorm:"not null;default:0"`
+	InvalidRows   int        `gorm:"not null;default:0"`
+	DuplicateRows int        `gorm:"not null;default:0"`
+	FailedRows    int        `gorm:"not null;default:0"`
+	Payload       []byte     `gorm:"type:blob"`
+	LastError     string     `gorm:"type:text"`
+	CreatedBy     string     `gorm:"size:64"`
+	StartedAt     time.Time  `gorm:"not null"`
+	FinishedAt    *time.Time `gorm:""`
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+func (jobEntity) TableName() string {
+	return "historical_case_import_jobs"
+}
+
+type rowErrorEntity struct {
+	ID              uint    `gorm:"primaryKey;autoIncrement"`
+	JobID           string  `gorm:"size:64;index:idx_case_import_errors_job_row,priority:1;not null"`
+	RowNumber       int     `gorm:"index:idx_case_import_errors_job_row,priority:2;not null"`
+	Status          string  `gorm:"size:32;not null"`
+	Title           string  `gorm:"size:255"`
+	Error           string  `gorm:"type:text"`
+	DuplicateCaseID string  `gorm:"size:64"`
+	Similarity      float64 `gorm:"not null;default:0"`
+	CreatedAt       time.Time
+}
+
+func (rowErrorEntity) TableName() string {
+	return "historical_case_import_errors"
+}
+
+func init() {
+	database.RegisterMainDBSchemaInitializer("historical_case_import", initJobSchema)
+}
+
+func initJobSchema(db *gorm.DB) error {
+	if db == nil {
+		return fmt.Errorf("historical case import schema db is nil")
+	}
+	return db.AutoMigrate(&jobEntity{}, &rowErrorEntity{})
+}
+
+// Job 是一次历史案件批量导入任务的进度快照。
+type Job struct {
+	JobID         string
+	Format        string
+	FileName      string
+	Status        string
+	BatchSize     int
+	TotalRows     int
+	ProcessedRows int
+	ImportedRows  int
+	InvalidRows   int
+	DuplicateRows int
+	FailedRows    int
+	LastError     string
+	CreatedBy     string
+	StartedAt     time.Time
+	FinishedAt    *time.Time
+	UpdatedAt     time.Time
+}
+
+// RowError 是错误报告中的一行：校验失败、与库内案件重复或写入失败的导入数据。
+type RowError struct {
+	RowNumber       int
+	Status          string
+	Title           string
+	Error           string
+	DuplicateCaseID string
+	Similarity      float64
+}
+
+// CaseWriter 是导入任务写入历史案件库的端口。
+type CaseWriter interface {
+	// CreateBatch 批量校验、查重并入库，返回值与 inputs 一一对应。
+	CreateBatch(ctx context.Context, userID string, inputs []case_library.CreateHistoricalCaseInput) []case_library.HistoricalCaseBatchResult
+}
+
+// CaseSource 是导出任务遍历历史案件库的端口。
+type CaseSource interface {
+	StreamAll(callback func(case_library.HistoricalCaseRecord) error) error
+}
+
+// Service 负责历史案件库的批量导入与流式导出。
+// 导入在后台按批执行，每批复用单条上传的校验与查重逻辑；同一时刻只允许一个导入任务运行，
+// 避免并发导入之间互相漏判重复。
+type Service struct {
+	writer CaseWriter
+	source CaseSource
+
+	mu      sync.Mutex
+	running map[string]struct{}
+}
+
+var (
+	defaultServiceOnce sync.Once
+	defaultService     *Service
+)
+
+// NewService 创建导入导出服务，参数为 nil 时使用默认实现。
+func NewService(writer CaseWriter, source CaseSource) *Service {
+	if writer == nil {
+		writer = caseLibraryWriter{}
+	}
+	if source == nil {
+		source = caseLibrarySource{}
+	}
+	return &Service{
+		writer:  writer,
+		source:  source,
+		running: map[string]struct{}{},
+	}
+}
+
+// DefaultService 返回进程级导入导出服务。
+func DefaultService() *Service {
+	defaultServiceOnce.Do(func() {
+		defaultService = NewService(nil, nil)
+	})
+	return defaultService
+}
+
+// StartImport 校验导入文件并在后台启动导入任务。
+// 文件整体不可解析或没有任何数据行时同步返回 ErrInvalidImportFile；单行问题记入错误报告，不影响其余行。
+func (s *Service) StartImport(userID string, fileName string, format string, payload []byte, batchSize int) (Job, error) {
+	db := jobDB()
+	if db == nil {
+		return Job{}, fmt.Errorf("database not initialized")
+	}
+	normalizedFormat, err := NormalizeFormat(format, fileName)
+	if err != nil {
+		return Job{}, err
+	}
+	if len(payload) > MaxImportFileBytes {
+		return Job{}, fmt.Errorf("%w: file exceeds %d bytes", ErrInvalidImportFile, MaxImportFileBytes)
+	}
+	rows, err := parseRows(normalizedFormat, payload)
+	if err != nil {
+		return Job{}, fmt.Errorf("%w: %v", ErrInvalidImportFile, err)
+	}
+	if len(rows) == 0 {
+		return Job{}, fmt.Errorf("%w: no data rows", ErrInvalidImportFile)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var running int64
+	if err := db.Model(&jobEntity{}).Where("status = ?", JobStatusRunning).Count(&running).Error; err != nil {
+		return Job{}, fmt.Errorf("query running historical case import failed: %w", err)
+	}
+	if running > 0 {
+		return Job{}, ErrImportRunning
+	}
+
+	entity := jobEntity{
+		JobID:     newJobID(),
+		Format:    normalizedFormat,
+		FileName:  strings.TrimSpace(fileName),
+		Status:    JobStatusRunning,
+		BatchSize: normalizeBatchSize(batchSize),
+		TotalRows: len(rows),
+		Payload:   append([]byte{}, payload...),
+		CreatedBy: strings.TrimSpace(userID),
+		StartedAt: time.Now(),
+	}
+	if err := db.Create(&entity).Error; err != nil {
+		return Job{}, fmt.Errorf("create historical case import job failed: %w", err)
+	}
+	s.launchLocked(entity, rows)
+	return jobFromEntity(entity), nil
+}
+
+// ResumeInterruptedJobs 恢复进程重启前仍在运行的导入任务，从最后一个已提交批次之后继续。
+func (s *Service) ResumeInterruptedJobs() {
+	db := jobDB()
+	if db == nil {
+		return
+	}
+	var rows []jobEntity
+	if err := db.Where("status = ?", JobStatusRunning).Order("id asc").Find(&rows).Error; err != nil {
+		log.Printf("[case_import] query interrupted jobs failed: %v", err)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for index, row := range rows {
+		if _, ok := s.running[row.JobID]; ok {
+			continue
+		}
+		if index > 0 {
+			finishJob(row.JobID, JobStatusFailed, "superseded by an earlier running import")
+			continue
+		}
+		parsed, err := parseRows(row.Format, row.Payload)
+		if err != nil {
+			finishJob(row.JobID, JobStatusFailed, "reparse import file failed: "+err.Error())
+			continue
+		}
+		log.Printf("[case_import] resuming job: job_id=%s processed=%d/%d", row.JobID, row.ProcessedRows, row.TotalRows)
+		s.launchLocked(row, parsed)
+	}
+}
+
+// Get 返回指定导入任务。
+func (s *Service) Get(jobID string) (Job, error) {
+	entity, err := loadJob(jobID)
+	if err != nil {
+		return Job{}, err
+	}
+	return jobFromEntity(entity), nil
+}
+
+// List 按创建时间倒序返回最近的导入任务。
+func (s *Service) List(limit int) ([]Job, error) {
+	db := jobDB()
+	if db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	var rows []jobEntity
+	if err := db.Omit("payload").Order("id desc").Limit(limit).Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("list historical case import jobs failed: %w", err)
+	}
+	jobs := make([]Job, 0, len(rows))
+	for _, row := range rows {
+		jobs = append(jobs, jobFromEntity(row))
+	}
+	return jobs, nil
+}
+
+// ListRowErrors 返回导入任务的错误报告，按文件行号升序。
+func (s *Service) ListRowErrors(jobID string) ([]RowError, error) {
+	entity, err := loadJob(jobID)
+	if err != nil {
+		return nil, err
+	}
+	var rows []rowErrorEntity
+	if err := jobDB().Where("job_id = ?", entity.JobID).Order("row_number asc").Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("list historical case import errors failed: %w", err)
+	}
+	items := make([]RowError, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, RowError{
+			RowNumber:       row.RowNumber,
+			Status:          row.Status,
+			Title:           row.Title,
+			Error:           row.Error,
+			DuplicateCaseID: row.DuplicateCaseID,
+			Similarity:      row.Similarity,
+		})
+	}
+	return items, nil
+}
+
+func (s *Service) launchLocked(entity jobEntity, rows []parsedRow) {
+	s.running[entity.JobID] = struct{}{}
+	go s.run(entity, rows)
+}
+
+func (s *Service) run(job jobEntity, rows []parsedRow) {
+	defer func() {
+		s.mu.Lock()
+		delete(s.running, job.JobID)
+		s.mu.Unlock()
+	}()
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			log.Printf("[case_import] panic recovered: job_id=%s err=%v", job.JobID, recovered)
+			finishJob(job.JobID, JobStatusFailed, fmt.Sprintf("panic: %v", recovered))
+		}
+	}()
+
+	if err := s.importRows(&job, rows); err != nil {
+		log.Printf("[case_import] job failed: job_id=%s err=%v", job.JobID, err)
+		finishJob(job.JobID, JobStatusFailed, err.Error())
+		return
+	}
+	log.Printf("[case_import] job completed: job_id=%s imported=%d invalid=%d duplicate=%d failed=%d",
+		job.JobID, job.ImportedRows, job.InvalidRows, job.DuplicateRows, job.FailedRows)
+	finishJob(job.JobID, JobStatusCompleted, "")
+}
+
+// importRows 从 ProcessedRows 处继续按批导入；每批结束后提交进度与错误行，进程重启时从下一批继续。
+func (s *Service) importRows(job *jobEntity, rows []parsedRow) error {
+	ctx := context.Background()
+	for job.ProcessedRows < len(rows) {
+		end := job.ProcessedRows + job.BatchSize
+		if end > len(rows) {
+			end = len(rows)
+		}
+		batch := rows[job.ProcessedRows:end]
+
+		issues := make([]rowErrorEntity, 0)
+		inputs := make([]case_library.CreateHistoricalCaseInput, 0, len(batch))
+		positions := make([]int, 0, len(batch))
+		for index, row := range batch {
+			if row.ParseErr != nil {
+				issues = append(issues, newRowError(job.JobID, row, RowStatusInvalid, row.ParseErr.Error()))
+				continue
+			}
+			inputs = append(inputs, row.Input)
+			positions = append(positions, index)
+		}
+		if len(inputs) > 0 {
+			results := s.writer.CreateBatch(ctx, job.CreatedBy, inputs)
+			for offset, result := range results {
+				row := batch[positions[offset]]
+				if result.Err == nil {
+					job.ImportedRows++
+					continue
+				}
+				issues = append(issues, classifyRowError(job.JobID, row, result.Err))
+			}
+		}
+		for _, issue := range issues {
+			switch issue.Status {
+			case RowStatusInvalid:
+				job.InvalidRows++
+			case RowStatusDuplicate:
+				job.DuplicateRows++
+			default:
+				job.FailedRows++
+				job.LastError = issue.Error
+			}
+		}
+		job.ProcessedRows = end
+		if err := saveProgress(job, issues); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func newRowError(jobID string, row parsedRow, status string, message string) rowErrorEntity {
+	return rowErrorEntity{
+		JobID:     jobID,
+		RowNumber: row.RowNumber,
+		Status:    status,
+		Title:     truncateRunes(strings.TrimSpace(row.Input.Title), 255),
+		Error:     message,
+	}
+}
+
+// classifyRowError 把单条写入错误归类为校验失败、重复或写入失败。
+func classifyRowError(jobID string, row parsedRow, err error) rowErrorEntity {
+	if case_library.IsValidationError(err) {
+		return newRowError(jobID, row, RowStatusInvalid, err.Error())
+	}
+	if duplicateErr, ok := case_library.AsDuplicateHistoricalCaseError(err); ok && duplicateErr != nil {
+		issue := newRowError(jobID, row, RowStatusDuplicate, err.Error())
+		issue.DuplicateCaseID = strings.TrimSpace(duplicateErr.TopMatch.CaseID)
+		issue.Similarity = duplicateErr.TopMatch.Similarity
+		return issue
+	}
+	return newRowError(jobID, row, RowStatusFailed, err.Error())
+}
+
+// saveProgress 在同一事务中提交批次进度与错误行。
+func saveProgress(job *jobEntity, issues []rowErrorEntity) error {
+	db := jobDB()
+	if db == nil {
+		return fmt.Errorf("database not initialized")
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		if len(issues) > 0 {
+			if err := tx.Create(&issues).Error; err != nil {
+				return fmt.Errorf("save historical case import errors failed: %w", err)
+			}
+		}
+		if err := tx.Model(&jobEntity{}).
+			Where("job_id = ?", job.JobID).
+			Updates(map[string]interface{}{
+				"processed_rows": job.ProcessedRows,
+				"imported_rows":  job.ImportedRows,
+				"invalid_rows":   job.InvalidRows,
+				"duplicate_rows": job.DuplicateRows,
+				"failed_rows":    job.FailedRows,
+				"last_error":     job.LastError,
+			}).Error; err != nil {
+			return fmt.Errorf("save historical case import progress failed: %w", err)
+		}
+		return nil
+	})
+}
+
+// finishJob 把运行中的任务置为终态，并释放已不再需要的原始文件内容。
+func finishJob(jobID string, status string, lastError string) {
+	db := jobDB()
+	if db == nil {
+		return
+	}
+	now := time.Now()
+	updates := map[string]interface{}{
+		"status":      status,
+		"finished_at": &now,
+		"payload":     []byte{},
+	}
+	if lastError != "" {
+		updates["last_error"] = lastError
+	}
+	if err := db.Model(&jobEntity{}).Where("job_id = ? AND status = ?", jobID, JobStatusRunning).Updates(updates).Error; err != nil {
+		log.Printf("[case_import] update job status failed: job_id=%s status=%s err=%v", jobID, status, err)
+	}
+}
+
+func loadJob(jobID string) (jobEntity, error) {
+	db := jobDB()
+	if db == nil {
+		return jobEntity{}, fmt.Errorf("database not initialized")
+	}
+	var entity jobEntity
+	result := db.Omit("payload").Where("job_id = ?", strings.TrimSpace(jobID)).Limit(1).Find(&entity)
+	if result.Error != nil {
+		return jobEntity{}, fmt.Errorf("query historical case import job failed: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return jobEntity{}, ErrJobNotFound
+	}
+	return entity, nil
+}
+
+func jobFromEntity(entity jobEntity) Job {
+	return Job{
+		JobID:         entity.JobID,
+		Format:        entity.Format,
+		FileName:      entity.FileName,
+		Status:        entity.Status,
+		BatchSize:     entity.BatchSize,
+		TotalRows:     entity.TotalRows,
+		ProcessedRows: entity.ProcessedRows,
+		ImportedRows:  entity.ImportedRows,
+		InvalidRows:   entity.InvalidRows,
+		DuplicateRows: entity.DuplicateRows,
+		FailedRows:    entity.FailedRows,
+		LastError:     entity.LastError,
+		CreatedBy:     entity.CreatedBy,
+		StartedAt:     entity.StartedAt,
+		FinishedAt:    entity.FinishedAt,
+		UpdatedAt:     entity.UpdatedAt,
+	}
+}
+
+func normalizeBatchSize(batchSize int) int {
+	if batchSize <= 0 {
+		return defaultImportBatchSize
+	}
+	if batchSize > maxImportBatchSize {
+		return maxImportBatchSize
+	}
+	return batchSize
+}
+
+func truncateRunes(value string, limit int) string {
+	runes := []rune(value)
+	if len(runes) <= limit {
+		return value
+	}
+	return string(runes[:limit])
+}
+
+func newJobID() string {
+	buf := make([]byte, 6)
+	if _, err := rand.Read(buf); err != nil {
+		return fmt.Sprintf("CASEIMP-%d", time.Now().UnixNano())
+	}
+	return "CASEIMP-" + strings.ToUpper(hex.EncodeToString(buf))
+}
+
+type caseLibraryWriter struct{}
+
+func (caseLibraryWriter) CreateBatch(ctx context.Context, userID string, inputs []case_library.CreateHistoricalCaseInput) []case_library.HistoricalCaseBatchResult {
+	return case_library.CreateHistoricalCases(ctx, userID, inputs)
+}
+
+type caseLibrarySource struct{}
+
+func (caseLibrarySource) StreamAll(callback func(case_library.HistoricalCaseRecord) error) error {
+	return case_library.StreamAllHistoricalCases(callback)
+}
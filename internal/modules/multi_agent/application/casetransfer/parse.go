@@ -0,0 +1,358 @@
+package casetransfer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"antifraud/internal/modules/multi_agent/adapters/outbound/case_library"
+)
+
+const (
+	FormatJSONL = "jsonl"
+	FormatCSV   = "csv"
+
+	// csvListSeparator 是 CSV 中列表字段的简写分隔符；单元格以 "[" 开头时按 JSON 数组解析。
+	csvListSeparator = "|"
+)
+
+var ErrUnsupportedFormat = errors.New("unsupported case transfer format")
+
+// csvColumns 是导入导出共用的 CSV 列，顺序即导出时的列顺序。
+var csvColumns = []string{
+	"title",
+	"target_group",
+	"risk_level",
+	"scam_type",
+	"case_description",
+	"typical_scripts",
+	"keywords",
+	"violated_law",
+	"suggestion",
+	"citations",
+}
+
+// caseRow 与 CreateHistoricalCaseRequest 字段一致，导出文件中的其余字段（case_id 等）导入时忽略。
+type caseRow struct {
+	Title           string            `json:"title"`
+	TargetGroup     string            `json:"target_group"`
+	RiskLevel       string            `json:"risk_level"`
+	ScamType        string            `json:"scam_type"`
+	CaseDescription string            `json:"case_description"`
+	TypicalScripts  []string          `json:"typical_scripts"`
+	Keywords        []string          `json:"keywords"`
+	ViolatedLaw     string            `json:"violated_law"`
+	Suggestion      string            `json:"suggestion"`
+	Citations       []caseCitationRow `json:"citations,omitempty"`
+}
+
+// caseCitationRow 是来源引用在导入导出文件中的格式，retrieved_at 为 RFC3339 时间。
+// 链接合法性与去重在入库时由案件库统一校验。
+type caseCitationRow struct {
+	URL           string `json:"url"`
+	Title         string `json:"title,omitempty"`
+	PublishedDate string `json:"published_date,omitempty"`
+	RetrievedAt   string `json:"retrieved_at,omitempty"`
+}
+
+func (r caseRow) toInput() (case_library.CreateHistoricalCaseInput, error) {
+	citations, err := parseCitationRows(r.Citations)
+	if err != nil {
+		return case_library.CreateHistoricalCaseInput{}, err
+	}
+	return case_library.CreateHistoricalCaseInput{
+		Title:           r.Title,
+		TargetGroup:     r.TargetGroup,
+		RiskLevel:       r.RiskLevel,
+		ScamType:        r.ScamType,
+		CaseDescription: r.CaseDescription,
+		TypicalScripts:  r.TypicalScripts,
+		Keywords:        r.Keywords,
+		ViolatedLaw:     r.ViolatedLaw,
+		Suggestion:      r.Suggestion,
+		Citations:       citations,
+	}, nil
+}
+
+func parseCitationRows(rows []caseCitationRow) ([]case_library.CaseCitation, error) {
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	citations := make([]case_library.CaseCitation, 0, len(rows))
+	for index, row := range rows {
+		citation := case_library.CaseCitation{
+			URL:           row.URL,
+			Title:         row.Title,
+			PublishedDate: row.PublishedDate,
+		}
+		if retrievedAt := strings.TrimSpace(row.RetrievedAt); retrievedAt != "" {
+			parsed, err := time.Parse(time.RFC3339, retrievedAt)
+			if err != nil {
+				return nil, fmt.Errorf("citations[%d].retrieved_at is not RFC3339: %q", index, retrievedAt)
+			}
+			citation.RetrievedAt = parsed
+		}
+		citations = append(citations, citation)
+	}
+	return citations, nil
+}
+
+func toCitationRows(citations []case_library.CaseCitation) []caseCitationRow {
+	if len(citations) == 0 {
+		return nil
+	}
+	rows := make([]caseCitationRow, 0, len(citations))
+	for _, citation := range citations {
+		row := caseCitationRow{
+			URL:           citation.URL,
+			Title:         citation.Title,
+			PublishedDate: citation.PublishedDate,
+		}
+		if !citation.RetrievedAt.IsZero() {
+			row.RetrievedAt = citation.RetrievedAt.Format(time.RFC3339)
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// parsedRow 是导入文件中的一条数据；RowNumber 为该条数据在文件中的起始行号（从 1 开始，CSV 表头占第 1 行）。
+// ParseErr 非空表示该行无法解析，不会进入校验与入库。
+type parsedRow struct {
+	RowNumber int
+	Input     case_library.CreateHistoricalCaseInput
+	ParseErr  error
+}
+
+// NormalizeFormat 规范化导入导出格式，空值时按文件名后缀推断，无法识别时返回 ErrUnsupportedFormat。
+func NormalizeFormat(format string, fileName string) (string, error) {
+	normalized := strings.ToLower(strings.TrimSpace(format))
+	if normalized == "" {
+		lowerName := strings.ToLower(strings.TrimSpace(fileName))
+		switch {
+		case strings.HasSuffix(lowerName, ".csv"):
+			normalized = FormatCSV
+		case strings.HasSuffix(lowerName, ".jsonl"), strings.HasSuffix(lowerName, ".ndjson"):
+			normalized = FormatJSONL
+		}
+	}
+	switch normalized {
+	case FormatJSONL, "ndjson":
+		return FormatJSONL, nil
+	case FormatCSV:
+		return FormatCSV, nil
+	}
+	return "", fmt.Errorf("%w: %q", ErrUnsupportedFormat, format)
+}
+
+// parseRows 解析整个导入文件；单行错误记录在对应行上，只有文件整体不可读（如 CSV 缺少必需列）时返回 error。
+func parseRows(format string, payload []byte) ([]parsedRow, error) {
+	payload = bytes.TrimPrefix(payload, []byte("\xef\xbb\xbf"))
+	switch format {
+	case FormatJSONL:
+		return parseJSONLRows(payload)
+	case FormatCSV:
+		return parseCSVRows(payload)
+	}
+	return nil, fmt.Errorf("%w: %q", ErrUnsupportedFormat, format)
+}
+
+func parseJSONLRows(payload []byte) ([]parsedRow, error) {
+	rows := make([]parsedRow, 0)
+	scanner := bufio.NewScanner(bytes.NewReader(payload))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(payload)+1)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var row exportedCase
+		if err := json.Unmarshal([]byte(line), &row); err != nil {
+			rows = append(rows, parsedRow{RowNumber: lineNumber, ParseErr: fmt.Errorf("invalid json: %v", err)})
+			continue
+		}
+		input, err := row.toInput()
+		if err != nil {
+			rows = append(rows, parsedRow{RowNumber: lineNumber, ParseErr: err})
+			continue
+		}
+		input.Embedding = row.embedding()
+		rows = append(rows, parsedRow{RowNumber: lineNumber, Input: input})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read jsonl failed: %w", err)
+	}
+	return rows, nil
+}
+
+func parseCSVRows(payload []byte) ([]parsedRow, error) {
+	reader := csv.NewReader(bytes.NewReader(payload))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("csv header is missing")
+		}
+		return nil, fmt.Errorf("read csv header failed: %w", err)
+	}
+	columnIndex := make(map[string]int, len(header))
+	for index, name := range header {
+		columnIndex[strings.ToLower(strings.TrimSpace(name))] = index
+	}
+	for _, required := range []string{"title", "target_group", "risk_level", "scam_type", "case_description"} {
+		if _, ok := columnIndex[required]; !ok {
+			return nil, fmt.Errorf("csv header is missing required column: %s", required)
+		}
+	}
+
+	rows := make([]parsedRow, 0)
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				rows = append(rows, parsedRow{RowNumber: parseErr.StartLine, ParseErr: fmt.Errorf("invalid csv row: %v", parseErr.Err)})
+				continue
+			}
+			return nil, fmt.Errorf("read csv failed: %w", err)
+		}
+		if isBlankCSVRecord(record) {
+			continue
+		}
+		rowNumber, _ := reader.FieldPos(0)
+
+		cell := func(name string) string {
+			index, ok := columnIndex[name]
+			if !ok || index >= len(record) {
+				return ""
+			}
+			return record[index]
+		}
+		typicalScripts, scriptsErr := parseCSVList(cell("typical_scripts"))
+		keywords, keywordsErr := parseCSVList(cell("keywords"))
+		if scriptsErr != nil || keywordsErr != nil {
+			rows = append(rows, parsedRow{RowNumber: rowNumber, ParseErr: fmt.Errorf("invalid list cell: %v", errors.Join(scriptsErr, keywordsErr))})
+			continue
+		}
+		citationRows, err := parseCSVCitations(cell("citations"))
+		if err != nil {
+			rows = append(rows, parsedRow{RowNumber: rowNumber, ParseErr: fmt.Errorf("invalid citations cell: %v", err)})
+			continue
+		}
+		input, err := caseRow{
+			Title:           cell("title"),
+			TargetGroup:     cell("target_group"),
+			RiskLevel:       cell("risk_level"),
+			ScamType:        cell("scam_type"),
+			CaseDescription: cell("case_description"),
+			TypicalScripts:  typicalScripts,
+			Keywords:        keywords,
+			ViolatedLaw:     cell("violated_law"),
+			Suggestion:      cell("suggestion"),
+			Citations:       citationRows,
+		}.toInput()
+		if err != nil {
+			rows = append(rows, parsedRow{RowNumber: rowNumber, ParseErr: err})
+			continue
+		}
+		input.Embedding = parseCSVEmbedding(cell("embedding_model"), cell("embedding_dimension"), cell("embedding_vector"))
+		rows = append(rows, parsedRow{RowNumber: rowNumber, Input: input})
+	}
+	return rows, nil
+}
+
+// parseCSVList 解析 CSV 中的列表字段：JSON 数组或 "|" 分隔的字符串。
+func parseCSVList(value string) ([]string, error) {
+	trimmed := strings.TrimSpace(value)
+	if trimmed == "" {
+		return nil, nil
+	}
+	if strings.HasPrefix(trimmed, "[") {
+		var items []string
+		if err := json.Unmarshal([]byte(trimmed), &items); err != nil {
+			return nil, err
+		}
+		return items, nil
+	}
+	return strings.Split(trimmed, csvListSeparator), nil
+}
+
+// parseCSVCitations 解析 CSV 中的来源引用列，单元格为与 JSONL 相同结构的 JSON 数组。
+func parseCSVCitations(value string) ([]caseCitationRow, error) {
+	trimmed := strings.TrimSpace(value)
+	if trimmed == "" {
+		return nil, nil
+	}
+	var rows []caseCitationRow
+	if err := json.Unmarshal([]byte(trimmed), &rows); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// parseCSVEmbedding 解析 include_vectors 导出的向量列；列缺失或内容无法解析时返回 nil，该行按普通数据重新向量化。
+func parseCSVEmbedding(modelName string, dimension string, vector string) *case_library.CaseEmbedding {
+	trimmedVector := strings.TrimSpace(vector)
+	if strings.TrimSpace(modelName) == "" || trimmedVector == "" {
+		return nil
+	}
+	row := exportedCase{EmbeddingModel: modelName}
+	if err := json.Unmarshal([]byte(trimmedVector), &row.EmbeddingVector); err != nil {
+		return nil
+	}
+	if trimmedDimension := strings.TrimSpace(dimension); trimmedDimension != "" {
+		parsed, err := strconv.Atoi(trimmedDimension)
+		if err != nil {
+			return nil
+		}
+		row.EmbeddingDimension = parsed
+	}
+	return row.embedding()
+}
+
+// formatCSVCitations 导出时把来源引用写成 JSON 数组，无引用时留空。
+func formatCSVCitations(rows []caseCitationRow) string {
+	if len(rows) == 0 {
+		return ""
+	}
+	encoded, err := json.Marshal(rows)
+	if err != nil {
+		return ""
+	}
+	return string(encoded)
+}
+
+// formatCSVList 导出时统一写成 JSON 数组，保证含分隔符的条目也能无损导回。
+func formatCSVList(items []string) string {
+	if len(items) == 0 {
+		return ""
+	}
+	encoded, err := json.Marshal(items)
+	if err != nil {
+		return strings.Join(items, csvListSeparator)
+	}
+	return string(encoded)
+}
+
+func isBlankCSVRecord(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,36 @@
+package casetransfer
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+var errorReportColumns = []string{"row_number", "status", "title", "error", "duplicate_case_id", "similarity"}
+
+// WriteErrorReportCSV 把导入错误报告写成 CSV，行号对应原始导入文件，便于修正后重新导入。
+func WriteErrorReportCSV(w io.Writer, items []RowError) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(errorReportColumns); err != nil {
+		return fmt.Errorf("write error report header failed: %w", err)
+	}
+	for _, item := range items {
+		similarity := ""
+		if item.DuplicateCaseID != "" {
+			similarity = strconv.FormatFloat(item.Similarity, 'f', 4, 64)
+		}
+		if err := writer.Write([]string{
+			strconv.Itoa(item.RowNumber),
+			item.Status,
+			item.Title,
+			item.Error,
+			item.DuplicateCaseID,
+			similarity,
+		}); err != nil {
+			return fmt.Errorf("write error report row failed: %w", err)
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,316 @@
+package casetransfer_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"antifraud/internal/modules/multi_agent/adapters/outbound/case_library"
+	"antifraud/internal/modules/multi_agent/application/casetransfer"
+	"antifraud/internal/modules/multi_agent/test/testsupport"
+)
+
+type fakeCaseWriter struct {
+	mu      sync.Mutex
+	created []case_library.CreateHistoricalCaseInput
+	batches int
+}
+
+func (f *fakeCaseWriter) CreateBatch(_ context.Context, _ string, inputs []case_library.CreateHistoricalCaseInput) []case_library.HistoricalCaseBatchResult {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.batches++
+	results := make([]case_library.HistoricalCaseBatchResult, len(inputs))
+	for index, input := range inputs {
+		switch {
+		case strings.TrimSpace(input.Title) == "":
+			results[index].Err = &case_library.ValidationError{Message: "title is required"}
+		case strings.Contains(input.Title, "重复"):
+			results[index].Err = &case_library.DuplicateHistoricalCaseError{
+				TopMatch: case_library.SimilarCaseResult{CaseID: "HCASE-EXIST", Similarity: 0.97},
+			}
+		case strings.Contains(input.Title, "故障"):
+			results[index].Err = errors.New("embedding provider timeout")
+		default:
+			f.created = append(f.created, input)
+			results[index].Record = case_library.HistoricalCaseRecord{CaseID: "HCASE-NEW", Title: input.Title}
+		}
+	}
+	return results
+}
+
+type fakeCaseSource struct {
+	records []case_library.HistoricalCaseRecord
+}
+
+func (f fakeCaseSource) StreamAll(callback func(case_library.HistoricalCaseRecord) error) error {
+	for _, record := range f.records {
+		if err := callback(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func waitForImportStatus(t *testing.T, service *casetransfer.Service, jobID string, status string) casetransfer.Job {
+	return testsupport.WaitForStatus(t,
+		func() (casetransfer.Job, error) { return service.Get(jobID) },
+		func(job casetransfer.Job) string { return job.Status },
+		status)
+}
+
+func TestImport_CSVClassifiesRowsAndReportsErrors(t *testing.T) {
+	testsupport.SetupMainDB(t)
+
+	writer := &fakeCaseWriter{}
+	service := casetransfer.NewService(writer, fakeCaseSource{})
+
+	payload := strings.Join([]string{
+		"title,target_group,risk_level,scam_type,case_description,typical_scripts,keywords",
+		`正常案件,老年人,高,冒充客服,描述,"[""话术一"",""话术二""]",退款|客服`,
+		`,老年人,高,冒充客服,描述,,`,
+		`重复案件,老年人,高,冒充客服,描述,,`,
+		`故障案件,老年人,高,冒充客服,描述,,`,
+		`坏列表,老年人,高,冒充客服,描述,"[oops",`,
+	}, "\n")
+
+	job, err := service.StartImport("admin", "cases.csv", "", []byte(payload), 2)
+	if err != nil {
+		t.Fatalf("start import failed: %v", err)
+	}
+	if job.Format != casetransfer.FormatCSV || job.TotalRows != 5 || job.BatchSize != 2 {
+		t.Fatalf("unexpected job: %+v", job)
+	}
+
+	finished := waitForImportStatus(t, service, job.JobID, casetransfer.JobStatusCompleted)
+	if finished.ProcessedRows != 5 || finished.ImportedRows != 1 || finished.InvalidRows != 2 || finished.DuplicateRows != 1 || finished.FailedRows != 1 {
+		t.Fatalf("unexpected counters: %+v", finished)
+	}
+	if writer.batches != 2 {
+		t.Fatalf("expected parse-only batch to skip the writer, got %d batches", writer.batches)
+	}
+	if got := writer.created[0]; len(got.TypicalScripts) != 2 || len(got.Keywords) != 2 || got.Keywords[1] != "客服" {
+		t.Fatalf("unexpected parsed list fields: %+v", got)
+	}
+
+	rowErrors, err := service.ListRowErrors(job.JobID)
+	if err != nil {
+		t.Fatalf("list row errors failed: %v", err)
+	}
+	if len(rowErrors) != 4 {
+		t.Fatalf("expected 4 row errors, got %+v", rowErrors)
+	}
+	wantRows := []int{3, 4, 5, 6}
+	for index, item := range rowErrors {
+		if item.RowNumber != wantRows[index] {
+			t.Fatalf("expected row %d at %d, got %+v", wantRows[index], index, item)
+		}
+	}
+	if rowErrors[1].Status != casetransfer.RowStatusDuplicate || rowErrors[1].DuplicateCaseID != "HCASE-EXIST" {
+		t.Fatalf("unexpected duplicate row: %+v", rowErrors[1])
+	}
+	if rowErrors[2].Status != casetransfer.RowStatusFailed || rowErrors[3].Status != casetransfer.RowStatusInvalid {
+		t.Fatalf("unexpected row statuses: %+v", rowErrors)
+	}
+
+	var report bytes.Buffer
+	if err := casetransfer.WriteErrorReportCSV(&report, rowErrors); err != nil {
+		t.Fatalf("write error report failed: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(report.String()), "\n")
+	if len(lines) != 5 || !strings.HasPrefix(lines[0], "row_number,status") || !strings.Contains(lines[2], "HCASE-EXIST") {
+		t.Fatalf("unexpected error report:\n%s", report.String())
+	}
+}
+
+func TestImport_RejectsInvalidFiles(t *testing.T) {
+	testsupport.SetupMainDB(t)
+
+	service := casetransfer.NewService(&fakeCaseWriter{}, fakeCaseSource{})
+	if _, err := service.StartImport("admin", "cases.xlsx", "", []byte("x"), 0); !errors.Is(err, casetransfer.ErrUnsupportedFormat) {
+		t.Fatalf("expected unsupported format, got %v", err)
+	}
+	if _, err := service.StartImport("admin", "cases.csv", "", []byte("title,scam_type\nx,y\n"), 0); !errors.Is(err, casetransfer.ErrInvalidImportFile) {
+		t.Fatalf("expected invalid file for missing columns, got %v", err)
+	}
+	if _, err := service.StartImport("admin", "", "jsonl", []byte("\n\n"), 0); !errors.Is(err, casetransfer.ErrInvalidImportFile) {
+		t.Fatalf("expected invalid file for empty jsonl, got %v", err)
+	}
+}
+
+func TestExport_JSONLRoundTripsThroughImport(t *testing.T) {
+	testsupport.SetupMainDB(t)
+
+	source := fakeCaseSource{records: []case_library.HistoricalCaseRecord{
+		{
+			CaseID:          "HCASE-1",
+			CreatedBy:       "admin",
+			Title:           "冒充客服退款",
+			TargetGroup:     "老年人",
+			RiskLevel:       "高",
+			ScamType:        "冒充客服",
+			CaseDescription: "描述",
+			TypicalScripts:  []string{"您的快递丢失了"},
+			Keywords:        []string{"退款"},
+			EmbeddingModel:  "embed-v1",
+			EmbeddingVector: []float64{0.1, 0.2},
+			CreatedAt:       time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		},
+	}}
+	writer := &fakeCaseWriter{}
+	service := casetransfer.NewService(writer, source)
+
+	var withoutVectors bytes.Buffer
+	count, err := service.Export(context.Background(), &withoutVectors, casetransfer.ExportOptions{Format: "jsonl"})
+	if err != nil || count != 1 {
+		t.Fatalf("export failed: count=%d err=%v", count, err)
+	}
+	if strings.Contains(withoutVectors.String(), "embedding_vector") {
+		t.Fatalf("expected vectors to be omitted: %s", withoutVectors.String())
+	}
+
+	var withVectors bytes.Buffer
+	if _, err := service.Export(context.Background(), &withVectors, casetransfer.ExportOptions{Format: "jsonl", IncludeVectors: true}); err != nil {
+		t.Fatalf("export with vectors failed: %v", err)
+	}
+	var exported map[string]interface{}
+	if err := json.Unmarshal(withVectors.Bytes(), &exported); err != nil {
+		t.Fatalf("decode exported line failed: %v", err)
+	}
+	if exported["case_id"] != "HCASE-1" || exported["embedding_model"] != "embed-v1" || exported["created_at"] != "2026-01-02T03:04:05Z" {
+		t.Fatalf("unexpected exported case: %+v", exported)
+	}
+
+	job, err := service.StartImport("admin", "library.jsonl", "", withVectors.Bytes(), 0)
+	if err != nil {
+		t.Fatalf("reimport failed: %v", err)
+	}
+	finished := waitForImportStatus(t, service, job.JobID, casetransfer.JobStatusCompleted)
+	if finished.ImportedRows != 1 || len(writer.created) != 1 || writer.created[0].TypicalScripts[0] != "您的快递丢失了" {
+		t.Fatalf("unexpected reimport result: job=%+v created=%+v", finished, writer.created)
+	}
+
+	var csvOut bytes.Buffer
+	if _, err := service.Export(context.Background(), &csvOut, casetransfer.ExportOptions{Format: "csv"}); err != nil {
+		t.Fatalf("csv export failed: %v", err)
+	}
+	if !strings.HasPrefix(csvOut.String(), "title,target_group,risk_level,scam_type,case_description,typical_scripts,keywords") {
+		t.Fatalf("unexpected csv header: %s", csvOut.String())
+	}
+}
+
+func TestExport_CitationsRoundTripThroughJSONLAndCSV(t *testing.T) {
+	testsupport.SetupMainDB(t)
+
+	retrievedAt := time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC)
+	source := fakeCaseSource{records: []case_library.HistoricalCaseRecord{
+		{
+			CaseID:          "HCASE-CITED",
+			Title:           "冒充公检法",
+			TargetGroup:     "老年人",
+			RiskLevel:       "高",
+			ScamType:        "冒充公检法",
+			CaseDescription: "描述",
+			Citations: []case_library.CaseCitation{
+				{URL: "https://news.example.com/a", Title: "警方通报", PublishedDate: "2026-03-01", RetrievedAt: retrievedAt},
+			},
+		},
+	}}
+
+	for _, format := range []string{"jsonl", "csv"} {
+		writer := &fakeCaseWriter{}
+		service := casetransfer.NewService(writer, source)
+		var exported bytes.Buffer
+		if _, err := service.Export(context.Background(), &exported, casetransfer.ExportOptions{Format: format}); err != nil {
+			t.Fatalf("%s export failed: %v", format, err)
+		}
+		job, err := service.StartImport("admin", "library."+format, "", exported.Bytes(), 0)
+		if err != nil {
+			t.Fatalf("%s reimport failed: %v", format, err)
+		}
+		finished := waitForImportStatus(t, service, job.JobID, casetransfer.JobStatusCompleted)
+		if finished.ImportedRows != 1 || len(writer.created) != 1 {
+			t.Fatalf("%s: unexpected reimport result: job=%+v", format, finished)
+		}
+		citations := writer.created[0].Citations
+		if len(citations) != 1 || citations[0].URL != "https://news.example.com/a" || citations[0].Title != "警方通报" ||
+			citations[0].PublishedDate != "2026-03-01" || !citations[0].RetrievedAt.Equal(retrievedAt) {
+			t.Fatalf("%s: expected citations to survive the round trip, got %+v", format, citations)
+		}
+	}
+
+	service := casetransfer.NewService(&fakeCaseWriter{}, fakeCaseSource{})
+	payload := `{"title":"坏引用","target_group":"老年人","risk_level":"高","scam_type":"冒充客服","case_description":"描述","citations":[{"url":"https://a.example.com","retrieved_at":"yesterday"}]}`
+	job, err := service.StartImport("admin", "bad.jsonl", "", []byte(payload), 0)
+	if err != nil {
+		t.Fatalf("start import failed: %v", err)
+	}
+	finished := waitForImportStatus(t, service, job.JobID, casetransfer.JobStatusCompleted)
+	if finished.InvalidRows != 1 {
+		t.Fatalf("expected malformed retrieved_at to mark the row invalid, got %+v", finished)
+	}
+}
+
+func TestImport_CarriesExportedVectorsToWriter(t *testing.T) {
+	testsupport.SetupMainDB(t)
+
+	source := fakeCaseSource{records: []case_library.HistoricalCaseRecord{
+		{
+			CaseID:             "HCASE-VEC",
+			Title:              "冒充客服退款",
+			TargetGroup:        "老年人",
+			RiskLevel:          "高",
+			ScamType:           "冒充客服",
+			CaseDescription:    "描述",
+			EmbeddingModel:     "embed-v1",
+			EmbeddingDimension: 2,
+			EmbeddingVector:    []float64{0.1, 0.2},
+		},
+	}}
+
+	for _, format := range []string{"jsonl", "csv"} {
+		for _, includeVectors := range []bool{true, false} {
+			writer := &fakeCaseWriter{}
+			service := casetransfer.NewService(writer, source)
+			var exported bytes.Buffer
+			if _, err := service.Export(context.Background(), &exported, casetransfer.ExportOptions{Format: format, IncludeVectors: includeVectors}); err != nil {
+				t.Fatalf("%s export failed: %v", format, err)
+			}
+			job, err := service.StartImport("admin", "library."+format, "", exported.Bytes(), 0)
+			if err != nil {
+				t.Fatalf("%s reimport failed: %v", format, err)
+			}
+			waitForImportStatus(t, service, job.JobID, casetransfer.JobStatusCompleted)
+			if len(writer.created) != 1 {
+				t.Fatalf("%s: expected one imported case, got %+v", format, writer.created)
+			}
+			embedding := writer.created[0].Embedding
+			if !includeVectors {
+				if embedding != nil {
+					t.Fatalf("%s: expected no vector without include_vectors, got %+v", format, embedding)
+				}
+				continue
+			}
+			if embedding == nil || embedding.Model != "embed-v1" || len(embedding.Vector) != 2 || embedding.Vector[1] != 0.2 {
+				t.Fatalf("%s: expected exported vector to reach the writer, got %+v", format, embedding)
+			}
+		}
+	}
+
+	writer := &fakeCaseWriter{}
+	service := casetransfer.NewService(writer, fakeCaseSource{})
+	payload := `{"title":"维度不符","target_group":"老年人","risk_level":"高","scam_type":"冒充客服","case_description":"描述","embedding_model":"embed-v1","embedding_dimension":3,"embedding_vector":[0.1,0.2]}`
+	job, err := service.StartImport("admin", "mismatch.jsonl", "", []byte(payload), 0)
+	if err != nil {
+		t.Fatalf("start import failed: %v", err)
+	}
+	waitForImportStatus(t, service, job.JobID, casetransfer.JobStatusCompleted)
+	if len(writer.created) != 1 || writer.created[0].Embedding != nil {
+		t.Fatalf("expected vector with mismatched dimension to be dropped, got %+v", writer.created)
+	}
+}
@@ -8,11 +8,14 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
+	"antifraud/internal/modules/multi_agent/adapters/outbound/blobstore"
 	"antifraud/internal/modules/multi_agent/adapters/outbound/state"
+	"antifraud/internal/modules/multi_agent/core"
 	appcfg "antifraud/internal/platform/config"
 )
 
@@ -66,52 +69,117 @@ var audioPresets = []audioPreset{
 }
 
 // NormalizeTaskPayload 在任务入队前对多媒体进行规范化，避免超长、超大输入直接进入后续分析链路。
-func NormalizeTaskPayload(payload state.TaskPayload) (state.TaskPayload, error) {
+// 输入既可以是 data url/base64，也可以是入队时由已上传媒体换成的 blob:<sha256> 引用：
+// 图片、视频与扩展模态的引用只校验存在后原样保留，由 worker 领取任务交给分析智能体时再读取内容；
+// 音频需要转码，只有音频引用会在此读取原始内容。
+// ctx 取消时会终止正在执行的 ffmpeg/ffprobe 子进程。
+func NormalizeTaskPayload(ctx context.Context, payload state.TaskPayload) (state.TaskPayload, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
 	normalized := state.TaskPayload{
 		Text:          strings.TrimSpace(payload.Text),
-		Images:        append([]string{}, payload.Images...),
 		VideoInsights: append([]string{}, payload.VideoInsights...),
 		AudioInsights: append([]string{}, payload.AudioInsights...),
 		ImageInsights: append([]string{}, payload.ImageInsights...),
+		ExtraInsights: state.CloneModalityLists(payload.ExtraInsights),
 	}
 
 	normalized.Videos = make([]string, 0, len(payload.Videos))
 	for idx, item := range payload.Videos {
-		video, err := normalizeVideoInput(item)
+		video, err := normalizeVideoInput(ctx, item)
 		if err != nil {
 			return state.TaskPayload{}, fmt.Errorf("视频 %d 预处理失败: %w", idx+1, err)
 		}
 		normalized.Videos = append(normalized.Videos, video)
 	}
 
+	normalized.Images = make([]string, 0, len(payload.Images))
+	for idx, item := range payload.Images {
+		image, err := keepMediaReference(item)
+		if err != nil {
+			return state.TaskPayload{}, fmt.Errorf("图片 %d 预处理失败: %w", idx+1, err)
+		}
+		normalized.Images = append(normalized.Images, image)
+	}
+
 	normalized.Audios = make([]string, 0, len(payload.Audios))
 	for idx, item := range payload.Audios {
-		audio, err := normalizeAudioData(item)
+		audio, err := normalizeAudioData(ctx, item)
 		if err != nil {
 			return state.TaskPayload{}, fmt.Errorf("音频 %d 预处理失败: %w", idx+1, err)
 		}
 		normalized.Audios = append(normalized.Audios, audio)
 	}
 
+	extraInputs, err := normalizeExtraModalityInputs(ctx, payload.ExtraInputs)
+	if err != nil {
+		return state.TaskPayload{}, err
+	}
+	normalized.ExtraInputs = extraInputs
+
 	return normalized, nil
 }
 
-func normalizeVideoInput(input string) (string, error) {
-	payload, err := decodeMediaInput(input, "video/mp4")
+// normalizeExtraModalityInputs 调用注册模态各自的 Preprocess 规范化扩展输入；未注册的模态直接拒绝。
+func normalizeExtraModalityInputs(ctx context.Context, inputs map[string][]string) (map[string][]string, error) {
+	kinds := make([]string, 0, len(inputs))
+	for kind := range inputs {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+
+	var normalized map[string][]string
+	for _, kind := range kinds {
+		analyzer, ok := multi_agent.LookupModalityAnalyzer(kind)
+		if !ok {
+			return nil, fmt.Errorf("不支持的输入类型: %s", kind)
+		}
+		items := make([]string, 0, len(inputs[kind]))
+		for idx, item := range inputs[kind] {
+			if strings.TrimSpace(item) == "" {
+				continue
+			}
+			input, err := keepMediaReference(item)
+			if err != nil {
+				return nil, fmt.Errorf("%s %d 预处理失败: %w", kind, idx+1, err)
+			}
+			processed, err := analyzer.Preprocess(ctx, input)
+			if err != nil {
+				return nil, fmt.Errorf("%s %d 预处理失败: %w", kind, idx+1, err)
+			}
+			items = append(items, processed)
+		}
+		if len(items) == 0 {
+			continue
+		}
+		if normalized == nil {
+			normalized = map[string][]string{}
+		}
+		normalized[kind] = items
+	}
+	return normalized, nil
+}
+
+func normalizeVideoInput(ctx context.Context, input string) (string, error) {
+	if _, ok := blobstore.ParseRef(input); ok {
+		return keepMediaReference(input)
+	}
+	payload, err := decodeMediaInput(ctx, input, "video/mp4")
 	if err != nil {
 		return "", err
 	}
 	return encodeDataURL(payload.MIME, payload.Raw), nil
 }
 
-func normalizeAudioData(input string) (string, error) {
-	payload, err := decodeMediaInput(input, "audio/mpeg")
+func normalizeAudioData(ctx context.Context, input string) (string, error) {
+	payload, err := decodeMediaInput(ctx, input, "audio/mpeg")
 	if err != nil {
 		return "", err
 	}
 
 	output, err := transcodeWithFFmpeg(payload.Raw, mimeToExtension(payload.MIME, ".mp3"), ".mp3", func(inPath string, outPath string) error {
-		durationSeconds, probeErr := probeMediaDurationSeconds(inPath)
+		durationSeconds, probeErr := probeMediaDurationSeconds(ctx, inPath)
 		if probeErr != nil {
 			return fmt.Errorf("读取音频时长失败: %w", probeErr)
 		}
@@ -128,7 +196,7 @@ func normalizeAudioData(input string) (string, error) {
 				"-b:a", preset.Bitrate,
 				outPath,
 			}
-			ok, runErr := runFFmpegAttempt(args, outPath)
+			ok, runErr := runFFmpegAttempt(ctx, args, outPath)
 			if runErr != nil {
 				return runErr
 			}
@@ -175,18 +243,21 @@ func transcodeWithFFmpeg(raw []byte, inputExt string, outputExt string, process
 	return bytes, nil
 }
 
-func runFFmpegAttempt(args []string, outPath string) (bool, error) {
+func runFFmpegAttempt(ctx context.Context, args []string, outPath string) (bool, error) {
 	ffmpegPath, err := lookupFFmpegPath()
 	if err != nil {
 		return false, err
 	}
 
-	cmdCtx, cancel := context.WithTimeout(context.Background(), ffmpegRunTimeout)
+	cmdCtx, cancel := context.WithTimeout(ctx, ffmpegRunTimeout)
 	defer cancel()
 
 	cmd := exec.CommandContext(cmdCtx, ffmpegPath, args...)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
+		if ctx.Err() != nil {
+			return false, fmt.Errorf("ffmpeg 处理已取消: %w", ctx.Err())
+		}
 		if cmdCtx.Err() == context.DeadlineExceeded {
 			return false, fmt.Errorf("ffmpeg 处理超时: %w", err)
 		}
@@ -249,13 +320,13 @@ func maxRawMediaBytes() int {
 	return rawBudget
 }
 
-func probeMediaDurationSeconds(path string) (float64, error) {
+func probeMediaDurationSeconds(ctx context.Context, path string) (float64, error) {
 	ffprobePath, err := lookupFFprobePath()
 	if err != nil {
 		return 0, err
 	}
 
-	cmdCtx, cancel := context.WithTimeout(context.Background(), ffmpegRunTimeout)
+	cmdCtx, cancel := context.WithTimeout(ctx, ffmpegRunTimeout)
 	defer cancel()
 
 	args := []string{
@@ -267,6 +338,9 @@ func probeMediaDurationSeconds(path string) (float64, error) {
 	cmd := exec.CommandContext(cmdCtx, ffprobePath, args...)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
+		if ctx.Err() != nil {
+			return 0, fmt.Errorf("ffprobe 处理已取消: %w", ctx.Err())
+		}
 		if cmdCtx.Err() == context.DeadlineExceeded {
 			return 0, fmt.Errorf("ffprobe 处理超时: %w", err)
 		}
@@ -335,12 +409,36 @@ func formatFPS(value float64) string {
 	return strconv.FormatFloat(rounded, 'f', -1, 64)
 }
 
-func decodeMediaInput(input string, fallbackMIME string) (dataURLPayload, error) {
+// keepMediaReference 校验 blob:<sha256> 引用的内容存在（只读元数据）并返回规范化的引用，其他输入原样返回。
+func keepMediaReference(input string) (string, error) {
+	key, ok := blobstore.ParseRef(input)
+	if !ok {
+		return input, nil
+	}
+	if _, err := blobstore.DefaultStore().Stat(key); err != nil {
+		return "", fmt.Errorf("读取媒体 %s 失败: %w", key, err)
+	}
+	return blobstore.Ref(key), nil
+}
+
+func decodeMediaInput(ctx context.Context, input string, fallbackMIME string) (dataURLPayload, error) {
 	trimmed := strings.TrimSpace(input)
 	if trimmed == "" {
 		return dataURLPayload{}, fmt.Errorf("媒体内容为空")
 	}
 
+	if key, ok := blobstore.ParseRef(trimmed); ok {
+		blob, raw, err := blobstore.DefaultStore().ReadAll(ctx, key)
+		if err != nil {
+			return dataURLPayload{}, fmt.Errorf("读取媒体 %s 失败: %w", key, err)
+		}
+		mimeType := strings.TrimSpace(blob.MIME)
+		if mimeType == "" || mimeType == "application/octet-stream" {
+			mimeType = fallbackMIME
+		}
+		return dataURLPayload{MIME: mimeType, Raw: raw}, nil
+	}
+
 	if strings.HasPrefix(trimmed, "data:") {
 		parts := strings.SplitN(trimmed, ",", 2)
 		if len(parts) != 2 {
@@ -0,0 +1,748 @@
+package migration
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"antifraud/internal/modules/multi_agent/adapters/outbound/case_library"
+	"antifraud/internal/modules/multi_agent/adapters/outbound/state"
+	"antifraud/internal/modules/multi_agent/adapters/outbound/user_history_index"
+	"antifraud/internal/platform/database"
+	"antifraud/internal/platform/embedding"
+
+	"gorm.io/gorm"
+)
+
+const (
+	JobStatusRunning   = "running"
+	JobStatusCompleted = "completed"
+	JobStatusFailed    = "failed"
+	JobStatusCancelled = "cancelled"
+
+	PhaseHistoricalCases = "historical_cases"
+	PhaseUserHistory     = "user_history"
+	PhaseCutover         = "cutover"
+	PhaseDone            = "done"
+
+	defaultBatchSize = 50
+	maxBatchSize     = 500
+	// maxMigrationSweeps 是全量扫描的最大轮数：首轮之后的补扫用于重试失败项，
+	// 以及迁移期间新写入但未被双写覆盖的数据。
+	maxMigrationSweeps = 3
+)
+
+var (
+	ErrMigrationRunning   = errors.New("an embedding migration is already running")
+	ErrInvalidTargetModel = errors.New("invalid target embedding model")
+	ErrJobNotFound        = errors.New("embedding migration job not found")
+	ErrJobNotRunning      = errors.New("embedding migration job is not running")
+
+	errJobStopped = errors.New("embedding migration job stopped")
+)
+
+// jobDB 返回迁移任务表所在的主业务库。
+var jobDB = func() *gorm.DB { return database.DB }
+
+type jobEntity struct {
+	ID                uint       `gorm:"primaryKey;autoIncrement"`
+	JobID             string     `gorm:"size:64;uniqueIndex;not null"`
+	SourceModel       string     `gorm:"size:128"`
+	TargetModel       string     `gorm:"size:128;not null"`
+	Status            string     `gorm:"size:32;index;not null"`
+	Phase             string     `gorm:"size:32"`
+	BatchSize         int        `gorm:"not null"`
+	Sweep             int        `gorm:"not null;default:0"`
+	HistoricalTotal   int64      `gorm:"not null;default:0"`
+	HistoricalDone    int64      `gorm:"not null;default:0"`
+	HistoricalFailed  int64      `gorm:"not null;default:0"`
+	UserHistoryTotal  int64      `gorm:"not null;default:0"`
+	UserHistoryDone   int64      `gorm:"not null;default:0"`
+	UserHistoryFailed int64      `gorm:"not null;default:0"`
+	LastError         string     `gorm:"type:text"`
+	CreatedBy         string     `gorm:"size:64"`
+	StartedAt         time.Time  `gorm:"not null"`
+	FinishedAt        *time.Time `gorm:""`
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+}
+
+func (jobEntity) TableName() string {
+	return "embedding_migration_jobs"
+}
+
+func init() {
+	database.RegisterMainDBSchemaInitializer("embedding_migration", initJobSchema)
+}
+
+func initJobSchema(db *gorm.DB) error {
+	if db == nil {
+		return fmt.Errorf("embedding migration schema db is nil")
+	}
+	return db.AutoMigrate(&jobEntity{})
+}
+
+// Job 是一次 embedding 模型迁移任务的进度快照。
+type Job struct {
+	JobID             string
+	SourceModel       string
+	TargetModel       string
+	Status            string
+	Phase             string
+	BatchSize         int
+	Sweep             int
+	HistoricalTotal   int64
+	HistoricalDone    int64
+	HistoricalFailed  int64
+	UserHistoryTotal  int64
+	UserHistoryDone   int64
+	UserHistoryFailed int64
+	LastError         string
+	CreatedBy         string
+	StartedAt         time.Time
+	FinishedAt        *time.Time
+	UpdatedAt         time.Time
+}
+
+// HistoricalCaseStore 是迁移任务访问历史案件向量的端口。
+type HistoricalCaseStore interface {
+	ListSources(afterID uint, limit int) ([]case_library.HistoricalCaseEmbeddingSource, error)
+	// Stage 批量暂存目标模型向量，返回值与 sources 一一对应。
+	Stage(ctx context.Context, sources []case_library.HistoricalCaseEmbeddingSource, model string) []error
+	Count(model string) (total int64, ready int64, err error)
+	Promote(model string) (int64, error)
+	ClearStaged() error
+	DominantModel() (string, error)
+}
+
+// UserHistoryStore 是迁移任务访问用户历史向量索引的端口。
+type UserHistoryStore interface {
+	ListKeys(afterUserID string, afterRecordID string, limit int) ([]user_history_index.HistoryVectorKey, error)
+	// Stage 批量暂存目标模型向量，返回值与 keys 一一对应。
+	Stage(ctx context.Context, keys []user_history_index.HistoryVectorKey, model string) []error
+	Count(model string) (total int64, ready int64, err error)
+	Promote(model string) (int64, error)
+	ClearStaged() error
+}
+
+// ModelRegistry 是进程内 embedding 模型状态的端口。
+type ModelRegistry interface {
+	ActiveModel() string
+	SetActiveModel(model string)
+	SetMigrationTargetModel(model string)
+	ConfiguredModel() (string, error)
+}
+
+// Service 编排 embedding 模型迁移：按批重新向量化到暂存列，全部就绪后一次性切换。
+// 迁移期间检索仍使用旧模型向量，新写入的数据由写入路径双写目标模型向量；
+// 同一时刻只允许一个迁移任务运行。
+type Service struct {
+	cases     HistoricalCaseStore
+	histories UserHistoryStore
+	models    ModelRegistry
+
+	mu      sync.Mutex
+	cancels map[string]context.CancelFunc
+}
+
+var (
+	defaultServiceOnce sync.Once
+	defaultService     *Service
+)
+
+// NewService 创建迁移服务，参数为 nil 时使用默认实现。
+func NewService(cases HistoricalCaseStore, histories UserHistoryStore, models ModelRegistry) *Service {
+	if cases == nil {
+		cases = caseLibraryStore{}
+	}
+	if histories == nil {
+		histories = userHistoryIndexStore{}
+	}
+	if models == nil {
+		models = embeddingModelRegistry{}
+	}
+	return &Service{
+		cases:     cases,
+		histories: histories,
+		models:    models,
+		cancels:   map[string]context.CancelFunc{},
+	}
+}
+
+// DefaultService 返回进程级迁移服务。
+func DefaultService() *Service {
+	defaultServiceOnce.Do(func() {
+		defaultService = NewService(nil, nil, nil)
+	})
+	return defaultService
+}
+
+// InitializeActiveModel 在服务启动时固定当前 embedding 模型，并返回该模型：
+// 优先取最近一次完成迁移的目标模型，其次取案件库中使用最多的模型，都没有时取配置中的 embedding.model。
+// 这样修改配置不会让新向量与存量向量混用，切换模型必须通过迁移任务完成。
+func (s *Service) InitializeActiveModel() (string, error) {
+	db := jobDB()
+	if db == nil {
+		return "", fmt.Errorf("database not initialized")
+	}
+
+	active := ""
+	var latest jobEntity
+	result := db.Where("status = ?", JobStatusCompleted).Order("id desc").Limit(1).Find(&latest)
+	if result.Error != nil {
+		return "", fmt.Errorf("query completed embedding migration failed: %w", result.Error)
+	}
+	if result.RowsAffected > 0 {
+		active = strings.TrimSpace(latest.TargetModel)
+	}
+	if active == "" {
+		dominant, err := s.cases.DominantModel()
+		if err != nil {
+			log.Printf("[embedding_migration] query dominant embedding model failed: %v", err)
+		}
+		active = dominant
+	}
+	if active == "" {
+		configured, err := s.models.ConfiguredModel()
+		if err != nil {
+			return "", err
+		}
+		active = configured
+	}
+	s.models.SetActiveModel(active)
+	return active, nil
+}
+
+// ResumeInterruptedJobs 恢复进程重启前仍在运行的迁移任务；已完成的批次会被跳过。
+func (s *Service) ResumeInterruptedJobs() {
+	db := jobDB()
+	if db == nil {
+		return
+	}
+	var rows []jobEntity
+	if err := db.Where("status = ?", JobStatusRunning).Order("id asc").Find(&rows).Error; err != nil {
+		log.Printf("[embedding_migration] query interrupted jobs failed: %v", err)
+		return
+	}
+	for index, row := range rows {
+		if index > 0 {
+			// 正常情况下最多只有一个运行中的任务，多余的按失败处理。
+			s.finish(row.JobID, JobStatusFailed, "superseded by an earlier running migration")
+			continue
+		}
+		log.Printf("[embedding_migration] resuming job: job_id=%s target=%s phase=%s", row.JobID, row.TargetModel, row.Phase)
+		s.launch(row)
+	}
+}
+
+// Start 创建并在后台启动一个迁移任务。targetModel 为空时使用配置中的 embedding.model。
+func (s *Service) Start(userID string, targetModel string, batchSize int) (Job, error) {
+	db := jobDB()
+	if db == nil {
+		return Job{}, fmt.Errorf("database not initialized")
+	}
+
+	target := strings.TrimSpace(targetModel)
+	if target == "" {
+		configured, err := s.models.ConfiguredModel()
+		if err != nil {
+			return Job{}, err
+		}
+		target = configured
+	}
+	if target == "" {
+		return Job{}, fmt.Errorf("%w: target model is empty", ErrInvalidTargetModel)
+	}
+	source := s.models.ActiveModel()
+	if target == source {
+		return Job{}, fmt.Errorf("%w: %s is already the active model", ErrInvalidTargetModel, target)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var running int64
+	if err := db.Model(&jobEntity{}).Where("status = ?", JobStatusRunning).Count(&running).Error; err != nil {
+		return Job{}, fmt.Errorf("query running embedding migration failed: %w", err)
+	}
+	if running > 0 {
+		return Job{}, ErrMigrationRunning
+	}
+
+	entity := jobEntity{
+		JobID:       newJobID(),
+		SourceModel: source,
+		TargetModel: target,
+		Status:      JobStatusRunning,
+		Phase:       PhaseHistoricalCases,
+		BatchSize:   normalizeBatchSize(batchSize),
+		CreatedBy:   strings.TrimSpace(userID),
+		StartedAt:   time.Now(),
+	}
+	if err := db.Create(&entity).Error; err != nil {
+		return Job{}, fmt.Errorf("create embedding migration job failed: %w", err)
+	}
+	s.launchLocked(entity)
+	return jobFromEntity(entity), nil
+}
+
+// Get 返回指定任务。
+func (s *Service) Get(jobID string) (Job, error) {
+	entity, err := loadJob(jobID)
+	if err != nil {
+		return Job{}, err
+	}
+	return jobFromEntity(entity), nil
+}
+
+// List 按创建时间倒序返回最近的迁移任务。
+func (s *Service) List(limit int) ([]Job, error) {
+	db := jobDB()
+	if db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	var rows []jobEntity
+	if err := db.Order("id desc").Limit(limit).Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("list embedding migration jobs failed: %w", err)
+	}
+	jobs := make([]Job, 0, len(rows))
+	for _, row := range rows {
+		jobs = append(jobs, jobFromEntity(row))
+	}
+	return jobs, nil
+}
+
+// Cancel 取消运行中的任务：停止后台处理、清空暂存向量，当前检索模型保持不变。
+func (s *Service) Cancel(jobID string) (Job, error) {
+	entity, err := loadJob(jobID)
+	if err != nil {
+		return Job{}, err
+	}
+	if entity.Status != JobStatusRunning || entity.Phase == PhaseCutover {
+		return Job{}, ErrJobNotRunning
+	}
+	if !s.finish(entity.JobID, JobStatusCancelled, "") {
+		return Job{}, ErrJobNotRunning
+	}
+
+	s.mu.Lock()
+	cancel, local := s.cancels[entity.JobID]
+	s.mu.Unlock()
+	if local {
+		// 后台 goroutine 退出时负责清理暂存向量，避免与正在写入的批次竞争。
+		cancel()
+	} else {
+		s.discardStaged()
+	}
+	return s.Get(entity.JobID)
+}
+
+// ActiveModel 返回当前固定的 embedding 模型。
+func (s *Service) ActiveModel() string {
+	return s.models.ActiveModel()
+}
+
+// ConfiguredModel 返回配置中的 embedding.model，读取失败时返回空串。
+func (s *Service) ConfiguredModel() string {
+	configured, err := s.models.ConfiguredModel()
+	if err != nil {
+		log.Printf("[embedding_migration] load configured embedding model failed: %v", err)
+		return ""
+	}
+	return configured
+}
+
+func (s *Service) launch(entity jobEntity) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.launchLocked(entity)
+}
+
+func (s *Service) launchLocked(entity jobEntity) {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancels[entity.JobID] = cancel
+	s.models.SetMigrationTargetModel(entity.TargetModel)
+	go s.run(ctx, entity)
+}
+
+func (s *Service) run(ctx context.Context, job jobEntity) {
+	defer func() {
+		s.mu.Lock()
+		if cancel, ok := s.cancels[job.JobID]; ok {
+			cancel()
+			delete(s.cancels, job.JobID)
+		}
+		s.mu.Unlock()
+	}()
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			log.Printf("[embedding_migration] panic recovered: job_id=%s err=%v", job.JobID, recovered)
+			s.models.SetMigrationTargetModel("")
+			s.finish(job.JobID, JobStatusFailed, fmt.Sprintf("panic: %v", recovered))
+		}
+	}()
+
+	err := s.migrate(ctx, &job)
+	switch {
+	case err == nil:
+		log.Printf("[embedding_migration] job completed: job_id=%s %s -> %s", job.JobID, job.SourceModel, job.TargetModel)
+	case errors.Is(err, errJobStopped):
+		log.Printf("[embedding_migration] job cancelled: job_id=%s", job.JobID)
+		s.models.SetMigrationTargetModel("")
+		s.discardStaged()
+	default:
+		log.Printf("[embedding_migration] job failed: job_id=%s err=%v", job.JobID, err)
+		s.models.SetMigrationTargetModel("")
+		s.finish(job.JobID, JobStatusFailed, err.Error())
+	}
+}
+
+// migrate 执行全量重新向量化与切换；失败项在后续补扫中重试，仍未就绪时不切换。
+func (s *Service) migrate(ctx context.Context, job *jobEntity) error {
+	complete := false
+	for sweep := 0; sweep < maxMigrationSweeps; sweep++ {
+		job.Sweep++
+		if err := s.migrateHistoricalCases(ctx, job); err != nil {
+			return err
+		}
+		if err := s.migrateUserHistory(ctx, job); err != nil {
+			return err
+		}
+		if job.HistoricalDone >= job.HistoricalTotal && job.UserHistoryDone >= job.UserHistoryTotal {
+			complete = true
+			break
+		}
+	}
+	if !complete {
+		return fmt.Errorf("vectors still missing after %d sweeps: historical_cases=%d/%d user_history=%d/%d last_error=%s",
+			maxMigrationSweeps, job.HistoricalDone, job.HistoricalTotal, job.UserHistoryDone, job.UserHistoryTotal, job.LastError)
+	}
+
+	job.Phase = PhaseCutover
+	if err := saveProgress(job); err != nil {
+		return err
+	}
+	if _, err := s.cases.Promote(job.TargetModel); err != nil {
+		return fmt.Errorf("promote historical case embeddings failed: %w", err)
+	}
+	if _, err := s.histories.Promote(job.TargetModel); err != nil {
+		return fmt.Errorf("promote user history embeddings failed: %w", err)
+	}
+	s.models.SetActiveModel(job.TargetModel)
+	s.models.SetMigrationTargetModel("")
+	job.Phase = PhaseDone
+	if err := saveProgress(job); err != nil {
+		return err
+	}
+	s.finish(job.JobID, JobStatusCompleted, "")
+	return nil
+}
+
+func (s *Service) migrateHistoricalCases(ctx context.Context, job *jobEntity) error {
+	job.Phase = PhaseHistoricalCases
+	job.HistoricalFailed = 0
+	afterID := uint(0)
+	for {
+		if ctx.Err() != nil {
+			return errJobStopped
+		}
+		sources, err := s.cases.ListSources(afterID, job.BatchSize)
+		if err != nil {
+			return err
+		}
+		pending := make([]case_library.HistoricalCaseEmbeddingSource, 0, len(sources))
+		for _, source := range sources {
+			afterID = source.ID
+			if source.EmbeddingModel != job.TargetModel && source.StagedEmbeddingModel != job.TargetModel {
+				pending = append(pending, source)
+			}
+		}
+		if len(pending) > 0 {
+			errs := s.cases.Stage(ctx, pending, job.TargetModel)
+			if ctx.Err() != nil {
+				return errJobStopped
+			}
+			for index, err := range errs {
+				if err == nil {
+					continue
+				}
+				job.HistoricalFailed++
+				job.LastError = err.Error()
+				log.Printf("[embedding_migration] stage historical case failed: job_id=%s case_id=%s err=%v", job.JobID, pending[index].CaseID, err)
+			}
+		}
+		total, ready, err := s.cases.Count(job.TargetModel)
+		if err != nil {
+			return err
+		}
+		job.HistoricalTotal, job.HistoricalDone = total, ready
+		if err := saveProgress(job); err != nil {
+			return err
+		}
+		if len(sources) < job.BatchSize {
+			return nil
+		}
+	}
+}
+
+func (s *Service) migrateUserHistory(ctx context.Context, job *jobEntity) error {
+	job.Phase = PhaseUserHistory
+	job.UserHistoryFailed = 0
+	afterUserID, afterRecordID := "", ""
+	for {
+		if ctx.Err() != nil {
+			return errJobStopped
+		}
+		keys, err := s.histories.ListKeys(afterUserID, afterRecordID, job.BatchSize)
+		if err != nil {
+			return err
+		}
+		pending := make([]user_history_index.HistoryVectorKey, 0, len(keys))
+		for _, key := range keys {
+			afterUserID, afterRecordID = key.UserID, key.RecordID
+			if key.EmbeddingModel != job.TargetModel && key.StagedEmbeddingModel != job.TargetModel {
+				pending = append(pending, key)
+			}
+		}
+		if len(pending) > 0 {
+			errs := s.histories.Stage(ctx, pending, job.TargetModel)
+			if ctx.Err() != nil {
+				return errJobStopped
+			}
+			for index, err := range errs {
+				if err == nil {
+					continue
+				}
+				job.UserHistoryFailed++
+				job.LastError = err.Error()
+				log.Printf("[embedding_migration] stage user history failed: job_id=%s record_id=%s err=%v", job.JobID, pending[index].RecordID, err)
+			}
+		}
+		total, ready, err := s.histories.Count(job.TargetModel)
+		if err != nil {
+			return err
+		}
+		job.UserHistoryTotal, job.UserHistoryDone = total, ready
+		if err := saveProgress(job); err != nil {
+			return err
+		}
+		if len(keys) < job.BatchSize {
+			return nil
+		}
+	}
+}
+
+func (s *Service) discardStaged() {
+	if err := s.cases.ClearStaged(); err != nil {
+		log.Printf("[embedding_migration] clear staged historical case embeddings failed: %v", err)
+	}
+	if err := s.histories.ClearStaged(); err != nil {
+		log.Printf("[embedding_migration] clear staged user history embeddings failed: %v", err)
+	}
+}
+
+// finish 把运行中的任务置为终态，返回是否确实发生了状态变更。
+func (s *Service) finish(jobID string, status string, lastError string) bool {
+	db := jobDB()
+	if db == nil {
+		return false
+	}
+	now := time.Now()
+	updates := map[string]interface{}{
+		"status":      status,
+		"finished_at": &now,
+	}
+	if lastError != "" {
+		updates["last_error"] = lastError
+	}
+	result := db.Model(&jobEntity{}).Where("job_id = ? AND status = ?", jobID, JobStatusRunning).Updates(updates)
+	if result.Error != nil {
+		log.Printf("[embedding_migration] update job status failed: job_id=%s status=%s err=%v", jobID, status, result.Error)
+		return false
+	}
+	return result.RowsAffected > 0
+}
+
+// saveProgress 持久化批次进度；任务已不处于运行状态（例如被取消）时返回 errJobStopped。
+func saveProgress(job *jobEntity) error {
+	db := jobDB()
+	if db == nil {
+		return fmt.Errorf("database not initialized")
+	}
+	result := db.Model(&jobEntity{}).
+		Where("job_id = ? AND status = ?", job.JobID, JobStatusRunning).
+		Updates(map[string]interface{}{
+			"phase":               job.Phase,
+			"sweep":               job.Sweep,
+			"historical_total":    job.HistoricalTotal,
+			"historical_done":     job.HistoricalDone,
+			"historical_failed":   job.HistoricalFailed,
+			"user_history_total":  job.UserHistoryTotal,
+			"user_history_done":   job.UserHistoryDone,
+			"user_history_failed": job.UserHistoryFailed,
+			"last_error":          job.LastError,
+		})
+	if result.Error != nil {
+		return fmt.Errorf("save embedding migration progress failed: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errJobStopped
+	}
+	return nil
+}
+
+func loadJob(jobID string) (jobEntity, error) {
+	db := jobDB()
+	if db == nil {
+		return jobEntity{}, fmt.Errorf("database not initialized")
+	}
+	var entity jobEntity
+	result := db.Where("job_id = ?", strings.TrimSpace(jobID)).Limit(1).Find(&entity)
+	if result.Error != nil {
+		return jobEntity{}, fmt.Errorf("query embedding migration job failed: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return jobEntity{}, ErrJobNotFound
+	}
+	return entity, nil
+}
+
+func jobFromEntity(entity jobEntity) Job {
+	return Job{
+		JobID:             entity.JobID,
+		SourceModel:       entity.SourceModel,
+		TargetModel:       entity.TargetModel,
+		Status:            entity.Status,
+		Phase:             entity.Phase,
+		BatchSize:         entity.BatchSize,
+		Sweep:             entity.Sweep,
+		HistoricalTotal:   entity.HistoricalTotal,
+		HistoricalDone:    entity.HistoricalDone,
+		HistoricalFailed:  entity.HistoricalFailed,
+		UserHistoryTotal:  entity.UserHistoryTotal,
+		UserHistoryDone:   entity.UserHistoryDone,
+		UserHistoryFailed: entity.UserHistoryFailed,
+		LastError:         entity.LastError,
+		CreatedBy:         entity.CreatedBy,
+		StartedAt:         entity.StartedAt,
+		FinishedAt:        entity.FinishedAt,
+		UpdatedAt:         entity.UpdatedAt,
+	}
+}
+
+func normalizeBatchSize(batchSize int) int {
+	if batchSize <= 0 {
+		return defaultBatchSize
+	}
+	if batchSize > maxBatchSize {
+		return maxBatchSize
+	}
+	return batchSize
+}
+
+func newJobID() string {
+	buf := make([]byte, 6)
+	if _, err := rand.Read(buf); err != nil {
+		return fmt.Sprintf("EMBMIG-%d", time.Now().UnixNano())
+	}
+	return "EMBMIG-" + strings.ToUpper(hex.EncodeToString(buf))
+}
+
+type caseLibraryStore struct{}
+
+func (caseLibraryStore) ListSources(afterID uint, limit int) ([]case_library.HistoricalCaseEmbeddingSource, error) {
+	return case_library.ListHistoricalCaseEmbeddingSources(afterID, limit)
+}
+
+func (caseLibraryStore) Stage(ctx context.Context, sources []case_library.HistoricalCaseEmbeddingSource, model string) []error {
+	return case_library.StageHistoricalCaseEmbeddings(ctx, sources, model)
+}
+
+func (caseLibraryStore) Count(model string) (int64, int64, error) {
+	return case_library.CountHistoricalCaseEmbeddings(model)
+}
+
+func (caseLibraryStore) Promote(model string) (int64, error) {
+	return case_library.PromoteStagedHistoricalCaseEmbeddings(model)
+}
+
+func (caseLibraryStore) ClearStaged() error {
+	return case_library.ClearStagedHistoricalCaseEmbeddings()
+}
+
+func (caseLibraryStore) DominantModel() (string, error) {
+	return case_library.DominantHistoricalCaseEmbeddingModel()
+}
+
+type userHistoryIndexStore struct{}
+
+func (userHistoryIndexStore) ListKeys(afterUserID string, afterRecordID string, limit int) ([]user_history_index.HistoryVectorKey, error) {
+	return user_history_index.DefaultService().ListHistoryVectorKeys(afterUserID, afterRecordID, limit)
+}
+
+// Stage 从历史归档记录重建 embedding 输入；归档记录已被删除的孤儿向量直接清理。
+func (userHistoryIndexStore) Stage(ctx context.Context, keys []user_history_index.HistoryVectorKey, model string) []error {
+	service := user_history_index.DefaultService()
+	errs := make([]error, len(keys))
+	inputs := make([]user_history_index.ArchiveInput, 0, len(keys))
+	indexes := make([]int, 0, len(keys))
+	for index, key := range keys {
+		record, ok := state.GetCaseHistoryRecord(key.UserID, key.RecordID)
+		if !ok {
+			errs[index] = service.DeleteHistoryVector(key.RecordID, key.UserID)
+			continue
+		}
+		inputs = append(inputs, user_history_index.ArchiveInput{
+			RecordID:    record.RecordID,
+			UserID:      record.UserID,
+			Title:       record.Title,
+			CaseSummary: record.CaseSummary,
+			ScamType:    record.ScamType,
+			CreatedAt:   record.CreatedAt,
+		})
+		indexes = append(indexes, index)
+	}
+	for offset, err := range service.StageHistoryVectors(ctx, inputs, model) {
+		errs[indexes[offset]] = err
+	}
+	return errs
+}
+
+func (userHistoryIndexStore) Count(model string) (int64, int64, error) {
+	return user_history_index.DefaultService().CountHistoryVectors(model)
+}
+
+func (userHistoryIndexStore) Promote(model string) (int64, error) {
+	return user_history_index.DefaultService().PromoteStagedHistoryVectors(model)
+}
+
+func (userHistoryIndexStore) ClearStaged() error {
+	return user_history_index.DefaultService().ClearStagedHistoryVectors()
+}
+
+type embeddingModelRegistry struct{}
+
+func (embeddingModelRegistry) ActiveModel() string {
+	return embedding.ActiveModel()
+}
+
+func (embeddingModelRegistry) SetActiveModel(model string) {
+	embedding.SetActiveModel(model)
+}
+
+func (embeddingModelRegistry) SetMigrationTargetModel(model string) {
+	embedding.SetMigrationTargetModel(model)
+}
+
+func (embeddingModelRegistry) ConfiguredModel() (string, error) {
+	return embedding.ConfiguredModel()
+}
@@ -0,0 +1,314 @@
+package migration_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"antifraud/internal/modules/multi_agent/adapters/outbound/case_library"
+	"antifraud/internal/modules/multi_agent/adapters/outbound/user_history_index"
+	"antifraud/internal/modules/multi_agent/application/migration"
+	"antifraud/internal/modules/multi_agent/test/testsupport"
+)
+
+type fakeCaseStore struct {
+	mu       sync.Mutex
+	sources  []case_library.HistoricalCaseEmbeddingSource
+	failOnce map[string]bool
+	block    chan struct{}
+	promoted string
+	cleared  int
+}
+
+func (f *fakeCaseStore) ListSources(afterID uint, limit int) ([]case_library.HistoricalCaseEmbeddingSource, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	page := []case_library.HistoricalCaseEmbeddingSource{}
+	for _, source := range f.sources {
+		if source.ID > afterID && len(page) < limit {
+			page = append(page, source)
+		}
+	}
+	return page, nil
+}
+
+func (f *fakeCaseStore) Stage(ctx context.Context, sources []case_library.HistoricalCaseEmbeddingSource, model string) []error {
+	errs := make([]error, len(sources))
+	if f.block != nil {
+		select {
+		case <-f.block:
+		case <-ctx.Done():
+			for index := range errs {
+				errs[index] = ctx.Err()
+			}
+			return errs
+		}
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for position, source := range sources {
+		if f.failOnce[source.CaseID] {
+			delete(f.failOnce, source.CaseID)
+			errs[position] = errors.New("embedding provider timeout")
+			continue
+		}
+		for index := range f.sources {
+			if f.sources[index].CaseID == source.CaseID {
+				f.sources[index].StagedEmbeddingModel = model
+			}
+		}
+	}
+	return errs
+}
+
+func (f *fakeCaseStore) Count(model string) (int64, int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	ready := int64(0)
+	for _, source := range f.sources {
+		if source.EmbeddingModel == model || source.StagedEmbeddingModel == model {
+			ready++
+		}
+	}
+	return int64(len(f.sources)), ready, nil
+}
+
+func (f *fakeCaseStore) Promote(model string) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	promoted := int64(0)
+	for index := range f.sources {
+		if f.sources[index].StagedEmbeddingModel == model {
+			f.sources[index].EmbeddingModel = model
+			f.sources[index].StagedEmbeddingModel = ""
+			promoted++
+		}
+	}
+	f.promoted = model
+	return promoted, nil
+}
+
+func (f *fakeCaseStore) ClearStaged() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for index := range f.sources {
+		f.sources[index].StagedEmbeddingModel = ""
+	}
+	f.cleared++
+	return nil
+}
+
+func (f *fakeCaseStore) DominantModel() (string, error) {
+	return "embed-v1", nil
+}
+
+type fakeHistoryStore struct {
+	mu       sync.Mutex
+	keys     []user_history_index.HistoryVectorKey
+	promoted string
+}
+
+func (f *fakeHistoryStore) ListKeys(afterUserID string, afterRecordID string, limit int) ([]user_history_index.HistoryVectorKey, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	page := []user_history_index.HistoryVectorKey{}
+	for _, key := range f.keys {
+		after := key.UserID > afterUserID || (key.UserID == afterUserID && key.RecordID > afterRecordID)
+		if after && len(page) < limit {
+			page = append(page, key)
+		}
+	}
+	return page, nil
+}
+
+func (f *fakeHistoryStore) Stage(_ context.Context, keys []user_history_index.HistoryVectorKey, model string) []error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, key := range keys {
+		for index := range f.keys {
+			if f.keys[index].RecordID == key.RecordID {
+				f.keys[index].StagedEmbeddingModel = model
+			}
+		}
+	}
+	return make([]error, len(keys))
+}
+
+func (f *fakeHistoryStore) Count(model string) (int64, int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	ready := int64(0)
+	for _, key := range f.keys {
+		if key.EmbeddingModel == model || key.StagedEmbeddingModel == model {
+			ready++
+		}
+	}
+	return int64(len(f.keys)), ready, nil
+}
+
+func (f *fakeHistoryStore) Promote(model string) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.promoted = model
+	return int64(len(f.keys)), nil
+}
+
+func (f *fakeHistoryStore) ClearStaged() error {
+	return nil
+}
+
+type fakeModelRegistry struct {
+	mu         sync.Mutex
+	active     string
+	target     string
+	configured string
+}
+
+func (f *fakeModelRegistry) ActiveModel() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.active
+}
+
+func (f *fakeModelRegistry) SetActiveModel(model string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.active = model
+}
+
+func (f *fakeModelRegistry) SetMigrationTargetModel(model string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.target = model
+}
+
+func (f *fakeModelRegistry) targetModel() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.target
+}
+
+func (f *fakeModelRegistry) ConfiguredModel() (string, error) {
+	return f.configured, nil
+}
+
+func waitForJobStatus(t *testing.T, service *migration.Service, jobID string, status string) migration.Job {
+	return testsupport.WaitForStatus(t,
+		func() (migration.Job, error) { return service.Get(jobID) },
+		func(job migration.Job) string { return job.Status },
+		status)
+}
+
+func TestEmbeddingMigration_ReindexesRetriesAndCutsOver(t *testing.T) {
+	testsupport.SetupMainDB(t)
+
+	cases := &fakeCaseStore{
+		sources: []case_library.HistoricalCaseEmbeddingSource{
+			{ID: 1, CaseID: "HCASE-1", EmbeddingModel: "embed-v1"},
+			{ID: 2, CaseID: "HCASE-2", EmbeddingModel: "embed-v1"},
+			{ID: 3, CaseID: "HCASE-3", EmbeddingModel: "embed-v1", StagedEmbeddingModel: "embed-v2"},
+		},
+		failOnce: map[string]bool{"HCASE-2": true},
+	}
+	histories := &fakeHistoryStore{
+		keys: []user_history_index.HistoryVectorKey{
+			{UserID: "1", RecordID: "REC-1", EmbeddingModel: "embed-v1"},
+			{UserID: "2", RecordID: "REC-2", EmbeddingModel: "embed-v1"},
+		},
+	}
+	models := &fakeModelRegistry{configured: "embed-v2"}
+	service := migration.NewService(cases, histories, models)
+
+	active, err := service.InitializeActiveModel()
+	if err != nil || active != "embed-v1" {
+		t.Fatalf("expected active model pinned to library model, got %q err=%v", active, err)
+	}
+	if _, err := service.Start("admin", "embed-v1", 0); !errors.Is(err, migration.ErrInvalidTargetModel) {
+		t.Fatalf("expected invalid target for active model, got %v", err)
+	}
+
+	job, err := service.Start("admin", "", 2)
+	if err != nil {
+		t.Fatalf("start migration failed: %v", err)
+	}
+	if job.TargetModel != "embed-v2" || job.SourceModel != "embed-v1" || job.BatchSize != 2 {
+		t.Fatalf("unexpected job: %+v", job)
+	}
+
+	finished := waitForJobStatus(t, service, job.JobID, migration.JobStatusCompleted)
+	if finished.Phase != migration.PhaseDone || finished.Sweep != 2 {
+		t.Fatalf("expected a retry sweep before cutover, got %+v", finished)
+	}
+	if finished.HistoricalDone != 3 || finished.HistoricalTotal != 3 || finished.UserHistoryDone != 2 {
+		t.Fatalf("unexpected progress counters: %+v", finished)
+	}
+	if cases.promoted != "embed-v2" || histories.promoted != "embed-v2" {
+		t.Fatalf("expected both stores promoted, cases=%q histories=%q", cases.promoted, histories.promoted)
+	}
+	if models.ActiveModel() != "embed-v2" || models.targetModel() != "" {
+		t.Fatalf("expected active model switched and target cleared, active=%q target=%q", models.ActiveModel(), models.targetModel())
+	}
+
+	active, err = service.InitializeActiveModel()
+	if err != nil || active != "embed-v2" {
+		t.Fatalf("expected completed migration to win on restart, got %q err=%v", active, err)
+	}
+}
+
+func TestEmbeddingMigration_CancelKeepsActiveModelAndDiscardsStaged(t *testing.T) {
+	testsupport.SetupMainDB(t)
+
+	cases := &fakeCaseStore{
+		sources: []case_library.HistoricalCaseEmbeddingSource{
+			{ID: 1, CaseID: "HCASE-1", EmbeddingModel: "embed-v1"},
+		},
+		block: make(chan struct{}),
+	}
+	histories := &fakeHistoryStore{}
+	models := &fakeModelRegistry{active: "embed-v1", configured: "embed-v2"}
+	service := migration.NewService(cases, histories, models)
+
+	job, err := service.Start("admin", "embed-v2", 0)
+	if err != nil {
+		t.Fatalf("start migration failed: %v", err)
+	}
+	if models.targetModel() != "embed-v2" {
+		t.Fatalf("expected dual-write target set while running, got %q", models.targetModel())
+	}
+	if _, err := service.Start("admin", "embed-v3", 0); !errors.Is(err, migration.ErrMigrationRunning) {
+		t.Fatalf("expected concurrent start to be rejected, got %v", err)
+	}
+
+	cancelled, err := service.Cancel(job.JobID)
+	if err != nil {
+		t.Fatalf("cancel migration failed: %v", err)
+	}
+	if cancelled.Status != migration.JobStatusCancelled || cancelled.FinishedAt == nil {
+		t.Fatalf("unexpected cancelled job: %+v", cancelled)
+	}
+	if _, err := service.Cancel(job.JobID); !errors.Is(err, migration.ErrJobNotRunning) {
+		t.Fatalf("expected second cancel to fail, got %v", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		cases.mu.Lock()
+		cleared := cases.cleared
+		cases.mu.Unlock()
+		if cleared > 0 && models.targetModel() == "" {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if models.targetModel() != "" || models.ActiveModel() != "embed-v1" || cases.promoted != "" {
+		t.Fatalf("cancel must not switch models: active=%q target=%q promoted=%q", models.ActiveModel(), models.targetModel(), cases.promoted)
+	}
+	if cases.cleared == 0 {
+		t.Fatal("expected staged vectors discarded after cancel")
+	}
+	if _, err := service.Get("EMBMIG-MISSING"); !errors.Is(err, migration.ErrJobNotFound) {
+		t.Fatalf("expected not found, got %v", err)
+	}
+}
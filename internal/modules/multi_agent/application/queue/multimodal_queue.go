@@ -1,10 +1,15 @@
 package queue
 
 import (
+	"context"
+	"fmt"
 	"strings"
 
+	"antifraud/internal/modules/multi_agent/adapters/outbound/blobstore"
+	"antifraud/internal/modules/multi_agent/adapters/outbound/mediastore"
 	"antifraud/internal/modules/multi_agent/adapters/outbound/state"
 	"antifraud/internal/modules/multi_agent/application"
+	appcfg "antifraud/internal/platform/config"
 )
 
 type EnqueueRequest struct {
@@ -12,23 +17,103 @@ type EnqueueRequest struct {
 	Videos []string
 	Audios []string
 	Images []string
+	// Inputs 按模态名提交通过 ModalityAnalyzer 注册的输入；内置模态的 key 会并入对应专用字段。
+	Inputs map[string][]string
+	// MediaIDs 按模态名引用已上传到媒体存储的文件（image/video/audio 或已注册的扩展模态），
+	// 入队前校验媒体归属，并换成 blob:<sha256> 引用写入任务载荷。
+	MediaIDs map[string][]string
 }
 
-// EnqueueMultimodalTask 创建任务并异步触发处理。
-func EnqueueMultimodalTask(userID string, request EnqueueRequest) (state.TaskRecord, error) {
+// StartMultimodalTaskWorkers 启动多模态任务工作池，并回收上次进程遗留的未完成任务。
+func StartMultimodalTaskWorkers(ctx context.Context, cfg appcfg.TaskQueueConfig) error {
+	return application.DefaultTaskService().StartWorkers(ctx, application.TaskQueueOptionsFromConfig(cfg))
+}
+
+// EnqueueMultimodalTask 创建任务并写入持久化队列，由工作池异步处理。
+// ctx 仅约束入队前的媒体预处理，任务入队后的分析生命周期由工作池管理。
+func EnqueueMultimodalTask(ctx context.Context, userID string, request EnqueueRequest) (state.TaskRecord, error) {
 	payload := state.TaskPayload{
 		Text:   strings.TrimSpace(request.Text),
 		Videos: append([]string{}, request.Videos...),
 		Audios: append([]string{}, request.Audios...),
 		Images: append([]string{}, request.Images...),
 	}
-	normalizedPayload, err := application.NormalizeTaskPayload(payload)
+	for kind, items := range request.Inputs {
+		modality := strings.TrimSpace(kind)
+		payload.SetModalityInputs(modality, append(payload.ModalityInputs(modality), items...))
+	}
+	for kind, mediaIDs := range request.MediaIDs {
+		modality := strings.TrimSpace(kind)
+		refs := payload.ModalityInputs(modality)
+		for _, mediaID := range mediaIDs {
+			if trimmed := strings.TrimSpace(mediaID); trimmed != "" {
+				refs = append(refs, mediastore.MediaRef(trimmed))
+			}
+		}
+		payload.SetModalityInputs(modality, refs)
+	}
+	payload, err := resolveMediaReferences(userID, payload)
+	if err != nil {
+		return state.TaskRecord{}, err
+	}
+	normalizedPayload, err := application.NormalizeTaskPayload(ctx, payload)
 	if err != nil {
 		return state.TaskRecord{}, err
 	}
 	return application.DefaultTaskService().EnqueueTask(userID, normalizedPayload)
 }
 
+// resolveMediaReferences 确认输入中引用的每个媒体都属于提交用户（防止借引用读取他人上传的文件），
+// 再把 media:<id> 换成媒体内容在 blob 存储中的 blob:<sha256> 引用，整个过程不读取媒体内容。
+func resolveMediaReferences(userID string, payload state.TaskPayload) (state.TaskPayload, error) {
+	store := mediastore.DefaultStore()
+	resolve := func(items []string) ([]string, error) {
+		if len(items) == 0 {
+			return items, nil
+		}
+		resolved := make([]string, 0, len(items))
+		for _, item := range items {
+			// blob 引用只由服务端生成，客户端直接提交会绕过归属校验。
+			if _, ok := blobstore.ParseRef(item); ok {
+				return nil, fmt.Errorf("%w: 不支持直接提交 blob 引用，请使用 media 引用已上传的文件", mediastore.ErrMediaNotFound)
+			}
+			mediaID, ok := mediastore.ParseMediaRef(item)
+			if !ok {
+				resolved = append(resolved, item)
+				continue
+			}
+			media, err := store.Get(userID, mediaID)
+			if err != nil {
+				return nil, fmt.Errorf("媒体 %s 不可用: %w", mediaID, err)
+			}
+			resolved = append(resolved, media.BlobRef())
+		}
+		return resolved, nil
+	}
+
+	var err error
+	if payload.Videos, err = resolve(payload.Videos); err != nil {
+		return state.TaskPayload{}, err
+	}
+	if payload.Audios, err = resolve(payload.Audios); err != nil {
+		return state.TaskPayload{}, err
+	}
+	if payload.Images, err = resolve(payload.Images); err != nil {
+		return state.TaskPayload{}, err
+	}
+	for kind, items := range payload.ExtraInputs {
+		if payload.ExtraInputs[kind], err = resolve(items); err != nil {
+			return state.TaskPayload{}, err
+		}
+	}
+	return payload, nil
+}
+
+// CancelMultimodalTask 取消当前用户的进行中任务，返回 false 表示任务不存在或已结束。
+func CancelMultimodalTask(userID string, taskID string) (state.TaskRecord, bool, error) {
+	return application.DefaultTaskService().CancelTask(userID, taskID)
+}
+
 // GetUserTaskState 返回用户任务视图（进行中 + 历史）。
 func GetUserTaskState(userID string) state.UserStateView {
 	return application.DefaultTaskService().GetUserTaskState(userID)
@@ -0,0 +1,342 @@
+package retention
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"antifraud/internal/modules/multi_agent/adapters/outbound/blobstore"
+	"antifraud/internal/modules/multi_agent/adapters/outbound/state"
+	"antifraud/internal/platform/database"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrExportNotFound         = errors.New("user data export not found")
+	ErrExportExpired          = errors.New("user data export expired")
+	ErrExportAlreadyUsed      = errors.New("user data export already used for purge")
+	ErrDataChangedAfterExport = errors.New("user data changed after export")
+)
+
+// exportDB 返回导出凭证表所在的主业务库。
+var exportDB = func() *gorm.DB { return database.DB }
+
+// exportEntity 记录一次成功的用户数据导出，作为“导出后清除”的凭证与审计记录，不保存导出内容本身。
+type exportEntity struct {
+	ID            uint       `gorm:"primaryKey;autoIncrement"`
+	ExportID      string     `gorm:"size:64;uniqueIndex;not null"`
+	UserID        string     `gorm:"size:64;index;not null"`
+	HistoryCount  int        `gorm:"not null;default:0"`
+	TaskCount     int        `gorm:"not null;default:0"`
+	MediaCount    int        `gorm:"not null;default:0"`
+	ExpiresAt     time.Time  `gorm:"not null"`
+	PurgedAt      *time.Time `gorm:""`
+	HistoryPurged int        `gorm:"not null;default:0"`
+	TasksPurged   int        `gorm:"not null;default:0"`
+	CreatedAt     time.Time  `gorm:"index;not null"`
+}
+
+func (exportEntity) TableName() string {
+	return "user_data_exports"
+}
+
+func init() {
+	database.RegisterMainDBSchemaInitializer("user_data_export", initExportSchema)
+}
+
+func initExportSchema(db *gorm.DB) error {
+	if db == nil {
+		return fmt.Errorf("user data export schema db is nil")
+	}
+	return db.AutoMigrate(&exportEntity{})
+}
+
+// Export 是一次用户数据导出的凭证。CreatedAt 为数据快照时间，清除时据此确认导出后数据未变化；
+// PurgedAt 非空表示该凭证已用于清除，HistoryPurged/TasksPurged 为实际删除的记录数。
+type Export struct {
+	ExportID      string
+	UserID        string
+	HistoryCount  int
+	TaskCount     int
+	MediaCount    int
+	CreatedAt     time.Time
+	ExpiresAt     time.Time
+	PurgedAt      *time.Time
+	HistoryPurged int
+	TasksPurged   int
+}
+
+type exportManifest struct {
+	ExportID     string          `json:"export_id"`
+	UserID       string          `json:"user_id"`
+	ExportedAt   string          `json:"exported_at"`
+	ExpiresAt    string          `json:"purge_token_expires_at"`
+	HistoryCount int             `json:"history_count"`
+	TaskCount    int             `json:"task_count"`
+	Media        []exportedMedia `json:"media"`
+	MissingMedia []string        `json:"missing_media,omitempty"`
+	Retention    exportRetention `json:"retention"`
+}
+
+type exportedMedia struct {
+	Key      string `json:"key"`
+	Path     string `json:"path"`
+	MimeType string `json:"mime_type"`
+	Size     int64  `json:"size"`
+}
+
+// exportRetention 向用户说明导出时生效的保留策略，天数为 0 表示永久保留。
+type exportRetention struct {
+	MediaDays  int            `json:"media_days"`
+	RecordDays map[string]int `json:"record_days"`
+}
+
+// NewExport 为用户生成一次导出凭证，now 作为数据快照时间；凭证在 WriteExport 成功后才落库生效。
+func (s *Service) NewExport(userID string, now time.Time) Export {
+	return Export{
+		ExportID:  newExportID(),
+		UserID:    strings.TrimSpace(userID),
+		CreatedAt: now,
+		ExpiresAt: now.Add(s.options.ExportTTL),
+	}
+}
+
+// WriteExport 把用户全部历史案件、未归档任务及其引用的原始媒体以 zip 流式写入 w，成功后保存导出凭证。
+// 压缩包结构：
+// 1) manifest.json：导出概要、保留策略与媒体清单；
+// 2) history.jsonl / tasks.jsonl：每行一条记录，脱敏占位符按任务映射还原为原文，载荷中的媒体保持 blob:<sha256> 引用；
+// 3) media/<sha256>：引用对应的原始媒体，blob 已被清理的摘要列入 missing_media。
+// 写出失败（如客户端断开）时不保存凭证，用户需重新导出后才能清除。
+func (s *Service) WriteExport(ctx context.Context, export Export, w io.Writer) (Export, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	db := exportDB()
+	if db == nil {
+		return export, fmt.Errorf("user data export db is nil")
+	}
+
+	archive := zip.NewWriter(w)
+	keys := map[string]struct{}{}
+	collect := func(payload state.TaskPayload) {
+		for _, key := range state.PayloadBlobKeys(payload) {
+			keys[key] = struct{}{}
+		}
+	}
+
+	historyWriter, err := archive.Create("history.jsonl")
+	if err != nil {
+		return export, err
+	}
+	historyEncoder := json.NewEncoder(historyWriter)
+	historyEncoder.SetEscapeHTML(false)
+	export.HistoryCount = 0
+	if err := state.StreamUserHistory(export.UserID, func(record state.CaseHistoryRecord) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		collect(record.Payload)
+		export.HistoryCount++
+		return historyEncoder.Encode(state.RestoreCaseHistoryPII(record))
+	}); err != nil {
+		return export, fmt.Errorf("export history failed: %w", err)
+	}
+
+	tasks, err := state.ListUserPendingTasks(export.UserID)
+	if err != nil {
+		return export, fmt.Errorf("export tasks failed: %w", err)
+	}
+	taskWriter, err := archive.Create("tasks.jsonl")
+	if err != nil {
+		return export, err
+	}
+	taskEncoder := json.NewEncoder(taskWriter)
+	taskEncoder.SetEscapeHTML(false)
+	for _, task := range tasks {
+		collect(task.Payload)
+		if err := taskEncoder.Encode(state.RestoreTaskPII(task)); err != nil {
+			return export, err
+		}
+	}
+	export.TaskCount = len(tasks)
+
+	sortedKeys := make([]string, 0, len(keys))
+	for key := range keys {
+		sortedKeys = append(sortedKeys, key)
+	}
+	sort.Strings(sortedKeys)
+	manifest := exportManifest{
+		ExportID:     export.ExportID,
+		UserID:       export.UserID,
+		ExportedAt:   export.CreatedAt.Format(time.RFC3339),
+		ExpiresAt:    export.ExpiresAt.Format(time.RFC3339),
+		HistoryCount: export.HistoryCount,
+		TaskCount:    export.TaskCount,
+		Media:        make([]exportedMedia, 0, len(sortedKeys)),
+		Retention:    s.retentionSummary(),
+	}
+	store := blobstore.DefaultStore()
+	for _, key := range sortedKeys {
+		if err := ctx.Err(); err != nil {
+			return export, err
+		}
+		item, err := writeExportMedia(ctx, archive, store, key)
+		if errors.Is(err, blobstore.ErrBlobNotFound) {
+			manifest.MissingMedia = append(manifest.MissingMedia, key)
+			continue
+		}
+		if err != nil {
+			return export, fmt.Errorf("export media %s failed: %w", key, err)
+		}
+		manifest.Media = append(manifest.Media, item)
+	}
+	export.MediaCount = len(manifest.Media)
+
+	manifestWriter, err := archive.Create("manifest.json")
+	if err != nil {
+		return export, err
+	}
+	manifestEncoder := json.NewEncoder(manifestWriter)
+	manifestEncoder.SetEscapeHTML(false)
+	manifestEncoder.SetIndent("", "  ")
+	if err := manifestEncoder.Encode(manifest); err != nil {
+		return export, err
+	}
+	if err := archive.Close(); err != nil {
+		return export, err
+	}
+
+	entity := exportEntity{
+		ExportID:     export.ExportID,
+		UserID:       export.UserID,
+		HistoryCount: export.HistoryCount,
+		TaskCount:    export.TaskCount,
+		MediaCount:   export.MediaCount,
+		ExpiresAt:    export.ExpiresAt,
+		CreatedAt:    export.CreatedAt,
+	}
+	if err := db.Create(&entity).Error; err != nil {
+		return export, fmt.Errorf("save user data export failed: %w", err)
+	}
+	return export, nil
+}
+
+// writeExportMedia 把一个 blob 以不压缩方式写入 media/<sha256>，媒体本身多已压缩，再次压缩收益很小。
+func writeExportMedia(ctx context.Context, archive *zip.Writer, store *blobstore.Store, key string) (exportedMedia, error) {
+	blob, reader, err := store.Open(ctx, key)
+	if err != nil {
+		return exportedMedia{}, err
+	}
+	defer reader.Close()
+
+	path := "media/" + key
+	writer, err := archive.CreateHeader(&zip.FileHeader{Name: path, Method: zip.Store, Modified: blob.CreatedAt})
+	if err != nil {
+		return exportedMedia{}, err
+	}
+	if _, err := io.Copy(writer, reader); err != nil {
+		return exportedMedia{}, err
+	}
+	return exportedMedia{Key: key, Path: path, MimeType: blob.MIME, Size: blob.Size}, nil
+}
+
+// PurgeUserData 凭 WriteExport 生成的导出凭证清除用户的全部任务、历史案件、进度事件与媒体引用。
+// 说明：
+// 1) 凭证须属于该用户、未过期且未使用过；
+// 2) 导出后任务或历史有新增、更新时返回 ErrDataChangedAfterExport，避免清除用户未导出的数据；
+// 3) 仍有执行中任务时透传 state.ErrUserTasksProcessing；
+// 4) 清除成功后凭证标记为已使用并保留删除数量，作为清除记录。
+func (s *Service) PurgeUserData(userID string, exportID string, now time.Time) (Export, error) {
+	db := exportDB()
+	if db == nil {
+		return Export{}, fmt.Errorf("user data export db is nil")
+	}
+	uid := strings.TrimSpace(userID)
+	eid := strings.TrimSpace(exportID)
+	if eid == "" {
+		return Export{}, ErrExportNotFound
+	}
+
+	var entity exportEntity
+	query := db.Where("export_id = ? AND user_id = ?", eid, uid).Limit(1).Find(&entity)
+	if query.Error != nil {
+		return Export{}, query.Error
+	}
+	if query.RowsAffected == 0 {
+		return Export{}, ErrExportNotFound
+	}
+	if entity.PurgedAt != nil {
+		return toExport(entity), ErrExportAlreadyUsed
+	}
+	if now.After(entity.ExpiresAt) {
+		return toExport(entity), ErrExportExpired
+	}
+	changed, err := state.UserDataChangedSince(uid, entity.CreatedAt)
+	if err != nil {
+		return toExport(entity), err
+	}
+	if changed {
+		return toExport(entity), ErrDataChangedAfterExport
+	}
+
+	result, err := state.PurgeUserData(uid)
+	if err != nil {
+		return toExport(entity), err
+	}
+	purgedAt := now
+	entity.PurgedAt = &purgedAt
+	entity.HistoryPurged = result.HistoryDeleted
+	entity.TasksPurged = result.TasksDeleted
+	if err := db.Model(&exportEntity{}).Where("id = ?", entity.ID).Updates(map[string]interface{}{
+		"purged_at":      purgedAt,
+		"history_purged": result.HistoryDeleted,
+		"tasks_purged":   result.TasksDeleted,
+	}).Error; err != nil {
+		log.Printf("[retention] mark user data export purged failed: export=%s err=%v", eid, err)
+	}
+	return toExport(entity), nil
+}
+
+func (s *Service) retentionSummary() exportRetention {
+	summary := exportRetention{
+		MediaDays:  int(s.options.MediaRetention / (24 * time.Hour)),
+		RecordDays: map[string]int{},
+	}
+	for _, level := range []string{RiskLevelLow, RiskLevelMedium, RiskLevelHigh} {
+		summary.RecordDays[level] = int(s.options.RecordRetention[level] / (24 * time.Hour))
+	}
+	return summary
+}
+
+func toExport(entity exportEntity) Export {
+	return Export{
+		ExportID:      entity.ExportID,
+		UserID:        entity.UserID,
+		HistoryCount:  entity.HistoryCount,
+		TaskCount:     entity.TaskCount,
+		MediaCount:    entity.MediaCount,
+		CreatedAt:     entity.CreatedAt,
+		ExpiresAt:     entity.ExpiresAt,
+		PurgedAt:      entity.PurgedAt,
+		HistoryPurged: entity.HistoryPurged,
+		TasksPurged:   entity.TasksPurged,
+	}
+}
+
+func newExportID() string {
+	buf := make([]byte, 12)
+	if _, err := rand.Read(buf); err != nil {
+		return fmt.Sprintf("EXPORT-%d", time.Now().UnixNano())
+	}
+	return "EXPORT-" + strings.ToUpper(hex.EncodeToString(buf))
+}
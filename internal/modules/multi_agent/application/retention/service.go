@@ -0,0 +1,199 @@
+package retention
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"antifraud/internal/modules/multi_agent/adapters/outbound/state"
+	appcfg "antifraud/internal/platform/config"
+)
+
+// 风险等级取值与 history_cases.risk_level 一致。
+const (
+	RiskLevelLow    = "低"
+	RiskLevelMedium = "中"
+	RiskLevelHigh   = "高"
+)
+
+// purgeBatchSize 是单轮清理每批处理的记录数，批次之间不持有事务，避免长时间锁表。
+const purgeBatchSize = 100
+
+// Options 是数据保留策略：各保留时长 <=0 表示永久保留，Interval <=0 时 Start 不启动后台清理。
+// RecordRetention 以风险等级（低/中/高）为键，未列出的等级永久保留。
+type Options struct {
+	MediaRetention  time.Duration
+	RecordRetention map[string]time.Duration
+	Interval        time.Duration
+	ExportTTL       time.Duration
+}
+
+// OptionsFromConfig 将配置文件中的 data_retention 转换为保留策略，负数天数视为永久保留。
+func OptionsFromConfig(cfg appcfg.DataRetentionConfig) Options {
+	return Options{
+		MediaRetention: days(cfg.MediaDays),
+		RecordRetention: map[string]time.Duration{
+			RiskLevelLow:    days(cfg.LowRiskRecordDays),
+			RiskLevelMedium: days(cfg.MediumRiskRecordDays),
+			RiskLevelHigh:   days(cfg.HighRiskRecordDays),
+		},
+		Interval:  time.Duration(cfg.PurgeIntervalMinutes) * time.Minute,
+		ExportTTL: time.Duration(cfg.ExportTTLMinutes) * time.Minute,
+	}
+}
+
+func days(value int) time.Duration {
+	if value <= 0 {
+		return 0
+	}
+	return time.Duration(value) * 24 * time.Hour
+}
+
+// PurgeReport 汇总一轮保留策略清理的结果。
+type PurgeReport struct {
+	MediaPurged   int
+	RecordsPurged map[string]int
+}
+
+// Service 执行数据保留策略：按周期清除到期的原始媒体与历史记录，并提供用户“导出后清除”流程。
+type Service struct {
+	options Options
+
+	// runMu 串行化清理轮次，避免后台任务与手动触发并发处理同一批记录。
+	runMu sync.Mutex
+}
+
+var (
+	defaultServiceMu sync.Mutex
+	defaultService   *Service
+)
+
+// NewService 创建保留策略服务，ExportTTL 未设置时默认 30 分钟。
+func NewService(options Options) *Service {
+	if options.ExportTTL <= 0 {
+		options.ExportTTL = 30 * time.Minute
+	}
+	records := make(map[string]time.Duration, len(options.RecordRetention))
+	for level, retention := range options.RecordRetention {
+		records[level] = retention
+	}
+	options.RecordRetention = records
+	return &Service{options: options}
+}
+
+// DefaultService 返回按 data_retention 配置创建的进程级保留策略服务。
+func DefaultService() *Service {
+	defaultServiceMu.Lock()
+	defer defaultServiceMu.Unlock()
+	if defaultService == nil {
+		options := OptionsFromConfig(appcfg.DataRetentionConfig{})
+		if cfg, err := appcfg.LoadConfig("internal/platform/config/config.json"); err == nil && cfg != nil {
+			options = OptionsFromConfig(cfg.DataRetention)
+		}
+		defaultService = NewService(options)
+	}
+	return defaultService
+}
+
+// SetDefaultService 替换进程级保留策略服务并返回原服务，供测试使用。
+func SetDefaultService(service *Service) *Service {
+	defaultServiceMu.Lock()
+	defer defaultServiceMu.Unlock()
+	previous := defaultService
+	defaultService = service
+	return previous
+}
+
+// Options 返回服务当前生效的保留策略。
+func (s *Service) Options() Options {
+	return s.options
+}
+
+// RunOnce 按 now 计算各项截止时间并清理到期数据：先清除原始媒体，再按风险等级删除整条记录。
+// 每项分批处理直到没有到期数据；ctx 取消时在批次之间提前结束。
+func (s *Service) RunOnce(ctx context.Context, now time.Time) (PurgeReport, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	s.runMu.Lock()
+	defer s.runMu.Unlock()
+
+	report := PurgeReport{RecordsPurged: map[string]int{}}
+	if s.options.MediaRetention > 0 {
+		purged, err := drain(ctx, func() (int, error) {
+			return state.PurgeHistoryMedia(now.Add(-s.options.MediaRetention), purgeBatchSize)
+		})
+		report.MediaPurged = purged
+		if err != nil {
+			return report, fmt.Errorf("purge history media failed: %w", err)
+		}
+	}
+	for _, level := range []string{RiskLevelLow, RiskLevelMedium, RiskLevelHigh} {
+		retention := s.options.RecordRetention[level]
+		if retention <= 0 {
+			continue
+		}
+		deleted, err := drain(ctx, func() (int, error) {
+			return state.DeleteExpiredHistory(level, now.Add(-retention), purgeBatchSize)
+		})
+		report.RecordsPurged[level] = deleted
+		if err != nil {
+			return report, fmt.Errorf("purge %s risk history failed: %w", level, err)
+		}
+	}
+	return report, nil
+}
+
+// drain 重复执行 batch 直到某批未处理满（没有更多到期数据，或剩余记录本轮处理失败留待下一轮）。
+func drain(ctx context.Context, batch func() (int, error)) (int, error) {
+	total := 0
+	for {
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
+		processed, err := batch()
+		total += processed
+		if err != nil || processed < purgeBatchSize {
+			return total, err
+		}
+	}
+}
+
+// Start 立即并按 Interval 周期执行保留策略清理，ctx 取消后停止；Interval <=0 时不启动。
+func (s *Service) Start(ctx context.Context) {
+	if s.options.Interval <= 0 {
+		log.Printf("[retention] background purge disabled")
+		return
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	go func() {
+		ticker := time.NewTicker(s.options.Interval)
+		defer ticker.Stop()
+		for {
+			report, err := s.RunOnce(ctx, time.Now())
+			if err != nil && ctx.Err() == nil {
+				log.Printf("[retention] purge failed: %v", err)
+			}
+			if report.MediaPurged > 0 || report.total() > 0 {
+				log.Printf("[retention] purged media=%d records=%v", report.MediaPurged, report.RecordsPurged)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (r PurgeReport) total() int {
+	total := 0
+	for _, count := range r.RecordsPurged {
+		total += count
+	}
+	return total
+}
@@ -0,0 +1,207 @@
+package retention_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"antifraud/internal/modules/multi_agent/adapters/outbound/blobstore"
+	"antifraud/internal/modules/multi_agent/adapters/outbound/state"
+	statemodel "antifraud/internal/modules/multi_agent/adapters/outbound/state/model"
+	"antifraud/internal/modules/multi_agent/application/retention"
+	"antifraud/internal/modules/multi_agent/test/testsupport"
+	appcfg "antifraud/internal/platform/config"
+
+	"gorm.io/gorm"
+)
+
+func setupRetentionDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db := testsupport.SetupMainDB(t)
+	backend, err := blobstore.NewLocalBackend(t.TempDir())
+	if err != nil {
+		t.Fatalf("create blob backend failed: %v", err)
+	}
+	previous := blobstore.SetDefaultStore(blobstore.NewStore(backend, blobstore.Options{}))
+	t.Cleanup(func() {
+		blobstore.SetDefaultStore(previous)
+	})
+	return db
+}
+
+func dataURL(mimeType string, raw string) string {
+	return "data:" + mimeType + ";base64," + base64.StdEncoding.EncodeToString([]byte(raw))
+}
+
+func addHistory(t *testing.T, db *gorm.DB, userID string, riskLevel string, age time.Duration, payload state.TaskPayload) state.CaseHistoryRecord {
+	t.Helper()
+	record := state.AddCaseHistory(state.CaseHistoryRecord{
+		UserID:      userID,
+		Title:       riskLevel + "风险案件",
+		CaseSummary: "摘要",
+		RiskLevel:   riskLevel,
+		RiskScore:   50,
+		Payload:     payload,
+		Report:      "报告",
+	})
+	createdAt := time.Now().Add(-age)
+	if err := db.Model(&statemodel.HistoryCaseEntity{}).
+		Where("record_id = ?", record.RecordID).
+		Updates(map[string]interface{}{"created_at": createdAt, "updated_at": createdAt}).Error; err != nil {
+		t.Fatalf("backdate history failed: %v", err)
+	}
+	return record
+}
+
+func TestRunOnce_AppliesMediaAndPerRiskRecordRetention(t *testing.T) {
+	db := setupRetentionDB(t)
+	const day = 24 * time.Hour
+	low := addHistory(t, db, "u-1", "低", 20*day, state.TaskPayload{Text: "低风险"})
+	medium := addHistory(t, db, "u-1", "中", 2*day, state.TaskPayload{Images: []string{dataURL("image/png", "medium")}})
+	high := addHistory(t, db, "u-1", "高", 20*day, state.TaskPayload{Images: []string{dataURL("image/png", "high")}})
+	recent := addHistory(t, db, "u-1", "中", time.Hour, state.TaskPayload{Images: []string{dataURL("image/png", "recent")}})
+
+	service := retention.NewService(retention.OptionsFromConfig(appcfg.DataRetentionConfig{
+		MediaDays:            1,
+		LowRiskRecordDays:    10,
+		MediumRiskRecordDays: 30,
+		HighRiskRecordDays:   -1,
+	}))
+	report, err := service.RunOnce(context.Background(), time.Now())
+	if err != nil {
+		t.Fatalf("run retention failed: %v", err)
+	}
+	if report.MediaPurged != 2 || report.RecordsPurged[retention.RiskLevelLow] != 1 || report.RecordsPurged[retention.RiskLevelMedium] != 0 {
+		t.Fatalf("unexpected purge report: %+v", report)
+	}
+	if _, exists := report.RecordsPurged[retention.RiskLevelHigh]; exists {
+		t.Fatalf("expected high risk records kept forever, got %+v", report)
+	}
+	if _, exists := state.GetCaseHistoryRecord("u-1", low.RecordID); exists {
+		t.Fatal("expected expired low risk record deleted")
+	}
+	for _, record := range []state.CaseHistoryRecord{medium, high} {
+		kept, exists := state.GetCaseHistoryRecord("u-1", record.RecordID)
+		if !exists || len(kept.Payload.Images) != 0 || kept.MediaPurgedAt == nil || kept.Report != "报告" {
+			t.Fatalf("expected record %s kept without media, got exists=%t %+v", record.RecordID, exists, kept)
+		}
+	}
+	if kept, _ := state.GetCaseHistoryRecord("u-1", recent.RecordID); len(kept.Payload.Images) != 1 {
+		t.Fatalf("expected recent media kept, got %+v", kept.Payload)
+	}
+}
+
+func TestExportThenPurge_RequiresFreshUnusedExport(t *testing.T) {
+	db := setupRetentionDB(t)
+	record := addHistory(t, db, "u-export", "高", time.Hour, state.TaskPayload{
+		Text:   "转账截图",
+		Images: []string{dataURL("image/png", "evidence")},
+	})
+	other := addHistory(t, db, "u-other", "低", time.Hour, state.TaskPayload{Text: "他人"})
+	service := retention.NewService(retention.Options{ExportTTL: 10 * time.Minute})
+
+	var buffer bytes.Buffer
+	export, err := service.WriteExport(context.Background(), service.NewExport("u-export", time.Now()), &buffer)
+	if err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+	if export.HistoryCount != 1 || export.TaskCount != 0 || export.MediaCount != 1 {
+		t.Fatalf("unexpected export summary: %+v", export)
+	}
+	files := readZip(t, buffer.Bytes())
+	key := state.PayloadBlobKeys(record.Payload)[0]
+	if string(files["media/"+key]) != "evidence" {
+		t.Fatalf("expected media exported, got files %v", fileNames(files))
+	}
+	if !strings.Contains(string(files["history.jsonl"]), record.RecordID) || strings.Contains(string(files["history.jsonl"]), other.RecordID) {
+		t.Fatalf("expected only own history exported, got %s", files["history.jsonl"])
+	}
+	var manifest struct {
+		ExportID string `json:"export_id"`
+		Media    []struct {
+			Key      string `json:"key"`
+			MimeType string `json:"mime_type"`
+		} `json:"media"`
+	}
+	if err := json.Unmarshal(files["manifest.json"], &manifest); err != nil || manifest.ExportID != export.ExportID || len(manifest.Media) != 1 || manifest.Media[0].MimeType != "image/png" {
+		t.Fatalf("unexpected manifest: %s err=%v", files["manifest.json"], err)
+	}
+
+	if _, err := service.PurgeUserData("u-other", export.ExportID, time.Now()); !errors.Is(err, retention.ErrExportNotFound) {
+		t.Fatalf("expected export bound to its user, got %v", err)
+	}
+	if _, err := service.PurgeUserData("u-export", export.ExportID, time.Now().Add(time.Hour)); !errors.Is(err, retention.ErrExportExpired) {
+		t.Fatalf("expected expired export rejected, got %v", err)
+	}
+	time.Sleep(5 * time.Millisecond)
+	state.AddCaseHistory(state.CaseHistoryRecord{
+		UserID:    "u-export",
+		Title:     "导出后新增",
+		RiskLevel: "低",
+		RiskScore: 5,
+		Payload:   state.TaskPayload{Text: "new"},
+	})
+	if _, err := service.PurgeUserData("u-export", export.ExportID, time.Now()); !errors.Is(err, retention.ErrDataChangedAfterExport) {
+		t.Fatalf("expected data change after export to block purge, got %v", err)
+	}
+
+	buffer.Reset()
+	export, err = service.WriteExport(context.Background(), service.NewExport("u-export", time.Now()), &buffer)
+	if err != nil {
+		t.Fatalf("re-export failed: %v", err)
+	}
+	purged, err := service.PurgeUserData("u-export", export.ExportID, time.Now())
+	if err != nil {
+		t.Fatalf("purge failed: %v", err)
+	}
+	if purged.HistoryPurged != 2 || purged.PurgedAt == nil {
+		t.Fatalf("unexpected purge result: %+v", purged)
+	}
+	if view := state.GetUserStateView("u-export"); len(view.History) != 0 {
+		t.Fatalf("expected history purged, got %+v", view.History)
+	}
+	if _, exists := state.GetCaseHistoryRecord("u-other", other.RecordID); !exists {
+		t.Fatal("expected other user's history kept")
+	}
+	if _, err := service.PurgeUserData("u-export", export.ExportID, time.Now()); !errors.Is(err, retention.ErrExportAlreadyUsed) {
+		t.Fatalf("expected export reuse rejected, got %v", err)
+	}
+}
+
+func readZip(t *testing.T, content []byte) map[string][]byte {
+	t.Helper()
+	reader, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		t.Fatalf("open zip failed: %v", err)
+	}
+	files := map[string][]byte{}
+	for _, file := range reader.File {
+		handle, err := file.Open()
+		if err != nil {
+			t.Fatalf("open zip entry failed: %v", err)
+		}
+		data, err := io.ReadAll(handle)
+		_ = handle.Close()
+		if err != nil {
+			t.Fatalf("read zip entry failed: %v", err)
+		}
+		files[file.Name] = data
+	}
+	return files
+}
+
+func fileNames(files map[string][]byte) []string {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	return names
+}
@@ -3,32 +3,50 @@ package application
 import (
 	"context"
 	"fmt"
+	"sync"
+	"time"
 
 	"antifraud/internal/modules/multi_agent/adapters/outbound/state"
 	"antifraud/internal/modules/multi_agent/core"
+	"antifraud/internal/platform/redaction"
 )
 
 // Analyzer 定义任务处理所需的分析器端口。
 type Analyzer interface {
-	Analyze(ctx context.Context, userID string, taskID string, text string, videos []string, audios []string, images []string) (string, error)
+	Analyze(ctx context.Context, userID string, taskID string, payload state.TaskPayload) (string, error)
 }
 
 // TaskStore 定义任务状态持久化端口。
+// 队列相关方法（Claim/Renew/Recover）基于 pending_tasks 表的租约字段实现，保证进程重启后任务可恢复。
 type TaskStore interface {
-	CreateTask(userID string, payload state.TaskPayload) state.TaskRecord
+	// CreateTask 落库任务并在同一事务内保存入队时的脱敏映射，保证任务可被领取时映射已可见。
+	CreateTask(userID string, payload state.TaskPayload, redactionMapping map[string]string) state.TaskRecord
 	GetUserTaskState(userID string) state.UserStateView
-	MarkTaskProcessing(userID string, taskID string)
 	GetTask(userID string, taskID string) (state.TaskRecord, bool)
-	MarkTaskFailed(userID string, taskID string, errMsg string)
-	MarkTaskCompleted(userID string, taskID string, report string)
+	MarkTaskFailed(userID string, taskID string, workerID string, errMsg string) bool
+	MarkTaskCompleted(userID string, taskID string, workerID string, report string) bool
+	CancelTask(userID string, taskID string) (state.TaskRecord, bool, error)
+	MarkTaskCancelled(userID string, taskID string)
+	ClaimNextTask(workerID string, leaseTTL time.Duration, globalLimit int, perUserLimit int) (state.TaskRecord, bool)
+	RenewTaskLease(taskID string, workerID string, leaseTTL time.Duration) bool
+	RecoverExpiredTasks(maxAttempts int) (requeued int, failed int)
+	AppendTaskProgress(userID string, taskID string, stage string, message string, data map[string]interface{})
 }
 
 // TaskService 编排多模态任务入队和处理。
 type TaskService struct {
 	store    TaskStore
 	analyzer Analyzer
+
+	workersMu sync.Mutex
+	workers   *TaskWorkerPool
 }
 
+var (
+	defaultTaskServiceOnce sync.Once
+	defaultTaskService     *TaskService
+)
+
 func NewTaskService(store TaskStore, analyzer Analyzer) *TaskService {
 	if store == nil {
 		store = defaultTaskStore{}
@@ -39,71 +57,154 @@ func NewTaskService(store TaskStore, analyzer Analyzer) *TaskService {
 	return &TaskService{store: store, analyzer: analyzer}
 }
 
+// DefaultTaskService 返回进程内共享的默认任务服务，入队与工作池共用同一实例。
 func DefaultTaskService() *TaskService {
-	return NewTaskService(nil, nil)
+	defaultTaskServiceOnce.Do(func() {
+		defaultTaskService = NewTaskService(nil, nil)
+	})
+	return defaultTaskService
 }
 
+// EnqueueTask 将任务持久化到 pending_tasks 并唤醒工作池。
+// payload 应已经过 NormalizeTaskPayload 预处理；入队时会记录 media_normalized/queued 进度事件。
+// 用户文本在落库前脱敏，任务内只保存占位符，原文映射单独保存，供分析阶段续用与展示报告时还原。
+// 工作池未启动时任务仍保留在表中，待工作池启动后按先进先出顺序处理。
 func (s *TaskService) EnqueueTask(userID string, payload state.TaskPayload) (state.TaskRecord, error) {
 	if s == nil || s.store == nil {
 		return state.TaskRecord{}, fmt.Errorf("task service is unavailable")
 	}
-	task := s.store.CreateTask(userID, payload)
-	go s.processTask(userID, task.TaskID)
+	redactor := redaction.NewRedactor(nil)
+	payload.Text = redactor.Redact(payload.Text)
+	task := s.store.CreateTask(userID, payload, redactor.Mapping())
+	mediaCounts := map[string]interface{}{
+		"videos": len(payload.Videos),
+		"audios": len(payload.Audios),
+		"images": len(payload.Images),
+	}
+	mediaTotal := len(payload.Videos) + len(payload.Audios) + len(payload.Images)
+	for kind, items := range payload.ExtraInputs {
+		mediaCounts[kind] = len(items)
+		mediaTotal += len(items)
+	}
+	if mediaTotal > 0 {
+		s.store.AppendTaskProgress(task.UserID, task.TaskID, state.TaskProgressStageMediaNormalized, "媒体预处理完成", mediaCounts)
+	}
+	s.store.AppendTaskProgress(task.UserID, task.TaskID, state.TaskProgressStageQueued, "任务已入队，等待处理", mediaCounts)
+	if pool := s.currentWorkers(); pool != nil {
+		pool.Notify()
+	}
 	return task, nil
 }
 
-func (s *TaskService) GetUserTaskState(userID string) state.UserStateView {
+// StartWorkers 启动任务工作池；重复调用时复用已启动的工作池。
+func (s *TaskService) StartWorkers(ctx context.Context, options TaskQueueOptions) error {
 	if s == nil || s.store == nil {
-		return state.UserStateView{}
+		return fmt.Errorf("task service is unavailable")
 	}
-	return s.store.GetUserTaskState(userID)
+	s.workersMu.Lock()
+	defer s.workersMu.Unlock()
+	if s.workers != nil {
+		return nil
+	}
+	pool := NewTaskWorkerPool(s.store, s.analyzer, options)
+	if err := pool.Start(ctx); err != nil {
+		return err
+	}
+	s.workers = pool
+	return nil
 }
 
-func (s *TaskService) processTask(userID, taskID string) {
-	s.store.MarkTaskProcessing(userID, taskID)
-
-	task, exists := s.store.GetTask(userID, taskID)
-	if !exists {
+// StopWorkers 停止工作池并等待正在执行的任务返回。
+func (s *TaskService) StopWorkers() {
+	if s == nil {
 		return
 	}
+	s.workersMu.Lock()
+	pool := s.workers
+	s.workers = nil
+	s.workersMu.Unlock()
+	if pool != nil {
+		pool.Stop()
+	}
+}
 
-	report, err := s.analyzer.Analyze(context.Background(), userID, taskID, task.Payload.Text, task.Payload.Videos, task.Payload.Audios, task.Payload.Images)
-	if err != nil {
-		s.store.MarkTaskFailed(userID, taskID, err.Error())
-		return
+func (s *TaskService) currentWorkers() *TaskWorkerPool {
+	s.workersMu.Lock()
+	defer s.workersMu.Unlock()
+	return s.workers
+}
+
+// CancelTask 取消当前用户的进行中任务；若任务正由本进程 worker 执行，会立即中止其分析上下文。
+// 返回 false 表示任务不存在或已结束。
+func (s *TaskService) CancelTask(userID string, taskID string) (state.TaskRecord, bool, error) {
+	if s == nil || s.store == nil {
+		return state.TaskRecord{}, false, fmt.Errorf("task service is unavailable")
+	}
+	task, ok, err := s.store.CancelTask(userID, taskID)
+	if err != nil || !ok {
+		return task, ok, err
+	}
+	if pool := s.currentWorkers(); pool != nil {
+		pool.CancelRunning(task.TaskID)
 	}
-	s.store.MarkTaskCompleted(userID, taskID, report)
+	return task, true, nil
+}
+
+func (s *TaskService) GetUserTaskState(userID string) state.UserStateView {
+	if s == nil || s.store == nil {
+		return state.UserStateView{}
+	}
+	return s.store.GetUserTaskState(userID)
 }
 
 type defaultTaskStore struct{}
 
-func (defaultTaskStore) CreateTask(userID string, payload state.TaskPayload) state.TaskRecord {
-	return state.CreateTask(userID, payload)
+func (defaultTaskStore) CreateTask(userID string, payload state.TaskPayload, redactionMapping map[string]string) state.TaskRecord {
+	return state.CreateTaskWithRedactionMapping(userID, payload, redactionMapping)
 }
 
 func (defaultTaskStore) GetUserTaskState(userID string) state.UserStateView {
 	return state.GetUserStateView(userID)
 }
 
-func (defaultTaskStore) MarkTaskProcessing(userID string, taskID string) {
-	state.MarkTaskProcessing(userID, taskID)
-}
-
 func (defaultTaskStore) GetTask(userID string, taskID string) (state.TaskRecord, bool) {
 	return state.GetTask(userID, taskID)
 }
 
-func (defaultTaskStore) MarkTaskFailed(userID string, taskID string, errMsg string) {
-	state.MarkTaskFailed(userID, taskID, errMsg)
+func (defaultTaskStore) MarkTaskFailed(userID string, taskID string, workerID string, errMsg string) bool {
+	return state.MarkTaskFailed(userID, taskID, workerID, errMsg)
+}
+
+func (defaultTaskStore) MarkTaskCompleted(userID string, taskID string, workerID string, report string) bool {
+	return state.MarkTaskCompleted(userID, taskID, workerID, report)
+}
+
+func (defaultTaskStore) CancelTask(userID string, taskID string) (state.TaskRecord, bool, error) {
+	return state.CancelTask(userID, taskID)
+}
+
+func (defaultTaskStore) MarkTaskCancelled(userID string, taskID string) {
+	state.MarkTaskCancelled(userID, taskID)
+}
+
+func (defaultTaskStore) ClaimNextTask(workerID string, leaseTTL time.Duration, globalLimit int, perUserLimit int) (state.TaskRecord, bool) {
+	return state.ClaimNextPendingTask(workerID, leaseTTL, globalLimit, perUserLimit)
+}
+
+func (defaultTaskStore) RenewTaskLease(taskID string, workerID string, leaseTTL time.Duration) bool {
+	return state.RenewTaskLease(taskID, workerID, leaseTTL)
+}
+
+func (defaultTaskStore) RecoverExpiredTasks(maxAttempts int) (int, int) {
+	return state.RecoverExpiredTaskLeases(maxAttempts)
 }
 
-func (defaultTaskStore) MarkTaskCompleted(userID string, taskID string, report string) {
-	state.MarkTaskCompleted(userID, taskID, report)
+func (defaultTaskStore) AppendTaskProgress(userID string, taskID string, stage string, message string, data map[string]interface{}) {
+	state.AppendTaskProgressEvent(userID, taskID, stage, message, data)
 }
 
 type defaultAnalyzer struct{}
 
-func (defaultAnalyzer) Analyze(ctx context.Context, userID string, taskID string, text string, videos []string, audios []string, images []string) (string, error) {
-	_ = ctx
-	return multi_agent.AnalyzeMainReportForUser(userID, taskID, text, videos, audios, images)
+func (defaultAnalyzer) Analyze(ctx context.Context, userID string, taskID string, payload state.TaskPayload) (string, error) {
+	return multi_agent.AnalyzeTaskPayloadForUser(ctx, userID, taskID, payload)
 }
@@ -0,0 +1,340 @@
+package application
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"antifraud/internal/modules/multi_agent/adapters/outbound/state"
+	appcfg "antifraud/internal/platform/config"
+)
+
+var (
+	errTaskCancelledByUser = errors.New("task cancelled by user")
+	errTaskLeaseLost       = errors.New("task lease lost")
+)
+
+// TaskQueueOptions 定义持久化任务队列的并发与租约参数。
+type TaskQueueOptions struct {
+	// GlobalConcurrency 既是 worker 数量，也是全局同时处理任务数上限。
+	GlobalConcurrency  int
+	PerUserConcurrency int
+	LeaseTTL           time.Duration
+	HeartbeatInterval  time.Duration
+	PollInterval       time.Duration
+	MaxAttempts        int
+}
+
+// TaskQueueOptionsFromConfig 将 config.json 中的 task_queue 配置转换为运行期参数。
+func TaskQueueOptionsFromConfig(cfg appcfg.TaskQueueConfig) TaskQueueOptions {
+	return TaskQueueOptions{
+		GlobalConcurrency:  cfg.GlobalConcurrency,
+		PerUserConcurrency: cfg.PerUserConcurrency,
+		LeaseTTL:           time.Duration(cfg.LeaseSeconds) * time.Second,
+		HeartbeatInterval:  time.Duration(cfg.HeartbeatSeconds) * time.Second,
+		PollInterval:       time.Duration(cfg.PollIntervalMS) * time.Millisecond,
+		MaxAttempts:        cfg.MaxAttempts,
+	}
+}
+
+func normalizeTaskQueueOptions(options TaskQueueOptions) TaskQueueOptions {
+	if options.GlobalConcurrency <= 0 {
+		options.GlobalConcurrency = 4
+	}
+	if options.PerUserConcurrency <= 0 {
+		options.PerUserConcurrency = 1
+	}
+	if options.LeaseTTL <= 0 {
+		options.LeaseTTL = 90 * time.Second
+	}
+	if options.HeartbeatInterval <= 0 || options.HeartbeatInterval >= options.LeaseTTL {
+		options.HeartbeatInterval = options.LeaseTTL / 3
+	}
+	if options.PollInterval <= 0 {
+		options.PollInterval = 2 * time.Second
+	}
+	if options.MaxAttempts <= 0 {
+		options.MaxAttempts = 3
+	}
+	return options
+}
+
+// TaskWorkerPool 是基于 pending_tasks 表的有界工作池。
+// 设计说明：
+// 1) 任务状态全部落库，worker 通过租约领取任务并定期心跳续期；
+// 2) 启动时先回收过期租约，进程重启前未完成的任务会重新进入 pending 队列；
+// 3) 入队时通过 Notify 唤醒空闲 worker，同时按 PollInterval 兜底轮询；
+// 4) 同进程内的领取动作串行执行，避免并发领取突破单用户并发上限。
+type TaskWorkerPool struct {
+	store      TaskStore
+	analyzer   Analyzer
+	options    TaskQueueOptions
+	instanceID string
+
+	wake    chan struct{}
+	claimMu sync.Mutex
+
+	runningMu sync.Mutex
+	running   map[string]context.CancelCauseFunc
+
+	mu      sync.Mutex
+	started bool
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+func NewTaskWorkerPool(store TaskStore, analyzer Analyzer, options TaskQueueOptions) *TaskWorkerPool {
+	if store == nil {
+		store = defaultTaskStore{}
+	}
+	if analyzer == nil {
+		analyzer = defaultAnalyzer{}
+	}
+	options = normalizeTaskQueueOptions(options)
+	return &TaskWorkerPool{
+		store:      store,
+		analyzer:   analyzer,
+		options:    options,
+		instanceID: newWorkerInstanceID(),
+		wake:       make(chan struct{}, options.GlobalConcurrency),
+		running:    map[string]context.CancelCauseFunc{},
+	}
+}
+
+// Start 回收过期租约后启动 worker 与租约回收协程。
+func (p *TaskWorkerPool) Start(ctx context.Context) error {
+	if p == nil {
+		return fmt.Errorf("task worker pool is unavailable")
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.started {
+		return nil
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	requeued, failed := p.store.RecoverExpiredTasks(p.options.MaxAttempts)
+	if requeued > 0 || failed > 0 {
+		log.Printf("[task_queue] startup recovery: requeued=%d failed=%d", requeued, failed)
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	p.cancel = cancel
+	p.started = true
+
+	for index := 0; index < p.options.GlobalConcurrency; index++ {
+		workerID := fmt.Sprintf("%s-W%02d", p.instanceID, index+1)
+		p.wg.Add(1)
+		go p.runWorker(runCtx, workerID)
+	}
+	p.wg.Add(1)
+	go p.runLeaseRecovery(runCtx)
+
+	log.Printf("[task_queue] worker pool started: instance=%s workers=%d per_user=%d lease=%s",
+		p.instanceID, p.options.GlobalConcurrency, p.options.PerUserConcurrency, p.options.LeaseTTL)
+	p.Notify()
+	return nil
+}
+
+// Stop 停止领取新任务并等待正在执行的任务返回。
+// 未完成的任务保留 processing 状态，租约过期后会被下一次启动回收。
+func (p *TaskWorkerPool) Stop() {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	if !p.started {
+		p.mu.Unlock()
+		return
+	}
+	p.started = false
+	cancel := p.cancel
+	p.cancel = nil
+	p.mu.Unlock()
+
+	cancel()
+	p.wg.Wait()
+}
+
+// Notify 唤醒一个空闲 worker 尝试领取任务；没有空闲 worker 时直接丢弃信号。
+func (p *TaskWorkerPool) Notify() {
+	if p == nil {
+		return
+	}
+	select {
+	case p.wake <- struct{}{}:
+	default:
+	}
+}
+
+// CancelRunning 中止本进程内正在执行的指定任务；任务不在本进程执行时返回 false。
+func (p *TaskWorkerPool) CancelRunning(taskID string) bool {
+	if p == nil {
+		return false
+	}
+	p.runningMu.Lock()
+	cancel, ok := p.running[taskID]
+	p.runningMu.Unlock()
+	if ok {
+		cancel(errTaskCancelledByUser)
+	}
+	return ok
+}
+
+func (p *TaskWorkerPool) runWorker(ctx context.Context, workerID string) {
+	defer p.wg.Done()
+
+	ticker := time.NewTicker(p.options.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		p.drain(ctx, workerID)
+		select {
+		case <-ctx.Done():
+			return
+		case <-p.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+// drain 连续领取并处理任务，直到队列中没有当前可领取的任务。
+func (p *TaskWorkerPool) drain(ctx context.Context, workerID string) {
+	for ctx.Err() == nil {
+		task, ok := p.claim(workerID)
+		if !ok {
+			return
+		}
+		p.processClaimedTask(ctx, workerID, task)
+		// 任务结束会释放单用户并发名额，唤醒其他 worker 重新检查被跳过的任务。
+		p.Notify()
+	}
+}
+
+func (p *TaskWorkerPool) claim(workerID string) (state.TaskRecord, bool) {
+	p.claimMu.Lock()
+	defer p.claimMu.Unlock()
+	return p.store.ClaimNextTask(workerID, p.options.LeaseTTL, p.options.GlobalConcurrency, p.options.PerUserConcurrency)
+}
+
+func (p *TaskWorkerPool) processClaimedTask(ctx context.Context, workerID string, task state.TaskRecord) {
+	taskCtx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	p.trackRunning(task.TaskID, cancel)
+	defer p.untrackRunning(task.TaskID)
+	p.store.AppendTaskProgress(task.UserID, task.TaskID, state.TaskProgressStageStarted, "任务开始分析", map[string]interface{}{
+		"attempt": task.Attempts,
+	})
+
+	heartbeatDone := make(chan struct{})
+	defer close(heartbeatDone)
+	go p.keepLeaseAlive(taskCtx, cancel, heartbeatDone, workerID, task.TaskID)
+
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			log.Printf("[task_queue] task panic recovered: worker=%s task=%s panic=%v", workerID, task.TaskID, recovered)
+			p.finalize(workerID, task, p.store.MarkTaskFailed(task.UserID, task.TaskID, workerID, fmt.Sprintf("task panic: %v", recovered)))
+		}
+	}()
+
+	report, err := p.analyzer.Analyze(taskCtx, task.UserID, task.TaskID, task.Payload)
+	if ctx.Err() != nil {
+		// 工作池正在停止：保留租约让任务在重启后被回收重跑，而不是记为失败。
+		log.Printf("[task_queue] task interrupted by shutdown: worker=%s task=%s", workerID, task.TaskID)
+		return
+	}
+	if taskCtx.Err() != nil {
+		// 用户取消或租约丢失：仅当任务已被标记 cancelled 时归档，被回收重新排队的任务交给新的持有者。
+		log.Printf("[task_queue] task aborted: worker=%s task=%s cause=%v", workerID, task.TaskID, context.Cause(taskCtx))
+		p.store.MarkTaskCancelled(task.UserID, task.TaskID)
+		return
+	}
+	if err != nil {
+		p.finalize(workerID, task, p.store.MarkTaskFailed(task.UserID, task.TaskID, workerID, err.Error()))
+		return
+	}
+	p.finalize(workerID, task, p.store.MarkTaskCompleted(task.UserID, task.TaskID, workerID, report))
+}
+
+// finalize 处理归档结果：心跳尚未发现租约丢失时，归档会因 worker 不再持有 processing 租约而被拒绝；
+// 此时若任务已被用户取消则按取消归档，已被回收重新排队的任务交给新的持有者。
+func (p *TaskWorkerPool) finalize(workerID string, task state.TaskRecord, finalized bool) {
+	if finalized {
+		return
+	}
+	log.Printf("[task_queue] task finalize skipped, lease no longer held: worker=%s task=%s", workerID, task.TaskID)
+	p.store.MarkTaskCancelled(task.UserID, task.TaskID)
+}
+
+// keepLeaseAlive 按心跳间隔续期租约；租约丢失时取消任务上下文，避免与接管的 worker 重复执行。
+func (p *TaskWorkerPool) keepLeaseAlive(ctx context.Context, cancel context.CancelCauseFunc, done <-chan struct{}, workerID string, taskID string) {
+	ticker := time.NewTicker(p.options.HeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-done:
+			return
+		case <-ticker.C:
+			if !p.store.RenewTaskLease(taskID, workerID, p.options.LeaseTTL) {
+				log.Printf("[task_queue] task lease lost: worker=%s task=%s", workerID, taskID)
+				cancel(errTaskLeaseLost)
+				return
+			}
+		}
+	}
+}
+
+func (p *TaskWorkerPool) trackRunning(taskID string, cancel context.CancelCauseFunc) {
+	p.runningMu.Lock()
+	defer p.runningMu.Unlock()
+	p.running[taskID] = cancel
+}
+
+func (p *TaskWorkerPool) untrackRunning(taskID string) {
+	p.runningMu.Lock()
+	defer p.runningMu.Unlock()
+	delete(p.running, taskID)
+}
+
+// runLeaseRecovery 周期性回收过期租约，覆盖 worker 异常退出或多实例部署时的遗留任务。
+func (p *TaskWorkerPool) runLeaseRecovery(ctx context.Context) {
+	defer p.wg.Done()
+
+	ticker := time.NewTicker(p.options.HeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			requeued, failed := p.store.RecoverExpiredTasks(p.options.MaxAttempts)
+			if requeued > 0 || failed > 0 {
+				log.Printf("[task_queue] recovered expired leases: requeued=%d failed=%d", requeued, failed)
+			}
+			if requeued > 0 {
+				p.Notify()
+			}
+		}
+	}
+}
+
+// newWorkerInstanceID 生成进程级实例标识，作为租约持有者前缀。
+func newWorkerInstanceID() string {
+	bytes := make([]byte, 4)
+	if _, err := rand.Read(bytes); err != nil {
+		return fmt.Sprintf("worker-%d-%d", os.Getpid(), time.Now().UnixNano())
+	}
+	return fmt.Sprintf("worker-%d-%s", os.Getpid(), hex.EncodeToString(bytes))
+}
@@ -43,8 +43,16 @@ func (a CommonAgent) Name() string {
 
 // Retry 使用线性退避执行重试。
 func (a CommonAgent) Retry(action string, fn func() error) error {
+	return a.RetryWithContext(context.Background(), action, fn)
+}
+
+// RetryWithContext 与 Retry 语义一致，但 ctx 取消后不再发起新的尝试，也会提前结束退避等待。
+func (a CommonAgent) RetryWithContext(ctx context.Context, action string, fn func() error) error {
 	var lastErr error
 	for attempt := 1; attempt <= a.RetryMax; attempt++ {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return fmt.Errorf("%s cancelled: %w", action, ctxErr)
+		}
 		if err := fn(); err == nil {
 			if attempt > 1 {
 				fmt.Printf("[%s] retry succeeded: action=%s, attempt=%d\n", a.Name(), action, attempt)
@@ -56,8 +64,13 @@ func (a CommonAgent) Retry(action string, fn func() error) error {
 		}
 
 		if attempt < a.RetryMax {
-			backoff := time.Duration(attempt) * a.RetryDelay
-			time.Sleep(backoff)
+			backoff := time.NewTimer(time.Duration(attempt) * a.RetryDelay)
+			select {
+			case <-ctx.Done():
+				backoff.Stop()
+				return fmt.Errorf("%s cancelled: %w", action, ctx.Err())
+			case <-backoff.C:
+			}
 		}
 	}
 
@@ -109,6 +122,9 @@ func NewSubAgentBase(name string, modelCfg config.ModelConfig, retryCfg config.R
 
 func (a *SubAgentBase) Analyze(ctx context.Context, dataBase64 string, index int) (string, error) {
 	displayIndex := index + 1
+	if err := ctx.Err(); err != nil {
+		return "", fmt.Errorf("%s %d: analysis cancelled: %w", a.profile.Modality, displayIndex, err)
+	}
 
 	if a.profile.BuildDataURL == nil {
 		return "", fmt.Errorf("%s %d: BuildDataURL is not configured", a.profile.Modality, displayIndex)
@@ -194,7 +210,7 @@ func (a *SubAgentBase) AnalyzeWithUnifiedTool(ctx context.Context, client *opena
 	action := fmt.Sprintf("create chat completion for %s %d", modality, displayIndex)
 
 	var resp openai.ChatCompletionResponse
-	if err := a.Retry(action, func() error {
+	if err := a.RetryWithContext(ctx, action, func() error {
 		var callErr error
 		resp, callErr = client.CreateChatCompletion(ctx, req)
 		return callErr
@@ -79,7 +79,7 @@ func (a *ASRAgent) Transcribe(ctx context.Context, audioBase64 string, index int
 
 	action := fmt.Sprintf("create chat completion for asr audio %d", displayIndex)
 	var resp openai.ChatCompletionResponse
-	if err := a.Retry(action, func() error {
+	if err := a.RetryWithContext(ctx, action, func() error {
 		var callErr error
 		resp, callErr = a.client.CreateChatCompletion(ctx, req)
 		return callErr
@@ -139,14 +139,13 @@ func extractMessageText(msg openai.ChatCompletionMessage) string {
 	return strings.TrimSpace(strings.Join(texts, "\n"))
 }
 
-func TranscribeAudiosParallel(audiosBase64 []string) []string {
+func TranscribeAudiosParallel(ctx context.Context, audiosBase64 []string) []string {
 	cfg, err := config.LoadConfig("internal/platform/config/config.json")
 	if err != nil {
 		fmt.Printf("Error loading config: %v\n", err)
 		return []string{fmt.Sprintf("Error loading config: %v", err)}
 	}
 
-	ctx := context.Background()
 	agent := NewASRAgent(cfg.Agents.ASR, cfg.Retry)
 	return agent.TranscribeBatchInParallel(ctx, audiosBase64)
 }
@@ -60,14 +60,13 @@ func NewAudioAgent(modelCfg config.ModelConfig, retryCfg config.RetryConfig, sys
 	}
 }
 
-func AnalyzeAudiosParallel(audiosBase64 []string) []string {
+func AnalyzeAudiosParallel(ctx context.Context, audiosBase64 []string) []string {
 	cfg, err := config.LoadConfig("internal/platform/config/config.json")
 	if err != nil {
 		fmt.Printf("Error loading config: %v\n", err)
 		return []string{fmt.Sprintf("Error loading config: %v", err)}
 	}
 
-	ctx := context.Background()
 	agent := NewAudioAgent(cfg.Agents.Audio, cfg.Retry, cfg.Prompts.Audio)
 	return agent.AnalyzeBatchInParallel(ctx, audiosBase64)
 }
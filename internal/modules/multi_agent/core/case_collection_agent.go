@@ -19,6 +19,34 @@ var (
 	caseCollectionToolHandlerResolver = tool.GetCaseCollectionToolHandler
 )
 
+// CaseCollectionObserver 接收案件采集过程中的进度事件，供后台任务持久化进度。
+// 回调在采集循环内同步调用，实现方应尽快返回。
+type CaseCollectionObserver interface {
+	// RoundStarted 在每轮模型调用前触发，round 从 1 开始。
+	RoundStarted(round int)
+	// SearchIssued 在每次执行 search_web 时触发，query 为实际下发给搜索服务的检索词。
+	SearchIssued(query string)
+	// PendingReviewCreated 在成功写入一条待审核案件后触发。
+	PendingReviewCreated(recordID string)
+	// DuplicateRejected 在案件因与已有历史案件过于相似被拒绝写入时触发，caseID 为命中的已有案件。
+	DuplicateRejected(caseID string)
+}
+
+type caseCollectionObserverContextKey struct{}
+
+// WithCaseCollectionObserver 把进度观察者绑定到 ctx，CollectCases 会在关键节点回调。
+func WithCaseCollectionObserver(ctx context.Context, observer CaseCollectionObserver) context.Context {
+	return context.WithValue(ctx, caseCollectionObserverContextKey{}, observer)
+}
+
+func caseCollectionObserverFromContext(ctx context.Context) CaseCollectionObserver {
+	if ctx == nil {
+		return nil
+	}
+	observer, _ := ctx.Value(caseCollectionObserverContextKey{}).(CaseCollectionObserver)
+	return observer
+}
+
 // CaseCollectionAgent 负责“搜索公开案例 -> 生成结构化案件 -> 写入待审核库”。
 type CaseCollectionAgent struct {
 	CommonAgent
@@ -61,13 +89,18 @@ func CollectCases(query string, caseCount int) error {
 
 // CollectCasesForUser 是案件采集的主入口。
 func CollectCasesForUser(userID string, query string, caseCount int) error {
+	return CollectCasesForUserContext(context.Background(), userID, query, caseCount)
+}
+
+// CollectCasesForUserContext 与 CollectCasesForUser 相同，但 ctx 取消后会在当前轮结束前停止采集。
+func CollectCasesForUserContext(ctx context.Context, userID string, query string, caseCount int) error {
 	cfg, err := config.LoadConfig("internal/platform/config/config.json")
 	if err != nil {
 		return fmt.Errorf("load case collection config failed: %w", err)
 	}
 
 	agent := NewCaseCollectionAgent(cfg.Agents.CaseCollection, cfg.Retry, cfg.Prompts.CaseCollection)
-	return agent.CollectCases(context.Background(), userID, query, caseCount)
+	return agent.CollectCases(ctx, userID, query, caseCount)
 }
 
 // CollectCases 执行案件采集工具闭环，直到达到目标数量或达到最大轮次。
@@ -87,7 +120,7 @@ func (a *CaseCollectionAgent) CollectCases(ctx context.Context, userID string, q
 	if trimmedUserID == "" {
 		trimmedUserID = "demo-user"
 	}
-	ctx = tool.BindUserID(ctx, trimmedUserID)
+	ctx = tool.BindSearchCitations(tool.BindCaseCollectionSource(tool.BindUserID(ctx, trimmedUserID)))
 
 	for _, requiredToolName := range []string{tool.WebSearchToolName, tool.UploadHistoricalCaseToVectorDBToolName} {
 		if !caseCollectionHasTool(requiredToolName) {
@@ -107,12 +140,19 @@ func (a *CaseCollectionAgent) CollectCases(ctx context.Context, userID string, q
 		},
 	}
 
+	observer := caseCollectionObserverFromContext(ctx)
 	createdCount := 0
 	maxRounds := caseCollectionMaxRounds(caseCount)
 	searchSummaries := make([]string, 0, caseCount)
 	uploadPhasePrepared := false
 
 	for round := 0; round < maxRounds; round++ {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("case collection cancelled after %d rounds: %w", round, err)
+		}
+		if observer != nil {
+			observer.RoundStarted(round + 1)
+		}
 		if round == caseCount && !uploadPhasePrepared {
 			messages = buildCaseCollectionUploadPhaseMessages(systemPrompt, trimmedQuery, caseCount, searchSummaries)
 			uploadPhasePrepared = true
@@ -128,7 +168,7 @@ func (a *CaseCollectionAgent) CollectCases(ctx context.Context, userID string, q
 			round+1, strings.TrimSpace(a.modelID), len(messages), caseCount, forcedToolName, truncateForLog(trimmedQuery, 120))
 
 		var resp openai.ChatCompletionResponse
-		if err := a.Retry(action, func() error {
+		if err := a.RetryWithContext(ctx, action, func() error {
 			var callErr error
 			resp, callErr = a.client.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
 				Model:       a.modelID,
@@ -207,6 +247,12 @@ func (a *CaseCollectionAgent) CollectCases(ctx context.Context, userID string, q
 				continue
 			}
 
+			if observer != nil && call.Function.Name == tool.WebSearchToolName {
+				if input, parseErr := tool.ParseWebSearchInput(call.Function.Arguments); parseErr == nil && strings.TrimSpace(input.Query) != "" {
+					observer.SearchIssued(strings.TrimSpace(input.Query))
+				}
+			}
+
 			response, err := handler.Handle(ctx, call.Function.Arguments)
 			if err != nil {
 				appendToolResponse(call.ID, map[string]interface{}{"status": "failed", "error": err.Error()})
@@ -214,7 +260,13 @@ func (a *CaseCollectionAgent) CollectCases(ctx context.Context, userID string, q
 			}
 
 			appendToolResponse(call.ID, response.Payload)
+			if observer != nil && call.Function.Name == tool.UploadHistoricalCaseToVectorDBToolName {
+				if caseID, ok := extractDuplicateCaseID(response.Payload); ok {
+					observer.DuplicateRejected(caseID)
+				}
+			}
 			if call.Function.Name == tool.WebSearchToolName {
+				tool.RecordSearchCitations(ctx, response.Payload)
 				if summary := buildCaseCollectionSearchSummary(len(searchSummaries)+1, response.Payload); summary != "" {
 					searchSummaries = append(searchSummaries, summary)
 				}
@@ -222,6 +274,9 @@ func (a *CaseCollectionAgent) CollectCases(ctx context.Context, userID string, q
 			if call.Function.Name == tool.UploadHistoricalCaseToVectorDBToolName {
 				if recordID, ok := extractPendingReviewRecordID(response.Payload); ok {
 					createdCount++
+					if observer != nil {
+						observer.PendingReviewCreated(recordID)
+					}
 					fmt.Printf("[CaseCollectionAgent][Round %d] created pending review count=%d/%d record=%s\n",
 						round+1, createdCount, caseCount, strings.TrimSpace(recordID))
 				}
@@ -263,6 +318,15 @@ func extractPendingReviewRecordID(payload map[string]interface{}) (string, bool)
 	return recordID, true
 }
 
+// extractDuplicateCaseID 识别上传工具因查重被拒的响应，返回命中的已有案件 ID。
+func extractDuplicateCaseID(payload map[string]interface{}) (string, bool) {
+	duplicate, ok := payload["duplicate_case"].(map[string]interface{})
+	if !ok {
+		return "", false
+	}
+	return stringifyPayload(duplicate["case_id"]), true
+}
+
 func stringifyPayload(value interface{}) string {
 	text, _ := value.(string)
 	return strings.TrimSpace(text)
@@ -279,7 +343,7 @@ func buildCaseCollectionSystemPrompt(prompt string) string {
 func buildCaseCollectionUserPrompt(query string, caseCount int) string {
 	currentTime := time.Now().Format("2006-01-02 15:04:05 -07:00 MST")
 	return fmt.Sprintf(
-		"请围绕以下主题搜集诈骗案件，并提交 %d 个待审核案件。\n\n当前时间：%s\n要求：优先选择案发时间或发布时间明确、且时间尽量接近当前时间的最新公开案件。\n流程约束：你最多可进行 %d 轮 search_web 搜索；完成搜索后，请逐条调用 upload_historical_case_to_vector_db，直到提交满目标数量。\n搜索主题：%s",
+		"请围绕以下主题搜集诈骗案件，并提交 %d 个待审核案件。\n\n当前时间：%s\n要求：优先选择案发时间或发布时间明确、且时间尽量接近当前时间的最新公开案件。\n流程约束：你最多可进行 %d 轮 search_web 搜索；完成搜索后，请逐条调用 upload_historical_case_to_vector_db，直到提交满目标数量。\n来源要求：每个案件都必须在 source_urls 中填写其依据的 search_web 结果原始链接。\n搜索主题：%s",
 		caseCount,
 		currentTime,
 		caseCount,
@@ -308,7 +372,7 @@ func buildCaseCollectionUploadPhaseUserPrompt(query string, caseCount int, searc
 	}
 
 	return fmt.Sprintf(
-		"请围绕以下主题继续整理诈骗案件，并提交 %d 个待审核案件。\n\n当前时间：%s\n搜索主题：%s\n阶段说明：前置 search_web 阶段已经完成，共 %d 轮。不要继续搜索，只能基于下面的压缩搜索结果整理案件，并逐条调用 upload_historical_case_to_vector_db。\n压缩搜索结果：\n%s",
+		"请围绕以下主题继续整理诈骗案件，并提交 %d 个待审核案件。\n\n当前时间：%s\n搜索主题：%s\n阶段说明：前置 search_web 阶段已经完成，共 %d 轮。不要继续搜索，只能基于下面的压缩搜索结果整理案件，并逐条调用 upload_historical_case_to_vector_db；每个案件都必须在 source_urls 中填写其依据的结果链接。\n压缩搜索结果：\n%s",
 		caseCount,
 		currentTime,
 		strings.TrimSpace(query),
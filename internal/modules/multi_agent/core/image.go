@@ -59,14 +59,13 @@ func NewImageAgent(modelCfg config.ModelConfig, retryCfg config.RetryConfig, sys
 	}
 }
 
-func AnalyzeImagesParallel(imagesBase64 []string) []string {
+func AnalyzeImagesParallel(ctx context.Context, imagesBase64 []string) []string {
 	cfg, err := config.LoadConfig("internal/platform/config/config.json")
 	if err != nil {
 		fmt.Printf("Error loading config: %v\n", err)
 		return []string{fmt.Sprintf("Error loading config: %v", err)}
 	}
 
-	ctx := context.Background()
 	agent := NewImageAgent(cfg.Agents.Image, cfg.Retry, cfg.Prompts.Image)
 	return agent.AnalyzeBatchInParallel(ctx, imagesBase64)
 }
@@ -3,7 +3,9 @@ package multi_agent
 import (
 	"antifraud/internal/modules/multi_agent/adapters/outbound/state"
 	"antifraud/internal/modules/multi_agent/adapters/outbound/tool"
+	"antifraud/internal/modules/multi_agent/domain/indicators"
 	"antifraud/internal/platform/config"
+	"antifraud/internal/platform/redaction"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -13,14 +15,12 @@ import (
 	openai "antifraud/internal/platform/llm"
 )
 
-type modalityResult struct {
-	Text          string
-	Image         string
-	Video         string
-	Audio         string
-	ImageInsights []string
-	VideoInsights []string
-	AudioInsights []string
+// modalityOutcome 保存单个已注册模态在本次任务中的输入数量与子智能体解读结果。
+type modalityOutcome struct {
+	analyzer   ModalityAnalyzer
+	inputCount int
+	summary    string
+	insights   []string
 }
 
 // MainAgent 负责聚合多模态子智能体结果并驱动工具调用闭环。
@@ -46,115 +46,146 @@ func NewMainAgent(modelCfg config.ModelConfig, retryCfg config.RetryConfig, syst
 	}
 }
 
+// NewMainAgentWithClient 允许测试与离线评测注入自定义客户端（桩模型或录制回放）。
+func NewMainAgentWithClient(modelCfg config.ModelConfig, retryCfg config.RetryConfig, systemPrompt string, client *openai.Client) *MainAgent {
+	common := NewCommonAgent("MainAgent", modelCfg, retryCfg)
+
+	return &MainAgent{
+		CommonAgent:  common,
+		client:       client,
+		modelID:      strings.TrimSpace(modelCfg.Model),
+		systemPrompt: strings.TrimSpace(systemPrompt),
+	}
+}
+
 // AnalyzeMainReport 提供默认用户上下文的主流程入口。
 func AnalyzeMainReport(text string, videosBase64 []string, audiosBase64 []string, imagesBase64 []string) (string, error) {
-	return AnalyzeMainReportForUser("demo-user", "", text, videosBase64, audiosBase64, imagesBase64)
+	return AnalyzeMainReportForUser(context.Background(), "demo-user", "", text, videosBase64, audiosBase64, imagesBase64)
+}
+
+// AnalyzeMainReportForUser 以内置图片/视频/音频输入调用主流程，保持旧入口兼容。
+func AnalyzeMainReportForUser(ctx context.Context, userID string, taskID string, text string, videosBase64 []string, audiosBase64 []string, imagesBase64 []string) (string, error) {
+	return AnalyzeTaskPayloadForUser(ctx, userID, taskID, state.TaskPayload{
+		Text:   text,
+		Videos: videosBase64,
+		Audios: audiosBase64,
+		Images: imagesBase64,
+	})
 }
 
-// AnalyzeMainReportForUser 是主流程入口：
-// 并行执行子模态分析 -> 组装主输入 -> 调用主智能体输出最终报告。
-func AnalyzeMainReportForUser(userID string, taskID string, text string, videosBase64 []string, audiosBase64 []string, imagesBase64 []string) (string, error) {
+// AnalyzeTaskPayloadForUser 是主流程入口：
+// 按注册顺序并行执行各模态子智能体 -> 组装主输入 -> 调用主智能体输出最终报告。
+// 模态来自 ModalityAnalyzer 注册表，新增模态无需修改本函数。
+// ctx 取消后子智能体、ffmpeg 与主智能体工具循环都会尽快停止，避免继续消耗模型额度。
+// 发往模型与联网搜索的文本均经过任务级脱敏（见 bindTaskRedactor），最终报告中保留占位符，由展示层按映射还原。
+func AnalyzeTaskPayloadForUser(ctx context.Context, userID string, taskID string, payload state.TaskPayload) (string, error) {
 	cfg, err := config.LoadConfig("internal/platform/config/config.json")
 	if err != nil {
 		return "", fmt.Errorf("load main config failed: %w", err)
 	}
 	mainAgent := NewMainAgent(cfg.Agents.Main, cfg.Retry, cfg.Prompts.Main)
+	return mainAgent.AnalyzeTaskPayload(ctx, userID, taskID, payload)
+}
+
+// AnalyzeTaskPayload 使用当前主智能体实例执行完整多模态流程。
+// 子智能体仍按全局配置创建；离线评测通过 NewMainAgentWithClient 替换主智能体客户端后调用本方法。
+func (a *MainAgent) AnalyzeTaskPayload(ctx context.Context, userID string, taskID string, payload state.TaskPayload) (string, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
 
 	trimmedUserID := strings.TrimSpace(userID)
 	if trimmedUserID == "" {
 		trimmedUserID = "demo-user"
 	}
 	trimmedTaskID := strings.TrimSpace(taskID)
-	trimmedText := strings.TrimSpace(text)
-
-	videosBase64 = normalizeBase64List(videosBase64)
-	audiosBase64 = normalizeBase64List(audiosBase64)
-	imagesBase64 = normalizeBase64List(imagesBase64)
-	fmt.Printf("[MainAgent] start analyze: user=%s task=%s text_len=%d video=%d audio=%d image=%d\n",
-		trimmedUserID, firstNonEmptyForLog(trimmedTaskID, "<empty>"), len(trimmedText), len(videosBase64), len(audiosBase64), len(imagesBase64))
-
-	results := modalityResult{
-		Text:  trimmedText,
-		Image: "No image input provided.",
-		Video: "No video input provided.",
-		Audio: "No audio input provided.",
+	ctx, redactor := bindTaskRedactor(ctx, trimmedUserID, trimmedTaskID)
+	defer persistRedactionMapping(trimmedUserID, trimmedTaskID, redactor)
+
+	analyzers := RegisteredModalityAnalyzers()
+	normalizedPayload := state.TaskPayload{Text: redactor.Redact(strings.TrimSpace(payload.Text))}
+	outcomes := make([]modalityOutcome, len(analyzers))
+	inputCounts := make([]string, 0, len(analyzers))
+	for index, analyzer := range analyzers {
+		items := normalizeBase64List(payload.ModalityInputs(analyzer.Kind()))
+		normalizedPayload.SetModalityInputs(analyzer.Kind(), items)
+		outcomes[index] = modalityOutcome{
+			analyzer:   analyzer,
+			inputCount: len(items),
+			summary:    fmt.Sprintf("No %s input provided.", analyzer.Kind()),
+		}
+		inputCounts = append(inputCounts, fmt.Sprintf("%s=%d", analyzer.Kind(), len(items)))
 	}
+	fmt.Printf("[MainAgent] start analyze: user=%s task=%s text_len=%d %s\n",
+		trimmedUserID, firstNonEmptyForLog(trimmedTaskID, "<empty>"), len(normalizedPayload.Text), strings.Join(inputCounts, " "))
 
+	// 每个模态的 goroutine 只写入自己下标的 outcome，无需额外加锁。
 	var wg sync.WaitGroup
-	var mu sync.Mutex
-
-	if len(imagesBase64) > 0 {
+	for index := range outcomes {
+		if outcomes[index].inputCount == 0 {
+			continue
+		}
 		wg.Add(1)
-		go func() {
+		go func(outcome *modalityOutcome, items []string) {
 			defer wg.Done()
-			fmt.Printf("[MainAgent] image sub-agent start, count=%d\n", len(imagesBase64))
-			parallelResults := AnalyzeImagesParallel(imagesBase64)
-			mu.Lock()
-			defer mu.Unlock()
+			analyzer := outcome.analyzer
+			kind := analyzer.Kind()
+			fmt.Printf("[MainAgent] %s sub-agent start, count=%d\n", kind, len(items))
+			parallelResults := analyzer.AnalyzeBatch(ctx, items)
+			reportTaskProgress(trimmedUserID, trimmedTaskID, state.TaskProgressStageSubAgentCompleted, fmt.Sprintf("%s 子智能体分析完成", analyzer.Label()), map[string]interface{}{
+				"modality":     kind,
+				"input_count":  len(items),
+				"result_count": len(parallelResults),
+			})
 			if len(parallelResults) == 0 {
-				results.Image = "Image analysis failed: empty result"
-				results.ImageInsights = []string{"Image analysis failed: empty result"}
+				failure := fmt.Sprintf("%s analysis failed: empty result", analyzer.Label())
+				outcome.summary = failure
+				outcome.insights = []string{failure}
 				return
 			}
-			results.Image = formatModalityBatchResult("Image", parallelResults)
-			results.ImageInsights = append([]string{}, parallelResults...)
-			fmt.Printf("[MainAgent] image sub-agent done, result_count=%d\n", len(parallelResults))
-		}()
+			// 子智能体会从图片、视频中读出号码与姓名，解读结果进入主智能体前同样脱敏。
+			outcome.insights = redactor.RedactList(parallelResults)
+			outcome.summary = formatModalityBatchResult(analyzer.Label(), outcome.insights)
+			fmt.Printf("[MainAgent] %s sub-agent done, result_count=%d\n", kind, len(parallelResults))
+		}(&outcomes[index], normalizedPayload.ModalityInputs(outcomes[index].analyzer.Kind()))
 	}
 
-	if len(videosBase64) > 0 {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			fmt.Printf("[MainAgent] video sub-agent start, count=%d\n", len(videosBase64))
-			parallelResults := AnalyzeVideosParallel(videosBase64)
-			mu.Lock()
-			defer mu.Unlock()
-			if len(parallelResults) == 0 {
-				results.Video = "Video analysis failed: empty result"
-				results.VideoInsights = []string{"Video analysis failed: empty result"}
-				return
-			}
-			results.Video = formatModalityBatchResult("Video", parallelResults)
-			results.VideoInsights = append([]string{}, parallelResults...)
-			fmt.Printf("[MainAgent] video sub-agent done, result_count=%d\n", len(parallelResults))
-		}()
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return "", fmt.Errorf("analysis cancelled: %w", err)
 	}
 
-	if len(audiosBase64) > 0 {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			fmt.Printf("[MainAgent] audio sub-agent start, count=%d\n", len(audiosBase64))
-			parallelResults := AnalyzeAudiosParallel(audiosBase64)
-			mu.Lock()
-			defer mu.Unlock()
-			if len(parallelResults) == 0 {
-				results.Audio = "Audio analysis failed: empty result"
-				results.AudioInsights = []string{"Audio analysis failed: empty result"}
-				return
-			}
-			results.Audio = formatModalityBatchResult("Audio", parallelResults)
-			results.AudioInsights = append([]string{}, parallelResults...)
-			fmt.Printf("[MainAgent] audio sub-agent done, result_count=%d\n", len(parallelResults))
-		}()
+	insights := make(map[string][]string, len(outcomes))
+	insightCounts := make(map[string]interface{}, len(outcomes))
+	insightLog := make([]string, 0, len(outcomes))
+	for _, outcome := range outcomes {
+		kind := outcome.analyzer.Kind()
+		if len(outcome.insights) > 0 {
+			insights[kind] = outcome.insights
+		}
+		insightCounts[kind+"_insights"] = len(outcome.insights)
+		insightLog = append(insightLog, fmt.Sprintf("%s_insights=%d", kind, len(outcome.insights)))
 	}
+	fmt.Printf("[MainAgent] sub-agents complete: %s\n", strings.Join(insightLog, " "))
 
-	wg.Wait()
-	fmt.Printf("[MainAgent] sub-agents complete: image_insights=%d video_insights=%d audio_insights=%d\n",
-		len(results.ImageInsights), len(results.VideoInsights), len(results.AudioInsights))
+	// 结构化线索由规则确定性抽取，不依赖模型输出；视频解读中已包含 ASR 转写。
+	taskIndicators := indicators.Extract(redactor, indicatorSources(normalizedPayload.Text, outcomes)...)
+	insightCounts["indicator_count"] = len(taskIndicators)
 
 	if trimmedTaskID != "" {
-		state.UpdateTaskInsights(trimmedUserID, trimmedTaskID, results.VideoInsights, results.AudioInsights, results.ImageInsights)
+		persistRedactionMapping(trimmedUserID, trimmedTaskID, redactor)
+		state.UpdateTaskInsights(trimmedUserID, trimmedTaskID, insights)
+		state.UpdateTaskIndicators(trimmedUserID, trimmedTaskID, taskIndicators)
+		reportTaskProgress(trimmedUserID, trimmedTaskID, state.TaskProgressStageInsightsSaved, "子模态解读已保存，主智能体开始研判", insightCounts)
 	}
 
-	finalInput := buildMainAgentInput(results)
-	// 外层先写入子模态洞察（insights）。
+	finalInput := buildMainAgentInput(normalizedPayload.Text, outcomes, taskIndicators)
+	// 外层先写入子模态洞察（insights）与结构化线索。
 	// generateReport 入口会继续补齐 user/task/payload，确保工具上下文完整。
-	ctx := context.Background()
-	ctx = tool.BindTaskInsights(ctx, results.VideoInsights, results.AudioInsights, results.ImageInsights)
+	ctx = tool.BindTaskInsights(ctx, insights)
+	ctx = tool.BindTaskIndicators(ctx, taskIndicators)
 
-	report, err := mainAgent.generateReport(ctx, finalInput, trimmedUserID, trimmedTaskID, trimmedText, videosBase64, audiosBase64, imagesBase64)
+	report, err := a.generateReport(ctx, finalInput, trimmedUserID, trimmedTaskID, normalizedPayload)
 	if err != nil {
 		return "", err
 	}
@@ -162,6 +193,25 @@ func AnalyzeMainReportForUser(userID string, taskID string, text string, videosB
 	return report, nil
 }
 
+// bindTaskRedactor 返回绑定了任务级 Redactor 的 ctx。调用方已绑定时直接复用；
+// 否则按入队时保存的映射恢复，保证同一任务的占位符编号在入队、子智能体与工具调用之间保持一致。
+func bindTaskRedactor(ctx context.Context, userID string, taskID string) (context.Context, *redaction.Redactor) {
+	if redactor := redaction.FromContext(ctx); redactor != nil {
+		return ctx, redactor
+	}
+	redactor := redaction.NewRedactor(state.GetTaskRedactionMapping(userID, taskID))
+	return redaction.WithRedactor(ctx, redactor), redactor
+}
+
+// persistRedactionMapping 把任务当前的脱敏映射合并写回，已保存的占位符保持原值不被覆盖；
+// 无 taskID 的同步调用只在内存中使用映射。
+func persistRedactionMapping(userID string, taskID string, redactor *redaction.Redactor) {
+	if strings.TrimSpace(taskID) == "" {
+		return
+	}
+	state.SaveTaskRedactionMapping(userID, taskID, redactor.Mapping())
+}
+
 // normalizeBase64List 过滤空输入并保留有效 Base64 项。
 func normalizeBase64List(items []string) []string {
 	normalized := make([]string, 0, len(items))
@@ -192,30 +242,47 @@ func formatModalityBatchResult(modality string, results []string) string {
 	return strings.TrimSpace(builder.String())
 }
 
-// buildMainAgentInput 构建主智能体用户输入载荷。
-func buildMainAgentInput(results modalityResult) string {
-	textInput := results.Text
+// buildMainAgentInput 构建主智能体用户输入载荷，各模态段落按注册顺序排列，末尾附确定性抽取的结构化线索。
+func buildMainAgentInput(text string, outcomes []modalityOutcome, extracted []state.Indicator) string {
+	textInput := text
 	if textInput == "" {
 		textInput = "No text input provided."
 	}
 
-	return fmt.Sprintf(
-		"[User Text]\n%s\n\n[Image Insights]\n%s\n\n[Video Insights]\n%s\n\n[Audio Insights]\n%s",
-		textInput,
-		results.Image,
-		results.Video,
-		results.Audio,
-	)
+	var builder strings.Builder
+	builder.WriteString("[User Text]\n")
+	builder.WriteString(textInput)
+	for _, outcome := range outcomes {
+		builder.WriteString(fmt.Sprintf("\n\n[%s Insights]\n%s", outcome.analyzer.Label(), outcome.summary))
+	}
+	if len(extracted) > 0 {
+		builder.WriteString("\n\n[Extracted Indicators]")
+		for _, indicator := range extracted {
+			builder.WriteString(fmt.Sprintf("\n- %s: %s (sources: %s)", indicator.Kind, indicator.Value, strings.Join(indicator.Sources, ",")))
+		}
+	}
+	return builder.String()
+}
+
+// indicatorSources 按“文本 + 各模态解读”的顺序组织结构化线索的抽取来源。
+func indicatorSources(text string, outcomes []modalityOutcome) []indicators.Source {
+	sources := []indicators.Source{{Name: indicators.SourceText, Text: text}}
+	for _, outcome := range outcomes {
+		for _, insight := range outcome.insights {
+			sources = append(sources, indicators.Source{Name: outcome.analyzer.Kind(), Text: insight})
+		}
+	}
+	return sources
 }
 
 // generateReport 驱动主智能体工具调用循环，直到拿到终态报告。
-func (a *MainAgent) generateReport(ctx context.Context, finalInput string, userID string, taskID string, rawText string, rawVideos []string, rawAudios []string, rawImages []string) (string, error) {
+func (a *MainAgent) generateReport(ctx context.Context, finalInput string, userID string, taskID string, payload state.TaskPayload) (string, error) {
 	// 工具执行依赖的关键上下文在这里统一绑定：
 	// - user_id / task_id：用于用户查询与任务级归档定位
-	// - 原始 payload（text/videos/audios/images）：用于落库保留原始输入
+	// - payload（脱敏后的 text + 各模态输入）：用于落库保留输入
 	ctx = tool.BindUserID(ctx, userID)
 	ctx = tool.BindTaskID(ctx, taskID)
-	ctx = tool.BindTaskPayload(ctx, rawText, rawVideos, rawAudios, rawImages)
+	ctx = tool.BindTaskPayload(ctx, payload)
 
 	if a.client == nil {
 		return "", fmt.Errorf("main agent client is not initialized")
@@ -238,10 +305,16 @@ func (a *MainAgent) generateReport(ctx context.Context, finalInput string, userI
 	historyCaseWritten := false
 
 	for round := 0; round < maxRounds; round++ {
+		if err := ctx.Err(); err != nil {
+			return "", fmt.Errorf("main agent cancelled before round %d: %w", round+1, err)
+		}
+		reportTaskProgress(userID, taskID, state.TaskProgressStageMainAgentRound, fmt.Sprintf("主智能体第 %d 轮推理", round+1), map[string]interface{}{
+			"round": round + 1,
+		})
 		action := fmt.Sprintf("create chat completion round %d", round+1)
 		fmt.Printf("[MainAgent][Round %d] request model=%s messages=%d\n", round+1, strings.TrimSpace(a.modelID), len(messages))
 		var resp openai.ChatCompletionResponse
-		if err := a.Retry(action, func() error {
+		if err := a.RetryWithContext(ctx, action, func() error {
 			var callErr error
 			resp, callErr = a.client.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
 				Model:       a.modelID,
@@ -291,12 +364,14 @@ func (a *MainAgent) generateReport(ctx context.Context, finalInput string, userI
 
 		toolResponseAdded := false
 		appendToolResponse := func(callID string, payload map[string]interface{}) {
-			toolPayload, _ := json.Marshal(payload)
-			fmt.Printf("[MainAgent][Round %d] tool_result call_id=%s payload=%s\n", round+1, strings.TrimSpace(callID), truncateForLog(string(toolPayload), 360))
+			encoded, _ := json.Marshal(payload)
+			// 工具结果可能带回用户画像、历史案件等原文，回传模型前按任务映射脱敏；占位符不含引号与反斜杠，不会破坏 JSON。
+			toolPayload := redaction.FromContext(ctx).Redact(string(encoded))
+			fmt.Printf("[MainAgent][Round %d] tool_result call_id=%s payload=%s\n", round+1, strings.TrimSpace(callID), truncateForLog(toolPayload, 360))
 			messages = append(messages, openai.ChatCompletionMessage{
 				Role:       openai.ChatMessageRoleTool,
 				ToolCallID: callID,
-				Content:    string(toolPayload),
+				Content:    toolPayload,
 			})
 			toolResponseAdded = true
 		}
@@ -316,6 +391,7 @@ func (a *MainAgent) generateReport(ctx context.Context, finalInput string, userI
 			if handler == nil {
 				appendToolResponse(call.ID, map[string]interface{}{"error": "unsupported tool"})
 				fmt.Printf("[MainAgent][Round %d] unsupported tool: %s\n", round+1, call.Function.Name)
+				reportToolCallProgress(userID, taskID, round+1, call.Function.Name, "unsupported")
 				continue
 			}
 
@@ -323,10 +399,12 @@ func (a *MainAgent) generateReport(ctx context.Context, finalInput string, userI
 			if err != nil {
 				appendToolResponse(call.ID, map[string]interface{}{"error": err.Error()})
 				fmt.Printf("[MainAgent][Round %d] tool handler error: %v\n", round+1, err)
+				reportToolCallProgress(userID, taskID, round+1, call.Function.Name, "error")
 				continue
 			}
 
 			appendToolResponse(call.ID, response.Payload)
+			reportToolCallProgress(userID, taskID, round+1, call.Function.Name, "ok")
 			if response.ContextMutator != nil {
 				ctx = response.ContextMutator(ctx)
 			}
@@ -336,6 +414,10 @@ func (a *MainAgent) generateReport(ctx context.Context, finalInput string, userI
 				// 供 write_user_history_case 在归档时读取并持久化。
 				ctx = tool.BindFinalReport(ctx, finalResult)
 				fmt.Printf("[MainAgent][Round %d] final_result updated, len=%d\n", round+1, len(strings.TrimSpace(finalResult)))
+				reportTaskProgress(userID, taskID, state.TaskProgressStageFinalReport, "最终报告已生成", map[string]interface{}{
+					"round":  round + 1,
+					"report": finalResult,
+				})
 			}
 		}
 
@@ -356,6 +438,23 @@ func (a *MainAgent) generateReport(ctx context.Context, finalInput string, userI
 	return "", fmt.Errorf("main agent exceeded max tool rounds (%d) without final result", maxRounds)
 }
 
+// reportTaskProgress 记录任务阶段事件；无 taskID 的同步调用（如探测脚本）不产生事件。
+func reportTaskProgress(userID string, taskID string, stage string, message string, data map[string]interface{}) {
+	if strings.TrimSpace(taskID) == "" {
+		return
+	}
+	state.AppendTaskProgressEvent(userID, taskID, stage, message, data)
+}
+
+// reportToolCallProgress 记录主智能体单次工具调用的执行结果。
+func reportToolCallProgress(userID string, taskID string, round int, toolName string, status string) {
+	reportTaskProgress(userID, taskID, state.TaskProgressStageToolCall, fmt.Sprintf("调用工具 %s", strings.TrimSpace(toolName)), map[string]interface{}{
+		"round":  round,
+		"tool":   strings.TrimSpace(toolName),
+		"status": status,
+	})
+}
+
 func truncateForLog(input string, maxLen int) string {
 	trimmed := strings.TrimSpace(input)
 	if trimmed == "" {
@@ -0,0 +1,164 @@
+package multi_agent
+
+import (
+	"antifraud/internal/modules/multi_agent/adapters/outbound/state"
+	"antifraud/internal/platform/config"
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// ModalityAnalyzer 定义一种输入模态接入多模态分析流水线所需的能力。
+// 新增输入类型（PDF、聊天导出文件、URL、二维码等）只需实现本接口并调用 RegisterModalityAnalyzer，
+// 主智能体会按注册顺序并行调用各模态子智能体，并把解读结果写入 TaskPayload 中以 Kind 为 key 的插槽。
+type ModalityAnalyzer interface {
+	// Kind 是模态名，同时作为请求 inputs、TaskPayload 插槽与进度事件中的 key。
+	Kind() string
+	// Label 用于主智能体输入中的段落标题，例如 "Image" 对应 "[Image Insights]"。
+	Label() string
+	// Preprocess 在任务入队前对单个输入做规范化，返回值会作为持久化的原始输入。
+	// 已上传的媒体以 blob:<sha256> 引用传入，原样返回即可，worker 分析前会把引用还原为 data url。
+	Preprocess(ctx context.Context, input string) (string, error)
+	// AnalyzeBatch 并行分析同一模态的全部输入，返回与输入一一对应的解读文本。
+	AnalyzeBatch(ctx context.Context, inputs []string) []string
+}
+
+var (
+	modalityRegistryMu sync.RWMutex
+	modalityRegistry   []ModalityAnalyzer
+)
+
+func init() {
+	// 内置模态的注册顺序决定主智能体输入中的段落顺序，保持与历史版本一致。
+	for _, analyzer := range []ModalityAnalyzer{
+		builtinModalityAnalyzer{kind: state.ModalityImage, label: "Image", analyze: AnalyzeImagesParallel},
+		builtinModalityAnalyzer{kind: state.ModalityVideo, label: "Video", analyze: AnalyzeVideosParallel},
+		builtinModalityAnalyzer{kind: state.ModalityAudio, label: "Audio", analyze: AnalyzeAudiosParallel},
+	} {
+		if err := RegisterModalityAnalyzer(analyzer); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// RegisterModalityAnalyzer 注册一种输入模态；模态名为空、与文本输入冲突或重复注册时返回错误。
+func RegisterModalityAnalyzer(analyzer ModalityAnalyzer) error {
+	if analyzer == nil {
+		return fmt.Errorf("modality analyzer is nil")
+	}
+	kind := strings.TrimSpace(analyzer.Kind())
+	if kind == "" {
+		return fmt.Errorf("modality analyzer kind is empty")
+	}
+	if kind == "text" {
+		return fmt.Errorf("modality analyzer kind %q is reserved", kind)
+	}
+
+	modalityRegistryMu.Lock()
+	defer modalityRegistryMu.Unlock()
+	for _, existing := range modalityRegistry {
+		if existing.Kind() == kind {
+			return fmt.Errorf("modality analyzer %q already registered", kind)
+		}
+	}
+	modalityRegistry = append(modalityRegistry, analyzer)
+	return nil
+}
+
+// LookupModalityAnalyzer 按模态名查找已注册的分析器。
+func LookupModalityAnalyzer(kind string) (ModalityAnalyzer, bool) {
+	target := strings.TrimSpace(kind)
+	modalityRegistryMu.RLock()
+	defer modalityRegistryMu.RUnlock()
+	for _, analyzer := range modalityRegistry {
+		if analyzer.Kind() == target {
+			return analyzer, true
+		}
+	}
+	return nil, false
+}
+
+// RegisteredModalityAnalyzers 按注册顺序返回全部分析器的快照。
+func RegisteredModalityAnalyzers() []ModalityAnalyzer {
+	modalityRegistryMu.RLock()
+	defer modalityRegistryMu.RUnlock()
+	return append([]ModalityAnalyzer{}, modalityRegistry...)
+}
+
+// builtinModalityAnalyzer 适配内置的图片/视频/音频子智能体。
+// 内置模态的转码预处理依赖 ffmpeg，仍由 application.NormalizeTaskPayload 负责，这里原样返回。
+type builtinModalityAnalyzer struct {
+	kind    string
+	label   string
+	analyze func(ctx context.Context, inputs []string) []string
+}
+
+func (a builtinModalityAnalyzer) Kind() string {
+	return a.kind
+}
+
+func (a builtinModalityAnalyzer) Label() string {
+	return a.label
+}
+
+func (a builtinModalityAnalyzer) Preprocess(ctx context.Context, input string) (string, error) {
+	return input, nil
+}
+
+func (a builtinModalityAnalyzer) AnalyzeBatch(ctx context.Context, inputs []string) []string {
+	return a.analyze(ctx, inputs)
+}
+
+// ProfileModalityAnalyzer 基于 SubAgentProfile 快速接入新模态：
+// 复用 SubAgentBase 的请求构造、重试与统一分析工具，只需声明数据编码方式、提示词与所用模型。
+type ProfileModalityAnalyzer struct {
+	ModalityKind  string
+	ModalityLabel string
+	AgentName     string
+	Profile       SubAgentProfile
+	// ModelConfig 从全局配置中选择该模态使用的模型，例如 cfg.Agents.Image。
+	ModelConfig func(cfg *config.Config) config.ModelConfig
+	// PreprocessInput 可选，入队前规范化单个输入；为空时原样保留。
+	PreprocessInput func(ctx context.Context, input string) (string, error)
+}
+
+func (a ProfileModalityAnalyzer) Kind() string {
+	return strings.TrimSpace(a.ModalityKind)
+}
+
+func (a ProfileModalityAnalyzer) Label() string {
+	if label := strings.TrimSpace(a.ModalityLabel); label != "" {
+		return label
+	}
+	return a.Kind()
+}
+
+func (a ProfileModalityAnalyzer) Preprocess(ctx context.Context, input string) (string, error) {
+	if a.PreprocessInput == nil {
+		return input, nil
+	}
+	return a.PreprocessInput(ctx, input)
+}
+
+func (a ProfileModalityAnalyzer) AnalyzeBatch(ctx context.Context, inputs []string) []string {
+	if a.ModelConfig == nil {
+		return []string{fmt.Sprintf("Error: model config selector is not configured for %s", a.Kind())}
+	}
+	cfg, err := config.LoadConfig("internal/platform/config/config.json")
+	if err != nil {
+		fmt.Printf("Error loading config: %v\n", err)
+		return []string{fmt.Sprintf("Error loading config: %v", err)}
+	}
+
+	profile := a.Profile
+	if strings.TrimSpace(profile.Modality) == "" {
+		profile.Modality = a.Kind()
+	}
+	agentName := strings.TrimSpace(a.AgentName)
+	if agentName == "" {
+		agentName = a.Label() + "Agent"
+	}
+	agent := NewSubAgentBase(agentName, a.ModelConfig(cfg), cfg.Retry, profile)
+	return agent.AnalyzeBatchInParallel(ctx, inputs)
+}
@@ -3,6 +3,7 @@ package multi_agent
 import (
 	"antifraud/internal/modules/multi_agent/adapters/outbound/tool"
 	"antifraud/internal/platform/config"
+	"antifraud/internal/platform/redaction"
 	"context"
 	"fmt"
 	"strings"
@@ -124,14 +125,13 @@ func (a *VideoAgent) AnalyzeWithTranscript(ctx context.Context, videoBase64 stri
 	return strings.TrimSpace(result + "\n\n【视频音轨ASR转写】\n" + trimmedTranscript), nil
 }
 
-func AnalyzeVideosParallel(videosBase64 []string) []string {
+func AnalyzeVideosParallel(ctx context.Context, videosBase64 []string) []string {
 	cfg, err := config.LoadConfig("internal/platform/config/config.json")
 	if err != nil {
 		fmt.Printf("Error loading config: %v\n", err)
 		return []string{fmt.Sprintf("Error loading config: %v", err)}
 	}
 
-	ctx := context.Background()
 	videoAgent := NewVideoAgent(cfg.Agents.Video, cfg.Retry, cfg.Prompts.Video)
 	asrAgent := NewASRAgent(cfg.Agents.ASR, cfg.Retry)
 
@@ -144,7 +144,7 @@ func AnalyzeVideosParallel(videosBase64 []string) []string {
 			defer wg.Done()
 			fmt.Printf("[VideoAgent] starting analysis for video %d...\n", index+1)
 
-			videoForAnalysis, prepareErr := compressVideoForAnalysis(input)
+			videoForAnalysis, prepareErr := compressVideoForAnalysis(ctx, input)
 			if prepareErr != nil {
 				results[index] = fmt.Sprintf("Error: prepare video failed: %v", prepareErr)
 				fmt.Printf("[VideoAgent] video prepare failed for video %d: %v\n", index+1, prepareErr)
@@ -155,6 +155,8 @@ func AnalyzeVideosParallel(videosBase64 []string) []string {
 			if transcriptErr != nil {
 				fmt.Printf("[VideoAgent] asr skipped for video %d: %v\n", index+1, transcriptErr)
 			}
+			// 转写文本会拼入视频模型的提示词，先按任务映射脱敏，通话中报出的号码、姓名不原样外发。
+			transcript = redaction.FromContext(ctx).Redact(transcript)
 
 			result, err := videoAgent.AnalyzeWithTranscript(ctx, videoForAnalysis, transcript, index)
 			if err != nil {
@@ -180,7 +182,7 @@ func transcribeVideoAudio(ctx context.Context, asrAgent *ASRAgent, videoBase64 s
 		return "", fmt.Errorf("ASR agent is nil")
 	}
 
-	audioData, err := extractAudioTrackForASR(videoBase64)
+	audioData, err := extractAudioTrackForASR(ctx, videoBase64)
 	if err != nil {
 		return "", fmt.Errorf("extract audio track failed: %w", err)
 	}
@@ -60,35 +60,35 @@ var (
 	}
 )
 
-func compressVideoForAnalysis(videoInput string) (string, error) {
+func compressVideoForAnalysis(ctx context.Context, videoInput string) (string, error) {
 	raw, err := decodeVideoInputRaw(videoInput)
 	if err != nil {
 		return "", err
 	}
 
-	output, err := transcodeVideoBranch(raw)
+	output, err := transcodeVideoBranch(ctx, raw)
 	if err != nil {
 		return "", err
 	}
 	return fmt.Sprintf("data:video/mp4;base64,%s", base64.StdEncoding.EncodeToString(output)), nil
 }
 
-func extractAudioTrackForASR(videoInput string) (string, error) {
+func extractAudioTrackForASR(ctx context.Context, videoInput string) (string, error) {
 	raw, err := decodeVideoInputRaw(videoInput)
 	if err != nil {
 		return "", err
 	}
 
-	output, err := transcodeASRAudioBranch(raw)
+	output, err := transcodeASRAudioBranch(ctx, raw)
 	if err != nil {
 		return "", err
 	}
 	return fmt.Sprintf("data:audio/mpeg;base64,%s", base64.StdEncoding.EncodeToString(output)), nil
 }
 
-func transcodeVideoBranch(raw []byte) ([]byte, error) {
+func transcodeVideoBranch(ctx context.Context, raw []byte) ([]byte, error) {
 	return transcodeVideoMedia(raw, ".mp4", func(inPath string, outPath string) error {
-		durationSeconds, probeErr := probeCoreMediaDurationSeconds(inPath)
+		durationSeconds, probeErr := probeCoreMediaDurationSeconds(ctx, inPath)
 		if probeErr != nil {
 			return fmt.Errorf("读取视频时长失败: %w", probeErr)
 		}
@@ -112,7 +112,7 @@ func transcodeVideoBranch(raw []byte) ([]byte, error) {
 				"-b:a", preset.AudioRate,
 				outPath,
 			}
-			ok, runErr := runCoreFFmpegAttempt(args, outPath)
+			ok, runErr := runCoreFFmpegAttempt(ctx, args, outPath)
 			if runErr != nil {
 				return runErr
 			}
@@ -124,9 +124,9 @@ func transcodeVideoBranch(raw []byte) ([]byte, error) {
 	})
 }
 
-func transcodeASRAudioBranch(raw []byte) ([]byte, error) {
+func transcodeASRAudioBranch(ctx context.Context, raw []byte) ([]byte, error) {
 	return transcodeAudioMedia(raw, ".mp4", ".mp3", func(inPath string, outPath string) error {
-		durationSeconds, probeErr := probeCoreMediaDurationSeconds(inPath)
+		durationSeconds, probeErr := probeCoreMediaDurationSeconds(ctx, inPath)
 		if probeErr != nil {
 			return fmt.Errorf("读取视频时长失败: %w", probeErr)
 		}
@@ -146,7 +146,7 @@ func transcodeASRAudioBranch(raw []byte) ([]byte, error) {
 				"-b:a", preset.Bitrate,
 				outPath,
 			}
-			ok, runErr := runCoreFFmpegAttempt(args, outPath)
+			ok, runErr := runCoreFFmpegAttempt(ctx, args, outPath)
 			if runErr != nil {
 				return runErr
 			}
@@ -245,18 +245,21 @@ func decodeVideoInputRaw(input string) ([]byte, error) {
 	return raw, nil
 }
 
-func runCoreFFmpegAttempt(args []string, outPath string) (bool, error) {
+func runCoreFFmpegAttempt(ctx context.Context, args []string, outPath string) (bool, error) {
 	ffmpegPath, err := lookupCoreFFmpegPath()
 	if err != nil {
 		return false, err
 	}
 
-	cmdCtx, cancel := context.WithTimeout(context.Background(), videoAgentFFmpegTimeout)
+	cmdCtx, cancel := context.WithTimeout(ctx, videoAgentFFmpegTimeout)
 	defer cancel()
 
 	cmd := exec.CommandContext(cmdCtx, ffmpegPath, args...)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
+		if ctx.Err() != nil {
+			return false, fmt.Errorf("ffmpeg 处理已取消: %w", ctx.Err())
+		}
 		if cmdCtx.Err() == context.DeadlineExceeded {
 			return false, fmt.Errorf("ffmpeg 处理超时: %w", err)
 		}
@@ -311,13 +314,13 @@ func resolveCoreBinaryPath(toolName string, configured string) (string, error) {
 	return "", fmt.Errorf("未找到 %s。请先安装 ffmpeg，并在 config.json 的 media_tools 中配置路径，或将 %s 加入 PATH", toolName, trimmed)
 }
 
-func probeCoreMediaDurationSeconds(path string) (float64, error) {
+func probeCoreMediaDurationSeconds(ctx context.Context, path string) (float64, error) {
 	ffprobePath, err := lookupCoreFFprobePath()
 	if err != nil {
 		return 0, err
 	}
 
-	cmdCtx, cancel := context.WithTimeout(context.Background(), videoAgentFFmpegTimeout)
+	cmdCtx, cancel := context.WithTimeout(ctx, videoAgentFFmpegTimeout)
 	defer cancel()
 
 	args := []string{
@@ -329,6 +332,9 @@ func probeCoreMediaDurationSeconds(path string) (float64, error) {
 	cmd := exec.CommandContext(cmdCtx, ffprobePath, args...)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
+		if ctx.Err() != nil {
+			return 0, fmt.Errorf("ffprobe 处理已取消: %w", ctx.Err())
+		}
 		if cmdCtx.Err() == context.DeadlineExceeded {
 			return 0, fmt.Errorf("ffprobe 处理超时: %w", err)
 		}
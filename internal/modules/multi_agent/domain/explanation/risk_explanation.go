@@ -0,0 +1,309 @@
+package explanation
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"antifraud/internal/modules/multi_agent/adapters/outbound/state"
+	"antifraud/internal/modules/multi_agent/domain/scoring"
+)
+
+const (
+	// SourceBreakdown 表示解释来自归档时保存的完整评分明细。
+	SourceBreakdown = "breakdown"
+	// SourceRiskSummary 表示早期记录没有评分明细，解释由 risk_summary 结构化摘要回推，不含动态判级部分。
+	SourceRiskSummary = "risk_summary"
+	// SourceNone 表示记录既无评分明细也无结构化摘要，只能给出风险等级本身。
+	SourceNone = "none"
+)
+
+// maxNotificationReasons 是家庭通知中附带的理由条数上限，避免推送文案过长。
+const maxNotificationReasons = 3
+
+var dimensionLabels = map[string]string{
+	scoring.DimensionSocialEngineering: "话术操控",
+	scoring.DimensionRequestedActions:  "索要动作",
+	scoring.DimensionEvidenceStrength:  "证据强度",
+	scoring.DimensionLossExposure:      "损失暴露",
+}
+
+var dimensionOrder = []string{
+	scoring.DimensionSocialEngineering,
+	scoring.DimensionRequestedActions,
+	scoring.DimensionEvidenceStrength,
+	scoring.DimensionLossExposure,
+}
+
+// RiskExplanation 是单条历史案件"为什么是这个风险等级"的结构化解释。
+// Headline 与 Reasons 是可直接展示的文案，其余字段供前端按需渲染明细。
+type RiskExplanation struct {
+	RecordID            string                   `json:"record_id"`
+	RiskLevel           string                   `json:"risk_level"`
+	RiskScore           int                      `json:"risk_score"`
+	RuleVersion         string                   `json:"rule_version,omitempty"`
+	Source              string                   `json:"source"`
+	Headline            string                   `json:"headline"`
+	Reasons             []string                 `json:"reasons"`
+	Dimensions          []DimensionContribution  `json:"dimensions"`
+	Factors             []FactorContribution     `json:"factors"`
+	HitRules            []string                 `json:"hit_rules"`
+	KeyEvidence         []string                 `json:"key_evidence"`
+	VictimActionStage   string                   `json:"victim_action_stage,omitempty"`
+	SimilarCaseStrength string                   `json:"similar_case_strength,omitempty"`
+	MultimodalEvidence  string                   `json:"multimodal_evidence,omitempty"`
+	Dynamic             *DynamicLevelExplanation `json:"dynamic,omitempty"`
+}
+
+// DimensionContribution 是单个评分维度的得分。
+type DimensionContribution struct {
+	Key   string `json:"key"`
+	Label string `json:"label"`
+	Score int    `json:"score"`
+}
+
+// FactorContribution 是单个命中因子的计分。
+type FactorContribution struct {
+	Key            string `json:"key"`
+	Label          string `json:"label"`
+	Dimension      string `json:"dimension"`
+	DimensionLabel string `json:"dimension_label"`
+	Weight         int    `json:"weight"`
+}
+
+// DynamicLevelExplanation 解释动态阈值与相似命中如何把分数映射为最终等级。
+type DynamicLevelExplanation struct {
+	BaseScore               int    `json:"base_score"`
+	HistoricalScore         int    `json:"historical_score"`
+	DynamicThreshold        int    `json:"dynamic_threshold"`
+	KnowledgeBaseHit        string `json:"knowledge_base_hit"`
+	KnowledgeBaseAdjustment int    `json:"knowledge_base_adjustment"`
+	UserHistoryHit          string `json:"user_history_hit"`
+	UserHistoryAdjustment   int    `json:"user_history_adjustment"`
+	AdjustedScore           int    `json:"adjusted_score"`
+	Summary                 string `json:"summary"`
+}
+
+// legacyRiskSummary 对应评分工具写入 risk_summary 的结构化 JSON。
+type legacyRiskSummary struct {
+	Score               int            `json:"score"`
+	Dimensions          map[string]int `json:"dimensions"`
+	VictimActionStage   string         `json:"victim_action_stage"`
+	SimilarCaseStrength string         `json:"similar_case_strength"`
+	MultimodalEvidence  string         `json:"multimodal_evidence"`
+	HitRules            []string       `json:"hit_rules"`
+	KeyEvidence         []string       `json:"key_evidence"`
+	RuleSetVersion      string         `json:"rule_set_version"`
+}
+
+// BuildRiskExplanation 根据历史案件生成风险等级解释。
+// 优先使用归档时保存的评分明细；早期记录回退到 risk_summary 结构化摘要。
+func BuildRiskExplanation(record state.CaseHistoryRecord) RiskExplanation {
+	breakdown, source := resolveBreakdown(record)
+
+	result := RiskExplanation{
+		RecordID:            strings.TrimSpace(record.RecordID),
+		RiskLevel:           strings.TrimSpace(record.RiskLevel),
+		RiskScore:           record.RiskScore,
+		RuleVersion:         firstNonEmpty(breakdown.RuleVersion, record.RuleVersion),
+		Source:              source,
+		Dimensions:          buildDimensions(breakdown.Dimensions),
+		Factors:             buildFactors(breakdown.HitFactors),
+		HitRules:            cleanList(breakdown.HitRules),
+		KeyEvidence:         cleanList(breakdown.KeyEvidence),
+		VictimActionStage:   strings.TrimSpace(breakdown.VictimActionStage),
+		SimilarCaseStrength: strings.TrimSpace(breakdown.SimilarCaseStrength),
+		MultimodalEvidence:  strings.TrimSpace(breakdown.MultimodalEvidence),
+	}
+	if result.RiskScore <= 0 {
+		result.RiskScore = breakdown.Score
+	}
+	if breakdown.Dynamic != nil {
+		result.Dynamic = buildDynamic(*breakdown.Dynamic)
+	}
+	result.Headline = buildHeadline(result)
+	result.Reasons = buildReasons(result)
+	return result
+}
+
+// NotificationReasons 返回适合推送给家庭守护人的精简理由。
+func (e RiskExplanation) NotificationReasons() []string {
+	if len(e.Reasons) <= maxNotificationReasons {
+		return append([]string{}, e.Reasons...)
+	}
+	return append([]string{}, e.Reasons[:maxNotificationReasons]...)
+}
+
+func resolveBreakdown(record state.CaseHistoryRecord) (state.RiskBreakdown, string) {
+	if record.RiskBreakdown != nil {
+		return *record.RiskBreakdown, SourceBreakdown
+	}
+	summary := strings.TrimSpace(record.RiskSummary)
+	if summary == "" {
+		return state.RiskBreakdown{}, SourceNone
+	}
+	var legacy legacyRiskSummary
+	if err := json.Unmarshal([]byte(summary), &legacy); err != nil {
+		return state.RiskBreakdown{}, SourceNone
+	}
+	return state.RiskBreakdown{
+		Score:               legacy.Score,
+		RuleVersion:         legacy.RuleSetVersion,
+		Dimensions:          legacy.Dimensions,
+		HitRules:            legacy.HitRules,
+		KeyEvidence:         legacy.KeyEvidence,
+		VictimActionStage:   legacy.VictimActionStage,
+		SimilarCaseStrength: legacy.SimilarCaseStrength,
+		MultimodalEvidence:  legacy.MultimodalEvidence,
+	}, SourceRiskSummary
+}
+
+func buildDimensions(dimensions map[string]int) []DimensionContribution {
+	result := make([]DimensionContribution, 0, len(dimensionOrder))
+	if len(dimensions) == 0 {
+		return result
+	}
+	for _, key := range dimensionOrder {
+		result = append(result, DimensionContribution{Key: key, Label: dimensionLabels[key], Score: dimensions[key]})
+	}
+	return result
+}
+
+// buildFactors 按权重从高到低排列命中因子，权重相同保持规则文件中的顺序。
+func buildFactors(hits []state.RiskFactorHit) []FactorContribution {
+	result := make([]FactorContribution, 0, len(hits))
+	for _, hit := range hits {
+		result = append(result, FactorContribution{
+			Key:            strings.TrimSpace(hit.Key),
+			Label:          strings.TrimSpace(hit.Label),
+			Dimension:      strings.TrimSpace(hit.Dimension),
+			DimensionLabel: dimensionLabels[strings.TrimSpace(hit.Dimension)],
+			Weight:         hit.Weight,
+		})
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Weight > result[j].Weight
+	})
+	return result
+}
+
+func buildDynamic(dynamic state.DynamicRiskBreakdown) *DynamicLevelExplanation {
+	result := &DynamicLevelExplanation{
+		BaseScore:               dynamic.BaseScore,
+		HistoricalScore:         dynamic.HistoricalScore,
+		DynamicThreshold:        dynamic.DynamicThreshold,
+		KnowledgeBaseHit:        normalizeHit(dynamic.KnowledgeBaseHit),
+		KnowledgeBaseAdjustment: dynamic.KnowledgeBaseAdjustment,
+		UserHistoryHit:          normalizeHit(dynamic.UserHistoryHit),
+		UserHistoryAdjustment:   dynamic.UserHistoryAdjustment,
+		AdjustedScore:           dynamic.AdjustedScore,
+	}
+	relation := "未超过"
+	if result.AdjustedScore > result.DynamicThreshold {
+		relation = "超过"
+	}
+	result.Summary = fmt.Sprintf("用户历史风险分 %d，动态阈值 %d；本案评分 %d，结合相似案件调整后为 %d，%s阈值。",
+		result.HistoricalScore, result.DynamicThreshold, result.BaseScore, result.AdjustedScore, relation)
+	return result
+}
+
+func buildHeadline(e RiskExplanation) string {
+	level := e.RiskLevel
+	if level == "" {
+		level = "未知"
+	}
+	if e.Dynamic != nil {
+		gap := e.Dynamic.AdjustedScore - e.Dynamic.DynamicThreshold
+		if gap > 0 {
+			return fmt.Sprintf("判定为%s风险：调整后得分 %d，高出动态阈值 %d 分。", level, e.Dynamic.AdjustedScore, gap)
+		}
+		return fmt.Sprintf("判定为%s风险：调整后得分 %d，未超过动态阈值 %d。", level, e.Dynamic.AdjustedScore, e.Dynamic.DynamicThreshold)
+	}
+	if e.RiskScore > 0 {
+		return fmt.Sprintf("判定为%s风险：风险评分 %d。", level, e.RiskScore)
+	}
+	return fmt.Sprintf("判定为%s风险。", level)
+}
+
+// buildReasons 按"对结论影响大小"排序生成理由：组合规则与受害动作优先，其次高权重因子，再到动态判级与证据。
+func buildReasons(e RiskExplanation) []string {
+	reasons := make([]string, 0, 8)
+	for _, rule := range e.HitRules {
+		if name, ok := strings.CutPrefix(rule, "组合规则："); ok {
+			reasons = append(reasons, fmt.Sprintf("同时出现「%s」，触发组合保底规则。", name))
+		}
+	}
+	if e.VictimActionStage != "" && e.VictimActionStage != "未操作" {
+		reasons = append(reasons, fmt.Sprintf("用户%s，存在实际损失风险。", e.VictimActionStage))
+	}
+	for _, factor := range e.Factors {
+		reasons = append(reasons, fmt.Sprintf("命中风险因子「%s」（%s +%d）。", factor.Label, factor.DimensionLabel, factor.Weight))
+	}
+	if len(e.Factors) == 0 {
+		// 早期记录没有因子明细，退回展示命中规则文本。
+		for _, rule := range e.HitRules {
+			if strings.Contains(rule, "：") {
+				continue
+			}
+			reasons = append(reasons, fmt.Sprintf("命中风险因子「%s」。", rule))
+		}
+	}
+	if e.Dynamic != nil {
+		if adjustment := describeHit("知识库", e.Dynamic.KnowledgeBaseHit, e.Dynamic.KnowledgeBaseAdjustment); adjustment != "" {
+			reasons = append(reasons, adjustment)
+		}
+		if adjustment := describeHit("用户历史", e.Dynamic.UserHistoryHit, e.Dynamic.UserHistoryAdjustment); adjustment != "" {
+			reasons = append(reasons, adjustment)
+		}
+		reasons = append(reasons, e.Dynamic.Summary)
+	}
+	if len(e.KeyEvidence) > 0 {
+		reasons = append(reasons, fmt.Sprintf("关键证据：%s", e.KeyEvidence[0]))
+	}
+	return reasons
+}
+
+func describeHit(source string, hit string, adjustment int) string {
+	switch hit {
+	case "high":
+		return fmt.Sprintf("%s中命中高风险相似案件（%+d）。", source, adjustment)
+	case "low":
+		if adjustment == 0 {
+			return fmt.Sprintf("%s中命中低风险相似案件，但当前分数明显偏高，未下调。", source)
+		}
+		return fmt.Sprintf("%s中命中低风险相似案件（%+d）。", source, adjustment)
+	default:
+		return ""
+	}
+}
+
+func normalizeHit(raw string) string {
+	switch strings.TrimSpace(raw) {
+	case "high":
+		return "high"
+	case "low":
+		return "low"
+	default:
+		return "none"
+	}
+}
+
+func cleanList(items []string) []string {
+	result := make([]string, 0, len(items))
+	for _, item := range items {
+		if trimmed := strings.TrimSpace(item); trimmed != "" {
+			result = append(result, trimmed)
+		}
+	}
+	return result
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if trimmed := strings.TrimSpace(value); trimmed != "" {
+			return trimmed
+		}
+	}
+	return ""
+}
@@ -0,0 +1,98 @@
+package explanation_test
+
+import (
+	"strings"
+	"testing"
+
+	"antifraud/internal/modules/multi_agent/adapters/outbound/state"
+	"antifraud/internal/modules/multi_agent/domain/explanation"
+)
+
+func TestBuildRiskExplanation_FromBreakdown(t *testing.T) {
+	record := state.CaseHistoryRecord{
+		RecordID:  "TASK-1",
+		RiskLevel: "高",
+		RiskScore: 76,
+		RiskBreakdown: &state.RiskBreakdown{
+			Score:       76,
+			RuleVersion: "2026.03-baseline",
+			Dimensions:  map[string]int{"social_engineering": 8, "requested_actions": 36, "loss_exposure": 0},
+			HitFactors: []state.RiskFactorHit{
+				{Key: "impersonation", Label: "冒充身份", Dimension: "social_engineering", Weight: 8},
+				{Key: "money_transfer_request", Label: "要求转账/充值", Dimension: "requested_actions", Weight: 20},
+				{Key: "private_account_collection", Label: "要求向私人账户收款", Dimension: "requested_actions", Weight: 16},
+			},
+			HitRules:          []string{"冒充身份", "要求转账/充值", "要求向私人账户收款", "受害动作阶段：未操作", "组合规则：转账+私人账户收款"},
+			KeyEvidence:       []string{"对方要求转账到个人账户"},
+			VictimActionStage: "未操作",
+			Dynamic: &state.DynamicRiskBreakdown{
+				BaseScore:               76,
+				HistoricalScore:         65,
+				DynamicThreshold:        45,
+				KnowledgeBaseHit:        "high",
+				KnowledgeBaseAdjustment: 8,
+				UserHistoryHit:          "none",
+				AdjustedScore:           84,
+				RiskLevel:               "高",
+			},
+		},
+	}
+
+	result := explanation.BuildRiskExplanation(record)
+
+	if result.Source != explanation.SourceBreakdown || result.RuleVersion != "2026.03-baseline" {
+		t.Fatalf("unexpected source/version: %+v", result)
+	}
+	if len(result.Factors) != 3 || result.Factors[0].Key != "money_transfer_request" || result.Factors[0].DimensionLabel == "" {
+		t.Fatalf("expected factors sorted by weight with labels, got %+v", result.Factors)
+	}
+	if len(result.Dimensions) != 4 || result.Dimensions[1].Score != 36 {
+		t.Fatalf("unexpected dimensions: %+v", result.Dimensions)
+	}
+	if result.Dynamic == nil || result.Dynamic.AdjustedScore != 84 || result.Dynamic.Summary == "" {
+		t.Fatalf("unexpected dynamic explanation: %+v", result.Dynamic)
+	}
+	if !strings.Contains(result.Headline, "高风险") || !strings.Contains(result.Headline, "39") {
+		t.Fatalf("unexpected headline: %q", result.Headline)
+	}
+	if len(result.Reasons) == 0 || !strings.Contains(result.Reasons[0], "转账+私人账户收款") {
+		t.Fatalf("expected combination rule to lead reasons, got %+v", result.Reasons)
+	}
+	joined := strings.Join(result.Reasons, "\n")
+	if !strings.Contains(joined, "知识库中命中高风险相似案件（+8）") {
+		t.Fatalf("expected knowledge base adjustment in reasons, got %+v", result.Reasons)
+	}
+	if strings.Contains(joined, "用户历史中") {
+		t.Fatalf("user history miss should not produce a reason, got %+v", result.Reasons)
+	}
+	if got := result.NotificationReasons(); len(got) != 3 {
+		t.Fatalf("expected notification reasons capped at 3, got %+v", got)
+	}
+}
+
+func TestBuildRiskExplanation_FallsBackToRiskSummary(t *testing.T) {
+	record := state.CaseHistoryRecord{
+		RecordID:    "TASK-LEGACY",
+		RiskLevel:   "中",
+		RiskScore:   58,
+		RiskSummary: `{"score":58,"dimensions":{"social_engineering":13,"requested_actions":10,"evidence_strength":0,"loss_exposure":8},"victim_action_stage":"已点击链接","hit_rules":["冒充身份","紧迫催促","要求点击链接/安装应用","受害动作阶段：已点击链接"],"key_evidence":["短信链接"]}`,
+	}
+
+	result := explanation.BuildRiskExplanation(record)
+
+	if result.Source != explanation.SourceRiskSummary || result.Dynamic != nil {
+		t.Fatalf("unexpected legacy explanation: %+v", result)
+	}
+	if len(result.Dimensions) != 4 || result.VictimActionStage != "已点击链接" {
+		t.Fatalf("unexpected legacy details: %+v", result)
+	}
+	joined := strings.Join(result.Reasons, "\n")
+	if !strings.Contains(joined, "用户已点击链接") || !strings.Contains(joined, "「冒充身份」") || strings.Contains(joined, "「受害动作阶段") {
+		t.Fatalf("unexpected legacy reasons: %+v", result.Reasons)
+	}
+
+	empty := explanation.BuildRiskExplanation(state.CaseHistoryRecord{RecordID: "TASK-EMPTY", RiskLevel: "低"})
+	if empty.Source != explanation.SourceNone || empty.Headline != "判定为低风险。" || empty.Reasons == nil {
+		t.Fatalf("unexpected empty explanation: %+v", empty)
+	}
+}
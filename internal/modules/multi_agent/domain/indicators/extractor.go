@@ -0,0 +1,235 @@
+package indicators
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"antifraud/internal/modules/multi_agent/adapters/outbound/state"
+	"antifraud/internal/platform/redaction"
+)
+
+// 内置的结构化线索类别。注册顺序即报告中的展示顺序。
+const (
+	KindPhone       = "phone"
+	KindURL         = "url"
+	KindDomain      = "domain"
+	KindBankAccount = "bank_account"
+	KindPaymentQR   = "payment_qr"
+	KindApp         = "app"
+	KindWeChat      = "wechat_id"
+	KindQQ          = "qq_id"
+)
+
+// SourceText 是用户提交文本的来源名，其余来源使用模态名（video/audio/image 或扩展模态）。
+const SourceText = "text"
+
+// maxIndicators 是单个任务保留的线索条数上限，避免异常输入撑大归档记录。
+const maxIndicators = 50
+
+// Source 是一段参与抽取的文本及其来源名。
+type Source struct {
+	Name string
+	Text string
+}
+
+// Extractor 描述一类线索的确定性抽取方式。
+// Find 返回文本中命中的原始片段（已脱敏文本中的电话号码、银行卡号以占位符形式出现）；
+// Normalize 将还原后的原值归一为去重与指纹计算的比较键，返回空串表示丢弃该片段。
+type Extractor struct {
+	Kind      string
+	Label     string
+	Find      func(text string) []string
+	Normalize func(value string) string
+}
+
+var (
+	extractorsMu sync.RWMutex
+	extractors   []Extractor
+)
+
+func init() {
+	mustRegisterExtractor(Extractor{Kind: KindPhone, Label: "电话号码", Find: findPlaceholders(redaction.KindPhone), Normalize: normalizePhone})
+	mustRegisterExtractor(Extractor{Kind: KindURL, Label: "链接", Find: findURLs, Normalize: normalizeURL})
+	mustRegisterExtractor(Extractor{Kind: KindDomain, Label: "域名", Find: findDomains, Normalize: normalizeDomain})
+	mustRegisterExtractor(Extractor{Kind: KindBankAccount, Label: "收款账号", Find: findBankAccounts, Normalize: normalizeBankAccount})
+	mustRegisterExtractor(Extractor{Kind: KindPaymentQR, Label: "收款码内容", Find: findPaymentQRPayloads, Normalize: strings.TrimSpace})
+	mustRegisterExtractor(Extractor{Kind: KindApp, Label: "App 名称", Find: findAppNames, Normalize: normalizeLower})
+	mustRegisterExtractor(Extractor{Kind: KindWeChat, Label: "微信号", Find: findWeChatIDs, Normalize: normalizeLower})
+	mustRegisterExtractor(Extractor{Kind: KindQQ, Label: "QQ 号", Find: findQQIDs, Normalize: digitsOnly})
+}
+
+// RegisterExtractor 追加一个线索抽取器；类别或标签为空、Find 为空或类别重复时返回错误。
+func RegisterExtractor(extractor Extractor) error {
+	kind := strings.TrimSpace(extractor.Kind)
+	label := strings.TrimSpace(extractor.Label)
+	if kind == "" || label == "" {
+		return fmt.Errorf("indicator extractor kind and label are required")
+	}
+	if extractor.Find == nil {
+		return fmt.Errorf("indicator extractor %q find func is nil", kind)
+	}
+
+	extractorsMu.Lock()
+	defer extractorsMu.Unlock()
+	for _, existing := range extractors {
+		if existing.Kind == kind {
+			return fmt.Errorf("indicator extractor %q already registered", kind)
+		}
+	}
+	extractor.Kind = kind
+	extractor.Label = label
+	extractors = append(extractors, extractor)
+	return nil
+}
+
+func mustRegisterExtractor(extractor Extractor) {
+	if err := RegisterExtractor(extractor); err != nil {
+		panic(err)
+	}
+}
+
+// Kinds 按注册顺序返回已注册的线索类别。
+func Kinds() []string {
+	extractorsMu.RLock()
+	defer extractorsMu.RUnlock()
+	kinds := make([]string, 0, len(extractors))
+	for _, extractor := range extractors {
+		kinds = append(kinds, extractor.Kind)
+	}
+	return kinds
+}
+
+// KindLabel 返回类别的中文展示名，未注册的类别原样返回。
+func KindLabel(kind string) string {
+	if extractor, ok := lookupExtractor(kind); ok {
+		return extractor.Label
+	}
+	return kind
+}
+
+// Fingerprint 返回线索原值的指纹（类别与归一值的 SHA-256），供其他子系统按原值查找关联案件；
+// 类别未注册或归一后为空时返回空串。
+func Fingerprint(kind, value string) string {
+	extractor, ok := lookupExtractor(kind)
+	if !ok {
+		return ""
+	}
+	normalized := normalizeValue(extractor, value)
+	if normalized == "" {
+		return ""
+	}
+	return fingerprint(extractor.Kind, normalized)
+}
+
+// Extract 依次对每个来源运行全部抽取器，按“类别 + 归一值”去重并合并来源；结果按类别注册顺序排列，同类按首次出现排序。
+// redactor 为任务级 Redactor：来源文本先经其脱敏，电话号码、银行卡号等以占位符保存，指纹按还原后的原值计算；
+// redactor 为 nil 时使用临时 Redactor 并把结果还原为原值。
+func Extract(redactor *redaction.Redactor, sources ...Source) []state.Indicator {
+	registered := registeredExtractors()
+	working := redactor
+	if working == nil {
+		working = redaction.NewRedactor(nil)
+	}
+
+	result := make([]state.Indicator, 0)
+	positions := map[string]int{}
+	for _, source := range sources {
+		name := strings.TrimSpace(source.Name)
+		text := working.Redact(source.Text)
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		for _, extractor := range registered {
+			for _, found := range extractor.Find(text) {
+				value := strings.TrimSpace(found)
+				if value == "" {
+					continue
+				}
+				original := working.Restore(value)
+				key, digest := indicatorKey(extractor, original)
+				if key == "" {
+					continue
+				}
+				if index, exists := positions[key]; exists {
+					result[index].Sources = appendSource(result[index].Sources, name)
+					continue
+				}
+				if len(result) >= maxIndicators {
+					continue
+				}
+				if redactor == nil {
+					value = original
+				}
+				positions[key] = len(result)
+				result = append(result, state.Indicator{
+					Kind:        extractor.Kind,
+					Value:       value,
+					Fingerprint: digest,
+					Sources:     appendSource(nil, name),
+				})
+			}
+		}
+	}
+	order := make(map[string]int, len(registered))
+	for index, extractor := range registered {
+		order[extractor.Kind] = index
+	}
+	sort.SliceStable(result, func(i, j int) bool { return order[result[i].Kind] < order[result[j].Kind] })
+	return result
+}
+
+// indicatorKey 返回去重键与指纹。占位符缺少映射无法还原时按占位符本身去重，不生成指纹。
+func indicatorKey(extractor Extractor, original string) (string, string) {
+	if _, unresolved := redaction.PlaceholderKind(original); unresolved {
+		return extractor.Kind + "\x00" + original, ""
+	}
+	normalized := normalizeValue(extractor, original)
+	if normalized == "" {
+		return "", ""
+	}
+	return extractor.Kind + "\x00" + normalized, fingerprint(extractor.Kind, normalized)
+}
+
+func fingerprint(kind, normalized string) string {
+	sum := sha256.Sum256([]byte(kind + ":" + normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+func normalizeValue(extractor Extractor, value string) string {
+	if extractor.Normalize != nil {
+		return extractor.Normalize(value)
+	}
+	return strings.TrimSpace(value)
+}
+
+func appendSource(sources []string, name string) []string {
+	if name == "" {
+		return sources
+	}
+	for _, existing := range sources {
+		if existing == name {
+			return sources
+		}
+	}
+	return append(sources, name)
+}
+
+func lookupExtractor(kind string) (Extractor, bool) {
+	trimmed := strings.TrimSpace(kind)
+	for _, extractor := range registeredExtractors() {
+		if extractor.Kind == trimmed {
+			return extractor, true
+		}
+	}
+	return Extractor{}, false
+}
+
+func registeredExtractors() []Extractor {
+	extractorsMu.RLock()
+	defer extractorsMu.RUnlock()
+	return append([]Extractor{}, extractors...)
+}
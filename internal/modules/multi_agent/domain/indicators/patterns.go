@@ -0,0 +1,285 @@
+package indicators
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"antifraud/internal/platform/redaction"
+)
+
+// urlPattern 匹配 http(s) 链接与 www. 开头的网址，遇到空白、中文标点或括号即截止。
+var urlPattern = regexp.MustCompile(`(?i)(?:https?://|www\.)[^\s<>"'“”‘’（）()\[\]{}，。；！？、]+`)
+
+// urlTrailingPunctuation 是常紧跟在网址后的英文标点，不属于网址本身。
+const urlTrailingPunctuation = ".,;:!?'\""
+
+// domainPattern 匹配以常见顶级域结尾的裸域名；前一字符为字母数字或 @ 时视为标识或邮箱的一部分而跳过。
+var domainPattern = regexp.MustCompile(`(?i)(?:^|[^A-Za-z0-9@._\-])((?:[a-z0-9](?:[a-z0-9\-]{0,61}[a-z0-9])?\.)+(?:com|cn|net|org|top|xyz|vip|cc|io|app|info|site|shop|club|me|live|pro|ltd|co|hk|tw|online|fun|icu|tk|ws|biz|asia|work|tech|link|cloud|store|win|bet))(?:$|[^A-Za-z0-9\-])`)
+
+// paymentSchemePattern 匹配微信、支付宝、云闪付等支付 App 的收款码协议链接与官方收款码短链。
+var paymentSchemePattern = regexp.MustCompile(`(?i)(?:(?:wxp|weixin|alipays?|alipayqr|upwallet|unionpay)://|https?://(?:qr\.alipay\.com|wx\.tenpay\.com|payapp\.weixin\.qq\.com|qr\.95516\.com)/)[^\s<>"'“”‘’（）()\[\]{}，。；！？、]+`)
+
+// qrContentPattern 以“二维码内容：”等提示词识别图片子智能体转写出的二维码原文。
+var qrContentPattern = regexp.MustCompile(`(?:二维码|收款码|付款码)(?:内容|链接|信息)?(?:为|是)?[:：]\s*([^\s，。；！？、]+)`)
+
+// bankAccountPattern 以账号提示词识别未通过银行卡校验的收款账号；group 1 用于排除 QQ 账号、微信账号等非银行账号。
+var bankAccountPattern = regexp.MustCompile(`(?i)(qq|微信|游戏|登录|登陆)?(?:银行卡号?|卡号|银行账号|收款账[号户]|对公账[号户]|账号|帐号)(?:是|为)?[:：]?\s*(\d[\d \-]{8,28}\d)`)
+
+var (
+	appQuotedPattern = regexp.MustCompile(`(?i)(?:下载|安装|打开|登录|注册|使用)(?:了)?(?:一个|一款|名为|叫做?)?[《「“"]([^》」”"\s]{2,16})[》」”"]|[《「“"]([^》」”"\s]{2,16})[》」”"]\s*(?:app|软件|应用|客户端)`)
+	appSuffixPattern = regexp.MustCompile(`([\p{Han}A-Za-z0-9]{2,24}?)\s*(?i:app)`)
+)
+
+// appNameCues 是 App 名称前常见的动词与量词；“xx App”写法会把它们一并匹配进名称，命中后从最后一个提示词处截断。
+var appNameCues = []string{"下载", "安装", "打开", "登录", "注册", "使用", "名为", "叫做", "名叫", "一个", "一款", "这个", "那个", "装", "个", "款", "的", "了"}
+
+// genericAppNames 是泛指而非具体 App 的词语。
+var genericAppNames = map[string]bool{"手机": true, "官方": true, "相关": true, "某": true, "该": true, "此": true, "这款": true, "那款": true, "其他": true, "第三方": true}
+
+var (
+	wechatPattern = regexp.MustCompile(`(?i)(?:微信号?|vx|wx|v信|威信|薇信|weixin)\s*(?:号)?(?:是|为)?[:：]?\s*([A-Za-z][\-_A-Za-z0-9]{5,19})`)
+	qqPattern     = regexp.MustCompile(`(?i)(?:qq|扣扣)\s*(?:号码?|群号?)?(?:是|为)?[:：]?\s*([1-9]\d{4,11})`)
+)
+
+// findPlaceholders 返回文本中属于指定脱敏类别的占位符（如 [电话号码_1]）。
+func findPlaceholders(kinds ...string) func(string) []string {
+	return func(text string) []string {
+		found := make([]string, 0)
+		for _, match := range redaction.FindPlaceholders(text) {
+			placeholder := text[match.Start:match.End]
+			kind, ok := redaction.PlaceholderKind(placeholder)
+			if !ok {
+				continue
+			}
+			for _, wanted := range kinds {
+				if kind == wanted {
+					found = append(found, placeholder)
+					break
+				}
+			}
+		}
+		return found
+	}
+}
+
+func findURLs(text string) []string {
+	found := make([]string, 0)
+	for _, match := range urlPattern.FindAllString(text, -1) {
+		if trimmed := strings.TrimRight(match, urlTrailingPunctuation); trimmed != "" {
+			found = append(found, trimmed)
+		}
+	}
+	return found
+}
+
+// findDomains 返回链接中的主机名与文本中的裸域名，IP 地址不视为域名。
+func findDomains(text string) []string {
+	found := make([]string, 0)
+	for _, link := range findURLs(text) {
+		if host := urlHost(link); host != "" && strings.ContainsAny(host, "abcdefghijklmnopqrstuvwxyz") {
+			found = append(found, normalizeDomain(host))
+		}
+	}
+	for _, match := range domainPattern.FindAllStringSubmatch(text, -1) {
+		found = append(found, normalizeDomain(match[1]))
+	}
+	return found
+}
+
+// findBankAccounts 返回银行卡号占位符与提示词后的收款账号。
+func findBankAccounts(text string) []string {
+	found := findPlaceholders(redaction.KindBankCard)(text)
+	for _, loc := range bankAccountPattern.FindAllStringSubmatchIndex(text, -1) {
+		if loc[2] >= 0 || !followedByBoundary(text, loc[5]) {
+			continue
+		}
+		found = append(found, text[loc[4]:loc[5]])
+	}
+	return found
+}
+
+// findPaymentQRPayloads 返回支付协议链接与子智能体转写的二维码内容。
+func findPaymentQRPayloads(text string) []string {
+	found := make([]string, 0)
+	for _, match := range paymentSchemePattern.FindAllString(text, -1) {
+		if trimmed := strings.TrimRight(match, urlTrailingPunctuation); trimmed != "" {
+			found = append(found, trimmed)
+		}
+	}
+	for _, match := range qrContentPattern.FindAllStringSubmatch(text, -1) {
+		if trimmed := strings.TrimRight(match[1], urlTrailingPunctuation); trimmed != "" {
+			found = append(found, trimmed)
+		}
+	}
+	return found
+}
+
+// findAppNames 识别“下载《xx》”“《xx》App”与“xx App”三种写法的 App 名称。
+func findAppNames(text string) []string {
+	found := make([]string, 0)
+	for _, match := range appQuotedPattern.FindAllStringSubmatch(text, -1) {
+		found = append(found, firstNonEmpty(match[1], match[2]))
+	}
+	urlSpans := urlPattern.FindAllStringIndex(text, -1)
+	for _, loc := range appSuffixPattern.FindAllStringSubmatchIndex(text, -1) {
+		// “app”后紧跟字母时是普通英文单词（如 apple），位于链接内时是域名或路径的一部分，均不是 App 名称。
+		if loc[1] < len(text) && isASCIILetter(text[loc[1]]) || insideSpans(urlSpans, loc[0]) {
+			continue
+		}
+		name := text[loc[2]:loc[3]]
+		// 英文名与 app 直接相连时（如 WhatsApp）app 属于名称本身，名称只取紧邻的英文部分。
+		if isASCIILetter(name[len(name)-1]) && loc[3]+3 == loc[1] {
+			start := loc[3]
+			for start > loc[2] && isASCIIAlnum(text[start-1]) {
+				start--
+			}
+			name = text[start:loc[1]]
+		}
+		if cut := lastCueEnd(name, appNameCues); cut > 0 {
+			name = name[cut:]
+		}
+		found = append(found, name)
+	}
+	result := make([]string, 0, len(found))
+	for _, name := range found {
+		trimmed := strings.TrimSpace(name)
+		if utf8.RuneCountInString(trimmed) < 2 || genericAppNames[trimmed] {
+			continue
+		}
+		result = append(result, trimmed)
+	}
+	return result
+}
+
+func findWeChatIDs(text string) []string {
+	found := make([]string, 0)
+	for _, loc := range wechatPattern.FindAllStringSubmatchIndex(text, -1) {
+		if !followedByBoundary(text, loc[3]) {
+			continue
+		}
+		found = append(found, text[loc[2]:loc[3]])
+	}
+	return found
+}
+
+func findQQIDs(text string) []string {
+	found := make([]string, 0)
+	for _, loc := range qqPattern.FindAllStringSubmatchIndex(text, -1) {
+		if !followedByBoundary(text, loc[3]) {
+			continue
+		}
+		found = append(found, text[loc[2]:loc[3]])
+	}
+	return found
+}
+
+// followedByBoundary 判断命中片段后没有紧跟字母数字，避免截取更长标识的前缀。
+func followedByBoundary(text string, end int) bool {
+	if end >= len(text) {
+		return true
+	}
+	b := text[end]
+	return !(isASCIIAlnum(b) || b == '_' || b == '-')
+}
+
+func isASCIILetter(b byte) bool {
+	return b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z'
+}
+
+func isASCIIAlnum(b byte) bool {
+	return b >= '0' && b <= '9' || isASCIILetter(b)
+}
+
+func insideSpans(spans [][]int, offset int) bool {
+	for _, span := range spans {
+		if offset >= span[0] && offset < span[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// lastCueEnd 返回 value 中最后一个提示词的结束偏移，没有提示词时返回 0。
+func lastCueEnd(value string, cues []string) int {
+	best := 0
+	for _, cue := range cues {
+		if index := strings.LastIndex(value, cue); index >= 0 && index+len(cue) > best {
+			best = index + len(cue)
+		}
+	}
+	return best
+}
+
+func urlHost(link string) string {
+	raw := link
+	if !strings.Contains(raw, "://") {
+		raw = "http://" + raw
+	}
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(parsed.Hostname())
+}
+
+func normalizeURL(value string) string {
+	trimmed := strings.TrimRight(strings.TrimSpace(value), urlTrailingPunctuation)
+	raw := trimmed
+	if !strings.Contains(raw, "://") {
+		raw = "http://" + raw
+	}
+	parsed, err := url.Parse(raw)
+	if err != nil || parsed.Host == "" {
+		return ""
+	}
+	parsed.Scheme = strings.ToLower(parsed.Scheme)
+	parsed.Host = strings.ToLower(parsed.Host)
+	return strings.TrimRight(parsed.String(), "/")
+}
+
+func normalizeDomain(value string) string {
+	return strings.TrimPrefix(strings.ToLower(strings.TrimSpace(value)), "www.")
+}
+
+// normalizePhone 去掉 86 国家码，使带与不带国家码的同一手机号共用指纹。
+func normalizePhone(value string) string {
+	digits := digitsOnly(value)
+	if len(digits) == 13 && strings.HasPrefix(digits, "86") {
+		return digits[2:]
+	}
+	return digits
+}
+
+// normalizeBankAccount 只保留数字，位数不在常见账号范围（10-25 位）内时丢弃。
+func normalizeBankAccount(value string) string {
+	digits := digitsOnly(value)
+	if len(digits) < 10 || len(digits) > 25 {
+		return ""
+	}
+	return digits
+}
+
+func normalizeLower(value string) string {
+	return strings.ToLower(strings.TrimSpace(value))
+}
+
+func digitsOnly(value string) string {
+	var builder strings.Builder
+	for _, r := range value {
+		if r >= '0' && r <= '9' {
+			builder.WriteRune(r)
+		}
+	}
+	return builder.String()
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if trimmed := strings.TrimSpace(value); trimmed != "" {
+			return trimmed
+		}
+	}
+	return ""
+}
@@ -0,0 +1,105 @@
+package indicators_test
+
+import (
+	"strings"
+	"testing"
+
+	"antifraud/internal/modules/multi_agent/domain/indicators"
+	"antifraud/internal/platform/redaction"
+)
+
+func TestExtract_CollectsTypedIndicatorsAcrossSources(t *testing.T) {
+	got := indicators.Extract(nil,
+		indicators.Source{Name: indicators.SourceText, Text: "客服让我下载安心贷APP，加微信 kefu_8899 或QQ号：12345678，电话13800138000，访问https://www.Evil-Pay.com/login?id=3。"},
+		indicators.Source{Name: "video", Text: "【视频音轨ASR转写】\n请拨打138-0013-8000，把钱转到6222021234567890128"},
+		indicators.Source{Name: "image", Text: "二维码内容：wxp://f2f0abcDEF123，页面显示 evil-pay.com，收款账号：1234 5678 9012"},
+	)
+
+	want := []struct {
+		kind    string
+		value   string
+		sources string
+	}{
+		{indicators.KindPhone, "13800138000", "text,video"},
+		{indicators.KindURL, "https://www.Evil-Pay.com/login?id=3", "text"},
+		{indicators.KindDomain, "evil-pay.com", "text,image"},
+		{indicators.KindBankAccount, "6222021234567890128", "video"},
+		{indicators.KindBankAccount, "1234 5678 9012", "image"},
+		{indicators.KindPaymentQR, "wxp://f2f0abcDEF123", "image"},
+		{indicators.KindApp, "安心贷", "text"},
+		{indicators.KindWeChat, "kefu_8899", "text"},
+		{indicators.KindQQ, "12345678", "text"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d indicators, got %+v", len(want), got)
+	}
+	for i, expected := range want {
+		if got[i].Kind != expected.kind || got[i].Value != expected.value || strings.Join(got[i].Sources, ",") != expected.sources {
+			t.Fatalf("indicator %d: expected %+v, got %+v", i, expected, got[i])
+		}
+		if got[i].Fingerprint == "" {
+			t.Fatalf("indicator %d: expected fingerprint, got %+v", i, got[i])
+		}
+	}
+}
+
+func TestExtract_KeepsPlaceholdersWithTaskRedactor(t *testing.T) {
+	redactor := redaction.NewRedactor(nil)
+	text := redactor.Redact("对方电话+86 138 0013 8000，卡号6222021234567890128")
+
+	got := indicators.Extract(redactor, indicators.Source{Name: indicators.SourceText, Text: text})
+	if len(got) != 2 {
+		t.Fatalf("expected phone and bank account, got %+v", got)
+	}
+	if got[0].Value != "[电话号码_1]" || got[1].Value != "[银行卡号_1]" {
+		t.Fatalf("expected placeholders kept, got %+v", got)
+	}
+	if got[0].Fingerprint != indicators.Fingerprint(indicators.KindPhone, "13800138000") {
+		t.Fatalf("expected fingerprint of original phone, got %+v", got[0])
+	}
+	if got[1].Fingerprint != indicators.Fingerprint(indicators.KindBankAccount, "6222 0212 3456 7890 128") {
+		t.Fatalf("expected fingerprint of original account, got %+v", got[1])
+	}
+}
+
+func TestExtract_SkipsNonIndicators(t *testing.T) {
+	got := indicators.Extract(nil, indicators.Source{
+		Name: indicators.SourceText,
+		Text: "我的QQ账号123456789012被盗了，吃了个apple，发邮件到 help@example.com，版本 v1.2，转账5000元，下载手机app",
+	})
+	for _, indicator := range got {
+		switch indicator.Kind {
+		case indicators.KindQQ:
+			continue
+		default:
+			t.Fatalf("unexpected indicator %+v in %+v", indicator, got)
+		}
+	}
+}
+
+func TestRegisterExtractor_RejectsDuplicatesAndAddsCustomKind(t *testing.T) {
+	if err := indicators.RegisterExtractor(indicators.Extractor{Kind: indicators.KindPhone, Label: "号码", Find: func(string) []string { return nil }}); err == nil {
+		t.Fatal("expected duplicate kind rejected")
+	}
+	if err := indicators.RegisterExtractor(indicators.Extractor{Kind: "telegram_id", Label: "Telegram 账号"}); err == nil {
+		t.Fatal("expected nil find func rejected")
+	}
+
+	err := indicators.RegisterExtractor(indicators.Extractor{
+		Kind:  "telegram_id",
+		Label: "Telegram 账号",
+		Find: func(text string) []string {
+			if strings.Contains(text, "@scam_support_bot") {
+				return []string{"@scam_support_bot"}
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("register custom extractor failed: %v", err)
+	}
+	got := indicators.Extract(nil, indicators.Source{Name: indicators.SourceText, Text: "联系 @scam_support_bot 办理"})
+	if len(got) != 1 || got[0].Kind != "telegram_id" || indicators.KindLabel("telegram_id") != "Telegram 账号" {
+		t.Fatalf("expected custom indicator, got %+v", got)
+	}
+}
@@ -0,0 +1,471 @@
+package scoring
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+)
+
+const (
+	DimensionSocialEngineering = "social_engineering"
+	DimensionRequestedActions  = "requested_actions"
+	DimensionEvidenceStrength  = "evidence_strength"
+	DimensionLossExposure      = "loss_exposure"
+
+	PhasePreliminary = "preliminary"
+	PhaseTerminal    = "terminal"
+)
+
+// FactorKeys 是评分工具 submit_current_risk_assessment 支持的布尔风险因子，规则文件只能引用这些 key。
+var FactorKeys = []string{
+	"impersonation",
+	"urgency",
+	"threat_pressure",
+	"benefit_inducement",
+	"channel_switch_request",
+	"invite_code_request",
+	"verification_process_request",
+	"screenshot_or_record_request",
+	"trust_building_pressure",
+	"money_transfer_request",
+	"verification_code_request",
+	"remote_control_request",
+	"link_or_app_install_request",
+	"sensitive_info_request",
+	"private_account_collection",
+	"fake_official_visuals",
+}
+
+//go:embed default_rules.json
+var defaultRuleSetJSON []byte
+
+// RuleSet 是一份带版本号的风险评分规则。
+// 计分流程：
+// 1) 命中的布尔因子按 weight 累加到所属维度，强度枚举与受害阶段各自加分；
+// 2) 四个维度求和后乘以受害阶段 multiplier；
+// 3) 依次套用“仅前期信号保底 / 终局信号保底 / 组合规则保底 / 受害阶段保底”，取最大值并截断到 100。
+type RuleSet struct {
+	Version               string            `json:"version"`
+	Description           string            `json:"description,omitempty"`
+	Factors               []FactorRule      `json:"factors"`
+	StrengthLevels        []StrengthLevel   `json:"strength_levels"`
+	VictimActionStages    []VictimStageRule `json:"victim_action_stages"`
+	PreliminaryOnlyFloors []SignalFloor     `json:"preliminary_only_floors"`
+	TerminalFloors        []SignalFloor     `json:"terminal_floors"`
+	CombinationRules      []CombinationRule `json:"combination_rules"`
+	ThresholdBands        []ThresholdBand   `json:"threshold_bands"`
+}
+
+// FactorRule 定义单个布尔因子的权重、维度与所属阶段（前期引流 / 终局动作）。
+type FactorRule struct {
+	Key       string `json:"key"`
+	Label     string `json:"label"`
+	Dimension string `json:"dimension"`
+	Weight    int    `json:"weight"`
+	Phase     string `json:"phase"`
+}
+
+// StrengthLevel 定义相似案例/多模态证据强度枚举对应的分值。
+type StrengthLevel struct {
+	Value string `json:"value"`
+	Label string `json:"label"`
+	Score int    `json:"score"`
+}
+
+// VictimStageRule 定义受害动作阶段的损失暴露分、总分乘数与保底分。
+type VictimStageRule struct {
+	Value      string  `json:"value"`
+	Label      string  `json:"label"`
+	Score      int     `json:"score"`
+	Multiplier float64 `json:"multiplier,omitempty"`
+	Floor      int     `json:"floor,omitempty"`
+}
+
+// SignalFloor 按命中信号数量给出保底分；同一列表按顺序取第一条满足的规则。
+type SignalFloor struct {
+	MinTerminal    int `json:"min_terminal,omitempty"`
+	MinPreliminary int `json:"min_preliminary,omitempty"`
+	Floor          int `json:"floor"`
+}
+
+// CombinationRule 表示多个因子同时命中时的保底分，例如“冒充身份 + 索要验证码 ⇒ 至少 80 分”。
+type CombinationRule struct {
+	Name  string   `json:"name"`
+	AllOf []string `json:"all_of"`
+	Floor int      `json:"floor"`
+}
+
+// ThresholdBand 定义历史分区间对应的动态阈值，按 max_historical_score 升序匹配。
+type ThresholdBand struct {
+	MaxHistoricalScore int `json:"max_historical_score"`
+	Threshold          int `json:"threshold"`
+}
+
+// Signals 是一次评分的输入。
+type Signals struct {
+	Factors             map[string]bool
+	SimilarCaseStrength string
+	MultimodalEvidence  string
+	VictimActionStage   string
+}
+
+// Outcome 是一次评分的结果。
+type Outcome struct {
+	Score             int
+	Dimensions        map[string]int
+	HitRules          []string
+	HitFactors        []FactorRule
+	SimilarCaseLabel  string
+	MultimodalLabel   string
+	VictimActionStage string
+	VictimActionLabel string
+	RuleSetVersion    string
+}
+
+// DefaultRuleSet 返回内置默认规则的副本。
+func DefaultRuleSet() RuleSet {
+	rules, err := ParseRuleSet(defaultRuleSetJSON)
+	if err != nil {
+		panic(fmt.Sprintf("invalid embedded risk scoring rules: %v", err))
+	}
+	return rules
+}
+
+// LoadRuleSet 读取并校验规则文件。
+func LoadRuleSet(path string) (RuleSet, error) {
+	raw, err := os.ReadFile(strings.TrimSpace(path))
+	if err != nil {
+		return RuleSet{}, fmt.Errorf("read risk scoring rules failed: %w", err)
+	}
+	return ParseRuleSet(raw)
+}
+
+// ParseRuleSet 解析并校验规则 JSON，未知字段视为错误，避免拼写错误的规则被静默忽略。
+func ParseRuleSet(raw []byte) (RuleSet, error) {
+	decoder := json.NewDecoder(bytes.NewReader(bytes.TrimPrefix(raw, []byte("\xef\xbb\xbf"))))
+	decoder.DisallowUnknownFields()
+	var rules RuleSet
+	if err := decoder.Decode(&rules); err != nil {
+		return RuleSet{}, fmt.Errorf("decode risk scoring rules failed: %w", err)
+	}
+	rules.normalize()
+	if err := rules.Validate(); err != nil {
+		return RuleSet{}, err
+	}
+	return rules, nil
+}
+
+func (r *RuleSet) normalize() {
+	r.Version = strings.TrimSpace(r.Version)
+	r.Description = strings.TrimSpace(r.Description)
+	for i := range r.Factors {
+		r.Factors[i].Key = strings.TrimSpace(r.Factors[i].Key)
+		r.Factors[i].Label = strings.TrimSpace(r.Factors[i].Label)
+		r.Factors[i].Dimension = strings.TrimSpace(r.Factors[i].Dimension)
+		r.Factors[i].Phase = strings.TrimSpace(r.Factors[i].Phase)
+	}
+	for i := range r.StrengthLevels {
+		r.StrengthLevels[i].Value = strings.TrimSpace(r.StrengthLevels[i].Value)
+		r.StrengthLevels[i].Label = strings.TrimSpace(r.StrengthLevels[i].Label)
+	}
+	for i := range r.VictimActionStages {
+		r.VictimActionStages[i].Value = strings.TrimSpace(r.VictimActionStages[i].Value)
+		r.VictimActionStages[i].Label = strings.TrimSpace(r.VictimActionStages[i].Label)
+		if r.VictimActionStages[i].Multiplier == 0 {
+			r.VictimActionStages[i].Multiplier = 1
+		}
+	}
+	for i := range r.CombinationRules {
+		r.CombinationRules[i].Name = strings.TrimSpace(r.CombinationRules[i].Name)
+		for j := range r.CombinationRules[i].AllOf {
+			r.CombinationRules[i].AllOf[j] = strings.TrimSpace(r.CombinationRules[i].AllOf[j])
+		}
+	}
+}
+
+// Validate 校验规则完整性：因子 key 必须已知且唯一，枚举必须包含 none，阈值区间必须覆盖 0-100。
+func (r RuleSet) Validate() error {
+	if r.Version == "" {
+		return fmt.Errorf("risk scoring rules: version is required")
+	}
+
+	known := make(map[string]struct{}, len(FactorKeys))
+	for _, key := range FactorKeys {
+		known[key] = struct{}{}
+	}
+	seen := make(map[string]struct{}, len(r.Factors))
+	for _, factor := range r.Factors {
+		if _, ok := known[factor.Key]; !ok {
+			return fmt.Errorf("risk scoring rules: unknown factor %q", factor.Key)
+		}
+		if _, dup := seen[factor.Key]; dup {
+			return fmt.Errorf("risk scoring rules: duplicate factor %q", factor.Key)
+		}
+		seen[factor.Key] = struct{}{}
+		if factor.Label == "" {
+			return fmt.Errorf("risk scoring rules: factor %q label is required", factor.Key)
+		}
+		switch factor.Dimension {
+		case DimensionSocialEngineering, DimensionRequestedActions, DimensionEvidenceStrength:
+		default:
+			return fmt.Errorf("risk scoring rules: factor %q has invalid dimension %q", factor.Key, factor.Dimension)
+		}
+		if factor.Phase != PhasePreliminary && factor.Phase != PhaseTerminal {
+			return fmt.Errorf("risk scoring rules: factor %q phase must be preliminary or terminal", factor.Key)
+		}
+		if factor.Weight < 0 {
+			return fmt.Errorf("risk scoring rules: factor %q weight must be >= 0", factor.Key)
+		}
+	}
+
+	if err := validateEnumValues("strength_levels", strengthValues(r.StrengthLevels)); err != nil {
+		return err
+	}
+	if err := validateEnumValues("victim_action_stages", stageValues(r.VictimActionStages)); err != nil {
+		return err
+	}
+	for _, stage := range r.VictimActionStages {
+		if stage.Multiplier < 0 {
+			return fmt.Errorf("risk scoring rules: victim_action_stages %q multiplier must be >= 0", stage.Value)
+		}
+	}
+
+	for _, floor := range append(append([]SignalFloor{}, r.PreliminaryOnlyFloors...), r.TerminalFloors...) {
+		if floor.Floor < 0 || floor.Floor > 100 {
+			return fmt.Errorf("risk scoring rules: floor must be within 0-100")
+		}
+	}
+	for _, rule := range r.CombinationRules {
+		if rule.Name == "" || len(rule.AllOf) < 2 {
+			return fmt.Errorf("risk scoring rules: combination rule needs a name and at least two factors")
+		}
+		for _, key := range rule.AllOf {
+			if _, ok := known[key]; !ok {
+				return fmt.Errorf("risk scoring rules: combination %q references unknown factor %q", rule.Name, key)
+			}
+		}
+		if rule.Floor < 0 || rule.Floor > 100 {
+			return fmt.Errorf("risk scoring rules: combination %q floor must be within 0-100", rule.Name)
+		}
+	}
+
+	if len(r.ThresholdBands) == 0 {
+		return fmt.Errorf("risk scoring rules: threshold_bands is required")
+	}
+	previous := -1
+	for _, band := range r.ThresholdBands {
+		if band.MaxHistoricalScore <= previous {
+			return fmt.Errorf("risk scoring rules: threshold_bands must be sorted by max_historical_score")
+		}
+		if band.Threshold < 0 || band.Threshold > 100 {
+			return fmt.Errorf("risk scoring rules: threshold must be within 0-100")
+		}
+		previous = band.MaxHistoricalScore
+	}
+	if previous < 100 {
+		return fmt.Errorf("risk scoring rules: threshold_bands must cover historical score 100")
+	}
+	return nil
+}
+
+// Score 按规则计算风险分。
+func (r RuleSet) Score(signals Signals) (Outcome, error) {
+	similarCase, err := r.resolveStrength(signals.SimilarCaseStrength)
+	if err != nil {
+		return Outcome{}, err
+	}
+	multimodal, err := r.resolveStrength(signals.MultimodalEvidence)
+	if err != nil {
+		return Outcome{}, err
+	}
+	stage, err := r.resolveVictimStage(signals.VictimActionStage)
+	if err != nil {
+		return Outcome{}, err
+	}
+
+	dimensions := map[string]int{
+		DimensionSocialEngineering: 0,
+		DimensionRequestedActions:  0,
+		DimensionEvidenceStrength:  0,
+		DimensionLossExposure:      stage.Score,
+	}
+	hitRules := make([]string, 0, len(r.Factors)+4)
+	hitFactors := make([]FactorRule, 0, len(r.Factors))
+	preliminaryCount := 0
+	terminalCount := 0
+	for _, factor := range r.Factors {
+		if !signals.Factors[factor.Key] {
+			continue
+		}
+		dimensions[factor.Dimension] += factor.Weight
+		hitRules = append(hitRules, factor.Label)
+		hitFactors = append(hitFactors, factor)
+		if factor.Phase == PhaseTerminal {
+			terminalCount++
+		} else {
+			preliminaryCount++
+		}
+	}
+
+	if similarCase.Score > 0 {
+		dimensions[DimensionEvidenceStrength] += similarCase.Score
+		hitRules = append(hitRules, "相似案例支持："+similarCase.Label)
+	}
+	if multimodal.Score > 0 {
+		dimensions[DimensionEvidenceStrength] += multimodal.Score
+		hitRules = append(hitRules, "多模态证据："+multimodal.Label)
+	}
+	if stage.Label != "" {
+		hitRules = append(hitRules, "受害动作阶段："+stage.Label)
+	}
+
+	sum := dimensions[DimensionSocialEngineering] + dimensions[DimensionRequestedActions] + dimensions[DimensionEvidenceStrength] + dimensions[DimensionLossExposure]
+	score := int(math.Round(float64(sum) * stage.Multiplier))
+
+	if terminalCount == 0 {
+		score = maxInt(score, firstMatchingFloor(r.PreliminaryOnlyFloors, terminalCount, preliminaryCount))
+	}
+	score = maxInt(score, firstMatchingFloor(r.TerminalFloors, terminalCount, preliminaryCount))
+	for _, rule := range r.CombinationRules {
+		if !allFactorsHit(signals.Factors, rule.AllOf) {
+			continue
+		}
+		score = maxInt(score, rule.Floor)
+		hitRules = append(hitRules, "组合规则："+rule.Name)
+	}
+	score = maxInt(score, stage.Floor)
+	if score > 100 {
+		score = 100
+	}
+
+	return Outcome{
+		Score:             score,
+		Dimensions:        dimensions,
+		HitRules:          hitRules,
+		HitFactors:        hitFactors,
+		SimilarCaseLabel:  similarCase.Label,
+		MultimodalLabel:   multimodal.Label,
+		VictimActionStage: stage.Value,
+		VictimActionLabel: stage.Label,
+		RuleSetVersion:    r.Version,
+	}, nil
+}
+
+// ThresholdFor 根据历史分返回动态阈值。
+func (r RuleSet) ThresholdFor(historicalScore int) int {
+	score := historicalScore
+	if score < 0 {
+		score = 0
+	}
+	if score > 100 {
+		score = 100
+	}
+	for _, band := range r.ThresholdBands {
+		if score <= band.MaxHistoricalScore {
+			return band.Threshold
+		}
+	}
+	return r.ThresholdBands[len(r.ThresholdBands)-1].Threshold
+}
+
+// StrengthValues 返回强度枚举的可选值，供工具 schema 使用。
+func (r RuleSet) StrengthValues() []string {
+	return strengthValues(r.StrengthLevels)
+}
+
+// VictimActionStageValues 返回受害阶段枚举的可选值，供工具 schema 使用。
+func (r RuleSet) VictimActionStageValues() []string {
+	return stageValues(r.VictimActionStages)
+}
+
+func (r RuleSet) resolveStrength(raw string) (StrengthLevel, error) {
+	value := strings.TrimSpace(raw)
+	if value == "" {
+		value = "none"
+	}
+	for _, level := range r.StrengthLevels {
+		if level.Value == value {
+			return level, nil
+		}
+	}
+	return StrengthLevel{}, fmt.Errorf("strength must be one of %s", strings.Join(r.StrengthValues(), "/"))
+}
+
+func (r RuleSet) resolveVictimStage(raw string) (VictimStageRule, error) {
+	value := strings.TrimSpace(raw)
+	if value == "" {
+		value = "none"
+	}
+	for _, stage := range r.VictimActionStages {
+		if stage.Value == value {
+			return stage, nil
+		}
+	}
+	return VictimStageRule{}, fmt.Errorf("victim_action_stage is invalid")
+}
+
+func firstMatchingFloor(floors []SignalFloor, terminalCount int, preliminaryCount int) int {
+	for _, floor := range floors {
+		if terminalCount >= floor.MinTerminal && preliminaryCount >= floor.MinPreliminary && (floor.MinTerminal > 0 || floor.MinPreliminary > 0) {
+			return floor.Floor
+		}
+	}
+	return 0
+}
+
+func allFactorsHit(factors map[string]bool, keys []string) bool {
+	for _, key := range keys {
+		if !factors[key] {
+			return false
+		}
+	}
+	return len(keys) > 0
+}
+
+func validateEnumValues(name string, values []string) error {
+	seen := make(map[string]struct{}, len(values))
+	hasNone := false
+	for _, value := range values {
+		if value == "" {
+			return fmt.Errorf("risk scoring rules: %s value is required", name)
+		}
+		if _, dup := seen[value]; dup {
+			return fmt.Errorf("risk scoring rules: duplicate %s value %q", name, value)
+		}
+		seen[value] = struct{}{}
+		if value == "none" {
+			hasNone = true
+		}
+	}
+	if !hasNone {
+		return fmt.Errorf("risk scoring rules: %s must include none", name)
+	}
+	return nil
+}
+
+func strengthValues(levels []StrengthLevel) []string {
+	values := make([]string, 0, len(levels))
+	for _, level := range levels {
+		values = append(values, level.Value)
+	}
+	return values
+}
+
+func stageValues(stages []VictimStageRule) []string {
+	values := make([]string, 0, len(stages))
+	for _, stage := range stages {
+		values = append(values, stage.Value)
+	}
+	return values
+}
+
+func maxInt(left int, right int) int {
+	if left > right {
+		return left
+	}
+	return right
+}
@@ -0,0 +1,101 @@
+package scoring
+
+import (
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// reloadCheckInterval 是规则文件变更检查的最小间隔，避免每次评分都访问磁盘。
+const reloadCheckInterval = 2 * time.Second
+
+var (
+	storeMu     sync.Mutex
+	storePath   string
+	storeRules  = DefaultRuleSet()
+	storeMod    time.Time
+	storeSize   int64
+	storeLoaded bool
+	storeCheck  time.Time
+)
+
+// SetRuleFile 指定外部规则文件路径，路径为空时使用内置默认规则。
+// 规则文件修改后会在下一次评分时自动热加载；文件无效时记录日志并继续沿用上一版规则，保证评分不中断。
+func SetRuleFile(path string) error {
+	storeMu.Lock()
+	defer storeMu.Unlock()
+
+	storePath = strings.TrimSpace(path)
+	storeMod = time.Time{}
+	storeSize = 0
+	storeLoaded = false
+	storeCheck = time.Time{}
+	if storePath == "" {
+		storeRules = DefaultRuleSet()
+		return nil
+	}
+	return reloadLocked(true)
+}
+
+// Current 返回当前生效的规则集。
+func Current() RuleSet {
+	storeMu.Lock()
+	defer storeMu.Unlock()
+
+	if storePath != "" {
+		now := time.Now()
+		if !storeLoaded || now.Sub(storeCheck) >= reloadCheckInterval {
+			storeCheck = now
+			_ = reloadLocked(false)
+		}
+	}
+	return storeRules
+}
+
+// Reload 立即检查并重新加载规则文件，供运维在修改规则后主动触发。
+func Reload() error {
+	storeMu.Lock()
+	defer storeMu.Unlock()
+
+	if storePath == "" {
+		return nil
+	}
+	storeCheck = time.Now()
+	return reloadLocked(true)
+}
+
+// CurrentVersion 返回当前生效规则集的版本号。
+func CurrentVersion() string {
+	return Current().Version
+}
+
+// reloadLocked 在文件 mtime/size 变化时重新加载规则；force 为 true 时无条件加载。
+func reloadLocked(force bool) error {
+	info, err := os.Stat(storePath)
+	if err != nil {
+		log.Printf("[scoring] stat risk rules failed: path=%s err=%v", storePath, err)
+		return err
+	}
+	if !force && storeLoaded && info.ModTime().Equal(storeMod) && info.Size() == storeSize {
+		return nil
+	}
+
+	rules, err := LoadRuleSet(storePath)
+	if err != nil {
+		log.Printf("[scoring] load risk rules failed, keep version=%s: path=%s err=%v", storeRules.Version, storePath, err)
+		storeMod = info.ModTime()
+		storeSize = info.Size()
+		storeLoaded = true
+		return err
+	}
+	if rules.Version != storeRules.Version {
+		log.Printf("[scoring] risk rules loaded: version=%s path=%s", rules.Version, storePath)
+	}
+	storeRules = rules
+	storeMod = info.ModTime()
+	storeSize = info.Size()
+	storeLoaded = true
+	return nil
+}
@@ -0,0 +1,138 @@
+package scoring_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"antifraud/internal/modules/multi_agent/domain/scoring"
+)
+
+const tunedRules = `{
+  "version": "2026.04-tuned",
+  "factors": [
+    {"key": "impersonation", "label": "冒充身份", "dimension": "social_engineering", "weight": 10, "phase": "preliminary"},
+    {"key": "verification_code_request", "label": "要求验证码", "dimension": "requested_actions", "weight": 18, "phase": "terminal"}
+  ],
+  "strength_levels": [{"value": "none", "label": "无", "score": 0}],
+  "victim_action_stages": [
+    {"value": "none", "label": "未操作", "score": 0},
+    {"value": "clicked_link", "label": "已点击链接", "score": 10, "multiplier": 1.5}
+  ],
+  "terminal_floors": [{"min_terminal": 1, "floor": 40}],
+  "combination_rules": [
+    {"name": "冒充+验证码", "all_of": ["impersonation", "verification_code_request"], "floor": 80}
+  ],
+  "threshold_bands": [
+    {"max_historical_score": 50, "threshold": 65},
+    {"max_historical_score": 100, "threshold": 35}
+  ]
+}`
+
+func writeRules(t *testing.T, dir string, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, "risk_rules.json")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write rules failed: %v", err)
+	}
+	return path
+}
+
+func TestDefaultRuleSet_MatchesBaselineScoring(t *testing.T) {
+	rules := scoring.DefaultRuleSet()
+	outcome, err := rules.Score(scoring.Signals{
+		Factors: map[string]bool{
+			"money_transfer_request":     true,
+			"private_account_collection": true,
+		},
+	})
+	if err != nil {
+		t.Fatalf("score failed: %v", err)
+	}
+	if outcome.Score != 76 || outcome.RuleSetVersion != rules.Version {
+		t.Fatalf("unexpected baseline outcome: %+v", outcome)
+	}
+	if !strings.Contains(strings.Join(outcome.HitRules, ","), "组合规则：转账+私人账户收款") {
+		t.Fatalf("expected combination rule to be reported, got %v", outcome.HitRules)
+	}
+	if rules.ThresholdFor(21) != 55 || rules.ThresholdFor(100) != 40 {
+		t.Fatalf("unexpected default thresholds")
+	}
+}
+
+func TestParseRuleSet_CombinationFloorAndStageMultiplier(t *testing.T) {
+	rules, err := scoring.ParseRuleSet([]byte(tunedRules))
+	if err != nil {
+		t.Fatalf("parse rules failed: %v", err)
+	}
+
+	combined, err := rules.Score(scoring.Signals{Factors: map[string]bool{"impersonation": true, "verification_code_request": true}})
+	if err != nil {
+		t.Fatalf("score failed: %v", err)
+	}
+	if combined.Score != 80 {
+		t.Fatalf("expected combination floor 80, got %d", combined.Score)
+	}
+
+	multiplied, err := rules.Score(scoring.Signals{Factors: map[string]bool{"impersonation": true}, VictimActionStage: "clicked_link"})
+	if err != nil {
+		t.Fatalf("score failed: %v", err)
+	}
+	if multiplied.Score != 30 || multiplied.Dimensions[scoring.DimensionLossExposure] != 10 {
+		t.Fatalf("expected (10+10)*1.5=30, got %+v", multiplied)
+	}
+
+	if rules.ThresholdFor(50) != 65 || rules.ThresholdFor(51) != 35 {
+		t.Fatalf("unexpected tuned thresholds")
+	}
+	if _, err := rules.Score(scoring.Signals{SimilarCaseStrength: "strong"}); err == nil || !strings.Contains(err.Error(), "none") {
+		t.Fatalf("expected strength enum error from tuned rules, got %v", err)
+	}
+}
+
+func TestParseRuleSet_RejectsInvalidRules(t *testing.T) {
+	cases := map[string]string{
+		"unknown factor":     strings.Replace(tunedRules, `"key": "impersonation"`, `"key": "telepathy"`, 1),
+		"uncovered bands":    strings.Replace(tunedRules, `"max_historical_score": 100`, `"max_historical_score": 90`, 1),
+		"missing version":    strings.Replace(tunedRules, `"version": "2026.04-tuned"`, `"version": ""`, 1),
+		"misspelled section": strings.Replace(tunedRules, `"terminal_floors"`, `"terminal_floor"`, 1),
+	}
+	for name, raw := range cases {
+		if _, err := scoring.ParseRuleSet([]byte(raw)); err == nil {
+			t.Fatalf("%s: expected validation error", name)
+		}
+	}
+}
+
+func TestSetRuleFile_ReloadKeepsLastValidRules(t *testing.T) {
+	t.Cleanup(func() { _ = scoring.SetRuleFile("") })
+	path := writeRules(t, t.TempDir(), tunedRules)
+
+	if err := scoring.SetRuleFile(path); err != nil {
+		t.Fatalf("set rule file failed: %v", err)
+	}
+	if scoring.CurrentVersion() != "2026.04-tuned" {
+		t.Fatalf("expected tuned version, got %s", scoring.CurrentVersion())
+	}
+
+	writeRules(t, filepath.Dir(path), strings.Replace(tunedRules, "2026.04-tuned", "2026.05-tuned", 1))
+	if err := scoring.Reload(); err != nil {
+		t.Fatalf("reload failed: %v", err)
+	}
+	if scoring.CurrentVersion() != "2026.05-tuned" {
+		t.Fatalf("expected reloaded version, got %s", scoring.CurrentVersion())
+	}
+
+	writeRules(t, filepath.Dir(path), `{"version": "broken"`)
+	if err := scoring.Reload(); err == nil {
+		t.Fatal("expected reload error for broken file")
+	}
+	if scoring.CurrentVersion() != "2026.05-tuned" {
+		t.Fatalf("expected last valid rules to stay active, got %s", scoring.CurrentVersion())
+	}
+
+	if err := scoring.SetRuleFile(""); err != nil || scoring.CurrentVersion() != scoring.DefaultRuleSet().Version {
+		t.Fatalf("expected built-in rules after reset, err=%v version=%s", err, scoring.CurrentVersion())
+	}
+}
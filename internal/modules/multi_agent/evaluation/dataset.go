@@ -0,0 +1,171 @@
+package evaluation
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"antifraud/internal/modules/multi_agent/adapters/outbound/tool"
+)
+
+// DefaultDatasetPath 是仓库内置标注集的相对路径（相对仓库根目录）。
+const DefaultDatasetPath = "test/labeled_cases.json"
+
+// WhiteSampleGroup 是标注集中正常样本分组名，其余分组均视为诈骗样本。
+const WhiteSampleGroup = "white_samples"
+
+const (
+	expectedScamRiskLevel   = "高"
+	expectedBenignRiskLevel = "低"
+)
+
+// Dataset 是按分组展开后的标注集。
+type Dataset struct {
+	Path  string        `json:"path"`
+	Cases []LabeledCase `json:"cases"`
+}
+
+// LabeledCase 是一条标注样本。
+// 标注集字段约定：
+// 1) type 为人工标注的诈骗类型；
+// 2) predict/predict_risk/score 为历史运行记录的模型输出，供 RecordedPredictor 与桩模型复用；
+// 3) 可选 risk 字段显式给出期望风险等级，缺省时正常样本期望“低”、诈骗样本期望“高”；
+// 4) 可选 assessment 字段给出桩模型提交的结构化风险因子，缺省时按关键词推断。
+type LabeledCase struct {
+	ID           string                    `json:"id"`
+	Group        string                    `json:"group"`
+	Text         string                    `json:"text,omitempty"`
+	MediaIndex   string                    `json:"media_index,omitempty"`
+	ScamType     string                    `json:"scam_type"`
+	ExpectedRisk string                    `json:"expected_risk"`
+	IsScam       bool                      `json:"is_scam"`
+	Recorded     RecordedPrediction        `json:"recorded"`
+	Assessment   *tool.RiskAssessmentInput `json:"assessment,omitempty"`
+}
+
+// RecordedPrediction 是标注集中保存的历史模型输出。
+type RecordedPrediction struct {
+	ScamType  string `json:"scam_type,omitempty"`
+	RiskLevel string `json:"risk_level,omitempty"`
+	Score     int    `json:"score"`
+	HasScore  bool   `json:"has_score"`
+}
+
+// Replayable 表示样本是否具备可回放到主流程的输入。
+// 仅记录媒体序号的样本（如 video 分组）缺少原始媒体，当前只能跳过。
+func (c LabeledCase) Replayable() bool {
+	return strings.TrimSpace(c.Text) != ""
+}
+
+type rawLabeledCase struct {
+	Text        string                    `json:"text"`
+	Index       flexibleInt               `json:"index"`
+	Type        string                    `json:"type"`
+	Predict     string                    `json:"predict"`
+	PredictRisk string                    `json:"predict_risk"`
+	Score       flexibleInt               `json:"score"`
+	Risk        string                    `json:"risk"`
+	Assessment  *tool.RiskAssessmentInput `json:"assessment"`
+}
+
+// flexibleInt 兼容标注集中以字符串或数字保存的分数与媒体序号。
+type flexibleInt struct {
+	value int
+	valid bool
+}
+
+func (f *flexibleInt) UnmarshalJSON(data []byte) error {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 || bytes.Equal(trimmed, []byte("null")) {
+		return nil
+	}
+	raw := string(trimmed)
+	if strings.HasPrefix(raw, "\"") {
+		var text string
+		if err := json.Unmarshal(trimmed, &text); err != nil {
+			return err
+		}
+		raw = strings.TrimSpace(text)
+		if raw == "" {
+			return nil
+		}
+	}
+	parsed, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return fmt.Errorf("invalid number %q: %w", raw, err)
+	}
+	f.value = int(parsed + 0.5)
+	f.valid = true
+	return nil
+}
+
+// LoadDataset 读取标注集文件。文件顶层为 “分组名 -> 样本数组”，分组按名称排序展开，保证报告顺序稳定。
+func LoadDataset(path string) (Dataset, error) {
+	trimmedPath := strings.TrimSpace(path)
+	if trimmedPath == "" {
+		trimmedPath = DefaultDatasetPath
+	}
+	raw, err := os.ReadFile(trimmedPath)
+	if err != nil {
+		return Dataset{}, fmt.Errorf("read dataset failed: %w", err)
+	}
+	dataset, err := ParseDataset(raw)
+	if err != nil {
+		return Dataset{}, err
+	}
+	dataset.Path = trimmedPath
+	return dataset, nil
+}
+
+// ParseDataset 解析标注集 JSON 内容。
+func ParseDataset(raw []byte) (Dataset, error) {
+	groups := map[string][]rawLabeledCase{}
+	if err := json.Unmarshal(bytes.TrimPrefix(raw, []byte("\xef\xbb\xbf")), &groups); err != nil {
+		return Dataset{}, fmt.Errorf("decode dataset failed: %w", err)
+	}
+
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	cases := make([]LabeledCase, 0)
+	for _, name := range names {
+		for index, item := range groups[name] {
+			isScam := strings.TrimSpace(name) != WhiteSampleGroup
+			expectedRisk := strings.TrimSpace(item.Risk)
+			if expectedRisk == "" {
+				expectedRisk = expectedBenignRiskLevel
+				if isScam {
+					expectedRisk = expectedScamRiskLevel
+				}
+			}
+			mediaIndex := ""
+			if item.Index.valid {
+				mediaIndex = strconv.Itoa(item.Index.value)
+			}
+			cases = append(cases, LabeledCase{
+				ID:           fmt.Sprintf("%s#%d", name, index+1),
+				Group:        name,
+				Text:         strings.TrimSpace(item.Text),
+				MediaIndex:   mediaIndex,
+				ScamType:     strings.TrimSpace(item.Type),
+				ExpectedRisk: expectedRisk,
+				IsScam:       isScam,
+				Recorded: RecordedPrediction{
+					ScamType:  strings.TrimSpace(item.Predict),
+					RiskLevel: strings.TrimSpace(item.PredictRisk),
+					Score:     item.Score.value,
+					HasScore:  item.Score.valid,
+				},
+				Assessment: item.Assessment,
+			})
+		}
+	}
+	return Dataset{Cases: cases}, nil
+}
@@ -0,0 +1,242 @@
+package evaluation
+
+import (
+	"sort"
+	"strings"
+)
+
+// riskLevelOrder 固定风险等级在混淆矩阵中的排列顺序。
+var riskLevelOrder = []string{"低", "中", "高"}
+
+const missingPredictionLabel = "(无)"
+
+// ConfusionMatrix 是按“期望标签 x 预测标签”统计的混淆矩阵，Matrix[i][j] 表示期望 Labels[i]、预测 Labels[j] 的样本数。
+type ConfusionMatrix struct {
+	Labels   []string       `json:"labels"`
+	Matrix   [][]int        `json:"matrix"`
+	Total    int            `json:"total"`
+	Correct  int            `json:"correct"`
+	Accuracy float64        `json:"accuracy"`
+	PerLabel []LabelMetrics `json:"per_label"`
+}
+
+// LabelMetrics 是单个标签的精确率与召回率。
+type LabelMetrics struct {
+	Label     string  `json:"label"`
+	Support   int     `json:"support"`
+	Predicted int     `json:"predicted"`
+	Precision float64 `json:"precision"`
+	Recall    float64 `json:"recall"`
+}
+
+// CalibrationBin 是分数校准曲线上的一个分箱：同一分数区间内诈骗样本占比应接近平均分/100。
+type CalibrationBin struct {
+	Lower     int     `json:"lower"`
+	Upper     int     `json:"upper"`
+	Count     int     `json:"count"`
+	MeanScore float64 `json:"mean_score"`
+	ScamRate  float64 `json:"scam_rate"`
+}
+
+// Calibration 汇总分数校准曲线与 Brier 分数（越低越好）。
+type Calibration struct {
+	Bins       []CalibrationBin `json:"bins"`
+	Samples    int              `json:"samples"`
+	BrierScore float64          `json:"brier_score"`
+}
+
+// RuleHitRate 是单条评分规则的命中统计。
+// Precision 表示命中该规则的样本中诈骗样本占比，用于发现误报率偏高的规则。
+type RuleHitRate struct {
+	Rule       string  `json:"rule"`
+	Hits       int     `json:"hits"`
+	HitRate    float64 `json:"hit_rate"`
+	ScamHits   int     `json:"scam_hits"`
+	BenignHits int     `json:"benign_hits"`
+	Precision  float64 `json:"precision"`
+}
+
+type labelPair struct {
+	expected  string
+	predicted string
+}
+
+// BuildConfusionMatrix 根据期望/预测标签对构建混淆矩阵；fixedOrder 中的标签优先排列，其余按名称排序。
+func BuildConfusionMatrix(expected []string, predicted []string, fixedOrder []string) ConfusionMatrix {
+	pairs := make([]labelPair, 0, len(expected))
+	seen := map[string]bool{}
+	for index := range expected {
+		pair := labelPair{expected: normalizeLabel(expected[index])}
+		if index < len(predicted) {
+			pair.predicted = normalizeLabel(predicted[index])
+		} else {
+			pair.predicted = missingPredictionLabel
+		}
+		pairs = append(pairs, pair)
+		seen[pair.expected] = true
+		seen[pair.predicted] = true
+	}
+
+	labels := make([]string, 0, len(seen))
+	for _, label := range fixedOrder {
+		if seen[label] {
+			labels = append(labels, label)
+			delete(seen, label)
+		}
+	}
+	rest := make([]string, 0, len(seen))
+	for label := range seen {
+		rest = append(rest, label)
+	}
+	sort.Strings(rest)
+	labels = append(labels, rest...)
+
+	position := make(map[string]int, len(labels))
+	for index, label := range labels {
+		position[label] = index
+	}
+	matrix := make([][]int, len(labels))
+	for index := range matrix {
+		matrix[index] = make([]int, len(labels))
+	}
+
+	result := ConfusionMatrix{Labels: labels, Matrix: matrix, Total: len(pairs)}
+	for _, pair := range pairs {
+		matrix[position[pair.expected]][position[pair.predicted]]++
+		if pair.expected == pair.predicted {
+			result.Correct++
+		}
+	}
+	result.Accuracy = ratio(result.Correct, result.Total)
+
+	result.PerLabel = make([]LabelMetrics, 0, len(labels))
+	for index, label := range labels {
+		metrics := LabelMetrics{Label: label}
+		for other := range labels {
+			metrics.Support += matrix[index][other]
+			metrics.Predicted += matrix[other][index]
+		}
+		if metrics.Support == 0 && metrics.Predicted == 0 {
+			continue
+		}
+		metrics.Precision = ratio(matrix[index][index], metrics.Predicted)
+		metrics.Recall = ratio(matrix[index][index], metrics.Support)
+		result.PerLabel = append(result.PerLabel, metrics)
+	}
+	return result
+}
+
+// BuildCalibration 把 0-100 分数划分为 bins 个等宽区间，统计每个区间的平均分与诈骗样本占比。
+func BuildCalibration(scores []int, isScam []bool, bins int) Calibration {
+	if bins <= 0 {
+		bins = 10
+	}
+	width := 100.0 / float64(bins)
+	result := Calibration{Bins: make([]CalibrationBin, bins)}
+	scoreSums := make([]int, bins)
+	scamCounts := make([]int, bins)
+	for index := range result.Bins {
+		result.Bins[index].Lower = int(float64(index)*width + 0.5)
+		result.Bins[index].Upper = int(float64(index+1)*width + 0.5)
+	}
+
+	brierSum := 0.0
+	for index, score := range scores {
+		if index >= len(isScam) {
+			break
+		}
+		clamped := clampScore(score)
+		bin := int(float64(clamped) / width)
+		if bin >= bins {
+			bin = bins - 1
+		}
+		result.Bins[bin].Count++
+		scoreSums[bin] += clamped
+		outcome := 0.0
+		if isScam[index] {
+			scamCounts[bin]++
+			outcome = 1
+		}
+		diff := float64(clamped)/100 - outcome
+		brierSum += diff * diff
+		result.Samples++
+	}
+	for index := range result.Bins {
+		count := result.Bins[index].Count
+		if count == 0 {
+			continue
+		}
+		result.Bins[index].MeanScore = float64(scoreSums[index]) / float64(count)
+		result.Bins[index].ScamRate = ratio(scamCounts[index], count)
+	}
+	if result.Samples > 0 {
+		result.BrierScore = brierSum / float64(result.Samples)
+	}
+	return result
+}
+
+// BuildRuleHitRates 统计每条规则的命中次数；hitRules 与 isScam 一一对应，分母为提供了命中规则的样本数。
+func BuildRuleHitRates(hitRules [][]string, isScam []bool) []RuleHitRate {
+	stats := map[string]*RuleHitRate{}
+	for index, rules := range hitRules {
+		scam := index < len(isScam) && isScam[index]
+		counted := map[string]bool{}
+		for _, rule := range rules {
+			trimmed := strings.TrimSpace(rule)
+			if trimmed == "" || counted[trimmed] {
+				continue
+			}
+			counted[trimmed] = true
+			item, ok := stats[trimmed]
+			if !ok {
+				item = &RuleHitRate{Rule: trimmed}
+				stats[trimmed] = item
+			}
+			item.Hits++
+			if scam {
+				item.ScamHits++
+			} else {
+				item.BenignHits++
+			}
+		}
+	}
+
+	result := make([]RuleHitRate, 0, len(stats))
+	for _, item := range stats {
+		item.HitRate = ratio(item.Hits, len(hitRules))
+		item.Precision = ratio(item.ScamHits, item.Hits)
+		result = append(result, *item)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Hits != result[j].Hits {
+			return result[i].Hits > result[j].Hits
+		}
+		return result[i].Rule < result[j].Rule
+	})
+	return result
+}
+
+func normalizeLabel(label string) string {
+	trimmed := strings.TrimSpace(label)
+	if trimmed == "" {
+		return missingPredictionLabel
+	}
+	return trimmed
+}
+
+func clampScore(score int) int {
+	if score < 0 {
+		return 0
+	}
+	if score > 100 {
+		return 100
+	}
+	return score
+}
+
+func ratio(numerator int, denominator int) float64 {
+	if denominator <= 0 {
+		return 0
+	}
+	return float64(numerator) / float64(denominator)
+}
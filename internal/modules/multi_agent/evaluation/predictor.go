@@ -0,0 +1,115 @@
+package evaluation
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"antifraud/internal/modules/multi_agent/adapters/outbound/state"
+	"antifraud/internal/modules/multi_agent/adapters/outbound/tool"
+	multi_agent "antifraud/internal/modules/multi_agent/core"
+)
+
+const defaultEvaluationUserID = "eval-user"
+
+// Prediction 是单条样本的结构化预测结果。
+type Prediction struct {
+	ScamType  string   `json:"scam_type"`
+	RiskLevel string   `json:"risk_level"`
+	Score     int      `json:"score"`
+	HasScore  bool     `json:"has_score"`
+	HitRules  []string `json:"hit_rules,omitempty"`
+	// HasHitRules 区分“未命中任何规则”与“预测来源不提供命中规则”，后者不计入规则命中率分母。
+	HasHitRules bool   `json:"has_hit_rules"`
+	Report      string `json:"report,omitempty"`
+}
+
+// Predictor 对单条标注样本给出预测。
+type Predictor interface {
+	Name() string
+	Predict(ctx context.Context, labeled LabeledCase) (Prediction, error)
+}
+
+// RecordedScamTypePredictor 由诈骗类型直接取自标注集 predict 字段的预测来源实现。
+// 这类来源的诈骗类型混淆矩阵只是复算历史输出，提示词或规则变化不会反映在其中，报告据此标注来源。
+type RecordedScamTypePredictor interface {
+	ScamTypeFromRecorded() bool
+}
+
+// RecordedPredictor 直接复用标注集中保存的历史模型输出，不调用任何模型。
+// 用于对比“历史运行”与“当前主流程”的指标差异。
+type RecordedPredictor struct{}
+
+func (RecordedPredictor) Name() string {
+	return "recorded"
+}
+
+func (RecordedPredictor) ScamTypeFromRecorded() bool {
+	return true
+}
+
+func (RecordedPredictor) Predict(ctx context.Context, labeled LabeledCase) (Prediction, error) {
+	if strings.TrimSpace(labeled.Recorded.ScamType) == "" && strings.TrimSpace(labeled.Recorded.RiskLevel) == "" {
+		return Prediction{}, fmt.Errorf("recorded prediction is empty")
+	}
+	return Prediction{
+		ScamType:  labeled.Recorded.ScamType,
+		RiskLevel: labeled.Recorded.RiskLevel,
+		Score:     labeled.Recorded.Score,
+		HasScore:  labeled.Recorded.HasScore,
+	}, nil
+}
+
+// MainAgentPredictor 把样本回放到完整的 MainAgent 流程，并通过 AnalysisTrace 读取结构化结果。
+// 回放不携带 taskID，因此不会写入进度事件或更新进行中任务。
+type MainAgentPredictor struct {
+	Label  string
+	Agent  *multi_agent.MainAgent
+	UserID string
+	// RecordedScamType 表示驱动主流程的模型按标注集 predict 回显诈骗类型（如 StubLLM），诈骗类型不是模型判定的结果。
+	RecordedScamType bool
+}
+
+func (p MainAgentPredictor) Name() string {
+	if label := strings.TrimSpace(p.Label); label != "" {
+		return label
+	}
+	return "main_agent"
+}
+
+func (p MainAgentPredictor) ScamTypeFromRecorded() bool {
+	return p.RecordedScamType
+}
+
+func (p MainAgentPredictor) Predict(ctx context.Context, labeled LabeledCase) (Prediction, error) {
+	if p.Agent == nil {
+		return Prediction{}, fmt.Errorf("main agent is not configured")
+	}
+	userID := strings.TrimSpace(p.UserID)
+	if userID == "" {
+		userID = defaultEvaluationUserID
+	}
+
+	trace := tool.NewAnalysisTrace()
+	report, err := p.Agent.AnalyzeTaskPayload(tool.BindAnalysisTrace(ctx, trace), userID, "", state.TaskPayload{Text: labeled.Text})
+	if err != nil {
+		return Prediction{}, err
+	}
+
+	finalReport, ok := trace.LatestFinalReport()
+	if !ok {
+		return Prediction{}, fmt.Errorf("main agent finished without %s", tool.FinalReportToolName)
+	}
+	prediction := Prediction{
+		ScamType:  finalReport.ScamType,
+		RiskLevel: finalReport.RiskLevel,
+		Report:    report,
+	}
+	if assessment, ok := trace.LatestRiskAssessment(); ok {
+		prediction.Score = assessment.Score
+		prediction.HasScore = true
+		prediction.HitRules = assessment.HitRules
+		prediction.HasHitRules = true
+	}
+	return prediction, nil
+}
@@ -0,0 +1,143 @@
+package evaluation
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// WriteJSON 以缩进 JSON 输出完整评测报告，便于在 CI 中保存并与上一次结果做 diff。
+func WriteJSON(w io.Writer, report Report) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
+
+// WriteMarkdown 输出便于人工审阅的 Markdown 摘要报告。
+func WriteMarkdown(w io.Writer, report Report) error {
+	var builder strings.Builder
+
+	builder.WriteString("# 离线评测报告\n\n")
+	builder.WriteString(fmt.Sprintf("- 生成时间：%s\n", report.GeneratedAt.Format(time.RFC3339)))
+	builder.WriteString(fmt.Sprintf("- 数据集：`%s`\n", noneFallback(report.Dataset)))
+	builder.WriteString(fmt.Sprintf("- 预测来源：`%s`\n", report.Predictor))
+	builder.WriteString(fmt.Sprintf("- 评分规则版本：`%s`\n", noneFallback(report.RuleVersion)))
+	builder.WriteString(fmt.Sprintf("- 样本数：%d（已评测 %d，跳过 %d，失败 %d）\n\n",
+		report.TotalCases, report.EvaluatedCases, report.SkippedCases, report.FailedCases))
+
+	if report.ScamTypeSource == ScamTypeSourceRecorded {
+		builder.WriteString("## 1. 诈骗类型（recorded）\n\n")
+		builder.WriteString("> 诈骗类型取自标注集记录的 predict 字段而非本次模型判定，下表仅复算历史输出，不反映提示词或规则的变化。\n\n")
+	} else {
+		builder.WriteString("## 1. 诈骗类型\n\n")
+	}
+	writeConfusionMarkdown(&builder, report.ScamType)
+
+	builder.WriteString("## 2. 风险等级\n\n")
+	writeConfusionMarkdown(&builder, report.RiskLevel)
+
+	builder.WriteString("## 3. 分数校准\n\n")
+	if report.Calibration.Samples == 0 {
+		builder.WriteString("无可用分数。\n\n")
+	} else {
+		builder.WriteString(fmt.Sprintf("样本数 %d，Brier 分数 %.4f。\n\n", report.Calibration.Samples, report.Calibration.BrierScore))
+		builder.WriteString("| 分数区间 | 样本数 | 平均分 | 诈骗占比 |\n")
+		builder.WriteString("| --- | ---: | ---: | ---: |\n")
+		for _, bin := range report.Calibration.Bins {
+			if bin.Count == 0 {
+				continue
+			}
+			builder.WriteString(fmt.Sprintf("| %d-%d | %d | %.1f | %s |\n", bin.Lower, bin.Upper, bin.Count, bin.MeanScore, formatPercent(bin.ScamRate)))
+		}
+		builder.WriteString("\n")
+	}
+
+	builder.WriteString("## 4. 规则命中率\n\n")
+	if len(report.RuleHits) == 0 {
+		builder.WriteString("无命中规则记录。\n\n")
+	} else {
+		builder.WriteString("| 规则 | 命中数 | 命中率 | 诈骗样本 | 正常样本 | 精确率 |\n")
+		builder.WriteString("| --- | ---: | ---: | ---: | ---: | ---: |\n")
+		for _, item := range report.RuleHits {
+			builder.WriteString(fmt.Sprintf("| %s | %d | %s | %d | %d | %s |\n",
+				escapeMarkdownCell(item.Rule), item.Hits, formatPercent(item.HitRate), item.ScamHits, item.BenignHits, formatPercent(item.Precision)))
+		}
+		builder.WriteString("\n")
+	}
+
+	builder.WriteString("## 5. 错误与跳过样本\n\n")
+	problems := 0
+	for _, item := range report.Cases {
+		switch item.Status {
+		case CaseStatusSkipped, CaseStatusFailed:
+			builder.WriteString(fmt.Sprintf("- `%s` %s：%s\n", item.ID, item.Status, escapeMarkdownCell(item.Reason)))
+			problems++
+		case CaseStatusEvaluated:
+			if item.Prediction == nil {
+				continue
+			}
+			if item.Prediction.ScamType != item.ScamType || item.Prediction.RiskLevel != item.ExpectedRisk {
+				builder.WriteString(fmt.Sprintf("- `%s` 预测偏差：类型 %s -> %s，风险 %s -> %s，分数 %d\n",
+					item.ID, item.ScamType, noneFallback(item.Prediction.ScamType), item.ExpectedRisk, noneFallback(item.Prediction.RiskLevel), item.Prediction.Score))
+				problems++
+			}
+		}
+	}
+	if problems == 0 {
+		builder.WriteString("无。\n")
+	}
+
+	_, err := io.WriteString(w, builder.String())
+	return err
+}
+
+func writeConfusionMarkdown(builder *strings.Builder, matrix ConfusionMatrix) {
+	if matrix.Total == 0 {
+		builder.WriteString("无已评测样本。\n\n")
+		return
+	}
+	builder.WriteString(fmt.Sprintf("准确率 %s（%d/%d）。行为期望标签，列为预测标签。\n\n", formatPercent(matrix.Accuracy), matrix.Correct, matrix.Total))
+
+	builder.WriteString("| 期望 \\ 预测 |")
+	for _, label := range matrix.Labels {
+		builder.WriteString(" " + escapeMarkdownCell(label) + " |")
+	}
+	builder.WriteString("\n| --- |")
+	for range matrix.Labels {
+		builder.WriteString(" ---: |")
+	}
+	builder.WriteString("\n")
+	for row, label := range matrix.Labels {
+		builder.WriteString("| " + escapeMarkdownCell(label) + " |")
+		for _, count := range matrix.Matrix[row] {
+			builder.WriteString(fmt.Sprintf(" %d |", count))
+		}
+		builder.WriteString("\n")
+	}
+	builder.WriteString("\n| 标签 | 样本数 | 预测数 | 精确率 | 召回率 |\n")
+	builder.WriteString("| --- | ---: | ---: | ---: | ---: |\n")
+	for _, item := range matrix.PerLabel {
+		builder.WriteString(fmt.Sprintf("| %s | %d | %d | %s | %s |\n",
+			escapeMarkdownCell(item.Label), item.Support, item.Predicted, formatPercent(item.Precision), formatPercent(item.Recall)))
+	}
+	builder.WriteString("\n")
+}
+
+func formatPercent(value float64) string {
+	return fmt.Sprintf("%.1f%%", value*100)
+}
+
+func escapeMarkdownCell(value string) string {
+	replacer := strings.NewReplacer("|", "\\|", "\n", " ", "\r", " ")
+	return replacer.Replace(strings.TrimSpace(value))
+}
+
+func noneFallback(value string) string {
+	if strings.TrimSpace(value) == "" {
+		return missingPredictionLabel
+	}
+	return strings.TrimSpace(value)
+}
@@ -0,0 +1,155 @@
+package evaluation
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"antifraud/internal/modules/multi_agent/domain/scoring"
+)
+
+const (
+	CaseStatusEvaluated = "evaluated"
+	CaseStatusSkipped   = "skipped"
+	CaseStatusFailed    = "failed"
+)
+
+// 诈骗类型的预测来源：model 为模型判定，recorded 为复用标注集 predict 字段。
+const (
+	ScamTypeSourceModel    = "model"
+	ScamTypeSourceRecorded = "recorded"
+)
+
+// RunOptions 定义一次评测的可选参数。
+type RunOptions struct {
+	// CalibrationBins 是分数校准曲线的分箱数量，默认 10。
+	CalibrationBins int
+	// Now 可选，用于测试固定报告生成时间。
+	Now func() time.Time
+}
+
+// Report 是一次离线评测的完整结果。
+type Report struct {
+	GeneratedAt    time.Time       `json:"generated_at"`
+	Dataset        string          `json:"dataset"`
+	Predictor      string          `json:"predictor"`
+	RuleVersion    string          `json:"rule_version"`
+	TotalCases     int             `json:"total_cases"`
+	EvaluatedCases int             `json:"evaluated_cases"`
+	SkippedCases   int             `json:"skipped_cases"`
+	FailedCases    int             `json:"failed_cases"`
+	ScamType       ConfusionMatrix `json:"scam_type"`
+	// ScamTypeSource 为 recorded 时诈骗类型混淆矩阵只复算标注集 predict 与 type，不能用来发现回归。
+	ScamTypeSource string          `json:"scam_type_source"`
+	RiskLevel      ConfusionMatrix `json:"risk_level"`
+	Calibration    Calibration     `json:"calibration"`
+	RuleHits       []RuleHitRate   `json:"rule_hits"`
+	Cases          []CaseResult    `json:"cases"`
+}
+
+// CaseResult 是单条样本的评测明细。
+type CaseResult struct {
+	ID           string      `json:"id"`
+	Group        string      `json:"group"`
+	Status       string      `json:"status"`
+	Reason       string      `json:"reason,omitempty"`
+	ScamType     string      `json:"scam_type"`
+	ExpectedRisk string      `json:"expected_risk"`
+	IsScam       bool        `json:"is_scam"`
+	Prediction   *Prediction `json:"prediction,omitempty"`
+	DurationMS   int64       `json:"duration_ms"`
+}
+
+// Run 按顺序把标注样本交给 predictor 预测并汇总指标。
+// 缺少可回放输入的样本记为 skipped，预测失败的样本记为 failed，二者都不计入指标。
+func Run(ctx context.Context, dataset Dataset, predictor Predictor, options RunOptions) (Report, error) {
+	if predictor == nil {
+		return Report{}, fmt.Errorf("predictor is nil")
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	now := time.Now
+	if options.Now != nil {
+		now = options.Now
+	}
+
+	report := Report{
+		GeneratedAt: now(),
+		Dataset:     dataset.Path,
+		Predictor:   predictor.Name(),
+		RuleVersion: scoring.CurrentVersion(),
+		TotalCases:  len(dataset.Cases),
+		Cases:       make([]CaseResult, 0, len(dataset.Cases)),
+	}
+
+	expectedTypes := make([]string, 0, len(dataset.Cases))
+	predictedTypes := make([]string, 0, len(dataset.Cases))
+	expectedRisks := make([]string, 0, len(dataset.Cases))
+	predictedRisks := make([]string, 0, len(dataset.Cases))
+	scores := make([]int, 0, len(dataset.Cases))
+	scoreOutcomes := make([]bool, 0, len(dataset.Cases))
+	hitRules := make([][]string, 0, len(dataset.Cases))
+	ruleOutcomes := make([]bool, 0, len(dataset.Cases))
+
+	for _, labeled := range dataset.Cases {
+		if err := ctx.Err(); err != nil {
+			return Report{}, fmt.Errorf("evaluation cancelled: %w", err)
+		}
+		result := CaseResult{
+			ID:           labeled.ID,
+			Group:        labeled.Group,
+			ScamType:     labeled.ScamType,
+			ExpectedRisk: labeled.ExpectedRisk,
+			IsScam:       labeled.IsScam,
+		}
+		if !labeled.Replayable() {
+			result.Status = CaseStatusSkipped
+			result.Reason = "missing replayable text input"
+			report.SkippedCases++
+			report.Cases = append(report.Cases, result)
+			continue
+		}
+
+		startedAt := time.Now()
+		prediction, err := predictor.Predict(ctx, labeled)
+		result.DurationMS = time.Since(startedAt).Milliseconds()
+		if err != nil {
+			log.Printf("[evaluation] predict failed: case=%s predictor=%s err=%v", labeled.ID, predictor.Name(), err)
+			result.Status = CaseStatusFailed
+			result.Reason = err.Error()
+			report.FailedCases++
+			report.Cases = append(report.Cases, result)
+			continue
+		}
+
+		result.Status = CaseStatusEvaluated
+		result.Prediction = &prediction
+		report.EvaluatedCases++
+		report.Cases = append(report.Cases, result)
+
+		expectedTypes = append(expectedTypes, labeled.ScamType)
+		predictedTypes = append(predictedTypes, prediction.ScamType)
+		expectedRisks = append(expectedRisks, labeled.ExpectedRisk)
+		predictedRisks = append(predictedRisks, prediction.RiskLevel)
+		if prediction.HasScore {
+			scores = append(scores, prediction.Score)
+			scoreOutcomes = append(scoreOutcomes, labeled.IsScam)
+		}
+		if prediction.HasHitRules {
+			hitRules = append(hitRules, prediction.HitRules)
+			ruleOutcomes = append(ruleOutcomes, labeled.IsScam)
+		}
+	}
+
+	report.ScamType = BuildConfusionMatrix(expectedTypes, predictedTypes, nil)
+	report.ScamTypeSource = ScamTypeSourceModel
+	if recorded, ok := predictor.(RecordedScamTypePredictor); ok && recorded.ScamTypeFromRecorded() {
+		report.ScamTypeSource = ScamTypeSourceRecorded
+	}
+	report.RiskLevel = BuildConfusionMatrix(expectedRisks, predictedRisks, riskLevelOrder)
+	report.Calibration = BuildCalibration(scores, scoreOutcomes, options.CalibrationBins)
+	report.RuleHits = BuildRuleHitRates(hitRules, ruleOutcomes)
+	return report, nil
+}
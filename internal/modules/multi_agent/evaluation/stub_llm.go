@@ -0,0 +1,235 @@
+package evaluation
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"antifraud/internal/modules/multi_agent/adapters/outbound/tool"
+	openai "antifraud/internal/platform/llm"
+	"antifraud/internal/platform/redaction"
+)
+
+// StubBaseURL 是桩模型客户端使用的占位地址，请求不会离开进程。
+const StubBaseURL = "http://eval-stub.local/v1"
+
+const (
+	stubHighRiskScore   = 70
+	stubMediumRiskScore = 40
+)
+
+// StubLLM 是按固定脚本应答的主智能体桩模型，实现 http.RoundTripper 后注入 llm.Client。
+// 每条样本的对话脚本：
+// 1) 第一轮调用 submit_current_risk_assessment，风险因子取自样本 assessment 字段，缺省时按关键词推断；
+// 2) 第二轮读取工具返回的分数换算风险等级，并以样本记录的 predict 作为诈骗类型调用 submit_final_report；
+// 3) 第三轮不再调用工具，主智能体返回已生成的最终报告。
+// 风险分数与命中规则始终由真实评分工具计算，评分权重变化会直接反映在评测指标中；
+// 诈骗类型只是回显标注集记录，使用桩模型时 MainAgentPredictor 应设置 RecordedScamType，报告会把该矩阵标注为 recorded。
+type StubLLM struct {
+	cases []LabeledCase
+}
+
+// NewStubLLM 以标注样本构造桩模型，按主智能体输入中的样本文本匹配脚本。
+func NewStubLLM(cases []LabeledCase) *StubLLM {
+	return &StubLLM{cases: append([]LabeledCase{}, cases...)}
+}
+
+// NewClient 返回经由桩模型应答的 llm.Client。
+func (s *StubLLM) NewClient() *openai.Client {
+	return openai.NewClientWithConfig(openai.Config{
+		APIKey:     "eval-stub",
+		BaseURL:    StubBaseURL,
+		HTTPClient: &http.Client{Transport: s},
+	})
+}
+
+func (s *StubLLM) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !strings.HasSuffix(req.URL.Path, "/chat/completions") {
+		return stubHTTPResponse(req, http.StatusNotFound, map[string]interface{}{"error": "stub only serves chat completions"})
+	}
+	var chatReq openai.ChatCompletionRequest
+	if req.Body != nil {
+		defer req.Body.Close()
+		if err := json.NewDecoder(req.Body).Decode(&chatReq); err != nil {
+			return stubHTTPResponse(req, http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		}
+	}
+
+	message, err := s.nextMessage(chatReq.Messages)
+	if err != nil {
+		return stubHTTPResponse(req, http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+	}
+	return stubHTTPResponse(req, http.StatusOK, openai.ChatCompletionResponse{
+		Choices: []openai.ChatCompletionChoice{{Message: message}},
+	})
+}
+
+func (s *StubLLM) nextMessage(messages []openai.ChatCompletionMessage) (openai.ChatCompletionMessage, error) {
+	labeled, ok := s.matchCase(messages)
+	if !ok {
+		return openai.ChatCompletionMessage{}, fmt.Errorf("stub script not found for main agent input")
+	}
+
+	calledTools := map[string]string{}
+	for _, message := range messages {
+		for _, call := range message.ToolCalls {
+			calledTools[call.ID] = call.Function.Name
+		}
+	}
+	assessed := false
+	reported := false
+	score := 0
+	for _, message := range messages {
+		if message.Role != openai.ChatMessageRoleTool {
+			continue
+		}
+		switch calledTools[message.ToolCallID] {
+		case tool.RiskAssessmentToolName:
+			var payload struct {
+				Score int `json:"score"`
+			}
+			if err := json.Unmarshal([]byte(message.Content), &payload); err == nil {
+				assessed = true
+				score = payload.Score
+			}
+		case tool.FinalReportToolName:
+			reported = true
+		}
+	}
+
+	switch {
+	case !assessed:
+		return stubToolCallMessage("eval_call_assessment", tool.RiskAssessmentToolName, stubAssessmentInput(labeled))
+	case !reported:
+		scamType := labeled.Recorded.ScamType
+		if strings.TrimSpace(scamType) == "" {
+			scamType = labeled.ScamType
+		}
+		riskLevel := stubRiskLevel(score)
+		// 真实模型只能看到脱敏后的文本，桩模型回显的文本发现同样使用占位符。
+		return stubToolCallMessage("eval_call_final_report", tool.FinalReportToolName, tool.FinalReportPayload{
+			Summary:     fmt.Sprintf("离线评测桩模型结论：风险分 %d。", score),
+			TextFinding: redaction.NewRedactor(nil).Redact(labeled.Text),
+			ScamType:    scamType,
+			RiskLevel:   riskLevel,
+			RiskReason:  fmt.Sprintf("评分工具给出 %d 分，按桩模型阈值判定为%s风险。", score, riskLevel),
+		})
+	default:
+		return openai.ChatCompletionMessage{
+			Role:    openai.ChatMessageRoleAssistant,
+			Content: "离线评测桩模型：分析完成。",
+		}, nil
+	}
+}
+
+// matchCase 按主智能体 user 消息中包含的样本文本定位脚本。
+// 主智能体收到的是脱敏后的文本，样本同样经一个新的 Redactor 脱敏后再比较，占位符编号与主流程一致。
+func (s *StubLLM) matchCase(messages []openai.ChatCompletionMessage) (LabeledCase, bool) {
+	for _, message := range messages {
+		if message.Role != openai.ChatMessageRoleUser {
+			continue
+		}
+		for _, labeled := range s.cases {
+			text := strings.TrimSpace(labeled.Text)
+			if text == "" {
+				continue
+			}
+			if strings.Contains(message.Content, text) || strings.Contains(message.Content, redaction.NewRedactor(nil).Redact(text)) {
+				return labeled, true
+			}
+		}
+	}
+	return LabeledCase{}, false
+}
+
+func stubRiskLevel(score int) string {
+	switch {
+	case score >= stubHighRiskScore:
+		return "高"
+	case score >= stubMediumRiskScore:
+		return "中"
+	default:
+		return "低"
+	}
+}
+
+func stubToolCallMessage(callID string, toolName string, arguments interface{}) (openai.ChatCompletionMessage, error) {
+	encoded, err := json.Marshal(arguments)
+	if err != nil {
+		return openai.ChatCompletionMessage{}, fmt.Errorf("encode stub tool arguments failed: %w", err)
+	}
+	return openai.ChatCompletionMessage{
+		Role: openai.ChatMessageRoleAssistant,
+		ToolCalls: []openai.ToolCall{{
+			ID:   callID,
+			Type: openai.ToolTypeFunction,
+			Function: openai.FunctionCall{
+				Name:      toolName,
+				Arguments: string(encoded),
+			},
+		}},
+	}, nil
+}
+
+func stubHTTPResponse(req *http.Request, status int, body interface{}) (*http.Response, error) {
+	encoded, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Response{
+		StatusCode:    status,
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          io.NopCloser(bytes.NewReader(encoded)),
+		ContentLength: int64(len(encoded)),
+		Request:       req,
+	}, nil
+}
+
+// stubRiskSignal 描述一个风险因子及其触发关键词。
+type stubRiskSignal struct {
+	keywords []string
+	apply    func(input *tool.RiskAssessmentInput)
+}
+
+// stubRiskSignals 是桩模型推断风险因子的关键词表，仅用于标注集未提供 assessment 的样本。
+var stubRiskSignals = []stubRiskSignal{
+	{keywords: []string{"公安", "警官", "警号", "检察院", "法院", "客服", "领导", "工作人员"}, apply: func(input *tool.RiskAssessmentInput) { input.Impersonation = true }},
+	{keywords: []string{"立即", "马上", "尽快", "赶紧", "限时", "今天内", "否则"}, apply: func(input *tool.RiskAssessmentInput) { input.Urgency = true }},
+	{keywords: []string{"冻结", "逮捕", "通缉", "涉嫌", "洗钱", "坐牢", "影响征信"}, apply: func(input *tool.RiskAssessmentInput) { input.ThreatPressure = true }},
+	{keywords: []string{"返利", "佣金", "高收益", "日结", "稳赚", "回报", "赔偿", "理赔"}, apply: func(input *tool.RiskAssessmentInput) { input.BenefitInducement = true }},
+	{keywords: []string{"加微信", "加QQ", "私聊", "拉你进群", "加群", "备用号"}, apply: func(input *tool.RiskAssessmentInput) { input.ChannelSwitchRequest = true }},
+	{keywords: []string{"邀请码", "口令"}, apply: func(input *tool.RiskAssessmentInput) { input.InviteCodeRequest = true }},
+	{keywords: []string{"宝贝", "亲爱的", "相信我"}, apply: func(input *tool.RiskAssessmentInput) { input.TrustBuildingPressure = true }},
+	{keywords: []string{"转账", "汇款", "充值", "打款", "垫付", "保证金", "解冻费"}, apply: func(input *tool.RiskAssessmentInput) { input.MoneyTransferRequest = true }},
+	{keywords: []string{"验证码"}, apply: func(input *tool.RiskAssessmentInput) { input.VerificationCodeRequest = true }},
+	{keywords: []string{"屏幕共享", "共享屏幕", "远程"}, apply: func(input *tool.RiskAssessmentInput) { input.RemoteControlRequest = true }},
+	{keywords: []string{"链接", "下载", "安装", "扫码", "二维码"}, apply: func(input *tool.RiskAssessmentInput) { input.LinkOrAppInstallRequest = true }},
+	{keywords: []string{"身份证号", "银行卡号", "密码", "人脸"}, apply: func(input *tool.RiskAssessmentInput) { input.SensitiveInfoRequest = true }},
+	{keywords: []string{"安全账户", "个人账户", "指定账户"}, apply: func(input *tool.RiskAssessmentInput) { input.PrivateAccountCollection = true }},
+}
+
+func stubAssessmentInput(labeled LabeledCase) tool.RiskAssessmentInput {
+	if labeled.Assessment != nil {
+		return *labeled.Assessment
+	}
+	input := tool.RiskAssessmentInput{}
+	evidence := make([]string, 0, 6)
+	for _, signal := range stubRiskSignals {
+		for _, keyword := range signal.keywords {
+			if !strings.Contains(labeled.Text, keyword) {
+				continue
+			}
+			signal.apply(&input)
+			if len(evidence) < 6 {
+				evidence = append(evidence, keyword)
+			}
+			break
+		}
+	}
+	input.KeyEvidence = evidence
+	return input
+}
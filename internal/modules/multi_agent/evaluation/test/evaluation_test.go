@@ -0,0 +1,212 @@
+package evaluation_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	multi_agent "antifraud/internal/modules/multi_agent/core"
+	"antifraud/internal/modules/multi_agent/evaluation"
+	"antifraud/internal/platform/config"
+	openai "antifraud/internal/platform/llm"
+)
+
+const sampleDataset = `{
+  "white_samples": [
+    {"text": "银行网点工作人员当面介绍理财产品说明书，客户自行决定是否购买。", "type": "其他诈骗类", "predict": "其他诈骗类", "predict_risk": "低", "score": "8"}
+  ],
+  "scam_cases": [
+    {"text": "自称公安的人要求把存款转到安全账户配合调查。", "type": "冒充公检法类", "predict": "冒充公检法类", "predict_risk": "高", "score": 87,
+     "assessment": {"impersonation": true, "money_transfer_request": true, "private_account_collection": true}}
+  ],
+  "video": [
+    {"index": 6, "type": "冒充客服类", "predict": "冒充客服类", "predict_risk": "高", "score": "100"}
+  ]
+}`
+
+func TestParseDataset_ExpandsGroupsWithDefaults(t *testing.T) {
+	dataset, err := evaluation.ParseDataset([]byte(sampleDataset))
+	if err != nil {
+		t.Fatalf("parse dataset failed: %v", err)
+	}
+	if len(dataset.Cases) != 3 {
+		t.Fatalf("expected 3 cases, got %d", len(dataset.Cases))
+	}
+
+	byID := map[string]evaluation.LabeledCase{}
+	for _, item := range dataset.Cases {
+		byID[item.ID] = item
+	}
+	scam := byID["scam_cases#1"]
+	if !scam.IsScam || scam.ExpectedRisk != "高" || scam.Recorded.Score != 87 || !scam.Recorded.HasScore || scam.Assessment == nil {
+		t.Fatalf("unexpected scam case: %+v", scam)
+	}
+	white := byID["white_samples#1"]
+	if white.IsScam || white.ExpectedRisk != "低" || white.Recorded.Score != 8 {
+		t.Fatalf("unexpected white sample: %+v", white)
+	}
+	video := byID["video#1"]
+	if video.Replayable() || video.MediaIndex != "6" {
+		t.Fatalf("expected media-only case to be non-replayable, got %+v", video)
+	}
+}
+
+func TestRun_StubLLMDrivesMainAgentPipeline(t *testing.T) {
+	dataset, err := evaluation.ParseDataset([]byte(sampleDataset))
+	if err != nil {
+		t.Fatalf("parse dataset failed: %v", err)
+	}
+
+	stub := evaluation.NewStubLLM(dataset.Cases)
+	agent := multi_agent.NewMainAgentWithClient(config.ModelConfig{Model: "eval-stub"}, config.RetryConfig{MaxRetries: 1}, "系统提示词", stub.NewClient())
+	fixedNow := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	report, err := evaluation.Run(context.Background(), dataset, evaluation.MainAgentPredictor{Label: "stub", Agent: agent, RecordedScamType: true}, evaluation.RunOptions{
+		CalibrationBins: 5,
+		Now:             func() time.Time { return fixedNow },
+	})
+	if err != nil {
+		t.Fatalf("run evaluation failed: %v", err)
+	}
+
+	if report.EvaluatedCases != 2 || report.SkippedCases != 1 || report.FailedCases != 0 {
+		t.Fatalf("unexpected case counters: %+v", report)
+	}
+	if report.ScamType.Accuracy != 1 || report.ScamTypeSource != evaluation.ScamTypeSourceRecorded {
+		t.Fatalf("expected recorded scam type accuracy 1, got source=%q %+v", report.ScamTypeSource, report.ScamType)
+	}
+	if report.RiskLevel.Correct != 2 {
+		t.Fatalf("expected both risk levels to match, got %+v", report.RiskLevel)
+	}
+
+	var scamPrediction *evaluation.Prediction
+	for _, item := range report.Cases {
+		if item.ID == "scam_cases#1" {
+			scamPrediction = item.Prediction
+		}
+	}
+	if scamPrediction == nil || scamPrediction.Score != 76 || scamPrediction.RiskLevel != "高" {
+		t.Fatalf("expected scoring tool result to drive prediction, got %+v", scamPrediction)
+	}
+
+	rules := map[string]evaluation.RuleHitRate{}
+	for _, item := range report.RuleHits {
+		rules[item.Rule] = item
+	}
+	if transfer := rules["要求转账/充值"]; transfer.Hits != 1 || transfer.ScamHits != 1 || transfer.HitRate != 0.5 {
+		t.Fatalf("unexpected transfer rule stats: %+v", transfer)
+	}
+	if report.Calibration.Samples != 2 || report.Calibration.Bins[3].Count != 1 || report.Calibration.Bins[3].ScamRate != 1 {
+		t.Fatalf("unexpected calibration: %+v", report.Calibration)
+	}
+
+	var jsonOut bytes.Buffer
+	if err := evaluation.WriteJSON(&jsonOut, report); err != nil {
+		t.Fatalf("write json failed: %v", err)
+	}
+	var decoded evaluation.Report
+	if err := json.Unmarshal(jsonOut.Bytes(), &decoded); err != nil || decoded.Predictor != "stub" || !decoded.GeneratedAt.Equal(fixedNow) {
+		t.Fatalf("unexpected json report: err=%v report=%+v", err, decoded)
+	}
+
+	var markdown bytes.Buffer
+	if err := evaluation.WriteMarkdown(&markdown, report); err != nil {
+		t.Fatalf("write markdown failed: %v", err)
+	}
+	for _, section := range []string{"## 1. 诈骗类型（recorded）", "## 2. 风险等级", "## 3. 分数校准", "## 4. 规则命中率", "`video#1` skipped"} {
+		if !strings.Contains(markdown.String(), section) {
+			t.Fatalf("markdown report missing %q:\n%s", section, markdown.String())
+		}
+	}
+}
+
+type recordingTransport struct {
+	next   http.RoundTripper
+	bodies []string
+}
+
+func (r *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	r.bodies = append(r.bodies, string(body))
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return r.next.RoundTrip(req)
+}
+
+func TestRun_MainAgentNeverSendsRawPIIToModel(t *testing.T) {
+	dataset, err := evaluation.ParseDataset([]byte(`{
+  "scam_cases": [
+    {"text": "自称客服的人让我回拨13800138000，并把钱转到6222021234567890128。", "type": "冒充客服类", "predict": "冒充客服类", "predict_risk": "高", "score": 80,
+     "assessment": {"impersonation": true, "money_transfer_request": true}}
+  ]
+}`))
+	if err != nil {
+		t.Fatalf("parse dataset failed: %v", err)
+	}
+
+	transport := &recordingTransport{next: evaluation.NewStubLLM(dataset.Cases)}
+	client := openai.NewClientWithConfig(openai.Config{
+		APIKey:     "eval-stub",
+		BaseURL:    evaluation.StubBaseURL,
+		HTTPClient: &http.Client{Transport: transport},
+	})
+	agent := multi_agent.NewMainAgentWithClient(config.ModelConfig{Model: "eval-stub"}, config.RetryConfig{MaxRetries: 1}, "系统提示词", client)
+	report, err := evaluation.Run(context.Background(), dataset, evaluation.MainAgentPredictor{Label: "stub", Agent: agent}, evaluation.RunOptions{})
+	if err != nil || report.EvaluatedCases != 1 || report.FailedCases != 0 {
+		t.Fatalf("expected redacted case to be evaluated, got err=%v report=%+v", err, report)
+	}
+
+	if len(transport.bodies) == 0 {
+		t.Fatal("expected model requests recorded")
+	}
+	for _, body := range transport.bodies {
+		if strings.Contains(body, "13800138000") || strings.Contains(body, "6222021234567890128") {
+			t.Fatalf("expected model request without raw PII, got %s", body)
+		}
+	}
+	if !strings.Contains(transport.bodies[0], "[电话号码_1]") || !strings.Contains(transport.bodies[0], "[银行卡号_1]") {
+		t.Fatalf("expected placeholders in model input, got %s", transport.bodies[0])
+	}
+}
+
+func TestBuildConfusionMatrix_FixedOrderAndPerLabelMetrics(t *testing.T) {
+	matrix := evaluation.BuildConfusionMatrix(
+		[]string{"高", "高", "低", "中"},
+		[]string{"高", "中", "低", ""},
+		[]string{"低", "中", "高"},
+	)
+	if strings.Join(matrix.Labels, ",") != "低,中,高,(无)" {
+		t.Fatalf("unexpected label order: %v", matrix.Labels)
+	}
+	if matrix.Correct != 2 || matrix.Accuracy != 0.5 {
+		t.Fatalf("unexpected accuracy: %+v", matrix)
+	}
+	if matrix.Matrix[2][1] != 1 || matrix.Matrix[1][3] != 1 {
+		t.Fatalf("unexpected matrix cells: %v", matrix.Matrix)
+	}
+	for _, item := range matrix.PerLabel {
+		if item.Label == "高" && (item.Precision != 1 || item.Recall != 0.5) {
+			t.Fatalf("unexpected metrics for 高: %+v", item)
+		}
+	}
+}
+
+func TestRecordedPredictor_ReusesDatasetPredictions(t *testing.T) {
+	dataset, err := evaluation.ParseDataset([]byte(sampleDataset))
+	if err != nil {
+		t.Fatalf("parse dataset failed: %v", err)
+	}
+	report, err := evaluation.Run(context.Background(), dataset, evaluation.RecordedPredictor{}, evaluation.RunOptions{})
+	if err != nil {
+		t.Fatalf("run evaluation failed: %v", err)
+	}
+	if report.EvaluatedCases != 2 || report.Calibration.Samples != 2 || len(report.RuleHits) != 0 || report.ScamTypeSource != evaluation.ScamTypeSourceRecorded {
+		t.Fatalf("unexpected recorded report: %+v", report)
+	}
+}
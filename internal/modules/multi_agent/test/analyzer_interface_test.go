@@ -1,6 +1,7 @@
 package multi_agent_test
 
 import (
+	"context"
 	"errors"
 	"strings"
 	"testing"
@@ -77,3 +78,23 @@ func TestCommonAgentRetry_FailedAfterMaxAttempts(t *testing.T) {
 		t.Fatalf("unexpected error text: %v", err)
 	}
 }
+
+func TestCommonAgentRetryWithContext_StopsWhenCancelled(t *testing.T) {
+	agent := testCommonAgent()
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	err := agent.RetryWithContext(ctx, "do-something", func() error {
+		calls++
+		cancel()
+		return errors.New("temporary")
+	})
+	if err == nil {
+		t.Fatalf("expected cancellation error")
+	}
+	if calls != 1 {
+		t.Fatalf("expected retry to stop after cancellation, got %d calls", calls)
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
@@ -1,11 +1,19 @@
 package application_test
 
 import (
+	"context"
+	"encoding/base64"
+	"errors"
 	"strings"
 	"testing"
+	"time"
 
+	"antifraud/internal/modules/multi_agent/adapters/outbound/blobstore"
+	"antifraud/internal/modules/multi_agent/adapters/outbound/mediastore"
 	"antifraud/internal/modules/multi_agent/adapters/outbound/state"
 	"antifraud/internal/modules/multi_agent/application"
+	"antifraud/internal/modules/multi_agent/core"
+	"antifraud/internal/platform/database"
 )
 
 func TestNormalizeTaskPayload_TextAndImagesPassthrough(t *testing.T) {
@@ -14,7 +22,7 @@ func TestNormalizeTaskPayload_TextAndImagesPassthrough(t *testing.T) {
 		Images: []string{"data:image/png;base64,AAAA"},
 	}
 
-	got, err := application.NormalizeTaskPayload(input)
+	got, err := application.NormalizeTaskPayload(context.Background(), input)
 	if err != nil {
 		t.Fatalf("expected nil error, got %v", err)
 	}
@@ -31,7 +39,7 @@ func TestNormalizeTaskPayload_TextAndImagesPassthrough(t *testing.T) {
 }
 
 func TestNormalizeTaskPayload_InvalidVideoBase64(t *testing.T) {
-	_, err := application.NormalizeTaskPayload(state.TaskPayload{
+	_, err := application.NormalizeTaskPayload(context.Background(), state.TaskPayload{
 		Videos: []string{"%%%"},
 	})
 	if err == nil {
@@ -41,3 +49,105 @@ func TestNormalizeTaskPayload_InvalidVideoBase64(t *testing.T) {
 		t.Fatalf("unexpected error: %v", err)
 	}
 }
+
+type trimModalityAnalyzer struct{}
+
+func (trimModalityAnalyzer) Kind() string  { return "preprocess_test_url" }
+func (trimModalityAnalyzer) Label() string { return "URL" }
+func (trimModalityAnalyzer) Preprocess(ctx context.Context, input string) (string, error) {
+	return strings.ToLower(strings.TrimSpace(input)), nil
+}
+func (trimModalityAnalyzer) AnalyzeBatch(ctx context.Context, inputs []string) []string {
+	return inputs
+}
+
+func TestNormalizeTaskPayload_ExtensionModalityUsesRegisteredPreprocess(t *testing.T) {
+	if _, ok := multi_agent.LookupModalityAnalyzer("preprocess_test_url"); !ok {
+		if err := multi_agent.RegisterModalityAnalyzer(trimModalityAnalyzer{}); err != nil {
+			t.Fatalf("register modality failed: %v", err)
+		}
+	}
+
+	got, err := application.NormalizeTaskPayload(context.Background(), state.TaskPayload{
+		ExtraInputs: map[string][]string{"preprocess_test_url": {"  HTTPS://EXAMPLE.COM/Pay  ", " "}},
+	})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if items := got.ModalityInputs("preprocess_test_url"); len(items) != 1 || items[0] != "https://example.com/pay" {
+		t.Fatalf("unexpected normalized extension inputs: %#v", items)
+	}
+}
+
+func TestNormalizeTaskPayload_RejectsUnregisteredModality(t *testing.T) {
+	_, err := application.NormalizeTaskPayload(context.Background(), state.TaskPayload{
+		ExtraInputs: map[string][]string{"unregistered_kind": {"data"}},
+	})
+	if err == nil || !strings.Contains(err.Error(), "unregistered_kind") {
+		t.Fatalf("expected unsupported modality error, got %v", err)
+	}
+}
+
+func TestNormalizeTaskPayload_KeepsMediaReferencesUntilClaim(t *testing.T) {
+	setupTaskQueueDB(t)
+	if err := database.InitMainDBSchemas(); err != nil {
+		t.Fatalf("init main db schemas failed: %v", err)
+	}
+	store := mediastore.NewStore(mediastore.Options{Dir: t.TempDir()})
+	previous := mediastore.SetDefaultStore(store)
+	blobBackend, err := blobstore.NewLocalBackend(t.TempDir())
+	if err != nil {
+		t.Fatalf("create blob backend failed: %v", err)
+	}
+	previousBlobs := blobstore.SetDefaultStore(blobstore.NewStore(blobBackend, blobstore.Options{}))
+	t.Cleanup(func() {
+		blobstore.SetDefaultStore(previousBlobs)
+		mediastore.SetDefaultStore(previous)
+	})
+
+	image, err := store.Put("u-1", "shot.png", "", strings.NewReader("\x89PNG\r\n\x1a\nimage"))
+	if err != nil {
+		t.Fatalf("put image failed: %v", err)
+	}
+	video, err := store.Put("u-1", "clip.mov", "video/quicktime", strings.NewReader("video-bytes"))
+	if err != nil {
+		t.Fatalf("put video failed: %v", err)
+	}
+
+	got, err := application.NormalizeTaskPayload(context.Background(), state.TaskPayload{
+		Images: []string{image.BlobRef(), "data:image/png;base64,AAAA"},
+		Videos: []string{" " + video.BlobRef() + " "},
+	})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	// 引用在入队前原样保留，不读取内容。
+	if len(got.Images) != 2 || got.Images[0] != image.BlobRef() || got.Images[1] != "data:image/png;base64,AAAA" {
+		t.Fatalf("expected image reference kept, got %#v", got.Images)
+	}
+	if len(got.Videos) != 1 || got.Videos[0] != video.BlobRef() {
+		t.Fatalf("expected video reference kept, got %#v", got.Videos)
+	}
+
+	// worker 领取任务时才把引用还原为分析智能体消费的 data url。
+	task := state.CreateTask("u-1", got)
+	claimed, ok := state.ClaimNextPendingTask("worker-A", time.Minute, 0, 0)
+	if !ok || claimed.TaskID != task.TaskID {
+		t.Fatalf("expected task claimed, got %+v ok=%v", claimed, ok)
+	}
+	wantImage := "data:image/png;base64," + base64.StdEncoding.EncodeToString([]byte("\x89PNG\r\n\x1a\nimage"))
+	if len(claimed.Payload.Images) != 2 || claimed.Payload.Images[0] != wantImage {
+		t.Fatalf("unexpected hydrated images: %#v", claimed.Payload.Images)
+	}
+	wantVideo := "data:video/quicktime;base64," + base64.StdEncoding.EncodeToString([]byte("video-bytes"))
+	if len(claimed.Payload.Videos) != 1 || claimed.Payload.Videos[0] != wantVideo {
+		t.Fatalf("unexpected hydrated videos: %#v", claimed.Payload.Videos)
+	}
+
+	_, err = application.NormalizeTaskPayload(context.Background(), state.TaskPayload{
+		Videos: []string{blobstore.Ref(strings.Repeat("0", 64))},
+	})
+	if !errors.Is(err, blobstore.ErrBlobNotFound) || !strings.Contains(err.Error(), "视频 1 预处理失败") {
+		t.Fatalf("expected missing media error, got %v", err)
+	}
+}
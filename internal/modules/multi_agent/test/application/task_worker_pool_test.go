@@ -0,0 +1,382 @@
+package application_test
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"antifraud/internal/modules/multi_agent/adapters/outbound/state"
+	statemodel "antifraud/internal/modules/multi_agent/adapters/outbound/state/model"
+	"antifraud/internal/modules/multi_agent/application"
+	"antifraud/internal/platform/database"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type blockingAnalyzer struct {
+	mu      sync.Mutex
+	running map[string]string
+	// mappings 记录分析开始时读到的脱敏映射，用于确认映射先于任务可被领取。
+	mappings map[string]map[string]string
+	started  chan string
+	release  chan struct{}
+}
+
+func newBlockingAnalyzer() *blockingAnalyzer {
+	return &blockingAnalyzer{
+		running:  map[string]string{},
+		mappings: map[string]map[string]string{},
+		started:  make(chan string, 16),
+		release:  make(chan struct{}),
+	}
+}
+
+func (a *blockingAnalyzer) Analyze(ctx context.Context, userID string, taskID string, payload state.TaskPayload) (string, error) {
+	a.mu.Lock()
+	a.running[taskID] = userID
+	a.mappings[taskID] = state.GetTaskRedactionMapping(userID, taskID)
+	a.mu.Unlock()
+	a.started <- taskID
+
+	select {
+	case <-a.release:
+	case <-ctx.Done():
+	}
+
+	a.mu.Lock()
+	delete(a.running, taskID)
+	a.mu.Unlock()
+	return "report for " + taskID, nil
+}
+
+func (a *blockingAnalyzer) runningUsers() []string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	users := make([]string, 0, len(a.running))
+	for _, userID := range a.running {
+		users = append(users, userID)
+	}
+	return users
+}
+
+func setupTaskQueueDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	oldDB := database.DB
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "task_queue_test.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open sqlite failed: %v", err)
+	}
+	if err := db.AutoMigrate(&statemodel.PendingTaskEntity{}, &statemodel.HistoryCaseEntity{}, &statemodel.TaskProgressEventEntity{}, &statemodel.TaskRedactionMappingEntity{}); err != nil {
+		t.Fatalf("migrate state tables failed: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("get sql db failed: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	database.DB = db
+	t.Cleanup(func() {
+		database.DB = oldDB
+		_ = sqlDB.Close()
+	})
+	return db
+}
+
+func waitForStarted(t *testing.T, analyzer *blockingAnalyzer) string {
+	t.Helper()
+	select {
+	case taskID := <-analyzer.started:
+		return taskID
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for task to start")
+		return ""
+	}
+}
+
+func testQueueOptions(global int, perUser int) application.TaskQueueOptions {
+	return application.TaskQueueOptions{
+		GlobalConcurrency:  global,
+		PerUserConcurrency: perUser,
+		LeaseTTL:           time.Minute,
+		HeartbeatInterval:  20 * time.Second,
+		PollInterval:       20 * time.Millisecond,
+		MaxAttempts:        3,
+	}
+}
+
+func TestTaskWorkerPool_RecoversInterruptedTaskOnStart(t *testing.T) {
+	db := setupTaskQueueDB(t)
+
+	task := state.CreateTask("u-1", state.TaskPayload{Text: "疑似冒充客服退款"})
+	expired := time.Now().Add(-time.Minute)
+	if err := db.Model(&statemodel.PendingTaskEntity{}).
+		Where("task_id = ?", task.TaskID).
+		Updates(map[string]interface{}{
+			"status":           state.TaskStatusProcessing,
+			"lease_owner":      "worker-old-W01",
+			"lease_expires_at": expired,
+			"attempts":         1,
+		}).Error; err != nil {
+		t.Fatalf("simulate interrupted task failed: %v", err)
+	}
+
+	analyzer := newBlockingAnalyzer()
+	close(analyzer.release)
+	pool := application.NewTaskWorkerPool(nil, analyzer, testQueueOptions(1, 1))
+	if err := pool.Start(context.Background()); err != nil {
+		t.Fatalf("start pool failed: %v", err)
+	}
+	defer pool.Stop()
+
+	if got := waitForStarted(t, analyzer); got != task.TaskID {
+		t.Fatalf("expected recovered task %s, got %s", task.TaskID, got)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		detail, ok := state.GetTaskDetailByID("u-1", task.TaskID)
+		if ok && detail.Status == state.TaskStatusCompleted {
+			if detail.Report != "report for "+task.TaskID {
+				t.Fatalf("unexpected report: %q", detail.Report)
+			}
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatal("recovered task was not completed")
+}
+
+func TestTaskWorkerPool_FailsTaskAfterMaxAttempts(t *testing.T) {
+	db := setupTaskQueueDB(t)
+
+	task := state.CreateTask("u-1", state.TaskPayload{Text: "多次中断的任务"})
+	if err := db.Model(&statemodel.PendingTaskEntity{}).
+		Where("task_id = ?", task.TaskID).
+		Updates(map[string]interface{}{
+			"status":   state.TaskStatusProcessing,
+			"attempts": 3,
+		}).Error; err != nil {
+		t.Fatalf("simulate exhausted task failed: %v", err)
+	}
+
+	requeued, failed := state.RecoverExpiredTaskLeases(3)
+	if requeued != 0 || failed != 1 {
+		t.Fatalf("expected exhausted task to fail, got requeued=%d failed=%d", requeued, failed)
+	}
+	detail, ok := state.GetTaskDetailByID("u-1", task.TaskID)
+	if !ok || detail.Status != state.TaskStatusFailed {
+		t.Fatalf("expected failed history record, got ok=%v detail=%+v", ok, detail)
+	}
+}
+
+func TestMarkTaskFinalized_RequiresWorkerToHoldProcessingLease(t *testing.T) {
+	db := setupTaskQueueDB(t)
+
+	reclaimed := state.CreateTask("u-1", state.TaskPayload{Text: "租约被回收的任务"})
+	if claimed, ok := state.ClaimNextPendingTask("worker-A", time.Minute, 0, 0); !ok || claimed.TaskID != reclaimed.TaskID {
+		t.Fatalf("claim failed: ok=%v task=%+v", ok, claimed)
+	}
+	if err := db.Model(&statemodel.PendingTaskEntity{}).
+		Where("task_id = ?", reclaimed.TaskID).
+		Update("lease_owner", "worker-B").Error; err != nil {
+		t.Fatalf("simulate reclaim failed: %v", err)
+	}
+	if state.MarkTaskCompleted("u-1", reclaimed.TaskID, "worker-A", "stale report") || state.MarkTaskFailed("u-1", reclaimed.TaskID, "worker-A", "stale error") {
+		t.Fatal("expected stale worker finalize rejected")
+	}
+	if pending, ok := state.GetTask("u-1", reclaimed.TaskID); !ok || pending.Status != state.TaskStatusProcessing {
+		t.Fatalf("expected task kept for new owner, got ok=%v %+v", ok, pending)
+	}
+	if !state.MarkTaskCompleted("u-1", reclaimed.TaskID, "worker-B", "fresh report") {
+		t.Fatal("expected lease owner finalize accepted")
+	}
+	if detail, ok := state.GetTaskDetailByID("u-1", reclaimed.TaskID); !ok || detail.Report != "fresh report" {
+		t.Fatalf("expected owner report archived, got ok=%v %+v", ok, detail)
+	}
+
+	cancelled := state.CreateTask("u-1", state.TaskPayload{Text: "分析结束前被取消的任务"})
+	if claimed, ok := state.ClaimNextPendingTask("worker-A", time.Minute, 0, 0); !ok || claimed.TaskID != cancelled.TaskID {
+		t.Fatalf("claim failed: ok=%v task=%+v", ok, claimed)
+	}
+	if _, ok, err := state.CancelTask("u-1", cancelled.TaskID); err != nil || !ok {
+		t.Fatalf("cancel failed: ok=%v err=%v", ok, err)
+	}
+	if state.MarkTaskCompleted("u-1", cancelled.TaskID, "worker-A", "late report") {
+		t.Fatal("expected cancelled task not completed")
+	}
+	state.MarkTaskCancelled("u-1", cancelled.TaskID)
+	if detail, ok := state.GetTaskDetailByID("u-1", cancelled.TaskID); !ok || detail.Status != state.TaskStatusCancelled {
+		t.Fatalf("expected cancelled history record, got ok=%v %+v", ok, detail)
+	}
+}
+
+func TestTaskWorkerPool_RespectsPerUserConcurrency(t *testing.T) {
+	setupTaskQueueDB(t)
+
+	first := state.CreateTask("u-1", state.TaskPayload{Text: "任务一"})
+	time.Sleep(5 * time.Millisecond)
+	second := state.CreateTask("u-1", state.TaskPayload{Text: "任务二"})
+	time.Sleep(5 * time.Millisecond)
+	third := state.CreateTask("u-2", state.TaskPayload{Text: "任务三"})
+
+	analyzer := newBlockingAnalyzer()
+	pool := application.NewTaskWorkerPool(nil, analyzer, testQueueOptions(2, 1))
+	if err := pool.Start(context.Background()); err != nil {
+		t.Fatalf("start pool failed: %v", err)
+	}
+	defer pool.Stop()
+
+	started := map[string]bool{
+		waitForStarted(t, analyzer): true,
+		waitForStarted(t, analyzer): true,
+	}
+	if !started[first.TaskID] || !started[third.TaskID] || started[second.TaskID] {
+		t.Fatalf("expected first and third tasks to run concurrently, got %v", started)
+	}
+
+	users := analyzer.runningUsers()
+	if len(users) != 2 || users[0] == users[1] {
+		t.Fatalf("expected two distinct users running, got %v", users)
+	}
+
+	close(analyzer.release)
+	if got := waitForStarted(t, analyzer); got != second.TaskID {
+		t.Fatalf("expected second task after slot released, got %s", got)
+	}
+}
+
+func TestTaskService_CancelPendingTaskArchivesImmediately(t *testing.T) {
+	setupTaskQueueDB(t)
+
+	service := application.NewTaskService(nil, newBlockingAnalyzer())
+	task, err := service.EnqueueTask("u-1", state.TaskPayload{Text: "尚未开始的任务"})
+	if err != nil {
+		t.Fatalf("enqueue failed: %v", err)
+	}
+
+	cancelled, ok, err := service.CancelTask("u-1", task.TaskID)
+	if err != nil || !ok {
+		t.Fatalf("expected cancel success, got ok=%v err=%v", ok, err)
+	}
+	if cancelled.Status != state.TaskStatusCancelled {
+		t.Fatalf("expected cancelled status, got %q", cancelled.Status)
+	}
+
+	detail, exists := state.GetTaskDetailByID("u-1", task.TaskID)
+	if !exists || detail.Status != state.TaskStatusCancelled {
+		t.Fatalf("expected cancelled history record, got exists=%v detail=%+v", exists, detail)
+	}
+	if _, ok, _ := service.CancelTask("u-1", task.TaskID); ok {
+		t.Fatal("expected finished task to be no longer cancellable")
+	}
+}
+
+func TestTaskService_EnqueueRedactsTextAndSavesMapping(t *testing.T) {
+	setupTaskQueueDB(t)
+
+	service := application.NewTaskService(nil, newBlockingAnalyzer())
+	task, err := service.EnqueueTask("u-1", state.TaskPayload{Text: "对方自称客服，让我回拨13800138000"})
+	if err != nil {
+		t.Fatalf("enqueue failed: %v", err)
+	}
+	stored, exists := state.GetTask("u-1", task.TaskID)
+	if !exists || stored.Payload.Text != "对方自称客服，让我回拨[电话号码_1]" {
+		t.Fatalf("expected redacted text stored, got exists=%v %+v", exists, stored.Payload)
+	}
+	if mapping := state.GetTaskRedactionMapping("u-1", task.TaskID); mapping["[电话号码_1]"] != "13800138000" {
+		t.Fatalf("expected mapping saved, got %+v", mapping)
+	}
+	if restored := state.RestoreTaskPII(stored); restored.Payload.Text != "对方自称客服，让我回拨13800138000" {
+		t.Fatalf("expected restorable text, got %q", restored.Payload.Text)
+	}
+}
+
+func TestTaskService_EnqueueMakesMappingVisibleBeforeTaskIsClaimable(t *testing.T) {
+	setupTaskQueueDB(t)
+
+	analyzer := newBlockingAnalyzer()
+	service := application.NewTaskService(nil, analyzer)
+	if err := service.StartWorkers(context.Background(), testQueueOptions(1, 1)); err != nil {
+		t.Fatalf("start workers failed: %v", err)
+	}
+	defer service.StopWorkers()
+	defer close(analyzer.release)
+
+	for index := 0; index < 5; index++ {
+		task, err := service.EnqueueTask("u-1", state.TaskPayload{Text: "让我回拨13800138000"})
+		if err != nil {
+			t.Fatalf("enqueue failed: %v", err)
+		}
+		if got := waitForStarted(t, analyzer); got != task.TaskID {
+			t.Fatalf("expected task %s to start, got %s", task.TaskID, got)
+		}
+		analyzer.mu.Lock()
+		seen := analyzer.mappings[task.TaskID]
+		analyzer.mu.Unlock()
+		if seen["[电话号码_1]"] != "13800138000" {
+			t.Fatalf("expected worker to see enqueue mapping, got %+v", seen)
+		}
+		analyzer.release <- struct{}{}
+	}
+}
+
+func TestTaskService_CancelRunningTaskStopsAnalyzer(t *testing.T) {
+	setupTaskQueueDB(t)
+
+	analyzer := newBlockingAnalyzer()
+	service := application.NewTaskService(nil, analyzer)
+	if err := service.StartWorkers(context.Background(), testQueueOptions(1, 1)); err != nil {
+		t.Fatalf("start workers failed: %v", err)
+	}
+	defer service.StopWorkers()
+
+	task, err := service.EnqueueTask("u-1", state.TaskPayload{Text: "正在分析的任务"})
+	if err != nil {
+		t.Fatalf("enqueue failed: %v", err)
+	}
+	if got := waitForStarted(t, analyzer); got != task.TaskID {
+		t.Fatalf("expected task %s to start, got %s", task.TaskID, got)
+	}
+
+	if _, ok, err := service.CancelTask("u-1", task.TaskID); err != nil || !ok {
+		t.Fatalf("expected cancel success, got ok=%v err=%v", ok, err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		detail, exists := state.GetTaskDetailByID("u-1", task.TaskID)
+		if exists && detail.Status == state.TaskStatusCancelled && len(analyzer.runningUsers()) == 0 {
+			assertProgressStages(t, "u-1", task.TaskID, state.TaskProgressStageQueued, state.TaskProgressStageStarted)
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatal("running task was not cancelled")
+}
+
+func assertProgressStages(t *testing.T, userID string, taskID string, expected ...string) {
+	t.Helper()
+	events, err := state.ListTaskProgressEvents(userID, taskID, 0, 0)
+	if err != nil {
+		t.Fatalf("list progress events failed: %v", err)
+	}
+	stages := make([]string, 0, len(events))
+	for _, event := range events {
+		stages = append(stages, event.Stage)
+	}
+	if len(stages) != len(expected) {
+		t.Fatalf("unexpected progress stages: got=%v want=%v", stages, expected)
+	}
+	for index := range expected {
+		if stages[index] != expected[index] {
+			t.Fatalf("unexpected progress stages: got=%v want=%v", stages, expected)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package multi_agent_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"antifraud/internal/modules/multi_agent/adapters/outbound/state"
+	"antifraud/internal/modules/multi_agent/core"
+)
+
+type stubModalityAnalyzer struct {
+	kind string
+}
+
+func (a stubModalityAnalyzer) Kind() string {
+	return a.kind
+}
+
+func (a stubModalityAnalyzer) Label() string {
+	return strings.ToUpper(a.kind)
+}
+
+func (a stubModalityAnalyzer) Preprocess(ctx context.Context, input string) (string, error) {
+	return strings.TrimSpace(input), nil
+}
+
+func (a stubModalityAnalyzer) AnalyzeBatch(ctx context.Context, inputs []string) []string {
+	return append([]string{}, inputs...)
+}
+
+func TestRegisteredModalityAnalyzers_BuiltinOrder(t *testing.T) {
+	analyzers := multi_agent.RegisteredModalityAnalyzers()
+	if len(analyzers) < 3 {
+		t.Fatalf("expected builtin analyzers, got %d", len(analyzers))
+	}
+	want := []string{state.ModalityImage, state.ModalityVideo, state.ModalityAudio}
+	for index, kind := range want {
+		if analyzers[index].Kind() != kind {
+			t.Fatalf("unexpected builtin order at %d: got=%s want=%s", index, analyzers[index].Kind(), kind)
+		}
+	}
+}
+
+func TestRegisterModalityAnalyzer_RejectsInvalidKinds(t *testing.T) {
+	if err := multi_agent.RegisterModalityAnalyzer(stubModalityAnalyzer{kind: " "}); err == nil {
+		t.Fatal("expected empty kind to be rejected")
+	}
+	if err := multi_agent.RegisterModalityAnalyzer(stubModalityAnalyzer{kind: "text"}); err == nil {
+		t.Fatal("expected reserved text kind to be rejected")
+	}
+	if err := multi_agent.RegisterModalityAnalyzer(stubModalityAnalyzer{kind: state.ModalityImage}); err == nil {
+		t.Fatal("expected duplicate builtin kind to be rejected")
+	}
+}
+
+func TestRegisterModalityAnalyzer_LookupExtension(t *testing.T) {
+	if err := multi_agent.RegisterModalityAnalyzer(stubModalityAnalyzer{kind: "registry_test_qr"}); err != nil {
+		t.Fatalf("register extension failed: %v", err)
+	}
+	analyzer, ok := multi_agent.LookupModalityAnalyzer("registry_test_qr")
+	if !ok || analyzer.Label() != "REGISTRY_TEST_QR" {
+		t.Fatalf("expected registered extension, got ok=%v analyzer=%v", ok, analyzer)
+	}
+	if _, ok := multi_agent.LookupModalityAnalyzer("registry_test_missing"); ok {
+		t.Fatal("expected unknown kind lookup to fail")
+	}
+}
+
+func TestTaskPayloadModalitySlots(t *testing.T) {
+	var payload state.TaskPayload
+	payload.SetModalityInputs(state.ModalityImage, []string{"img"})
+	payload.SetModalityInputs("pdf", []string{"doc"})
+	payload.SetModalityInsights("pdf", []string{"pdf insight"})
+
+	if len(payload.Images) != 1 || payload.Images[0] != "img" {
+		t.Fatalf("expected builtin slot to map to Images, got %#v", payload.Images)
+	}
+	if got := payload.ModalityInputs("pdf"); len(got) != 1 || got[0] != "doc" {
+		t.Fatalf("unexpected extension inputs: %#v", got)
+	}
+
+	cloned := payload.Clone()
+	cloned.ExtraInsights["pdf"][0] = "mutated"
+	if payload.ModalityInsights("pdf")[0] != "pdf insight" {
+		t.Fatal("expected Clone to deep copy extension insights")
+	}
+
+	payload.SetModalityInputs("pdf", nil)
+	if payload.ExtraInputs != nil {
+		t.Fatalf("expected empty extension inputs to be dropped, got %#v", payload.ExtraInputs)
+	}
+}
@@ -0,0 +1,67 @@
+// Package testsupport 提供 multi_agent 各测试套件共用的测试夹具。
+package testsupport
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"antifraud/internal/platform/database"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// statusPollTimeout 与 statusPollInterval 控制 WaitForStatus 的轮询节奏。
+const (
+	statusPollTimeout  = 5 * time.Second
+	statusPollInterval = 10 * time.Millisecond
+)
+
+// SetupMainDB 在临时目录创建 SQLite 主业务库并初始化全部表，替换 database.DB，测试结束时恢复原值并关闭连接。
+// 连接数限制为 1，避免后台任务与测试断言并发写入时出现 database is locked。
+func SetupMainDB(t testing.TB) *gorm.DB {
+	t.Helper()
+
+	oldDB := database.DB
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "main_test.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open sqlite failed: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("get sql db failed: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	database.DB = db
+	t.Cleanup(func() {
+		database.DB = oldDB
+		_ = sqlDB.Close()
+	})
+	if err := database.InitMainDBSchemas(); err != nil {
+		t.Fatalf("init main db schemas failed: %v", err)
+	}
+	return db
+}
+
+// WaitForStatus 轮询 get 直到 status 返回 want 并返回该结果，超时后让测试失败。
+func WaitForStatus[J any](t testing.TB, get func() (J, error), status func(J) string, want string) J {
+	t.Helper()
+	deadline := time.Now().Add(statusPollTimeout)
+	for time.Now().Before(deadline) {
+		job, err := get()
+		if err != nil {
+			t.Fatalf("get job failed: %v", err)
+		}
+		if status(job) == want {
+			return job
+		}
+		time.Sleep(statusPollInterval)
+	}
+	job, _ := get()
+	t.Fatalf("job did not reach status %s, last=%+v", want, job)
+	return job
+}
@@ -115,9 +115,39 @@ func TestBuildUserRiskInfoIncludesRatios(t *testing.T) {
 		t.Fatalf("update recent tags failed: %v", err)
 	}
 
-	state.AddCaseHistory(fmt.Sprintf("%d", user.ID), "TASK-1", "高风险案件", "summary", "其他诈骗类", "高", 82, `{"score":82}`, state.TaskPayload{}, "report")
-	state.AddCaseHistory(fmt.Sprintf("%d", user.ID), "TASK-2", "中风险案件", "summary", "其他诈骗类", "中", 56, `{"score":56}`, state.TaskPayload{}, "report")
-	state.AddCaseHistory(fmt.Sprintf("%d", user.ID), "TASK-3", "低风险案件", "summary", "其他诈骗类", "低", 24, `{"score":24}`, state.TaskPayload{}, "report")
+	state.AddCaseHistory(state.CaseHistoryRecord{
+		RecordID:    "TASK-1",
+		UserID:      fmt.Sprintf("%d", user.ID),
+		Title:       "高风险案件",
+		CaseSummary: "summary",
+		ScamType:    "其他诈骗类",
+		RiskLevel:   "高",
+		RiskScore:   82,
+		RiskSummary: `{"score":82}`,
+		Report:      "report",
+	})
+	state.AddCaseHistory(state.CaseHistoryRecord{
+		RecordID:    "TASK-2",
+		UserID:      fmt.Sprintf("%d", user.ID),
+		Title:       "中风险案件",
+		CaseSummary: "summary",
+		ScamType:    "其他诈骗类",
+		RiskLevel:   "中",
+		RiskScore:   56,
+		RiskSummary: `{"score":56}`,
+		Report:      "report",
+	})
+	state.AddCaseHistory(state.CaseHistoryRecord{
+		RecordID:    "TASK-3",
+		UserID:      fmt.Sprintf("%d", user.ID),
+		Title:       "低风险案件",
+		CaseSummary: "summary",
+		ScamType:    "其他诈骗类",
+		RiskLevel:   "低",
+		RiskScore:   24,
+		RiskSummary: `{"score":24}`,
+		Report:      "report",
+	})
 
 	info, err := userprofile.BuildUserRiskInfo(fmt.Sprintf("%d", user.ID), "day")
 	if err != nil {
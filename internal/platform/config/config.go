@@ -24,6 +24,8 @@ type EmbeddingConfig struct {
 	Model   string `json:"model"`
 	APIKey  string `json:"api_key"`
 	BaseURL string `json:"base_url"`
+	// BatchSize 为单次 embeddings 请求携带的最大输入条数，<=0 时取默认值 10。
+	BatchSize int `json:"batch_size"`
 }
 
 // ChatConfig 定义聊天系统模型配置。
@@ -41,6 +43,45 @@ type TavilyConfig struct {
 	IncludeAnswer string `json:"include_answer"`
 	SearchDepth   string `json:"search_depth"`
 	TimeoutMS     int    `json:"timeout_ms"`
+	// RateLimitPerMinute 为该 provider 每分钟最多发起的请求数，<=0 时取默认值 60。
+	RateLimitPerMinute int `json:"rate_limit_per_minute"`
+}
+
+// SearXNGConfig 定义自建 SearXNG 元搜索实例配置。
+type SearXNGConfig struct {
+	BaseURL            string `json:"base_url"`
+	Engines            string `json:"engines"`
+	Language           string `json:"language"`
+	TimeoutMS          int    `json:"timeout_ms"`
+	RateLimitPerMinute int    `json:"rate_limit_per_minute"`
+}
+
+// BingSearchConfig 定义 Bing Web Search v7 兼容接口配置。
+type BingSearchConfig struct {
+	APIKey             string `json:"api_key"`
+	BaseURL            string `json:"base_url"`
+	Market             string `json:"market"`
+	TimeoutMS          int    `json:"timeout_ms"`
+	RateLimitPerMinute int    `json:"rate_limit_per_minute"`
+}
+
+// WebSearchFixtureConfig 定义本地固定结果 provider，用于测试与离线演示。
+type WebSearchFixtureConfig struct {
+	Path string `json:"path"`
+}
+
+// WebSearchConfig 定义联网搜索 provider 选择与通用结果策略；Tavily 沿用顶层 tavily 配置。
+type WebSearchConfig struct {
+	Provider string `json:"provider"`
+	// AllowDomains 非空时只保留这些域名（含子域名）的结果，DenyDomains 中的域名始终剔除。
+	AllowDomains []string `json:"allow_domains"`
+	DenyDomains  []string `json:"deny_domains"`
+	// CacheTTLSeconds 为相同检索的结果缓存时长，0 取默认值 600，负数关闭缓存。
+	CacheTTLSeconds int                    `json:"cache_ttl_seconds"`
+	CacheMaxEntries int                    `json:"cache_max_entries"`
+	SearXNG         SearXNGConfig          `json:"searxng"`
+	Bing            BingSearchConfig       `json:"bing"`
+	Fixture         WebSearchFixtureConfig `json:"fixture"`
 }
 
 // RedisConfig 定义统一 Redis 连接配置。
@@ -68,6 +109,82 @@ type AlertWSConfig struct {
 	RecentWindowMinutes int `json:"recent_window_minutes"`
 }
 
+// TaskQueueConfig 定义多模态任务持久化队列与工作池配置。
+type TaskQueueConfig struct {
+	GlobalConcurrency  int `json:"global_concurrency"`
+	PerUserConcurrency int `json:"per_user_concurrency"`
+	LeaseSeconds       int `json:"lease_seconds"`
+	HeartbeatSeconds   int `json:"heartbeat_seconds"`
+	PollIntervalMS     int `json:"poll_interval_ms"`
+	MaxAttempts        int `json:"max_attempts"`
+}
+
+// MediaStoreConfig 定义多模态媒体上传的本地存储目录、单文件大小上限、分片大小与保留时长。
+type MediaStoreConfig struct {
+	Dir               string `json:"dir"`
+	MaxFileMB         int    `json:"max_file_mb"`
+	ChunkSizeKB       int    `json:"chunk_size_kb"`
+	SessionTTLMinutes int    `json:"session_ttl_minutes"`
+	RetentionHours    int    `json:"retention_hours"`
+}
+
+// BlobStoreConfig 定义内容寻址媒体 blob 存储的后端与本地目录，任务与历史记录只保存 blob 引用。
+type BlobStoreConfig struct {
+	Backend string `json:"backend"`
+	Dir     string `json:"dir"`
+	// OrphanGraceMinutes 为引用计数归零的 blob 被清理前的保留时长，<=0 时取默认值 60。
+	OrphanGraceMinutes int `json:"orphan_grace_minutes"`
+}
+
+// DataRetentionConfig 定义多模态历史数据的保留策略与后台清理周期。
+// 天数不大于 0 时该项永久保留；各项默认均为 0，需显式配置正数天数才会清理到期数据。
+type DataRetentionConfig struct {
+	// MediaDays 为历史案件原始媒体的保留天数，到期后仅删除媒体，文本、解读与报告保留。
+	MediaDays int `json:"media_days"`
+	// LowRiskRecordDays/MediumRiskRecordDays/HighRiskRecordDays 为各风险等级历史记录整体删除前的保留天数。
+	LowRiskRecordDays    int `json:"low_risk_record_days"`
+	MediumRiskRecordDays int `json:"medium_risk_record_days"`
+	HighRiskRecordDays   int `json:"high_risk_record_days"`
+	// PurgeIntervalMinutes 为后台清理周期，为负数时不启动后台清理。
+	PurgeIntervalMinutes int `json:"purge_interval_minutes"`
+	// ExportTTLMinutes 为“导出后清除”流程中导出凭证的有效期。
+	ExportTTLMinutes int `json:"export_ttl_minutes"`
+}
+
+// LLMCassetteConfig 定义模型请求录制回放配置，用于 CI 与离线环境确定性运行。
+type LLMCassetteConfig struct {
+	Mode string `json:"mode"`
+	Dir  string `json:"dir"`
+}
+
+// RiskRulesConfig 定义外部风险评分规则文件；path 为空时使用内置默认规则。
+type RiskRulesConfig struct {
+	Path string `json:"path"`
+}
+
+const (
+	LLMCassetteModeOff    = "off"
+	LLMCassetteModeRecord = "record"
+	LLMCassetteModeReplay = "replay"
+	LLMCassetteModeAuto   = "auto"
+)
+
+// BlobStoreBackendLocal 是内置的本地文件系统 blob 后端。
+const BlobStoreBackendLocal = "local"
+
+const (
+	WebSearchProviderTavily  = "tavily"
+	WebSearchProviderSearXNG = "searxng"
+	WebSearchProviderBing    = "bing"
+	WebSearchProviderFixture = "fixture"
+)
+
+// defaultWebSearchRateLimitPerMinute 是各联网搜索 provider 未配置限流时的每分钟请求上限。
+const defaultWebSearchRateLimitPerMinute = 60
+
+// defaultEmbeddingBatchSize 兼顾主流 embeddings 接口的单次输入上限（如 DashScope 为 10）。
+const defaultEmbeddingBatchSize = 10
+
 // AgentModelConfig 按智能体拆分模型与调用参数，便于后续扩展新 provider/model。
 type AgentModelConfig struct {
 	Main           ModelConfig `json:"main"`
@@ -93,17 +210,24 @@ type PromptConfig struct {
 
 // Config 是项目总配置对象。
 type Config struct {
-	Agents        AgentModelConfig `json:"agents"`
-	Embedding     EmbeddingConfig  `json:"embedding"`
-	Chat          ChatConfig       `json:"chat"`
-	AdminChat     ChatConfig       `json:"admin_chat"`
-	Tavily        TavilyConfig     `json:"tavily"`
-	Redis         RedisConfig      `json:"redis"`
-	MediaTools    MediaToolsConfig `json:"media_tools"`
-	Prompts       PromptConfig     `json:"prompts"`
-	Retry         RetryConfig      `json:"retry"`
-	AlertWS       AlertWSConfig    `json:"alert_ws"`
-	FamilyAlertWS AlertWSConfig    `json:"family_alert_ws"`
+	Agents        AgentModelConfig    `json:"agents"`
+	Embedding     EmbeddingConfig     `json:"embedding"`
+	Chat          ChatConfig          `json:"chat"`
+	AdminChat     ChatConfig          `json:"admin_chat"`
+	Tavily        TavilyConfig        `json:"tavily"`
+	WebSearch     WebSearchConfig     `json:"web_search"`
+	Redis         RedisConfig         `json:"redis"`
+	MediaTools    MediaToolsConfig    `json:"media_tools"`
+	Prompts       PromptConfig        `json:"prompts"`
+	Retry         RetryConfig         `json:"retry"`
+	AlertWS       AlertWSConfig       `json:"alert_ws"`
+	FamilyAlertWS AlertWSConfig       `json:"family_alert_ws"`
+	TaskQueue     TaskQueueConfig     `json:"task_queue"`
+	MediaStore    MediaStoreConfig    `json:"media_store"`
+	BlobStore     BlobStoreConfig     `json:"blob_store"`
+	DataRetention DataRetentionConfig `json:"data_retention"`
+	LLMCassette   LLMCassetteConfig   `json:"llm_cassette"`
+	RiskRules     RiskRulesConfig     `json:"risk_rules"`
 }
 
 var (
@@ -181,8 +305,14 @@ func (c *Config) applyEnvOverrides() {
 	c.Chat.APIKey = firstNonEmptyEnv("CHAT_API_KEY", c.Chat.APIKey)
 	c.AdminChat.APIKey = firstNonEmptyEnv("ADMIN_CHAT_API_KEY", c.AdminChat.APIKey)
 	c.Tavily.APIKey = firstNonEmptyEnv("TAVILY_API_KEY", c.Tavily.APIKey)
+	c.WebSearch.Provider = firstNonEmptyEnv("WEB_SEARCH_PROVIDER", c.WebSearch.Provider)
+	c.WebSearch.SearXNG.BaseURL = firstNonEmptyEnv("SEARXNG_BASE_URL", c.WebSearch.SearXNG.BaseURL)
+	c.WebSearch.Bing.APIKey = firstNonEmptyEnv("BING_SEARCH_API_KEY", c.WebSearch.Bing.APIKey)
 	c.MediaTools.FFmpegPath = firstNonEmptyEnv("FFMPEG_PATH", c.MediaTools.FFmpegPath)
 	c.MediaTools.FFprobePath = firstNonEmptyEnv("FFPROBE_PATH", c.MediaTools.FFprobePath)
+	c.LLMCassette.Mode = firstNonEmptyEnv("LLM_CASSETTE_MODE", c.LLMCassette.Mode)
+	c.LLMCassette.Dir = firstNonEmptyEnv("LLM_CASSETTE_DIR", c.LLMCassette.Dir)
+	c.RiskRules.Path = firstNonEmptyEnv("RISK_RULES_PATH", c.RiskRules.Path)
 }
 
 func firstNonEmptyEnv(envName string, fallback string) string {
@@ -209,6 +339,7 @@ func (c *Config) normalize() {
 	c.Chat = normalizeChatFromModel(c.Chat, c.Agents.Main)
 	c.AdminChat = normalizeChatFromChat(c.AdminChat, c.Chat)
 	c.Tavily = normalizeTavily(c.Tavily)
+	c.WebSearch = normalizeWebSearch(c.WebSearch)
 	c.Redis = normalizeRedis(c.Redis)
 	c.MediaTools = normalizeMediaTools(c.MediaTools)
 	c.Prompts.Main = strings.TrimSpace(c.Prompts.Main)
@@ -223,6 +354,11 @@ func (c *Config) normalize() {
 	}
 	c.AlertWS = normalizeAlertWS(c.AlertWS)
 	c.FamilyAlertWS = normalizeAlertWS(c.FamilyAlertWS)
+	c.TaskQueue = normalizeTaskQueue(c.TaskQueue)
+	c.MediaStore = normalizeMediaStore(c.MediaStore)
+	c.BlobStore = normalizeBlobStore(c.BlobStore)
+	c.DataRetention = normalizeDataRetention(c.DataRetention)
+	c.LLMCassette = normalizeLLMCassette(c.LLMCassette)
 }
 
 // normalizeModel 处理单个模型配置的字符串规范化。
@@ -237,6 +373,9 @@ func normalizeEmbedding(embeddingCfg EmbeddingConfig) EmbeddingConfig {
 	embeddingCfg.APIKey = strings.TrimSpace(embeddingCfg.APIKey)
 	embeddingCfg.BaseURL = strings.TrimSpace(embeddingCfg.BaseURL)
 	embeddingCfg.Model = strings.TrimSpace(embeddingCfg.Model)
+	if embeddingCfg.BatchSize <= 0 {
+		embeddingCfg.BatchSize = defaultEmbeddingBatchSize
+	}
 	return embeddingCfg
 }
 
@@ -296,9 +435,80 @@ func normalizeTavily(tavilyCfg TavilyConfig) TavilyConfig {
 	if tavilyCfg.TimeoutMS <= 0 {
 		tavilyCfg.TimeoutMS = 15000
 	}
+	if tavilyCfg.RateLimitPerMinute <= 0 {
+		tavilyCfg.RateLimitPerMinute = defaultWebSearchRateLimitPerMinute
+	}
 	return tavilyCfg
 }
 
+// normalizeWebSearch 补齐 provider 与缓存默认值，并把域名名单统一为小写主机名。
+func normalizeWebSearch(searchCfg WebSearchConfig) WebSearchConfig {
+	searchCfg.Provider = strings.ToLower(strings.TrimSpace(searchCfg.Provider))
+	if searchCfg.Provider == "" {
+		searchCfg.Provider = WebSearchProviderTavily
+	}
+	searchCfg.AllowDomains = normalizeDomainList(searchCfg.AllowDomains)
+	searchCfg.DenyDomains = normalizeDomainList(searchCfg.DenyDomains)
+	if searchCfg.CacheTTLSeconds == 0 {
+		searchCfg.CacheTTLSeconds = 600
+	}
+	if searchCfg.CacheMaxEntries <= 0 {
+		searchCfg.CacheMaxEntries = 256
+	}
+
+	searchCfg.SearXNG.BaseURL = strings.TrimRight(strings.TrimSpace(searchCfg.SearXNG.BaseURL), "/")
+	searchCfg.SearXNG.Engines = strings.TrimSpace(searchCfg.SearXNG.Engines)
+	searchCfg.SearXNG.Language = strings.TrimSpace(searchCfg.SearXNG.Language)
+	if searchCfg.SearXNG.Language == "" {
+		searchCfg.SearXNG.Language = "zh-CN"
+	}
+	if searchCfg.SearXNG.TimeoutMS <= 0 {
+		searchCfg.SearXNG.TimeoutMS = 15000
+	}
+	if searchCfg.SearXNG.RateLimitPerMinute <= 0 {
+		searchCfg.SearXNG.RateLimitPerMinute = defaultWebSearchRateLimitPerMinute
+	}
+
+	searchCfg.Bing.APIKey = strings.TrimSpace(searchCfg.Bing.APIKey)
+	searchCfg.Bing.BaseURL = strings.TrimRight(strings.TrimSpace(searchCfg.Bing.BaseURL), "/")
+	if searchCfg.Bing.BaseURL == "" {
+		searchCfg.Bing.BaseURL = "https://api.bing.microsoft.com/v7.0"
+	}
+	searchCfg.Bing.Market = strings.TrimSpace(searchCfg.Bing.Market)
+	if searchCfg.Bing.Market == "" {
+		searchCfg.Bing.Market = "zh-CN"
+	}
+	if searchCfg.Bing.TimeoutMS <= 0 {
+		searchCfg.Bing.TimeoutMS = 15000
+	}
+	if searchCfg.Bing.RateLimitPerMinute <= 0 {
+		searchCfg.Bing.RateLimitPerMinute = defaultWebSearchRateLimitPerMinute
+	}
+
+	searchCfg.Fixture.Path = strings.TrimSpace(searchCfg.Fixture.Path)
+	return searchCfg
+}
+
+func normalizeDomainList(domains []string) []string {
+	normalized := make([]string, 0, len(domains))
+	seen := map[string]struct{}{}
+	for _, domain := range domains {
+		value := strings.ToLower(strings.TrimSpace(domain))
+		value = strings.TrimPrefix(strings.TrimPrefix(value, "https://"), "http://")
+		value = strings.TrimPrefix(strings.TrimSuffix(value, "/"), "*.")
+		value = strings.TrimPrefix(value, ".")
+		if value == "" {
+			continue
+		}
+		if _, ok := seen[value]; ok {
+			continue
+		}
+		seen[value] = struct{}{}
+		normalized = append(normalized, value)
+	}
+	return normalized
+}
+
 func normalizeRedis(redisCfg RedisConfig) RedisConfig {
 	redisCfg.Addr = strings.TrimSpace(redisCfg.Addr)
 	redisCfg.Password = strings.TrimSpace(redisCfg.Password)
@@ -333,6 +543,104 @@ func normalizeAlertWS(alertCfg AlertWSConfig) AlertWSConfig {
 	return alertCfg
 }
 
+// normalizeTaskQueue 为任务队列补齐默认值，并保证心跳间隔小于租约时长。
+func normalizeTaskQueue(queueCfg TaskQueueConfig) TaskQueueConfig {
+	if queueCfg.GlobalConcurrency <= 0 {
+		queueCfg.GlobalConcurrency = 4
+	}
+	if queueCfg.PerUserConcurrency <= 0 {
+		queueCfg.PerUserConcurrency = 1
+	}
+	if queueCfg.PerUserConcurrency > queueCfg.GlobalConcurrency {
+		queueCfg.PerUserConcurrency = queueCfg.GlobalConcurrency
+	}
+	if queueCfg.LeaseSeconds <= 0 {
+		queueCfg.LeaseSeconds = 90
+	}
+	if queueCfg.HeartbeatSeconds <= 0 || queueCfg.HeartbeatSeconds >= queueCfg.LeaseSeconds {
+		queueCfg.HeartbeatSeconds = queueCfg.LeaseSeconds / 3
+		if queueCfg.HeartbeatSeconds <= 0 {
+			queueCfg.HeartbeatSeconds = 1
+		}
+	}
+	if queueCfg.PollIntervalMS <= 0 {
+		queueCfg.PollIntervalMS = 2000
+	}
+	if queueCfg.MaxAttempts <= 0 {
+		queueCfg.MaxAttempts = 3
+	}
+	return queueCfg
+}
+
+// normalizeMediaStore 为媒体存储补齐默认目录与上限，分片不超过单文件上限。
+func normalizeMediaStore(storeCfg MediaStoreConfig) MediaStoreConfig {
+	storeCfg.Dir = strings.TrimSpace(storeCfg.Dir)
+	if storeCfg.Dir == "" {
+		storeCfg.Dir = "DB/media"
+	}
+	if storeCfg.MaxFileMB <= 0 {
+		storeCfg.MaxFileMB = 200
+	}
+	if storeCfg.ChunkSizeKB <= 0 {
+		storeCfg.ChunkSizeKB = 4096
+	}
+	if storeCfg.ChunkSizeKB > storeCfg.MaxFileMB*1024 {
+		storeCfg.ChunkSizeKB = storeCfg.MaxFileMB * 1024
+	}
+	if storeCfg.SessionTTLMinutes <= 0 {
+		storeCfg.SessionTTLMinutes = 24 * 60
+	}
+	if storeCfg.RetentionHours <= 0 {
+		storeCfg.RetentionHours = 72
+	}
+	return storeCfg
+}
+
+// normalizeBlobStore 统一后端名大小写并补齐默认后端、目录与孤儿 blob 保留时长。
+func normalizeBlobStore(blobCfg BlobStoreConfig) BlobStoreConfig {
+	blobCfg.Backend = strings.ToLower(strings.TrimSpace(blobCfg.Backend))
+	if blobCfg.Backend == "" {
+		blobCfg.Backend = BlobStoreBackendLocal
+	}
+	blobCfg.Dir = strings.TrimSpace(blobCfg.Dir)
+	if blobCfg.Dir == "" {
+		blobCfg.Dir = "DB/blobs"
+	}
+	if blobCfg.OrphanGraceMinutes <= 0 {
+		blobCfg.OrphanGraceMinutes = 60
+	}
+	return blobCfg
+}
+
+// normalizeDataRetention 为未配置的清理周期与导出凭证有效期补齐默认值；保留天数不补默认值，未配置即永久保留。
+func normalizeDataRetention(retentionCfg DataRetentionConfig) DataRetentionConfig {
+	if retentionCfg.PurgeIntervalMinutes == 0 {
+		retentionCfg.PurgeIntervalMinutes = 60
+	}
+	if retentionCfg.ExportTTLMinutes <= 0 {
+		retentionCfg.ExportTTLMinutes = 30
+	}
+	return retentionCfg
+}
+
+// normalizeLLMCassette 统一模式大小写并补齐默认目录，未配置时关闭录制回放。
+func normalizeLLMCassette(cassetteCfg LLMCassetteConfig) LLMCassetteConfig {
+	cassetteCfg.Mode = strings.ToLower(strings.TrimSpace(cassetteCfg.Mode))
+	if cassetteCfg.Mode == "" {
+		cassetteCfg.Mode = LLMCassetteModeOff
+	}
+	cassetteCfg.Dir = strings.TrimSpace(cassetteCfg.Dir)
+	if cassetteCfg.Dir == "" {
+		cassetteCfg.Dir = "testdata/llm_cassettes"
+	}
+	return cassetteCfg
+}
+
+// ReplayOnly 表示模型请求只从 cassette 回放，不会访问真实模型服务。
+func (c LLMCassetteConfig) ReplayOnly() bool {
+	return c.Mode == LLMCassetteModeReplay
+}
+
 // validate 校验整体配置完整性。
 func (c Config) validate() error {
 	if c.Retry.MaxRetries <= 0 {
@@ -341,38 +649,43 @@ func (c Config) validate() error {
 	if c.Retry.RetryDelayMS <= 0 {
 		return fmt.Errorf("invalid retry.retry_delay_ms: must be > 0")
 	}
+	if err := validateLLMCassette("llm_cassette", c.LLMCassette); err != nil {
+		return err
+	}
+	// 纯回放模式不会访问模型服务，允许在无密钥的离线环境启动。
+	requireAPIKey := !c.LLMCassette.ReplayOnly()
 
-	if err := validateModel("agents.main", c.Agents.Main); err != nil {
+	if err := validateModel("agents.main", c.Agents.Main, requireAPIKey); err != nil {
 		return err
 	}
-	if err := validateModel("agents.image", c.Agents.Image); err != nil {
+	if err := validateModel("agents.image", c.Agents.Image, requireAPIKey); err != nil {
 		return err
 	}
-	if err := validateModel("agents.image_quick", c.Agents.ImageQuick); err != nil {
+	if err := validateModel("agents.image_quick", c.Agents.ImageQuick, requireAPIKey); err != nil {
 		return err
 	}
-	if err := validateModel("agents.video", c.Agents.Video); err != nil {
+	if err := validateModel("agents.video", c.Agents.Video, requireAPIKey); err != nil {
 		return err
 	}
-	if err := validateModel("agents.audio", c.Agents.Audio); err != nil {
+	if err := validateModel("agents.audio", c.Agents.Audio, requireAPIKey); err != nil {
 		return err
 	}
-	if err := validateModel("agents.asr", c.Agents.ASR); err != nil {
+	if err := validateModel("agents.asr", c.Agents.ASR, requireAPIKey); err != nil {
 		return err
 	}
-	if err := validateModel("agents.case_collection", c.Agents.CaseCollection); err != nil {
+	if err := validateModel("agents.case_collection", c.Agents.CaseCollection, requireAPIKey); err != nil {
 		return err
 	}
-	if err := validateModel("agents.simulation_quiz", c.Agents.SimulationQuiz); err != nil {
+	if err := validateModel("agents.simulation_quiz", c.Agents.SimulationQuiz, requireAPIKey); err != nil {
 		return err
 	}
-	if err := validateEmbedding("embedding", c.Embedding); err != nil {
+	if err := validateEmbedding("embedding", c.Embedding, requireAPIKey); err != nil {
 		return err
 	}
-	if err := validateChat("chat", c.Chat); err != nil {
+	if err := validateChat("chat", c.Chat, requireAPIKey); err != nil {
 		return err
 	}
-	if err := validateChat("admin_chat", c.AdminChat); err != nil {
+	if err := validateChat("admin_chat", c.AdminChat, requireAPIKey); err != nil {
 		return err
 	}
 	if err := validateTavily("tavily", c.Tavily); err != nil {
@@ -406,11 +719,11 @@ func (c Config) validate() error {
 }
 
 // validateModel 校验单个模型配置字段是否合法。
-func validateModel(name string, modelCfg ModelConfig) error {
+func validateModel(name string, modelCfg ModelConfig, requireAPIKey bool) error {
 	if modelCfg.Model == "" {
 		return fmt.Errorf("%s.model is required", name)
 	}
-	if modelCfg.APIKey == "" {
+	if requireAPIKey && modelCfg.APIKey == "" {
 		return fmt.Errorf("%s.api_key is required", name)
 	}
 	if modelCfg.BaseURL == "" {
@@ -428,11 +741,11 @@ func validateModel(name string, modelCfg ModelConfig) error {
 	return nil
 }
 
-func validateEmbedding(name string, embeddingCfg EmbeddingConfig) error {
+func validateEmbedding(name string, embeddingCfg EmbeddingConfig, requireAPIKey bool) error {
 	if embeddingCfg.Model == "" {
 		return fmt.Errorf("%s.model is required", name)
 	}
-	if embeddingCfg.APIKey == "" {
+	if requireAPIKey && embeddingCfg.APIKey == "" {
 		return fmt.Errorf("%s.api_key is required", name)
 	}
 	if embeddingCfg.BaseURL == "" {
@@ -441,14 +754,14 @@ func validateEmbedding(name string, embeddingCfg EmbeddingConfig) error {
 	return nil
 }
 
-func validateChat(name string, chatCfg ChatConfig) error {
+func validateChat(name string, chatCfg ChatConfig, requireAPIKey bool) error {
 	if chatCfg.Prompt == "" {
 		return fmt.Errorf("%s.prompt is required", name)
 	}
 	if chatCfg.Model == "" {
 		return fmt.Errorf("%s.model is required", name)
 	}
-	if chatCfg.APIKey == "" {
+	if requireAPIKey && chatCfg.APIKey == "" {
 		return fmt.Errorf("%s.api_key is required", name)
 	}
 	if chatCfg.BaseURL == "" {
@@ -457,6 +770,15 @@ func validateChat(name string, chatCfg ChatConfig) error {
 	return nil
 }
 
+func validateLLMCassette(name string, cassetteCfg LLMCassetteConfig) error {
+	switch cassetteCfg.Mode {
+	case LLMCassetteModeOff, LLMCassetteModeRecord, LLMCassetteModeReplay, LLMCassetteModeAuto:
+		return nil
+	default:
+		return fmt.Errorf("%s.mode must be one of off/record/replay/auto", name)
+	}
+}
+
 func validateTavily(name string, tavilyCfg TavilyConfig) error {
 	if tavilyCfg.APIKey == "" &&
 		tavilyCfg.BaseURL == "https://api.tavily.com" &&
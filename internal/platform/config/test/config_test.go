@@ -374,3 +374,184 @@ func TestConfigEnvOverridesMediaTools(t *testing.T) {
 		t.Fatalf("expected env override for media_tools.ffprobe_path, got %q", loaded.MediaTools.FFprobePath)
 	}
 }
+
+func TestConfigNormalizeTaskQueueDefaults(t *testing.T) {
+	cfg := validConfig()
+	file := writeConfigFile(t, cfg)
+
+	loaded, err := appcfg.LoadConfig(file)
+	if err != nil {
+		t.Fatalf("load config failed: %v", err)
+	}
+
+	queueCfg := loaded.TaskQueue
+	if queueCfg.GlobalConcurrency != 4 || queueCfg.PerUserConcurrency != 1 {
+		t.Fatalf("unexpected task_queue concurrency defaults: %+v", queueCfg)
+	}
+	if queueCfg.LeaseSeconds != 90 || queueCfg.HeartbeatSeconds != 30 {
+		t.Fatalf("unexpected task_queue lease defaults: %+v", queueCfg)
+	}
+	if queueCfg.PollIntervalMS != 2000 || queueCfg.MaxAttempts != 3 {
+		t.Fatalf("unexpected task_queue poll/attempt defaults: %+v", queueCfg)
+	}
+}
+
+func TestConfigNormalizeTaskQueueClampsInvalidValues(t *testing.T) {
+	cfg := validConfig()
+	cfg.TaskQueue = appcfg.TaskQueueConfig{
+		GlobalConcurrency:  2,
+		PerUserConcurrency: 5,
+		LeaseSeconds:       30,
+		HeartbeatSeconds:   45,
+	}
+	file := writeConfigFile(t, cfg)
+
+	loaded, err := appcfg.LoadConfig(file)
+	if err != nil {
+		t.Fatalf("load config failed: %v", err)
+	}
+
+	if loaded.TaskQueue.PerUserConcurrency != 2 {
+		t.Fatalf("expected per_user_concurrency clamped to global, got %d", loaded.TaskQueue.PerUserConcurrency)
+	}
+	if loaded.TaskQueue.HeartbeatSeconds != 10 {
+		t.Fatalf("expected heartbeat derived from lease, got %d", loaded.TaskQueue.HeartbeatSeconds)
+	}
+}
+
+func TestConfigNormalizeMediaStoreDefaultsAndClampsChunk(t *testing.T) {
+	cfg := validConfig()
+	file := writeConfigFile(t, cfg)
+
+	loaded, err := appcfg.LoadConfig(file)
+	if err != nil {
+		t.Fatalf("load config failed: %v", err)
+	}
+	storeCfg := loaded.MediaStore
+	if storeCfg.Dir != "DB/media" || storeCfg.MaxFileMB != 200 || storeCfg.ChunkSizeKB != 4096 {
+		t.Fatalf("unexpected media_store defaults: %+v", storeCfg)
+	}
+	if storeCfg.SessionTTLMinutes != 1440 || storeCfg.RetentionHours != 72 {
+		t.Fatalf("unexpected media_store ttl defaults: %+v", storeCfg)
+	}
+
+	cfg.MediaStore = appcfg.MediaStoreConfig{Dir: " /data/media ", MaxFileMB: 1, ChunkSizeKB: 4096}
+	loaded, err = appcfg.LoadConfig(writeConfigFile(t, cfg))
+	if err != nil {
+		t.Fatalf("load config failed: %v", err)
+	}
+	if loaded.MediaStore.Dir != "/data/media" || loaded.MediaStore.ChunkSizeKB != 1024 {
+		t.Fatalf("expected chunk size clamped to max file size, got %+v", loaded.MediaStore)
+	}
+}
+
+func TestConfigNormalizeBlobStoreDefaults(t *testing.T) {
+	loaded, err := appcfg.LoadConfig(writeConfigFile(t, validConfig()))
+	if err != nil {
+		t.Fatalf("load config failed: %v", err)
+	}
+	if loaded.BlobStore.Backend != appcfg.BlobStoreBackendLocal || loaded.BlobStore.Dir != "DB/blobs" || loaded.BlobStore.OrphanGraceMinutes != 60 {
+		t.Fatalf("unexpected blob_store defaults: %+v", loaded.BlobStore)
+	}
+
+	cfg := validConfig()
+	cfg.BlobStore = appcfg.BlobStoreConfig{Backend: " S3 ", Dir: " /data/blobs ", OrphanGraceMinutes: 5}
+	loaded, err = appcfg.LoadConfig(writeConfigFile(t, cfg))
+	if err != nil {
+		t.Fatalf("load config failed: %v", err)
+	}
+	if loaded.BlobStore.Backend != "s3" || loaded.BlobStore.Dir != "/data/blobs" || loaded.BlobStore.OrphanGraceMinutes != 5 {
+		t.Fatalf("unexpected normalized blob_store: %+v", loaded.BlobStore)
+	}
+}
+
+func TestConfigNormalizeDataRetentionDefaults(t *testing.T) {
+	loaded, err := appcfg.LoadConfig(writeConfigFile(t, validConfig()))
+	if err != nil {
+		t.Fatalf("load config failed: %v", err)
+	}
+	want := appcfg.DataRetentionConfig{
+		PurgeIntervalMinutes: 60,
+		ExportTTLMinutes:     30,
+	}
+	if loaded.DataRetention != want {
+		t.Fatalf("unexpected data_retention defaults: %+v", loaded.DataRetention)
+	}
+
+	cfg := validConfig()
+	cfg.DataRetention = appcfg.DataRetentionConfig{MediaDays: 7, HighRiskRecordDays: -1, PurgeIntervalMinutes: -1}
+	loaded, err = appcfg.LoadConfig(writeConfigFile(t, cfg))
+	if err != nil {
+		t.Fatalf("load config failed: %v", err)
+	}
+	if loaded.DataRetention.MediaDays != 7 || loaded.DataRetention.HighRiskRecordDays != -1 ||
+		loaded.DataRetention.PurgeIntervalMinutes != -1 || loaded.DataRetention.LowRiskRecordDays != 0 {
+		t.Fatalf("unexpected normalized data_retention: %+v", loaded.DataRetention)
+	}
+}
+
+func TestConfigLLMCassetteReplayAllowsMissingAPIKeys(t *testing.T) {
+	t.Setenv("LLM_CASSETTE_MODE", " Replay ")
+	t.Setenv("LLM_CASSETTE_DIR", " /tmp/cassettes ")
+
+	cfg := validConfig()
+	cfg.Agents.Main.APIKey = ""
+	cfg.Embedding.APIKey = ""
+	cfg.Chat.APIKey = ""
+	file := writeConfigFile(t, cfg)
+
+	loaded, err := appcfg.LoadConfig(file)
+	if err != nil {
+		t.Fatalf("expected replay mode to skip api_key checks, got %v", err)
+	}
+	if loaded.LLMCassette.Mode != appcfg.LLMCassetteModeReplay || loaded.LLMCassette.Dir != "/tmp/cassettes" {
+		t.Fatalf("unexpected llm_cassette config: %+v", loaded.LLMCassette)
+	}
+}
+
+func TestConfigLLMCassetteDefaultsAndValidation(t *testing.T) {
+	cfg := validConfig()
+	loaded, err := appcfg.LoadConfig(writeConfigFile(t, cfg))
+	if err != nil {
+		t.Fatalf("load config failed: %v", err)
+	}
+	if loaded.LLMCassette.Mode != appcfg.LLMCassetteModeOff || loaded.LLMCassette.Dir != "testdata/llm_cassettes" {
+		t.Fatalf("unexpected llm_cassette defaults: %+v", loaded.LLMCassette)
+	}
+
+	cfg.LLMCassette.Mode = "rewind"
+	if _, err := appcfg.LoadConfig(writeConfigFile(t, cfg)); err == nil || !strings.Contains(err.Error(), "llm_cassette.mode") {
+		t.Fatalf("expected invalid llm_cassette.mode error, got %v", err)
+	}
+
+	cfg.LLMCassette.Mode = appcfg.LLMCassetteModeRecord
+	cfg.Agents.Main.APIKey = ""
+	if _, err := appcfg.LoadConfig(writeConfigFile(t, cfg)); err == nil || !strings.Contains(err.Error(), "agents.main.api_key") {
+		t.Fatalf("expected record mode to keep api_key checks, got %v", err)
+	}
+}
+
+func TestConfigNormalizeWebSearch(t *testing.T) {
+	cfg := validConfig()
+	cfg.WebSearch.Provider = "  SearXNG "
+	cfg.WebSearch.AllowDomains = []string{" GOV.cn ", "", "gov.cn"}
+	cfg.WebSearch.SearXNG.BaseURL = " https://search.example.com/ "
+
+	loaded, err := appcfg.LoadConfig(writeConfigFile(t, cfg))
+	if err != nil {
+		t.Fatalf("load config failed: %v", err)
+	}
+
+	if loaded.WebSearch.Provider != appcfg.WebSearchProviderSearXNG {
+		t.Fatalf("expected normalized provider, got %q", loaded.WebSearch.Provider)
+	}
+	if len(loaded.WebSearch.AllowDomains) != 1 || loaded.WebSearch.AllowDomains[0] != "gov.cn" {
+		t.Fatalf("expected deduplicated allow domains, got %#v", loaded.WebSearch.AllowDomains)
+	}
+	if loaded.WebSearch.SearXNG.BaseURL != "https://search.example.com" {
+		t.Fatalf("expected trimmed searxng base url, got %q", loaded.WebSearch.SearXNG.BaseURL)
+	}
+	if loaded.WebSearch.CacheTTLSeconds != 600 || loaded.WebSearch.SearXNG.RateLimitPerMinute != 60 {
+		t.Fatalf("expected web search defaults, got %#v", loaded.WebSearch)
+	}
+}
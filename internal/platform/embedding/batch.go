@@ -0,0 +1,182 @@
+package embedding
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	appcfg "antifraud/internal/platform/config"
+	openai "antifraud/internal/platform/llm"
+)
+
+// loadEmbeddingConfig 读取 embedding 配置（LoadConfig 自带进程内缓存）。
+var loadEmbeddingConfig = func() (*appcfg.Config, error) {
+	return appcfg.LoadConfig(defaultConfigPath)
+}
+
+var embeddingClients sync.Map
+
+// embeddingClient 按 api_key + base_url 复用客户端，避免每次调用都重新构建。
+func embeddingClient(cfg *appcfg.Config) *openai.Client {
+	key := cfg.Embedding.APIKey + "\x00" + cfg.Embedding.BaseURL
+	if cached, ok := embeddingClients.Load(key); ok {
+		return cached.(*openai.Client)
+	}
+	client := openai.NewClientWithConfig(openai.Config{
+		APIKey:  cfg.Embedding.APIKey,
+		BaseURL: cfg.Embedding.BaseURL,
+	})
+	actual, _ := embeddingClients.LoadOrStore(key, client)
+	return actual.(*openai.Client)
+}
+
+// GenerateVectors 批量生成向量，返回值与 inputs 一一对应。
+// 相同文本只请求一次；命中内容哈希缓存的文本不再调用 embeddings 接口，其余按 embedding.batch_size 分批请求。
+func GenerateVectors(ctx context.Context, inputs []string) ([][]float64, string, error) {
+	return GenerateVectorsWithModel(ctx, inputs, "")
+}
+
+// GenerateVectorsWithModel 使用指定模型批量生成向量，model 为空时依次取 ActiveModel、配置中的 embedding.model。
+func GenerateVectorsWithModel(ctx context.Context, inputs []string, model string) ([][]float64, string, error) {
+	if len(inputs) == 0 {
+		return [][]float64{}, "", nil
+	}
+	trimmedInputs := make([]string, len(inputs))
+	for index, input := range inputs {
+		trimmedInputs[index] = strings.TrimSpace(input)
+		if trimmedInputs[index] == "" {
+			return nil, "", fmt.Errorf("input text is empty: index=%d", index)
+		}
+	}
+
+	cfg, err := loadEmbeddingConfig()
+	if err != nil {
+		return nil, "", fmt.Errorf("load config failed: %w", err)
+	}
+	explicitModel := strings.TrimSpace(model)
+	requestedModel := explicitModel
+	if requestedModel == "" {
+		requestedModel = ActiveModel()
+	}
+	pinned := requestedModel != ""
+	if requestedModel == "" {
+		requestedModel = strings.TrimSpace(cfg.Embedding.Model)
+	}
+
+	// 去重后按内容哈希查缓存，cacheKeys 与 uniqueInputs 下标一致。
+	uniqueInputs := make([]string, 0, len(trimmedInputs))
+	positions := make(map[string]int, len(trimmedInputs))
+	for _, input := range trimmedInputs {
+		if _, ok := positions[input]; !ok {
+			positions[input] = len(uniqueInputs)
+			uniqueInputs = append(uniqueInputs, input)
+		}
+	}
+	cacheKeys := make([]string, len(uniqueInputs))
+	for index, input := range uniqueInputs {
+		cacheKeys[index] = embeddingCacheKey(requestedModel, input)
+	}
+
+	vectors := make([][]float64, len(uniqueInputs))
+	modelName := ""
+	cached := embeddingVectorCache.Get(ctx, cacheKeys)
+	missing := make([]int, 0, len(uniqueInputs))
+	for index, key := range cacheKeys {
+		if entry, ok := cached[key]; ok && len(entry.Vector) > 0 {
+			vectors[index] = entry.Vector
+			modelName = entry.Model
+			continue
+		}
+		missing = append(missing, index)
+	}
+
+	if len(missing) > 0 {
+		client := embeddingClient(cfg)
+		callCtx := ctx
+		if callCtx == nil {
+			callCtx = context.Background()
+		}
+		batchSize := cfg.Embedding.BatchSize
+		if batchSize <= 0 {
+			batchSize = len(missing)
+		}
+
+		fresh := make(map[string]cachedVector, len(missing))
+		for start := 0; start < len(missing); start += batchSize {
+			end := start + batchSize
+			if end > len(missing) {
+				end = len(missing)
+			}
+			chunk := missing[start:end]
+			chunkInputs := make([]string, 0, len(chunk))
+			for _, index := range chunk {
+				chunkInputs = append(chunkInputs, uniqueInputs[index])
+			}
+
+			chunkVectors, responseModel, err := requestEmbeddings(callCtx, client, cfg, requestedModel, chunkInputs)
+			if err != nil {
+				return nil, "", err
+			}
+			// 显式指定或已固定模型时以请求的模型名为准，保证库内记录的模型名与迁移/检索比较时使用的名称一致。
+			chunkModel := requestedModel
+			if !pinned && responseModel != "" {
+				chunkModel = responseModel
+			}
+			modelName = chunkModel
+			for offset, index := range chunk {
+				vectors[index] = chunkVectors[offset]
+				fresh[cacheKeys[index]] = cachedVector{Model: chunkModel, Vector: chunkVectors[offset]}
+			}
+		}
+		embeddingVectorCache.Put(ctx, fresh)
+	}
+	if modelName == "" {
+		modelName = requestedModel
+	}
+
+	results := make([][]float64, len(trimmedInputs))
+	for index, input := range trimmedInputs {
+		results[index] = append([]float64{}, vectors[positions[input]]...)
+	}
+	return results, modelName, nil
+}
+
+// requestEmbeddings 发送一次 embeddings 请求，按响应中的 index 还原输入顺序。
+func requestEmbeddings(ctx context.Context, client *openai.Client, cfg *appcfg.Config, model string, inputs []string) ([][]float64, string, error) {
+	req := openai.EmbeddingRequest{
+		Model:          model,
+		Input:          inputs,
+		EncodingFormat: "float",
+	}
+	req.SetField("truncate", "NONE")
+
+	resp, err := createEmbeddingsWithRetry(ctx, client, cfg, req)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(resp.Data) != len(inputs) {
+		return nil, "", fmt.Errorf("embedding response size mismatch: inputs=%d data=%d", len(inputs), len(resp.Data))
+	}
+
+	sort.Slice(resp.Data, func(i, j int) bool {
+		return resp.Data[i].Index < resp.Data[j].Index
+	})
+	vectors := make([][]float64, len(inputs))
+	for index, item := range resp.Data {
+		if len(item.Embedding) == 0 {
+			return nil, "", fmt.Errorf("embedding vector is empty: index=%d", index)
+		}
+		vectors[index] = append([]float64{}, item.Embedding...)
+	}
+	return vectors, strings.TrimSpace(resp.Model), nil
+}
+
+// embeddingCacheKey 以模型名与输入文本的 SHA-256 作为缓存键，同一文本在不同模型下互不干扰。
+func embeddingCacheKey(model string, input string) string {
+	sum := sha256.Sum256([]byte(model + "\x00" + input))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,191 @@
+package embedding
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"antifraud/internal/platform/cache"
+	"antifraud/internal/platform/database"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	embeddingCacheRedisKeyPrefix = "cache:embedding:vector:"
+	embeddingCacheRedisTTL       = 7 * 24 * time.Hour
+)
+
+// cachedVector 是一条按内容哈希缓存的向量。
+type cachedVector struct {
+	Model  string    `json:"model"`
+	Vector []float64 `json:"vector"`
+}
+
+// vectorCache 是 embedding 结果缓存端口，Get 只返回命中的键。
+type vectorCache interface {
+	Get(ctx context.Context, keys []string) map[string]cachedVector
+	Put(ctx context.Context, entries map[string]cachedVector)
+}
+
+// embeddingVectorCache 先查 Redis，未命中再查主业务库 SQLite 表；缓存读写失败只记录日志，不影响向量生成。
+var embeddingVectorCache vectorCache = layeredVectorCache{
+	layers: []vectorCache{redisVectorCache{}, sqliteVectorCache{db: func() *gorm.DB { return database.DB }}},
+}
+
+type layeredVectorCache struct {
+	layers []vectorCache
+}
+
+func (c layeredVectorCache) Get(ctx context.Context, keys []string) map[string]cachedVector {
+	hits := make(map[string]cachedVector, len(keys))
+	pending := keys
+	for index, layer := range c.layers {
+		if len(pending) == 0 {
+			break
+		}
+		layerHits := layer.Get(ctx, pending)
+		if len(layerHits) == 0 {
+			continue
+		}
+		// 下层命中的结果回填到更快的上层。
+		for upper := 0; upper < index; upper++ {
+			c.layers[upper].Put(ctx, layerHits)
+		}
+		remaining := make([]string, 0, len(pending)-len(layerHits))
+		for _, key := range pending {
+			if entry, ok := layerHits[key]; ok {
+				hits[key] = entry
+				continue
+			}
+			remaining = append(remaining, key)
+		}
+		pending = remaining
+	}
+	return hits
+}
+
+func (c layeredVectorCache) Put(ctx context.Context, entries map[string]cachedVector) {
+	if len(entries) == 0 {
+		return
+	}
+	for _, layer := range c.layers {
+		layer.Put(ctx, entries)
+	}
+}
+
+type redisVectorCache struct{}
+
+func (redisVectorCache) Get(ctx context.Context, keys []string) map[string]cachedVector {
+	hits := make(map[string]cachedVector, len(keys))
+	for _, key := range keys {
+		var entry cachedVector
+		found, err := cache.GetJSONWithContext(ctx, embeddingCacheRedisKeyPrefix+key, &entry)
+		if err != nil {
+			// Redis 不可用时整体跳过本层，避免逐条重试。
+			return hits
+		}
+		if found && len(entry.Vector) > 0 {
+			hits[key] = entry
+		}
+	}
+	return hits
+}
+
+func (redisVectorCache) Put(ctx context.Context, entries map[string]cachedVector) {
+	for key, entry := range entries {
+		if err := cache.SetJSONWithContext(ctx, embeddingCacheRedisKeyPrefix+key, entry, embeddingCacheRedisTTL); err != nil {
+			return
+		}
+	}
+}
+
+// embeddingCacheEntity 是 embedding 缓存的持久化表，Redis 不可用或淘汰后仍可复用历史结果。
+type embeddingCacheEntity struct {
+	CacheKey  string `gorm:"primaryKey;size:64"`
+	Model     string `gorm:"size:128;index"`
+	Dimension int    `gorm:"not null"`
+	Vector    string `gorm:"type:text;not null"`
+	CreatedAt time.Time
+}
+
+func (embeddingCacheEntity) TableName() string {
+	return "embedding_vector_cache"
+}
+
+var embeddingCacheSchemaReady sync.Map
+
+type sqliteVectorCache struct {
+	db func() *gorm.DB
+}
+
+func (c sqliteVectorCache) ready() (*gorm.DB, bool) {
+	db := c.db()
+	if db == nil {
+		return nil, false
+	}
+	if _, ok := embeddingCacheSchemaReady.Load(db); ok {
+		return db, true
+	}
+	if err := db.AutoMigrate(&embeddingCacheEntity{}); err != nil {
+		log.Printf("[embedding] migrate embedding cache table failed: %v", err)
+		return nil, false
+	}
+	embeddingCacheSchemaReady.Store(db, struct{}{})
+	return db, true
+}
+
+func (c sqliteVectorCache) Get(ctx context.Context, keys []string) map[string]cachedVector {
+	hits := make(map[string]cachedVector, len(keys))
+	db, ok := c.ready()
+	if !ok || len(keys) == 0 {
+		return hits
+	}
+	var rows []embeddingCacheEntity
+	if err := db.WithContext(nonNilContext(ctx)).Where("cache_key IN ?", keys).Find(&rows).Error; err != nil {
+		log.Printf("[embedding] query embedding cache failed: %v", err)
+		return hits
+	}
+	for _, row := range rows {
+		var vector []float64
+		if err := json.Unmarshal([]byte(row.Vector), &vector); err != nil || len(vector) == 0 {
+			continue
+		}
+		hits[row.CacheKey] = cachedVector{Model: strings.TrimSpace(row.Model), Vector: vector}
+	}
+	return hits
+}
+
+func (c sqliteVectorCache) Put(ctx context.Context, entries map[string]cachedVector) {
+	db, ok := c.ready()
+	if !ok || len(entries) == 0 {
+		return
+	}
+	rows := make([]embeddingCacheEntity, 0, len(entries))
+	for key, entry := range entries {
+		encoded, err := json.Marshal(entry.Vector)
+		if err != nil {
+			continue
+		}
+		rows = append(rows, embeddingCacheEntity{
+			CacheKey:  key,
+			Model:     entry.Model,
+			Dimension: len(entry.Vector),
+			Vector:    string(encoded),
+		})
+	}
+	if err := db.WithContext(context.WithoutCancel(nonNilContext(ctx))).Clauses(clause.OnConflict{DoNothing: true}).Create(&rows).Error; err != nil {
+		log.Printf("[embedding] save embedding cache failed: %v", err)
+	}
+}
+
+func nonNilContext(ctx context.Context) context.Context {
+	if ctx == nil {
+		return context.Background()
+	}
+	return ctx
+}
@@ -3,9 +3,9 @@ package embedding
 import (
 	"context"
 	"fmt"
-	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	appcfg "antifraud/internal/platform/config"
@@ -14,58 +14,65 @@ import (
 
 const defaultConfigPath = "internal/platform/config/config.json"
 
-// GenerateVector 调用统一 embeddings 接口，并返回单条输入的向量与模型名。
-func GenerateVector(ctx context.Context, inputText string) ([]float64, string, error) {
-	trimmedInput := strings.TrimSpace(inputText)
-	if trimmedInput == "" {
-		return nil, "", fmt.Errorf("input text is empty")
-	}
+var modelState struct {
+	mu     sync.RWMutex
+	active string
+	target string
+}
 
-	cfg, err := appcfg.LoadConfig(defaultConfigPath)
-	if err != nil {
-		return nil, "", fmt.Errorf("load config failed: %w", err)
-	}
+// SetActiveModel 固定检索与写入使用的 embedding 模型。
+// 模型切换必须经过重新向量化迁移，因此服务启动时会把当前向量库使用的模型固定下来；
+// 为空时 GenerateVector 直接使用配置中的 embedding.model。
+func SetActiveModel(model string) {
+	modelState.mu.Lock()
+	defer modelState.mu.Unlock()
+	modelState.active = strings.TrimSpace(model)
+}
 
-	client := openai.NewClientWithConfig(openai.Config{
-		APIKey:  cfg.Embedding.APIKey,
-		BaseURL: cfg.Embedding.BaseURL,
-	})
+// ActiveModel 返回已固定的 embedding 模型；未固定时返回空串。
+func ActiveModel() string {
+	modelState.mu.RLock()
+	defer modelState.mu.RUnlock()
+	return modelState.active
+}
 
-	req := openai.EmbeddingRequest{
-		Model:          cfg.Embedding.Model,
-		Input:          []string{trimmedInput},
-		EncodingFormat: "float",
-	}
-	req.SetField("truncate", "NONE")
+// SetMigrationTargetModel 设置正在迁移的目标模型，写入路径据此为新数据双写目标模型向量；为空表示没有进行中的迁移。
+func SetMigrationTargetModel(model string) {
+	modelState.mu.Lock()
+	defer modelState.mu.Unlock()
+	modelState.target = strings.TrimSpace(model)
+}
 
-	callCtx := ctx
-	if callCtx == nil {
-		callCtx = context.Background()
-	}
+// MigrationTargetModel 返回正在迁移的目标模型；没有迁移时返回空串。
+func MigrationTargetModel() string {
+	modelState.mu.RLock()
+	defer modelState.mu.RUnlock()
+	return modelState.target
+}
 
-	resp, err := createEmbeddingsWithRetry(callCtx, client, cfg, req)
+// ConfiguredModel 返回配置文件中的 embedding.model。
+func ConfiguredModel() (string, error) {
+	cfg, err := loadEmbeddingConfig()
 	if err != nil {
-		return nil, "", err
-	}
-	if len(resp.Data) == 0 {
-		return nil, "", fmt.Errorf("embedding response is empty")
+		return "", fmt.Errorf("load config failed: %w", err)
 	}
+	return strings.TrimSpace(cfg.Embedding.Model), nil
+}
 
-	sort.Slice(resp.Data, func(i, j int) bool {
-		return resp.Data[i].Index < resp.Data[j].Index
-	})
-
-	vector := append([]float64{}, resp.Data[0].Embedding...)
-	if len(vector) == 0 {
-		return nil, "", fmt.Errorf("embedding vector is empty")
-	}
+// GenerateVector 调用统一 embeddings 接口，并返回单条输入的向量与模型名。
+// 使用的模型优先取 ActiveModel，未固定时取配置中的 embedding.model。
+func GenerateVector(ctx context.Context, inputText string) ([]float64, string, error) {
+	return GenerateVectorWithModel(ctx, inputText, "")
+}
 
-	modelName := strings.TrimSpace(resp.Model)
-	if modelName == "" {
-		modelName = strings.TrimSpace(cfg.Embedding.Model)
+// GenerateVectorWithModel 使用指定模型生成向量，model 为空时与 GenerateVector 行为一致。
+// 供 embedding 迁移任务按目标模型重新向量化使用。
+func GenerateVectorWithModel(ctx context.Context, inputText string, model string) ([]float64, string, error) {
+	vectors, modelName, err := GenerateVectorsWithModel(ctx, []string{inputText}, model)
+	if err != nil {
+		return nil, "", err
 	}
-
-	return vector, modelName, nil
+	return vectors[0], modelName, nil
 }
 
 func createEmbeddingsWithRetry(ctx context.Context, client *openai.Client, cfg *appcfg.Config, req openai.EmbeddingRequest) (openai.EmbeddingResponse, error) {
@@ -0,0 +1,145 @@
+package embedding_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+	_ "unsafe"
+
+	appcfg "antifraud/internal/platform/config"
+	"antifraud/internal/platform/database"
+	"antifraud/internal/platform/embedding"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+//go:linkname loadEmbeddingConfig antifraud/internal/platform/embedding.loadEmbeddingConfig
+var loadEmbeddingConfig func() (*appcfg.Config, error)
+
+type embeddingServer struct {
+	mu       sync.Mutex
+	requests [][]string
+	models   []string
+}
+
+func (s *embeddingServer) handler(t *testing.T) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/embeddings" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		var payload struct {
+			Model string   `json:"model"`
+			Input []string `json:"input"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("decode embedding request failed: %v", err)
+		}
+		s.mu.Lock()
+		s.requests = append(s.requests, payload.Input)
+		s.models = append(s.models, payload.Model)
+		s.mu.Unlock()
+
+		// 逆序返回，验证按 index 还原输入顺序。
+		data := make([]map[string]interface{}, 0, len(payload.Input))
+		for index := len(payload.Input) - 1; index >= 0; index-- {
+			data = append(data, map[string]interface{}{
+				"object":    "embedding",
+				"index":     index,
+				"embedding": []float64{float64(len(payload.Input[index])), 1},
+			})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"object": "list", "data": data, "model": "mock-embed"})
+	}
+}
+
+func (s *embeddingServer) snapshot() [][]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([][]string{}, s.requests...)
+}
+
+func setupEmbeddingTest(t *testing.T, batchSize int) *embeddingServer {
+	t.Helper()
+
+	server := &embeddingServer{}
+	httpServer := httptest.NewServer(server.handler(t))
+	t.Cleanup(httpServer.Close)
+
+	originalLoad := loadEmbeddingConfig
+	loadEmbeddingConfig = func() (*appcfg.Config, error) {
+		cfg := &appcfg.Config{}
+		cfg.Embedding = appcfg.EmbeddingConfig{Model: "mock-embed", BaseURL: httpServer.URL, BatchSize: batchSize}
+		cfg.Retry = appcfg.RetryConfig{MaxRetries: 1, RetryDelayMS: 1}
+		return cfg, nil
+	}
+
+	oldDB := database.DB
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "embedding_cache_test.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open sqlite failed: %v", err)
+	}
+	database.DB = db
+	t.Cleanup(func() {
+		loadEmbeddingConfig = originalLoad
+		database.DB = oldDB
+		if sqlDB, err := db.DB(); err == nil {
+			_ = sqlDB.Close()
+		}
+	})
+	return server
+}
+
+func TestGenerateVectors_BatchesDedupesAndCaches(t *testing.T) {
+	server := setupEmbeddingTest(t, 2)
+	// 每次运行使用不同文本，避免本机 Redis 中残留的缓存影响请求计数。
+	suffix := fmt.Sprintf("-%d", time.Now().UnixNano())
+	a, b, c := "冒充客服"+suffix, "安全账户转账"+suffix, "刷单返利兼职"+suffix
+
+	vectors, model, err := embedding.GenerateVectors(context.Background(), []string{a, " " + b + " ", a, c})
+	if err != nil {
+		t.Fatalf("generate vectors failed: %v", err)
+	}
+	if model != "mock-embed" || len(vectors) != 4 {
+		t.Fatalf("unexpected result: model=%s vectors=%v", model, vectors)
+	}
+	for index, input := range []string{a, b, a, c} {
+		if vectors[index][0] != float64(len(input)) {
+			t.Fatalf("vector %d not aligned with its input: %v", index, vectors[index])
+		}
+	}
+	requests := server.snapshot()
+	if len(requests) != 2 || len(requests[0]) != 2 || len(requests[1]) != 1 {
+		t.Fatalf("expected unique inputs sent in batches of 2, got %v", requests)
+	}
+
+	vectors, _, err = embedding.GenerateVectors(context.Background(), []string{c, a})
+	if err != nil {
+		t.Fatalf("generate cached vectors failed: %v", err)
+	}
+	if len(server.snapshot()) != 2 || vectors[0][0] != float64(len(c)) || vectors[1][0] != float64(len(a)) {
+		t.Fatalf("expected cache hits without new requests, requests=%v vectors=%v", server.snapshot(), vectors)
+	}
+
+	vector, model, err := embedding.GenerateVectorWithModel(context.Background(), a, "mock-embed-v2")
+	if err != nil {
+		t.Fatalf("generate vector with explicit model failed: %v", err)
+	}
+	if model != "mock-embed-v2" || len(vector) != 2 || len(server.snapshot()) != 3 {
+		t.Fatalf("expected a separate cache entry per model, model=%s requests=%v", model, server.snapshot())
+	}
+
+	if _, _, err := embedding.GenerateVectors(context.Background(), []string{a, "  "}); err == nil {
+		t.Fatal("expected error for blank input")
+	}
+}
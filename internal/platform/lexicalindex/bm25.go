@@ -0,0 +1,231 @@
+package lexicalindex
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Options 是 BM25 参数，零值使用常用默认值 k1=1.2、b=0.75。
+type Options struct {
+	K1 float64
+	B  float64
+}
+
+// Field 是参与索引的一个文本字段；Weight 用于提升标题、关键词等字段的词频权重（<=0 视为 1）。
+type Field struct {
+	Text   string
+	Weight int
+}
+
+// Hit 是一次检索命中，Score 越大越相关；MatchedTerms 为命中的查询词（去重、按出现顺序）。
+type Hit struct {
+	ID           string
+	Score        float64
+	MatchedTerms []string
+}
+
+type bm25Document struct {
+	length int
+	terms  map[string]int
+}
+
+// BM25 是进程内倒排索引，按 Okapi BM25 打分。
+// 分词规则见 Tokenize：中文按相邻双字切分，字母数字按连续串整体切分，
+// 因此账号、手机号、App 名称等精确串可以直接命中。
+// 非并发安全，调用方负责加锁。
+type BM25 struct {
+	k1       float64
+	b        float64
+	docs     map[string]bm25Document
+	postings map[string]map[string]int
+	totalLen int
+}
+
+// NewBM25 创建空索引。
+func NewBM25(options Options) *BM25 {
+	if options.K1 <= 0 {
+		options.K1 = 1.2
+	}
+	if options.B <= 0 || options.B > 1 {
+		options.B = 0.75
+	}
+	return &BM25{
+		k1:       options.K1,
+		b:        options.B,
+		docs:     map[string]bm25Document{},
+		postings: map[string]map[string]int{},
+	}
+}
+
+// Len 返回已索引文档数。
+func (x *BM25) Len() int {
+	return len(x.docs)
+}
+
+// Contains 判断文档是否已索引。
+func (x *BM25) Contains(id string) bool {
+	_, ok := x.docs[id]
+	return ok
+}
+
+// Upsert 写入或覆盖一篇文档；所有字段分词后为空时等同于删除并返回 false。
+func (x *BM25) Upsert(id string, fields ...Field) bool {
+	x.Remove(id)
+	if id == "" {
+		return false
+	}
+
+	terms := map[string]int{}
+	length := 0
+	for _, field := range fields {
+		weight := field.Weight
+		if weight <= 0 {
+			weight = 1
+		}
+		for _, token := range Tokenize(field.Text) {
+			terms[token] += weight
+			length += weight
+		}
+	}
+	if length == 0 {
+		return false
+	}
+
+	x.docs[id] = bm25Document{length: length, terms: terms}
+	x.totalLen += length
+	for term, tf := range terms {
+		posting, ok := x.postings[term]
+		if !ok {
+			posting = map[string]int{}
+			x.postings[term] = posting
+		}
+		posting[id] = tf
+	}
+	return true
+}
+
+// Remove 删除文档，返回文档此前是否存在。
+func (x *BM25) Remove(id string) bool {
+	doc, ok := x.docs[id]
+	if !ok {
+		return false
+	}
+	for term := range doc.terms {
+		posting := x.postings[term]
+		delete(posting, id)
+		if len(posting) == 0 {
+			delete(x.postings, term)
+		}
+	}
+	x.totalLen -= doc.length
+	delete(x.docs, id)
+	return true
+}
+
+// Search 返回与查询最相关的 k 篇文档，按得分降序、同分按 ID 升序；accept 非空时只返回其接受的文档。
+func (x *BM25) Search(query string, k int, accept func(id string) bool) []Hit {
+	if k <= 0 || len(x.docs) == 0 {
+		return []Hit{}
+	}
+	queryTerms := uniqueTokens(Tokenize(query))
+	if len(queryTerms) == 0 {
+		return []Hit{}
+	}
+
+	docCount := float64(len(x.docs))
+	avgLen := float64(x.totalLen) / docCount
+	scores := map[string]float64{}
+	matched := map[string][]string{}
+	for _, term := range queryTerms {
+		posting, ok := x.postings[term]
+		if !ok {
+			continue
+		}
+		df := float64(len(posting))
+		idf := math.Log(1 + (docCount-df+0.5)/(df+0.5))
+		for id, tf := range posting {
+			if accept != nil && !accept(id) {
+				continue
+			}
+			freq := float64(tf)
+			norm := x.k1 * (1 - x.b + x.b*float64(x.docs[id].length)/avgLen)
+			scores[id] += idf * freq * (x.k1 + 1) / (freq + norm)
+			matched[id] = append(matched[id], term)
+		}
+	}
+
+	hits := make([]Hit, 0, len(scores))
+	for id, score := range scores {
+		hits = append(hits, Hit{ID: id, Score: score, MatchedTerms: matched[id]})
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].ID < hits[j].ID
+	})
+	if len(hits) > k {
+		hits = hits[:k]
+	}
+	return hits
+}
+
+// Tokenize 把文本切分为索引词：
+// 1) 字母与数字连续串整体作为一个词（统一小写），保证账号、金额、App 名称可精确匹配；
+// 2) 中文等其他文字按相邻双字切分，单字串保留单字；
+// 3) 标点与空白作为分隔符。
+func Tokenize(text string) []string {
+	tokens := make([]string, 0, len(text)/2)
+	var ascii []rune
+	var cjk []rune
+	flushASCII := func() {
+		if len(ascii) > 0 {
+			tokens = append(tokens, string(ascii))
+			ascii = ascii[:0]
+		}
+	}
+	flushCJK := func() {
+		switch len(cjk) {
+		case 0:
+		case 1:
+			tokens = append(tokens, string(cjk))
+		default:
+			for i := 0; i+1 < len(cjk); i++ {
+				tokens = append(tokens, string(cjk[i:i+2]))
+			}
+		}
+		cjk = cjk[:0]
+	}
+
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			flushCJK()
+			ascii = append(ascii, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			flushASCII()
+			cjk = append(cjk, r)
+		default:
+			flushASCII()
+			flushCJK()
+		}
+	}
+	flushASCII()
+	flushCJK()
+	return tokens
+}
+
+func uniqueTokens(tokens []string) []string {
+	seen := make(map[string]struct{}, len(tokens))
+	unique := make([]string, 0, len(tokens))
+	for _, token := range tokens {
+		if _, ok := seen[token]; ok {
+			continue
+		}
+		seen[token] = struct{}{}
+		unique = append(unique, token)
+	}
+	return unique
+}
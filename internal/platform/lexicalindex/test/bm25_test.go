@@ -0,0 +1,50 @@
+package lexicalindex_test
+
+import (
+	"reflect"
+	"testing"
+
+	"antifraud/internal/platform/lexicalindex"
+)
+
+func TestTokenize_SplitsChineseBigramsAndKeepsAlphanumericRuns(t *testing.T) {
+	got := lexicalindex.Tokenize("下载QuickSupport，转账到6222-0202！")
+	want := []string{"下载", "quicksupport", "转账", "账到", "6222", "0202"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected tokens: got=%v want=%v", got, want)
+	}
+	if got := lexicalindex.Tokenize("骗"); !reflect.DeepEqual(got, []string{"骗"}) {
+		t.Fatalf("single character run should be kept, got %v", got)
+	}
+}
+
+func TestBM25_RanksRareExactTermsAndSupportsUpdates(t *testing.T) {
+	index := lexicalindex.NewBM25(lexicalindex.Options{})
+	index.Upsert("a", lexicalindex.Field{Text: "冒充客服退款，诱导转账"})
+	index.Upsert("b", lexicalindex.Field{Text: "冒充客服，要求转账到安全账户 6222020200112233"})
+	index.Upsert("c", lexicalindex.Field{Text: "刷单返利，诱导转账"}, lexicalindex.Field{Text: "刷单", Weight: 3})
+
+	hits := index.Search("转账 6222020200112233", 3, nil)
+	if len(hits) != 3 || hits[0].ID != "b" {
+		t.Fatalf("expected exact account match ranked first, got %+v", hits)
+	}
+	if !reflect.DeepEqual(hits[0].MatchedTerms, []string{"转账", "6222020200112233"}) {
+		t.Fatalf("unexpected matched terms: %v", hits[0].MatchedTerms)
+	}
+
+	onlyC := index.Search("转账", 5, func(id string) bool { return id == "c" })
+	if len(onlyC) != 1 || onlyC[0].ID != "c" {
+		t.Fatalf("accept filter not applied: %+v", onlyC)
+	}
+
+	index.Upsert("b", lexicalindex.Field{Text: "虚假投资理财"})
+	if hits := index.Search("6222020200112233", 3, nil); len(hits) != 0 {
+		t.Fatalf("replaced document should drop old terms, got %+v", hits)
+	}
+	if !index.Remove("a") || index.Remove("a") || index.Len() != 2 {
+		t.Fatalf("unexpected remove behaviour, len=%d", index.Len())
+	}
+	if index.Upsert("empty", lexicalindex.Field{Text: "，。！"}) || index.Contains("empty") {
+		t.Fatal("document without tokens should not be indexed")
+	}
+}
@@ -0,0 +1,344 @@
+package llm
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+const (
+	// CassetteModeOff 关闭录制回放，请求直连模型服务。
+	CassetteModeOff = "off"
+	// CassetteModeRecord 请求直连模型服务，并把每次交互写入 cassette 目录（同 key 覆盖）。
+	CassetteModeRecord = "record"
+	// CassetteModeReplay 只从 cassette 目录读取响应，未命中时直接报错，不发起任何网络请求。
+	CassetteModeReplay = "replay"
+	// CassetteModeAuto 优先回放，未命中时直连并录制，适合本地补录新用例。
+	CassetteModeAuto = "auto"
+)
+
+// DefaultCassetteDir 是未配置目录时使用的 cassette 存放路径。
+const DefaultCassetteDir = "testdata/llm_cassettes"
+
+const cassetteFormatVersion = 1
+
+// CassetteOptions 定义录制回放模式与存放目录。
+type CassetteOptions struct {
+	Mode string
+	Dir  string
+}
+
+// Enabled 表示是否需要接管请求。
+func (o CassetteOptions) Enabled() bool {
+	return NormalizeCassetteMode(o.Mode) != CassetteModeOff
+}
+
+// NormalizeCassetteMode 统一模式写法；空值与未知值都视为 off，由配置层负责拒绝非法值。
+func NormalizeCassetteMode(mode string) string {
+	switch strings.ToLower(strings.TrimSpace(mode)) {
+	case CassetteModeRecord:
+		return CassetteModeRecord
+	case CassetteModeReplay:
+		return CassetteModeReplay
+	case CassetteModeAuto:
+		return CassetteModeAuto
+	default:
+		return CassetteModeOff
+	}
+}
+
+var (
+	defaultCassetteMu sync.RWMutex
+	defaultCassette   CassetteOptions
+)
+
+// SetDefaultCassette 设置进程级录制回放选项，之后通过 NewClientWithConfig 创建的客户端都会经过 cassette。
+// 各智能体按需即时创建客户端，因此由启动入口根据 config.json 的 llm_cassette 统一设置一次即可。
+func SetDefaultCassette(options CassetteOptions) {
+	defaultCassetteMu.Lock()
+	defer defaultCassetteMu.Unlock()
+	defaultCassette = CassetteOptions{
+		Mode: NormalizeCassetteMode(options.Mode),
+		Dir:  strings.TrimSpace(options.Dir),
+	}
+}
+
+// DefaultCassette 返回当前进程级录制回放选项。
+func DefaultCassette() CassetteOptions {
+	defaultCassetteMu.RLock()
+	defer defaultCassetteMu.RUnlock()
+	return defaultCassette
+}
+
+// CassetteTransport 是按规范化请求体录制/回放模型交互的 http.RoundTripper。
+// 设计说明：
+// 1) key = sha256(method + path + 规范化 JSON 请求体)，请求体按 key 排序重新编码，工具定义、模型名与全部消息都参与匹配；
+// 2) 不记录 host 与请求头，更换服务域名或 API Key 不影响回放，也不会把密钥写入 cassette；
+// 3) 每次交互单独保存为 <key>.json，便于代码评审时逐条 diff；流式响应按原始字节保存，回放时原样输出。
+type CassetteTransport struct {
+	mode string
+	dir  string
+	base http.RoundTripper
+
+	writeMu sync.Mutex
+}
+
+// cassetteEntry 是单条交互在磁盘上的格式。
+type cassetteEntry struct {
+	Version  int              `json:"version"`
+	Key      string           `json:"key"`
+	Request  cassetteRequest  `json:"request"`
+	Response cassetteResponse `json:"response"`
+}
+
+type cassetteRequest struct {
+	Method string          `json:"method"`
+	Path   string          `json:"path"`
+	Body   json.RawMessage `json:"body,omitempty"`
+}
+
+type cassetteResponse struct {
+	StatusCode  int    `json:"status_code"`
+	ContentType string `json:"content_type,omitempty"`
+	Body        string `json:"body"`
+	// BodyEncoding 为 base64 时 Body 保存非 UTF-8 响应的 base64 编码。
+	BodyEncoding string `json:"body_encoding,omitempty"`
+}
+
+// NewCassetteTransport 创建录制回放 transport；base 为空时使用 http.DefaultTransport。
+func NewCassetteTransport(options CassetteOptions, base http.RoundTripper) *CassetteTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	dir := strings.TrimSpace(options.Dir)
+	if dir == "" {
+		dir = DefaultCassetteDir
+	}
+	return &CassetteTransport{
+		mode: NormalizeCassetteMode(options.Mode),
+		dir:  dir,
+		base: base,
+	}
+}
+
+func (t *CassetteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.mode == CassetteModeOff {
+		return t.base.RoundTrip(req)
+	}
+
+	body, err := readRequestBody(req)
+	if err != nil {
+		return nil, fmt.Errorf("read request body for cassette failed: %w", err)
+	}
+	normalizedBody := normalizeCassetteBody(body)
+	key := cassetteKey(req.Method, req.URL.Path, normalizedBody)
+
+	if t.mode == CassetteModeReplay || t.mode == CassetteModeAuto {
+		entry, found, err := t.load(key)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			return entry.Response.toHTTPResponse(req)
+		}
+		if t.mode == CassetteModeReplay {
+			return nil, fmt.Errorf("llm cassette miss: key=%s method=%s path=%s dir=%s", key, req.Method, req.URL.Path, t.dir)
+		}
+	}
+
+	outgoing := req.Clone(req.Context())
+	outgoing.Body = io.NopCloser(bytes.NewReader(body))
+	outgoing.ContentLength = int64(len(body))
+	resp, err := t.base.RoundTrip(outgoing)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("read response body for cassette failed: %w", err)
+	}
+
+	entry := cassetteEntry{
+		Version: cassetteFormatVersion,
+		Key:     key,
+		Request: cassetteRequest{
+			Method: req.Method,
+			Path:   req.URL.Path,
+			Body:   normalizedBody,
+		},
+		Response: newCassetteResponse(resp, respBody),
+	}
+	if err := t.save(entry); err != nil {
+		return nil, err
+	}
+
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+	resp.ContentLength = int64(len(respBody))
+	return resp, nil
+}
+
+func (t *CassetteTransport) entryPath(key string) string {
+	return filepath.Join(t.dir, key+".json")
+}
+
+func (t *CassetteTransport) load(key string) (cassetteEntry, bool, error) {
+	raw, err := os.ReadFile(t.entryPath(key))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return cassetteEntry{}, false, nil
+		}
+		return cassetteEntry{}, false, fmt.Errorf("read llm cassette failed: %w", err)
+	}
+	var entry cassetteEntry
+	if err := json.Unmarshal(raw, &entry); err != nil {
+		return cassetteEntry{}, false, fmt.Errorf("decode llm cassette %s failed: %w", key, err)
+	}
+	return entry, true, nil
+}
+
+// save 先写临时文件再重命名，避免并发录制或进程中断留下半截 cassette。
+func (t *CassetteTransport) save(entry cassetteEntry) error {
+	t.writeMu.Lock()
+	defer t.writeMu.Unlock()
+
+	if err := os.MkdirAll(t.dir, 0o755); err != nil {
+		return fmt.Errorf("create llm cassette dir failed: %w", err)
+	}
+	encoded, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode llm cassette failed: %w", err)
+	}
+	tmp, err := os.CreateTemp(t.dir, entry.Key+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("create llm cassette temp file failed: %w", err)
+	}
+	tmpPath := tmp.Name()
+	if _, err := tmp.Write(append(encoded, '\n')); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("write llm cassette failed: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("write llm cassette failed: %w", err)
+	}
+	if err := os.Rename(tmpPath, t.entryPath(entry.Key)); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("commit llm cassette failed: %w", err)
+	}
+	return nil
+}
+
+func readRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	defer req.Body.Close()
+	return io.ReadAll(req.Body)
+}
+
+// normalizeCassetteBody 把 JSON 请求体按 key 排序重新编码；非 JSON 请求体按原始字符串保存。
+func normalizeCassetteBody(body []byte) json.RawMessage {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) == 0 {
+		return nil
+	}
+	var decoded interface{}
+	decoder := json.NewDecoder(bytes.NewReader(trimmed))
+	decoder.UseNumber()
+	if err := decoder.Decode(&decoded); err == nil {
+		if normalized, err := marshalCassetteJSON(decoded); err == nil {
+			return normalized
+		}
+	}
+	fallback, _ := marshalCassetteJSON(string(trimmed))
+	return fallback
+}
+
+func marshalCassetteJSON(value interface{}) ([]byte, error) {
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return nil, err
+	}
+	return bytes.TrimRight(buffer.Bytes(), "\n"), nil
+}
+
+func cassetteKey(method string, path string, normalizedBody []byte) string {
+	hasher := sha256.New()
+	hasher.Write([]byte(strings.ToUpper(strings.TrimSpace(method))))
+	hasher.Write([]byte{' '})
+	hasher.Write([]byte(strings.TrimSpace(path)))
+	hasher.Write([]byte{'\n'})
+	hasher.Write(normalizedBody)
+	return hex.EncodeToString(hasher.Sum(nil))[:32]
+}
+
+func newCassetteResponse(resp *http.Response, body []byte) cassetteResponse {
+	recorded := cassetteResponse{
+		StatusCode:  resp.StatusCode,
+		ContentType: resp.Header.Get("Content-Type"),
+	}
+	if utf8.Valid(body) {
+		recorded.Body = string(body)
+		return recorded
+	}
+	recorded.Body = base64.StdEncoding.EncodeToString(body)
+	recorded.BodyEncoding = "base64"
+	return recorded
+}
+
+func (r cassetteResponse) toHTTPResponse(req *http.Request) (*http.Response, error) {
+	body := []byte(r.Body)
+	if r.BodyEncoding == "base64" {
+		decoded, err := base64.StdEncoding.DecodeString(r.Body)
+		if err != nil {
+			return nil, fmt.Errorf("decode llm cassette body failed: %w", err)
+		}
+		body = decoded
+	}
+	header := make(http.Header)
+	if strings.TrimSpace(r.ContentType) != "" {
+		header.Set("Content-Type", r.ContentType)
+	}
+	statusCode := r.StatusCode
+	if statusCode == 0 {
+		statusCode = http.StatusOK
+	}
+	return &http.Response{
+		StatusCode:    statusCode,
+		Status:        fmt.Sprintf("%d %s", statusCode, http.StatusText(statusCode)),
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// wrapHTTPClientWithCassette 返回经过 cassette 的 http.Client 副本，不修改调用方传入的客户端。
+func wrapHTTPClientWithCassette(client *http.Client, options CassetteOptions) *http.Client {
+	if client == nil {
+		client = &http.Client{}
+	}
+	if _, already := client.Transport.(*CassetteTransport); already {
+		return client
+	}
+	wrapped := *client
+	wrapped.Transport = NewCassetteTransport(options, client.Transport)
+	return &wrapped
+}
@@ -42,6 +42,10 @@ func NewClientWithConfig(cfg Config) *Client {
 	if cfg.HTTPClient == nil {
 		cfg.HTTPClient = &http.Client{}
 	}
+	// 进程级开启录制回放时，所有客户端统一经过 cassette，调用方无需改动。
+	if cassette := DefaultCassette(); cassette.Enabled() {
+		cfg.HTTPClient = wrapHTTPClientWithCassette(cfg.HTTPClient, cassette)
+	}
 	cfg.APIKey = strings.TrimSpace(cfg.APIKey)
 	cfg.BaseURL = strings.TrimRight(strings.TrimSpace(cfg.BaseURL), "/")
 	return &Client{cfg: cfg}
@@ -0,0 +1,159 @@
+package llm_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	openai "antifraud/internal/platform/llm"
+)
+
+func cassetteChatRequest(toolName string) openai.ChatCompletionRequest {
+	return openai.ChatCompletionRequest{
+		Model: "gpt-test",
+		Messages: []openai.ChatCompletionMessage{
+			{Role: openai.ChatMessageRoleUser, Content: "有人让我转账到安全账户"},
+		},
+		Tools: []openai.Tool{{
+			Type:     openai.ToolTypeFunction,
+			Function: &openai.FunctionDefinition{Name: toolName, Parameters: map[string]interface{}{"type": "object"}},
+		}},
+	}
+}
+
+func useDefaultCassette(t *testing.T, options openai.CassetteOptions) {
+	t.Helper()
+	openai.SetDefaultCassette(options)
+	t.Cleanup(func() { openai.SetDefaultCassette(openai.CassetteOptions{}) })
+}
+
+func TestCassette_RecordThenReplayWithoutNetwork(t *testing.T) {
+	dir := t.TempDir()
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"高风险"}}]}`))
+	}))
+
+	useDefaultCassette(t, openai.CassetteOptions{Mode: openai.CassetteModeRecord, Dir: dir})
+	recorder := openai.NewClientWithConfig(openai.Config{APIKey: "secret-token", BaseURL: server.URL})
+	recorded, err := recorder.CreateChatCompletion(context.Background(), cassetteChatRequest("submit_report"))
+	if err != nil {
+		t.Fatalf("record request failed: %v", err)
+	}
+	server.Close()
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	if len(files) != 1 {
+		t.Fatalf("expected exactly one cassette file, got %v", files)
+	}
+	raw, _ := os.ReadFile(files[0])
+	if strings.Contains(string(raw), "secret-token") {
+		t.Fatalf("cassette must not contain api key: %s", raw)
+	}
+
+	// 回放时改用不同 host 与密钥，仍应命中同一条录制。
+	useDefaultCassette(t, openai.CassetteOptions{Mode: openai.CassetteModeReplay, Dir: dir})
+	replayer := openai.NewClientWithConfig(openai.Config{BaseURL: "http://offline.invalid"})
+	replayed, err := replayer.CreateChatCompletion(context.Background(), cassetteChatRequest("submit_report"))
+	if err != nil {
+		t.Fatalf("replay request failed: %v", err)
+	}
+	if atomic.LoadInt32(&calls) != 1 {
+		t.Fatalf("expected a single upstream call, got %d", calls)
+	}
+	if len(replayed.Choices) != 1 || replayed.Choices[0].Message.Content != recorded.Choices[0].Message.Content {
+		t.Fatalf("unexpected replayed response: %+v", replayed)
+	}
+}
+
+func TestCassette_ReplayMissIncludesToolDefinitionsInKey(t *testing.T) {
+	dir := t.TempDir()
+	base := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(`{"choices":[]}`)),
+		}, nil
+	})
+
+	recorder := openai.NewClientWithConfig(openai.Config{
+		BaseURL:    "http://llm.local/v1",
+		HTTPClient: &http.Client{Transport: openai.NewCassetteTransport(openai.CassetteOptions{Mode: openai.CassetteModeRecord, Dir: dir}, base)},
+	})
+	if _, err := recorder.CreateChatCompletion(context.Background(), cassetteChatRequest("submit_report")); err != nil {
+		t.Fatalf("record request failed: %v", err)
+	}
+
+	replayer := openai.NewClientWithConfig(openai.Config{
+		BaseURL:    "http://llm.local/v1",
+		HTTPClient: &http.Client{Transport: openai.NewCassetteTransport(openai.CassetteOptions{Mode: openai.CassetteModeReplay, Dir: dir}, nil)},
+	})
+	if _, err := replayer.CreateChatCompletion(context.Background(), cassetteChatRequest("submit_report")); err != nil {
+		t.Fatalf("expected identical request to replay, got %v", err)
+	}
+	_, err := replayer.CreateChatCompletion(context.Background(), cassetteChatRequest("submit_other_report"))
+	if err == nil || !strings.Contains(err.Error(), "llm cassette miss") {
+		t.Fatalf("expected cassette miss for changed tool definition, got %v", err)
+	}
+}
+
+func TestCassette_ReplaysStreamingBody(t *testing.T) {
+	dir := t.TempDir()
+	streamBody := "data: {\"choices\":[{\"delta\":{\"content\":\"你\"}}]}\n\n" +
+		"data: {\"choices\":[{\"delta\":{\"content\":\"好\"}}]}\n\n" +
+		"data: [DONE]\n\n"
+	base := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": []string{"text/event-stream"}},
+			Body:       io.NopCloser(strings.NewReader(streamBody)),
+		}, nil
+	})
+	request := cassetteChatRequest("submit_report")
+	request.Stream = true
+
+	recorder := openai.NewClientWithConfig(openai.Config{
+		BaseURL:    "http://llm.local/v1",
+		HTTPClient: &http.Client{Transport: openai.NewCassetteTransport(openai.CassetteOptions{Mode: openai.CassetteModeAuto, Dir: dir}, base)},
+	})
+	stream, err := recorder.CreateChatCompletionStream(context.Background(), request)
+	if err != nil {
+		t.Fatalf("record stream failed: %v", err)
+	}
+	_ = stream.Close()
+
+	replayer := openai.NewClientWithConfig(openai.Config{
+		BaseURL:    "http://llm.local/v1",
+		HTTPClient: &http.Client{Transport: openai.NewCassetteTransport(openai.CassetteOptions{Mode: openai.CassetteModeReplay, Dir: dir}, nil)},
+	})
+	replayed, err := replayer.CreateChatCompletionStream(context.Background(), request)
+	if err != nil {
+		t.Fatalf("replay stream failed: %v", err)
+	}
+	defer replayed.Close()
+
+	var content strings.Builder
+	for {
+		chunk, err := replayed.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("recv failed: %v", err)
+		}
+		for _, choice := range chunk.Choices {
+			content.WriteString(choice.Delta.Content)
+		}
+	}
+	if content.String() != "你好" {
+		t.Fatalf("unexpected replayed stream content: %q", content.String())
+	}
+}
@@ -0,0 +1,298 @@
+package redaction
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// 内置的敏感信息类别。注册顺序即检测优先级：同一片段被多个检测器命中时保留先注册者的结果。
+const (
+	KindIDNumber = "id_number"
+	KindBankCard = "bank_card"
+	KindPhone    = "phone"
+	KindAddress  = "address"
+	KindName     = "name"
+)
+
+// Match 是检测器在文本中命中的一段敏感信息，Start/End 为字节偏移。
+type Match struct {
+	Start int
+	End   int
+}
+
+// Detector 描述一类敏感信息的检测方式。
+// Label 用于生成占位符（如 [电话号码_1]），Normalize 将命中片段归一为比较键，
+// 使同一号码的不同写法（空格、短横线分隔）映射到同一个占位符；为空时按原文比较。
+type Detector struct {
+	Kind      string
+	Label     string
+	Find      func(text string) []Match
+	Normalize func(value string) string
+}
+
+var (
+	detectorsMu sync.RWMutex
+	detectors   []Detector
+)
+
+func init() {
+	mustRegisterDetector(Detector{Kind: KindIDNumber, Label: "身份证号", Find: findDigitRuns(isIDNumber), Normalize: digitsOnly})
+	mustRegisterDetector(Detector{Kind: KindBankCard, Label: "银行卡号", Find: findDigitRuns(isBankCard), Normalize: digitsOnly})
+	mustRegisterDetector(Detector{Kind: KindPhone, Label: "电话号码", Find: findDigitRuns(isPhoneNumber), Normalize: normalizePhone})
+	mustRegisterDetector(Detector{Kind: KindAddress, Label: "地址", Find: findAddresses})
+	mustRegisterDetector(Detector{Kind: KindName, Label: "姓名", Find: findNames})
+}
+
+// RegisterDetector 追加一个敏感信息检测器；类别或标签为空、重复注册时返回错误。
+func RegisterDetector(detector Detector) error {
+	kind := strings.TrimSpace(detector.Kind)
+	label := strings.TrimSpace(detector.Label)
+	if kind == "" || label == "" {
+		return fmt.Errorf("redaction detector kind and label are required")
+	}
+	if detector.Find == nil {
+		return fmt.Errorf("redaction detector %q find func is nil", kind)
+	}
+	if strings.ContainsAny(label, "[]_") {
+		return fmt.Errorf("redaction detector %q label must not contain brackets or underscores", kind)
+	}
+
+	detectorsMu.Lock()
+	defer detectorsMu.Unlock()
+	for _, existing := range detectors {
+		if existing.Kind == kind {
+			return fmt.Errorf("redaction detector %q already registered", kind)
+		}
+		if existing.Label == label {
+			return fmt.Errorf("redaction detector label %q already registered", label)
+		}
+	}
+	detector.Kind = kind
+	detector.Label = label
+	detectors = append(detectors, detector)
+	return nil
+}
+
+func mustRegisterDetector(detector Detector) {
+	if err := RegisterDetector(detector); err != nil {
+		panic(err)
+	}
+}
+
+// DetectorKinds 按检测优先级返回已注册的类别。
+func DetectorKinds() []string {
+	detectorsMu.RLock()
+	defer detectorsMu.RUnlock()
+	kinds := make([]string, 0, len(detectors))
+	for _, detector := range detectors {
+		kinds = append(kinds, detector.Kind)
+	}
+	return kinds
+}
+
+func registeredDetectors() []Detector {
+	detectorsMu.RLock()
+	defer detectorsMu.RUnlock()
+	return append([]Detector{}, detectors...)
+}
+
+// digitRunPattern 匹配可能带 +、空格或短横线分隔的连续数字串，末位允许身份证校验码 X。
+var digitRunPattern = regexp.MustCompile(`\+?\d(?:[\d \-]*\d)?[Xx]?`)
+
+// findDigitRuns 返回命中 accept 的数字串；整串不满足时再按空白拆开逐段判断，
+// 覆盖“13800138000 13900139000”这类以空格并列的多个号码。
+func findDigitRuns(accept func(digits string) bool) func(string) []Match {
+	return func(text string) []Match {
+		matches := make([]Match, 0)
+		for _, loc := range digitRunPattern.FindAllStringIndex(text, -1) {
+			if !digitRunBoundary(text, loc[0], loc[1]) {
+				continue
+			}
+			run := text[loc[0]:loc[1]]
+			if accept(digitsOnly(run)) {
+				matches = append(matches, Match{Start: loc[0], End: loc[1]})
+				continue
+			}
+			offset := loc[0]
+			for _, part := range strings.Fields(run) {
+				start := offset + strings.Index(text[offset:loc[1]], part)
+				offset = start + len(part)
+				if accept(digitsOnly(part)) {
+					matches = append(matches, Match{Start: start, End: offset})
+				}
+			}
+		}
+		return matches
+	}
+}
+
+// digitRunBoundary 排除嵌在字母数字标识中的数字串（如订单号 A13800138000B 的一部分）。
+func digitRunBoundary(text string, start, end int) bool {
+	if start > 0 && isASCIIAlnum(text[start-1]) {
+		return false
+	}
+	return end >= len(text) || !isASCIIAlnum(text[end])
+}
+
+func isASCIIAlnum(b byte) bool {
+	return b >= '0' && b <= '9' || b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z'
+}
+
+func digitsOnly(value string) string {
+	var builder strings.Builder
+	for _, r := range value {
+		if r >= '0' && r <= '9' {
+			builder.WriteRune(r)
+		} else if r == 'x' || r == 'X' {
+			builder.WriteRune('X')
+		}
+	}
+	return builder.String()
+}
+
+// normalizePhone 去掉 +86/86 国家码，使带与不带国家码的同一手机号共用占位符。
+func normalizePhone(value string) string {
+	digits := digitsOnly(value)
+	if len(digits) == 13 && strings.HasPrefix(digits, "86") {
+		return digits[2:]
+	}
+	return digits
+}
+
+var idNumberWeights = []int{7, 9, 10, 5, 8, 4, 2, 1, 6, 3, 7, 9, 10, 5, 8, 4, 2}
+
+const idNumberCheckCodes = "10X98765432"
+
+// isIDNumber 校验 18 位身份证号（出生日期与 GB 11643 校验码）与 15 位旧版身份证号（出生日期）。
+func isIDNumber(digits string) bool {
+	switch len(digits) {
+	case 18:
+		if strings.Contains(digits[:17], "X") || !validBirthDate(digits[10:12], digits[12:14]) {
+			return false
+		}
+		sum := 0
+		for i, weight := range idNumberWeights {
+			sum += int(digits[i]-'0') * weight
+		}
+		return digits[17] == idNumberCheckCodes[sum%11]
+	case 15:
+		return !strings.Contains(digits, "X") && validBirthDate(digits[8:10], digits[10:12])
+	}
+	return false
+}
+
+func validBirthDate(month, day string) bool {
+	m := int(month[0]-'0')*10 + int(month[1]-'0')
+	d := int(day[0]-'0')*10 + int(day[1]-'0')
+	return m >= 1 && m <= 12 && d >= 1 && d <= 31
+}
+
+// isBankCard 校验 16-19 位且满足 Luhn 校验的银行卡号。
+func isBankCard(digits string) bool {
+	if len(digits) < 16 || len(digits) > 19 || strings.Contains(digits, "X") {
+		return false
+	}
+	sum := 0
+	double := false
+	for i := len(digits) - 1; i >= 0; i-- {
+		n := int(digits[i] - '0')
+		if double {
+			n *= 2
+			if n > 9 {
+				n -= 9
+			}
+		}
+		sum += n
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+var (
+	mobilePattern   = regexp.MustCompile(`^(?:86)?1[3-9]\d{9}$`)
+	landlinePattern = regexp.MustCompile(`^0[1-9]\d{1,2}\d{7,8}$`)
+)
+
+// isPhoneNumber 识别大陆手机号（可带 86 国家码）与带区号的固定电话。
+func isPhoneNumber(digits string) bool {
+	return mobilePattern.MatchString(digits) || landlinePattern.MatchString(digits)
+}
+
+const (
+	addressRegion = `(?:\p{Han}{2,7}(?:省|自治区|市|区|县|旗|镇|乡|街道))`
+	addressStreet = `\p{Han}{1,10}(?:路|街|大道|巷|弄|胡同)\d{1,5}号(?:院)?`
+	addressEstate = `\p{Han}{2,10}(?:小区|花园|公寓|大厦|新村|家园)`
+	addressUnit   = `(?:[\dA-Za-z]{1,5}(?:号楼|栋|幢|座|单元|层|楼|室|号))*`
+	addressBody   = addressRegion + `*(?:` + addressStreet + `(?:` + addressEstate + `)?|` + addressEstate + `[\dA-Za-z]{1,5}(?:号楼|栋|幢|座))` + addressUnit
+)
+
+var (
+	addressPattern         = regexp.MustCompile(addressBody)
+	addressAnchoredPattern = regexp.MustCompile(`^` + addressBody + `$`)
+)
+
+// addressCues 是地址前常见的引导词。正则会把引导词一并吞进行政区划前缀，命中后从最后一个引导词处截断。
+var addressCues = []string{"住在", "住址", "地址", "位于", "寄到", "送到", "家住", "来到", "在", "是", "到"}
+
+// findAddresses 识别“行政区划 + 道路门牌”或“小区 + 楼栋”形式的详细地址，仅有城市名不视为地址。
+func findAddresses(text string) []Match {
+	matches := make([]Match, 0)
+	for _, loc := range addressPattern.FindAllStringIndex(text, -1) {
+		start := loc[0] + addressCueEnd(text[loc[0]:loc[1]])
+		matches = append(matches, Match{Start: start, End: loc[1]})
+	}
+	return matches
+}
+
+// namePattern 以自我介绍、收款人等提示词为线索识别 2-3 字中文姓名；无提示词的裸姓名不做识别，避免误伤普通词语。
+var namePattern = regexp.MustCompile(`(?:我叫|名叫|叫做|姓名|户名|收款人|付款人|联系人|持卡人|开户人|真实姓名)(?:是|为)?[:：]?\s*(\p{Han}{2,3})`)
+
+// nameTrailingParticles 是常被 3 字匹配吞入的结尾虚词，命中时回退为 2 字姓名。
+const nameTrailingParticles = "的了是在和说给让吗呢"
+
+func findNames(text string) []Match {
+	matches := make([]Match, 0)
+	for _, loc := range namePattern.FindAllStringSubmatchIndex(text, -1) {
+		start, end := loc[2], loc[3]
+		runes := []rune(text[start:end])
+		if len(runes) == 3 && strings.ContainsRune(nameTrailingParticles, runes[2]) {
+			end -= len(string(runes[2]))
+		}
+		if !allHan(text[start:end]) {
+			continue
+		}
+		matches = append(matches, Match{Start: start, End: end})
+	}
+	return matches
+}
+
+func allHan(value string) bool {
+	for _, r := range value {
+		if !unicode.Is(unicode.Han, r) {
+			return false
+		}
+	}
+	return value != ""
+}
+
+// addressCueEnd 返回地址片段中最后一个引导词的结束偏移，要求截断后的剩余部分仍是完整地址；没有可截断的引导词时返回 0。
+func addressCueEnd(value string) int {
+	best := 0
+	for _, cue := range addressCues {
+		for offset := 0; ; {
+			index := strings.Index(value[offset:], cue)
+			if index < 0 {
+				break
+			}
+			end := offset + index + len(cue)
+			if end > best && addressAnchoredPattern.MatchString(value[end:]) {
+				best = end
+			}
+			offset = end
+		}
+	}
+	return best
+}
@@ -0,0 +1,307 @@
+package redaction
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// placeholderPattern 匹配 Redactor 生成的编号占位符，如 [电话号码_1]、[姓名_2]。
+var placeholderPattern = regexp.MustCompile(`\[([^\[\]_\s]+)_(\d+)\]`)
+
+// Redactor 在单个任务范围内把敏感信息替换为稳定占位符，并保留占位符到原文的可逆映射。
+// 同一原文（按检测器归一后比较）在任务内始终对应同一个占位符，编号按类别递增；
+// 已登记的姓名、地址等文本类原文在后续文本中即使缺少检测线索（如没有“我叫”的裸姓名）也会被替换。
+// nil Redactor 的方法均原样返回输入，调用方无需判空。
+type Redactor struct {
+	mu           sync.Mutex
+	originals    map[string]string
+	placeholders map[string]string
+	counters     map[string]int
+	// literals 记录按原文字面匹配的占位符（检测器未提供 Normalize 的文本类信息）。
+	literals map[string]bool
+}
+
+// NewRedactor 以已保存的映射（占位符 -> 原文）恢复任务的脱敏状态，mapping 为空时创建新的映射。
+func NewRedactor(mapping map[string]string) *Redactor {
+	r := &Redactor{
+		originals:    make(map[string]string, len(mapping)),
+		placeholders: make(map[string]string, len(mapping)),
+		counters:     map[string]int{},
+		literals:     map[string]bool{},
+	}
+	detectorsByLabel := map[string]Detector{}
+	for _, detector := range registeredDetectors() {
+		detectorsByLabel[detector.Label] = detector
+	}
+	for placeholder, original := range mapping {
+		parts := placeholderPattern.FindStringSubmatch(placeholder)
+		if len(parts) != 3 || parts[0] != placeholder || original == "" {
+			continue
+		}
+		r.originals[placeholder] = original
+		if index, err := strconv.Atoi(parts[2]); err == nil && index > r.counters[parts[1]] {
+			r.counters[parts[1]] = index
+		}
+		if detector, ok := detectorsByLabel[parts[1]]; ok {
+			r.placeholders[detector.Kind+"\x00"+normalizeValue(detector, original)] = placeholder
+			r.literals[placeholder] = detector.Normalize == nil
+		}
+	}
+	return r
+}
+
+type redactSpan struct {
+	start       int
+	end         int
+	placeholder string
+	detector    Detector
+}
+
+// Redact 将文本中的敏感信息替换为占位符，新出现的原文会追加到映射中。
+func (r *Redactor) Redact(text string) string {
+	if r == nil || strings.TrimSpace(text) == "" {
+		return text
+	}
+	detectors := registeredDetectors()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	spans := r.knownSpans(text)
+	for _, detector := range detectors {
+		for _, match := range detector.Find(text) {
+			if match.Start < 0 || match.End > len(text) || match.Start >= match.End {
+				continue
+			}
+			span := redactSpan{start: match.Start, end: match.End, detector: detector}
+			if !overlapsAny(spans, span) {
+				spans = append(spans, span)
+			}
+		}
+	}
+	if len(spans) == 0 {
+		return text
+	}
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+
+	var builder strings.Builder
+	last := 0
+	for _, span := range spans {
+		placeholder := span.placeholder
+		if placeholder == "" {
+			placeholder = r.placeholderFor(span.detector, text[span.start:span.end])
+		}
+		builder.WriteString(text[last:span.start])
+		builder.WriteString(placeholder)
+		last = span.end
+	}
+	builder.WriteString(text[last:])
+	return builder.String()
+}
+
+// RedactList 对列表中的每一项执行 Redact，返回新切片。
+func (r *Redactor) RedactList(items []string) []string {
+	if items == nil {
+		return nil
+	}
+	redacted := make([]string, len(items))
+	for i, item := range items {
+		redacted[i] = r.Redact(item)
+	}
+	return redacted
+}
+
+// Protect 显式登记一个已知类别的敏感值（如用户档案中的姓名）并返回其占位符；
+// 类别未注册或值为空时原样返回。登记后该值在后续 Redact 的文本中同样会被替换。
+func (r *Redactor) Protect(kind string, value string) string {
+	trimmed := strings.TrimSpace(value)
+	if r == nil || trimmed == "" {
+		return value
+	}
+	for _, detector := range registeredDetectors() {
+		if detector.Kind == strings.TrimSpace(kind) {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			return r.placeholderFor(detector, trimmed)
+		}
+	}
+	return value
+}
+
+// Restore 将文本中的占位符还原为原文，映射中不存在的占位符保持不变。
+func (r *Redactor) Restore(text string) string {
+	if r == nil || !strings.Contains(text, "[") {
+		return text
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.originals) == 0 {
+		return text
+	}
+	return placeholderPattern.ReplaceAllStringFunc(text, func(placeholder string) string {
+		if original, ok := r.originals[placeholder]; ok {
+			return original
+		}
+		return placeholder
+	})
+}
+
+// RestoreList 对列表中的每一项执行 Restore，返回新切片。
+func (r *Redactor) RestoreList(items []string) []string {
+	if items == nil {
+		return nil
+	}
+	restored := make([]string, len(items))
+	for i, item := range items {
+		restored[i] = r.Restore(item)
+	}
+	return restored
+}
+
+// Mapping 返回当前映射（占位符 -> 原文）的副本，用于持久化。
+func (r *Redactor) Mapping() map[string]string {
+	if r == nil {
+		return map[string]string{}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	mapping := make(map[string]string, len(r.originals))
+	for placeholder, original := range r.originals {
+		mapping[placeholder] = original
+	}
+	return mapping
+}
+
+// Strip 不可逆地移除文本中的敏感信息：先按检测器替换，再把所有编号占位符统一为不带编号的类别标记（如 [电话号码]），
+// 用于写入跨用户共享的数据（案件库、联网搜索关键词），避免暴露任务内的编号关系。
+func Strip(text string) string {
+	labels := map[string]bool{}
+	for _, detector := range registeredDetectors() {
+		labels[detector.Label] = true
+	}
+	redacted := NewRedactor(nil).Redact(text)
+	return placeholderPattern.ReplaceAllStringFunc(redacted, func(placeholder string) string {
+		label := placeholderPattern.FindStringSubmatch(placeholder)[1]
+		if !labels[label] {
+			return placeholder
+		}
+		return "[" + label + "]"
+	})
+}
+
+// PlaceholderKind 返回编号占位符（如 [电话号码_1]）所属的已注册类别，不是占位符或标签未注册时返回 false。
+func PlaceholderKind(placeholder string) (string, bool) {
+	parts := placeholderPattern.FindStringSubmatch(strings.TrimSpace(placeholder))
+	if len(parts) != 3 || parts[0] != strings.TrimSpace(placeholder) {
+		return "", false
+	}
+	for _, detector := range registeredDetectors() {
+		if detector.Label == parts[1] {
+			return detector.Kind, true
+		}
+	}
+	return "", false
+}
+
+// FindPlaceholders 返回文本中全部编号占位符的字节区间，供下游按占位符识别已脱敏的敏感信息。
+func FindPlaceholders(text string) []Match {
+	locs := placeholderPattern.FindAllStringIndex(text, -1)
+	matches := make([]Match, 0, len(locs))
+	for _, loc := range locs {
+		matches = append(matches, Match{Start: loc[0], End: loc[1]})
+	}
+	return matches
+}
+
+// placeholderFor 返回原文对应的占位符，首次出现时分配该类别的下一个编号；调用方需持有 r.mu。
+func (r *Redactor) placeholderFor(detector Detector, original string) string {
+	key := detector.Kind + "\x00" + normalizeValue(detector, original)
+	if placeholder, ok := r.placeholders[key]; ok {
+		return placeholder
+	}
+	r.counters[detector.Label]++
+	placeholder := "[" + detector.Label + "_" + strconv.Itoa(r.counters[detector.Label]) + "]"
+	r.placeholders[key] = placeholder
+	r.originals[placeholder] = original
+	r.literals[placeholder] = detector.Normalize == nil
+	return placeholder
+}
+
+// knownSpans 查找文本中已登记的文本类原文的出现位置，较长的原文优先，避免短姓名切开长地址；调用方需持有 r.mu。
+func (r *Redactor) knownSpans(text string) []redactSpan {
+	if len(r.originals) == 0 {
+		return nil
+	}
+	type known struct {
+		original    string
+		placeholder string
+	}
+	candidates := make([]known, 0, len(r.originals))
+	for placeholder, original := range r.originals {
+		if r.literals[placeholder] && utf8.RuneCountInString(original) >= 2 && strings.Contains(text, original) {
+			candidates = append(candidates, known{original: original, placeholder: placeholder})
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if len(candidates[i].original) != len(candidates[j].original) {
+			return len(candidates[i].original) > len(candidates[j].original)
+		}
+		return candidates[i].placeholder < candidates[j].placeholder
+	})
+
+	spans := make([]redactSpan, 0)
+	for _, candidate := range candidates {
+		for offset := 0; ; {
+			index := strings.Index(text[offset:], candidate.original)
+			if index < 0 {
+				break
+			}
+			span := redactSpan{start: offset + index, end: offset + index + len(candidate.original), placeholder: candidate.placeholder}
+			if !overlapsAny(spans, span) {
+				spans = append(spans, span)
+			}
+			offset = span.end
+		}
+	}
+	return spans
+}
+
+func overlapsAny(spans []redactSpan, candidate redactSpan) bool {
+	for _, span := range spans {
+		if candidate.start < span.end && span.start < candidate.end {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizeValue(detector Detector, value string) string {
+	if detector.Normalize != nil {
+		return detector.Normalize(value)
+	}
+	return strings.TrimSpace(value)
+}
+
+type contextKey struct{}
+
+// WithRedactor 将任务级 Redactor 绑定到 ctx，供子智能体、ASR 与工具在同一任务内共享占位符映射。
+func WithRedactor(ctx context.Context, redactor *Redactor) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, contextKey{}, redactor)
+}
+
+// FromContext 读取 ctx 中绑定的 Redactor，未绑定时返回 nil（其方法原样返回输入）。
+func FromContext(ctx context.Context) *Redactor {
+	if ctx == nil {
+		return nil
+	}
+	redactor, _ := ctx.Value(contextKey{}).(*Redactor)
+	return redactor
+}
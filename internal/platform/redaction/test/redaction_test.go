@@ -0,0 +1,90 @@
+package redaction_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"antifraud/internal/platform/redaction"
+)
+
+func TestRedactor_ReplacesPIIWithStablePlaceholdersAndRestores(t *testing.T) {
+	redactor := redaction.NewRedactor(nil)
+	text := "我叫张三，身份证11010519491231002X，卡号6222 0212 3456 7890 128，电话13800138000，家住北京市朝阳区建国路88号院3号楼2单元501室。"
+
+	redacted := redactor.Redact(text)
+	want := "我叫[姓名_1]，身份证[身份证号_1]，卡号[银行卡号_1]，电话[电话号码_1]，家住[地址_1]。"
+	if redacted != want {
+		t.Fatalf("unexpected redaction:\n got %s\nwant %s", redacted, want)
+	}
+	if restored := redactor.Restore(redacted); restored != text {
+		t.Fatalf("expected restore to original, got %s", restored)
+	}
+
+	again := redactor.Redact("张三再次提供电话 138-0013-8000 和新号码13900139000")
+	if again != "[姓名_1]再次提供电话 [电话号码_1] 和新号码[电话号码_2]" {
+		t.Fatalf("expected known values reuse placeholders, got %s", again)
+	}
+}
+
+func TestRedactor_SkipsNonPIINumbersAndIdentifiers(t *testing.T) {
+	redactor := redaction.NewRedactor(nil)
+	for _, text := range []string{
+		"对方要求转账5000元，订单号A13800138000B",
+		"验证码 123456，时间 2024-05-01",
+		"卡号 6222021234567890123 校验不通过",
+		"我是学生，住在北京市",
+	} {
+		if redacted := redactor.Redact(text); redacted != text {
+			t.Fatalf("expected %q unchanged, got %q", text, redacted)
+		}
+	}
+	if mapping := redactor.Mapping(); len(mapping) != 0 {
+		t.Fatalf("expected empty mapping, got %+v", mapping)
+	}
+}
+
+func TestNewRedactor_ResumesFromSavedMapping(t *testing.T) {
+	first := redaction.NewRedactor(nil)
+	first.Redact("收款人：李四，电话13800138000")
+	first.Protect(redaction.KindName, "王五")
+
+	resumed := redaction.NewRedactor(first.Mapping())
+	redacted := resumed.Redact("李四和王五都用过13800138000，另一个号码13900139000")
+	if redacted != "[姓名_1]和[姓名_2]都用过[电话号码_1]，另一个号码[电话号码_2]" {
+		t.Fatalf("expected resumed placeholders, got %s", redacted)
+	}
+}
+
+func TestStrip_RemovesPIIAndPlaceholderNumbers(t *testing.T) {
+	stripped := redaction.Strip("联系人王五，电话13800138000，转给[银行卡号_2]，参考[Image_1]")
+	if stripped != "联系人[姓名]，电话[电话号码]，转给[银行卡号]，参考[Image_1]" {
+		t.Fatalf("unexpected stripped text: %s", stripped)
+	}
+}
+
+func TestFromContext_NilRedactorPassesThrough(t *testing.T) {
+	if redactor := redaction.FromContext(context.Background()); redactor != nil {
+		t.Fatal("expected no redactor bound")
+	}
+	var redactor *redaction.Redactor
+	if got := redactor.Redact("电话13800138000"); got != "电话13800138000" {
+		t.Fatalf("expected nil redactor to pass through, got %s", got)
+	}
+
+	bound := redaction.NewRedactor(nil)
+	ctx := redaction.WithRedactor(context.Background(), bound)
+	if got := redaction.FromContext(ctx).Redact("电话13800138000"); !strings.Contains(got, "[电话号码_1]") {
+		t.Fatalf("expected bound redactor used, got %s", got)
+	}
+}
+
+func TestRegisterDetector_RejectsDuplicatesAndInvalidLabels(t *testing.T) {
+	find := func(string) []redaction.Match { return nil }
+	if err := redaction.RegisterDetector(redaction.Detector{Kind: redaction.KindPhone, Label: "号码", Find: find}); err == nil {
+		t.Fatal("expected duplicate kind rejected")
+	}
+	if err := redaction.RegisterDetector(redaction.Detector{Kind: "plate", Label: "车牌_号", Find: find}); err == nil {
+		t.Fatal("expected label with underscore rejected")
+	}
+}
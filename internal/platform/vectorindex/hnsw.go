@@ -0,0 +1,525 @@
+package vectorindex
+
+import (
+	"container/heap"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	defaultM              = 16
+	defaultEfConstruction = 200
+	defaultEfSearch       = 64
+	// compactTombstoneRatio 是墓碑节点占比上限，超过后整体重建，避免已删除节点拖慢检索。
+	compactTombstoneRatio = 0.25
+)
+
+// Options 定义 HNSW 图参数，零值字段使用默认值。
+type Options struct {
+	// M 为每层每个节点的邻居上限（第 0 层为 2M）。
+	M int
+	// EfConstruction 为插入时的候选集大小，越大建图越慢、召回越高。
+	EfConstruction int
+	// EfSearch 为检索时的最小候选集大小。
+	EfSearch int
+	// Seed 为层级随机数种子，固定种子便于测试复现。
+	Seed int64
+}
+
+// Hit 是一条检索结果，Similarity 为余弦相似度。
+type Hit struct {
+	ID         string
+	Similarity float64
+}
+
+// HNSW 是进程内的分层可导航小世界图索引，按余弦相似度检索。
+// 设计说明：
+// 1) 写入时统一做 L2 归一化，相似度即点积，与业务侧暴力扫描的计算口径一致；
+// 2) 删除采用墓碑标记，墓碑占比超过阈值后整体重建，保证图连通性；
+// 3) 检索支持 accept 过滤回调；过滤后结果不足时逐步放大候选集，最终退化为精确扫描，不会少返回。
+type HNSW struct {
+	mu sync.RWMutex
+
+	m              int
+	maxM0          int
+	efConstruction int
+	efSearch       int
+	levelMult      float64
+	rng            *rand.Rand
+
+	nodes      []*hnswNode
+	ids        map[string]int
+	entry      int
+	maxLevel   int
+	tombstones int
+}
+
+type hnswNode struct {
+	id        string
+	vector    []float64
+	level     int
+	neighbors [][]int
+	deleted   bool
+}
+
+// NewHNSW 创建空索引。
+func NewHNSW(options Options) *HNSW {
+	m := options.M
+	if m <= 1 {
+		m = defaultM
+	}
+	efConstruction := options.EfConstruction
+	if efConstruction <= 0 {
+		efConstruction = defaultEfConstruction
+	}
+	efSearch := options.EfSearch
+	if efSearch <= 0 {
+		efSearch = defaultEfSearch
+	}
+	seed := options.Seed
+	if seed == 0 {
+		seed = 1
+	}
+	return &HNSW{
+		m:              m,
+		maxM0:          m * 2,
+		efConstruction: efConstruction,
+		efSearch:       efSearch,
+		levelMult:      1 / math.Log(float64(m)),
+		rng:            rand.New(rand.NewSource(seed)),
+		ids:            map[string]int{},
+		entry:          -1,
+	}
+}
+
+// Len 返回有效（未删除）向量数量。
+func (h *HNSW) Len() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.ids)
+}
+
+// Contains 判断 id 是否在索引中。
+func (h *HNSW) Contains(id string) bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	_, ok := h.ids[strings.TrimSpace(id)]
+	return ok
+}
+
+// Upsert 写入或替换一条向量；向量为空或全零时视为删除并返回 false。
+func (h *HNSW) Upsert(id string, vector []float64) bool {
+	trimmedID := strings.TrimSpace(id)
+	if trimmedID == "" {
+		return false
+	}
+	normalized, ok := normalize(vector)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.removeLocked(trimmedID)
+	if !ok {
+		h.compactIfNeededLocked()
+		return false
+	}
+	h.insertLocked(trimmedID, normalized)
+	h.compactIfNeededLocked()
+	return true
+}
+
+// Remove 删除一条向量，返回是否存在。
+func (h *HNSW) Remove(id string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	removed := h.removeLocked(strings.TrimSpace(id))
+	if removed {
+		h.compactIfNeededLocked()
+	}
+	return removed
+}
+
+// Search 返回与 query 最相似的 k 条结果（相似度降序）；accept 为空表示不过滤。
+func (h *HNSW) Search(query []float64, k int, accept func(id string) bool) []Hit {
+	normalized, ok := normalize(query)
+	if !ok || k <= 0 {
+		return []Hit{}
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	if len(h.ids) == 0 {
+		return []Hit{}
+	}
+	ef := h.efSearch
+	if ef < k {
+		ef = k
+	}
+	for {
+		hits := h.searchLocked(normalized, k, ef, accept)
+		if len(hits) >= k || ef >= len(h.nodes) {
+			if len(hits) < k && accept != nil {
+				// 过滤条件过窄时图上可达的合格节点不足，退化为精确扫描保证结果完整。
+				return h.exactLocked(normalized, k, accept)
+			}
+			return hits
+		}
+		ef *= 4
+	}
+}
+
+// Exact 对全部向量做精确扫描，供召回自检与小候选集过滤使用。
+func (h *HNSW) Exact(query []float64, k int, accept func(id string) bool) []Hit {
+	normalized, ok := normalize(query)
+	if !ok || k <= 0 {
+		return []Hit{}
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.exactLocked(normalized, k, accept)
+}
+
+// Vector 返回 id 对应的归一化向量副本。
+func (h *HNSW) Vector(id string) ([]float64, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	index, ok := h.ids[strings.TrimSpace(id)]
+	if !ok {
+		return nil, false
+	}
+	return append([]float64{}, h.nodes[index].vector...), true
+}
+
+// IDs 返回全部有效 id（升序）。
+func (h *HNSW) IDs() []string {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	result := make([]string, 0, len(h.ids))
+	for id := range h.ids {
+		result = append(result, id)
+	}
+	sort.Strings(result)
+	return result
+}
+
+func (h *HNSW) insertLocked(id string, vector []float64) {
+	level := h.randomLevel()
+	node := &hnswNode{id: id, vector: vector, level: level, neighbors: make([][]int, level+1)}
+	index := len(h.nodes)
+	h.nodes = append(h.nodes, node)
+	h.ids[id] = index
+
+	if h.entry < 0 {
+		h.entry = index
+		h.maxLevel = level
+		return
+	}
+
+	entry := h.entry
+	for layer := h.maxLevel; layer > level; layer-- {
+		entry = h.greedyClosest(vector, entry, layer)
+	}
+
+	entries := []int{entry}
+	for layer := minInt(level, h.maxLevel); layer >= 0; layer-- {
+		candidates := h.searchLayer(vector, entries, h.efConstruction, layer)
+		limit := h.m
+		if layer == 0 {
+			limit = h.maxM0
+		}
+		selected := selectClosest(candidates, h.m)
+		node.neighbors[layer] = selected
+		for _, neighbor := range selected {
+			h.connect(neighbor, index, layer, limit)
+		}
+		entries = entries[:0]
+		for _, candidate := range candidates {
+			entries = append(entries, candidate.index)
+		}
+	}
+
+	if level > h.maxLevel {
+		h.maxLevel = level
+		h.entry = index
+	}
+}
+
+// connect 为 from 节点追加一条指向 to 的边，超过上限时只保留最相近的邻居。
+func (h *HNSW) connect(from int, to int, layer int, limit int) {
+	node := h.nodes[from]
+	if layer >= len(node.neighbors) {
+		return
+	}
+	node.neighbors[layer] = append(node.neighbors[layer], to)
+	if len(node.neighbors[layer]) <= limit {
+		return
+	}
+	scored := make([]candidate, 0, len(node.neighbors[layer]))
+	for _, neighbor := range node.neighbors[layer] {
+		scored = append(scored, candidate{index: neighbor, similarity: dot(node.vector, h.nodes[neighbor].vector)})
+	}
+	node.neighbors[layer] = selectClosest(scored, limit)
+}
+
+func (h *HNSW) removeLocked(id string) bool {
+	index, ok := h.ids[id]
+	if !ok {
+		return false
+	}
+	delete(h.ids, id)
+	h.nodes[index].deleted = true
+	h.tombstones++
+	return true
+}
+
+// compactIfNeededLocked 在墓碑过多时用剩余节点重建整张图。
+func (h *HNSW) compactIfNeededLocked() {
+	if len(h.ids) == 0 {
+		h.nodes = nil
+		h.entry = -1
+		h.maxLevel = 0
+		h.tombstones = 0
+		return
+	}
+	if float64(h.tombstones) <= float64(len(h.nodes))*compactTombstoneRatio {
+		return
+	}
+	live := make([]*hnswNode, 0, len(h.ids))
+	for _, node := range h.nodes {
+		if !node.deleted {
+			live = append(live, node)
+		}
+	}
+	h.nodes = nil
+	h.ids = map[string]int{}
+	h.entry = -1
+	h.maxLevel = 0
+	h.tombstones = 0
+	for _, node := range live {
+		h.insertLocked(node.id, node.vector)
+	}
+}
+
+func (h *HNSW) randomLevel() int {
+	return int(math.Floor(-math.Log(1-h.rng.Float64()) * h.levelMult))
+}
+
+func (h *HNSW) greedyClosest(query []float64, entry int, layer int) int {
+	current := entry
+	best := dot(query, h.nodes[current].vector)
+	for changed := true; changed; {
+		changed = false
+		for _, neighbor := range h.nodes[current].neighborsAt(layer) {
+			similarity := dot(query, h.nodes[neighbor].vector)
+			if similarity > best {
+				best = similarity
+				current = neighbor
+				changed = true
+			}
+		}
+	}
+	return current
+}
+
+// searchLayer 是 HNSW 论文中的 SEARCH-LAYER：维护候选最大堆与结果最小堆，返回按相似度降序的 ef 个最近节点（含墓碑）。
+func (h *HNSW) searchLayer(query []float64, entries []int, ef int, layer int) []candidate {
+	visited := make(map[int]struct{}, ef*4)
+	candidates := &maxHeap{}
+	results := &minHeap{}
+	for _, entry := range entries {
+		if _, seen := visited[entry]; seen {
+			continue
+		}
+		visited[entry] = struct{}{}
+		item := candidate{index: entry, similarity: dot(query, h.nodes[entry].vector)}
+		heap.Push(candidates, item)
+		heap.Push(results, item)
+	}
+	for results.Len() > ef {
+		heap.Pop(results)
+	}
+
+	for candidates.Len() > 0 {
+		current := heap.Pop(candidates).(candidate)
+		if results.Len() >= ef && current.similarity < (*results)[0].similarity {
+			break
+		}
+		for _, neighbor := range h.nodes[current.index].neighborsAt(layer) {
+			if _, seen := visited[neighbor]; seen {
+				continue
+			}
+			visited[neighbor] = struct{}{}
+			similarity := dot(query, h.nodes[neighbor].vector)
+			if results.Len() < ef || similarity > (*results)[0].similarity {
+				item := candidate{index: neighbor, similarity: similarity}
+				heap.Push(candidates, item)
+				heap.Push(results, item)
+				if results.Len() > ef {
+					heap.Pop(results)
+				}
+			}
+		}
+	}
+
+	ordered := make([]candidate, results.Len())
+	for i := len(ordered) - 1; i >= 0; i-- {
+		ordered[i] = heap.Pop(results).(candidate)
+	}
+	return ordered
+}
+
+func (h *HNSW) searchLocked(query []float64, k int, ef int, accept func(id string) bool) []Hit {
+	entry := h.entry
+	for layer := h.maxLevel; layer > 0; layer-- {
+		entry = h.greedyClosest(query, entry, layer)
+	}
+	candidates := h.searchLayer(query, []int{entry}, ef, 0)
+	hits := make([]Hit, 0, len(candidates))
+	for _, item := range candidates {
+		node := h.nodes[item.index]
+		if node.deleted {
+			continue
+		}
+		if accept != nil && !accept(node.id) {
+			continue
+		}
+		hits = append(hits, Hit{ID: node.id, Similarity: clamp(item.similarity)})
+	}
+	return topHits(hits, k)
+}
+
+func (h *HNSW) exactLocked(query []float64, k int, accept func(id string) bool) []Hit {
+	hits := make([]Hit, 0, len(h.ids))
+	for id, index := range h.ids {
+		if accept != nil && !accept(id) {
+			continue
+		}
+		hits = append(hits, Hit{ID: id, Similarity: clamp(dot(query, h.nodes[index].vector))})
+	}
+	return topHits(hits, k)
+}
+
+// topHits 按相似度降序、相似度相同按 id 升序取前 k 条，保证 ANN 与精确扫描的并列结果一致。
+func topHits(hits []Hit, k int) []Hit {
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Similarity != hits[j].Similarity {
+			return hits[i].Similarity > hits[j].Similarity
+		}
+		return hits[i].ID < hits[j].ID
+	})
+	if len(hits) > k {
+		hits = hits[:k]
+	}
+	return hits
+}
+
+func (n *hnswNode) neighborsAt(layer int) []int {
+	if layer >= len(n.neighbors) {
+		return nil
+	}
+	return n.neighbors[layer]
+}
+
+type candidate struct {
+	index      int
+	similarity float64
+}
+
+// selectClosest 按相似度取前 limit 个节点。
+func selectClosest(items []candidate, limit int) []int {
+	sorted := append([]candidate{}, items...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].similarity > sorted[j].similarity
+	})
+	if len(sorted) > limit {
+		sorted = sorted[:limit]
+	}
+	result := make([]int, 0, len(sorted))
+	for _, item := range sorted {
+		result = append(result, item.index)
+	}
+	return result
+}
+
+type maxHeap []candidate
+
+func (h maxHeap) Len() int            { return len(h) }
+func (h maxHeap) Less(i, j int) bool  { return h[i].similarity > h[j].similarity }
+func (h maxHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *maxHeap) Push(x interface{}) { *h = append(*h, x.(candidate)) }
+func (h *maxHeap) Pop() interface{} {
+	old := *h
+	item := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return item
+}
+
+type minHeap []candidate
+
+func (h minHeap) Len() int            { return len(h) }
+func (h minHeap) Less(i, j int) bool  { return h[i].similarity < h[j].similarity }
+func (h minHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *minHeap) Push(x interface{}) { *h = append(*h, x.(candidate)) }
+func (h *minHeap) Pop() interface{} {
+	old := *h
+	item := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return item
+}
+
+// normalize 做 L2 归一化，NaN/Inf 按 0 处理；全零向量返回 false。
+func normalize(vector []float64) ([]float64, bool) {
+	if len(vector) == 0 {
+		return nil, false
+	}
+	normalized := make([]float64, len(vector))
+	var norm2 float64
+	for i, value := range vector {
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			value = 0
+		}
+		normalized[i] = value
+		norm2 += value * value
+	}
+	if norm2 <= 0 {
+		return nil, false
+	}
+	norm := math.Sqrt(norm2)
+	for i := range normalized {
+		normalized[i] /= norm
+	}
+	return normalized, true
+}
+
+// dot 按较短向量的维度计算点积，与业务侧 cosineSimilarityNormalized 口径一致。
+func dot(left []float64, right []float64) float64 {
+	n := minInt(len(left), len(right))
+	var sum float64
+	for i := 0; i < n; i++ {
+		sum += left[i] * right[i]
+	}
+	return sum
+}
+
+func clamp(similarity float64) float64 {
+	if similarity > 1 {
+		return 1
+	}
+	if similarity < -1 {
+		return -1
+	}
+	return similarity
+}
+
+func minInt(a int, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
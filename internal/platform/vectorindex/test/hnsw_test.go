@@ -0,0 +1,128 @@
+package vectorindex_test
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"antifraud/internal/platform/vectorindex"
+)
+
+func randomVectors(count int, dimension int, seed int64) map[string][]float64 {
+	rng := rand.New(rand.NewSource(seed))
+	vectors := make(map[string][]float64, count)
+	for i := 0; i < count; i++ {
+		vector := make([]float64, dimension)
+		for j := range vector {
+			vector[j] = rng.NormFloat64()
+		}
+		vectors[fmt.Sprintf("CASE-%04d", i)] = vector
+	}
+	return vectors
+}
+
+func buildIndex(vectors map[string][]float64) *vectorindex.HNSW {
+	index := vectorindex.NewHNSW(vectorindex.Options{Seed: 42})
+	for id, vector := range vectors {
+		index.Upsert(id, vector)
+	}
+	return index
+}
+
+func recallAt(index *vectorindex.HNSW, queries [][]float64, k int, accept func(string) bool) float64 {
+	var total float64
+	for _, query := range queries {
+		exact := index.Exact(query, k, accept)
+		approx := index.Search(query, k, accept)
+		found := map[string]struct{}{}
+		for _, hit := range approx {
+			found[hit.ID] = struct{}{}
+		}
+		matched := 0
+		for _, hit := range exact {
+			if _, ok := found[hit.ID]; ok {
+				matched++
+			}
+		}
+		total += float64(matched) / float64(len(exact))
+	}
+	return total / float64(len(queries))
+}
+
+func TestHNSW_RecallAgainstExactScan(t *testing.T) {
+	vectors := randomVectors(2000, 32, 7)
+	index := buildIndex(vectors)
+	queries := make([][]float64, 0, 50)
+	for _, vector := range randomVectors(50, 32, 99) {
+		queries = append(queries, vector)
+	}
+
+	if recall := recallAt(index, queries, 10, nil); recall < 0.95 {
+		t.Fatalf("expected recall@10 >= 0.95, got %.3f", recall)
+	}
+}
+
+func TestHNSW_FilterNeverReturnsRejectedIDsOrTooFewResults(t *testing.T) {
+	index := buildIndex(randomVectors(600, 16, 3))
+	accept := func(id string) bool { return strings.HasSuffix(id, "7") }
+
+	hits := index.Search(randomVectors(1, 16, 11)["CASE-0000"], 10, accept)
+	if len(hits) != 10 {
+		t.Fatalf("expected 10 filtered hits, got %d", len(hits))
+	}
+	for i, hit := range hits {
+		if !accept(hit.ID) {
+			t.Fatalf("filter rejected id returned: %s", hit.ID)
+		}
+		if i > 0 && hit.Similarity > hits[i-1].Similarity {
+			t.Fatalf("hits not sorted by similarity: %+v", hits)
+		}
+	}
+
+	rare := func(id string) bool { return id == "CASE-0123" || id == "CASE-0456" }
+	if got := index.Search(randomVectors(1, 16, 12)["CASE-0000"], 5, rare); len(got) != 2 {
+		t.Fatalf("expected narrow filter to fall back to exact scan, got %+v", got)
+	}
+}
+
+func TestHNSW_RemoveAndReplaceStayConsistent(t *testing.T) {
+	vectors := randomVectors(300, 16, 5)
+	index := buildIndex(vectors)
+
+	removed := 0
+	for id := range vectors {
+		if removed == 150 {
+			break
+		}
+		if !index.Remove(id) {
+			t.Fatalf("expected %s to be removed", id)
+		}
+		delete(vectors, id)
+		removed++
+	}
+	if index.Len() != len(vectors) {
+		t.Fatalf("unexpected index size after removal: got=%d want=%d", index.Len(), len(vectors))
+	}
+
+	var target string
+	for id := range vectors {
+		target = id
+		break
+	}
+	replacement := randomVectors(1, 16, 77)["CASE-0000"]
+	index.Upsert(target, replacement)
+	hits := index.Search(replacement, 1, nil)
+	if len(hits) != 1 || hits[0].ID != target || hits[0].Similarity < 0.999 {
+		t.Fatalf("expected replaced vector to be found first, got %+v", hits)
+	}
+
+	for _, hit := range index.Search(replacement, 50, nil) {
+		if _, ok := vectors[hit.ID]; !ok {
+			t.Fatalf("removed id returned by search: %s", hit.ID)
+		}
+	}
+	if index.Upsert("CASE-ZERO", []float64{0, 0, 0}) {
+		t.Fatal("zero vector should not be indexed")
+	}
+}
@@ -0,0 +1,128 @@
+package web_search_system
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"antifraud/internal/platform/config"
+)
+
+// BingClient 调用 Bing Web Search v7 及其兼容接口。
+type BingClient struct {
+	apiKey     string
+	baseURL    string
+	market     string
+	httpClient *http.Client
+}
+
+type bingSearchResponse struct {
+	QueryContext struct {
+		OriginalQuery string `json:"originalQuery"`
+	} `json:"queryContext"`
+	WebPages struct {
+		Value []struct {
+			Name            string `json:"name"`
+			URL             string `json:"url"`
+			Snippet         string `json:"snippet"`
+			DatePublished   string `json:"datePublished"`
+			DateLastCrawled string `json:"dateLastCrawled"`
+		} `json:"value"`
+	} `json:"webPages"`
+}
+
+// NewBingClient 根据配置创建默认 HTTP 客户端。
+func NewBingClient(cfg config.BingSearchConfig) *BingClient {
+	timeoutMS := cfg.TimeoutMS
+	if timeoutMS <= 0 {
+		timeoutMS = defaultTimeoutMS
+	}
+	return NewBingClientWithHTTPClient(cfg, &http.Client{Timeout: time.Duration(timeoutMS) * time.Millisecond})
+}
+
+// NewBingClientWithHTTPClient 允许测试注入自定义 HTTP 客户端。
+func NewBingClientWithHTTPClient(cfg config.BingSearchConfig, httpClient *http.Client) *BingClient {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: defaultTimeoutMS * time.Millisecond}
+	}
+	baseURL := strings.TrimRight(strings.TrimSpace(cfg.BaseURL), "/")
+	if baseURL == "" {
+		baseURL = "https://api.bing.microsoft.com/v7.0"
+	}
+	return &BingClient{
+		apiKey:     strings.TrimSpace(cfg.APIKey),
+		baseURL:    baseURL,
+		market:     strings.TrimSpace(cfg.Market),
+		httpClient: httpClient,
+	}
+}
+
+// Search 执行单次 Bing 网页搜索；Bing 不返回摘要答案，Answer 为空。
+func (c *BingClient) Search(ctx context.Context, query string, maxResults int) (SearchResponse, error) {
+	trimmedQuery := strings.TrimSpace(query)
+	if trimmedQuery == "" {
+		return SearchResponse{}, fmt.Errorf("query is empty")
+	}
+	if c.apiKey == "" {
+		return SearchResponse{}, fmt.Errorf("bing search api key is empty")
+	}
+	if maxResults <= 0 {
+		maxResults = defaultMaxResults
+	}
+
+	params := url.Values{}
+	params.Set("q", trimmedQuery)
+	params.Set("count", strconv.Itoa(maxResults))
+	params.Set("responseFilter", "Webpages")
+	if c.market != "" {
+		params.Set("mkt", c.market)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/search?"+params.Encode(), nil)
+	if err != nil {
+		return SearchResponse{}, fmt.Errorf("build bing request failed: %w", err)
+	}
+	req.Header.Set("Ocp-Apim-Subscription-Key", c.apiKey)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return SearchResponse{}, fmt.Errorf("send bing request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return SearchResponse{}, fmt.Errorf("read bing response failed: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return SearchResponse{}, fmt.Errorf("bing search failed, status=%d, body=%s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var decoded bingSearchResponse
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		return SearchResponse{}, fmt.Errorf("decode bing response failed: %w", err)
+	}
+
+	results := make([]SearchResult, 0, len(decoded.WebPages.Value))
+	for _, item := range decoded.WebPages.Value {
+		if len(results) >= maxResults {
+			break
+		}
+		results = append(results, SearchResult{
+			Title:         item.Name,
+			URL:           item.URL,
+			Content:       item.Snippet,
+			PublishedDate: firstNonEmpty(item.DatePublished, item.DateLastCrawled),
+		})
+	}
+	return SearchResponse{
+		Query:   firstNonEmpty(decoded.QueryContext.OriginalQuery, trimmedQuery),
+		Results: results,
+	}, nil
+}
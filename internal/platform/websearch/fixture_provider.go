@@ -0,0 +1,74 @@
+package web_search_system
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// FixtureProvider 从本地固定数据返回搜索结果，不访问网络，用于测试与离线演示。
+// 查询按去首尾空格、忽略大小写精确匹配；未命中时返回 fallback，fallback 为空则返回空结果。
+type FixtureProvider struct {
+	responses map[string]SearchResponse
+	fallback  *SearchResponse
+}
+
+// fixtureFile 是 fixture 文件格式：{"responses": {"查询": {...}}, "default": {...}}。
+type fixtureFile struct {
+	Responses map[string]SearchResponse `json:"responses"`
+	Default   *SearchResponse           `json:"default"`
+}
+
+// NewFixtureProvider 使用内存数据创建 fixture provider。
+func NewFixtureProvider(responses map[string]SearchResponse, fallback *SearchResponse) *FixtureProvider {
+	normalized := make(map[string]SearchResponse, len(responses))
+	for query, response := range responses {
+		normalized[fixtureKey(query)] = response
+	}
+	return &FixtureProvider{responses: normalized, fallback: fallback}
+}
+
+// LoadFixtureProvider 从 JSON 文件加载 fixture provider；path 为空时所有查询返回空结果。
+func LoadFixtureProvider(path string) (*FixtureProvider, error) {
+	trimmedPath := strings.TrimSpace(path)
+	if trimmedPath == "" {
+		return NewFixtureProvider(nil, nil), nil
+	}
+	raw, err := os.ReadFile(trimmedPath)
+	if err != nil {
+		return nil, fmt.Errorf("read web search fixture failed: %w", err)
+	}
+	var decoded fixtureFile
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		return nil, fmt.Errorf("decode web search fixture failed: %w", err)
+	}
+	return NewFixtureProvider(decoded.Responses, decoded.Default), nil
+}
+
+// Search 返回与查询匹配的固定结果。
+func (p *FixtureProvider) Search(_ context.Context, query string, maxResults int) (SearchResponse, error) {
+	trimmedQuery := strings.TrimSpace(query)
+	if trimmedQuery == "" {
+		return SearchResponse{}, fmt.Errorf("query is empty")
+	}
+
+	response, ok := p.responses[fixtureKey(trimmedQuery)]
+	if !ok {
+		if p.fallback == nil {
+			return SearchResponse{Query: trimmedQuery, Results: []SearchResult{}}, nil
+		}
+		response = *p.fallback
+	}
+	response = cloneResponse(response)
+	response.Query = firstNonEmpty(response.Query, trimmedQuery)
+	if maxResults > 0 && len(response.Results) > maxResults {
+		response.Results = response.Results[:maxResults]
+	}
+	return response, nil
+}
+
+func fixtureKey(query string) string {
+	return strings.ToLower(strings.TrimSpace(query))
+}
@@ -0,0 +1,86 @@
+package web_search_system
+
+import (
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var htmlTagPattern = regexp.MustCompile(`<[^>]*>`)
+
+// publishedDateLayouts 覆盖各 provider 常见的发布时间格式，统一输出为 2006-01-02。
+var publishedDateLayouts = []string{
+	time.RFC3339Nano,
+	time.RFC3339,
+	"2006-01-02T15:04:05.0000000Z",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+	time.RFC1123Z,
+	time.RFC1123,
+	"Mon, 02 Jan 2006 15:04:05 GMT",
+}
+
+// normalizeResponse 统一不同 provider 的结果：去除 HTML 标记、裁剪长度、丢弃无链接条目、
+// 按链接去重、补齐来源域名并规范发布时间。
+func normalizeResponse(response SearchResponse, query string) SearchResponse {
+	normalized := SearchResponse{
+		Query:    firstNonEmpty(response.Query, query),
+		Answer:   truncateRunes(cleanText(response.Answer), maxAnswerRunes),
+		Provider: response.Provider,
+		Results:  make([]SearchResult, 0, len(response.Results)),
+	}
+	seen := map[string]struct{}{}
+	for _, result := range response.Results {
+		rawURL := strings.TrimSpace(result.URL)
+		parsed, err := url.Parse(rawURL)
+		if rawURL == "" || err != nil || parsed.Hostname() == "" {
+			continue
+		}
+		key := dedupeKey(parsed)
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+
+		normalized.Results = append(normalized.Results, SearchResult{
+			Title:         cleanText(result.Title),
+			URL:           rawURL,
+			Content:       truncateRunes(cleanText(result.Content), maxContentRunes),
+			Score:         result.Score,
+			PublishedDate: normalizePublishedDate(result.PublishedDate),
+			Source:        strings.TrimPrefix(strings.ToLower(parsed.Hostname()), "www."),
+		})
+	}
+	return normalized
+}
+
+func cleanText(input string) string {
+	stripped := htmlTagPattern.ReplaceAllString(input, "")
+	return strings.Join(strings.Fields(html.UnescapeString(stripped)), " ")
+}
+
+func dedupeKey(parsed *url.URL) string {
+	host := strings.TrimPrefix(strings.ToLower(parsed.Hostname()), "www.")
+	path := strings.TrimRight(parsed.EscapedPath(), "/")
+	key := host + path
+	if parsed.RawQuery != "" {
+		key += "?" + parsed.RawQuery
+	}
+	return key
+}
+
+func normalizePublishedDate(raw string) string {
+	trimmed := strings.TrimSpace(raw)
+	if trimmed == "" {
+		return ""
+	}
+	for _, layout := range publishedDateLayouts {
+		if parsed, err := time.Parse(layout, trimmed); err == nil {
+			return parsed.Format("2006-01-02")
+		}
+	}
+	return trimmed
+}
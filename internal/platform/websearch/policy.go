@@ -0,0 +1,268 @@
+package web_search_system
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// policySearcher 在具体 provider 外层统一处理缓存、限流、结果规范化与域名过滤。
+type policySearcher struct {
+	name     string
+	provider Searcher
+	limiter  *rateLimiter
+	cache    *responseCache
+	cacheTTL time.Duration
+	filter   domainFilter
+}
+
+// Search 命中缓存时不占用限流额度；配置了域名名单时按上限取回结果，过滤后再裁剪到 maxResults。
+func (s *policySearcher) Search(ctx context.Context, query string, maxResults int) (SearchResponse, error) {
+	trimmedQuery := strings.TrimSpace(query)
+	if trimmedQuery == "" {
+		return SearchResponse{}, fmt.Errorf("query is empty")
+	}
+	if maxResults <= 0 {
+		maxResults = defaultMaxResults
+	}
+	if maxResults > maxAllowedResults {
+		maxResults = maxAllowedResults
+	}
+	fetchResults := maxResults
+	if s.filter.active() {
+		fetchResults = maxAllowedResults
+	}
+
+	cacheKey := fmt.Sprintf("%s|%d|%s", s.name, fetchResults, strings.ToLower(trimmedQuery))
+	response, ok := s.cache.get(cacheKey)
+	if !ok {
+		if err := s.limiter.wait(ctx); err != nil {
+			return SearchResponse{}, fmt.Errorf("web search provider %s rate limited: %w", s.name, err)
+		}
+		fetched, err := s.provider.Search(ctx, trimmedQuery, fetchResults)
+		if err != nil {
+			return SearchResponse{}, err
+		}
+		response = normalizeResponse(fetched, trimmedQuery)
+		s.cache.set(cacheKey, response, s.cacheTTL)
+	}
+
+	results := make([]SearchResult, 0, maxResults)
+	for _, result := range response.Results {
+		if !s.filter.allows(result.URL) {
+			continue
+		}
+		results = append(results, result)
+		if len(results) >= maxResults {
+			break
+		}
+	}
+	response.Results = results
+	response.Provider = s.name
+	return response, nil
+}
+
+// domainFilter 按主机名匹配域名名单，名单中的域名同时覆盖其子域名。
+type domainFilter struct {
+	allow []string
+	deny  []string
+}
+
+func newDomainFilter(allow []string, deny []string) domainFilter {
+	return domainFilter{allow: lowerAll(allow), deny: lowerAll(deny)}
+}
+
+func (f domainFilter) active() bool {
+	return len(f.allow) > 0 || len(f.deny) > 0
+}
+
+func (f domainFilter) allows(rawURL string) bool {
+	if !f.active() {
+		return true
+	}
+	parsed, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || parsed.Hostname() == "" {
+		return false
+	}
+	host := strings.ToLower(parsed.Hostname())
+	for _, domain := range f.deny {
+		if matchesDomain(host, domain) {
+			return false
+		}
+	}
+	if len(f.allow) == 0 {
+		return true
+	}
+	for _, domain := range f.allow {
+		if matchesDomain(host, domain) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesDomain(host string, domain string) bool {
+	return host == domain || strings.HasSuffix(host, "."+domain)
+}
+
+func lowerAll(values []string) []string {
+	lowered := make([]string, 0, len(values))
+	for _, value := range values {
+		if trimmed := strings.ToLower(strings.TrimSpace(value)); trimmed != "" {
+			lowered = append(lowered, trimmed)
+		}
+	}
+	return lowered
+}
+
+// rateLimiter 是按分钟补充的令牌桶，桶容量等于每分钟请求数。
+type rateLimiter struct {
+	mu       sync.Mutex
+	perMin   int
+	tokens   float64
+	lastFill time.Time
+}
+
+var (
+	limitersMu sync.Mutex
+	limiters   = map[string]*rateLimiter{}
+)
+
+// sharedRateLimiter 返回 provider 的进程级限流器；perMinute<=0 表示不限流，配置变更时重建。
+func sharedRateLimiter(provider string, perMinute int) *rateLimiter {
+	if perMinute <= 0 {
+		return nil
+	}
+	limitersMu.Lock()
+	defer limitersMu.Unlock()
+	if limiter, ok := limiters[provider]; ok && limiter.perMin == perMinute {
+		return limiter
+	}
+	limiter := &rateLimiter{perMin: perMinute, tokens: float64(perMinute), lastFill: time.Now()}
+	limiters[provider] = limiter
+	return limiter
+}
+
+// wait 阻塞到取得令牌或 ctx 结束。
+func (l *rateLimiter) wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+	for {
+		delay := l.reserve(time.Now())
+		if delay <= 0 {
+			return nil
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// reserve 尝试取走一个令牌，失败时返回需要等待的时长。
+func (l *rateLimiter) reserve(now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	perSecond := float64(l.perMin) / 60
+	l.tokens += now.Sub(l.lastFill).Seconds() * perSecond
+	if l.tokens > float64(l.perMin) {
+		l.tokens = float64(l.perMin)
+	}
+	l.lastFill = now
+	if l.tokens >= 1 {
+		l.tokens--
+		return 0
+	}
+	return time.Duration((1 - l.tokens) / perSecond * float64(time.Second))
+}
+
+// responseCache 是带过期时间的 LRU 缓存，缓存的是规范化后、域名过滤前的结果。
+type responseCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	order      *list.List
+	entries    map[string]*list.Element
+}
+
+type cacheEntry struct {
+	key       string
+	response  SearchResponse
+	expiresAt time.Time
+}
+
+var (
+	cacheMu     sync.Mutex
+	searchCache *responseCache
+)
+
+func sharedResponseCache(maxEntries int) *responseCache {
+	if maxEntries <= 0 {
+		maxEntries = 256
+	}
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	if searchCache == nil || searchCache.maxEntries != maxEntries {
+		searchCache = &responseCache{maxEntries: maxEntries, order: list.New(), entries: map[string]*list.Element{}}
+	}
+	return searchCache
+}
+
+func cacheTTL(seconds int) time.Duration {
+	if seconds < 0 {
+		return 0
+	}
+	if seconds == 0 {
+		seconds = 600
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+func (c *responseCache) get(key string) (SearchResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	element, ok := c.entries[key]
+	if !ok {
+		return SearchResponse{}, false
+	}
+	entry := element.Value.(*cacheEntry)
+	if time.Now().After(entry.expiresAt) {
+		c.order.Remove(element)
+		delete(c.entries, key)
+		return SearchResponse{}, false
+	}
+	c.order.MoveToFront(element)
+	return cloneResponse(entry.response), true
+}
+
+func (c *responseCache) set(key string, response SearchResponse, ttl time.Duration) {
+	if ttl <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry := &cacheEntry{key: key, response: cloneResponse(response), expiresAt: time.Now().Add(ttl)}
+	if element, ok := c.entries[key]; ok {
+		element.Value = entry
+		c.order.MoveToFront(element)
+		return
+	}
+	c.entries[key] = c.order.PushFront(entry)
+	for c.order.Len() > c.maxEntries {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+func cloneResponse(response SearchResponse) SearchResponse {
+	response.Results = append([]SearchResult(nil), response.Results...)
+	return response
+}
@@ -0,0 +1,101 @@
+package web_search_system
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"antifraud/internal/platform/config"
+)
+
+// ProviderFactory 按总配置创建一个搜索 provider，返回的 rateLimitPerMinute 用于该 provider 的进程级限流。
+type ProviderFactory func(cfg config.Config) (searcher Searcher, rateLimitPerMinute int, err error)
+
+var (
+	providersMu sync.RWMutex
+	providers   = map[string]ProviderFactory{}
+)
+
+func init() {
+	mustRegisterProvider(config.WebSearchProviderTavily, func(cfg config.Config) (Searcher, int, error) {
+		return NewTavilyClient(cfg.Tavily), cfg.Tavily.RateLimitPerMinute, nil
+	})
+	mustRegisterProvider(config.WebSearchProviderSearXNG, func(cfg config.Config) (Searcher, int, error) {
+		client, err := NewSearXNGClient(cfg.WebSearch.SearXNG)
+		return client, cfg.WebSearch.SearXNG.RateLimitPerMinute, err
+	})
+	mustRegisterProvider(config.WebSearchProviderBing, func(cfg config.Config) (Searcher, int, error) {
+		return NewBingClient(cfg.WebSearch.Bing), cfg.WebSearch.Bing.RateLimitPerMinute, nil
+	})
+	mustRegisterProvider(config.WebSearchProviderFixture, func(cfg config.Config) (Searcher, int, error) {
+		provider, err := LoadFixtureProvider(cfg.WebSearch.Fixture.Path)
+		return provider, 0, err
+	})
+}
+
+// RegisterProvider 注册一个联网搜索 provider；名称为空或重复注册时返回错误。
+func RegisterProvider(name string, factory ProviderFactory) error {
+	trimmedName := strings.ToLower(strings.TrimSpace(name))
+	if trimmedName == "" {
+		return fmt.Errorf("web search provider name is empty")
+	}
+	if factory == nil {
+		return fmt.Errorf("web search provider %q factory is nil", trimmedName)
+	}
+
+	providersMu.Lock()
+	defer providersMu.Unlock()
+	if _, exists := providers[trimmedName]; exists {
+		return fmt.Errorf("web search provider %q already registered", trimmedName)
+	}
+	providers[trimmedName] = factory
+	return nil
+}
+
+func mustRegisterProvider(name string, factory ProviderFactory) {
+	if err := RegisterProvider(name, factory); err != nil {
+		panic(err)
+	}
+}
+
+// ProviderNames 返回已注册的 provider 名称（按字母序）。
+func ProviderNames() []string {
+	providersMu.RLock()
+	defer providersMu.RUnlock()
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewSearcher 按 web_search.provider 创建搜索器，并叠加进程级结果缓存、provider 限流与域名黑白名单。
+// 工具每次调用都会重新创建搜索器，缓存与限流状态按 provider 名称在进程内共享。
+func NewSearcher(cfg config.Config) (Searcher, error) {
+	name := strings.ToLower(strings.TrimSpace(cfg.WebSearch.Provider))
+	if name == "" {
+		name = config.WebSearchProviderTavily
+	}
+
+	providersMu.RLock()
+	factory, ok := providers[name]
+	providersMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown web search provider %q, available: %s", name, strings.Join(ProviderNames(), ", "))
+	}
+
+	provider, rateLimitPerMinute, err := factory(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("create web search provider %q failed: %w", name, err)
+	}
+	return &policySearcher{
+		name:     name,
+		provider: provider,
+		limiter:  sharedRateLimiter(name, rateLimitPerMinute),
+		cache:    sharedResponseCache(cfg.WebSearch.CacheMaxEntries),
+		cacheTTL: cacheTTL(cfg.WebSearch.CacheTTLSeconds),
+		filter:   newDomainFilter(cfg.WebSearch.AllowDomains, cfg.WebSearch.DenyDomains),
+	}, nil
+}
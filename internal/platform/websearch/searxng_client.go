@@ -0,0 +1,149 @@
+package web_search_system
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"antifraud/internal/platform/config"
+)
+
+// SearXNGClient 调用自建 SearXNG 实例的 JSON 搜索接口（实例需开启 json 输出格式）。
+type SearXNGClient struct {
+	baseURL    string
+	engines    string
+	language   string
+	httpClient *http.Client
+}
+
+type searxngSearchResponse struct {
+	Query     string            `json:"query"`
+	Answers   []json.RawMessage `json:"answers"`
+	Infoboxes []struct {
+		Content string `json:"content"`
+	} `json:"infoboxes"`
+	Results []struct {
+		Title         string  `json:"title"`
+		URL           string  `json:"url"`
+		Content       string  `json:"content"`
+		Score         float64 `json:"score"`
+		PublishedDate string  `json:"publishedDate"`
+	} `json:"results"`
+}
+
+// NewSearXNGClient 根据配置创建 SearXNG 客户端，base_url 为空时返回错误。
+func NewSearXNGClient(cfg config.SearXNGConfig) (*SearXNGClient, error) {
+	timeoutMS := cfg.TimeoutMS
+	if timeoutMS <= 0 {
+		timeoutMS = defaultTimeoutMS
+	}
+	return NewSearXNGClientWithHTTPClient(cfg, &http.Client{Timeout: time.Duration(timeoutMS) * time.Millisecond})
+}
+
+// NewSearXNGClientWithHTTPClient 允许测试注入自定义 HTTP 客户端。
+func NewSearXNGClientWithHTTPClient(cfg config.SearXNGConfig, httpClient *http.Client) (*SearXNGClient, error) {
+	baseURL := strings.TrimRight(strings.TrimSpace(cfg.BaseURL), "/")
+	if baseURL == "" {
+		return nil, fmt.Errorf("searxng base url is empty")
+	}
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: defaultTimeoutMS * time.Millisecond}
+	}
+	return &SearXNGClient{
+		baseURL:    baseURL,
+		engines:    strings.TrimSpace(cfg.Engines),
+		language:   strings.TrimSpace(cfg.Language),
+		httpClient: httpClient,
+	}, nil
+}
+
+// Search 执行单次 SearXNG 搜索，answers 或 infobox 作为摘要答案。
+func (c *SearXNGClient) Search(ctx context.Context, query string, maxResults int) (SearchResponse, error) {
+	trimmedQuery := strings.TrimSpace(query)
+	if trimmedQuery == "" {
+		return SearchResponse{}, fmt.Errorf("query is empty")
+	}
+
+	params := url.Values{}
+	params.Set("q", trimmedQuery)
+	params.Set("format", "json")
+	if c.language != "" {
+		params.Set("language", c.language)
+	}
+	if c.engines != "" {
+		params.Set("engines", c.engines)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/search?"+params.Encode(), nil)
+	if err != nil {
+		return SearchResponse{}, fmt.Errorf("build searxng request failed: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return SearchResponse{}, fmt.Errorf("send searxng request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return SearchResponse{}, fmt.Errorf("read searxng response failed: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return SearchResponse{}, fmt.Errorf("searxng search failed, status=%d, body=%s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var decoded searxngSearchResponse
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		return SearchResponse{}, fmt.Errorf("decode searxng response failed: %w", err)
+	}
+
+	answer := ""
+	for _, raw := range decoded.Answers {
+		if answer = decodeSearXNGAnswer(raw); answer != "" {
+			break
+		}
+	}
+	if answer == "" && len(decoded.Infoboxes) > 0 {
+		answer = decoded.Infoboxes[0].Content
+	}
+
+	results := make([]SearchResult, 0, maxResults)
+	for _, item := range decoded.Results {
+		if maxResults > 0 && len(results) >= maxResults {
+			break
+		}
+		results = append(results, SearchResult{
+			Title:         item.Title,
+			URL:           item.URL,
+			Content:       item.Content,
+			Score:         item.Score,
+			PublishedDate: item.PublishedDate,
+		})
+	}
+	return SearchResponse{
+		Query:   firstNonEmpty(decoded.Query, trimmedQuery),
+		Answer:  answer,
+		Results: results,
+	}, nil
+}
+
+// decodeSearXNGAnswer 兼容旧版本的字符串答案与新版本的 {"answer": "..."} 对象。
+func decodeSearXNGAnswer(raw json.RawMessage) string {
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return strings.TrimSpace(text)
+	}
+	var object struct {
+		Answer string `json:"answer"`
+	}
+	if err := json.Unmarshal(raw, &object); err == nil {
+		return strings.TrimSpace(object.Answer)
+	}
+	return ""
+}
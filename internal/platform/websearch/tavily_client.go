@@ -39,20 +39,22 @@ type TavilyClient struct {
 	httpClient    *http.Client
 }
 
-// SearchResponse 是搜索后的结构化结果。
+// SearchResponse 是搜索后的结构化结果，Provider 为实际提供结果的搜索服务。
 type SearchResponse struct {
-	Query   string         `json:"query"`
-	Answer  string         `json:"answer"`
-	Results []SearchResult `json:"results"`
+	Query    string         `json:"query"`
+	Answer   string         `json:"answer"`
+	Provider string         `json:"provider,omitempty"`
+	Results  []SearchResult `json:"results"`
 }
 
-// SearchResult 是单条搜索结果的精简表示。
+// SearchResult 是单条搜索结果的精简表示，Source 为去掉 www. 的来源域名。
 type SearchResult struct {
 	Title         string  `json:"title"`
 	URL           string  `json:"url"`
 	Content       string  `json:"content"`
 	Score         float64 `json:"score"`
 	PublishedDate string  `json:"published_date,omitempty"`
+	Source        string  `json:"source,omitempty"`
 }
 
 type tavilySearchRequest struct {
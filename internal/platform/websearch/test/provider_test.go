@@ -0,0 +1,247 @@
+package web_search_system_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	appcfg "antifraud/internal/platform/config"
+	"antifraud/internal/platform/websearch"
+)
+
+type countingSearcher struct {
+	calls    atomic.Int32
+	response web_search_system.SearchResponse
+}
+
+func (s *countingSearcher) Search(_ context.Context, query string, _ int) (web_search_system.SearchResponse, error) {
+	s.calls.Add(1)
+	response := s.response
+	response.Query = query
+	return response, nil
+}
+
+func registerCountingProvider(t *testing.T, name string, searcher *countingSearcher) {
+	t.Helper()
+	err := web_search_system.RegisterProvider(name, func(cfg appcfg.Config) (web_search_system.Searcher, int, error) {
+		return searcher, 0, nil
+	})
+	if err != nil {
+		t.Fatalf("register provider failed: %v", err)
+	}
+}
+
+func TestSearXNGClientSearch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/search" {
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+		query := r.URL.Query()
+		if query.Get("format") != "json" || query.Get("q") != "刷单 诈骗" || query.Get("language") != "zh-CN" {
+			t.Fatalf("unexpected query params: %s", r.URL.RawQuery)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"query":   "刷单 诈骗",
+			"answers": []interface{}{map[string]string{"answer": "刷单返利是高发诈骗"}},
+			"results": []map[string]interface{}{
+				{"title": "A", "url": "https://example.com/a", "content": "a", "score": 1.5},
+				{"title": "B", "url": "https://example.com/b", "content": "b"},
+			},
+		})
+	}))
+	defer server.Close()
+
+	client, err := web_search_system.NewSearXNGClientWithHTTPClient(appcfg.SearXNGConfig{
+		BaseURL:  server.URL + "/",
+		Language: "zh-CN",
+	}, server.Client())
+	if err != nil {
+		t.Fatalf("create searxng client failed: %v", err)
+	}
+	result, err := client.Search(context.Background(), " 刷单 诈骗 ", 1)
+	if err != nil {
+		t.Fatalf("search failed: %v", err)
+	}
+	if result.Answer != "刷单返利是高发诈骗" {
+		t.Fatalf("unexpected answer: %q", result.Answer)
+	}
+	if len(result.Results) != 1 || result.Results[0].URL != "https://example.com/a" {
+		t.Fatalf("unexpected results: %#v", result.Results)
+	}
+
+	if _, err := web_search_system.NewSearXNGClient(appcfg.SearXNGConfig{}); err == nil {
+		t.Fatalf("expected error for empty base url")
+	}
+}
+
+func TestBingClientSearch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Ocp-Apim-Subscription-Key"); got != "bing-key" {
+			t.Fatalf("unexpected key header: %q", got)
+		}
+		if r.URL.Query().Get("count") != "3" || r.URL.Query().Get("mkt") != "zh-CN" {
+			t.Fatalf("unexpected query params: %s", r.URL.RawQuery)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"webPages": map[string]interface{}{
+				"value": []map[string]interface{}{
+					{"name": "Result", "url": "https://example.com/r", "snippet": "snippet", "datePublished": "2026-03-14T08:00:00.0000000Z"},
+				},
+			},
+		})
+	}))
+	defer server.Close()
+
+	client := web_search_system.NewBingClientWithHTTPClient(appcfg.BingSearchConfig{
+		APIKey:  "bing-key",
+		BaseURL: server.URL,
+		Market:  "zh-CN",
+	}, server.Client())
+	result, err := client.Search(context.Background(), "fraud", 3)
+	if err != nil {
+		t.Fatalf("search failed: %v", err)
+	}
+	if result.Query != "fraud" || len(result.Results) != 1 || result.Results[0].Content != "snippet" {
+		t.Fatalf("unexpected result: %#v", result)
+	}
+
+	missingKey := web_search_system.NewBingClient(appcfg.BingSearchConfig{BaseURL: server.URL})
+	if _, err := missingKey.Search(context.Background(), "fraud", 3); err == nil {
+		t.Fatalf("expected error for empty api key")
+	}
+}
+
+func TestFixtureProviderLoadsFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fixture.json")
+	content := `{
+		"responses": {"Fraud Hotline": {"answer": "拨打 96110", "results": [{"title": "反诈", "url": "https://gov.cn/a"}]}},
+		"default": {"answer": "fallback"}
+	}`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write fixture failed: %v", err)
+	}
+
+	provider, err := web_search_system.LoadFixtureProvider(path)
+	if err != nil {
+		t.Fatalf("load fixture failed: %v", err)
+	}
+	hit, err := provider.Search(context.Background(), "  fraud hotline ", 5)
+	if err != nil {
+		t.Fatalf("search failed: %v", err)
+	}
+	if hit.Answer != "拨打 96110" || len(hit.Results) != 1 {
+		t.Fatalf("unexpected fixture hit: %#v", hit)
+	}
+	miss, err := provider.Search(context.Background(), "other", 5)
+	if err != nil {
+		t.Fatalf("search failed: %v", err)
+	}
+	if miss.Answer != "fallback" || miss.Query != "other" {
+		t.Fatalf("unexpected fixture fallback: %#v", miss)
+	}
+
+	if _, err := web_search_system.LoadFixtureProvider(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Fatalf("expected error for missing fixture file")
+	}
+}
+
+func TestNewSearcherRejectsUnknownProvider(t *testing.T) {
+	_, err := web_search_system.NewSearcher(appcfg.Config{WebSearch: appcfg.WebSearchConfig{Provider: "nope"}})
+	if err == nil || !strings.Contains(err.Error(), "unknown web search provider") {
+		t.Fatalf("expected unknown provider error, got %v", err)
+	}
+
+	names := strings.Join(web_search_system.ProviderNames(), ",")
+	for _, want := range []string{"bing", "fixture", "searxng", "tavily"} {
+		if !strings.Contains(names, want) {
+			t.Fatalf("provider %s not registered: %s", want, names)
+		}
+	}
+	if err := web_search_system.RegisterProvider("tavily", func(appcfg.Config) (web_search_system.Searcher, int, error) {
+		return nil, 0, nil
+	}); err == nil {
+		t.Fatalf("expected duplicate registration error")
+	}
+}
+
+func TestNewSearcherNormalizesFiltersAndCaches(t *testing.T) {
+	searcher := &countingSearcher{response: web_search_system.SearchResponse{
+		Answer: "<b>注意</b> &amp; 防范",
+		Results: []web_search_system.SearchResult{
+			{Title: "<em>官方</em>通报", URL: "https://www.gov.cn/news/1", Content: "a", PublishedDate: "2026-03-14T08:00:00Z"},
+			{Title: "重复", URL: "https://gov.cn/news/1/", Content: "dup"},
+			{Title: "子域名", URL: "https://police.gov.cn/2", Content: "b"},
+			{Title: "屏蔽", URL: "https://spam.gov.cn/3", Content: "c"},
+			{Title: "非白名单", URL: "https://blog.example.com/4", Content: "d"},
+			{Title: "无链接", URL: "", Content: "e"},
+		},
+	}}
+	registerCountingProvider(t, "counting-policy", searcher)
+
+	cfg := appcfg.Config{WebSearch: appcfg.WebSearchConfig{
+		Provider:     "counting-policy",
+		AllowDomains: []string{"gov.cn"},
+		DenyDomains:  []string{"spam.gov.cn"},
+	}}
+	client, err := web_search_system.NewSearcher(cfg)
+	if err != nil {
+		t.Fatalf("new searcher failed: %v", err)
+	}
+	result, err := client.Search(context.Background(), "policy query", 5)
+	if err != nil {
+		t.Fatalf("search failed: %v", err)
+	}
+	if result.Provider != "counting-policy" || result.Answer != "注意 & 防范" {
+		t.Fatalf("unexpected response: %#v", result)
+	}
+	if len(result.Results) != 2 {
+		t.Fatalf("expected 2 filtered results, got %#v", result.Results)
+	}
+	first := result.Results[0]
+	if first.Title != "官方通报" || first.Source != "gov.cn" || first.PublishedDate != "2026-03-14" {
+		t.Fatalf("unexpected normalized result: %#v", first)
+	}
+	if result.Results[1].Source != "police.gov.cn" {
+		t.Fatalf("unexpected second result: %#v", result.Results[1])
+	}
+
+	client, err = web_search_system.NewSearcher(cfg)
+	if err != nil {
+		t.Fatalf("new searcher failed: %v", err)
+	}
+	if _, err := client.Search(context.Background(), "POLICY query", 5); err != nil {
+		t.Fatalf("cached search failed: %v", err)
+	}
+	if calls := searcher.calls.Load(); calls != 1 {
+		t.Fatalf("expected cached response across searchers, provider called %d times", calls)
+	}
+}
+
+func TestNewSearcherCacheDisabled(t *testing.T) {
+	searcher := &countingSearcher{response: web_search_system.SearchResponse{
+		Results: []web_search_system.SearchResult{{Title: "A", URL: "https://example.com/a"}},
+	}}
+	registerCountingProvider(t, "counting-nocache", searcher)
+
+	client, err := web_search_system.NewSearcher(appcfg.Config{WebSearch: appcfg.WebSearchConfig{
+		Provider:        "counting-nocache",
+		CacheTTLSeconds: -1,
+	}})
+	if err != nil {
+		t.Fatalf("new searcher failed: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := client.Search(context.Background(), "same query", 5); err != nil {
+			t.Fatalf("search failed: %v", err)
+		}
+	}
+	if calls := searcher.calls.Load(); calls != 2 {
+		t.Fatalf("expected provider called twice without cache, got %d", calls)
+	}
+}